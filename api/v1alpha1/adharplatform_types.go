@@ -223,3 +223,12 @@ func (l *AdharPlatform) GetArgoProjectName() string {
 func (l *AdharPlatform) GetArgoApplicationName(name string) string {
 	return fmt.Sprintf("%s-%s-gitserver-%s", globals.ProjectName, l.Name, name)
 }
+
+// Default fills in the fields the controllers otherwise assume: an empty
+// provider means a local kind cluster. The mutating webhook applies it so the
+// stored object shows the effective values.
+func (l *AdharPlatform) Default() {
+	if l.Spec.Provider == "" {
+		l.Spec.Provider = ProviderKind
+	}
+}
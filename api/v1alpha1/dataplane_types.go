@@ -162,3 +162,18 @@ type DataPlaneList struct {
 }
 
 func init() { SchemeBuilder.Register(&DataPlane{}, &DataPlaneList{}) }
+
+// DefaultObservabilityHub is the control-plane mesh identity that stores
+// telemetry when a DataPlane does not name one.
+const DefaultObservabilityHub = "adhar-mgmt"
+
+// Default fills in the fields the controllers otherwise assume. The mutating
+// webhook applies it so the stored object shows the effective values.
+func (dp *DataPlane) Default() {
+	if dp.Spec.Profile == "" {
+		dp.Spec.Profile = ProfileStandard
+	}
+	if dp.Spec.Observability.Hub == "" {
+		dp.Spec.Observability.Hub = DefaultObservabilityHub
+	}
+}
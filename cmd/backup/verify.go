@@ -67,5 +67,6 @@ func runVerifyBackup(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("backup %q is not Completed (phase: %s)", name, phase)
 	}
 	fmt.Println(helpers.CreateSuccess("✅ Backup verified: phase Completed"))
+	fmt.Println(helpers.CreateMuted("   For a deep integrity check of the stored archive run: adhar restore verify " + name))
 	return nil
 }
@@ -0,0 +1,133 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the file at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/certs"
+	provider "adhar-io/adhar/platform/providers"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CertsCmd represents the certs command
+var CertsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "Inventory and renew platform certificates",
+	Long: `Track the expiry of the certificates the platform depends on and renew them.
+
+Inventoried certificates:
+• cert-manager Certificates in every namespace
+• The self-signed platform certificate (adhar-cert), which is also the platform CA
+• The controller manager's webhook serving certificate
+• kubeadm control-plane certificates, over SSH (--cluster)
+
+Examples:
+  adhar certs list                          # Inventory with expiry status
+  adhar certs list --cluster=prod           # Include kubeadm certificates
+  adhar certs renew                         # Renew everything expiring soon
+  adhar certs rotate-ca                     # Replace the self-signed platform CA`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+var (
+	warnDays     int
+	criticalDays int
+	clusterName  string
+	sshUser      string
+	output       string
+	assumeYes    bool
+)
+
+func init() {
+	CertsCmd.PersistentFlags().IntVar(&warnDays, "warn-days", 30, "Warn about certificates expiring within this many days")
+	CertsCmd.PersistentFlags().IntVar(&criticalDays, "critical-days", 7, "Flag certificates expiring within this many days as critical")
+	CertsCmd.PersistentFlags().StringVar(&clusterName, "cluster", "", "kubeadm cluster created by adhar whose control-plane certificates to include (uses its SSH key)")
+	CertsCmd.PersistentFlags().StringVar(&sshUser, "ssh-user", "root", "SSH user on the control-plane nodes")
+
+	CertsCmd.AddCommand(listCmd)
+	CertsCmd.AddCommand(renewCmd)
+	CertsCmd.AddCommand(rotateCACmd)
+}
+
+func thresholds() certs.Thresholds {
+	return certs.Thresholds{
+		Warning:  time.Duration(warnDays) * 24 * time.Hour,
+		Critical: time.Duration(criticalDays) * 24 * time.Hour,
+	}
+}
+
+func kubeClient() (client.Client, error) {
+	conf, err := helpers.GetKubeConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the cluster (is it running? try `adhar up`): %w", err)
+	}
+	return helpers.GetKubeClient(conf)
+}
+
+// kubeadmTarget returns SSH access to the control plane of --cluster, or nil
+// when the flag is not set.
+func kubeadmTarget(ctx context.Context, c client.Client) (*certs.KubeadmTarget, error) {
+	if clusterName == "" {
+		return nil, nil
+	}
+	signer, err := provider.LoadClusterSSHKey(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := certs.ControlPlaneNodes(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	return certs.NewKubeadmTarget(signer, sshUser, nodes), nil
+}
+
+// inventory connects to the cluster and lists its certificates.
+func inventory(ctx context.Context) (client.Client, *certs.KubeadmTarget, []certs.Certificate, error) {
+	c, err := kubeClient()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	kubeadm, err := kubeadmTarget(ctx, c)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	list, err := certs.Inventory(ctx, c, certs.Options{Thresholds: thresholds(), Kubeadm: kubeadm})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return c, kubeadm, list, nil
+}
+
+func printResult(result certs.Result) {
+	for _, id := range result.Renewed {
+		fmt.Println(helpers.CreateSuccess("Renewed " + id))
+	}
+	for _, w := range result.Restarted {
+		fmt.Println(helpers.CreateMuted("Restarted " + w))
+	}
+	for _, s := range result.Skipped {
+		fmt.Println(helpers.CreateWarning("Skipped " + s))
+	}
+}
@@ -0,0 +1,106 @@
+package certs
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/certs"
+
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List platform certificates and their expiry",
+	Long: `List the platform's certificates, soonest expiry first.
+
+Certificates expiring within --warn-days are flagged as warning, within
+--critical-days as critical.
+
+Examples:
+  adhar certs list
+  adhar certs list --warn-days=60
+  adhar certs list --cluster=prod --ssh-user=ubuntu
+  adhar certs list -o json`,
+	Args: cobra.NoArgs,
+	RunE: runList,
+}
+
+func init() {
+	listCmd.Flags().StringVarP(&output, "output", "o", "table", "Output format (table, json)")
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	_, _, list, err := inventory(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch output {
+	case "json":
+		return helpers.PrintJSON(list)
+	case "table":
+	default:
+		return fmt.Errorf("unsupported output format %q (use table or json)", output)
+	}
+
+	if len(list) == 0 {
+		fmt.Println(helpers.CreateMuted("No certificates found."))
+		return nil
+	}
+
+	now := time.Now()
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%-10s %-60s %-12s %s\n", "STATUS", "CERTIFICATE", "EXPIRES", "DAYS LEFT"))
+	b.WriteString(strings.Repeat("─", 100) + "\n")
+	counts := map[certs.Status]int{}
+	for _, c := range list {
+		counts[c.Status]++
+		expires, left := "-", "-"
+		if !c.NotAfter.IsZero() {
+			expires = c.NotAfter.Format("2006-01-02")
+			left = fmt.Sprintf("%d", int(math.Floor(c.NotAfter.Sub(now).Hours()/24)))
+		}
+		name := c.ID()
+		if c.IsCA {
+			name += " (CA)"
+		}
+		b.WriteString(fmt.Sprintf("%-10s %-60s %-12s %s\n", statusLabel(c.Status), name, expires, left))
+	}
+	fmt.Println(helpers.BorderStyle.Render(b.String()))
+
+	if n := counts[certs.StatusExpired]; n > 0 {
+		fmt.Println(helpers.CreateError(fmt.Sprintf("%d certificate(s) expired", n)))
+	}
+	if n := counts[certs.StatusCritical]; n > 0 {
+		fmt.Println(helpers.CreateError(fmt.Sprintf("%d certificate(s) expire within %d days", n, criticalDays)))
+	}
+	if n := counts[certs.StatusWarning]; n > 0 {
+		fmt.Println(helpers.CreateWarning(fmt.Sprintf("%d certificate(s) expire within %d days", n, warnDays)))
+	}
+	if counts[certs.StatusExpired]+counts[certs.StatusCritical]+counts[certs.StatusWarning] > 0 {
+		fmt.Println(helpers.CreateMuted("Renew them with `adhar certs renew`."))
+	}
+	return nil
+}
+
+func statusLabel(s certs.Status) string {
+	switch s {
+	case certs.StatusOK:
+		return "✅ ok"
+	case certs.StatusWarning:
+		return "⚠️  warn"
+	case certs.StatusCritical:
+		return "🔴 crit"
+	case certs.StatusExpired:
+		return "❌ expired"
+	}
+	return "❔ " + string(s)
+}
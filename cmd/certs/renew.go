@@ -0,0 +1,129 @@
+package certs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/certs"
+
+	"github.com/spf13/cobra"
+)
+
+var renewCmd = &cobra.Command{
+	Use:   "renew [CERTIFICATE...]",
+	Short: "Renew certificates that are expiring",
+	Long: `Renew certificates, by default every one within --warn-days of expiry.
+
+Pass certificate IDs as shown by 'adhar certs list' (source/namespace/name), or
+--all to renew everything.
+
+• cert-manager Certificates are reissued by cert-manager
+• The self-signed platform certificate is re-signed with its existing key, so
+  clients that trust it keep working; workloads mounting it are restarted
+• The webhook serving certificate is reissued by restarting the controller manager
+• kubeadm certificates are renewed with 'kubeadm certs renew all' on each
+  control-plane node, then the static control-plane pods are restarted; nodes
+  are renewed one at a time, waiting for the local API server and etcd member
+  to be healthy before the next, so an HA control plane keeps quorum
+
+Examples:
+  adhar certs renew
+  adhar certs renew --dry-run
+  adhar certs renew cert-manager/adhar-system/keycloak-tls
+  adhar certs renew --all --cluster=prod --yes`,
+	RunE: runRenew,
+}
+
+var (
+	renewAll    bool
+	renewDryRun bool
+)
+
+func init() {
+	renewCmd.Flags().BoolVar(&renewAll, "all", false, "Renew every certificate, not only expiring ones")
+	renewCmd.Flags().BoolVar(&renewDryRun, "dry-run", false, "Show what would be renewed without changing anything")
+	renewCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Renew without interactive confirmation")
+}
+
+func runRenew(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+
+	c, kubeadm, list, err := inventory(ctx)
+	if err != nil {
+		return err
+	}
+	selected, err := selectCertificates(list, args, renewAll)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		fmt.Println(helpers.CreateSuccess(fmt.Sprintf("No certificates expire within %d days", warnDays)))
+		return nil
+	}
+
+	fmt.Println("Certificates to renew:")
+	for _, cert := range selected {
+		fmt.Printf("  %s (%s)\n", cert.ID(), cert.Status)
+	}
+	if renewDryRun {
+		return nil
+	}
+	if !confirm("Renew these certificates? Workloads using them will be restarted. [y/N]: ") {
+		fmt.Println("Aborted; nothing renewed.")
+		return nil
+	}
+
+	result, err := certs.Renew(ctx, c, selected, kubeadm)
+	printResult(result)
+	if err != nil {
+		return err
+	}
+	if kubeadm != nil && len(result.Renewed) > 0 {
+		fmt.Println(helpers.CreateMuted("kubeadm renewed the admin kubeconfig; fetch it again with `adhar cluster kubeconfig`."))
+	}
+	return nil
+}
+
+// selectCertificates picks the certificates named by ids, everything with
+// all, or those due for renewal.
+func selectCertificates(list []certs.Certificate, ids []string, all bool) ([]certs.Certificate, error) {
+	if len(ids) == 0 {
+		var selected []certs.Certificate
+		for _, cert := range list {
+			if all || cert.Status.NeedsRenewal() {
+				selected = append(selected, cert)
+			}
+		}
+		return selected, nil
+	}
+
+	byID := map[string]certs.Certificate{}
+	for _, cert := range list {
+		byID[cert.ID()] = cert
+	}
+	var selected []certs.Certificate
+	for _, id := range ids {
+		cert, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("certificate %q not found (see `adhar certs list`)", id)
+		}
+		selected = append(selected, cert)
+	}
+	return selected, nil
+}
+
+func confirm(prompt string) bool {
+	if assumeYes {
+		return true
+	}
+	fmt.Print(prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	a := strings.ToLower(strings.TrimSpace(answer))
+	return a == "y" || a == "yes"
+}
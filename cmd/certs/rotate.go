@@ -0,0 +1,102 @@
+package certs
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/certs"
+	"adhar-io/adhar/platform/providers/kind"
+
+	"github.com/spf13/cobra"
+)
+
+var rotateCACmd = &cobra.Command{
+	Use:   "rotate-ca",
+	Short: "Replace the self-signed platform CA",
+	Long: `Replace the self-signed platform certificate, which is also the platform CA,
+with a new certificate on a new key for the same hosts.
+
+The new certificate is written to every copy (adhar-cert, argocd-server-tls),
+the CA bundle Secret (default/adhar-cert) and the AdharPlatform resource, and
+workloads that mount it are restarted so they trust the new CA. For local
+platforms the copy in .adhar/pki, which the Kind API server trusts for OIDC, is
+updated as well.
+
+Browsers and machines that trusted the old CA must trust the new one: save it
+with --ca-out.
+
+Examples:
+  adhar certs rotate-ca
+  adhar certs rotate-ca --ca-out=adhar-ca.crt --yes`,
+	Args: cobra.NoArgs,
+	RunE: runRotateCA,
+}
+
+var caOut string
+
+func init() {
+	rotateCACmd.Flags().StringVar(&caOut, "ca-out", "", "Write the new CA certificate to this file")
+	rotateCACmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Rotate without interactive confirmation")
+}
+
+func runRotateCA(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	c, err := kubeClient()
+	if err != nil {
+		return err
+	}
+	if !confirm("Rotate the platform CA? Clients trusting the current CA will stop trusting the platform. [y/N]: ") {
+		fmt.Println("Aborted; nothing rotated.")
+		return nil
+	}
+
+	result, err := certs.RotateCA(ctx, c)
+	printResult(result)
+	if err != nil {
+		return err
+	}
+
+	cert, key, err := certs.PlatformCertificate(ctx, c)
+	if err != nil {
+		return err
+	}
+	if updated, err := updateLocalPKI(cert, key); err != nil {
+		return err
+	} else if updated {
+		fmt.Println(helpers.CreateWarning("Updated " + kind.PlatformPKIDirName + "; restart the Kind node container so the API server trusts the new CA for OIDC."))
+	}
+	if caOut != "" {
+		if err := os.WriteFile(caOut, cert, 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", caOut, err)
+		}
+		fmt.Println(helpers.CreateSuccess("New CA written to " + caOut))
+	}
+	if parsed, err := certs.ParsePEM(cert); err == nil {
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("New CA SHA-256 fingerprint: %X", sha256.Sum256(parsed.Raw))))
+	}
+	return nil
+}
+
+// updateLocalPKI refreshes the pre-generated platform certificate of a local
+// platform, when the working directory has one.
+func updateLocalPKI(cert, key []byte) (bool, error) {
+	certPath := filepath.Join(kind.PlatformPKIDirName, kind.PlatformCertFileName)
+	if _, err := os.Stat(certPath); err != nil {
+		return false, nil
+	}
+	if err := os.WriteFile(certPath, cert, 0o644); err != nil {
+		return false, fmt.Errorf("writing %s: %w", certPath, err)
+	}
+	keyPath := filepath.Join(kind.PlatformPKIDirName, kind.PlatformKeyFileName)
+	if err := os.WriteFile(keyPath, key, 0o600); err != nil {
+		return false, fmt.Errorf("writing %s: %w", keyPath, err)
+	}
+	return true, nil
+}
@@ -1,31 +1,549 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the file at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
 package config
 
 import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"adhar-io/adhar/cmd/helpers"
+	platformconfig "adhar-io/adhar/platform/config"
 	"adhar-io/adhar/platform/logger"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"sigs.k8s.io/yaml"
 )
 
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create new configuration files",
-	RunE:  runCreate,
+	Long: `Generate a validated, commented multi-environment config.yaml.
+
+Providers are given as type[:region] (up to two). With two providers one of
+them hosts the management cluster (--primary), and environments are placed on
+the production or non-production provider according to their type. Anything
+not supplied by flags is prompted for when running in a terminal.
+
+The generated file is validated and its environments resolved before it is
+written; an existing file is never overwritten without --force.
+
+Examples:
+  adhar config create
+  adhar config create --provider=kind
+  adhar config create --provider=gcp:asia-south1 --provider=digitalocean:blr1 \
+    --production-provider=gcp --env=dev:non-production --env=production:production
+  adhar config create --provider=aws --region=eu-west-1 --output=./prod.yaml --force`,
+	RunE: runCreate,
 }
 
 var (
-	createProvider string
-	createRegion   string
-	createName     string
+	createProviders       []string
+	createRegion          string
+	createName            string
+	createPrimary         string
+	createProdProvider    string
+	createNonProdProvider string
+	createEnvironments    []string
+	createHost            string
+	createEmail           string
+	createHA              bool
+	createOutput          string
+	createForce           bool
+	createNoPrompt        bool
 )
 
 func init() {
-	createCmd.Flags().StringVarP(&createProvider, "provider", "p", "", "Cloud provider")
-	createCmd.Flags().StringVarP(&createRegion, "region", "r", "", "Cloud region")
-	createCmd.Flags().StringVarP(&createName, "name", "n", "", "Configuration name")
+	createCmd.Flags().StringSliceVarP(&createProviders, "provider", "p", nil, "Provider as type[:region] (repeatable, at most 2)")
+	createCmd.Flags().StringVarP(&createRegion, "region", "r", "", "Region for providers given without one")
+	createCmd.Flags().StringVarP(&createName, "name", "n", "adhar-mgmt", "Management cluster context name")
+	createCmd.Flags().StringVar(&createPrimary, "primary", "", "Provider hosting the management cluster (defaults to the production provider)")
+	createCmd.Flags().StringVar(&createProdProvider, "production-provider", "", "Provider for production environments")
+	createCmd.Flags().StringVar(&createNonProdProvider, "non-production-provider", "", "Provider for non-production environments")
+	createCmd.Flags().StringSliceVarP(&createEnvironments, "env", "e", nil, "Environment as name[:type] (repeatable)")
+	createCmd.Flags().StringVar(&createHost, "host", "cloud.adhar.io", "Default platform host")
+	createCmd.Flags().StringVar(&createEmail, "email", "", "Email for certificate management")
+	createCmd.Flags().BoolVar(&createHA, "ha", false, "Enable HA mode")
+	createCmd.Flags().StringVarP(&createOutput, "output", "o", "", "Output path (defaults to --file or ./config.yaml)")
+	createCmd.Flags().BoolVar(&createForce, "force", false, "Overwrite an existing file")
+	createCmd.Flags().BoolVar(&createNoPrompt, "no-prompt", false, "Never prompt; use flags and defaults only")
+}
+
+// defaultRegions is used when a provider is given without a region.
+var defaultRegions = map[string]string{
+	"kind":         "local",
+	"custom":       "on-premises",
+	"aws":          "us-east-1",
+	"azure":        "eastus",
+	"gcp":          "us-central1",
+	"digitalocean": "nyc1",
+	"civo":         "LON1",
+}
+
+// createOptions is the fully-answered input to the generator.
+type createOptions struct {
+	Context            string
+	Host               string
+	Email              string
+	HA                 bool
+	Providers          []providerSpec
+	Primary            string
+	ProductionProvider string
+	NonProdProvider    string
+	Environments       []environmentSpec
+}
+
+type providerSpec struct {
+	Type   string
+	Region string
+}
+
+type environmentSpec struct {
+	Name string
+	Type string
 }
 
 func runCreate(cmd *cobra.Command, args []string) error {
+	path := createOutput
+	if path == "" {
+		path = configFile
+	}
+	if path == "" {
+		path = "./config.yaml"
+	}
+	if _, err := os.Stat(path); err == nil && !createForce {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+	}
+
 	logger.Info("📝 Creating new configuration...")
-	// TODO: Implement configuration creation
+
+	interactive := !createNoPrompt && term.IsTerminal(int(os.Stdin.Fd()))
+	opts, err := gatherCreateOptions(cmd, interactive, bufio.NewReader(os.Stdin), os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	data, cfg, err := generateConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("create directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+
+	fmt.Println(helpers.CreateSuccess("Configuration written to " + path))
+	names := make([]string, 0, len(cfg.ResolvedEnvironments))
+	for name := range cfg.ResolvedEnvironments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env := cfg.ResolvedEnvironments[name]
+		fmt.Printf("🌍 %-14s %-15s → %s (%s)\n", name, env.ResolvedType, env.ResolvedProvider, env.ResolvedRegion)
+	}
+	fmt.Println(helpers.CreateMuted("   Add credentials under providers.<name> before provisioning cloud clusters"))
+	return nil
+}
+
+// gatherCreateOptions merges flags with answers to prompts. Prompts are only
+// shown for values that were not set on the command line.
+func gatherCreateOptions(cmd *cobra.Command, interactive bool, in *bufio.Reader, out io.Writer) (createOptions, error) {
+	changed := func(name string) bool { return cmd != nil && cmd.Flags().Changed(name) }
+	ask := func(label, def string) string {
+		if !interactive {
+			return def
+		}
+		if def != "" {
+			fmt.Fprintf(out, "%s [%s]: ", label, def)
+		} else {
+			fmt.Fprintf(out, "%s: ", label)
+		}
+		line, _ := in.ReadString('\n')
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+		return def
+	}
+
+	opts := createOptions{Context: createName, Host: createHost, Email: createEmail, HA: createHA}
+
+	providers := createProviders
+	if !changed("provider") {
+		providers = splitList(ask("Providers (type[:region], comma separated, max 2)", "kind:local"))
+	}
+	for _, raw := range providers {
+		p, err := parseProviderSpec(raw, createRegion)
+		if err != nil {
+			return opts, err
+		}
+		opts.Providers = append(opts.Providers, p)
+	}
+
+	if len(opts.Providers) == 2 {
+		first, second := opts.Providers[0].Type, opts.Providers[1].Type
+		opts.ProductionProvider = createProdProvider
+		if !changed("production-provider") {
+			opts.ProductionProvider = ask("Production provider", first)
+		}
+		opts.NonProdProvider = createNonProdProvider
+		if !changed("non-production-provider") {
+			def := second
+			if opts.ProductionProvider == second {
+				def = first
+			}
+			opts.NonProdProvider = ask("Non-production provider", def)
+		}
+		opts.Primary = createPrimary
+		if !changed("primary") {
+			opts.Primary = ask("Management cluster provider", opts.ProductionProvider)
+		}
+	}
+
+	envs := createEnvironments
+	if !changed("env") {
+		envs = splitList(ask("Environments (name[:type], comma separated)", "dev:non-production,production:production"))
+	}
+	for _, raw := range envs {
+		e, err := parseEnvironmentSpec(raw)
+		if err != nil {
+			return opts, err
+		}
+		opts.Environments = append(opts.Environments, e)
+	}
+
+	if !changed("name") {
+		opts.Context = ask("Management context name", opts.Context)
+	}
+	if !changed("host") {
+		opts.Host = ask("Default host", opts.Host)
+	}
+	if !changed("email") {
+		opts.Email = ask("Email for certificates", opts.Email)
+	}
+	if opts.Email == "" {
+		if !interactive {
+			return opts, fmt.Errorf("--email is required in non-interactive mode")
+		}
+		return opts, fmt.Errorf("an email for certificates is required")
+	}
+	if !changed("ha") && interactive {
+		switch strings.ToLower(ask("Enable HA mode (y/n)", map[bool]string{true: "y", false: "n"}[opts.HA])) {
+		case "y", "yes", "true":
+			opts.HA = true
+		case "n", "no", "false":
+			opts.HA = false
+		}
+	}
+	return opts, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// parseProviderSpec parses type[:region], falling back to fallbackRegion and
+// then to the provider's default region.
+func parseProviderSpec(raw, fallbackRegion string) (providerSpec, error) {
+	typ, region, _ := strings.Cut(strings.TrimSpace(raw), ":")
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if _, ok := defaultRegions[typ]; !ok {
+		return providerSpec{}, fmt.Errorf("unknown provider type %q (valid: aws, azure, gcp, digitalocean, civo, custom, kind)", typ)
+	}
+	region = strings.TrimSpace(region)
+	if region == "" {
+		region = fallbackRegion
+	}
+	if region == "" {
+		region = defaultRegions[typ]
+	}
+	return providerSpec{Type: typ, Region: region}, nil
+}
+
+// parseEnvironmentSpec parses name[:type]. Without a type, environments named
+// prod/production are production and everything else is non-production.
+func parseEnvironmentSpec(raw string) (environmentSpec, error) {
+	name, typ, _ := strings.Cut(strings.TrimSpace(raw), ":")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return environmentSpec{}, fmt.Errorf("invalid environment %q: name is required", raw)
+	}
+	switch strings.ToLower(strings.TrimSpace(typ)) {
+	case "":
+		typ = platformconfig.EnvironmentTypeNonProduction
+		if name == "prod" || name == "production" {
+			typ = platformconfig.EnvironmentTypeProduction
+		}
+	case "production", "prod":
+		typ = platformconfig.EnvironmentTypeProduction
+	case "non-production", "nonprod", "non-prod":
+		typ = platformconfig.EnvironmentTypeNonProduction
+	default:
+		return environmentSpec{}, fmt.Errorf("invalid environment type %q for %s (production or non-production)", typ, name)
+	}
+	return environmentSpec{Name: name, Type: typ}, nil
+}
+
+// check reports problems the schema validator cannot see, such as provider
+// roles that point at providers which are not configured.
+func (o createOptions) check() error {
+	switch len(o.Providers) {
+	case 0:
+		return fmt.Errorf("at least one provider is required")
+	case 1:
+	case 2:
+		if o.Providers[0].Type == o.Providers[1].Type {
+			return fmt.Errorf("provider %s given twice", o.Providers[0].Type)
+		}
+		for role, name := range map[string]string{"primary": o.Primary, "production": o.ProductionProvider, "non-production": o.NonProdProvider} {
+			if name != o.Providers[0].Type && name != o.Providers[1].Type {
+				return fmt.Errorf("%s provider %q is not one of the configured providers", role, name)
+			}
+		}
+	default:
+		return fmt.Errorf("at most 2 providers are supported, got %d", len(o.Providers))
+	}
+	if len(o.Environments) == 0 {
+		return fmt.Errorf("at least one environment is required")
+	}
+	seen := map[string]bool{}
+	for _, e := range o.Environments {
+		if seen[e.Name] {
+			return fmt.Errorf("environment %s given twice", e.Name)
+		}
+		seen[e.Name] = true
+	}
 	return nil
 }
+
+// providerFor returns the provider an environment of the given type runs on.
+func (o createOptions) providerFor(envType string) string {
+	if len(o.Providers) == 1 {
+		return o.Providers[0].Type
+	}
+	if envType == platformconfig.EnvironmentTypeProduction {
+		return o.ProductionProvider
+	}
+	return o.NonProdProvider
+}
+
+// generateConfig renders the commented config.yaml and validates what was
+// rendered: the output is parsed back, checked by the schema validator and
+// its environments resolved, so the file on disk is known to load.
+func generateConfig(o createOptions) ([]byte, *platformconfig.Config, error) {
+	if err := o.check(); err != nil {
+		return nil, nil, err
+	}
+	data := renderConfig(o)
+
+	var cfg platformconfig.Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, nil, fmt.Errorf("generated config does not parse: %w", err)
+	}
+	if err := platformconfig.NewSchemaValidator().ValidateConfig(&cfg); err != nil {
+		return nil, nil, fmt.Errorf("generated config is invalid: %w", err)
+	}
+	if err := cfg.ResolveEnvironments(); err != nil {
+		return nil, nil, fmt.Errorf("resolve environments: %w", err)
+	}
+	for name, env := range cfg.ResolvedEnvironments {
+		if _, ok := cfg.Providers[env.ResolvedProvider]; !ok {
+			return nil, nil, fmt.Errorf("environment %s resolved to unknown provider %q", name, env.ResolvedProvider)
+		}
+	}
+	return data, &cfg, nil
+}
+
+const (
+	prodTemplate    = "prod-defaults"
+	nonprodTemplate = "nonprod-defaults"
+)
+
+// renderConfig writes the configuration by hand so it can carry the same
+// guidance comments as the reference config.yaml.
+func renderConfig(o createOptions) []byte {
+	var b strings.Builder
+	q := strconv.Quote
+	w := func(format string, a ...interface{}) { fmt.Fprintf(&b, format+"\n", a...) }
+
+	w("# Adhar Platform Configuration")
+	w("#")
+	w("# Generated by `adhar config create`. Validate changes with `adhar config validate`.")
+	w("# Never commit real tokens/credentials to version control; prefer")
+	w("# useEnvironment: true or credentials_file on each provider.")
+	w("")
+	w("# Global Settings")
+	w("globalSettings:")
+	w("  adharContext: %s", q(o.Context))
+	w("  defaultHost: %s", q(o.Host))
+	w("  defaultHttpPort: 80")
+	w("  defaultHttpsPort: 8443")
+	w("  enableHAMode: %t", o.HA)
+	w("  email: %s", q(o.Email))
+	if len(o.Providers) == 2 {
+		w("  productionProvider: %s", q(o.ProductionProvider))
+		w("  nonProductionProvider: %s", q(o.NonProdProvider))
+	}
+
+	w("")
+	w("# Provider configurations")
+	if len(o.Providers) == 2 {
+		w("# The primary provider hosts the management cluster; environments are")
+		w("# placed on the production or non-production provider by type.")
+	}
+	w("providers:")
+	for _, p := range o.Providers {
+		primary := len(o.Providers) == 1 || p.Type == o.Primary
+		w("  %s:", p.Type)
+		w("    type: %s", p.Type)
+		w("    region: %s", q(p.Region))
+		if primary {
+			w("    primary: true # Management cluster provider")
+		} else {
+			w("    primary: false")
+		}
+		for _, line := range providerHints[p.Type] {
+			w("    # %s", line)
+		}
+	}
+
+	used := map[string]bool{}
+	for _, e := range o.Environments {
+		used[templateFor(e.Type)] = true
+	}
+	w("")
+	w("# Environment Templates for reusable configurations")
+	w("environmentTemplates:")
+	if used[prodTemplate] {
+		w("  # Production template with high availability")
+		renderTemplate(w, prodTemplate, "3", "10", "2")
+	}
+	if used[nonprodTemplate] {
+		w("  # Non-production template with cost optimization")
+		renderTemplate(w, nonprodTemplate, "1", "5", "1")
+	}
+
+	w("")
+	w("# Environment Definitions")
+	w("environments:")
+	for _, e := range o.Environments {
+		provider := o.providerFor(e.Type)
+		w("  # %s environment on %s", e.Name, provider)
+		w("  %s:", e.Name)
+		w("    type: %s", e.Type)
+		w("    provider: %s", provider)
+		w("    template: %s", templateFor(e.Type))
+		w("    clusterConfig:")
+		w("      - key: \"name\"")
+		w("        value: %s", q("adhar-"+e.Name))
+	}
+	return []byte(b.String())
+}
+
+func templateFor(envType string) string {
+	if envType == platformconfig.EnvironmentTypeProduction {
+		return prodTemplate
+	}
+	return nonprodTemplate
+}
+
+// coreCharts are the core services every template installs.
+var coreCharts = []struct {
+	Service, RepoURL, Chart, Version, ReplicaKey string
+}{
+	{"cilium", "https://helm.cilium.io/", "cilium", "1.15.7", "operator.replicas"},
+	{"nginx", "https://kubernetes.github.io/ingress-nginx", "ingress-nginx", "4.10.1", "controller.replicaCount"},
+	{"gitea", "https://dl.gitea.com/charts/", "gitea", "10.3.0", ""},
+	{"argocd", "https://argoproj.github.io/argo-helm", "argo-cd", "6.11.1", "server.replicas"},
+}
+
+func renderTemplate(w func(string, ...interface{}), name, minNodes, maxNodes, replicas string) {
+	w("  %s:", name)
+	w("    clusterConfig:")
+	w("      - key: \"autoScale\"")
+	w("        value: \"true\"")
+	w("      - key: \"minNodes\"")
+	w("        value: %q", minNodes)
+	w("      - key: \"maxNodes\"")
+	w("        value: %q", maxNodes)
+	w("    coreServices:")
+	for _, c := range coreCharts {
+		w("      %s:", c.Service)
+		w("        chart:")
+		w("          repoURL: %q", c.RepoURL)
+		w("          name: %q", c.Chart)
+		w("          version: %q", c.Version)
+		if c.ReplicaKey != "" && replicas != "1" {
+			w("        values:")
+			w("          - key: %q", c.ReplicaKey)
+			w("            value: %q", replicas)
+		}
+	}
+}
+
+// providerHints are commented-out credential settings shown under each
+// provider so users know what to fill in.
+var providerHints = map[string][]string{
+	"aws": {
+		`credentials_file: "~/.aws/credentials"`,
+		`useEnvironment: true # AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY`,
+	},
+	"azure": {
+		`credentials_file: "~/.azure/accessTokens.json"`,
+		`useEnvironment: true # AZURE_CLIENT_ID, AZURE_CLIENT_SECRET, AZURE_TENANT_ID, AZURE_SUBSCRIPTION_ID`,
+	},
+	"gcp": {
+		`credentials_file: "~/.config/gcloud/YOUR_GCP_SERVICE_ACCOUNT.json"`,
+		`config:`,
+		`  project_id: "YOUR_GCP_PROJECT_ID"`,
+	},
+	"digitalocean": {
+		`useEnvironment: true # DIGITALOCEAN_TOKEN`,
+		`useManagedK8s: true # managed DOKS instead of droplets + kubeadm`,
+	},
+	"civo": {
+		`useEnvironment: true # CIVO_TOKEN`,
+	},
+	"custom": {
+		`config:`,
+		`  username: "ubuntu"`,
+		`  sshKeyPath: "~/.ssh/id_rsa"`,
+		`  nodeIPs: ["192.168.1.10"]`,
+	},
+	"kind": {
+		`config:`,
+		`  kind_path: kind`,
+		`  kubectl_path: kubectl`,
+	},
+}
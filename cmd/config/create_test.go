@@ -0,0 +1,99 @@
+package config
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	platformconfig "adhar-io/adhar/platform/config"
+)
+
+func TestGenerateConfigMultiProvider(t *testing.T) {
+	opts := createOptions{
+		Context: "adhar-mgmt", Host: "cloud.example.com", Email: "ops@example.com",
+		Providers:          []providerSpec{{Type: "gcp", Region: "asia-south1"}, {Type: "digitalocean", Region: "blr1"}},
+		Primary:            "gcp",
+		ProductionProvider: "gcp",
+		NonProdProvider:    "digitalocean",
+		Environments:       []environmentSpec{{Name: "dev", Type: "non-production"}, {Name: "production", Type: "production"}},
+	}
+	data, cfg, err := generateConfig(opts)
+	if err != nil {
+		t.Fatalf("generateConfig: %v\n%s", err, data)
+	}
+	if !strings.HasPrefix(string(data), "# Adhar Platform Configuration") || !strings.Contains(string(data), "primary: true # Management cluster provider") {
+		t.Errorf("missing comments:\n%s", data)
+	}
+	if !cfg.Providers["gcp"].Primary || cfg.Providers["digitalocean"].Primary {
+		t.Errorf("primary = %+v", cfg.Providers)
+	}
+	dev, prod := cfg.ResolvedEnvironments["dev"], cfg.ResolvedEnvironments["production"]
+	if dev.ResolvedProvider != "digitalocean" || dev.ResolvedRegion != "blr1" || prod.ResolvedProvider != "gcp" {
+		t.Errorf("dev = %s/%s, production = %s", dev.ResolvedProvider, dev.ResolvedRegion, prod.ResolvedProvider)
+	}
+	if diags, err := platformconfig.ValidateDocument(data, platformconfig.LintOptions{SkipCharts: true}); err != nil || platformconfig.HasErrors(diags) {
+		t.Errorf("generated config fails config validate: %v %+v", err, diags)
+	}
+	if prod.ResolvedCoreServices.ArgoCD == nil || len(prod.ResolvedClusterConfig) != 4 {
+		t.Errorf("production not resolved from template: %+v", prod)
+	}
+
+	opts.ProductionProvider = "aws"
+	if _, _, err := generateConfig(opts); err == nil {
+		t.Errorf("unknown production provider should fail")
+	}
+	opts.ProductionProvider = "gcp"
+	opts.Email = ""
+	if _, _, err := generateConfig(opts); err == nil || !strings.Contains(err.Error(), "email") {
+		t.Errorf("missing email should fail schema validation, got %v", err)
+	}
+}
+
+// setCreateFlags sets the create flag globals for one test and restores them
+// afterwards.
+func setCreateFlags(t *testing.T, name, host, email, region string) {
+	t.Helper()
+	oldName, oldHost, oldEmail, oldRegion := createName, createHost, createEmail, createRegion
+	t.Cleanup(func() { createName, createHost, createEmail, createRegion = oldName, oldHost, oldEmail, oldRegion })
+	createName, createHost, createEmail, createRegion = name, host, email, region
+}
+
+func TestGatherCreateOptionsPrompts(t *testing.T) {
+	setCreateFlags(t, "adhar-mgmt", "cloud.adhar.io", "", "")
+	in := bufio.NewReader(strings.NewReader("aws:eu-west-1, civo\n\ncivo\n\ndev,staging:prod\n\n\nme@example.com\ny\n"))
+	opts, err := gatherCreateOptions(nil, true, in, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts.Providers) != 2 || opts.Providers[1].Region != "LON1" {
+		t.Errorf("providers = %+v", opts.Providers)
+	}
+	if opts.ProductionProvider != "aws" || opts.NonProdProvider != "civo" || opts.Primary != "aws" {
+		t.Errorf("roles = %s/%s/%s", opts.ProductionProvider, opts.NonProdProvider, opts.Primary)
+	}
+	if len(opts.Environments) != 2 || opts.Environments[1].Type != "production" || opts.Email != "me@example.com" {
+		t.Errorf("opts = %+v", opts)
+	}
+	if _, _, err := generateConfig(opts); err != nil {
+		t.Errorf("generateConfig: %v", err)
+	}
+
+	if !opts.HA {
+		t.Errorf("HA answer ignored")
+	}
+	if p, err := parseProviderSpec("gke", ""); err == nil {
+		t.Errorf("parseProviderSpec(gke) = %+v, want error", p)
+	}
+	if _, err := parseEnvironmentSpec("qa:maybe"); err == nil {
+		t.Errorf("invalid environment type should fail")
+	}
+}
+
+func TestGatherCreateOptionsRequiresEmail(t *testing.T) {
+	setCreateFlags(t, "adhar-mgmt", "cloud.adhar.io", "", "")
+	_, err := gatherCreateOptions(nil, false, bufio.NewReader(strings.NewReader("")), io.Discard)
+	if err == nil || err.Error() != "--email is required in non-interactive mode" {
+		t.Errorf("err = %v, want the missing --email error", err)
+	}
+}
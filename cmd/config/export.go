@@ -1,21 +1,151 @@
 package config
 
 import (
-	"adhar-io/adhar/platform/logger"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"adhar-io/adhar/cmd/helpers"
+	platformconfig "adhar-io/adhar/platform/config"
 
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 )
 
 var exportCmd = &cobra.Command{
-	Use:   "export [config-name]",
+	Use:   "export [environment...]",
 	Short: "Export configuration",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runExport,
+	Long: `Export the resolved configuration (every environment with its template,
+provider and global settings merged) in a form that is safe to share or
+commit. Provider credentials are replaced with references to the environment
+variables the provider reads (for example ${DIGITALOCEAN_TOKEN}), or masked
+with --mask.
+
+A provider credential in config.yaml that is a whole ${VAR} reference is read
+from that environment variable when the configuration is loaded, so the
+references can be copied into config.yaml in place of the secrets.
+
+Examples:
+  adhar config export
+  adhar config export production --output=json
+  adhar config export dev staging --dest=./resolved.yaml
+  adhar config export --mask`,
+	RunE: runExport,
+}
+
+var (
+	exportOutput string
+	exportDest   string
+	exportMask   bool
+)
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "yaml", "Output format (yaml, json)")
+	exportCmd.Flags().StringVarP(&exportDest, "dest", "d", "", "Write to this file instead of stdout")
+	exportCmd.Flags().BoolVar(&exportMask, "mask", false, "Mask credentials instead of replacing them with environment variable references")
+}
+
+// exportedConfig is the shareable form of a resolved configuration.
+type exportedConfig struct {
+	GlobalSettings platformconfig.GlobalSettingsConfig `json:"globalSettings"`
+	Environments   map[string]exportedEnvironment      `json:"environments"`
+}
+
+type exportedEnvironment struct {
+	Type          string                               `json:"type"`
+	Provider      string                               `json:"provider"`
+	Region        string                               `json:"region"`
+	ProviderSpec  map[string]interface{}               `json:"providerConfig,omitempty"`
+	ClusterConfig []platformconfig.KeyValueConfig      `json:"clusterConfig,omitempty"`
+	CoreServices  *platformconfig.ResolvedCoreServices `json:"coreServices,omitempty"`
+	Addons        []platformconfig.AddonConfig         `json:"addons,omitempty"`
 }
 
 func runExport(cmd *cobra.Command, args []string) error {
-	configName := args[0]
-	logger.Info("📤 Exporting configuration: " + configName)
-	// TODO: Implement configuration export
+	path := resolveConfigPath()
+	cfg, err := platformconfig.LoadConfig(path)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if err := cfg.ResolveEnvironments(); err != nil {
+		return fmt.Errorf("resolve environments: %w", err)
+	}
+
+	out, redacted, err := buildExport(cfg, args, !exportMask)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	switch exportOutput {
+	case "json":
+		data, err = json.MarshalIndent(out, "", "  ")
+		data = append(data, '\n')
+	case "yaml":
+		data, err = yaml.Marshal(out)
+	default:
+		return fmt.Errorf("unsupported output format %q (yaml, json)", exportOutput)
+	}
+	if err != nil {
+		return fmt.Errorf("marshal export: %w", err)
+	}
+
+	if exportDest == "" {
+		fmt.Print(string(data))
+	} else {
+		if err := os.WriteFile(exportDest, data, 0644); err != nil {
+			return fmt.Errorf("write export: %w", err)
+		}
+		fmt.Println(helpers.CreateSuccess(fmt.Sprintf("Exported %d environment(s) to %s", len(out.Environments), exportDest)))
+	}
+	// Report on stderr so stdout stays a clean document.
+	if len(redacted) > 0 {
+		fmt.Fprintln(os.Stderr, helpers.CreateMuted("🔒 Redacted credentials: "+strings.Join(redacted, ", ")))
+	}
 	return nil
 }
+
+// buildExport assembles the shareable view of cfg for the named environments
+// (all when names is empty) and returns the credential fields it redacted.
+func buildExport(cfg *platformconfig.Config, names []string, envRefs bool) (*exportedConfig, []string, error) {
+	if len(names) == 0 {
+		for name := range cfg.ResolvedEnvironments {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	out := &exportedConfig{GlobalSettings: cfg.GlobalSettings, Environments: map[string]exportedEnvironment{}}
+	seen := map[string]bool{}
+	var redacted []string
+	for _, name := range names {
+		env, ok := cfg.ResolvedEnvironments[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("environment %q not found in configuration", name)
+		}
+		e := exportedEnvironment{
+			Type:          env.ResolvedType,
+			Provider:      env.ResolvedProvider,
+			Region:        env.ResolvedRegion,
+			ClusterConfig: env.ResolvedClusterConfig,
+			CoreServices:  env.ResolvedCoreServices,
+			Addons:        env.ResolvedAddons,
+		}
+		if env.ProviderConfig != nil {
+			safe, fields := platformconfig.RedactProvider(env.ResolvedProvider, *env.ProviderConfig, envRefs)
+			e.ProviderSpec = safe.ToProviderMap()
+			for _, f := range fields {
+				key := "providers." + env.ResolvedProvider + "." + f
+				if !seen[key] {
+					seen[key] = true
+					redacted = append(redacted, key)
+				}
+			}
+		}
+		out.Environments[name] = e
+	}
+	sort.Strings(redacted)
+	return out, redacted, nil
+}
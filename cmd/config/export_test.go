@@ -0,0 +1,102 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	platformconfig "adhar-io/adhar/platform/config"
+
+	"sigs.k8s.io/yaml"
+)
+
+func TestBuildExportRedactsCredentials(t *testing.T) {
+	cfg := &platformconfig.Config{
+		Providers: map[string]platformconfig.ConfigProviderConfig{
+			"aws": {
+				Type: "aws", Region: "eu-west-1", Primary: true,
+				AccessKeyID: "AKIA123", SecretAccessKey: "s3cr3t", CredentialsFile: "~/.aws/credentials",
+				Config: map[string]interface{}{
+					"vpc_cidr": "10.0.0.0/16",
+					"registry": map[string]interface{}{"password": "hunter2", "sshKeyPath": "~/.ssh/id"},
+				},
+			},
+		},
+		EnvironmentTemplates: map[string]platformconfig.EnvironmentTemplateConfig{
+			"base": {ClusterConfig: []platformconfig.KeyValueConfig{{Key: "minNodes", Value: "1"}}},
+		},
+		Environments: map[string]platformconfig.EnvironmentConfig{
+			"dev":  {Type: "non-production", Template: "base"},
+			"prod": {Type: "production", Template: "base"},
+		},
+	}
+	if err := cfg.ResolveEnvironments(); err != nil {
+		t.Fatal(err)
+	}
+
+	out, redacted, err := buildExport(cfg, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := yaml.Marshal(out)
+	for _, secret := range []string{"AKIA123", "s3cr3t", "hunter2"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("export leaks %q:\n%s", secret, data)
+		}
+	}
+	for _, ref := range []string{"${AWS_ACCESS_KEY_ID}", "${AWS_SECRET_ACCESS_KEY}", "${ADHAR_AWS_REGISTRY_PASSWORD}", "~/.aws/credentials", "~/.ssh/id"} {
+		if !strings.Contains(string(data), ref) {
+			t.Errorf("export missing %q:\n%s", ref, data)
+		}
+	}
+	if len(redacted) != 3 || redacted[0] != "providers.aws.accessKeyId" {
+		t.Errorf("redacted = %v", redacted)
+	}
+	if cfg.Providers["aws"].AccessKeyID != "AKIA123" {
+		t.Errorf("redaction must not modify the loaded config")
+	}
+
+	masked, _, _ := buildExport(cfg, []string{"prod"}, false)
+	if len(masked.Environments) != 1 || masked.Environments["prod"].ProviderSpec["secretAccessKey"] != platformconfig.RedactedValue {
+		t.Errorf("masked = %+v", masked.Environments["prod"].ProviderSpec)
+	}
+	if _, _, err := buildExport(cfg, []string{"qa"}, true); err == nil {
+		t.Errorf("unknown environment should fail")
+	}
+}
+
+func TestBuiltinTemplates(t *testing.T) {
+	dir := t.TempDir()
+	env := filepath.Join(dir, "environments", "prod")
+	if err := os.MkdirAll(env, 0755); err != nil {
+		t.Fatal(err)
+	}
+	doc := `environment: prod
+type: prod
+packages:
+  - name: vcluster
+    enabled: "true"
+    category: core
+  - name: kyverno
+    enabled: true
+    category: security
+  - name: knative
+    enabled: "false"
+    category: application
+`
+	if err := os.WriteFile(filepath.Join(env, "config.yaml"), []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := builtinTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Name != "prod" || got[0].Type != "prod" ||
+		strings.Join(got[0].CoreServices, ",") != "vcluster" || strings.Join(got[0].Addons, ",") != "kyverno" {
+		t.Errorf("builtinTemplates = %+v", got)
+	}
+	if _, err := builtinTemplates(t.TempDir()); err == nil {
+		t.Errorf("empty stack dir should fail")
+	}
+}
@@ -1,19 +1,217 @@
 package config
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"adhar-io/adhar/cmd/helpers"
+	platformconfig "adhar-io/adhar/platform/config"
 	"adhar-io/adhar/platform/logger"
 
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 )
 
 var listTemplatesCmd = &cobra.Command{
 	Use:   "list-templates",
 	Short: "List available configuration templates",
-	RunE:  runListTemplates,
+	Long: `List environment templates from the configuration file (environmentTemplates)
+and the built-in environment templates shipped in the platform stack
+(platform/stack/environments), with the core services and addons each enables.
+
+Examples:
+  adhar config list-templates
+  adhar config list-templates --output=json
+  adhar config list-templates --stack-dir=/opt/adhar/platform/stack`,
+	RunE: runListTemplates,
+}
+
+var (
+	listTemplatesOutput   string
+	listTemplatesStackDir string
+)
+
+func init() {
+	listTemplatesCmd.Flags().StringVarP(&listTemplatesOutput, "output", "o", "table", "Output format (table, json, yaml)")
+	listTemplatesCmd.Flags().StringVar(&listTemplatesStackDir, "stack-dir", "platform/stack", "Path to the platform stack directory")
+}
+
+// templateInfo describes one template in the catalogue.
+type templateInfo struct {
+	Name         string   `json:"name"`
+	Source       string   `json:"source"`
+	Type         string   `json:"type,omitempty"`
+	CoreServices []string `json:"coreServices"`
+	Addons       []string `json:"addons"`
+	UsedBy       []string `json:"usedBy,omitempty"`
+}
+
+// builtinEnvironment is the shape of platform/stack/environments/*/config.yaml.
+type builtinEnvironment struct {
+	Environment string `json:"environment"`
+	Type        string `json:"type"`
+	Packages    []struct {
+		Name     string      `json:"name"`
+		Enabled  interface{} `json:"enabled"`
+		Category string      `json:"category"`
+	} `json:"packages"`
 }
 
 func runListTemplates(cmd *cobra.Command, args []string) error {
-	logger.Info("📋 Listing available configuration templates...")
-	// TODO: Implement template listing
-	return nil
+	if listTemplatesOutput == "table" {
+		logger.Info("📋 Listing available configuration templates...")
+	}
+
+	var templates []templateInfo
+	path := resolveConfigPath()
+	if cfg, err := platformconfig.LoadConfig(path); err != nil {
+		fmt.Fprintln(os.Stderr, helpers.CreateWarning("Skipping config templates: "+err.Error()))
+	} else {
+		templates = append(templates, configTemplates(cfg, path)...)
+	}
+
+	builtin, err := builtinTemplates(listTemplatesStackDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, helpers.CreateWarning("Skipping built-in templates: "+err.Error()))
+	}
+	templates = append(templates, builtin...)
+
+	switch listTemplatesOutput {
+	case "json":
+		return helpers.PrintJSON(templates)
+	case "yaml":
+		return helpers.PrintYAML(templates)
+	case "table":
+	default:
+		return fmt.Errorf("unsupported output format %q (table, json, yaml)", listTemplatesOutput)
+	}
+
+	if len(templates) == 0 {
+		fmt.Println(helpers.CreateWarning("No templates found"))
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSOURCE\tTYPE\tCORE SERVICES\tADDONS\tUSED BY")
+	for _, t := range templates {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", t.Name, t.Source, dash(t.Type),
+			dash(strings.Join(t.CoreServices, ", ")), summarizeList(t.Addons, 6), dash(strings.Join(t.UsedBy, ", ")))
+	}
+	return w.Flush()
+}
+
+// configTemplates lists environmentTemplates from the loaded config along
+// with the environments that reference each one.
+func configTemplates(cfg *platformconfig.Config, path string) []templateInfo {
+	usedBy := map[string][]string{}
+	for name, env := range cfg.Environments {
+		usedBy[env.Template] = append(usedBy[env.Template], name)
+	}
+	names := make([]string, 0, len(cfg.EnvironmentTemplates))
+	for name := range cfg.EnvironmentTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out []templateInfo
+	for _, name := range names {
+		t := cfg.EnvironmentTemplates[name]
+		info := templateInfo{Name: name, Source: path, CoreServices: []string{}, Addons: []string{}}
+		for svc, sc := range t.CoreServices {
+			info.CoreServices = append(info.CoreServices, chartLabel(svc, sc.Chart.Version))
+		}
+		for _, a := range t.Addons {
+			info.Addons = append(info.Addons, chartLabel(a.Name, a.Chart.Version))
+		}
+		sort.Strings(info.CoreServices)
+		info.UsedBy = usedBy[name]
+		sort.Strings(info.UsedBy)
+		out = append(out, info)
+	}
+	return out
+}
+
+// builtinTemplates reads <stackDir>/environments/*/config.yaml. Packages in
+// the core category are reported as core services, the rest as addons.
+func builtinTemplates(stackDir string) ([]templateInfo, error) {
+	files, err := filepath.Glob(filepath.Join(stackDir, "environments", "*", "config.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no environments found under %s", filepath.Join(stackDir, "environments"))
+	}
+	sort.Strings(files)
+
+	var out []templateInfo
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return out, err
+		}
+		var env builtinEnvironment
+		if err := yaml.Unmarshal(data, &env); err != nil {
+			return out, fmt.Errorf("parse %s: %w", file, err)
+		}
+		info := templateInfo{
+			Name:         filepath.Base(filepath.Dir(file)),
+			Source:       "built-in",
+			Type:         env.Type,
+			CoreServices: []string{},
+			Addons:       []string{},
+		}
+		for _, p := range env.Packages {
+			if !isEnabled(p.Enabled) {
+				continue
+			}
+			if p.Category == "core" {
+				info.CoreServices = append(info.CoreServices, p.Name)
+			} else {
+				info.Addons = append(info.Addons, p.Name)
+			}
+		}
+		sort.Strings(info.CoreServices)
+		sort.Strings(info.Addons)
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+// isEnabled accepts both `enabled: true` and the quoted `enabled: "true"`
+// used by the generated environment files.
+func isEnabled(v interface{}) bool {
+	switch b := v.(type) {
+	case bool:
+		return b
+	case string:
+		return strings.EqualFold(b, "true")
+	}
+	return false
+}
+
+func chartLabel(name, version string) string {
+	if version == "" {
+		return name
+	}
+	return name + "@" + version
+}
+
+func summarizeList(items []string, max int) string {
+	if len(items) == 0 {
+		return "-"
+	}
+	if len(items) <= max {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s (+%d more)", strings.Join(items[:max], ", "), len(items)-max)
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
 }
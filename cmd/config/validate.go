@@ -1,6 +1,12 @@
 package config
 
 import (
+	"fmt"
+	"os"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/globals"
+	platformconfig "adhar-io/adhar/platform/config"
 	"adhar-io/adhar/platform/logger"
 
 	"github.com/spf13/cobra"
@@ -9,13 +15,126 @@ import (
 var validateCmd = &cobra.Command{
 	Use:   "validate [config-file]",
 	Short: "Validate configuration files",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runValidate,
+	Long: `Validate a config.yaml against config.schema.json and the platform's
+semantic rules, reporting every problem at once with its line and column.
+
+Checks:
+  • JSON Schema (types, required fields, unknown keys)
+  • Global settings, provider and environment rules
+  • Template and provider references resolve
+  • Exactly one primary provider
+  • CIDRs in cluster config do not overlap between environments
+  • Chart versions exist in the local Helm repository cache (offline)
+
+Examples:
+  adhar config validate
+  adhar config validate ./config.yaml --output=json
+  adhar config validate config.yaml --output=sarif > config.sarif`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runValidate,
+}
+
+var (
+	validateOutput     string
+	validateSchema     string
+	validateSkipCharts bool
+	validateHelmCache  string
+)
+
+func init() {
+	validateCmd.Flags().StringVarP(&validateOutput, "output", "o", "text", "Output format (text, json, sarif)")
+	validateCmd.Flags().StringVar(&validateSchema, "schema", "", "JSON Schema to validate against (defaults to the built-in config.schema.json)")
+	validateCmd.Flags().BoolVar(&validateSkipCharts, "skip-chart-check", false, "Skip checking chart versions against the Helm cache")
+	validateCmd.Flags().StringVar(&validateHelmCache, "helm-cache", "", "Helm repository cache directory (defaults to Helm's)")
 }
 
 func runValidate(cmd *cobra.Command, args []string) error {
-	configFile := args[0]
-	logger.Info("✅ Validating configuration file: " + configFile)
-	// TODO: Implement configuration validation
+	path := resolveConfigPath()
+	if len(args) > 0 {
+		path = args[0]
+	}
+
+	opts := platformconfig.LintOptions{
+		SkipCharts: validateSkipCharts,
+		ChartCache: platformconfig.HelmCache{RepositoryCache: validateHelmCache},
+	}
+	if validateSchema != "" {
+		schema, err := os.ReadFile(validateSchema)
+		if err != nil {
+			return fmt.Errorf("read schema: %w", err)
+		}
+		opts.Schema = schema
+	}
+
+	if validateOutput == "text" {
+		logger.Info("✅ Validating configuration file: " + path)
+	}
+	diags, err := platformconfig.ValidateFile(path, opts)
+	if err != nil {
+		return fmt.Errorf("validate %s: %w", path, err)
+	}
+
+	switch validateOutput {
+	case "json":
+		if err := helpers.PrintJSON(map[string]interface{}{
+			"file":        path,
+			"valid":       !platformconfig.HasErrors(diags),
+			"diagnostics": diags,
+		}); err != nil {
+			return err
+		}
+	case "sarif":
+		results := make([]helpers.SARIFResult, 0, len(diags))
+		for _, d := range diags {
+			results = append(results, helpers.SARIFResult{
+				RuleID: "config/" + d.Rule, Level: d.Severity, Message: diagnosticMessage(d),
+				URI: path, Line: d.Line, Column: d.Column,
+			})
+		}
+		if err := helpers.WriteSARIF(os.Stdout, "adhar-config-validate", globals.Version, "https://adhar.io", nil, results); err != nil {
+			return err
+		}
+	case "text":
+		printDiagnostics(path, diags)
+	default:
+		return fmt.Errorf("unsupported output format %q (text, json, sarif)", validateOutput)
+	}
+
+	if platformconfig.HasErrors(diags) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%s is invalid", path)
+	}
 	return nil
 }
+
+func diagnosticMessage(d platformconfig.Diagnostic) string {
+	if d.Path == "" {
+		return d.Message
+	}
+	return d.Path + ": " + d.Message
+}
+
+func printDiagnostics(path string, diags []platformconfig.Diagnostic) {
+	errors, warnings := 0, 0
+	for _, d := range diags {
+		line := fmt.Sprintf("%s:%d:%d: %s [%s]", path, d.Line, d.Column, diagnosticMessage(d), d.Rule)
+		if d.Line == 0 {
+			line = fmt.Sprintf("%s: %s [%s]", path, diagnosticMessage(d), d.Rule)
+		}
+		if d.Severity == platformconfig.SeverityError {
+			errors++
+			fmt.Println(helpers.ErrorStyle.Render("❌ " + line))
+		} else {
+			warnings++
+			fmt.Println(helpers.CreateWarning("⚠️  " + line))
+		}
+	}
+	switch {
+	case errors > 0:
+		fmt.Println(helpers.ErrorStyle.Render(fmt.Sprintf("\n%d error(s), %d warning(s)", errors, warnings)))
+	case warnings > 0:
+		fmt.Println(helpers.CreateSuccess(fmt.Sprintf("Configuration is valid with %d warning(s)", warnings)))
+	default:
+		fmt.Println(helpers.CreateSuccess("Configuration is valid"))
+	}
+}
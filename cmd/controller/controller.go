@@ -26,12 +26,14 @@ import (
 	"fmt"
 	stdlog "log"
 	"os"
+	"path/filepath"
 	"time"
 
 	"adhar-io/adhar/api/v1alpha1"
 	"adhar-io/adhar/globals"
 	"adhar-io/adhar/platform/controllers"
 	"adhar-io/adhar/platform/k8s"
+	"adhar-io/adhar/platform/webhooks"
 
 	"github.com/go-logr/stdr"
 	"github.com/spf13/cobra"
@@ -45,6 +47,7 @@ import (
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 var (
@@ -54,6 +57,11 @@ var (
 	stackDir           string
 	platformName       string
 	namespace          string
+	enableWebhooks     bool
+	webhookPort        int
+	webhookCertDir     string
+	webhookCertMode    string
+	webhookService     string
 )
 
 // ControllerCmd represents the controller command
@@ -69,7 +77,15 @@ keeps reconciling continuously after the CLI exits.
 
 The manager reads its build configuration (host, port, TLS settings) from the
 existing AdharPlatform resource. GitOps repository seeding is a bootstrap
-(CLI) concern: this mode reconciles an already-bootstrapped platform.`,
+(CLI) concern: this mode reconciles an already-bootstrapped platform.
+
+With --enable-webhooks the manager also serves validating admission webhooks
+for the platform XRs (CompositeCluster, CompositeDatabase, CompositeNetwork,
+CompositeApplication, CompositeEnvironment) and the DataPlane and
+AdharPlatform resources, plus defaulting webhooks for DataPlane and
+AdharPlatform, and installs the webhook configurations that route them to its
+Service. The serving certificate is self-signed or issued
+by cert-manager (--webhook-cert-mode).`,
 	RunE:         runController,
 	SilenceUsage: true,
 }
@@ -81,6 +97,11 @@ func init() {
 	ControllerCmd.Flags().StringVar(&stackDir, "stack-dir", "", "Optional path to a platform stack directory for GitOps repo seeding (bootstrap is normally CLI-owned)")
 	ControllerCmd.Flags().StringVar(&platformName, "platform-name", globals.DefaultClusterName, "Name of the AdharPlatform resource to reconcile")
 	ControllerCmd.Flags().StringVar(&namespace, "namespace", globals.AdharSystemNamespace, "Namespace of the AdharPlatform resource and leader-election lease")
+	ControllerCmd.Flags().BoolVar(&enableWebhooks, "enable-webhooks", false, "Serve the platform validating admission webhooks")
+	ControllerCmd.Flags().IntVar(&webhookPort, "webhook-port", 9443, "Port the webhook server listens on")
+	ControllerCmd.Flags().StringVar(&webhookCertDir, "webhook-cert-dir", filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs"), "Directory the webhook serving certificate is written to")
+	ControllerCmd.Flags().StringVar(&webhookCertMode, "webhook-cert-mode", webhooks.CertModeSelfSigned, "How the webhook serving certificate is issued: self-signed or cert-manager")
+	ControllerCmd.Flags().StringVar(&webhookService, "webhook-service", webhooks.ServiceName, "Service in --namespace that routes to the webhook server")
 }
 
 func runController(cmd *cobra.Command, args []string) error {
@@ -94,8 +115,30 @@ func runController(cmd *cobra.Command, args []string) error {
 	}
 	scheme := k8s.GetScheme()
 
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	// The serving certificate has to be on disk before the webhook server
+	// starts, so it is settled with a direct client ahead of the manager.
+	var caBundle []byte
+	var webhookClient client.Client
+	if enableWebhooks {
+		webhookClient, err = client.New(cfg, client.Options{Scheme: scheme})
+		if err != nil {
+			return fmt.Errorf("creating kubernetes client: %w", err)
+		}
+		caBundle, err = webhooks.EnsureServingCertificate(ctx, webhookClient, webhookCertMode, namespace, webhookService, webhookCertDir)
+		if err != nil {
+			return err
+		}
+	}
+
 	mgr, err := manager.New(cfg, manager.Options{
 		Scheme: scheme,
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    webhookPort,
+			CertDir: webhookCertDir,
+		}),
 		Metrics: metricsserver.Options{
 			BindAddress: metricsBindAddress,
 		},
@@ -117,9 +160,17 @@ func runController(cmd *cobra.Command, args []string) error {
 	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
 		return fmt.Errorf("adding readyz check: %w", err)
 	}
-
-	ctx, cancel := context.WithCancel(cmd.Context())
-	defer cancel()
+	if enableWebhooks {
+		if err := webhooks.SetupWebhooks(mgr); err != nil {
+			return fmt.Errorf("setting up webhooks: %w", err)
+		}
+		if err := mgr.AddReadyzCheck("webhook", mgr.GetWebhookServer().StartedChecker()); err != nil {
+			return fmt.Errorf("adding webhook readyz check: %w", err)
+		}
+		if err := webhooks.InstallConfigurations(ctx, webhookClient, namespace, webhookService, caBundle); err != nil {
+			return err
+		}
+	}
 
 	// The reconcilers take the build customization (host/port/TLS) as static
 	// config; in-cluster it comes from the AdharPlatform resource the CLI
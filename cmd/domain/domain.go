@@ -0,0 +1,54 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the file at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package domain
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// DomainCmd represents the domain command
+var DomainCmd = &cobra.Command{
+	Use:   "domain",
+	Short: "Verify platform domain, DNS and certificate issuance",
+	Long: `Inspect the platform domain set up by adhar.
+
+The DNS provider publishing public records and answering ACME DNS-01
+challenges is selected per environment in config.yaml:
+
+  environments:
+    production:
+      dns:
+        provider: route53          # route53, clouddns, azuredns, digitalocean, cloudflare, rfc2136
+        zone: example.com
+        credentialsSecret: aws-credentials
+        config:
+          region: us-east-1
+        splitHorizon:
+          enabled: true            # serve the domain from CoreDNS inside the cluster
+          internalZone: corp.internal
+
+Examples:
+  adhar domain verify                       # Verify the cluster's domain setup
+  adhar domain verify --env=production      # Verify against config.yaml settings`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Help()
+	},
+}
+
+func init() {
+	DomainCmd.AddCommand(verifyCmd)
+}
@@ -0,0 +1,308 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
+	platformconfig "adhar-io/adhar/platform/config"
+	platformdomain "adhar-io/adhar/platform/domain"
+	provider "adhar-io/adhar/platform/providers"
+	"adhar-io/adhar/platform/types"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [DOMAIN]",
+	Short: "Check domain resolution and ACME DNS-01 readiness",
+	Long: `Check that the platform domain resolves and that cert-manager can answer
+ACME DNS-01 challenges through the configured DNS provider.
+
+Checks:
+• The domain and any --host names resolve
+• The provider settings are complete and the zone is delegated (NS records)
+• _acme-challenge is not a dangling CNAME and holds no stale TXT records
+• The credentials Secret exists in kube-system (external-dns) and cert-manager
+• The Let's Encrypt ClusterIssuer has the provider's DNS-01 solver and is ready
+• With split-horizon, a name in each internal zone resolves to the internal
+  target through the cluster DNS Service (reached through a port-forward),
+  and the internal zone does not resolve publicly
+
+Settings are read from the cluster (recorded at setup), or from config.yaml
+with --env. In-cluster checks are skipped when the cluster is unreachable.
+
+Examples:
+  adhar domain verify
+  adhar domain verify --env=production
+  adhar domain verify example.com --host=argocd.example.com --resolver=1.1.1.1:53
+  adhar domain verify -o json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runVerify,
+}
+
+var (
+	verifyEnv      string
+	verifyConfig   string
+	verifyHosts    []string
+	verifyResolver string
+	output         string
+)
+
+func init() {
+	verifyCmd.Flags().StringVar(&verifyEnv, "env", "", "Read DNS settings from this config.yaml environment instead of the cluster")
+	verifyCmd.Flags().StringVarP(&verifyConfig, "config", "c", "", "Path to config.yaml (used with --env)")
+	verifyCmd.Flags().StringSliceVar(&verifyHosts, "host", nil, "Additional hostnames that must resolve (repeatable)")
+	verifyCmd.Flags().StringVar(&verifyResolver, "resolver", "", "DNS server (host:port) for public lookups; defaults to the system resolver")
+	verifyCmd.Flags().StringVarP(&output, "output", "o", "table", "Output format (table, json)")
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	if output != "table" && output != "json" {
+		return fmt.Errorf("unsupported output format %q (use table or json)", output)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	c, domainName, cfg := clusterDomain(ctx)
+	if verifyEnv != "" {
+		var err error
+		if domainName, cfg, err = environmentDomain(verifyEnv); err != nil {
+			return err
+		}
+	}
+	if len(args) == 1 {
+		domainName = args[0]
+	}
+	if domainName == "" {
+		return fmt.Errorf("no domain configured in the cluster; pass it as an argument or use --env")
+	}
+	if cfg == nil {
+		cfg = &types.DomainConfig{}
+	}
+
+	opts := platformdomain.VerifyOptions{
+		Domain:         domainName,
+		Hosts:          verifyHosts,
+		DNS:            cfg.DNS,
+		TLSEnvironment: cfg.TLS.Environment,
+	}
+	if verifyResolver != "" {
+		if _, _, err := net.SplitHostPort(verifyResolver); err != nil {
+			return fmt.Errorf("invalid --resolver %q: %w", verifyResolver, err)
+		}
+		opts.Resolver = resolverFor(verifyResolver)
+	}
+
+	if c != nil && cfg.DNS.SplitHorizon.Enabled {
+		r, stop, err := clusterResolver(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, helpers.CreateWarning(fmt.Sprintf("Cannot reach the cluster DNS Service: %v", err)))
+		} else {
+			defer stop()
+			opts.ClusterResolver = r
+		}
+	}
+
+	checks := platformdomain.Verify(ctx, c, opts)
+	if output == "json" {
+		if err := helpers.PrintJSON(checks); err != nil {
+			return err
+		}
+	} else {
+		printChecks(domainName, checks)
+	}
+	if platformdomain.Failed(checks) {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("domain verification failed for %s", domainName)
+	}
+	return nil
+}
+
+// clusterDomain connects to the cluster and reads its recorded domain setup.
+// The client is nil when the cluster cannot be reached.
+func clusterDomain(ctx context.Context) (client.Client, string, *types.DomainConfig) {
+	conf, err := helpers.GetKubeConfig()
+	if err != nil {
+		return nil, "", nil
+	}
+	c, err := helpers.GetKubeClient(conf)
+	if err != nil {
+		return nil, "", nil
+	}
+	name, cfg, err := platformdomain.ClusterDomainConfig(ctx, c)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return c, "", nil
+		}
+		return nil, "", nil
+	}
+	return c, name, cfg
+}
+
+// environmentDomain builds the domain configuration of a config.yaml environment.
+func environmentDomain(env string) (string, *types.DomainConfig, error) {
+	path := verifyConfig
+	if path == "" {
+		path = platformconfig.GetConfigPath()
+	}
+	cfg, err := platformconfig.LoadConfig(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.ResolveEnvironments(); err != nil {
+		return "", nil, fmt.Errorf("failed to resolve environments: %w", err)
+	}
+	resolved, ok := cfg.ResolvedEnvironments[env]
+	if !ok {
+		return "", nil, fmt.Errorf("environment %q not found in %s", env, path)
+	}
+	domainConfig := provider.BuildDomainConfig(resolved)
+	return domainConfig.BaseDomain, domainConfig, nil
+}
+
+// resolverFor returns a resolver that sends every query to addr.
+func resolverFor(addr string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// clusterResolver port-forwards to a pod behind the cluster DNS Service,
+// using the same kubeconfig as the rest of adhar, and returns a resolver
+// querying it over TCP, and a function stopping the forward.
+func clusterResolver(ctx context.Context) (*net.Resolver, func(), error) {
+	conf, err := helpers.GetKubeConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	cs, err := kubernetes.NewForConfig(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+	pod, err := dnsPod(ctx, cs)
+	if err != nil {
+		return nil, nil, err
+	}
+	transport, upgrader, err := spdy.RoundTripperFor(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+	url := cs.CoreV1().RESTClient().Post().Resource("pods").Namespace(pod.Namespace).Name(pod.Name).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	stopCh, readyCh := make(chan struct{}), make(chan struct{})
+	fw, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{"0:53"}, stopCh, readyCh, io.Discard, io.Discard)
+	if err != nil {
+		return nil, nil, err
+	}
+	done := make(chan error, 1)
+	go func() { done <- fw.ForwardPorts() }()
+	stop := sync.OnceFunc(func() { close(stopCh) })
+
+	select {
+	case <-readyCh:
+	case err := <-done:
+		stop()
+		return nil, nil, fmt.Errorf("port-forward to kube-system/%s failed: %w", pod.Name, err)
+	case <-ctx.Done():
+		stop()
+		return nil, nil, ctx.Err()
+	}
+	ports, err := fw.GetPorts()
+	if err != nil || len(ports) == 0 {
+		stop()
+		return nil, nil, fmt.Errorf("port-forward to kube-system/%s has no local port: %v", pod.Name, err)
+	}
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(int(ports[0].Local)))
+
+	// port-forward only carries TCP; the Go resolver speaks DNS over TCP
+	// when the connection is a stream
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "tcp", addr)
+		},
+	}, stop, nil
+}
+
+// dnsPod picks a running, ready pod selected by the kube-system/kube-dns
+// Service.
+func dnsPod(ctx context.Context, cs kubernetes.Interface) (*corev1.Pod, error) {
+	svc, err := cs.CoreV1().Services("kube-system").Get(ctx, "kube-dns", metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the cluster DNS Service: %w", err)
+	}
+	selector := labels.SelectorFromSet(svc.Spec.Selector).String()
+	pods, err := cs.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster DNS pods: %w", err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, c := range pod.Status.Conditions {
+			if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+				return pod, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no ready pod behind kube-system/kube-dns (selector %s)", selector)
+}
+
+func printChecks(domainName string, checks []platformdomain.Check) {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("Domain: %s\n\n", domainName))
+	for _, c := range checks {
+		b.WriteString(fmt.Sprintf("%s %-40s %s\n", statusIcon(c.Status), c.Name, c.Message))
+	}
+	fmt.Println(helpers.BorderStyle.Render(strings.TrimRight(b.String(), "\n")))
+
+	counts := map[platformdomain.CheckStatus]int{}
+	for _, c := range checks {
+		counts[c.Status]++
+	}
+	switch {
+	case counts[platformdomain.CheckFail] > 0:
+		fmt.Println(helpers.CreateError(fmt.Sprintf("%d check(s) failed", counts[platformdomain.CheckFail])))
+	case counts[platformdomain.CheckWarn] > 0:
+		fmt.Println(helpers.CreateWarning(fmt.Sprintf("%d warning(s)", counts[platformdomain.CheckWarn])))
+	default:
+		fmt.Println(helpers.CreateSuccess("Domain is ready for DNS-01 certificate issuance"))
+	}
+}
+
+func statusIcon(s platformdomain.CheckStatus) string {
+	switch s {
+	case platformdomain.CheckPass:
+		return "✅"
+	case platformdomain.CheckWarn:
+		return "⚠️ "
+	case platformdomain.CheckFail:
+		return "❌"
+	}
+	return "⏭️ "
+}
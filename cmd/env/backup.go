@@ -1,31 +1,250 @@
 package env
 
 import (
+	"context"
 	"fmt"
+	"time"
 
-	"adhar-io/adhar/platform/logger"
+	"adhar-io/adhar/cmd/helpers"
 
 	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	backupTTL             time.Duration
+	backupStorageLocation string
+	backupWait            bool
+	backupTimeout         time.Duration
+	backupSkipDatabases   bool
 )
 
 var backupCmd = &cobra.Command{
 	Use:   "backup [environment-name]",
 	Short: "Backup environment",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runBackup,
+	Long: `Snapshot an environment with Velero. The snapshot covers every namespace the
+environment owns (its own namespace plus namespaces labelled
+` + "`adhar.io/environment=<env>`" + `), the CompositeEnvironment spec, the ArgoCD
+Applications that deploy into those namespaces and the environment's
+databases. CloudNativePG clusters with an object store get a fresh CNPG
+backup so they can be recovered consistently; other volumes are captured by
+Velero volume snapshots.
+
+Restore the snapshot with ` + "`adhar env restore`" + `, optionally under a new name.
+
+Examples:
+  adhar env backup staging
+  adhar env backup staging --ttl=72h --storage-location=offsite
+  adhar env backup staging --wait=false`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBackup,
+}
+
+func init() {
+	backupCmd.Flags().DurationVar(&backupTTL, "ttl", 30*24*time.Hour, "How long Velero keeps the backup")
+	backupCmd.Flags().StringVar(&backupStorageLocation, "storage-location", "", "Velero BackupStorageLocation (default: the default location)")
+	backupCmd.Flags().BoolVar(&backupWait, "wait", true, "Wait for the backup to complete")
+	backupCmd.Flags().DurationVar(&backupTimeout, "timeout", 30*time.Minute, "Maximum time to wait for database and Velero backups")
+	backupCmd.Flags().BoolVar(&backupSkipDatabases, "skip-databases", false, "Do not take CNPG backups; rely on volume snapshots only")
 }
 
 func runBackup(cmd *cobra.Command, args []string) error {
 	envName := args[0]
-	logger.Info(fmt.Sprintf("💾 Creating backup for environment: %s", envName))
 
-	// TODO: Implement environment backup
-	// This should:
-	// - Backup Kubernetes resources
-	// - Backup persistent data
-	// - Backup configurations
-	// - Create backup metadata
+	clientset, err := getClientset()
+	if err != nil {
+		return unreachable(err)
+	}
+	dyn, err := getDynamicClient()
+	if err != nil {
+		return unreachable(err)
+	}
+	ctx := context.Background()
+
+	fmt.Println(helpers.TitleStyle.Render(fmt.Sprintf("💾 Backing up environment %s", envName)))
+
+	namespaces, err := ownedNamespaces(ctx, clientset, envName)
+	if err != nil {
+		return err
+	}
+	stamp := time.Now().UTC().Format("20060102-150405")
+	snap := &envSnapshot{Environment: envName, CreatedAt: time.Now().UTC(), Namespaces: namespaces}
+	if ns, err := clientset.CoreV1().Namespaces().Get(ctx, envName, metav1.GetOptions{}); err == nil {
+		snap.Tier = ns.Labels["adhar.io/tier"]
+	} else {
+		return fmt.Errorf("environment namespace %q not found: %w", envName, err)
+	}
+	fmt.Printf("📦 Namespaces: %v\n", namespaces)
+
+	xr, err := dyn.Resource(compositeEnvironmentGVR).Namespace(envName).Get(ctx, envName, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		snap.Spec, _, _ = unstructured.NestedMap(xr.Object, "spec")
+		fmt.Println(helpers.CreateSuccess("✅ CompositeEnvironment spec captured"))
+	case k8serrors.IsNotFound(err) || crdMissing(err):
+		fmt.Println(helpers.CreateMuted("   No CompositeEnvironment XR; the environment is a plain namespace"))
+	default:
+		return fmt.Errorf("failed to get CompositeEnvironment %q: %w", envName, err)
+	}
+
+	apps, err := environmentApplications(ctx, dyn, namespaces)
+	if err != nil {
+		return err
+	}
+	for _, app := range apps {
+		snap.Applications = append(snap.Applications, cleanObject(app.Object))
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ %d ArgoCD application(s) captured", len(apps))))
+
+	dbs, err := snapshotDatabases(ctx, dyn, namespaces, stamp)
+	if err != nil {
+		return err
+	}
+	snap.Databases = dbs
+
+	manifest := snapshotConfigMapPrefix + stamp
+	if err := writeSnapshot(ctx, dyn, manifest, snap); err != nil {
+		return err
+	}
 
-	logger.Info("✅ Environment backup created successfully")
+	name := fmt.Sprintf("env-%s-%s", truncate(envName, 40), stamp)
+	spec := map[string]interface{}{
+		"includedNamespaces": toInterfaceSlice(namespaces),
+		"snapshotVolumes":    true,
+		"ttl":                backupTTL.String(),
+	}
+	if backupStorageLocation != "" {
+		spec["storageLocation"] = backupStorageLocation
+	}
+	backup := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "velero.io/v1",
+		"kind":       "Backup",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": veleroNamespace,
+			"labels": map[string]interface{}{
+				envLabel:              envName,
+				"adhar.io/managed-by": "adhar-cli",
+			},
+			"annotations": map[string]interface{}{snapshotAnnotation: manifest},
+		},
+		"spec": spec,
+	}}
+	if _, err := dyn.Resource(veleroBackupGVR).Namespace(veleroNamespace).Create(ctx, backup, metav1.CreateOptions{}); err != nil {
+		if crdMissing(err) {
+			return fmt.Errorf("Velero Backup CRD not installed (velero not present in the cluster)")
+		}
+		return fmt.Errorf("failed to create backup %q: %w", name, err)
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Backup %q created", name)))
+
+	if !backupWait {
+		fmt.Println(helpers.CreateMuted("   Track progress with: adhar backup list"))
+		return nil
+	}
+	fmt.Println(helpers.CreateMuted("   Waiting for Velero to finish..."))
+	if err := waitForVelero(ctx, dyn, veleroBackupGVR, name, backupTimeout, false); err != nil {
+		return err
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Environment %s backed up to %s", envName, name)))
+	fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Clone it with: adhar env restore <new-env> %s", name)))
 	return nil
 }
+
+// snapshotDatabases records the environment's databases and takes an
+// on-demand CNPG backup of every cluster that archives to an object store.
+func snapshotDatabases(ctx context.Context, dyn dynamic.Interface, namespaces []string, stamp string) ([]envDatabase, error) {
+	var out []envDatabase
+	for _, ns := range namespaces {
+		xrs, err := dyn.Resource(compositeDatabaseGVR).Namespace(ns).List(ctx, metav1.ListOptions{})
+		if err != nil && !crdMissing(err) {
+			return nil, fmt.Errorf("failed to list CompositeDatabases in %s: %w", ns, err)
+		}
+		if err == nil {
+			for _, xr := range xrs.Items {
+				out = append(out, envDatabase{Namespace: ns, Name: xr.GetName(), Kind: "composite"})
+				fmt.Println(helpers.CreateMuted(fmt.Sprintf("   CompositeDatabase %s/%s: spec only, data stays with the provider", ns, xr.GetName())))
+			}
+		}
+
+		clusters, err := dyn.Resource(cnpgClusterGVR).Namespace(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			if crdMissing(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list CNPG clusters in %s: %w", ns, err)
+		}
+		for _, c := range clusters.Items {
+			db := envDatabase{Namespace: ns, Name: c.GetName(), Kind: "cnpg"}
+			_, db.Archiving, _ = unstructured.NestedMap(c.Object, "spec", "backup", "barmanObjectStore")
+			if db.Archiving && !backupSkipDatabases {
+				db.Backup = truncate(c.GetName(), 40) + "-" + stamp
+				if err := takeCNPGBackup(ctx, dyn, ns, c.GetName(), db.Backup); err != nil {
+					return nil, err
+				}
+				fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Database %s/%s backed up (%s)", ns, c.GetName(), db.Backup)))
+			} else {
+				fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Database %s/%s: captured by volume snapshots only", ns, c.GetName())))
+			}
+			out = append(out, db)
+		}
+	}
+	return out, nil
+}
+
+// takeCNPGBackup creates an on-demand CNPG Backup and waits for it.
+func takeCNPGBackup(ctx context.Context, dyn dynamic.Interface, ns, cluster, name string) error {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "postgresql.cnpg.io/v1",
+		"kind":       "Backup",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": ns,
+			"labels":    map[string]interface{}{"adhar.io/managed-by": "adhar-cli"},
+		},
+		"spec": map[string]interface{}{
+			"cluster": map[string]interface{}{"name": cluster},
+		},
+	}}
+	res := dyn.Resource(cnpgBackupGVR).Namespace(ns)
+	if _, err := res.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create CNPG backup of %s/%s: %w", ns, cluster, err)
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, backupTimeout)
+	defer cancel()
+	for {
+		got, err := res.Get(cctx, name, metav1.GetOptions{})
+		if err == nil {
+			switch nestedString(got.Object, "status", "phase") {
+			case "completed":
+				return nil
+			case "failed":
+				return fmt.Errorf("CNPG backup %s/%s failed: %s", ns, name, nestedString(got.Object, "status", "error"))
+			}
+		}
+		select {
+		case <-cctx.Done():
+			return fmt.Errorf("timed out waiting for CNPG backup %s/%s", ns, name)
+		case <-time.After(envPollInterval):
+		}
+	}
+}
+
+func toInterfaceSlice(in []string) []interface{} {
+	out := make([]interface{}, len(in))
+	for i, s := range in {
+		out[i] = s
+	}
+	return out
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
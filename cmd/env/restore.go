@@ -1,33 +1,500 @@
 package env
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"time"
 
-	"adhar-io/adhar/platform/logger"
+	"adhar-io/adhar/cmd/helpers"
 
 	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	restoreHosts          map[string]string
+	restoreTier           string
+	restoreDryRun         bool
+	restoreOverwrite      bool
+	restoreKeepSecretRefs bool
+	restoreTimeout        time.Duration
 )
 
 var restoreCmd = &cobra.Command{
 	Use:   "restore [environment-name] [backup-name]",
 	Short: "Restore environment from backup",
-	Args:  cobra.ExactArgs(2),
-	RunE:  runRestore,
+	Long: `Restore an environment from a snapshot taken with ` + "`adhar env backup`" + `.
+
+When environment-name matches the environment that was backed up the snapshot
+is restored in place; objects that still exist are left alone unless
+--overwrite is set. Any other name clones the environment: every namespace is
+restored under a new name (the environment name is replaced wherever it
+appears as a whole token, otherwise the new name is appended), the
+CompositeEnvironment and ArgoCD Applications are recreated for the new
+environment, and CloudNativePG clusters recover from the snapshot's CNPG
+backups into a WAL archive of their own.
+
+While cloning, hostnames that embed the environment name are rewritten in
+Ingresses, HTTPRoutes, Certificates, ConfigMaps, container env vars and Helm
+values (api.staging.example.com → api.pr-42.example.com), in-cluster service
+addresses follow the namespace mapping, and ExternalSecret keys are renamed
+the same way. TLS secret names are kept: the Secrets are restored under their
+original names and cert-manager reissues them for the new hostnames. Use
+--host for hosts that do not contain the environment name.
+
+Examples:
+  adhar env restore staging env-staging-20260101-020000
+  adhar env restore pr-42 env-staging-20260101-020000 --tier=dev
+  adhar env restore pr-42 env-staging-20260101-020000 --host=staging.example.com=pr-42.preview.example.com
+  adhar env restore pr-42 env-staging-20260101-020000 --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: runRestore,
+}
+
+func init() {
+	restoreCmd.Flags().StringToStringVar(&restoreHosts, "host", nil, "Explicit hostname rewrite old=new (repeatable)")
+	restoreCmd.Flags().StringVar(&restoreTier, "tier", "", "Tier of the restored environment (default: the snapshot's tier)")
+	restoreCmd.Flags().BoolVar(&restoreDryRun, "dry-run", false, "Show the restore plan without applying it")
+	restoreCmd.Flags().BoolVar(&restoreOverwrite, "overwrite", false, "Update objects that already exist from the snapshot")
+	restoreCmd.Flags().BoolVar(&restoreKeepSecretRefs, "keep-secret-refs", false, "Keep ExternalSecret keys pointing at the source environment's secrets")
+	restoreCmd.Flags().DurationVar(&restoreTimeout, "timeout", 30*time.Minute, "Maximum time to wait for each Velero restore")
 }
 
 func runRestore(cmd *cobra.Command, args []string) error {
-	envName := args[0]
-	backupName := args[1]
-	logger.Info(fmt.Sprintf("🔄 Restoring environment %s from backup %s", envName, backupName))
-
-	// TODO: Implement environment restoration
-	// This should:
-	// - Validate backup exists and is compatible
-	// - Restore Kubernetes resources
-	// - Restore persistent data
-	// - Restore configurations
-	// - Verify restoration success
-
-	logger.Info("✅ Environment restored successfully")
+	envName, backupName := args[0], args[1]
+
+	clientset, err := getClientset()
+	if err != nil {
+		return unreachable(err)
+	}
+	dyn, err := getDynamicClient()
+	if err != nil {
+		return unreachable(err)
+	}
+	ctx := context.Background()
+
+	backup, err := dyn.Resource(veleroBackupGVR).Namespace(veleroNamespace).Get(ctx, backupName, metav1.GetOptions{})
+	if err != nil {
+		if crdMissing(err) {
+			return fmt.Errorf("Velero Backup CRD not installed (velero not present in the cluster)")
+		}
+		return fmt.Errorf("failed to get backup %q: %w", backupName, err)
+	}
+	source := backup.GetLabels()[envLabel]
+	manifest := backup.GetAnnotations()[snapshotAnnotation]
+	if source == "" || manifest == "" {
+		return fmt.Errorf("backup %q is not an environment snapshot; create one with `adhar env backup`", backupName)
+	}
+	if phase := nestedString(backup.Object, "status", "phase"); phase != "Completed" && phase != "PartiallyFailed" {
+		return fmt.Errorf("backup %q is %s; only completed backups can be restored", backupName, valueOr(phase, "not finished"))
+	}
+	namespaces, _, _ := unstructured.NestedStringSlice(backup.Object, "spec", "includedNamespaces")
+	sort.Strings(namespaces)
+
+	rw := &envRewriter{From: source, To: envName, Namespaces: namespaces, Hosts: restoreHosts}
+	mapping := map[string]interface{}{}
+	for _, ns := range namespaces {
+		if to := rw.Namespace(ns); to != ns {
+			mapping[ns] = to
+		}
+	}
+
+	if rw.Clone() {
+		fmt.Println(helpers.TitleStyle.Render(fmt.Sprintf("🧬 Cloning environment %s → %s", source, envName)))
+		if _, err := clientset.CoreV1().Namespaces().Get(ctx, envName, metav1.GetOptions{}); err == nil {
+			return fmt.Errorf("environment %q already exists; delete it first or pick another name", envName)
+		}
+	} else {
+		fmt.Println(helpers.TitleStyle.Render(fmt.Sprintf("🔄 Restoring environment %s", envName)))
+	}
+	fmt.Printf("📦 Backup: %s\n", backupName)
+	for _, ns := range namespaces {
+		fmt.Printf("   %-28s → %s\n", ns, rw.Namespace(ns))
+	}
+	if rw.Clone() {
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Hostnames and secret references: %q → %q", source, envName)))
+	}
+	for from, to := range restoreHosts {
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Host: %s → %s", from, to)))
+	}
+	if restoreDryRun {
+		fmt.Println(helpers.CreateMuted("   DRY RUN - nothing restored"))
+		return nil
+	}
+
+	runID := fmt.Sprintf("env-%s-%d", truncate(envName, 40), time.Now().Unix())
+	policy := "none"
+	if restoreOverwrite {
+		policy = "update"
+	}
+
+	// 1. Everything but the XR (recreated below for the new name) and the CNPG
+	// clusters (restored next, once their Backup objects are back).
+	resources := map[string]interface{}{
+		"includedNamespaces":     toInterfaceSlice(namespaces),
+		"excludedResources":      []interface{}{"compositeenvironments.platform.adhar.io", "clusters.postgresql.cnpg.io"},
+		"existingResourcePolicy": policy,
+		"restoreStatus":          map[string]interface{}{"includedResources": []interface{}{"backups.postgresql.cnpg.io"}},
+	}
+	if len(mapping) > 0 {
+		resources["namespaceMapping"] = mapping
+	}
+	if err := runVeleroRestore(ctx, dyn, backupName, runID+"-resources", resources); err != nil {
+		return err
+	}
+	fmt.Println(helpers.CreateSuccess("✅ Namespaces and workloads restored"))
+
+	snap, err := readSnapshot(ctx, dyn, rw.Namespace(source), manifest)
+	if err != nil {
+		return err
+	}
+
+	// 2. Databases, recovering from the CNPG backups taken with the snapshot.
+	if err := restoreDatabases(ctx, dyn, backupName, runID, snap, rw, mapping, policy); err != nil {
+		return err
+	}
+
+	// 3. Rewrite hostnames and secret references in what was restored.
+	if rw.Clone() || len(restoreHosts) > 0 {
+		changed, err := rewriteRestored(ctx, dyn, rw)
+		if err != nil {
+			return err
+		}
+		fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Rewrote hostnames and secret references in %d object(s)", changed)))
+	}
+	if rw.Clone() {
+		if err := relabelNamespaces(ctx, dyn, rw); err != nil {
+			return err
+		}
+	}
+
+	// 4. CompositeEnvironment and ArgoCD Applications.
+	if snap.Spec != nil {
+		if err := restoreCompositeEnvironment(ctx, dyn, snap, rw); err != nil {
+			return err
+		}
+		fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ CompositeEnvironment %q restored", envName)))
+	}
+	for _, app := range snap.Applications {
+		obj := rewriteApplication(app, rw)
+		_, err := dyn.Resource(argoApplicationGVR).Namespace(obj.GetNamespace()).Create(ctx, obj, metav1.CreateOptions{})
+		switch {
+		case err == nil:
+			fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Application %s → %s", obj.GetName(), nestedString(obj.Object, "spec", "destination", "namespace"))))
+		case k8serrors.IsAlreadyExists(err):
+			fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Application %s already exists; left unchanged", obj.GetName())))
+		default:
+			return fmt.Errorf("failed to create application %q: %w", obj.GetName(), err)
+		}
+	}
+
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Environment %s restored from %s", envName, backupName)))
 	return nil
 }
+
+// runVeleroRestore creates a Velero Restore of backup and waits for it.
+// Partial failures are reported but do not stop the environment restore.
+func runVeleroRestore(ctx context.Context, dyn dynamic.Interface, backup, name string, spec map[string]interface{}) error {
+	spec["backupName"] = backup
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "velero.io/v1",
+		"kind":       "Restore",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": veleroNamespace,
+			"labels":    map[string]interface{}{"adhar.io/managed-by": "adhar-cli"},
+		},
+		"spec": spec,
+	}}
+	if _, err := dyn.Resource(veleroRestoreGVR).Namespace(veleroNamespace).Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create restore %q: %w", name, err)
+	}
+	fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Velero restore %s running...", name)))
+	if err := waitForVelero(ctx, dyn, veleroRestoreGVR, name, restoreTimeout, true); err != nil {
+		return err
+	}
+	if restored, err := dyn.Resource(veleroRestoreGVR).Namespace(veleroNamespace).Get(ctx, name, metav1.GetOptions{}); err == nil &&
+		nestedString(restored.Object, "status", "phase") == "PartiallyFailed" {
+		fmt.Println(helpers.CreateWarning(fmt.Sprintf("⚠️  Restore %s partially failed; inspect with `velero restore describe %s --details`", name, name)))
+	}
+	return nil
+}
+
+// restoreDatabases restores the CNPG clusters of the snapshot, bootstrapping
+// each one that has a snapshot backup from it via a Velero resource modifier.
+func restoreDatabases(ctx context.Context, dyn dynamic.Interface, backup, runID string, snap *envSnapshot, rw *envRewriter, mapping map[string]interface{}, policy string) error {
+	hasCNPG := false
+	for _, db := range snap.Databases {
+		hasCNPG = hasCNPG || db.Kind == "cnpg"
+	}
+	if !hasCNPG {
+		return nil
+	}
+
+	spec := map[string]interface{}{
+		"includedNamespaces":     toInterfaceSlice(snap.Namespaces),
+		"includedResources":      []interface{}{"clusters.postgresql.cnpg.io"},
+		"existingResourcePolicy": policy,
+	}
+	if len(mapping) > 0 {
+		spec["namespaceMapping"] = mapping
+	}
+	rules, err := databaseModifierRules(snap.Databases, rw)
+	if err != nil {
+		return fmt.Errorf("failed to render CNPG recovery rules: %w", err)
+	}
+	if rules != "" {
+		modifier := runID + "-cnpg"
+		cm := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":      modifier,
+				"namespace": veleroNamespace,
+				"labels":    map[string]interface{}{"adhar.io/managed-by": "adhar-cli"},
+			},
+			"data": map[string]interface{}{"cnpg-recovery.yaml": rules},
+		}}
+		res := dyn.Resource(configMapGVR).Namespace(veleroNamespace)
+		if _, err := res.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create resource modifier %q: %w", modifier, err)
+		}
+		defer func() { _ = res.Delete(context.Background(), modifier, metav1.DeleteOptions{}) }()
+		spec["resourceModifier"] = map[string]interface{}{"kind": "ConfigMap", "name": modifier}
+	}
+	if err := runVeleroRestore(ctx, dyn, backup, runID+"-databases", spec); err != nil {
+		return err
+	}
+	for _, db := range snap.Databases {
+		switch {
+		case db.Kind == "composite":
+			fmt.Println(helpers.CreateMuted(fmt.Sprintf("   CompositeDatabase %s/%s restored empty; its data is not part of the snapshot", rw.Namespace(db.Namespace), db.Name)))
+		case db.Backup != "":
+			fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Database %s/%s recovering from %s", rw.Namespace(db.Namespace), db.Name, db.Backup)))
+		default:
+			fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Database %s/%s restored from volume snapshots", rw.Namespace(db.Namespace), db.Name)))
+		}
+	}
+	return nil
+}
+
+// restoredRewrite names a resource whose hostnames or secret references are
+// rewritten after a clone, and the function that does it. rewrite returns
+// true when it changed the object. TLS secret names are left alone: Secrets
+// are restored under their original names, and cert-manager reissues the
+// certificate for the rewritten hostnames into that same Secret.
+type restoredRewrite struct {
+	GVR     schema.GroupVersionResource
+	Rewrite func(obj map[string]interface{}, rw *envRewriter) bool
+}
+
+var restoredRewrites = []restoredRewrite{
+	{
+		GVR: schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+		Rewrite: func(obj map[string]interface{}, rw *envRewriter) bool {
+			changed := rewriteEach(obj, rw.Text, "spec", "rules", "[]", "host")
+			return rewriteEach(obj, rw.Text, "spec", "tls", "[]", "hosts", "[]") || changed
+		},
+	},
+	{
+		GVR: schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"},
+		Rewrite: func(obj map[string]interface{}, rw *envRewriter) bool {
+			return rewriteEach(obj, rw.Text, "spec", "hostnames", "[]")
+		},
+	},
+	{
+		GVR: schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"},
+		Rewrite: func(obj map[string]interface{}, rw *envRewriter) bool {
+			changed := rewriteEach(obj, rw.Text, "spec", "dnsNames", "[]")
+			return rewriteEach(obj, rw.Text, "spec", "commonName") || changed
+		},
+	},
+	{
+		GVR: schema.GroupVersionResource{Group: "external-secrets.io", Version: "v1beta1", Resource: "externalsecrets"},
+		Rewrite: func(obj map[string]interface{}, rw *envRewriter) bool {
+			if restoreKeepSecretRefs {
+				return false
+			}
+			changed := rewriteEach(obj, rw.Token, "spec", "data", "[]", "remoteRef", "key")
+			changed = rewriteEach(obj, rw.Token, "spec", "dataFrom", "[]", "extract", "key") || changed
+			return rewriteEach(obj, rw.Token, "spec", "dataFrom", "[]", "find", "path") || changed
+		},
+	},
+	{
+		GVR: configMapGVR,
+		Rewrite: func(obj map[string]interface{}, rw *envRewriter) bool {
+			if nestedString(obj, "metadata", "labels", snapshotLabel) != "" {
+				return false
+			}
+			data, ok := obj["data"].(map[string]interface{})
+			if !ok {
+				return false
+			}
+			changed := false
+			for k, v := range data {
+				if s, ok := v.(string); ok && rw.Text(s) != s {
+					data[k] = rw.Text(s)
+					changed = true
+				}
+			}
+			return changed
+		},
+	},
+	{
+		GVR:     schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		Rewrite: rewriteContainerEnv,
+	},
+	{
+		GVR:     schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		Rewrite: rewriteContainerEnv,
+	},
+}
+
+// rewriteContainerEnv rewrites hostnames in literal env var values of a pod
+// template.
+func rewriteContainerEnv(obj map[string]interface{}, rw *envRewriter) bool {
+	changed := rewriteEach(obj, rw.Text, "spec", "template", "spec", "containers", "[]", "env", "[]", "value")
+	return rewriteEach(obj, rw.Text, "spec", "template", "spec", "initContainers", "[]", "env", "[]", "value") || changed
+}
+
+// rewriteEach applies fn to the string(s) at path, where "[]" steps into every
+// element of a list. It reports whether anything changed.
+func rewriteEach(obj interface{}, fn func(string) string, path ...string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	switch t := obj.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			s, ok := t[path[0]].(string)
+			if !ok || fn(s) == s {
+				return false
+			}
+			t[path[0]] = fn(s)
+			return true
+		}
+		return rewriteEach(t[path[0]], fn, path[1:]...)
+	case []interface{}:
+		if path[0] != "[]" {
+			return false
+		}
+		changed := false
+		for i, e := range t {
+			if s, ok := e.(string); ok && len(path) == 1 {
+				if fn(s) != s {
+					t[i] = fn(s)
+					changed = true
+				}
+				continue
+			}
+			changed = rewriteEach(e, fn, path[1:]...) || changed
+		}
+		return changed
+	}
+	return false
+}
+
+// rewriteRestored applies restoredRewrites to every matching object in the
+// target namespaces and returns how many objects were updated.
+func rewriteRestored(ctx context.Context, dyn dynamic.Interface, rw *envRewriter) (int, error) {
+	changed := 0
+	for _, ns := range rw.Namespaces {
+		target := rw.Namespace(ns)
+		for _, r := range restoredRewrites {
+			list, err := dyn.Resource(r.GVR).Namespace(target).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				if crdMissing(err) || k8serrors.IsNotFound(err) {
+					continue
+				}
+				return changed, fmt.Errorf("failed to list %s in %s: %w", r.GVR.Resource, target, err)
+			}
+			for i := range list.Items {
+				obj := &list.Items[i]
+				if !r.Rewrite(obj.Object, rw) {
+					continue
+				}
+				if _, err := dyn.Resource(r.GVR).Namespace(target).Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+					return changed, fmt.Errorf("failed to update %s %s/%s: %w", r.GVR.Resource, target, obj.GetName(), err)
+				}
+				changed++
+			}
+		}
+	}
+	return changed, nil
+}
+
+// relabelNamespaces points the cloned namespaces' environment label at the
+// new environment so they show up under it in `adhar env list`.
+func relabelNamespaces(ctx context.Context, dyn dynamic.Interface, rw *envRewriter) error {
+	labels := map[string]interface{}{envLabel: rw.To}
+	if restoreTier != "" {
+		labels["adhar.io/tier"] = restoreTier
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{
+		"labels":      labels,
+		"annotations": map[string]interface{}{clonedFromAnnotation: rw.From},
+	}})
+	if err != nil {
+		return err
+	}
+	nsGVR := schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+	for _, ns := range rw.Namespaces {
+		if _, err := dyn.Resource(nsGVR).Patch(ctx, rw.Namespace(ns), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("failed to label namespace %q: %w", rw.Namespace(ns), err)
+		}
+	}
+	return nil
+}
+
+// restoreCompositeEnvironment recreates the XR from the snapshot spec under
+// the target environment name.
+func restoreCompositeEnvironment(ctx context.Context, dyn dynamic.Interface, snap *envSnapshot, rw *envRewriter) error {
+	spec := runtime.DeepCopyJSON(snap.Spec)
+	// Crossplane fills these in; copying them would bind the clone to the
+	// source environment's composed resources.
+	delete(spec, "crossplane")
+	delete(spec, "resourceRefs")
+	_ = unstructured.SetNestedField(spec, rw.To, "parameters", "name")
+	if restoreTier != "" {
+		_ = unstructured.SetNestedField(spec, restoreTier, "parameters", "tier")
+	}
+	ns := rw.Namespace(snap.Environment)
+	meta := map[string]interface{}{
+		"name":      rw.To,
+		"namespace": ns,
+		"labels":    map[string]interface{}{"adhar.io/managed-by": "adhar-cli"},
+	}
+	if rw.Clone() {
+		meta["annotations"] = map[string]interface{}{clonedFromAnnotation: rw.From}
+	}
+	xr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "platform.adhar.io/v1alpha1",
+		"kind":       "CompositeEnvironment",
+		"metadata":   meta,
+		"spec":       spec,
+	}}
+	_, err := dyn.Resource(compositeEnvironmentGVR).Namespace(ns).Create(ctx, xr, metav1.CreateOptions{})
+	if k8serrors.IsAlreadyExists(err) {
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("   CompositeEnvironment %q already exists; left unchanged", rw.To)))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create CompositeEnvironment %q: %w", rw.To, err)
+	}
+	return nil
+}
+
+func valueOr(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
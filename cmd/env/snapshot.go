@@ -0,0 +1,487 @@
+package env
+
+// snapshot.go models an environment snapshot: a Velero Backup of every
+// namespace the environment owns, plus a manifest ConfigMap written into the
+// environment namespace just before the backup runs. The manifest records the
+// pieces Velero cannot restore verbatim into a renamed environment — the
+// CompositeEnvironment spec, the ArgoCD Applications (which live in
+// adhar-system, not in the environment) and the CNPG backups taken for each
+// database — so `adhar env restore` can rebuild them under a new name.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"adhar-io/adhar/globals"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	veleroNamespace = "velero"
+
+	// snapshotConfigMapPrefix names the ConfigMap holding the manifest inside
+	// the environment namespace, so it travels with the Velero backup. Each
+	// backup writes its own, referenced from snapshotAnnotation on the Backup.
+	snapshotConfigMapPrefix = "adhar-env-snapshot-"
+	snapshotKey             = "snapshot.json"
+	snapshotLabel           = "adhar.io/env-snapshot"
+	snapshotAnnotation      = "adhar.io/env-snapshot"
+
+	// clonedFromAnnotation records the environment a clone was restored from.
+	clonedFromAnnotation = "adhar.io/cloned-from"
+
+	envPollInterval = 5 * time.Second
+)
+
+var (
+	veleroBackupGVR = schema.GroupVersionResource{
+		Group: "velero.io", Version: "v1", Resource: "backups",
+	}
+	veleroRestoreGVR = schema.GroupVersionResource{
+		Group: "velero.io", Version: "v1", Resource: "restores",
+	}
+	argoApplicationGVR = schema.GroupVersionResource{
+		Group: "argoproj.io", Version: "v1alpha1", Resource: "applications",
+	}
+	cnpgClusterGVR = schema.GroupVersionResource{
+		Group: "postgresql.cnpg.io", Version: "v1", Resource: "clusters",
+	}
+	cnpgBackupGVR = schema.GroupVersionResource{
+		Group: "postgresql.cnpg.io", Version: "v1", Resource: "backups",
+	}
+	compositeDatabaseGVR = schema.GroupVersionResource{
+		Group: "platform.adhar.io", Version: "v1alpha1", Resource: "compositedatabases",
+	}
+	configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+)
+
+// envSnapshot is the manifest stored alongside an environment backup.
+type envSnapshot struct {
+	Environment string    `json:"environment"`
+	Tier        string    `json:"tier,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// Namespaces owned by the environment: its own namespace plus every
+	// namespace labelled adhar.io/environment=<env>.
+	Namespaces []string `json:"namespaces"`
+	// Spec of the CompositeEnvironment XR, if one exists.
+	Spec map[string]interface{} `json:"spec,omitempty"`
+	// Applications are the ArgoCD Applications deploying into Namespaces,
+	// stripped of status and server-populated metadata.
+	Applications []map[string]interface{} `json:"applications,omitempty"`
+	Databases    []envDatabase            `json:"databases,omitempty"`
+}
+
+// envDatabase is one database captured by the snapshot.
+type envDatabase struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Kind is "cnpg" for CloudNativePG clusters or "composite" for
+	// CompositeDatabase XRs, whose data lives with the cloud provider and is
+	// not part of the snapshot.
+	Kind string `json:"kind"`
+	// Backup is the CNPG Backup taken for this snapshot; empty when the
+	// cluster has no object store configured and relies on volume snapshots.
+	Backup string `json:"backup,omitempty"`
+	// Archiving is true when the cluster ships WAL to an object store, in
+	// which case a clone needs its own serverName.
+	Archiving bool `json:"archiving,omitempty"`
+}
+
+// ownedNamespaces returns the environment namespace and every namespace
+// labelled as belonging to it.
+func ownedNamespaces(ctx context.Context, cs kubernetes.Interface, env string) ([]string, error) {
+	nss, err := cs.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: envLabel + "=" + env})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces of environment %q: %w", env, err)
+	}
+	seen := map[string]bool{}
+	for _, ns := range nss.Items {
+		seen[ns.Name] = true
+	}
+	if !seen[env] {
+		if _, err := cs.CoreV1().Namespaces().Get(ctx, env, metav1.GetOptions{}); err == nil {
+			seen[env] = true
+		}
+	}
+	if len(seen) == 0 {
+		return nil, fmt.Errorf("environment %q not found: no namespace named or labelled %s=%s", env, envLabel, env)
+	}
+	out := make([]string, 0, len(seen))
+	for ns := range seen {
+		out = append(out, ns)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// environmentApplications returns the ArgoCD Applications whose destination
+// is one of namespaces.
+func environmentApplications(ctx context.Context, dyn dynamic.Interface, namespaces []string) ([]unstructured.Unstructured, error) {
+	list, err := dyn.Resource(argoApplicationGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if crdMissing(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list ArgoCD applications: %w", err)
+	}
+	var out []unstructured.Unstructured
+	for _, app := range list.Items {
+		if containsString(namespaces, nestedString(app.Object, "spec", "destination", "namespace")) {
+			out = append(out, app)
+		}
+	}
+	return out, nil
+}
+
+// cleanObject strips status and server-populated metadata so an object can be
+// re-created elsewhere.
+func cleanObject(obj map[string]interface{}) map[string]interface{} {
+	c := runtime.DeepCopyJSON(obj)
+	delete(c, "status")
+	delete(c, "operation")
+	for _, f := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink", "ownerReferences", "finalizers"} {
+		unstructured.RemoveNestedField(c, "metadata", f)
+	}
+	unstructured.RemoveNestedField(c, "metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration")
+	return c
+}
+
+// writeSnapshot stores the manifest in the environment namespace under name,
+// removing the manifests of earlier backups (those live on in their backups).
+func writeSnapshot(ctx context.Context, dyn dynamic.Interface, name string, snap *envSnapshot) error {
+	raw, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	res := dyn.Resource(configMapGVR).Namespace(snap.Environment)
+	if err := res.DeleteCollection(ctx, metav1.DeleteOptions{}, metav1.ListOptions{LabelSelector: snapshotLabel}); err != nil {
+		return fmt.Errorf("failed to remove previous snapshot manifests: %w", err)
+	}
+	cm := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": snap.Environment,
+			"labels": map[string]interface{}{
+				envLabel:              snap.Environment,
+				snapshotLabel:         "true",
+				"adhar.io/managed-by": "adhar-cli",
+			},
+		},
+		"data": map[string]interface{}{snapshotKey: string(raw)},
+	}}
+	if _, err := res.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to write snapshot manifest: %w", err)
+	}
+	return nil
+}
+
+// readSnapshot loads a manifest restored into namespace.
+func readSnapshot(ctx context.Context, dyn dynamic.Interface, namespace, name string) (*envSnapshot, error) {
+	cm, err := dyn.Resource(configMapGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("snapshot manifest %s/%s not found: %w", namespace, name, err)
+	}
+	var snap envSnapshot
+	if err := json.Unmarshal([]byte(nestedString(cm.Object, "data", snapshotKey)), &snap); err != nil {
+		return nil, fmt.Errorf("snapshot manifest %s/%s is invalid: %w", namespace, name, err)
+	}
+	return &snap, nil
+}
+
+// envRewriter maps names, namespaces and hostnames from a source environment
+// onto a target one. The source name is replaced wherever it appears as a
+// whole token, i.e. delimited by anything other than [a-z0-9]:
+// "staging" → "pr-42" turns "api.staging.example.com" into
+// "api.pr-42.example.com" and "web-staging" into "web-pr-42", but leaves
+// "stagingarea" alone.
+type envRewriter struct {
+	From, To string
+	// Namespaces are the source environment's namespaces.
+	Namespaces []string
+	// Hosts are explicit old=new hostname replacements applied first, for
+	// hosts that do not embed the environment name.
+	Hosts map[string]string
+}
+
+// hostnameRe matches dotted DNS names, alone or inside URLs and free text.
+var hostnameRe = regexp.MustCompile(`[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+`)
+
+// Clone reports whether the restore targets a different environment.
+func (rw *envRewriter) Clone() bool { return rw.From != rw.To }
+
+// Name rewrites a resource name. Names that do not mention the source
+// environment get the target appended so clones never collide with the
+// original in shared namespaces.
+func (rw *envRewriter) Name(s string) string {
+	if !rw.Clone() {
+		return s
+	}
+	if out := replaceToken(s, rw.From, rw.To); out != s {
+		return out
+	}
+	return s + "-" + rw.To
+}
+
+// Namespace maps an owned namespace to its clone; other namespaces are kept.
+func (rw *envRewriter) Namespace(ns string) string {
+	if !containsString(rw.Namespaces, ns) {
+		return ns
+	}
+	return rw.Name(ns)
+}
+
+// Token rewrites whole-token occurrences of the environment name, for
+// references such as secret-store keys.
+func (rw *envRewriter) Token(s string) string {
+	if !rw.Clone() {
+		return s
+	}
+	return replaceToken(s, rw.From, rw.To)
+}
+
+// Text rewrites the hostnames inside s: explicit --host replacements, in-cluster
+// service addresses (svc.<ns>.svc…) of owned namespaces, and any hostname
+// embedding the environment name. Text outside hostnames is left alone.
+func (rw *envRewriter) Text(s string) string {
+	for from, to := range rw.Hosts {
+		s = strings.ReplaceAll(s, from, to)
+	}
+	if !rw.Clone() {
+		return s
+	}
+	return hostnameRe.ReplaceAllStringFunc(s, func(host string) string {
+		labels := strings.Split(host, ".")
+		for i, l := range labels {
+			if i > 0 && i+1 < len(labels) && labels[i+1] == "svc" {
+				labels[i] = rw.Namespace(l)
+			} else {
+				labels[i] = replaceToken(l, rw.From, rw.To)
+			}
+		}
+		return strings.Join(labels, ".")
+	})
+}
+
+// replaceToken replaces whole-token occurrences of from in s.
+func replaceToken(s, from, to string) string {
+	if from == "" {
+		return s
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(s, from)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end := i + len(from)
+		if (i == 0 || !isTokenChar(s[i-1])) && (end == len(s) || !isTokenChar(s[end])) {
+			b.WriteString(s[:i])
+			b.WriteString(to)
+		} else {
+			b.WriteString(s[:end])
+		}
+		s = s[end:]
+	}
+}
+
+func isTokenChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
+
+// rewriteApplication turns a snapshotted ArgoCD Application into the one for
+// the target environment: renamed, pointed at the mapped namespace, labelled
+// with the target environment and with hostnames in its Helm values rewritten.
+func rewriteApplication(app map[string]interface{}, rw *envRewriter) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: runtime.DeepCopyJSON(app)}
+	obj.SetName(rw.Name(obj.GetName()))
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(globals.AdharSystemNamespace)
+	}
+	if ns := nestedString(obj.Object, "spec", "destination", "namespace"); ns != "" {
+		_ = unstructured.SetNestedField(obj.Object, rw.Namespace(ns), "spec", "destination", "namespace")
+	}
+
+	lbls := obj.GetLabels()
+	if lbls == nil {
+		lbls = map[string]string{}
+	}
+	lbls[envLabel] = rw.To
+	obj.SetLabels(lbls)
+	if rw.Clone() {
+		ann := obj.GetAnnotations()
+		if ann == nil {
+			ann = map[string]string{}
+		}
+		ann[clonedFromAnnotation] = rw.From
+		obj.SetAnnotations(ann)
+	}
+
+	rewriteHelm := func(source map[string]interface{}) {
+		helm, ok := source["helm"].(map[string]interface{})
+		if !ok {
+			return
+		}
+		if params, ok := helm["parameters"].([]interface{}); ok {
+			for _, p := range params {
+				if pm, ok := p.(map[string]interface{}); ok {
+					if v, ok := pm["value"].(string); ok {
+						pm["value"] = rw.Text(v)
+					}
+				}
+			}
+		}
+		if v, ok := helm["values"].(string); ok {
+			helm["values"] = rw.Text(v)
+		}
+		if v, ok := helm["valuesObject"]; ok {
+			helm["valuesObject"] = rewriteStrings(v, rw.Text)
+		}
+	}
+	spec, _ := obj.Object["spec"].(map[string]interface{})
+	if src, ok := spec["source"].(map[string]interface{}); ok {
+		rewriteHelm(src)
+	}
+	if srcs, ok := spec["sources"].([]interface{}); ok {
+		for _, s := range srcs {
+			if src, ok := s.(map[string]interface{}); ok {
+				rewriteHelm(src)
+			}
+		}
+	}
+	return obj
+}
+
+// rewriteStrings applies fn to every string inside v.
+func rewriteStrings(v interface{}, fn func(string) string) interface{} {
+	switch t := v.(type) {
+	case string:
+		return fn(t)
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = rewriteStrings(e, fn)
+		}
+		return t
+	case []interface{}:
+		for i, e := range t {
+			t[i] = rewriteStrings(e, fn)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// databaseModifierRules renders the Velero resource-modifier rules that make
+// restored CNPG clusters recover from the snapshot's CNPG backups. A clone of
+// a cluster that archives WAL also gets its own serverName so it does not
+// write into the source cluster's archive.
+func databaseModifierRules(dbs []envDatabase, rw *envRewriter) (string, error) {
+	var rules []interface{}
+	for _, db := range dbs {
+		if db.Kind != "cnpg" || db.Backup == "" {
+			continue
+		}
+		bootstrap, err := json.Marshal(map[string]interface{}{
+			"recovery": map[string]interface{}{
+				"backup": map[string]interface{}{"name": db.Backup},
+			},
+		})
+		if err != nil {
+			return "", err
+		}
+		patches := []interface{}{
+			map[string]interface{}{"operation": "add", "path": "/spec/bootstrap", "value": string(bootstrap)},
+		}
+		if db.Archiving && rw.Clone() {
+			patches = append(patches, map[string]interface{}{
+				"operation": "add",
+				"path":      "/spec/backup/barmanObjectStore/serverName",
+				"value":     db.Name + "-" + rw.To,
+			})
+		}
+		rules = append(rules, map[string]interface{}{
+			"conditions": map[string]interface{}{
+				"groupResource":     "clusters.postgresql.cnpg.io",
+				"resourceNameRegex": "^" + db.Name + "$",
+				"namespaces":        []interface{}{db.Namespace, rw.Namespace(db.Namespace)},
+			},
+			"patches": patches,
+		})
+	}
+	if len(rules) == 0 {
+		return "", nil
+	}
+	out, err := yaml.Marshal(map[string]interface{}{
+		"version":               "v1",
+		"resourceModifierRules": rules,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// waitForVelero polls a Velero Backup or Restore until it reaches a terminal
+// phase. PartiallyFailed is an error unless allowPartial is set.
+func waitForVelero(ctx context.Context, dyn dynamic.Interface, gvr schema.GroupVersionResource, name string, timeout time.Duration, allowPartial bool) error {
+	kind := strings.TrimSuffix(gvr.Resource, "s")
+	cctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		obj, err := dyn.Resource(gvr).Namespace(veleroNamespace).Get(cctx, name, metav1.GetOptions{})
+		if err == nil {
+			switch phase := nestedString(obj.Object, "status", "phase"); phase {
+			case "Completed":
+				return nil
+			case "PartiallyFailed":
+				if allowPartial {
+					return nil
+				}
+				return fmt.Errorf("%s %q partially failed; inspect with `velero %s describe %s --details`", kind, name, kind, name)
+			case "Failed", "FailedValidation":
+				msg := nestedString(obj.Object, "status", "failureReason")
+				if errs, _, _ := unstructured.NestedStringSlice(obj.Object, "status", "validationErrors"); len(errs) > 0 {
+					msg = strings.Join(errs, "; ")
+				}
+				return fmt.Errorf("%s %q %s: %s", kind, name, phase, msg)
+			}
+		} else if !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get %s %q: %w", kind, name, err)
+		}
+		select {
+		case <-cctx.Done():
+			return fmt.Errorf("timed out after %s waiting for %s %q", timeout, kind, name)
+		case <-time.After(envPollInterval):
+		}
+	}
+}
+
+func nestedString(obj map[string]interface{}, fields ...string) string {
+	s, _, _ := unstructured.NestedString(obj, fields...)
+	return s
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
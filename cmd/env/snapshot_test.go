@@ -0,0 +1,140 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvRewriterNames(t *testing.T) {
+	rw := &envRewriter{From: "staging", To: "pr-42", Namespaces: []string{"staging", "staging-data", "shared"}}
+
+	cases := map[string]string{
+		"staging":      "pr-42",
+		"staging-data": "pr-42-data",
+		"shared":       "shared-pr-42",
+		"other":        "other",
+	}
+	for in, want := range cases {
+		if got := rw.Namespace(in); got != want {
+			t.Errorf("Namespace(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := rw.Name("web"); got != "web-pr-42" {
+		t.Errorf("Name(web) = %q, want web-pr-42", got)
+	}
+	if got := rw.Token("stagingarea/staging/db"); got != "stagingarea/pr-42/db" {
+		t.Errorf("Token = %q", got)
+	}
+
+	same := &envRewriter{From: "staging", To: "staging", Namespaces: []string{"staging"}}
+	if same.Clone() || same.Name("web") != "web" || same.Text("api.staging.example.com") != "api.staging.example.com" {
+		t.Errorf("in-place rewriter must not rename anything")
+	}
+}
+
+func TestEnvRewriterText(t *testing.T) {
+	rw := &envRewriter{
+		From:       "staging",
+		To:         "staging-2",
+		Namespaces: []string{"staging", "shared"},
+		Hosts:      map[string]string{"www.example.com": "preview.example.com"},
+	}
+	cases := map[string]string{
+		"https://api.staging.example.com/v1":              "https://api.staging-2.example.com/v1",
+		"postgres://db-rw.staging.svc.cluster.local:5432": "postgres://db-rw.staging-2.svc.cluster.local:5432",
+		"redis.shared.svc:6379":                           "redis.shared-staging-2.svc:6379",
+		"https://www.example.com":                         "https://preview.example.com",
+		"staging is not a hostname":                       "staging is not a hostname",
+	}
+	for in, want := range cases {
+		if got := rw.Text(in); got != want {
+			t.Errorf("Text(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRewriteApplication(t *testing.T) {
+	rw := &envRewriter{From: "staging", To: "pr-42", Namespaces: []string{"staging"}}
+	app := map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata":   map[string]interface{}{"name": "web-staging", "namespace": "adhar-system"},
+		"spec": map[string]interface{}{
+			"destination": map[string]interface{}{"namespace": "staging"},
+			"source": map[string]interface{}{
+				"helm": map[string]interface{}{
+					"parameters":   []interface{}{map[string]interface{}{"name": "host", "value": "web.staging.example.com"}},
+					"valuesObject": map[string]interface{}{"db": map[string]interface{}{"host": "db-rw.staging.svc"}},
+				},
+			},
+		},
+	}
+
+	got := rewriteApplication(app, rw)
+	if got.GetName() != "web-pr-42" {
+		t.Errorf("name = %q", got.GetName())
+	}
+	if ns := nestedString(got.Object, "spec", "destination", "namespace"); ns != "pr-42" {
+		t.Errorf("destination namespace = %q", ns)
+	}
+	if got.GetLabels()[envLabel] != "pr-42" || got.GetAnnotations()[clonedFromAnnotation] != "staging" {
+		t.Errorf("labels/annotations not set: %v %v", got.GetLabels(), got.GetAnnotations())
+	}
+	if v := nestedString(got.Object, "spec", "source", "helm", "valuesObject", "db", "host"); v != "db-rw.pr-42.svc" {
+		t.Errorf("valuesObject host = %q", v)
+	}
+	params := got.Object["spec"].(map[string]interface{})["source"].(map[string]interface{})["helm"].(map[string]interface{})["parameters"].([]interface{})
+	if v := params[0].(map[string]interface{})["value"]; v != "web.pr-42.example.com" {
+		t.Errorf("helm parameter = %v", v)
+	}
+	if nestedString(app, "metadata", "name") != "web-staging" {
+		t.Errorf("source application was modified")
+	}
+}
+
+func TestDatabaseModifierRules(t *testing.T) {
+	rw := &envRewriter{From: "staging", To: "pr-42", Namespaces: []string{"staging"}}
+	rules, err := databaseModifierRules([]envDatabase{
+		{Namespace: "staging", Name: "app-db", Kind: "cnpg", Backup: "app-db-20260101-000000", Archiving: true},
+		{Namespace: "staging", Name: "cache-db", Kind: "cnpg"},
+		{Namespace: "staging", Name: "orders", Kind: "composite"},
+	}, rw)
+	if err != nil {
+		t.Fatalf("databaseModifierRules: %v", err)
+	}
+	for _, want := range []string{"^app-db$", "app-db-20260101-000000", "/spec/backup/barmanObjectStore/serverName", "app-db-pr-42", "- pr-42"} {
+		if !strings.Contains(rules, want) {
+			t.Errorf("rules missing %q:\n%s", want, rules)
+		}
+	}
+	if strings.Contains(rules, "cache-db") || strings.Contains(rules, "orders") {
+		t.Errorf("rules include databases without a snapshot backup:\n%s", rules)
+	}
+
+	if rules, _ := databaseModifierRules([]envDatabase{{Namespace: "staging", Name: "cache-db", Kind: "cnpg"}}, rw); rules != "" {
+		t.Errorf("expected no rules, got:\n%s", rules)
+	}
+}
+
+func TestRewriteEach(t *testing.T) {
+	rw := &envRewriter{From: "staging", To: "pr-42", Namespaces: []string{"staging"}}
+	ing := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"rules": []interface{}{map[string]interface{}{"host": "app.staging.example.com"}},
+			"tls": []interface{}{map[string]interface{}{
+				"hosts":      []interface{}{"app.staging.example.com"},
+				"secretName": "app-staging-tls",
+			}},
+		},
+	}
+	if !restoredRewrites[0].Rewrite(ing, rw) {
+		t.Fatal("expected ingress to change")
+	}
+	tls := ing["spec"].(map[string]interface{})["tls"].([]interface{})[0].(map[string]interface{})
+	if tls["hosts"].([]interface{})[0] != "app.pr-42.example.com" || tls["secretName"] != "app-staging-tls" {
+		t.Errorf("tls = %v", tls)
+	}
+	if restoredRewrites[0].Rewrite(ing, rw) {
+		t.Error("second rewrite should be a no-op")
+	}
+}
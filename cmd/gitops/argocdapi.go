@@ -0,0 +1,114 @@
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"adhar-io/adhar/platform/k8s"
+	"adhar-io/adhar/platform/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// argocdAPI is a minimal client for the ArgoCD API server, for state that is
+// not kept in Kubernetes resources (such as repository connection status).
+type argocdAPI struct {
+	base  string
+	token string
+	http  *http.Client
+}
+
+// argoConnectionState mirrors ArgoCD's ConnectionState.
+type argoConnectionState struct {
+	Status      string `json:"status"`
+	Message     string `json:"message"`
+	AttemptedAt string `json:"attemptedAt"`
+}
+
+// newArgocdAPI logs in as the ArgoCD admin using the initial admin secret.
+func newArgocdAPI(ctx context.Context) (*argocdAPI, error) {
+	base, err := utils.ArgocdBaseUrl(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := cs.CoreV1().Secrets(utils.ArgocdNamespace).Get(ctx, utils.ArgocdInitialAdminSecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ArgoCD admin credentials: %w", err)
+	}
+	api := &argocdAPI{base: base + "/api/v1", http: utils.GetHttpClient()}
+	var session struct {
+		Token string `json:"token"`
+	}
+	login := map[string]string{"username": utils.ArgocdAdminName, "password": string(secret.Data["password"])}
+	if err := api.do(ctx, http.MethodPost, "/session", login, &session); err != nil {
+		return nil, fmt.Errorf("ArgoCD login failed: %w", err)
+	}
+	api.token = session.Token
+	return api, nil
+}
+
+// repositoryStates returns the connection state of every repository ArgoCD
+// knows, keyed by normalized URL. refresh makes ArgoCD re-test each one.
+func (a *argocdAPI) repositoryStates(ctx context.Context, refresh bool) (map[string]argoConnectionState, error) {
+	var list struct {
+		Items []struct {
+			Repo            string              `json:"repo"`
+			ConnectionState argoConnectionState `json:"connectionState"`
+		} `json:"items"`
+	}
+	path := "/repositories"
+	if refresh {
+		path += "?forceRefresh=true"
+	}
+	if err := a.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	out := make(map[string]argoConnectionState, len(list.Items))
+	for _, it := range list.Items {
+		out[normalizeRepoURL(it.Repo)] = it.ConnectionState
+	}
+	return out, nil
+}
+
+func (a *argocdAPI) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.base+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: HTTP %d: %s", method, path, resp.StatusCode, truncate(string(bytes.TrimSpace(data)), 200))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,35 @@
+package gitops
+
+import (
+	"context"
+
+	"adhar-io/adhar/platform/utils/gitopsrepo"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// openGitopsRepo clones branch of repoURL as the Gitea admin.
+func openGitopsRepo(ctx context.Context, repoURL, branch string) (*gitopsrepo.Repo, error) {
+	auth, err := gitopsrepo.AdminAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return gitopsrepo.Open(ctx, auth, repoURL, branch)
+}
+
+// giteaClient returns an API client for the platform Gitea, signed in as the
+// Gitea admin.
+func giteaClient(ctx context.Context) (*gitea.Client, error) {
+	auth, err := gitopsrepo.AdminAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return gitopsrepo.Client(ctx, auth)
+}
+
+func shortSHA(rev string) string {
+	if len(rev) == 40 {
+		return rev[:7]
+	}
+	return rev
+}
@@ -37,7 +37,7 @@ This command provides:
 • Multi-environment deployment management
 
 Examples:
-  adhar gitops sync                    # Sync all applications
+  adhar gitops sync --all              # Sync all applications
   adhar gitops sync --app=my-app      # Sync specific application
   adhar gitops rollback --app=my-app  # Rollback application
   adhar gitops status                 # Show GitOps status
@@ -0,0 +1,96 @@
+package gitops
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/k8s"
+	"adhar-io/adhar/platform/utils"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	argoApplicationGVR = schema.GroupVersionResource{
+		Group: "argoproj.io", Version: "v1alpha1", Resource: "applications",
+	}
+	argoApplicationSetGVR = schema.GroupVersionResource{
+		Group: "argoproj.io", Version: "v1alpha1", Resource: "applicationsets",
+	}
+)
+
+// syncWaveAnnotation orders Applications in an app-of-apps; lower waves sync
+// first.
+const syncWaveAnnotation = "argocd.argoproj.io/sync-wave"
+
+// getDynamicClient returns a dynamic client built from the shared kubeconfig.
+func getDynamicClient() (dynamic.Interface, error) {
+	return k8s.GetDynamicClient()
+}
+
+// unreachable wraps a client-construction error with a friendly message.
+func unreachable(err error) error {
+	fmt.Println(helpers.ErrorStyle.Render("❌ Could not connect to the cluster"))
+	fmt.Println(helpers.CreateMuted("   " + err.Error()))
+	fmt.Println(helpers.CreateMuted("   Is the cluster running? Try `adhar up` or check your kubeconfig context."))
+	return fmt.Errorf("failed to get Kubernetes client: %w", err)
+}
+
+func crdMissing(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "could not find") || strings.Contains(err.Error(), "no matches for kind"))
+}
+
+// splitAppRef accepts "name" or "namespace/name"; bare names live in the
+// ArgoCD namespace.
+func splitAppRef(ref string) (namespace, name string) {
+	if ns, n, ok := strings.Cut(ref, "/"); ok {
+		return ns, n
+	}
+	return utils.ArgocdNamespace, ref
+}
+
+// operationTimeout parses the shared --timeout flag.
+func operationTimeout() (time.Duration, error) {
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --timeout %q: %w", timeout, err)
+	}
+	return d, nil
+}
+
+func nestedString(obj map[string]interface{}, fields ...string) string {
+	s, _, _ := unstructured.NestedString(obj, fields...)
+	return s
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	if n <= 3 {
+		return s[:n]
+	}
+	return s[:n-3] + "..."
+}
+
+// healthIcon maps an ArgoCD health status to an icon.
+func healthIcon(health string) string {
+	switch health {
+	case "Healthy":
+		return "💚"
+	case "Progressing":
+		return "🔄"
+	case "Degraded":
+		return "💔"
+	case "Suspended":
+		return "⏸️"
+	case "Missing":
+		return "❓"
+	default:
+		return "⚪"
+	}
+}
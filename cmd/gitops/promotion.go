@@ -0,0 +1,691 @@
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/utils"
+	"adhar-io/adhar/platform/utils/gitopsrepo"
+
+	"code.gitea.io/sdk/gitea"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// Promotion workflows live in ConfigMaps in the ArgoCD namespace: the
+// definition under workflowKey and the state of the latest run under runKey.
+// Every step persists its progress there, so a run survives the CLI exiting
+// and `trigger` picks it up where it stopped.
+const (
+	workflowConfigMapPrefix = "adhar-workflow-"
+	workflowLabel           = "adhar.io/gitops-workflow"
+	workflowKey             = "workflow.json"
+	runKey                  = "run.json"
+
+	promotionModeCommit = "commit"
+	promotionModePR     = "pr"
+
+	defaultStageTimeout = 10 * time.Minute
+	promotionPoll       = 5 * time.Second
+)
+
+// Run statuses.
+const (
+	runRunning   = "Running"
+	runWaiting   = "Waiting" // on an approval or a pull request merge
+	runStopped   = "Stopped"
+	runSucceeded = "Succeeded"
+	runFailed    = "Failed"
+)
+
+// Stage phases, in order.
+const (
+	stagePending          = "Pending"
+	stageAwaitingApproval = "AwaitingApproval"
+	stagePROpen           = "PROpen"
+	stageCommitted        = "Committed"
+	stageHealthy          = "Healthy"
+	stageSucceeded        = "Succeeded"
+	stageFailed           = "Failed"
+)
+
+var errRunStopped = errors.New("run was stopped")
+
+// promotionWorkflow is a promotion pipeline across environments, e.g.
+// dev → staging → prod. Each stage writes the promoted version into a YAML
+// file in the GitOps repository and waits for the stage's Application.
+type promotionWorkflow struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Repo        string           `json:"repo"`
+	Branch      string           `json:"branch,omitempty"`
+	Stages      []promotionStage `json:"stages"`
+}
+
+type promotionStage struct {
+	Name string `json:"name"`
+	// App is the ArgoCD Application deploying the stage (name or ns/name).
+	App string `json:"app"`
+	// File and Path locate the value to bump, e.g. envs/dev/values.yaml and
+	// image.tag, or kustomization.yaml and images[name=web].newTag.
+	File string `json:"file"`
+	Path string `json:"path"`
+	// Mode is commit (push to the branch) or pr (open a Gitea pull request
+	// and wait for it to be merged).
+	Mode string `json:"mode,omitempty"`
+	// Approval requires `workflow approve` before the stage starts.
+	Approval bool `json:"approval,omitempty"`
+	// Soak keeps the stage Healthy for this long before the next one starts.
+	Soak    string `json:"soak,omitempty"`
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// promotionRun is the persisted state of one promotion.
+type promotionRun struct {
+	ID        string     `json:"id"`
+	Version   string     `json:"version"`
+	Status    string     `json:"status"`
+	Current   int        `json:"current"`
+	StartedBy string     `json:"startedBy"`
+	StartedAt string     `json:"startedAt"`
+	UpdatedAt string     `json:"updatedAt"`
+	Message   string     `json:"message,omitempty"`
+	Stages    []stageRun `json:"stages"`
+}
+
+type stageRun struct {
+	Name       string `json:"name"`
+	Phase      string `json:"phase"`
+	Previous   string `json:"previous,omitempty"` // value before the bump
+	Commit     string `json:"commit,omitempty"`
+	PR         int64  `json:"pr,omitempty"`
+	PRURL      string `json:"prURL,omitempty"`
+	ApprovedBy string `json:"approvedBy,omitempty"`
+	HealthyAt  string `json:"healthyAt,omitempty"`
+	Message    string `json:"message,omitempty"`
+}
+
+// Active reports whether the run can still make progress.
+func (r *promotionRun) Active() bool {
+	return r != nil && r.Status != runSucceeded
+}
+
+var stageNamePattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+func (w *promotionWorkflow) validate() error {
+	if !stageNamePattern.MatchString(w.Name) {
+		return fmt.Errorf("workflow name %q must be a lowercase DNS label", w.Name)
+	}
+	if w.Repo == "" {
+		return fmt.Errorf("workflow %s: repo is required", w.Name)
+	}
+	if len(w.Stages) == 0 {
+		return fmt.Errorf("workflow %s: at least one stage is required", w.Name)
+	}
+	seen := map[string]bool{}
+	for i := range w.Stages {
+		s := &w.Stages[i]
+		if !stageNamePattern.MatchString(s.Name) {
+			return fmt.Errorf("stage %d: name %q must be a lowercase DNS label", i+1, s.Name)
+		}
+		if seen[s.Name] {
+			return fmt.Errorf("stage %s is defined twice", s.Name)
+		}
+		seen[s.Name] = true
+		if s.App == "" || s.File == "" || s.Path == "" {
+			return fmt.Errorf("stage %s: app, file and path are required", s.Name)
+		}
+		if _, err := parseYAMLPath(s.Path); err != nil {
+			return fmt.Errorf("stage %s: %w", s.Name, err)
+		}
+		s.Mode = valueOr(s.Mode, promotionModeCommit)
+		if s.Mode != promotionModeCommit && s.Mode != promotionModePR {
+			return fmt.Errorf("stage %s: mode must be %s or %s", s.Name, promotionModeCommit, promotionModePR)
+		}
+		for flag, v := range map[string]string{"soak": s.Soak, "timeout": s.Timeout} {
+			if v == "" {
+				continue
+			}
+			if _, err := time.ParseDuration(v); err != nil {
+				return fmt.Errorf("stage %s: invalid %s %q", s.Name, flag, v)
+			}
+		}
+	}
+	return nil
+}
+
+func (s promotionStage) timeout() time.Duration {
+	if d, err := time.ParseDuration(s.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return defaultStageTimeout
+}
+
+func (s promotionStage) soak() time.Duration {
+	d, _ := time.ParseDuration(s.Soak)
+	return d
+}
+
+// newPromotionRun starts a run of version through every stage of w.
+func newPromotionRun(w promotionWorkflow, version, who string) *promotionRun {
+	now := time.Now().UTC().Format(time.RFC3339)
+	run := &promotionRun{
+		ID:        time.Now().UTC().Format("20060102-150405"),
+		Version:   version,
+		Status:    runRunning,
+		StartedBy: who,
+		StartedAt: now,
+		UpdatedAt: now,
+	}
+	for _, s := range w.Stages {
+		run.Stages = append(run.Stages, stageRun{Name: s.Name, Phase: stagePending})
+	}
+	return run
+}
+
+// resume prepares a stopped, waiting or failed run to continue. A failed
+// stage is retried from its last durable step.
+func (r *promotionRun) resume() {
+	r.Status = runRunning
+	r.Message = ""
+	if r.Current < len(r.Stages) {
+		st := &r.Stages[r.Current]
+		if st.Phase == stageFailed {
+			st.Phase = stagePending
+			if st.Commit != "" {
+				st.Phase = stageCommitted
+			}
+			st.Message = ""
+		}
+	}
+}
+
+// --- ConfigMap store ---
+
+func workflowConfigMapName(name string) string {
+	return workflowConfigMapPrefix + name
+}
+
+// loadWorkflow returns the workflow definition and its latest run, if any.
+func loadWorkflow(ctx context.Context, cs kubernetes.Interface, name string) (promotionWorkflow, *promotionRun, error) {
+	var wf promotionWorkflow
+	cm, err := cs.CoreV1().ConfigMaps(utils.ArgocdNamespace).Get(ctx, workflowConfigMapName(name), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return wf, nil, fmt.Errorf("workflow %s not found; create it with `adhar gitops workflow create`", name)
+		}
+		return wf, nil, fmt.Errorf("failed to get workflow %s: %w", name, err)
+	}
+	return decodeWorkflow(cm)
+}
+
+func decodeWorkflow(cm *corev1.ConfigMap) (promotionWorkflow, *promotionRun, error) {
+	var wf promotionWorkflow
+	if err := json.Unmarshal([]byte(cm.Data[workflowKey]), &wf); err != nil {
+		return wf, nil, fmt.Errorf("workflow %s is corrupt: %w", cm.Name, err)
+	}
+	if cm.Data[runKey] == "" {
+		return wf, nil, nil
+	}
+	run := &promotionRun{}
+	if err := json.Unmarshal([]byte(cm.Data[runKey]), run); err != nil {
+		return wf, nil, fmt.Errorf("run state of workflow %s is corrupt: %w", wf.Name, err)
+	}
+	return wf, run, nil
+}
+
+// saveWorkflow creates or (with overwrite) replaces a workflow definition,
+// keeping its run state.
+func saveWorkflow(ctx context.Context, cs kubernetes.Interface, wf promotionWorkflow, overwrite bool) error {
+	data, err := json.MarshalIndent(wf, "", "  ")
+	if err != nil {
+		return err
+	}
+	cms := cs.CoreV1().ConfigMaps(utils.ArgocdNamespace)
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      workflowConfigMapName(wf.Name),
+			Namespace: utils.ArgocdNamespace,
+			Labels:    map[string]string{workflowLabel: wf.Name, "app.kubernetes.io/managed-by": "adhar"},
+		},
+		Data: map[string]string{workflowKey: string(data)},
+	}
+	_, err = cms.Create(ctx, cm, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		if !overwrite {
+			return fmt.Errorf("workflow %s already exists; pass --overwrite to replace it", wf.Name)
+		}
+		return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			cur, err := cms.Get(ctx, cm.Name, metav1.GetOptions{})
+			if err != nil {
+				return err
+			}
+			if _, run, _ := decodeWorkflow(cur); run != nil && run.Active() && run.Status != runFailed {
+				return fmt.Errorf("workflow %s has a run in progress (%s); stop it first", wf.Name, run.Status)
+			}
+			cur.Data[workflowKey] = string(data)
+			_, err = cms.Update(ctx, cur, metav1.UpdateOptions{})
+			return err
+		})
+	}
+	return err
+}
+
+// saveRun persists run. Unless force is set it refuses to overwrite a
+// newer run or to move a run that was stopped meanwhile, returning
+// errRunStopped so the caller can exit.
+func saveRun(ctx context.Context, cs kubernetes.Interface, name string, run *promotionRun, force bool) error {
+	run.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	cms := cs.CoreV1().ConfigMaps(utils.ArgocdNamespace)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := cms.Get(ctx, workflowConfigMapName(name), metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if !force {
+			_, cur, err := decodeWorkflow(cm)
+			if err != nil {
+				return err
+			}
+			if cur != nil && cur.ID != run.ID {
+				return fmt.Errorf("run %s was replaced by run %s", run.ID, cur.ID)
+			}
+			if cur != nil && cur.Status == runStopped && run.Status != runStopped {
+				return errRunStopped
+			}
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[runKey] = string(data)
+		_, err = cms.Update(ctx, cm, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// --- engine ---
+
+// promotionEngine drives a run forward, persisting after every step.
+type promotionEngine struct {
+	cs  kubernetes.Interface
+	dyn dynamic.Interface
+	wf  promotionWorkflow
+	run *promotionRun
+	who string
+}
+
+// Run advances the run until it succeeds, fails, is stopped or has to wait
+// for a person (an approval or a pull request merge).
+func (e *promotionEngine) Run(ctx context.Context) error {
+	err := e.advance(ctx)
+	if errors.Is(err, errRunStopped) {
+		fmt.Println(helpers.CreateWarning(fmt.Sprintf("⏹️  Run %s was stopped", e.run.ID)))
+		return nil
+	}
+	if err != nil {
+		e.run.Status = runFailed
+		e.run.Message = err.Error()
+		if e.run.Current < len(e.run.Stages) {
+			e.run.Stages[e.run.Current].Phase = stageFailed
+			e.run.Stages[e.run.Current].Message = err.Error()
+		}
+		if serr := saveRun(ctx, e.cs, e.wf.Name, e.run, false); serr != nil && !errors.Is(serr, errRunStopped) {
+			fmt.Println(helpers.CreateWarning("⚠️  Could not record the failure: " + serr.Error()))
+		}
+	}
+	return err
+}
+
+func (e *promotionEngine) advance(ctx context.Context) error {
+	for e.run.Current < len(e.wf.Stages) {
+		stage := e.wf.Stages[e.run.Current]
+		st := &e.run.Stages[e.run.Current]
+		label := fmt.Sprintf("[%d/%d %s]", e.run.Current+1, len(e.wf.Stages), stage.Name)
+
+		switch st.Phase {
+		case stagePending, stageAwaitingApproval:
+			if stage.Approval && st.ApprovedBy == "" {
+				st.Phase = stageAwaitingApproval
+				e.run.Status = runWaiting
+				e.run.Message = "waiting for approval of " + stage.Name
+				if err := e.save(ctx); err != nil {
+					return err
+				}
+				fmt.Printf("✋ %s Waiting for approval to promote %s\n", label, e.run.Version)
+				fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Approve with: adhar gitops workflow approve --name=%s", e.wf.Name)))
+				return nil
+			}
+			fmt.Printf("✏️  %s Setting %s in %s to %s\n", label, stage.Path, stage.File, e.run.Version)
+			if err := e.bump(ctx, stage, st); err != nil {
+				return err
+			}
+		case stagePROpen:
+			waiting, err := e.checkPR(ctx, stage, st)
+			if err != nil {
+				return err
+			}
+			if waiting {
+				e.run.Status = runWaiting
+				e.run.Message = fmt.Sprintf("waiting for PR #%d to be merged", st.PR)
+				if err := e.save(ctx); err != nil {
+					return err
+				}
+				fmt.Printf("🔀 %s Waiting for pull request #%d to be merged: %s\n", label, st.PR, st.PRURL)
+				fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Resume after merging with: adhar gitops workflow trigger --name=%s", e.wf.Name)))
+				return nil
+			}
+			fmt.Printf("🔀 %s Pull request #%d merged as %s\n", label, st.PR, shortSHA(st.Commit))
+		case stageCommitted:
+			fmt.Printf("⏳ %s Waiting for %s to deploy %s\n", label, stage.App, shortSHA(st.Commit))
+			if err := e.waitDeployed(ctx, stage, st.Commit); err != nil {
+				return err
+			}
+			st.Phase = stageHealthy
+			st.HealthyAt = time.Now().UTC().Format(time.RFC3339)
+			fmt.Println(helpers.CreateSuccess(fmt.Sprintf("💚 %s %s is Healthy at %s", label, stage.App, shortSHA(st.Commit))))
+		case stageHealthy:
+			if err := e.soak(ctx, stage, st); err != nil {
+				return err
+			}
+			st.Phase = stageSucceeded
+		case stageSucceeded:
+			e.run.Current++
+			continue
+		default:
+			return fmt.Errorf("stage %s is in unknown phase %q", stage.Name, st.Phase)
+		}
+		if e.run.Status != runRunning {
+			e.run.Status, e.run.Message = runRunning, ""
+		}
+		if err := e.save(ctx); err != nil {
+			return err
+		}
+	}
+	e.run.Status = runSucceeded
+	e.run.Message = fmt.Sprintf("%s promoted through %d stages", e.run.Version, len(e.wf.Stages))
+	return e.save(ctx)
+}
+
+func (e *promotionEngine) save(ctx context.Context) error {
+	return saveRun(ctx, e.cs, e.wf.Name, e.run, false)
+}
+
+// bump writes the version into the stage's file, as a direct commit or a
+// pull request. A file that already holds the version goes straight to
+// waiting on the current commit.
+func (e *promotionEngine) bump(ctx context.Context, stage promotionStage, st *stageRun) error {
+	var lastErr error
+	for attempt := 0; attempt < 3; attempt++ {
+		repo, err := openGitopsRepo(ctx, e.wf.Repo, e.wf.Branch)
+		if err != nil {
+			return err
+		}
+		data, err := repo.ReadFile(stage.File)
+		if err != nil {
+			return err
+		}
+		updated, previous, err := setYAMLValue(data, stage.Path, e.run.Version)
+		if err != nil {
+			return fmt.Errorf("%s: %w", stage.File, err)
+		}
+		if st.Previous == "" {
+			st.Previous = previous
+		}
+		if previous == e.run.Version {
+			head, err := repo.Head()
+			if err != nil {
+				return err
+			}
+			st.Commit, st.Phase = head, stageCommitted
+			fmt.Println(helpers.CreateMuted(fmt.Sprintf("   %s already at %s", stage.File, e.run.Version)))
+			return nil
+		}
+		if _, err := repo.WriteFile(stage.File, updated); err != nil {
+			return err
+		}
+		msg := fmt.Sprintf("Promote %s to %s\n\nWorkflow %s, run %s: %s %s -> %s in %s.\n",
+			stage.Name, e.run.Version, e.wf.Name, e.run.ID, stage.Path, previous, e.run.Version, stage.File)
+		sha, err := repo.Commit(msg, e.who)
+		if err != nil {
+			return err
+		}
+
+		if stage.Mode == promotionModePR {
+			return e.openPR(ctx, repo, stage, st, msg)
+		}
+		if lastErr = repo.Push(ctx, ""); lastErr == nil {
+			st.Commit, st.Phase = sha, stageCommitted
+			fmt.Println(helpers.CreateSuccess(fmt.Sprintf("   ✅ Pushed %s to %s", shortSHA(sha), repo.Branch)))
+			return nil
+		}
+		// Most likely someone pushed meanwhile; re-clone and retry.
+		fmt.Println(helpers.CreateMuted("   Push rejected, retrying: " + lastErr.Error()))
+	}
+	return lastErr
+}
+
+func (e *promotionEngine) openPR(ctx context.Context, repo *gitopsrepo.Repo, stage promotionStage, st *stageRun, msg string) error {
+	branch := fmt.Sprintf("promote/%s/%s-%s", e.wf.Name, stage.Name, sanitizeRef(e.run.Version))
+	if err := repo.Push(ctx, branch); err != nil {
+		return err
+	}
+	owner, name, err := gitopsrepo.OwnerRepo(e.wf.Repo)
+	if err != nil {
+		return err
+	}
+	client, err := giteaClient(ctx)
+	if err != nil {
+		return err
+	}
+	// A retried stage keeps its pull request while it is still open: the
+	// forced push above already updated its branch.
+	if st.PR != 0 {
+		if pr, _, err := client.GetPullRequest(owner, name, st.PR); err == nil && reusablePR(pr, branch) {
+			st.PRURL, st.Phase = pr.HTMLURL, stagePROpen
+			fmt.Println(helpers.CreateSuccess(fmt.Sprintf("   ✅ Updated pull request #%d: %s", pr.Index, pr.HTMLURL)))
+			return nil
+		}
+	}
+	title, body, _ := strings.Cut(msg, "\n\n")
+	pr, _, err := client.CreatePullRequest(owner, name, gitea.CreatePullRequestOption{
+		Head:  branch,
+		Base:  repo.Branch,
+		Title: title,
+		Body:  body + fmt.Sprintf("\nRequested by %s via `adhar gitops workflow`.\n", e.who),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open a pull request for %s: %w", stage.Name, err)
+	}
+	st.PR, st.PRURL, st.Phase = pr.Index, pr.HTMLURL, stagePROpen
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("   ✅ Opened pull request #%d: %s", pr.Index, pr.HTMLURL)))
+	return nil
+}
+
+// reusablePR reports whether pr is still open for the promotion branch.
+func reusablePR(pr *gitea.PullRequest, branch string) bool {
+	return pr.State == gitea.StateOpen && !pr.HasMerged && pr.Head != nil && pr.Head.Ref == branch
+}
+
+// checkPR reports whether the stage's pull request is still open. A merged
+// PR moves the stage on; one closed without merging fails it.
+func (e *promotionEngine) checkPR(ctx context.Context, stage promotionStage, st *stageRun) (bool, error) {
+	owner, name, err := gitopsrepo.OwnerRepo(e.wf.Repo)
+	if err != nil {
+		return false, err
+	}
+	client, err := giteaClient(ctx)
+	if err != nil {
+		return false, err
+	}
+	pr, _, err := client.GetPullRequest(owner, name, st.PR)
+	if err != nil {
+		return false, fmt.Errorf("failed to get pull request #%d: %w", st.PR, err)
+	}
+	switch {
+	case pr.HasMerged && pr.MergedCommitID != nil:
+		st.Commit, st.Phase = *pr.MergedCommitID, stageCommitted
+		return false, nil
+	case pr.State == gitea.StateClosed:
+		return false, fmt.Errorf("pull request #%d for %s was closed without merging", st.PR, stage.Name)
+	default:
+		return true, nil
+	}
+}
+
+// waitDeployed nudges the stage's Application to pick up commit and waits
+// until it is deployed, Synced and Healthy.
+func (e *promotionEngine) waitDeployed(ctx context.Context, stage promotionStage, commit string) error {
+	ns, name := splitAppRef(stage.App)
+	apps := e.dyn.Resource(argoApplicationGVR).Namespace(ns)
+	obj, err := apps.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get application %s: %w", stage.App, err)
+	}
+	if _, auto, _ := unstructured.NestedMap(obj.Object, "spec", "syncPolicy", "automated"); auto {
+		patch := []byte(`{"metadata":{"annotations":{"argocd.argoproj.io/refresh":"normal"}}}`)
+		if _, err := apps.Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("failed to refresh %s: %w", stage.App, err)
+		}
+	} else {
+		op := map[string]interface{}{
+			"initiatedBy": map[string]interface{}{"username": e.who},
+			"info":        []interface{}{map[string]interface{}{"name": "Reason", "value": fmt.Sprintf("workflow %s: promote %s", e.wf.Name, e.run.Version)}},
+			"sync":        map[string]interface{}{"revision": commit},
+		}
+		if err := requestSync(ctx, e.dyn, obj, op); err != nil {
+			return fmt.Errorf("failed to sync %s: %w", stage.App, err)
+		}
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, stage.timeout())
+	defer cancel()
+	last := ""
+	for {
+		if err := e.checkStopped(cctx); err != nil {
+			return err
+		}
+		cur, err := apps.Get(cctx, name, metav1.GetOptions{})
+		if err == nil {
+			state := appDeployState(cur.Object, commit)
+			if state.String() != last {
+				last = state.String()
+				fmt.Println(helpers.CreateMuted("   " + last))
+			}
+			if state.Failed != "" {
+				return fmt.Errorf("%s failed to sync %s: %s", stage.App, shortSHA(commit), state.Failed)
+			}
+			if state.Deployed && state.Sync == "Synced" && state.Health == "Healthy" {
+				return nil
+			}
+		}
+		select {
+		case <-cctx.Done():
+			return fmt.Errorf("%s did not become Synced and Healthy at %s within %s (last: %s)", stage.App, shortSHA(commit), stage.timeout(), last)
+		case <-time.After(promotionPoll):
+		}
+	}
+}
+
+// soak keeps watching the stage's health for its soak period.
+func (e *promotionEngine) soak(ctx context.Context, stage promotionStage, st *stageRun) error {
+	healthyAt, err := time.Parse(time.RFC3339, st.HealthyAt)
+	if err != nil {
+		healthyAt = time.Now()
+	}
+	until := healthyAt.Add(stage.soak())
+	if time.Now().After(until) {
+		return nil
+	}
+	fmt.Printf("🧪 Soaking %s until %s\n", stage.Name, until.Local().Format(time.Kitchen))
+	ns, name := splitAppRef(stage.App)
+	for time.Now().Before(until) {
+		if err := e.checkStopped(ctx); err != nil {
+			return err
+		}
+		cur, err := e.dyn.Resource(argoApplicationGVR).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			if h := nestedString(cur.Object, "status", "health", "status"); h == "Degraded" || h == "Missing" {
+				return fmt.Errorf("%s turned %s during the soak period", stage.App, h)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(promotionPoll):
+		}
+	}
+	return nil
+}
+
+// checkStopped returns errRunStopped once `workflow stop` has marked the run.
+func (e *promotionEngine) checkStopped(ctx context.Context) error {
+	_, cur, err := loadWorkflow(ctx, e.cs, e.wf.Name)
+	if err != nil {
+		return nil
+	}
+	if cur != nil && cur.ID == e.run.ID && cur.Status == runStopped {
+		return errRunStopped
+	}
+	return nil
+}
+
+// deployState summarises an Application relative to a target commit.
+type deployState struct {
+	Revision string
+	Sync     string
+	Health   string
+	Deployed bool   // commit is live or in the deployment history
+	Failed   string // message of a failed sync of commit
+}
+
+func (s deployState) String() string {
+	return fmt.Sprintf("revision %s, %s, %s", shortSHA(valueOr(s.Revision, "-")), valueOr(s.Sync, "Unknown"), valueOr(s.Health, "Unknown"))
+}
+
+func appDeployState(obj map[string]interface{}, commit string) deployState {
+	s := deployState{
+		Revision: nestedString(obj, "status", "sync", "revision"),
+		Sync:     nestedString(obj, "status", "sync", "status"),
+		Health:   nestedString(obj, "status", "health", "status"),
+	}
+	s.Deployed = s.Revision == commit
+	history, _, _ := unstructured.NestedSlice(obj, "status", "history")
+	for _, h := range history {
+		if m, ok := h.(map[string]interface{}); ok && nestedString(m, "revision") == commit {
+			s.Deployed = true
+		}
+	}
+	phase := nestedString(obj, "status", "operationState", "phase")
+	if (phase == "Failed" || phase == "Error") && nestedString(obj, "status", "operationState", "syncResult", "revision") == commit {
+		s.Failed = valueOr(nestedString(obj, "status", "operationState", "message"), phase)
+	}
+	return s
+}
+
+// sanitizeRef makes a version usable in a branch name.
+func sanitizeRef(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '-'
+		}
+	}, s)
+}
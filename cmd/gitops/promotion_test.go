@@ -0,0 +1,150 @@
+package gitops
+
+import (
+	"strings"
+	"testing"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+func TestSetYAMLValue(t *testing.T) {
+	doc := `# web values
+image:
+  repository: ghcr.io/acme/web
+  tag: v1.0.0 # bumped by promotion
+images:
+  - name: worker
+    newTag: "1.0"
+  - name: web
+    newTag: "1.0"
+`
+	out, old, err := setYAMLValue([]byte(doc), "image.tag", "v1.1.0")
+	if err != nil || old != "v1.0.0" {
+		t.Fatalf("setYAMLValue = %q, %v", old, err)
+	}
+	if !strings.Contains(string(out), "tag: v1.1.0 # bumped by promotion") || !strings.HasPrefix(string(out), "# web values") {
+		t.Errorf("comments not preserved:\n%s", out)
+	}
+
+	out, old, err = setYAMLValue([]byte(doc), "images[name=web].newTag", "1.10")
+	if err != nil || old != "1.0" {
+		t.Fatalf("selector set = %q, %v", old, err)
+	}
+	if got, _ := getYAMLValue(out, "images[1].newTag"); got != "1.10" {
+		t.Errorf("images[1].newTag = %q, want 1.10 (kept as a string)", got)
+	}
+	if got, _ := getYAMLValue(out, "images[0].newTag"); got != "1.0" {
+		t.Errorf("worker tag changed to %q", got)
+	}
+
+	if same, _, _ := setYAMLValue([]byte(doc), "image.tag", "v1.0.0"); string(same) != doc {
+		t.Errorf("unchanged value should return the input untouched")
+	}
+	for _, bad := range []string{"image", "image.missing", "images[name=db].newTag", "images[5].newTag", "image..tag", "images[].x"} {
+		if _, _, err := setYAMLValue([]byte(doc), bad, "x"); err == nil {
+			t.Errorf("setYAMLValue(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseStageSpecAndValidate(t *testing.T) {
+	s, err := parseStageSpec("name=prod,app=argocd/web-prod,file=envs/prod/values.yaml,path=image.tag,mode=pr,approval=true,soak=10m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "prod" || s.App != "argocd/web-prod" || !s.Approval || s.Mode != promotionModePR || s.soak().Minutes() != 10 {
+		t.Errorf("stage = %+v", s)
+	}
+	if s.timeout() != defaultStageTimeout {
+		t.Errorf("default timeout = %s", s.timeout())
+	}
+	for _, bad := range []string{"name", "name=dev,colour=blue", "approval=maybe"} {
+		if _, err := parseStageSpec(bad); err == nil {
+			t.Errorf("parseStageSpec(%q) should fail", bad)
+		}
+	}
+
+	dev := promotionStage{Name: "dev", App: "web-dev", File: "dev.yaml", Path: "image.tag"}
+	wf := promotionWorkflow{Name: "web", Repo: "https://gitea/acme/deploy.git", Stages: []promotionStage{dev, s}}
+	if err := wf.validate(); err != nil {
+		t.Fatalf("validate = %v", err)
+	}
+	if wf.Stages[0].Mode != promotionModeCommit {
+		t.Errorf("mode should default to commit")
+	}
+	dup := wf
+	dup.Stages = []promotionStage{dev, dev}
+	if err := dup.validate(); err == nil {
+		t.Errorf("duplicate stage names should fail")
+	}
+	badMode := promotionWorkflow{Name: "web", Repo: "r", Stages: []promotionStage{{Name: "dev", App: "a", File: "f", Path: "p", Mode: "email"}}}
+	if err := badMode.validate(); err == nil {
+		t.Errorf("unknown mode should fail")
+	}
+}
+
+func TestPromotionRunResume(t *testing.T) {
+	wf := promotionWorkflow{Stages: []promotionStage{{Name: "dev"}, {Name: "prod"}}}
+	run := newPromotionRun(wf, "v2", "alice")
+	if len(run.Stages) != 2 || run.Stages[1].Phase != stagePending || !run.Active() {
+		t.Fatalf("new run = %+v", run)
+	}
+	run.Current = 1
+	run.Status = runFailed
+	run.Stages[1] = stageRun{Name: "prod", Phase: stageFailed, Commit: "abc", Message: "timed out"}
+	run.resume()
+	if run.Status != runRunning || run.Stages[1].Phase != stageCommitted || run.Stages[1].Message != "" {
+		t.Errorf("failed stage with a commit should resume waiting on it: %+v", run.Stages[1])
+	}
+	run.Stages[1] = stageRun{Name: "prod", Phase: stageFailed}
+	run.resume()
+	if run.Stages[1].Phase != stagePending {
+		t.Errorf("failed stage without a commit should restart: %+v", run.Stages[1])
+	}
+	var none *promotionRun
+	if none.Active() {
+		t.Errorf("nil run is not active")
+	}
+}
+
+func TestReusablePR(t *testing.T) {
+	branch := "promote/web/prod-v2"
+	for name, tc := range map[string]struct {
+		pr   gitea.PullRequest
+		want bool
+	}{
+		"open":         {gitea.PullRequest{State: gitea.StateOpen, Head: &gitea.PRBranchInfo{Ref: branch}}, true},
+		"closed":       {gitea.PullRequest{State: gitea.StateClosed, Head: &gitea.PRBranchInfo{Ref: branch}}, false},
+		"merged":       {gitea.PullRequest{State: gitea.StateOpen, HasMerged: true, Head: &gitea.PRBranchInfo{Ref: branch}}, false},
+		"other branch": {gitea.PullRequest{State: gitea.StateOpen, Head: &gitea.PRBranchInfo{Ref: "promote/web/prod-v1"}}, false},
+	} {
+		if got := reusablePR(&tc.pr, branch); got != tc.want {
+			t.Errorf("%s: reusablePR = %v, want %v", name, got, tc.want)
+		}
+	}
+}
+
+func TestAppDeployState(t *testing.T) {
+	app := map[string]interface{}{
+		"status": map[string]interface{}{
+			"sync":    map[string]interface{}{"revision": "new", "status": "Synced"},
+			"health":  map[string]interface{}{"status": "Healthy"},
+			"history": []interface{}{map[string]interface{}{"revision": "ours"}},
+		},
+	}
+	if s := appDeployState(app, "ours"); !s.Deployed || s.Failed != "" {
+		t.Errorf("commit in history should count as deployed: %+v", s)
+	}
+	if s := appDeployState(app, "other"); s.Deployed {
+		t.Errorf("unknown commit should not be deployed")
+	}
+	app["status"].(map[string]interface{})["operationState"] = map[string]interface{}{
+		"phase": "Failed", "message": "hook failed", "syncResult": map[string]interface{}{"revision": "other"},
+	}
+	if s := appDeployState(app, "other"); s.Failed != "hook failed" {
+		t.Errorf("Failed = %q", s.Failed)
+	}
+	if sanitizeRef("v1.2.0+build/7") != "v1.2.0-build-7" {
+		t.Errorf("sanitizeRef = %q", sanitizeRef("v1.2.0+build/7"))
+	}
+}
@@ -1,22 +1,54 @@
 package gitops
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 
-	"adhar-io/adhar/platform/logger"
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/k8s"
+	"adhar-io/adhar/platform/utils"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
 )
 
 var repoCmd = &cobra.Command{
-	Use:   "repo",
+	Use:   "repo [list|add|update|remove|test]",
 	Short: "Manage Git repositories",
-	Long: `Manage Git repositories for GitOps operations.
-	
+	Long: `Manage the Git and Helm repositories ArgoCD can deploy from.
+
+Repositories and credential templates are ArgoCD Secrets labelled
+argocd.argoproj.io/secret-type=repository or repo-creds in the ArgoCD
+namespace. A credential template (--creds) applies to every repository whose
+URL starts with its URL, so one entry can cover a whole Git organisation.
+
+Supported credentials:
+  HTTPS basic   --username/--password
+  SSH key       --ssh-private-key-path (ssh:// or git@host:path URLs)
+  GitHub App    --github-app-id, --github-app-installation-id,
+                --github-app-private-key-path [--github-app-enterprise-base-url]
+  Helm / OCI    --type=helm [--oci] with optional --username/--password
+
+add and update test the connection first (git ls-remote, the Helm index or the
+OCI registry API); pass --skip-test to store the credentials anyway.
+
 Examples:
   adhar gitops repo list
-  adhar gitops repo add --url=git://repo.git
-  adhar gitops repo remove --url=git://repo.git`,
+  adhar gitops repo add --url=https://github.com/acme/deploy.git --username=bot --password=$TOKEN
+  adhar gitops repo add --url=git@github.com:acme/infra.git --ssh-private-key-path=~/.ssh/id_ed25519
+  adhar gitops repo add --creds --url=https://github.com/acme --github-app-id=1 --github-app-installation-id=2 --github-app-private-key-path=app.pem
+  adhar gitops repo add --type=helm --oci --url=ghcr.io/acme/charts --username=bot --password=$TOKEN
+  adhar gitops repo test --url=https://github.com/acme/deploy.git
+  adhar gitops repo remove --url=https://github.com/acme/deploy.git`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runRepo,
 }
 
@@ -24,114 +56,485 @@ var (
 	repoURL    string
 	repoName   string
 	repoAction string
+
+	repoType             string
+	repoProject          string
+	repoOCI              bool
+	repoTemplate         bool
+	repoUsername         string
+	repoPassword         string
+	repoSSHKeyPath       string
+	repoAppID            string
+	repoAppInstallation  string
+	repoAppKeyPath       string
+	repoAppEnterpriseURL string
+	repoInsecure         bool
+	repoSkipTest         bool
+	repoForce            bool
+	repoRefresh          bool
 )
 
 func init() {
-	repoCmd.Flags().StringVarP(&repoURL, "url", "u", "", "Repository URL")
-	repoCmd.Flags().StringVarP(&repoName, "name", "n", "", "Repository name")
-	repoCmd.Flags().StringVarP(&repoAction, "action", "a", "", "Action (add, remove, update, test)")
+	f := repoCmd.Flags()
+	f.StringVarP(&repoURL, "url", "u", "", "Repository URL (or URL prefix with --creds)")
+	f.StringVarP(&repoName, "name", "n", "", "Display name (Helm repositories use it as the repo alias)")
+	f.StringVarP(&repoAction, "action", "a", "", "Action (add, remove, update, test); same as the positional argument")
+	f.StringVar(&repoType, "type", "git", "Repository type: git or helm")
+	f.StringVar(&repoProject, "project", "", "Restrict the repository to an ArgoCD project")
+	f.BoolVar(&repoOCI, "oci", false, "Helm repository is an OCI registry")
+	f.BoolVar(&repoTemplate, "creds", false, "Manage a credential template (repo-creds) instead of a repository")
+	f.StringVar(&repoUsername, "username", "", "Username for HTTPS or registry authentication")
+	f.StringVar(&repoPassword, "password", "", "Password or access token for HTTPS or registry authentication")
+	f.StringVar(&repoSSHKeyPath, "ssh-private-key-path", "", "Path to an SSH private key")
+	f.StringVar(&repoAppID, "github-app-id", "", "GitHub App ID")
+	f.StringVar(&repoAppInstallation, "github-app-installation-id", "", "GitHub App installation ID")
+	f.StringVar(&repoAppKeyPath, "github-app-private-key-path", "", "Path to the GitHub App private key")
+	f.StringVar(&repoAppEnterpriseURL, "github-app-enterprise-base-url", "", "GitHub Enterprise API URL, e.g. https://ghe.example.com/api/v3")
+	f.BoolVar(&repoInsecure, "insecure", false, "Skip TLS verification and SSH host key checking")
+	f.BoolVar(&repoSkipTest, "skip-test", false, "Store credentials without testing the connection")
+	f.BoolVar(&repoForce, "force", false, "Remove a repository even if applications still use it")
+	f.BoolVar(&repoRefresh, "refresh", false, "Make ArgoCD re-test every repository when listing")
 }
 
 func runRepo(cmd *cobra.Command, args []string) error {
-	logger.Info("📚 Managing Git repositories...")
+	action := repoAction
+	if len(args) == 1 {
+		action = args[0]
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return unreachable(err)
+	}
 
-	switch repoAction {
+	switch action {
 	case "add":
-		return addRepository(repoURL, repoName)
-	case "remove":
-		return removeRepository(repoURL)
+		return addRepository(ctx, cs, cmd.Flags())
+	case "remove", "rm", "delete":
+		return removeRepository(ctx, cs)
 	case "update":
-		return updateRepository(repoURL)
+		return updateRepository(ctx, cs, cmd.Flags())
 	case "test":
-		return testRepository(repoURL)
+		return testRepository(ctx, cs, cmd.Flags())
+	case "", "list", "ls":
+		return listRepositories(ctx, cs)
 	default:
-		return listRepositories()
+		return fmt.Errorf("unknown repo action %q (want list, add, update, remove or test)", action)
 	}
 }
 
-func addRepository(url, name string) error {
-	if url == "" {
-		return fmt.Errorf("--url is required for adding repository")
+func addRepository(ctx context.Context, cs kubernetes.Interface, flags *pflag.FlagSet) error {
+	cred, err := applyRepoFlags(repoCredential{URL: repoURL, Type: repoType, Template: repoTemplate}, flags)
+	if err != nil {
+		return err
+	}
+	if err := cred.validate(); err != nil {
+		return err
+	}
+	existing, err := findRepoSecret(ctx, cs, cred.URL, cred.Template)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("%s is already configured (secret %s); use `adhar gitops repo update`", cred.URL, existing.Name)
 	}
 
-	logger.Info(fmt.Sprintf("➕ Adding repository: %s", url))
+	fmt.Println(helpers.TitleStyle.Render(fmt.Sprintf("➕ Adding %s %s", describeCredential(cred), cred.URL)))
+	if err := testBeforeSave(ctx, cred); err != nil {
+		return err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        repoSecretName(cred.URL, cred.Template),
+			Namespace:   utils.ArgocdNamespace,
+			Labels:      map[string]string{argoSecretTypeLabel: cred.secretType(), "app.kubernetes.io/managed-by": "adhar"},
+			Annotations: map[string]string{argoManagedByAnnotation: argoManagedByValue},
+		},
+		Data: cred.secretData(),
+	}
+	if _, err := cs.CoreV1().Secrets(utils.ArgocdNamespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create secret %s: %w", secret.Name, err)
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Added %s (secret %s/%s)", cred.URL, secret.Namespace, secret.Name)))
+	return nil
+}
 
-	// TODO: Implement repository addition
-	// This should:
-	// - Validate repository URL
-	// - Test connectivity
-	// - Add to ArgoCD
-	// - Configure authentication if needed
-	// - Verify addition
+func updateRepository(ctx context.Context, cs kubernetes.Interface, flags *pflag.FlagSet) error {
+	if repoURL == "" {
+		return fmt.Errorf("--url is required for updating repository")
+	}
+	secret, err := findRepoSecret(ctx, cs, repoURL, repoTemplate)
+	if err != nil {
+		return err
+	}
+	if secret == nil {
+		return fmt.Errorf("%s is not configured; use `adhar gitops repo add`", repoURL)
+	}
+	cred, err := applyRepoFlags(credentialFromSecret(secret), flags)
+	if err != nil {
+		return err
+	}
+	if err := cred.validate(); err != nil {
+		return err
+	}
 
-	logger.Info("✅ Repository added successfully")
+	fmt.Println(helpers.TitleStyle.Render(fmt.Sprintf("🔄 Updating %s %s", describeCredential(cred), cred.URL)))
+	if err := testBeforeSave(ctx, cred); err != nil {
+		return err
+	}
+	secret.Data = cred.secretData()
+	secret.StringData = nil
+	if _, err := cs.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update secret %s: %w", secret.Name, err)
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Updated %s (secret %s/%s)", cred.URL, secret.Namespace, secret.Name)))
 	return nil
 }
 
-func removeRepository(url string) error {
-	if url == "" {
+func removeRepository(ctx context.Context, cs kubernetes.Interface) error {
+	if repoURL == "" {
 		return fmt.Errorf("--url is required for removing repository")
 	}
+	secret, err := findRepoSecret(ctx, cs, repoURL, repoTemplate)
+	if err != nil {
+		return err
+	}
+	if secret == nil {
+		return fmt.Errorf("%s is not configured", repoURL)
+	}
+	if !repoTemplate {
+		users, err := applicationsUsingRepo(ctx, repoURL)
+		if err != nil {
+			fmt.Println(helpers.CreateWarning("⚠️  Could not check which applications use the repository: " + err.Error()))
+		}
+		if len(users) > 0 && !repoForce {
+			return fmt.Errorf("%s is used by %s; pass --force to remove it anyway", repoURL, strings.Join(users, ", "))
+		}
+	}
+	if err := cs.CoreV1().Secrets(secret.Namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete secret %s: %w", secret.Name, err)
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Removed %s (secret %s/%s)", repoURL, secret.Namespace, secret.Name)))
+	return nil
+}
 
-	logger.Info(fmt.Sprintf("➖ Removing repository: %s", url))
+// testRepository tests the credentials given on the command line, or those
+// ArgoCD would use for the URL when none are given.
+func testRepository(ctx context.Context, cs kubernetes.Interface, flags *pflag.FlagSet) error {
+	if repoURL == "" {
+		return fmt.Errorf("--url is required for testing repository")
+	}
+	base, source, err := resolveCredential(ctx, cs, repoURL)
+	if err != nil {
+		return err
+	}
+	if hasCredentialFlags(flags) {
+		source = "command line"
+	}
+	cred, err := applyRepoFlags(base, flags)
+	if err != nil {
+		return err
+	}
 
-	// TODO: Implement repository removal
-	// This should:
-	// - Check if repository is in use
-	// - Remove from ArgoCD
-	// - Clean up configurations
-	// - Verify removal
+	fmt.Println(helpers.TitleStyle.Render(fmt.Sprintf("🧪 Testing %s", cred.URL)))
+	fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Credentials: %s (%s)", cred.authKind(), source)))
+	res, err := probeRepository(ctx, cred)
+	if err != nil {
+		fmt.Println(helpers.ErrorStyle.Render("❌ " + err.Error()))
+		return fmt.Errorf("connection test for %s failed", cred.URL)
+	}
+	fmt.Println(helpers.CreateSuccess("✅ Connected: " + res.Detail))
+	for i, ref := range res.Refs {
+		if i == 10 {
+			fmt.Println(helpers.CreateMuted(fmt.Sprintf("   ... and %d more", len(res.Refs)-i)))
+			break
+		}
+		fmt.Println(helpers.CreateMuted("   " + ref))
+	}
+	return nil
+}
 
-	logger.Info("✅ Repository removed successfully")
+func listRepositories(ctx context.Context, cs kubernetes.Interface) error {
+	fmt.Println(helpers.TitleStyle.Render("📚 ArgoCD Repositories"))
+	repos, err := listRepoSecrets(ctx, cs, argoSecretRepository)
+	if err != nil {
+		return err
+	}
+	templates, err := listRepoSecrets(ctx, cs, argoSecretRepoCreds)
+	if err != nil {
+		return err
+	}
+	if len(repos)+len(templates) == 0 {
+		fmt.Println(helpers.CreateMuted("   No repositories configured. Add one with `adhar gitops repo add --url=...`"))
+		return nil
+	}
+
+	states := map[string]argoConnectionState{}
+	if len(repos) > 0 {
+		if api, err := newArgocdAPI(ctx); err != nil {
+			fmt.Println(helpers.CreateWarning("⚠️  Connection state unavailable: " + err.Error()))
+		} else if states, err = api.repositoryStates(ctx, repoRefresh); err != nil {
+			fmt.Println(helpers.CreateWarning("⚠️  Connection state unavailable: " + err.Error()))
+		}
+	}
+
+	var creds []repoCredential
+	for i := range templates {
+		creds = append(creds, credentialFromSecret(&templates[i]))
+	}
+	fmt.Printf("%-48s %-6s %-11s %-12s %s\n", "URL", "TYPE", "AUTH", "PROJECT", "STATUS")
+	fmt.Println(strings.Repeat("─", 100))
+	var failures []string
+	for i := range repos {
+		c := credentialFromSecret(&repos[i])
+		auth := c.authKind()
+		if auth == "anonymous" {
+			if t, ok := matchingTemplate(creds, c.URL); ok {
+				auth = t.authKind() + "*"
+			}
+		}
+		status := "-"
+		if st, ok := states[normalizeRepoURL(c.URL)]; ok {
+			status = connectionIcon(st.Status) + " " + st.Status
+			if st.Status == "Failed" && st.Message != "" {
+				failures = append(failures, fmt.Sprintf("%s: %s", c.URL, st.Message))
+			}
+		}
+		fmt.Printf("%-48s %-6s %-11s %-12s %s\n", truncate(c.URL, 48), describeType(c), auth, valueOr(c.Project, "-"), status)
+	}
+	for _, c := range creds {
+		fmt.Printf("%-48s %-6s %-11s %-12s %s\n", truncate(c.URL+"*", 48), describeType(c), c.authKind(), valueOr(c.Project, "-"), "🔑 template")
+	}
+	for _, f := range failures {
+		fmt.Println(helpers.ErrorStyle.Render("❌ " + truncate(f, 200)))
+	}
+	if len(creds) > 0 {
+		fmt.Println(helpers.CreateMuted("   * credentials inherited from a matching template"))
+	}
 	return nil
 }
 
-func updateRepository(url string) error {
-	if url == "" {
-		return fmt.Errorf("--url is required for updating repository")
+// applyRepoFlags overlays the flags that were set on base. Choosing a new
+// authentication method clears the previous one.
+func applyRepoFlags(base repoCredential, flags *pflag.FlagSet) (repoCredential, error) {
+	c := base
+	changed := flags.Changed
+	if changed("name") {
+		c.Name = repoName
+	}
+	if changed("type") {
+		c.Type = repoType
+	}
+	if changed("project") {
+		c.Project = repoProject
+	}
+	if changed("oci") {
+		c.OCI = repoOCI
+		if repoOCI && !changed("type") {
+			c.Type = "helm"
+		}
+	}
+	if changed("insecure") {
+		c.Insecure = repoInsecure
+	}
+	if changed("username") || changed("password") {
+		c.SSHPrivateKey, c.GitHubAppID, c.GitHubAppInstallationID, c.GitHubAppPrivateKey = "", "", "", ""
+		if changed("username") {
+			c.Username = repoUsername
+		}
+		if changed("password") {
+			c.Password = repoPassword
+		}
+	}
+	if changed("ssh-private-key-path") {
+		key, err := readKeyFile(repoSSHKeyPath)
+		if err != nil {
+			return c, err
+		}
+		c.SSHPrivateKey, c.Username, c.Password = key, "", ""
+		c.GitHubAppID, c.GitHubAppInstallationID, c.GitHubAppPrivateKey = "", "", ""
+	}
+	if changed("github-app-id") || changed("github-app-installation-id") || changed("github-app-private-key-path") {
+		c.Username, c.Password, c.SSHPrivateKey = "", "", ""
+		if changed("github-app-id") {
+			c.GitHubAppID = repoAppID
+		}
+		if changed("github-app-installation-id") {
+			c.GitHubAppInstallationID = repoAppInstallation
+		}
+		if changed("github-app-private-key-path") {
+			key, err := readKeyFile(repoAppKeyPath)
+			if err != nil {
+				return c, err
+			}
+			c.GitHubAppPrivateKey = key
+		}
 	}
+	if changed("github-app-enterprise-base-url") {
+		c.GitHubAppEnterpriseURL = repoAppEnterpriseURL
+	}
+	return c, nil
+}
 
-	logger.Info(fmt.Sprintf("🔄 Updating repository: %s", url))
+func hasCredentialFlags(flags *pflag.FlagSet) bool {
+	for _, name := range []string{"username", "password", "ssh-private-key-path", "github-app-id", "github-app-installation-id", "github-app-private-key-path"} {
+		if flags.Changed(name) {
+			return true
+		}
+	}
+	return false
+}
 
-	// TODO: Implement repository update
-	// This should:
-	// - Update repository configuration
-	// - Test connectivity
-	// - Update ArgoCD settings
-	// - Verify update
+func readKeyFile(path string) (string, error) {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + path[1:]
+		}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key file: %w", err)
+	}
+	return string(b), nil
+}
 
-	logger.Info("✅ Repository updated successfully")
+// testBeforeSave probes the repository unless --skip-test is set.
+func testBeforeSave(ctx context.Context, cred repoCredential) error {
+	if repoSkipTest || cred.Template {
+		return nil
+	}
+	res, err := probeRepository(ctx, cred)
+	if err != nil {
+		fmt.Println(helpers.ErrorStyle.Render("❌ Connection test failed: " + err.Error()))
+		return fmt.Errorf("connection test for %s failed; fix the credentials or pass --skip-test", cred.URL)
+	}
+	fmt.Println(helpers.CreateMuted("   Connection test passed: " + res.Detail))
 	return nil
 }
 
-func testRepository(url string) error {
-	if url == "" {
-		return fmt.Errorf("--url is required for testing repository")
+// resolveCredential finds the credentials ArgoCD would use for repoURL: its
+// repository Secret, topped up from the longest matching template.
+func resolveCredential(ctx context.Context, cs kubernetes.Interface, url string) (repoCredential, string, error) {
+	cred := repoCredential{URL: url, Type: repoType}
+	source := "none configured"
+	secret, err := findRepoSecret(ctx, cs, url, false)
+	if err != nil {
+		return cred, "", err
+	}
+	if secret != nil {
+		cred = credentialFromSecret(secret)
+		cred.URL = url
+		source = "secret " + secret.Name
 	}
+	if cred.authKind() != "anonymous" {
+		return cred, source, nil
+	}
+	templates, err := listRepoSecrets(ctx, cs, argoSecretRepoCreds)
+	if err != nil {
+		return cred, "", err
+	}
+	var creds []repoCredential
+	for i := range templates {
+		creds = append(creds, credentialFromSecret(&templates[i]))
+	}
+	if t, ok := matchingTemplate(creds, url); ok {
+		cred.Username, cred.Password, cred.SSHPrivateKey = t.Username, t.Password, t.SSHPrivateKey
+		cred.GitHubAppID, cred.GitHubAppInstallationID = t.GitHubAppID, t.GitHubAppInstallationID
+		cred.GitHubAppPrivateKey, cred.GitHubAppEnterpriseURL = t.GitHubAppPrivateKey, t.GitHubAppEnterpriseURL
+		source = "template " + t.URL
+	}
+	return cred, source, nil
+}
 
-	logger.Info(fmt.Sprintf("🧪 Testing repository: %s", url))
+func listRepoSecrets(ctx context.Context, cs kubernetes.Interface, secretType string) ([]corev1.Secret, error) {
+	list, err := cs.CoreV1().Secrets(utils.ArgocdNamespace).List(ctx, metav1.ListOptions{
+		LabelSelector: argoSecretTypeLabel + "=" + secretType,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ArgoCD %s secrets: %w", secretType, err)
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return string(list.Items[i].Data["url"]) < string(list.Items[j].Data["url"])
+	})
+	return list.Items, nil
+}
 
-	// TODO: Implement repository testing
-	// This should:
-	// - Test connectivity
-	// - Validate authentication
-	// - Check permissions
-	// - Report test results
+// findRepoSecret returns the repository (or template) Secret for url, or nil.
+func findRepoSecret(ctx context.Context, cs kubernetes.Interface, url string, template bool) (*corev1.Secret, error) {
+	secretType := argoSecretRepository
+	if template {
+		secretType = argoSecretRepoCreds
+	}
+	secrets, err := listRepoSecrets(ctx, cs, secretType)
+	if err != nil {
+		return nil, err
+	}
+	want := normalizeRepoURL(url)
+	for i := range secrets {
+		if normalizeRepoURL(credentialFromSecret(&secrets[i]).URL) == want {
+			return &secrets[i], nil
+		}
+	}
+	return nil, nil
+}
 
-	logger.Info("✅ Repository test completed")
-	return nil
+// applicationsUsingRepo lists the Applications with a source from url.
+func applicationsUsingRepo(ctx context.Context, url string) ([]string, error) {
+	dyn, err := getDynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	list, err := dyn.Resource(argoApplicationGVR).Namespace("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if crdMissing(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	want := normalizeRepoURL(url)
+	var users []string
+	for _, a := range list.Items {
+		urls := []string{nestedString(a.Object, "spec", "source", "repoURL")}
+		sources, _, _ := unstructured.NestedSlice(a.Object, "spec", "sources")
+		for _, s := range sources {
+			if m, ok := s.(map[string]interface{}); ok {
+				urls = append(urls, nestedString(m, "repoURL"))
+			}
+		}
+		for _, u := range urls {
+			if u != "" && normalizeRepoURL(u) == want {
+				users = append(users, a.GetNamespace()+"/"+a.GetName())
+				break
+			}
+		}
+	}
+	return users, nil
 }
 
-func listRepositories() error {
-	logger.Info("📋 Listing Git repositories...")
+func describeCredential(c repoCredential) string {
+	if c.Template {
+		return "credential template for"
+	}
+	return describeType(c) + " repository"
+}
 
-	// TODO: Implement repository listing
-	// This should show:
-	// - All configured repositories
-	// - Connection status
-	// - Authentication status
-	// - Last sync time
+func describeType(c repoCredential) string {
+	if c.OCI {
+		return "oci"
+	}
+	return c.Type
+}
 
-	logger.Info("✅ Repositories listed")
-	return nil
+func connectionIcon(status string) string {
+	switch status {
+	case "Successful":
+		return "✅"
+	case "Failed":
+		return "❌"
+	default:
+		return "⚪"
+	}
 }
@@ -0,0 +1,224 @@
+package gitops
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ArgoCD discovers repositories and credential templates from Secrets carrying
+// this label; there is no CRD.
+const (
+	argoSecretTypeLabel     = "argocd.argoproj.io/secret-type"
+	argoSecretRepository    = "repository"
+	argoSecretRepoCreds     = "repo-creds"
+	argoManagedByAnnotation = "managed-by"
+	argoManagedByValue      = "argocd.argoproj.io"
+)
+
+// repoCredential is the content of an ArgoCD repository or repo-creds Secret.
+// Template credentials (repo-creds) apply to every repository whose URL starts
+// with URL.
+type repoCredential struct {
+	URL      string
+	Name     string
+	Type     string // git or helm
+	Project  string
+	Template bool
+	OCI      bool
+	Insecure bool
+
+	Username      string
+	Password      string
+	SSHPrivateKey string
+
+	GitHubAppID             string
+	GitHubAppInstallationID string
+	GitHubAppPrivateKey     string
+	GitHubAppEnterpriseURL  string
+}
+
+// authKind names the authentication method for display.
+func (c repoCredential) authKind() string {
+	switch {
+	case c.SSHPrivateKey != "":
+		return "ssh"
+	case c.GitHubAppID != "":
+		return "github-app"
+	case c.Password != "":
+		return "https"
+	default:
+		return "anonymous"
+	}
+}
+
+func (c repoCredential) secretType() string {
+	if c.Template {
+		return argoSecretRepoCreds
+	}
+	return argoSecretRepository
+}
+
+// validate rejects combinations ArgoCD would not accept.
+func (c repoCredential) validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("--url is required")
+	}
+	if c.Type != "git" && c.Type != "helm" {
+		return fmt.Errorf("unknown repository type %q (want git or helm)", c.Type)
+	}
+	methods := 0
+	for _, set := range []bool{c.SSHPrivateKey != "", c.GitHubAppID != "", c.Password != ""} {
+		if set {
+			methods++
+		}
+	}
+	if methods > 1 {
+		return fmt.Errorf("choose one of password, SSH key or GitHub App credentials")
+	}
+	ssh := isSSHURL(c.URL)
+	switch {
+	case c.SSHPrivateKey != "" && !ssh:
+		return fmt.Errorf("an SSH private key needs an SSH URL (ssh:// or git@host:path), got %s", c.URL)
+	case ssh && c.Type == "helm":
+		return fmt.Errorf("helm repositories are fetched over HTTPS, not SSH")
+	case c.GitHubAppID != "" && (ssh || c.Type != "git"):
+		return fmt.Errorf("GitHub App credentials only work for HTTPS Git repositories")
+	case c.GitHubAppID != "" && (c.GitHubAppInstallationID == "" || c.GitHubAppPrivateKey == ""):
+		return fmt.Errorf("GitHub App credentials need an app ID, installation ID and private key")
+	case c.OCI && c.Type != "helm":
+		return fmt.Errorf("--oci only applies to helm repositories")
+	}
+	for _, id := range []string{c.GitHubAppID, c.GitHubAppInstallationID} {
+		if id == "" {
+			continue
+		}
+		if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+			return fmt.Errorf("GitHub App IDs must be numeric, got %q", id)
+		}
+	}
+	return nil
+}
+
+// secretData renders the credential in ArgoCD's Secret format.
+func (c repoCredential) secretData() map[string][]byte {
+	data := map[string][]byte{}
+	set := func(k, v string) {
+		if v != "" {
+			data[k] = []byte(v)
+		}
+	}
+	set("url", c.URL)
+	set("type", c.Type)
+	set("name", c.Name)
+	set("project", c.Project)
+	set("username", c.Username)
+	set("password", c.Password)
+	set("sshPrivateKey", c.SSHPrivateKey)
+	set("githubAppID", c.GitHubAppID)
+	set("githubAppInstallationID", c.GitHubAppInstallationID)
+	set("githubAppPrivateKey", c.GitHubAppPrivateKey)
+	set("githubAppEnterpriseBaseUrl", c.GitHubAppEnterpriseURL)
+	if c.OCI {
+		data["enableOCI"] = []byte("true")
+	}
+	if c.Insecure {
+		data["insecure"] = []byte("true")
+	}
+	return data
+}
+
+// credentialFromSecret parses an ArgoCD repository or repo-creds Secret.
+func credentialFromSecret(s *corev1.Secret) repoCredential {
+	get := func(k string) string {
+		if v, ok := s.Data[k]; ok {
+			return string(v)
+		}
+		return s.StringData[k]
+	}
+	return repoCredential{
+		URL:                     get("url"),
+		Name:                    get("name"),
+		Type:                    valueOr(get("type"), "git"),
+		Project:                 get("project"),
+		Template:                s.Labels[argoSecretTypeLabel] == argoSecretRepoCreds,
+		OCI:                     get("enableOCI") == "true",
+		Insecure:                get("insecure") == "true",
+		Username:                get("username"),
+		Password:                get("password"),
+		SSHPrivateKey:           get("sshPrivateKey"),
+		GitHubAppID:             get("githubAppID"),
+		GitHubAppInstallationID: get("githubAppInstallationID"),
+		GitHubAppPrivateKey:     get("githubAppPrivateKey"),
+		GitHubAppEnterpriseURL:  get("githubAppEnterpriseBaseUrl"),
+	}
+}
+
+// repoSecretName derives a stable Secret name from the URL, following the
+// argocd CLI's repo-<hash> / creds-<hash> scheme.
+func repoSecretName(repoURL string, template bool) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(normalizeRepoURL(repoURL)))
+	prefix := "repo"
+	if template {
+		prefix = "creds"
+	}
+	return fmt.Sprintf("%s-%d", prefix, h.Sum32())
+}
+
+// normalizeRepoURL makes URLs that refer to the same repository compare equal,
+// the way ArgoCD does: case-insensitive, without a trailing slash or ".git".
+func normalizeRepoURL(u string) string {
+	u = strings.ToLower(strings.TrimSpace(u))
+	u = strings.TrimSuffix(u, "/")
+	return strings.TrimSuffix(u, ".git")
+}
+
+// isSSHURL reports whether u is an ssh:// URL or scp-like (git@host:path).
+func isSSHURL(u string) bool {
+	if strings.HasPrefix(u, "ssh://") {
+		return true
+	}
+	if strings.Contains(u, "://") {
+		return false
+	}
+	at := strings.Index(u, "@")
+	colon := strings.Index(u, ":")
+	return at > 0 && colon > at
+}
+
+// matchingTemplate returns the repo-creds template with the longest URL prefix
+// of repoURL, as ArgoCD picks it.
+func matchingTemplate(templates []repoCredential, repoURL string) (repoCredential, bool) {
+	var best repoCredential
+	found := false
+	target := strings.ToLower(repoURL)
+	for _, t := range templates {
+		prefix := strings.ToLower(t.URL)
+		if strings.HasPrefix(target, prefix) && len(prefix) > len(best.URL) {
+			best, found = t, true
+		}
+	}
+	return best, found
+}
+
+// repoHost returns the host of an HTTPS or SSH repository URL for display.
+func repoHost(u string) string {
+	if isSSHURL(u) && !strings.HasPrefix(u, "ssh://") {
+		host := u[strings.Index(u, "@")+1:]
+		host, _, _ = strings.Cut(host, ":")
+		return host
+	}
+	if !strings.Contains(u, "://") {
+		u = "https://" + u
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	return parsed.Host
+}
@@ -0,0 +1,98 @@
+package gitops
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRepoCredentialValidate(t *testing.T) {
+	ok := []repoCredential{
+		{URL: "https://github.com/acme/deploy.git", Type: "git", Username: "bot", Password: "x"},
+		{URL: "git@github.com:acme/deploy.git", Type: "git", SSHPrivateKey: "key"},
+		{URL: "ssh://git@gitea.local/acme/deploy.git", Type: "git", SSHPrivateKey: "key"},
+		{URL: "https://github.com/acme", Type: "git", Template: true, GitHubAppID: "1", GitHubAppInstallationID: "2", GitHubAppPrivateKey: "pem"},
+		{URL: "ghcr.io/acme/charts", Type: "helm", OCI: true},
+	}
+	for _, c := range ok {
+		if err := c.validate(); err != nil {
+			t.Errorf("validate(%s) = %v", c.URL, err)
+		}
+	}
+	bad := []repoCredential{
+		{Type: "git"},
+		{URL: "https://x", Type: "svn"},
+		{URL: "https://x", Type: "git", SSHPrivateKey: "key"},
+		{URL: "git@x:y", Type: "git", SSHPrivateKey: "key", Password: "p"},
+		{URL: "git@x:y", Type: "helm"},
+		{URL: "https://x", Type: "git", GitHubAppID: "1"},
+		{URL: "https://x", Type: "git", GitHubAppID: "app", GitHubAppInstallationID: "2", GitHubAppPrivateKey: "pem"},
+		{URL: "https://x", Type: "git", OCI: true},
+	}
+	for _, c := range bad {
+		if err := c.validate(); err == nil {
+			t.Errorf("validate(%+v) should fail", c)
+		}
+	}
+}
+
+func TestRepoCredentialSecretRoundTrip(t *testing.T) {
+	in := repoCredential{
+		URL: "https://github.com/acme", Type: "git", Template: true, Project: "team-a", Insecure: true,
+		GitHubAppID: "1", GitHubAppInstallationID: "2", GitHubAppPrivateKey: "pem",
+	}
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{argoSecretTypeLabel: in.secretType()}},
+		Data:       in.secretData(),
+	}
+	if string(s.Data["insecure"]) != "true" || s.Data["password"] != nil {
+		t.Errorf("secret data = %v", s.Data)
+	}
+	if out := credentialFromSecret(s); out != in {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+	if out := credentialFromSecret(s); out.authKind() != "github-app" {
+		t.Errorf("authKind = %q", out.authKind())
+	}
+}
+
+func TestRepoURLHelpers(t *testing.T) {
+	if repoSecretName("https://GitHub.com/acme/deploy.git", false) != repoSecretName("https://github.com/acme/deploy/", false) {
+		t.Errorf("equivalent URLs should share a secret name")
+	}
+	if repoSecretName("https://github.com/acme", true) == repoSecretName("https://github.com/acme", false) {
+		t.Errorf("repository and template names should differ")
+	}
+	for u, want := range map[string]bool{
+		"git@github.com:acme/x.git": true,
+		"ssh://git@host/x":          true,
+		"https://user@host/x":       false,
+		"ghcr.io/acme/charts":       false,
+	} {
+		if isSSHURL(u) != want {
+			t.Errorf("isSSHURL(%q) = %v", u, !want)
+		}
+	}
+	for u, want := range map[string]string{
+		"git@github.com:acme/x.git": "github.com",
+		"ghcr.io/acme/charts":       "ghcr.io",
+		"oci://ghcr.io/acme/charts": "ghcr.io",
+		"https://charts.example.io": "charts.example.io",
+	} {
+		if got := repoHost(u); got != want {
+			t.Errorf("repoHost(%q) = %q, want %q", u, got, want)
+		}
+	}
+
+	templates := []repoCredential{{URL: "https://github.com/"}, {URL: "https://github.com/acme", Username: "bot"}}
+	if got, ok := matchingTemplate(templates, "https://github.com/acme/deploy.git"); !ok || got.Username != "bot" {
+		t.Errorf("matchingTemplate picked %+v", got)
+	}
+	if _, ok := matchingTemplate(templates, "https://gitlab.com/acme/x"); ok {
+		t.Errorf("matchingTemplate should not match another host")
+	}
+	if realm, svc := parseBearerChallenge(`Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="x"`); realm != "https://ghcr.io/token" || svc != "ghcr.io" {
+		t.Errorf("parseBearerChallenge = %q, %q", realm, svc)
+	}
+}
@@ -0,0 +1,259 @@
+package gitops
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/golang-jwt/jwt/v5"
+	cryptossh "golang.org/x/crypto/ssh"
+)
+
+// probeResult is the outcome of a connectivity test.
+type probeResult struct {
+	Detail string   // e.g. "12 branches/tags, default branch main"
+	Refs   []string // branch and tag names, for git repositories
+}
+
+// probeRepository checks that the repository is reachable with the given
+// credentials: a git ls-remote for Git repositories, the index for Helm
+// repositories and the registry API for OCI Helm repositories.
+func probeRepository(ctx context.Context, c repoCredential) (probeResult, error) {
+	switch {
+	case c.Type == "helm" && c.OCI:
+		return probeOCI(ctx, c)
+	case c.Type == "helm":
+		return probeHelmIndex(ctx, c)
+	default:
+		return lsRemote(ctx, c)
+	}
+}
+
+// gitAuth builds go-git credentials for c.
+func gitAuth(ctx context.Context, c repoCredential) (transport.AuthMethod, error) {
+	switch c.authKind() {
+	case "ssh":
+		user := "git"
+		if u, err := url.Parse(c.URL); err == nil && u.User != nil && u.User.Username() != "" {
+			user = u.User.Username()
+		} else if at := strings.Index(c.URL, "@"); at > 0 && !strings.Contains(c.URL, "://") {
+			user = c.URL[:at]
+		}
+		keys, err := gitssh.NewPublicKeys(user, []byte(c.SSHPrivateKey), "")
+		if err != nil {
+			return nil, fmt.Errorf("invalid SSH private key: %w", err)
+		}
+		if c.Insecure {
+			keys.HostKeyCallback = cryptossh.InsecureIgnoreHostKey()
+		}
+		return keys, nil
+	case "github-app":
+		token, err := githubAppToken(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		return &githttp.BasicAuth{Username: "x-access-token", Password: token}, nil
+	case "https":
+		return &githttp.BasicAuth{Username: valueOr(c.Username, "git"), Password: c.Password}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// lsRemote lists the refs of a Git repository, the equivalent of
+// `git ls-remote`.
+func lsRemote(ctx context.Context, c repoCredential) (probeResult, error) {
+	auth, err := gitAuth(ctx, c)
+	if err != nil {
+		return probeResult{}, err
+	}
+	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{Name: "origin", URLs: []string{c.URL}})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth, InsecureSkipTLS: c.Insecure})
+	if err != nil {
+		return probeResult{}, fmt.Errorf("ls-remote %s: %w", c.URL, err)
+	}
+	var res probeResult
+	head := ""
+	for _, ref := range refs {
+		switch {
+		case ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference:
+			head = ref.Target().Short()
+		case ref.Name().IsBranch(), ref.Name().IsTag():
+			res.Refs = append(res.Refs, ref.Name().Short())
+		}
+	}
+	sort.Strings(res.Refs)
+	res.Detail = fmt.Sprintf("%d branches/tags", len(res.Refs))
+	if head != "" {
+		res.Detail += ", default branch " + head
+	}
+	return res, nil
+}
+
+// githubAppToken exchanges the GitHub App private key for an installation
+// access token.
+func githubAppToken(ctx context.Context, c repoCredential) (string, error) {
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(c.GitHubAppPrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("invalid GitHub App private key: %w", err)
+	}
+	now := time.Now()
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+		Issuer:    c.GitHubAppID,
+		IssuedAt:  jwt.NewNumericDate(now.Add(-time.Minute)),
+		ExpiresAt: jwt.NewNumericDate(now.Add(9 * time.Minute)),
+	}).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+	api := strings.TrimSuffix(valueOr(c.GitHubAppEnterpriseURL, "https://api.github.com"), "/")
+	endpoint := fmt.Sprintf("%s/app/installations/%s/access_tokens", api, c.GitHubAppInstallationID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+signed)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	body, status, _, err := doProbe(req, c.Insecure)
+	if err != nil {
+		return "", fmt.Errorf("GitHub App token request: %w", err)
+	}
+	if status != http.StatusCreated {
+		return "", fmt.Errorf("GitHub App token request: HTTP %d: %s", status, truncate(strings.TrimSpace(string(body)), 200))
+	}
+	var tok struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(body, &tok); err != nil || tok.Token == "" {
+		return "", fmt.Errorf("GitHub App token response has no token")
+	}
+	return tok.Token, nil
+}
+
+// probeHelmIndex fetches index.yaml from a classic Helm repository.
+func probeHelmIndex(ctx context.Context, c repoCredential) (probeResult, error) {
+	endpoint := strings.TrimSuffix(c.URL, "/") + "/index.yaml"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return probeResult{}, err
+	}
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	body, status, _, err := doProbe(req, c.Insecure)
+	if err != nil {
+		return probeResult{}, err
+	}
+	if status != http.StatusOK {
+		return probeResult{}, fmt.Errorf("GET %s: HTTP %d", endpoint, status)
+	}
+	if !strings.Contains(string(body), "entries:") {
+		return probeResult{}, fmt.Errorf("%s is not a Helm repository index", endpoint)
+	}
+	return probeResult{Detail: "index.yaml reachable"}, nil
+}
+
+// probeOCI checks registry access for an OCI Helm repository, following the
+// registry's bearer-token challenge when there is one.
+func probeOCI(ctx context.Context, c repoCredential) (probeResult, error) {
+	host := repoHost(c.URL)
+	repoPath := strings.Trim(strings.TrimPrefix(strings.TrimPrefix(c.URL, "oci://"), host), "/")
+	endpoint := "https://" + host + "/v2/"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return probeResult{}, err
+	}
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	_, status, header, err := doProbe(req, c.Insecure)
+	if err != nil {
+		return probeResult{}, err
+	}
+	switch status {
+	case http.StatusOK:
+		return probeResult{Detail: "registry API reachable"}, nil
+	case http.StatusUnauthorized:
+	default:
+		return probeResult{}, fmt.Errorf("GET %s: HTTP %d", endpoint, status)
+	}
+
+	realm, service := parseBearerChallenge(header.Get("Www-Authenticate"))
+	if realm == "" {
+		return probeResult{}, fmt.Errorf("%s rejected the credentials (HTTP 401)", host)
+	}
+	q := url.Values{}
+	if service != "" {
+		q.Set("service", service)
+	}
+	if repoPath != "" {
+		q.Set("scope", "repository:"+repoPath+":pull")
+	}
+	tokReq, err := http.NewRequestWithContext(ctx, http.MethodGet, realm+"?"+q.Encode(), nil)
+	if err != nil {
+		return probeResult{}, err
+	}
+	if c.Username != "" || c.Password != "" {
+		tokReq.SetBasicAuth(c.Username, c.Password)
+	}
+	_, status, _, err = doProbe(tokReq, c.Insecure)
+	if err != nil {
+		return probeResult{}, err
+	}
+	if status != http.StatusOK {
+		return probeResult{}, fmt.Errorf("%s refused a pull token for %s (HTTP %d)", host, valueOr(repoPath, "the registry"), status)
+	}
+	return probeResult{Detail: "pull token granted for " + valueOr(repoPath, host)}, nil
+}
+
+// parseBearerChallenge extracts realm and service from a
+// `Bearer realm="...",service="..."` WWW-Authenticate header.
+func parseBearerChallenge(h string) (realm, service string) {
+	rest, ok := strings.CutPrefix(h, "Bearer ")
+	if !ok {
+		return "", ""
+	}
+	for _, part := range strings.Split(rest, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		v = strings.Trim(v, `"`)
+		switch k {
+		case "realm":
+			realm = v
+		case "service":
+			service = v
+		}
+	}
+	return realm, service
+}
+
+// doProbe sends req and returns the (size-limited) body, status and headers.
+func doProbe(req *http.Request, insecure bool) ([]byte, int, http.Header, error) {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	if insecure {
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	resp, err := (&http.Client{Transport: tr, Timeout: 30 * time.Second}).Do(req)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	return body, resp.StatusCode, resp.Header, err
+}
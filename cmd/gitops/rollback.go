@@ -1,64 +1,435 @@
 package gitops
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os/user"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	"adhar-io/adhar/platform/logger"
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/k8s"
 
 	"github.com/spf13/cobra"
+	authv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
 )
 
 var rollbackCmd = &cobra.Command{
 	Use:   "rollback",
 	Short: "Rollback deployments",
-	Long: `Rollback application deployments to previous versions.
-	
+	Long: `Rollback an ArgoCD Application to a revision from its deployment history
+(status.history).
+
+The target is the previous deployed revision by default, or the one given by
+--revision (a commit SHA or prefix, or the tag/branch it was deployed from) or
+--id (the history ID shown by --list).
+
+Two modes are supported:
+  pause       (default) disable auto-sync on the Application and sync it to
+              the old revision, as ` + "`argocd app rollback`" + ` does. Git is left
+              untouched, so re-enable auto-sync with --resume-sync once the
+              fix has landed.
+  git-revert  GitOps-pure: commit the application's path as it was at the
+              target revision onto its branch in the Gitea repository, then
+              sync. Auto-sync stays on and Git remains the source of truth.
+
+Either way the command waits for the application to become healthy and
+records who rolled back, when, to what and why as annotations on the
+Application.
+
 Examples:
-  adhar gitops rollback --app=my-app
-  adhar gitops rollback --app=my-app --revision=v1.0.0`,
+  adhar gitops rollback --app=my-app --list
+  adhar gitops rollback --app=my-app --reason="5xx spike after v1.4.0"
+  adhar gitops rollback --app=my-app --revision=v1.0.0
+  adhar gitops rollback --app=my-app --id=12 --mode=git-revert
+  adhar gitops rollback --app=my-app --resume-sync`,
 	RunE: runRollback,
 }
 
+const (
+	// rollbackPausedSyncAnnotation stores spec.syncPolicy.automated while a
+	// pause-mode rollback has auto-sync disabled.
+	rollbackPausedSyncAnnotation = "adhar.io/rollback-paused-sync"
+
+	rollbackByAnnotation       = "adhar.io/rollback-by"
+	rollbackAtAnnotation       = "adhar.io/rollback-at"
+	rollbackRevisionAnnotation = "adhar.io/rollback-revision"
+	rollbackReasonAnnotation   = "adhar.io/rollback-reason"
+
+	rollbackModePause     = "pause"
+	rollbackModeGitRevert = "git-revert"
+)
+
+var (
+	rollbackID         int64
+	rollbackReason     string
+	rollbackMode       string
+	rollbackList       bool
+	rollbackResumeSync bool
+)
+
+func init() {
+	rollbackCmd.Flags().StringVarP(&app, "app", "a", "", "Application name (or namespace/name)")
+	rollbackCmd.Flags().StringVar(&revision, "revision", "", "Commit SHA (or prefix) or deployed tag/branch to roll back to")
+	rollbackCmd.Flags().Int64Var(&rollbackID, "id", 0, "History ID to roll back to (see --list)")
+	rollbackCmd.Flags().StringVar(&rollbackReason, "reason", "", "Why the rollback is happening (recorded on the Application)")
+	rollbackCmd.Flags().StringVar(&rollbackMode, "mode", rollbackModePause, "Rollback mode: pause or git-revert")
+	rollbackCmd.Flags().BoolVar(&prune, "prune", false, "Delete resources not present at the target revision")
+	rollbackCmd.Flags().StringVarP(&timeout, "timeout", "i", "5m", "Maximum time to wait for the application to become healthy")
+	rollbackCmd.Flags().BoolVar(&rollbackList, "list", false, "Show the deployment history and exit")
+	rollbackCmd.Flags().BoolVar(&rollbackResumeSync, "resume-sync", false, "Re-enable auto-sync paused by an earlier rollback and exit")
+}
+
 func runRollback(cmd *cobra.Command, args []string) error {
 	if app == "" {
 		return fmt.Errorf("--app is required for rollback")
 	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	dyn, err := getDynamicClient()
+	if err != nil {
+		return unreachable(err)
+	}
+	ns, name := splitAppRef(app)
+	obj, err := dyn.Resource(argoApplicationGVR).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if crdMissing(err) {
+			return fmt.Errorf("ArgoCD Application CRD not installed")
+		}
+		return fmt.Errorf("failed to get application %s/%s: %w", ns, name, err)
+	}
+
+	switch {
+	case rollbackList:
+		printHistory(obj)
+		return nil
+	case rollbackResumeSync:
+		return resumeAutoSync(ctx, dyn, obj)
+	}
+
+	if rollbackMode != rollbackModePause && rollbackMode != rollbackModeGitRevert {
+		return fmt.Errorf("unknown --mode %q (want %s or %s)", rollbackMode, rollbackModePause, rollbackModeGitRevert)
+	}
+	history, err := deploymentHistory(obj)
+	if err != nil {
+		return err
+	}
 
-	logger.Info(fmt.Sprintf("🔄 Rolling back application: %s", app))
+	fmt.Println(helpers.TitleStyle.Render(fmt.Sprintf("⏪ Rolling back %s", name)))
+	var target historyEntry
+	if revision != "" || rollbackID != 0 {
+		target, err = rollbackToRevision(history, revision, rollbackID)
+	} else {
+		target, err = rollbackToPrevious(history, nestedString(obj.Object, "status", "sync", "revision"))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🎯 Target: history #%d, revision %s (%s), deployed %s\n",
+		target.ID, shortSHA(target.Revision), valueOr(target.TargetRevision, "HEAD"), valueOr(target.DeployedAt, "-"))
+
+	wait, err := operationTimeout()
+	if err != nil {
+		return err
+	}
+	who := currentUser(ctx)
+	prior := operationStartedAt(obj.Object)
+	if rollbackMode == rollbackModeGitRevert {
+		err = rollbackViaGit(ctx, dyn, obj, target, who)
+	} else {
+		err = rollbackViaOperation(ctx, dyn, obj, target, who)
+	}
+	if err != nil {
+		return err
+	}
 
-	if revision != "" {
-		return rollbackToRevision(app, revision)
+	res := followOperation(ctx, dyn, obj, prior, false, wait, &syncPrinter{})
+	if err := recordRollback(ctx, dyn, obj, target, who); err != nil {
+		fmt.Println(helpers.CreateWarning("⚠️  " + err.Error()))
+	}
+	printSyncResults([]syncResult{res})
+	if res.Failed() {
+		return fmt.Errorf("rollback of %s: %s", name, res.Summary())
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ %s rolled back to %s and healthy", name, shortSHA(target.Revision))))
+	if rollbackMode == rollbackModePause {
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Auto-sync stays off until: adhar gitops rollback --app=%s --resume-sync", app)))
 	}
+	return nil
+}
 
-	return rollbackToPrevious(app)
+// historyEntry is one entry of an Application's status.history.
+type historyEntry struct {
+	ID             int64
+	Revision       string
+	DeployedAt     string
+	InitiatedBy    string
+	TargetRevision string
+	// Source is the application source deployed at that point, as recorded by
+	// ArgoCD; it is sent back with the rollback operation.
+	Source map[string]interface{}
 }
 
-func rollbackToRevision(appName, revisionName string) error {
-	logger.Info(fmt.Sprintf("🔄 Rolling back %s to revision: %s", appName, revisionName))
+// deploymentHistory reads status.history, oldest first.
+func deploymentHistory(obj *unstructured.Unstructured) ([]historyEntry, error) {
+	raw, _, _ := unstructured.NestedSlice(obj.Object, "status", "history")
+	var out []historyEntry
+	for _, r := range raw {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		e := historyEntry{
+			Revision:   nestedString(m, "revision"),
+			DeployedAt: nestedString(m, "deployedAt"),
+		}
+		if e.Revision == "" {
+			// Multi-source applications record one revision per source.
+			if revs, _, _ := unstructured.NestedStringSlice(m, "revisions"); len(revs) > 0 {
+				return nil, fmt.Errorf("%s is a multi-source application; roll it back with the ArgoCD UI or CLI", obj.GetName())
+			}
+		}
+		e.ID, _, _ = unstructured.NestedInt64(m, "id")
+		e.Source, _, _ = unstructured.NestedMap(m, "source")
+		e.TargetRevision = nestedString(e.Source, "targetRevision")
+		e.InitiatedBy = nestedString(m, "initiatedBy", "username")
+		if auto, _, _ := unstructured.NestedBool(m, "initiatedBy", "automated"); auto && e.InitiatedBy == "" {
+			e.InitiatedBy = "auto-sync"
+		}
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%s has no deployment history to roll back to", obj.GetName())
+	}
+	return out, nil
+}
+
+// rollbackToRevision finds the history entry to roll back to by ID, commit SHA
+// (or a prefix of at least 7 characters) or the tag/branch it was deployed
+// from. The most recent match wins.
+func rollbackToRevision(history []historyEntry, rev string, id int64) (historyEntry, error) {
+	for i := len(history) - 1; i >= 0; i-- {
+		e := history[i]
+		switch {
+		case id != 0:
+			if e.ID == id {
+				return e, nil
+			}
+		case e.Revision == rev,
+			len(rev) >= 7 && strings.HasPrefix(e.Revision, rev),
+			e.TargetRevision == rev:
+			return e, nil
+		}
+	}
+	known := make([]string, 0, len(history))
+	for _, e := range history {
+		known = append(known, fmt.Sprintf("#%d %s", e.ID, shortSHA(e.Revision)))
+	}
+	want := rev
+	if id != 0 {
+		want = "#" + strconv.FormatInt(id, 10)
+	}
+	return historyEntry{}, fmt.Errorf("%s is not in the deployment history (known: %s)", want, strings.Join(known, ", "))
+}
 
-	// TODO: Implement revision-specific rollback
-	// This should:
-	// - Validate revision exists
-	// - Check rollback safety
-	// - Execute rollback
-	// - Monitor rollback progress
-	// - Verify rollback success
+// rollbackToPrevious returns the latest history entry whose revision differs
+// from the one currently deployed.
+func rollbackToPrevious(history []historyEntry, current string) (historyEntry, error) {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Revision != current {
+			return history[i], nil
+		}
+	}
+	return historyEntry{}, fmt.Errorf("no earlier revision in the deployment history (every entry is %s)", shortSHA(current))
+}
 
-	logger.Info("✅ Rollback to revision completed")
+// rollbackViaOperation disables auto-sync and starts a sync to the target
+// revision and source, mirroring the ArgoCD API server's rollback.
+func rollbackViaOperation(ctx context.Context, dyn dynamic.Interface, obj *unstructured.Unstructured, target historyEntry, who string) error {
+	if err := pauseAutoSync(ctx, dyn, obj); err != nil {
+		return err
+	}
+	op := map[string]interface{}{
+		"initiatedBy": map[string]interface{}{"username": who},
+		"info": []interface{}{
+			map[string]interface{}{"name": "Reason", "value": valueOr(rollbackReason, "adhar gitops rollback")},
+			map[string]interface{}{"name": "Rollback", "value": fmt.Sprintf("history #%d", target.ID)},
+		},
+		"sync": map[string]interface{}{
+			"revision":     target.Revision,
+			"prune":        prune,
+			"syncStrategy": map[string]interface{}{"apply": map[string]interface{}{}},
+		},
+	}
+	if target.Source != nil {
+		_ = unstructured.SetNestedMap(op, target.Source, "sync", "source")
+	}
+	if err := requestSync(ctx, dyn, obj, op); err != nil {
+		return err
+	}
+	fmt.Println("🚀 Rollback operation started")
 	return nil
 }
 
-func rollbackToPrevious(appName string) error {
-	logger.Info(fmt.Sprintf("🔄 Rolling back %s to previous version", appName))
+// rollbackViaGit restores the application's path to the target revision in
+// Git and syncs to the new commit.
+func rollbackViaGit(ctx context.Context, dyn dynamic.Interface, obj *unstructured.Unstructured, target historyEntry, who string) error {
+	if nestedString(obj.Object, "spec", "source", "chart") != "" {
+		return fmt.Errorf("%s deploys a Helm chart from a chart repository; git-revert needs a Git source", obj.GetName())
+	}
+	repoURL := nestedString(obj.Object, "spec", "source", "repoURL")
+	dir := nestedString(obj.Object, "spec", "source", "path")
+	branch := nestedString(obj.Object, "spec", "source", "targetRevision")
+
+	fmt.Printf("📥 Cloning %s (%s)\n", repoURL, valueOr(branch, "HEAD"))
+	repo, err := openGitopsRepo(ctx, repoURL, branch)
+	if err != nil {
+		return err
+	}
+	changed, err := repo.RestorePath(target.Revision, dir)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return fmt.Errorf("%s on %s already matches %s; nothing to revert", valueOr(dir, "/"), repo.Branch, shortSHA(target.Revision))
+	}
+	msg := fmt.Sprintf("Roll back %s to %s\n\nRestores %s to its state at %s (ArgoCD history #%d).\nRolled back by: %s\n",
+		obj.GetName(), shortSHA(target.Revision), valueOr(dir, "/"), target.Revision, target.ID, who)
+	if rollbackReason != "" {
+		msg += "Reason: " + rollbackReason + "\n"
+	}
+	sha, err := repo.CommitAndPush(ctx, msg, who)
+	if err != nil {
+		return err
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Pushed %s to %s", shortSHA(sha), repo.Branch)))
+
+	// Refresh and sync now rather than waiting for the next poll.
+	op := map[string]interface{}{
+		"initiatedBy": map[string]interface{}{"username": who},
+		"info":        []interface{}{map[string]interface{}{"name": "Reason", "value": valueOr(rollbackReason, "adhar gitops rollback (git-revert)")}},
+		"sync":        map[string]interface{}{"revision": sha, "prune": prune},
+	}
+	return requestSync(ctx, dyn, obj, op)
+}
+
+// pauseAutoSync removes spec.syncPolicy.automated, keeping the original in an
+// annotation so --resume-sync can restore it. It is a no-op when auto-sync is
+// already off.
+func pauseAutoSync(ctx context.Context, dyn dynamic.Interface, obj *unstructured.Unstructured) error {
+	automated, found, _ := unstructured.NestedMap(obj.Object, "spec", "syncPolicy", "automated")
+	if !found {
+		return nil
+	}
+	saved, err := json.Marshal(automated)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]interface{}{rollbackPausedSyncAnnotation: string(saved)}},
+		"spec":     map[string]interface{}{"syncPolicy": map[string]interface{}{"automated": nil}},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := dyn.Resource(argoApplicationGVR).Namespace(obj.GetNamespace()).Patch(ctx, obj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to disable auto-sync on %s: %w", obj.GetName(), err)
+	}
+	fmt.Println(helpers.CreateMuted("   Auto-sync disabled for the rollback"))
+	return nil
+}
 
-	// TODO: Implement previous version rollback
-	// This should:
-	// - Get deployment history
-	// - Identify previous version
-	// - Execute rollback
-	// - Monitor progress
-	// - Verify success
+// resumeAutoSync restores the automated sync policy saved by pauseAutoSync.
+func resumeAutoSync(ctx context.Context, dyn dynamic.Interface, obj *unstructured.Unstructured) error {
+	saved := obj.GetAnnotations()[rollbackPausedSyncAnnotation]
+	if saved == "" {
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("   %s has no auto-sync paused by a rollback", obj.GetName())))
+		return nil
+	}
+	var automated map[string]interface{}
+	if err := json.Unmarshal([]byte(saved), &automated); err != nil {
+		return fmt.Errorf("invalid %s annotation on %s: %w", rollbackPausedSyncAnnotation, obj.GetName(), err)
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]interface{}{rollbackPausedSyncAnnotation: nil}},
+		"spec":     map[string]interface{}{"syncPolicy": map[string]interface{}{"automated": automated}},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := dyn.Resource(argoApplicationGVR).Namespace(obj.GetNamespace()).Patch(ctx, obj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to re-enable auto-sync on %s: %w", obj.GetName(), err)
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Auto-sync re-enabled on %s", obj.GetName())))
+	return nil
+}
 
-	logger.Info("✅ Rollback to previous version completed")
+// recordRollback annotates the Application with who rolled it back, when, to
+// which revision and why.
+func recordRollback(ctx context.Context, dyn dynamic.Interface, obj *unstructured.Unstructured, target historyEntry, who string) error {
+	ann := map[string]interface{}{
+		rollbackByAnnotation:       who,
+		rollbackAtAnnotation:       time.Now().UTC().Format(time.RFC3339),
+		rollbackRevisionAnnotation: fmt.Sprintf("%s (history #%d, %s)", target.Revision, target.ID, rollbackMode),
+		rollbackReasonAnnotation:   nil,
+	}
+	if rollbackReason != "" {
+		ann[rollbackReasonAnnotation] = rollbackReason
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"annotations": ann}})
+	if err != nil {
+		return err
+	}
+	if _, err := dyn.Resource(argoApplicationGVR).Namespace(obj.GetNamespace()).Patch(ctx, obj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to record the rollback on %s: %w", obj.GetName(), err)
+	}
 	return nil
 }
+
+// currentUser identifies the caller: the Kubernetes username from a
+// SelfSubjectReview, falling back to the local OS user.
+func currentUser(ctx context.Context) string {
+	if cs, err := k8s.GetClientset(); err == nil {
+		review, err := cs.AuthenticationV1().SelfSubjectReviews().Create(ctx, &authv1.SelfSubjectReview{}, metav1.CreateOptions{})
+		if err == nil && review.Status.UserInfo.Username != "" {
+			return review.Status.UserInfo.Username
+		}
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "adhar-cli"
+}
+
+func printHistory(obj *unstructured.Unstructured) {
+	fmt.Println(helpers.TitleStyle.Render(fmt.Sprintf("📜 Deployment history of %s", obj.GetName())))
+	history, err := deploymentHistory(obj)
+	if err != nil {
+		fmt.Println(helpers.CreateMuted("   " + err.Error()))
+		return
+	}
+	current := nestedString(obj.Object, "status", "sync", "revision")
+	fmt.Printf("%-6s %-10s %-20s %-22s %s\n", "ID", "REVISION", "TARGET", "DEPLOYED", "BY")
+	fmt.Println(strings.Repeat("─", 80))
+	for i := len(history) - 1; i >= 0; i-- {
+		e := history[i]
+		marker := ""
+		if e.Revision == current && i == len(history)-1 {
+			marker = " ← current"
+		}
+		fmt.Printf("%-6d %-10s %-20s %-22s %s%s\n", e.ID, shortSHA(e.Revision), truncate(valueOr(e.TargetRevision, "HEAD"), 20), valueOr(e.DeployedAt, "-"), valueOr(e.InitiatedBy, "-"), marker)
+	}
+	if by := obj.GetAnnotations()[rollbackByAnnotation]; by != "" {
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Last rollback by %s at %s: %s", by, obj.GetAnnotations()[rollbackAtAnnotation], valueOr(obj.GetAnnotations()[rollbackReasonAnnotation], "no reason given"))))
+	}
+}
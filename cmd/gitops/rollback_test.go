@@ -0,0 +1,63 @@
+package gitops
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDeploymentHistoryAndTargets(t *testing.T) {
+	const (
+		shaA = "aaaaaaa111111111111111111111111111111111"
+		shaB = "bbbbbbb222222222222222222222222222222222"
+	)
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web"},
+		"status": map[string]interface{}{
+			"history": []interface{}{
+				map[string]interface{}{"id": int64(3), "revision": shaB, "source": map[string]interface{}{"targetRevision": "v1.1.0"}},
+				map[string]interface{}{"id": int64(1), "revision": shaA, "source": map[string]interface{}{"targetRevision": "v1.0.0"}},
+				map[string]interface{}{"id": int64(2), "revision": shaA, "initiatedBy": map[string]interface{}{"automated": true}},
+			},
+		},
+	}}
+	history, err := deploymentHistory(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 || history[0].ID != 1 || history[2].ID != 3 {
+		t.Fatalf("history not sorted by ID: %+v", history)
+	}
+	if history[1].InitiatedBy != "auto-sync" {
+		t.Errorf("automated entry InitiatedBy = %q", history[1].InitiatedBy)
+	}
+
+	prev, err := rollbackToPrevious(history, shaB)
+	if err != nil || prev.ID != 2 {
+		t.Errorf("rollbackToPrevious = %+v, %v; want #2", prev, err)
+	}
+	if _, err := rollbackToPrevious(history[:2], shaA); err == nil {
+		t.Errorf("rollbackToPrevious should fail when every entry is current")
+	}
+
+	for _, tc := range []struct {
+		rev  string
+		id   int64
+		want int64
+	}{
+		{rev: "v1.0.0", want: 1},
+		{rev: shaA[:7], want: 2},
+		{rev: shaB, want: 3},
+		{id: 1, want: 1},
+	} {
+		got, err := rollbackToRevision(history, tc.rev, tc.id)
+		if err != nil || got.ID != tc.want {
+			t.Errorf("rollbackToRevision(%q, %d) = #%d, %v; want #%d", tc.rev, tc.id, got.ID, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"aaaa", "v9.9.9"} {
+		if _, err := rollbackToRevision(history, bad, 0); err == nil {
+			t.Errorf("rollbackToRevision(%q) should fail", bad)
+		}
+	}
+}
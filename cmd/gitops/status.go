@@ -1,43 +1,108 @@
 package gitops
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
 
 	"adhar-io/adhar/cmd/apps"
-	"adhar-io/adhar/platform/logger"
+	"adhar-io/adhar/cmd/helpers"
 )
 
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show GitOps status",
 	Long: `Show GitOps status and application health.
-	
+
+Without --app this aggregates every ArgoCD Application and ApplicationSet:
+sync status, health, the deployed revision against the latest commit of the
+platform Gitea repository (GitRepository status), out-of-sync resources and
+the last sync error. --namespace keeps the applications that deploy into that
+namespace.
+
 Examples:
   adhar gitops status
   adhar gitops status --app=my-app
-  adhar gitops status --namespace=prod`,
+  adhar gitops status --namespace=prod
+  adhar gitops status --watch
+  adhar gitops status --output=json`,
 	RunE: runStatus,
 }
 
-func runStatus(cmd *cobra.Command, args []string) error {
-	logger.Info("📊 Checking GitOps status...")
+var (
+	statusWatch    bool
+	statusInterval time.Duration
+)
+
+var gitRepositoryGVR = schema.GroupVersionResource{Group: "platform.adhar.io", Version: "v1alpha1", Resource: "gitrepositories"}
 
+func init() {
+	statusCmd.Flags().StringVarP(&app, "app", "a", "", "Application name")
+	statusCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Only applications deploying into this namespace")
+	statusCmd.Flags().StringVarP(&output, "output", "f", "", "Output format (table, json, yaml)")
+	statusCmd.Flags().BoolVarP(&statusWatch, "watch", "w", false, "Refresh the status until interrupted")
+	statusCmd.Flags().DurationVar(&statusInterval, "interval", 5*time.Second, "Refresh interval for --watch")
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
 	if app != "" {
 		return showApplicationStatus(cmd, app)
 	}
-
-	if namespace != "" {
-		return showNamespaceStatus(namespace)
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	dyn, err := getDynamicClient()
+	if err != nil {
+		return unreachable(err)
+	}
+	if !statusWatch {
+		report, err := collectGitOpsStatus(ctx, dyn, namespace)
+		if err != nil {
+			return err
+		}
+		return renderGitOpsStatus(report)
 	}
 
-	return showOverallStatus()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	for {
+		report, err := collectGitOpsStatus(ctx, dyn, namespace)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if output == "" || output == "table" {
+			fmt.Print("\033[H\033[2J")
+		}
+		if err != nil {
+			fmt.Println(helpers.ErrorStyle.Render("❌ " + err.Error()))
+		} else if err := renderGitOpsStatus(report); err != nil {
+			return err
+		}
+		if output == "" || output == "table" {
+			fmt.Println(helpers.CreateMuted(fmt.Sprintf("\n   Refreshing every %s — Ctrl-C to stop", statusInterval)))
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(statusInterval):
+		}
+	}
 }
 
 func showApplicationStatus(cmd *cobra.Command, appName string) error {
-	logger.Info(fmt.Sprintf("📊 Showing status for application: %s", appName))
-
 	kubeconfigPath, err := cmd.Root().PersistentFlags().GetString("kubeconfig")
 	if err != nil {
 		return fmt.Errorf("read kubeconfig flag: %w", err)
@@ -51,31 +116,381 @@ func showApplicationStatus(cmd *cobra.Command, appName string) error {
 	return apps.RenderApplicationStatus(statusView, output, true)
 }
 
-func showNamespaceStatus(namespaceName string) error {
-	logger.Info(fmt.Sprintf("📊 Showing status for namespace: %s", namespaceName))
+// gitopsStatus is the aggregate view rendered by `gitops status`.
+type gitopsStatus struct {
+	Namespace       string         `json:"namespace,omitempty"`
+	GeneratedAt     time.Time      `json:"generatedAt"`
+	HealthScore     int            `json:"healthScore"`
+	Applications    []appStatus    `json:"applications"`
+	ApplicationSets []appSetStatus `json:"applicationSets"`
+}
 
-	// TODO: Implement namespace status display
-	// This should show:
-	// - All applications in namespace
-	// - Overall health
-	// - Sync status summary
-	// - Issues and warnings
+type appStatus struct {
+	Name           string          `json:"name"`
+	Namespace      string          `json:"namespace"`
+	Project        string          `json:"project"`
+	Destination    string          `json:"destination"`
+	ApplicationSet string          `json:"applicationSet,omitempty"`
+	Sync           string          `json:"sync"`
+	Health         string          `json:"health"`
+	RepoURL        string          `json:"repoURL,omitempty"`
+	TargetRevision string          `json:"targetRevision,omitempty"`
+	Revision       string          `json:"revision,omitempty"`
+	LatestCommit   string          `json:"latestCommit,omitempty"`
+	Drift          string          `json:"drift"` // latest, behind, unknown
+	OutOfSync      []resourceDrift `json:"outOfSync,omitempty"`
+	DiffSummary    map[string]int  `json:"diffSummary,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	LastSyncedAt   string          `json:"lastSyncedAt,omitempty"`
+}
 
-	logger.Info("✅ Namespace status displayed")
-	return nil
+type resourceDrift struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Change    string `json:"change"` // modified, missing, extra
+	Health    string `json:"health,omitempty"`
+}
+
+type appSetStatus struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Apps      int    `json:"apps"`
+	Healthy   int    `json:"healthy"`
+	OutOfSync int    `json:"outOfSync"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Healthy reports whether the app is Synced and Healthy.
+func (a appStatus) Healthy() bool {
+	return a.Sync == "Synced" && a.Health == "Healthy"
+}
+
+// collectGitOpsStatus gathers Applications, ApplicationSets and the latest
+// GitRepository commits. A non-empty namespace keeps applications whose
+// destination is that namespace, and the ApplicationSets that generate them.
+func collectGitOpsStatus(ctx context.Context, dyn dynamic.Interface, ns string) (*gitopsStatus, error) {
+	list, err := dyn.Resource(argoApplicationGVR).Namespace("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if crdMissing(err) {
+			return nil, fmt.Errorf("ArgoCD Application CRD not installed")
+		}
+		return nil, fmt.Errorf("failed to list applications: %w", err)
+	}
+	latest := latestGitCommits(ctx, dyn)
+
+	report := &gitopsStatus{Namespace: ns, GeneratedAt: time.Now()}
+	for i := range list.Items {
+		a := summarizeApplication(&list.Items[i], latest)
+		if ns != "" && destinationNamespace(&list.Items[i]) != ns {
+			continue
+		}
+		report.Applications = append(report.Applications, a)
+	}
+	sort.Slice(report.Applications, func(i, j int) bool {
+		return report.Applications[i].Name < report.Applications[j].Name
+	})
+
+	sets, err := dyn.Resource(argoApplicationSetGVR).Namespace("").List(ctx, metav1.ListOptions{})
+	if err == nil {
+		report.ApplicationSets = summarizeApplicationSets(sets.Items, report.Applications, ns != "")
+	}
+
+	healthy := 0
+	for _, a := range report.Applications {
+		if a.Healthy() {
+			healthy++
+		}
+	}
+	if n := len(report.Applications); n > 0 {
+		report.HealthScore = healthy * 100 / n
+	}
+	return report, nil
+}
+
+// latestGitCommits maps normalized in-cluster repository URLs to the newest
+// commit recorded on their GitRepository.
+func latestGitCommits(ctx context.Context, dyn dynamic.Interface) map[string]string {
+	out := map[string]string{}
+	list, err := dyn.Resource(gitRepositoryGVR).Namespace("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return out
+	}
+	for _, r := range list.Items {
+		hash := nestedString(r.Object, "status", "commit", "hash")
+		if hash == "" {
+			continue
+		}
+		for _, u := range []string{
+			nestedString(r.Object, "status", "internalGitRepositoryUrl"),
+			nestedString(r.Object, "status", "externalGitRepositoryUrl"),
+		} {
+			if u != "" {
+				out[normalizeRepoURL(u)] = hash
+			}
+		}
+	}
+	return out
+}
+
+func destinationNamespace(obj *unstructured.Unstructured) string {
+	return nestedString(obj.Object, "spec", "destination", "namespace")
+}
+
+func summarizeApplication(obj *unstructured.Unstructured, latest map[string]string) appStatus {
+	o := obj.Object
+	a := appStatus{
+		Name:         obj.GetName(),
+		Namespace:    obj.GetNamespace(),
+		Project:      valueOr(nestedString(o, "spec", "project"), "default"),
+		Destination:  valueOr(nestedString(o, "spec", "destination", "namespace"), "-"),
+		Sync:         valueOr(nestedString(o, "status", "sync", "status"), "Unknown"),
+		Health:       valueOr(nestedString(o, "status", "health", "status"), "Unknown"),
+		Revision:     nestedString(o, "status", "sync", "revision"),
+		LastSyncedAt: nestedString(o, "status", "operationState", "finishedAt"),
+		Drift:        "unknown",
+	}
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.Kind == "ApplicationSet" {
+			a.ApplicationSet = ref.Name
+		}
+	}
+	a.RepoURL = nestedString(o, "spec", "source", "repoURL")
+	a.TargetRevision = valueOr(nestedString(o, "spec", "source", "targetRevision"), "HEAD")
+	if a.RepoURL == "" {
+		// Multi-source: report the first Git source.
+		sources, _, _ := unstructured.NestedSlice(o, "spec", "sources")
+		revisions, _, _ := unstructured.NestedStringSlice(o, "status", "sync", "revisions")
+		for i, s := range sources {
+			m, ok := s.(map[string]interface{})
+			if !ok || nestedString(m, "chart") != "" {
+				continue
+			}
+			a.RepoURL = nestedString(m, "repoURL")
+			a.TargetRevision = valueOr(nestedString(m, "targetRevision"), "HEAD")
+			if i < len(revisions) {
+				a.Revision = revisions[i]
+			}
+			break
+		}
+	}
+	if hash, ok := latest[normalizeRepoURL(a.RepoURL)]; ok && a.RepoURL != "" {
+		a.LatestCommit = hash
+		a.Drift = "behind"
+		if a.Revision == hash {
+			a.Drift = "latest"
+		}
+	}
+
+	resources, _, _ := unstructured.NestedSlice(o, "status", "resources")
+	for _, r := range resources {
+		m, ok := r.(map[string]interface{})
+		if !ok || nestedString(m, "status") != "OutOfSync" {
+			continue
+		}
+		d := resourceDrift{
+			Group:     nestedString(m, "group"),
+			Kind:      nestedString(m, "kind"),
+			Namespace: nestedString(m, "namespace"),
+			Name:      nestedString(m, "name"),
+			Health:    nestedString(m, "health", "status"),
+			Change:    "modified",
+		}
+		if prune, _, _ := unstructured.NestedBool(m, "requiresPruning"); prune {
+			d.Change = "extra"
+		} else if d.Health == "Missing" {
+			d.Change = "missing"
+		}
+		a.OutOfSync = append(a.OutOfSync, d)
+	}
+	if len(a.OutOfSync) > 0 {
+		a.DiffSummary = map[string]int{}
+		for _, d := range a.OutOfSync {
+			a.DiffSummary[d.Change]++
+		}
+	}
+	a.LastError = lastSyncError(o)
+	return a
 }
 
-func showOverallStatus() error {
-	logger.Info("📊 Showing overall GitOps status...")
+// lastSyncError returns the newest error condition, or the message of a
+// failed last operation.
+func lastSyncError(o map[string]interface{}) string {
+	conds, _, _ := unstructured.NestedSlice(o, "status", "conditions")
+	for i := len(conds) - 1; i >= 0; i-- {
+		m, ok := conds[i].(map[string]interface{})
+		if ok && strings.HasSuffix(nestedString(m, "type"), "Error") {
+			return nestedString(m, "type") + ": " + nestedString(m, "message")
+		}
+	}
+	if phase := nestedString(o, "status", "operationState", "phase"); phase == "Failed" || phase == "Error" {
+		return "Sync" + phase + ": " + nestedString(o, "status", "operationState", "message")
+	}
+	return ""
+}
+
+func summarizeApplicationSets(sets []unstructured.Unstructured, apps []appStatus, filtered bool) []appSetStatus {
+	var out []appSetStatus
+	for _, s := range sets {
+		st := appSetStatus{Name: s.GetName(), Namespace: s.GetNamespace()}
+		for _, a := range apps {
+			if a.ApplicationSet != st.Name || a.Namespace != st.Namespace {
+				continue
+			}
+			st.Apps++
+			if a.Health == "Healthy" {
+				st.Healthy++
+			}
+			if a.Sync == "OutOfSync" {
+				st.OutOfSync++
+			}
+		}
+		conds, _, _ := unstructured.NestedSlice(s.Object, "status", "conditions")
+		for _, c := range conds {
+			m, ok := c.(map[string]interface{})
+			if ok && nestedString(m, "type") == "ErrorOccurred" && nestedString(m, "status") == "True" {
+				st.Error = nestedString(m, "message")
+			}
+		}
+		if filtered && st.Apps == 0 {
+			continue
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+func renderGitOpsStatus(r *gitopsStatus) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(r)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(b))
+		return nil
+	case "", "table":
+	default:
+		return fmt.Errorf("unsupported output format %q (want table, json or yaml)", output)
+	}
+
+	title := "📊 GitOps Status"
+	if r.Namespace != "" {
+		title += " — namespace " + r.Namespace
+	}
+	fmt.Println(helpers.TitleStyle.Render(title))
+	if len(r.Applications) == 0 {
+		fmt.Println(helpers.CreateMuted("   No applications found"))
+		return nil
+	}
+
+	synced, healthy, behind := 0, 0, 0
+	for _, a := range r.Applications {
+		if a.Sync == "Synced" {
+			synced++
+		}
+		if a.Health == "Healthy" {
+			healthy++
+		}
+		if a.Drift == "behind" {
+			behind++
+		}
+	}
+	n := len(r.Applications)
+	fmt.Printf("Health score: %d%%   Synced: %d/%d   Healthy: %d/%d   Behind Git: %d\n\n", r.HealthScore, synced, n, healthy, n, behind)
+
+	fmt.Printf("%-32s %-18s %-10s %-13s %-10s %-22s %s\n", "APPLICATION", "DESTINATION", "SYNC", "HEALTH", "REVISION", "GIT", "DIFF")
+	fmt.Println(strings.Repeat("─", 120))
+	for _, a := range r.Applications {
+		fmt.Printf("%-32s %-18s %-10s %-13s %-10s %-22s %s\n",
+			truncate(a.Name, 32), truncate(a.Destination, 18), a.Sync, healthIcon(a.Health)+" "+a.Health,
+			shortSHA(valueOr(a.Revision, "-")), driftLabel(a), diffLabel(a.DiffSummary))
+	}
 
-	// TODO: Implement overall status display
-	// This should show:
-	// - All applications
-	// - Overall health score
-	// - Sync status summary
-	// - Repository connectivity
-	// - ArgoCD server status
+	var problems []appStatus
+	for _, a := range r.Applications {
+		if len(a.OutOfSync) > 0 || a.LastError != "" {
+			problems = append(problems, a)
+		}
+	}
+	if len(problems) > 0 {
+		fmt.Println()
+		fmt.Println(helpers.CreateSection("Drift and errors"))
+		for _, a := range problems {
+			fmt.Printf("%s %s\n", healthIcon(a.Health), a.Name)
+			if a.LastError != "" {
+				fmt.Println(helpers.ErrorStyle.Render("   ❌ " + truncate(a.LastError, 160)))
+			}
+			for i, d := range a.OutOfSync {
+				if i == 8 {
+					fmt.Println(helpers.CreateMuted(fmt.Sprintf("   ... and %d more", len(a.OutOfSync)-i)))
+					break
+				}
+				ref := d.Kind + " " + d.Name
+				if d.Namespace != "" {
+					ref = d.Kind + " " + d.Namespace + "/" + d.Name
+				}
+				fmt.Printf("   %s %s\n", changeIcon(d.Change), ref)
+			}
+		}
+	}
 
-	logger.Info("✅ Overall status displayed")
+	if len(r.ApplicationSets) > 0 {
+		fmt.Println()
+		fmt.Printf("%-32s %-6s %-9s %-11s %s\n", "APPLICATIONSET", "APPS", "HEALTHY", "OUTOFSYNC", "STATUS")
+		fmt.Println(strings.Repeat("─", 80))
+		for _, s := range r.ApplicationSets {
+			status := "✅ OK"
+			if s.Error != "" {
+				status = "❌ " + truncate(s.Error, 60)
+			}
+			fmt.Printf("%-32s %-6d %-9d %-11d %s\n", truncate(s.Name, 32), s.Apps, s.Healthy, s.OutOfSync, status)
+		}
+	}
 	return nil
 }
+
+func driftLabel(a appStatus) string {
+	switch a.Drift {
+	case "latest":
+		return "✅ latest"
+	case "behind":
+		return "⚠️  behind " + shortSHA(a.LatestCommit)
+	default:
+		return "- " + truncate(a.TargetRevision, 18)
+	}
+}
+
+// diffLabel renders counts like "2 modified, 1 extra".
+func diffLabel(summary map[string]int) string {
+	if len(summary) == 0 {
+		return "-"
+	}
+	var parts []string
+	for _, change := range []string{"modified", "missing", "extra"} {
+		if n := summary[change]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, change))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func changeIcon(change string) string {
+	switch change {
+	case "missing":
+		return "➕"
+	case "extra":
+		return "➖"
+	default:
+		return "✏️ "
+	}
+}
@@ -0,0 +1,61 @@
+package gitops
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestSummarizeApplication(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "web", "namespace": "adhar-system",
+			"ownerReferences": []interface{}{map[string]interface{}{"kind": "ApplicationSet", "name": "apps", "apiVersion": "argoproj.io/v1alpha1", "uid": "1"}},
+		},
+		"spec": map[string]interface{}{
+			"destination": map[string]interface{}{"namespace": "prod"},
+			"source":      map[string]interface{}{"repoURL": "http://gitea-http.adhar-system.svc:3000/adhar/web.git", "targetRevision": "main"},
+		},
+		"status": map[string]interface{}{
+			"sync":   map[string]interface{}{"status": "OutOfSync", "revision": "old"},
+			"health": map[string]interface{}{"status": "Degraded"},
+			"resources": []interface{}{
+				map[string]interface{}{"kind": "Deployment", "namespace": "prod", "name": "web", "status": "OutOfSync", "health": map[string]interface{}{"status": "Degraded"}},
+				map[string]interface{}{"kind": "ConfigMap", "namespace": "prod", "name": "old", "status": "OutOfSync", "requiresPruning": true},
+				map[string]interface{}{"kind": "Service", "namespace": "prod", "name": "web", "status": "OutOfSync", "health": map[string]interface{}{"status": "Missing"}},
+				map[string]interface{}{"kind": "Secret", "namespace": "prod", "name": "web", "status": "Synced"},
+			},
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "SyncError", "message": "hook failed"},
+			},
+		},
+	}}
+	latest := map[string]string{normalizeRepoURL("http://gitea-http.adhar-system.svc:3000/adhar/web"): "new"}
+	a := summarizeApplication(obj, latest)
+	if a.ApplicationSet != "apps" || a.Destination != "prod" || a.Project != "default" {
+		t.Errorf("summary = %+v", a)
+	}
+	if a.Drift != "behind" || a.LatestCommit != "new" {
+		t.Errorf("drift = %s (%s)", a.Drift, a.LatestCommit)
+	}
+	if a.DiffSummary["modified"] != 1 || a.DiffSummary["extra"] != 1 || a.DiffSummary["missing"] != 1 || len(a.OutOfSync) != 3 {
+		t.Errorf("diff = %v", a.DiffSummary)
+	}
+	if diffLabel(a.DiffSummary) != "1 modified, 1 missing, 1 extra" {
+		t.Errorf("diffLabel = %q", diffLabel(a.DiffSummary))
+	}
+	if a.LastError != "SyncError: hook failed" {
+		t.Errorf("LastError = %q", a.LastError)
+	}
+	if a.Healthy() {
+		t.Errorf("degraded app reported healthy")
+	}
+
+	sets := summarizeApplicationSets([]unstructured.Unstructured{
+		{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "apps", "namespace": "adhar-system"}}},
+		{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "other", "namespace": "adhar-system"}}},
+	}, []appStatus{a}, true)
+	if len(sets) != 1 || sets[0].Apps != 1 || sets[0].OutOfSync != 1 {
+		t.Errorf("appsets = %+v", sets)
+	}
+}
@@ -1,59 +1,641 @@
 package gitops
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
-	"adhar-io/adhar/platform/logger"
+	"adhar-io/adhar/cmd/helpers"
 
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
 )
 
 var syncCmd = &cobra.Command{
-	Use:   "sync",
+	Use:   "sync [app...]",
 	Short: "Sync applications",
-	Long: `Sync applications with their Git repositories.
-	
+	Long: `Sync ArgoCD Applications with their Git repositories.
+
+A sync is requested by setting the Application's operation field, exactly as
+the ArgoCD API server does, and then followed until it finishes: every
+resource's sync result and health is printed as it changes. Applications are
+addressed by name (in the ArgoCD namespace) or as namespace/name.
+
+Several Applications can be synced at once: name them, narrow them with
+--selector, --project and --cluster, or pass --all for every Application in
+the cluster. Bulk syncs honour the
+` + "`argocd.argoproj.io/sync-wave`" + ` annotation on the Applications: waves run
+in ascending order, apps within a wave run --concurrency at a time, and a
+wave with failures stops the later ones.
+
+The command exits non-zero if any application fails to sync or ends up
+Degraded.
+
 Examples:
-  adhar gitops sync
-  adhar gitops sync --app=my-app
-  adhar gitops sync --app=my-app --revision=main`,
+  adhar gitops sync my-app
+  adhar gitops sync --app=my-app --revision=main --prune
+  adhar gitops sync my-app --resource=apps:Deployment:web --resource=:Service:web
+  adhar gitops sync my-app --sync-option=ServerSideApply=true --dry-run
+  adhar gitops sync --selector=adhar.io/environment=staging --concurrency=8
+  adhar gitops sync --project=platform --cluster=in-cluster
+  adhar gitops sync --all`,
 	RunE: runSync,
 }
 
-func runSync(cmd *cobra.Command, args []string) error {
-	logger.Info("🔄 Syncing applications...")
+var (
+	syncDryRun      bool
+	syncResources   []string
+	syncOptions     []string
+	syncSelector    string
+	syncProject     string
+	syncCluster     string
+	syncConcurrency int
+	syncAsync       bool
+	syncAll         bool
+)
+
+func init() {
+	syncCmd.Flags().StringVarP(&app, "app", "a", "", "Application name")
+	syncCmd.Flags().StringVar(&revision, "revision", "", "Git revision/branch/tag to sync to (default: the app's target revision)")
+	syncCmd.Flags().BoolVar(&prune, "prune", false, "Delete resources no longer in Git")
+	syncCmd.Flags().BoolVar(&force, "force", false, "Replace resources that cannot be patched (kubectl apply --force)")
+	syncCmd.Flags().StringVarP(&timeout, "timeout", "i", "5m", "Maximum time to wait for each application")
+	syncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Preview the sync without changing anything")
+	syncCmd.Flags().StringArrayVar(&syncResources, "resource", nil, "Only sync this resource, as GROUP:KIND:NAME or GROUP:KIND:NAMESPACE/NAME (repeatable)")
+	syncCmd.Flags().StringArrayVar(&syncOptions, "sync-option", nil, "ArgoCD sync option, e.g. ServerSideApply=true (repeatable)")
+	syncCmd.Flags().StringVarP(&syncSelector, "selector", "l", "", "Sync applications matching this label selector")
+	syncCmd.Flags().StringVarP(&syncProject, "project", "p", "", "Sync applications in this ArgoCD project")
+	syncCmd.Flags().StringVar(&syncCluster, "cluster", "", "Sync applications deploying to this cluster (server URL or name)")
+	syncCmd.Flags().IntVar(&syncConcurrency, "concurrency", 4, "Applications synced in parallel within a sync wave")
+	syncCmd.Flags().BoolVar(&syncAsync, "async", false, "Request the sync and return without waiting")
+	syncCmd.Flags().BoolVar(&syncAll, "all", false, "Sync every Application in the cluster")
+}
 
+func runSync(cmd *cobra.Command, args []string) error {
 	if app != "" {
-		return syncApplication(app)
+		args = append([]string{app}, args...)
+	}
+	filtered := syncSelector != "" || syncProject != "" || syncCluster != ""
+	switch {
+	case syncAll && (len(args) > 0 || filtered):
+		return fmt.Errorf("--all cannot be combined with application names or filters")
+	case !syncAll && len(args) == 0 && !filtered:
+		return fmt.Errorf("name the applications to sync, narrow them with --selector, --project or --cluster, or pass --all to sync every application")
+	}
+	req, err := newSyncRequest()
+	if err != nil {
+		return err
+	}
+	wait, err := operationTimeout()
+	if err != nil {
+		return err
+	}
+	dyn, err := getDynamicClient()
+	if err != nil {
+		return unreachable(err)
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	bulk := len(args) != 1 || filtered
+	if !bulk {
+		return syncApplication(ctx, dyn, args[0], req, wait)
+	}
+	if len(syncResources) > 0 {
+		return fmt.Errorf("--resource only applies to a single application")
+	}
+	return syncAllApplications(ctx, dyn, args, req, wait)
+}
+
+func syncApplication(ctx context.Context, dyn dynamic.Interface, ref string, req syncRequest, wait time.Duration) error {
+	ns, name := splitAppRef(ref)
+	fmt.Println(helpers.TitleStyle.Render(fmt.Sprintf("🔄 Syncing %s", name)))
+	obj, err := dyn.Resource(argoApplicationGVR).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if crdMissing(err) {
+			return fmt.Errorf("ArgoCD Application CRD not installed")
+		}
+		return fmt.Errorf("failed to get application %s/%s: %w", ns, name, err)
 	}
 
-	return syncAllApplications()
+	res := syncOne(ctx, dyn, obj, req, wait, &syncPrinter{})
+	printSyncResults([]syncResult{res})
+	if res.Failed() {
+		return fmt.Errorf("application %s: %s", name, res.Summary())
+	}
+	return nil
 }
 
-func syncApplication(appName string) error {
-	logger.Info(fmt.Sprintf("🔄 Syncing application: %s", appName))
+func syncAllApplications(ctx context.Context, dyn dynamic.Interface, names []string, req syncRequest, wait time.Duration) error {
+	fmt.Println(helpers.TitleStyle.Render("🔄 Syncing applications"))
+	apps, err := selectApplications(ctx, dyn, names)
+	if err != nil {
+		return err
+	}
+	if len(apps) == 0 {
+		fmt.Println(helpers.CreateMuted("   No applications match"))
+		return nil
+	}
+	if syncConcurrency < 1 {
+		syncConcurrency = 1
+	}
 
-	// TODO: Implement application synchronization
-	// This should:
-	// - Connect to ArgoCD
-	// - Trigger sync for specific application
-	// - Monitor sync progress
-	// - Report sync results
+	waves := planSyncWaves(apps)
+	printer := &syncPrinter{prefix: true}
+	var results []syncResult
+	stop := false
+	for _, wave := range waves {
+		if stop {
+			for _, a := range wave.Apps {
+				results = append(results, syncResult{App: a.GetName(), Skipped: true})
+			}
+			continue
+		}
+		if len(waves) > 1 {
+			fmt.Println(helpers.CreateSection(fmt.Sprintf("Wave %d (%d app(s))", wave.Wave, len(wave.Apps))))
+		}
 
-	logger.Info("✅ Application sync completed")
+		out := make([]syncResult, len(wave.Apps))
+		sem := make(chan struct{}, syncConcurrency)
+		var wg sync.WaitGroup
+		for i := range wave.Apps {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				out[i] = syncOne(ctx, dyn, &wave.Apps[i], req, wait, printer)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, r := range out {
+			if r.Failed() {
+				stop = true
+			}
+		}
+		results = append(results, out...)
+	}
+
+	printSyncResults(results)
+	failed := 0
+	for _, r := range results {
+		if r.Failed() {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d application(s) failed or degraded", failed, len(results))
+	}
 	return nil
 }
 
-func syncAllApplications() error {
-	logger.Info("🔄 Syncing all applications...")
+// syncRequest is the sync operation requested from the command line.
+type syncRequest struct {
+	Revision  string
+	Prune     bool
+	DryRun    bool
+	Force     bool
+	Options   []string
+	Resources []syncResource
+}
+
+// syncResource selects a single resource for a partial sync.
+type syncResource struct {
+	Group, Kind, Namespace, Name string
+}
 
-	// TODO: Implement bulk application synchronization
-	// This should:
-	// - Get all ArgoCD applications
-	// - Trigger sync for all applications
-	// - Monitor overall progress
-	// - Report sync summary
+func newSyncRequest() (syncRequest, error) {
+	req := syncRequest{Revision: revision, Prune: prune, DryRun: syncDryRun, Force: force, Options: syncOptions}
+	for _, s := range syncResources {
+		r, err := parseSyncResource(s)
+		if err != nil {
+			return req, err
+		}
+		req.Resources = append(req.Resources, r)
+	}
+	for _, o := range syncOptions {
+		if !strings.Contains(o, "=") {
+			return req, fmt.Errorf("invalid --sync-option %q (want Name=value, e.g. CreateNamespace=true)", o)
+		}
+	}
+	return req, nil
+}
 
-	logger.Info("✅ All applications synced")
+// parseSyncResource parses the argocd CLI format GROUP:KIND:NAME, where NAME
+// may be NAMESPACE/NAME and GROUP is empty for core resources.
+func parseSyncResource(s string) (syncResource, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return syncResource{}, fmt.Errorf("invalid --resource %q (want GROUP:KIND:NAME or GROUP:KIND:NAMESPACE/NAME)", s)
+	}
+	r := syncResource{Group: parts[0], Kind: parts[1], Name: parts[2]}
+	if ns, name, ok := strings.Cut(parts[2], "/"); ok {
+		r.Namespace, r.Name = ns, name
+	}
+	return r, nil
+}
+
+// operation renders the Application .operation for the request.
+func (r syncRequest) operation() map[string]interface{} {
+	s := map[string]interface{}{"prune": r.Prune}
+	if r.Revision != "" {
+		s["revision"] = r.Revision
+	}
+	if r.DryRun {
+		s["dryRun"] = true
+	}
+	if len(r.Options) > 0 {
+		opts := make([]interface{}, len(r.Options))
+		for i, o := range r.Options {
+			opts[i] = o
+		}
+		s["syncOptions"] = opts
+	}
+	if len(r.Resources) > 0 {
+		res := make([]interface{}, len(r.Resources))
+		for i, rr := range r.Resources {
+			m := map[string]interface{}{"group": rr.Group, "kind": rr.Kind, "name": rr.Name}
+			if rr.Namespace != "" {
+				m["namespace"] = rr.Namespace
+			}
+			res[i] = m
+		}
+		s["resources"] = res
+	}
+	if r.Force {
+		s["syncStrategy"] = map[string]interface{}{"apply": map[string]interface{}{"force": true}}
+	}
+	return map[string]interface{}{
+		"initiatedBy": map[string]interface{}{"username": "adhar-cli"},
+		"info":        []interface{}{map[string]interface{}{"name": "Reason", "value": "adhar gitops sync"}},
+		"sync":        s,
+	}
+}
+
+// requestSync sets the Application's operation. ArgoCD refuses to start a
+// new operation while one is running, so neither do we.
+func requestSync(ctx context.Context, dyn dynamic.Interface, obj *unstructured.Unstructured, op map[string]interface{}) error {
+	if _, running, _ := unstructured.NestedMap(obj.Object, "operation"); running &&
+		nestedString(obj.Object, "status", "operationState", "phase") == "Running" {
+		return fmt.Errorf("another operation is already in progress")
+	}
+	if _, multi, _ := unstructured.NestedSlice(obj.Object, "spec", "sources"); multi && nestedString(op, "sync", "revision") != "" {
+		return fmt.Errorf("--revision is not supported for multi-source applications")
+	}
+	patch, err := json.Marshal(map[string]interface{}{"operation": op})
+	if err != nil {
+		return err
+	}
+	_, err = dyn.Resource(argoApplicationGVR).Namespace(obj.GetNamespace()).Patch(ctx, obj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to request sync: %w", err)
+	}
 	return nil
 }
+
+// syncResult is the outcome of syncing one Application.
+type syncResult struct {
+	App      string
+	Phase    string // operation phase: Succeeded, Failed, Error…
+	Health   string
+	Revision string
+	Message  string
+	Skipped  bool
+	Err      error
+}
+
+// Failed reports whether the app counts against the exit code.
+func (r syncResult) Failed() bool {
+	if r.Skipped {
+		return false
+	}
+	return r.Err != nil || (r.Phase != "" && r.Phase != "Succeeded") || r.Health == "Degraded" || r.Health == "Missing"
+}
+
+// Summary is a one-line description of the outcome.
+func (r syncResult) Summary() string {
+	switch {
+	case r.Skipped:
+		return "skipped (an earlier sync wave failed)"
+	case r.Err != nil:
+		return r.Err.Error()
+	case r.Phase != "Succeeded":
+		return strings.TrimSpace(r.Phase + ": " + r.Message)
+	default:
+		return "synced, " + r.Health
+	}
+}
+
+// syncOne requests a sync of obj and follows it to completion.
+func syncOne(ctx context.Context, dyn dynamic.Interface, obj *unstructured.Unstructured, req syncRequest, wait time.Duration, p *syncPrinter) syncResult {
+	res := syncResult{App: obj.GetName()}
+	// Re-read the app so the running-operation check and the prior operation
+	// are current; bulk syncs list every app before the first wave starts.
+	cur, err := dyn.Resource(argoApplicationGVR).Namespace(obj.GetNamespace()).Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		res.Err = fmt.Errorf("failed to get application: %w", err)
+		p.Printf(res.App, "%s", helpers.ErrorStyle.Render("❌ "+res.Err.Error()))
+		return res
+	}
+	obj = cur
+	prior := operationStartedAt(obj.Object)
+	if err := requestSync(ctx, dyn, obj, req.operation()); err != nil {
+		res.Err = err
+		p.Printf(res.App, "%s", helpers.ErrorStyle.Render("❌ "+err.Error()))
+		return res
+	}
+	p.Printf(res.App, "🚀 Sync requested%s", describeTarget(req))
+	if syncAsync {
+		res.Phase = "Succeeded"
+		res.Message = "requested"
+		return res
+	}
+	return followOperation(ctx, dyn, obj, prior, req.DryRun, wait, p)
+}
+
+// followOperation polls an Application until an operation newer than the one
+// that started at prior finishes and, unless dryRun, its health settles.
+// Resource changes are streamed through p.
+func followOperation(ctx context.Context, dyn dynamic.Interface, obj *unstructured.Unstructured, prior string, dryRun bool, wait time.Duration, p *syncPrinter) syncResult {
+	res := syncResult{App: obj.GetName()}
+	cctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	seen := map[string]string{}
+	var opDone time.Time
+	for {
+		cur, err := dyn.Resource(argoApplicationGVR).Namespace(obj.GetNamespace()).Get(cctx, obj.GetName(), metav1.GetOptions{})
+		if err == nil {
+			op, _, _ := unstructured.NestedMap(cur.Object, "status", "operationState")
+			ours := newOperation(op, prior)
+			if ours {
+				for _, line := range resourceUpdates(cur.Object, seen) {
+					p.Printf(res.App, "%s", line)
+				}
+			}
+			res.Health = nestedString(cur.Object, "status", "health", "status")
+			phase := nestedString(op, "phase")
+			if ours && phase != "Running" && phase != "Terminating" && phase != "" {
+				res.Phase = phase
+				res.Message = nestedString(op, "message")
+				res.Revision = nestedString(op, "syncResult", "revision")
+				if opDone.IsZero() {
+					opDone = time.Now()
+					p.Printf(res.App, "%s %s %s", phaseIcon(phase), phase, helpers.CreateMuted(truncate(res.Message, 100)))
+				}
+				// Dry runs and failed syncs change nothing worth waiting on;
+				// otherwise wait for health to settle.
+				if phase != "Succeeded" || dryRun || res.Health != "Progressing" {
+					return res
+				}
+			}
+		}
+		select {
+		case <-cctx.Done():
+			if res.Phase == "" {
+				res.Err = fmt.Errorf("timed out after %s waiting for the sync to finish", wait)
+			} else {
+				res.Err = fmt.Errorf("synced but still %s after %s", res.Health, wait)
+			}
+			p.Printf(res.App, "%s", helpers.CreateWarning("⏱️  "+res.Err.Error()))
+			return res
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// operationStartedAt returns the start time ArgoCD recorded for the
+// Application's last operation, or "" when it has none.
+func operationStartedAt(obj map[string]interface{}) string {
+	return nestedString(obj, "status", "operationState", "startedAt")
+}
+
+// newOperation reports whether the operation state belongs to an operation
+// other than the one that started at prior. Both timestamps come from
+// ArgoCD, so the local clock never enters into it.
+func newOperation(op map[string]interface{}, prior string) bool {
+	startedAt := nestedString(op, "startedAt")
+	return startedAt != "" && startedAt != prior
+}
+
+// resourceUpdates returns a line for every resource whose sync result or
+// health changed since the last poll, recording the new state in seen.
+// Resources outside the sync result are only reported while unhealthy.
+func resourceUpdates(obj map[string]interface{}, seen map[string]string) []string {
+	type state struct{ sync, health, msg string }
+	states := map[string]*state{}
+	var keys []string
+	get := func(m map[string]interface{}) *state {
+		k := resourceKey(m)
+		if s, ok := states[k]; ok {
+			return s
+		}
+		s := &state{}
+		states[k] = s
+		keys = append(keys, k)
+		return s
+	}
+
+	results, _, _ := unstructured.NestedSlice(obj, "status", "operationState", "syncResult", "resources")
+	for _, r := range results {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		s := get(m)
+		s.sync = nestedString(m, "status")
+		if hook := nestedString(m, "hookType"); hook != "" {
+			s.sync = hook + " " + nestedString(m, "hookPhase")
+		}
+		s.msg = nestedString(m, "message")
+	}
+	resources, _, _ := unstructured.NestedSlice(obj, "status", "resources")
+	for _, r := range resources {
+		m, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		h := nestedString(m, "health", "status")
+		if _, tracked := states[resourceKey(m)]; h != "" && (tracked || h != "Healthy") {
+			get(m).health = h
+		}
+	}
+
+	var out []string
+	for _, k := range keys {
+		s := states[k]
+		cur := s.sync + "|" + s.health
+		if seen[k] == cur {
+			continue
+		}
+		seen[k] = cur
+		line := fmt.Sprintf("   %s %-52s %-16s %s", healthIcon(s.health), truncate(k, 52), valueOr(s.sync, "-"), s.health)
+		if s.msg != "" && s.sync != "Synced" {
+			line += " " + helpers.CreateMuted(truncate(s.msg, 80))
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// resourceKey identifies a resource entry as [group/]Kind [namespace/]name.
+func resourceKey(m map[string]interface{}) string {
+	kind := nestedString(m, "kind")
+	if g := nestedString(m, "group"); g != "" {
+		kind = g + "/" + kind
+	}
+	name := nestedString(m, "name")
+	if ns := nestedString(m, "namespace"); ns != "" {
+		name = ns + "/" + name
+	}
+	return kind + " " + name
+}
+
+func describeTarget(req syncRequest) string {
+	var parts []string
+	if req.Revision != "" {
+		parts = append(parts, "revision "+req.Revision)
+	}
+	if req.Prune {
+		parts = append(parts, "prune")
+	}
+	if req.DryRun {
+		parts = append(parts, "dry-run")
+	}
+	if len(req.Resources) > 0 {
+		parts = append(parts, fmt.Sprintf("%d resource(s)", len(req.Resources)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// selectApplications returns the named Applications, or every Application
+// matching the selector/project/cluster filters.
+func selectApplications(ctx context.Context, dyn dynamic.Interface, names []string) ([]unstructured.Unstructured, error) {
+	list, err := dyn.Resource(argoApplicationGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{LabelSelector: syncSelector})
+	if err != nil {
+		if crdMissing(err) {
+			return nil, fmt.Errorf("ArgoCD Application CRD not installed")
+		}
+		return nil, fmt.Errorf("failed to list applications: %w", err)
+	}
+	wanted := map[string]bool{}
+	for _, n := range names {
+		ns, name := splitAppRef(n)
+		wanted[ns+"/"+name] = true
+	}
+	var out []unstructured.Unstructured
+	for _, a := range list.Items {
+		if len(wanted) > 0 && !wanted[a.GetNamespace()+"/"+a.GetName()] {
+			continue
+		}
+		if syncProject != "" && nestedString(a.Object, "spec", "project") != syncProject {
+			continue
+		}
+		if syncCluster != "" && nestedString(a.Object, "spec", "destination", "server") != syncCluster &&
+			nestedString(a.Object, "spec", "destination", "name") != syncCluster {
+			continue
+		}
+		out = append(out, a)
+		delete(wanted, a.GetNamespace()+"/"+a.GetName())
+	}
+	if len(wanted) > 0 {
+		missing := make([]string, 0, len(wanted))
+		for k := range wanted {
+			missing = append(missing, k)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("application(s) not found or filtered out: %s", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// syncWave is a group of Applications sharing a sync-wave annotation.
+type syncWave struct {
+	Wave int
+	Apps []unstructured.Unstructured
+}
+
+// planSyncWaves groups apps by their sync-wave annotation (default 0) in
+// ascending order; apps within a wave are sorted by name.
+func planSyncWaves(apps []unstructured.Unstructured) []syncWave {
+	byWave := map[int][]unstructured.Unstructured{}
+	for _, a := range apps {
+		w, err := strconv.Atoi(strings.TrimSpace(a.GetAnnotations()[syncWaveAnnotation]))
+		if err != nil {
+			w = 0
+		}
+		byWave[w] = append(byWave[w], a)
+	}
+	out := make([]syncWave, 0, len(byWave))
+	for w, list := range byWave {
+		sort.Slice(list, func(i, j int) bool { return list[i].GetName() < list[j].GetName() })
+		out = append(out, syncWave{Wave: w, Apps: list})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Wave < out[j].Wave })
+	return out
+}
+
+// syncPrinter serialises progress lines from concurrent syncs, prefixing
+// them with the app name in bulk mode.
+type syncPrinter struct {
+	mu     sync.Mutex
+	prefix bool
+}
+
+func (p *syncPrinter) Printf(app, format string, args ...interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	line := fmt.Sprintf(format, args...)
+	if p.prefix {
+		line = helpers.CreateMuted(fmt.Sprintf("[%s]", app)) + " " + line
+	}
+	fmt.Fprintln(os.Stdout, line)
+}
+
+func printSyncResults(results []syncResult) {
+	fmt.Println()
+	fmt.Printf("%-32s %-12s %-12s %-10s %s\n", "APPLICATION", "SYNC", "HEALTH", "REVISION", "RESULT")
+	fmt.Println(strings.Repeat("─", 96))
+	for _, r := range results {
+		icon := "✅"
+		switch {
+		case r.Skipped:
+			icon = "⏭️ "
+		case r.Failed():
+			icon = "❌"
+		}
+		fmt.Printf("%-32s %-12s %-12s %-10s %s %s\n", truncate(r.App, 32), valueOr(r.Phase, "-"), valueOr(r.Health, "-"), truncate(valueOr(r.Revision, "-"), 10), icon, truncate(r.Summary(), 60))
+	}
+}
+
+func phaseIcon(phase string) string {
+	switch phase {
+	case "Succeeded":
+		return "✅"
+	case "Running":
+		return "🔄"
+	default:
+		return "❌"
+	}
+}
+
+func valueOr(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
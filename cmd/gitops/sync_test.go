@@ -0,0 +1,130 @@
+package gitops
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseSyncResource(t *testing.T) {
+	cases := map[string]syncResource{
+		"apps:Deployment:web":         {Group: "apps", Kind: "Deployment", Name: "web"},
+		":Service:web":                {Kind: "Service", Name: "web"},
+		"apps:Deployment:staging/web": {Group: "apps", Kind: "Deployment", Namespace: "staging", Name: "web"},
+	}
+	for in, want := range cases {
+		got, err := parseSyncResource(in)
+		if err != nil || got != want {
+			t.Errorf("parseSyncResource(%q) = %+v, %v; want %+v", in, got, err, want)
+		}
+	}
+	for _, bad := range []string{"Deployment/web", "apps::web", "apps:Deployment:"} {
+		if _, err := parseSyncResource(bad); err == nil {
+			t.Errorf("parseSyncResource(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSyncRequestOperation(t *testing.T) {
+	req := syncRequest{
+		Revision:  "v1.2.0",
+		Prune:     true,
+		Force:     true,
+		Options:   []string{"ServerSideApply=true"},
+		Resources: []syncResource{{Group: "apps", Kind: "Deployment", Namespace: "web", Name: "api"}},
+	}
+	op := req.operation()
+	if nestedString(op, "sync", "revision") != "v1.2.0" {
+		t.Errorf("revision not set: %v", op)
+	}
+	if p, _, _ := unstructured.NestedBool(op, "sync", "prune"); !p {
+		t.Errorf("prune not set")
+	}
+	if f, _, _ := unstructured.NestedBool(op, "sync", "syncStrategy", "apply", "force"); !f {
+		t.Errorf("force not set")
+	}
+	res, _, _ := unstructured.NestedSlice(op, "sync", "resources")
+	if len(res) != 1 || nestedString(res[0].(map[string]interface{}), "namespace") != "web" {
+		t.Errorf("resources = %v", res)
+	}
+	if nestedString(op, "initiatedBy", "username") != "adhar-cli" {
+		t.Errorf("initiatedBy not set")
+	}
+}
+
+func TestPlanSyncWaves(t *testing.T) {
+	mk := func(name, wave string) unstructured.Unstructured {
+		u := unstructured.Unstructured{Object: map[string]interface{}{}}
+		u.SetName(name)
+		if wave != "" {
+			u.SetAnnotations(map[string]string{syncWaveAnnotation: wave})
+		}
+		return u
+	}
+	waves := planSyncWaves([]unstructured.Unstructured{
+		mk("web", "2"), mk("db", "-1"), mk("api", "2"), mk("cache", ""), mk("bad", "x"),
+	})
+	var got []string
+	for _, w := range waves {
+		var names []string
+		for _, a := range w.Apps {
+			names = append(names, a.GetName())
+		}
+		got = append(got, strings.Join(names, ","))
+	}
+	want := []string{"db", "bad,cache", "api,web"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("waves = %v, want %v", got, want)
+	}
+}
+
+func TestResourceUpdates(t *testing.T) {
+	obj := map[string]interface{}{
+		"status": map[string]interface{}{
+			"operationState": map[string]interface{}{
+				"syncResult": map[string]interface{}{
+					"resources": []interface{}{
+						map[string]interface{}{"group": "apps", "kind": "Deployment", "namespace": "web", "name": "api", "status": "Synced"},
+					},
+				},
+			},
+			"resources": []interface{}{
+				map[string]interface{}{"group": "apps", "kind": "Deployment", "namespace": "web", "name": "api", "status": "Synced", "health": map[string]interface{}{"status": "Progressing"}},
+				map[string]interface{}{"kind": "Service", "namespace": "web", "name": "api", "status": "Synced", "health": map[string]interface{}{"status": "Healthy"}},
+			},
+		},
+	}
+	seen := map[string]string{}
+	lines := resourceUpdates(obj, seen)
+	if len(lines) != 1 || !strings.Contains(lines[0], "apps/Deployment web/api") || !strings.Contains(lines[0], "Progressing") {
+		t.Fatalf("lines = %q", lines)
+	}
+	if again := resourceUpdates(obj, seen); len(again) != 0 {
+		t.Errorf("unchanged state reported again: %q", again)
+	}
+}
+
+func TestNewOperation(t *testing.T) {
+	op := func(startedAt string) map[string]interface{} {
+		if startedAt == "" {
+			return nil
+		}
+		return map[string]interface{}{"startedAt": startedAt, "phase": "Succeeded"}
+	}
+	cases := []struct {
+		startedAt, prior string
+		want             bool
+	}{
+		{"2026-01-01T10:00:00Z", "2026-01-01T10:00:00Z", false}, // still the previous operation
+		{"2026-01-01T10:00:05Z", "2026-01-01T10:00:00Z", true},
+		{"2026-01-01T09:59:00Z", "2026-01-01T10:00:00Z", true}, // ArgoCD's clock, not ours
+		{"2026-01-01T10:00:00Z", "", true},                     // first operation on the app
+		{"", "", false},
+	}
+	for _, c := range cases {
+		if got := newOperation(op(c.startedAt), c.prior); got != c.want {
+			t.Errorf("newOperation(%q, prior %q) = %v, want %v", c.startedAt, c.prior, got, c.want)
+		}
+	}
+}
@@ -1,134 +1,461 @@
 package gitops
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"adhar-io/adhar/platform/logger"
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/k8s"
+	"adhar-io/adhar/platform/utils"
 
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
 )
 
 var workflowCmd = &cobra.Command{
-	Use:   "workflow",
+	Use:   "workflow [list|create|trigger|approve|stop|delete]",
 	Short: "Manage GitOps workflows",
-	Long: `Manage GitOps workflows and automation.
-	
+	Long: `Manage promotion workflows that move a version through environments,
+e.g. dev → staging → prod.
+
+Each stage sets a value (an image tag, a chart version) in a YAML file of the
+Gitea GitOps repository, either by committing to the branch or by opening a
+pull request, then waits for the stage's ArgoCD Application to deploy that
+commit and become Healthy. A stage can require manual approval before it
+starts and a soak period of continued health before the next one.
+
+Workflows and the state of their latest run are stored in ConfigMaps in the
+ArgoCD namespace, so a run can be stopped, or the CLI interrupted, and
+` + "`trigger`" + ` resumes it from the last completed step.
+
+Stages are given with --file (YAML) or repeated --stage flags:
+  --stage "name=dev,app=web-dev,file=envs/dev/values.yaml,path=image.tag"
+  --stage "name=prod,app=web-prod,file=envs/prod/kustomization.yaml,path=images[name=web].newTag,mode=pr,approval=true,soak=10m"
+
 Examples:
   adhar gitops workflow list
-  adhar gitops workflow create --name=deploy-prod
-  adhar gitops workflow trigger --name=deploy-prod`,
+  adhar gitops workflow create --name=web --repo=https://gitea.example/acme/deploy.git --stage ... --stage ...
+  adhar gitops workflow create --file=web-promotion.yaml
+  adhar gitops workflow trigger --name=web --version=v1.4.0
+  adhar gitops workflow approve --name=web
+  adhar gitops workflow stop --name=web
+  adhar gitops workflow list --name=web`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runWorkflow,
 }
 
 var (
 	workflowName   string
 	workflowAction string
+
+	workflowFile        string
+	workflowRepo        string
+	workflowBranch      string
+	workflowDescription string
+	workflowStages      []string
+	workflowVersion     string
+	workflowOverwrite   bool
+	workflowRestart     bool
+	workflowForce       bool
 )
 
 func init() {
-	workflowCmd.Flags().StringVarP(&workflowName, "name", "n", "", "Workflow name")
-	workflowCmd.Flags().StringVarP(&workflowAction, "action", "a", "", "Action (create, trigger, stop, delete)")
+	f := workflowCmd.Flags()
+	f.StringVarP(&workflowName, "name", "n", "", "Workflow name")
+	f.StringVarP(&workflowAction, "action", "a", "", "Action (create, trigger, approve, stop, delete); same as the positional argument")
+	f.StringVar(&workflowFile, "file", "", "Workflow definition file (YAML or JSON)")
+	f.StringVar(&workflowRepo, "repo", "", "GitOps repository URL (as ArgoCD sees it)")
+	f.StringVar(&workflowBranch, "branch", "", "Branch to promote on (default: the repository's default branch)")
+	f.StringVar(&workflowDescription, "description", "", "Workflow description")
+	f.StringArrayVar(&workflowStages, "stage", nil, "Stage as key=value pairs: name, app, file, path, mode, approval, soak, timeout (repeatable, in order)")
+	f.StringVar(&workflowVersion, "version", "", "Version to promote (default: the value currently in the first stage)")
+	f.BoolVar(&workflowOverwrite, "overwrite", false, "Replace an existing workflow definition")
+	f.BoolVar(&workflowRestart, "restart", false, "Discard an unfinished run and start a new one")
+	f.BoolVar(&workflowForce, "force", false, "Delete a workflow even if a run is in progress")
 }
 
 func runWorkflow(cmd *cobra.Command, args []string) error {
-	logger.Info("⚡ Managing GitOps workflows...")
+	action := workflowAction
+	if len(args) == 1 {
+		action = args[0]
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return unreachable(err)
+	}
 
-	switch workflowAction {
+	switch action {
 	case "create":
-		return createWorkflow(workflowName)
-	case "trigger":
-		return triggerWorkflow(workflowName)
+		return createWorkflow(ctx, cs)
+	case "trigger", "resume":
+		return triggerWorkflow(ctx, cs, workflowName)
+	case "approve":
+		return approveWorkflow(ctx, cs, workflowName)
 	case "stop":
-		return stopWorkflow(workflowName)
+		return stopWorkflow(ctx, cs, workflowName)
 	case "delete":
-		return deleteWorkflow(workflowName)
+		return deleteWorkflow(ctx, cs, workflowName)
+	case "", "list", "show":
+		if workflowName != "" {
+			return showWorkflow(ctx, cs, workflowName)
+		}
+		return listWorkflows(ctx, cs)
 	default:
-		return listWorkflows()
+		return fmt.Errorf("unknown workflow action %q (want list, create, trigger, approve, stop or delete)", action)
 	}
 }
 
-func createWorkflow(name string) error {
-	if name == "" {
-		return fmt.Errorf("workflow name is required")
+func createWorkflow(ctx context.Context, cs kubernetes.Interface) error {
+	var wf promotionWorkflow
+	if workflowFile != "" {
+		data, err := os.ReadFile(workflowFile)
+		if err != nil {
+			return fmt.Errorf("failed to read workflow file: %w", err)
+		}
+		if err := yaml.UnmarshalStrict(data, &wf); err != nil {
+			return fmt.Errorf("invalid workflow file %s: %w", workflowFile, err)
+		}
+	}
+	if workflowName != "" {
+		wf.Name = workflowName
+	}
+	if workflowRepo != "" {
+		wf.Repo = workflowRepo
+	}
+	if workflowBranch != "" {
+		wf.Branch = workflowBranch
+	}
+	if workflowDescription != "" {
+		wf.Description = workflowDescription
+	}
+	for _, spec := range workflowStages {
+		s, err := parseStageSpec(spec)
+		if err != nil {
+			return err
+		}
+		wf.Stages = append(wf.Stages, s)
+	}
+	if err := wf.validate(); err != nil {
+		return err
 	}
 
-	logger.Info("⚡ Creating workflow: " + name)
-
-	// TODO: Implement workflow creation
-	// This should:
-	// - Define workflow steps
-	// - Configure triggers
-	// - Set up approvals
-	// - Save workflow definition
-
-	logger.Info("✅ Workflow created successfully")
+	fmt.Println(helpers.TitleStyle.Render(fmt.Sprintf("⚡ Creating workflow %s", wf.Name)))
+	// Check the repository and every stage's file up front.
+	repo, err := openGitopsRepo(ctx, wf.Repo, wf.Branch)
+	if err != nil {
+		return err
+	}
+	for _, s := range wf.Stages {
+		data, err := repo.ReadFile(s.File)
+		if err != nil {
+			return fmt.Errorf("stage %s: %w", s.Name, err)
+		}
+		cur, err := getYAMLValue(data, s.Path)
+		if err != nil {
+			return fmt.Errorf("stage %s: %s: %w", s.Name, s.File, err)
+		}
+		fmt.Printf("   %-12s %s → %s:%s = %s\n", s.Name, s.App, s.File, s.Path, cur)
+	}
+	if err := saveWorkflow(ctx, cs, wf, workflowOverwrite); err != nil {
+		return err
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Workflow %s saved (%d stages on %s)", wf.Name, len(wf.Stages), repo.Branch)))
 	return nil
 }
 
-func triggerWorkflow(name string) error {
+// parseStageSpec parses a --stage value of comma-separated key=value pairs.
+func parseStageSpec(spec string) (promotionStage, error) {
+	var s promotionStage
+	for _, kv := range strings.Split(spec, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok {
+			return s, fmt.Errorf("invalid --stage %q: %q is not key=value", spec, kv)
+		}
+		switch k {
+		case "name":
+			s.Name = v
+		case "app":
+			s.App = v
+		case "file":
+			s.File = v
+		case "path":
+			s.Path = v
+		case "mode":
+			s.Mode = v
+		case "approval":
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return s, fmt.Errorf("invalid --stage %q: approval must be true or false", spec)
+			}
+			s.Approval = b
+		case "soak":
+			s.Soak = v
+		case "timeout":
+			s.Timeout = v
+		default:
+			return s, fmt.Errorf("invalid --stage %q: unknown key %q", spec, k)
+		}
+	}
+	return s, nil
+}
+
+func triggerWorkflow(ctx context.Context, cs kubernetes.Interface, name string) error {
 	if name == "" {
 		return fmt.Errorf("workflow name is required")
 	}
+	wf, run, err := loadWorkflow(ctx, cs, name)
+	if err != nil {
+		return err
+	}
+	who := currentUser(ctx)
 
-	logger.Info("⚡ Triggering workflow: " + name)
-
-	// TODO: Implement workflow triggering
-	// This should:
-	// - Validate workflow exists
-	// - Check prerequisites
-	// - Start workflow execution
-	// - Monitor progress
-
-	logger.Info("✅ Workflow triggered successfully")
+	switch {
+	case run.Active() && !workflowRestart:
+		if workflowVersion != "" && workflowVersion != run.Version {
+			return fmt.Errorf("run %s of %s is unfinished (%s); pass --restart to promote %s instead", run.ID, run.Version, run.Status, workflowVersion)
+		}
+		fmt.Println(helpers.TitleStyle.Render(fmt.Sprintf("⚡ Resuming %s run %s (%s)", name, run.ID, run.Version)))
+		run.resume()
+	default:
+		version := workflowVersion
+		if version == "" {
+			if version, err = currentStageValue(ctx, wf, 0); err != nil {
+				return err
+			}
+			fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Promoting %s, the version currently in %s", version, wf.Stages[0].Name)))
+		}
+		run = newPromotionRun(wf, version, who)
+		fmt.Println(helpers.TitleStyle.Render(fmt.Sprintf("⚡ Promoting %s through %s (run %s)", version, stageNames(wf), run.ID)))
+	}
+	if err := saveRun(ctx, cs, name, run, true); err != nil {
+		return err
+	}
+	dyn, err := getDynamicClient()
+	if err != nil {
+		return unreachable(err)
+	}
+	engine := &promotionEngine{cs: cs, dyn: dyn, wf: wf, run: run, who: who}
+	if err := engine.Run(ctx); err != nil {
+		fmt.Println(helpers.ErrorStyle.Render("❌ " + err.Error()))
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Retry the failed step with: adhar gitops workflow trigger --name=%s", name)))
+		return fmt.Errorf("workflow %s failed", name)
+	}
+	if run.Status == runSucceeded {
+		fmt.Println(helpers.CreateSuccess("✅ " + run.Message))
+	}
 	return nil
 }
 
-func stopWorkflow(name string) error {
+// currentStageValue reads the value a stage's file currently holds.
+func currentStageValue(ctx context.Context, wf promotionWorkflow, i int) (string, error) {
+	repo, err := openGitopsRepo(ctx, wf.Repo, wf.Branch)
+	if err != nil {
+		return "", err
+	}
+	data, err := repo.ReadFile(wf.Stages[i].File)
+	if err != nil {
+		return "", err
+	}
+	return getYAMLValue(data, wf.Stages[i].Path)
+}
+
+func approveWorkflow(ctx context.Context, cs kubernetes.Interface, name string) error {
 	if name == "" {
 		return fmt.Errorf("workflow name is required")
 	}
+	_, run, err := loadWorkflow(ctx, cs, name)
+	if err != nil {
+		return err
+	}
+	if run == nil || run.Current >= len(run.Stages) || run.Stages[run.Current].Phase != stageAwaitingApproval {
+		return fmt.Errorf("workflow %s has no stage waiting for approval", name)
+	}
+	st := &run.Stages[run.Current]
+	st.ApprovedBy = currentUser(ctx)
+	st.Phase = stagePending
+	run.Status = runRunning
+	if err := saveRun(ctx, cs, name, run, true); err != nil {
+		return err
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ %s approved promotion of %s to %s", st.ApprovedBy, run.Version, st.Name)))
+	return triggerWorkflow(ctx, cs, name)
+}
 
-	logger.Info("⏹️ Stopping workflow: " + name)
-
-	// TODO: Implement workflow stopping
-	// This should:
-	// - Find running workflow
-	// - Gracefully stop execution
-	// - Clean up resources
-	// - Update status
-
-	logger.Info("✅ Workflow stopped successfully")
+func stopWorkflow(ctx context.Context, cs kubernetes.Interface, name string) error {
+	if name == "" {
+		return fmt.Errorf("workflow name is required")
+	}
+	_, run, err := loadWorkflow(ctx, cs, name)
+	if err != nil {
+		return err
+	}
+	if !run.Active() || run.Status == runStopped {
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Workflow %s has no run to stop", name)))
+		return nil
+	}
+	run.Status = runStopped
+	run.Message = "stopped by " + currentUser(ctx)
+	if err := saveRun(ctx, cs, name, run, true); err != nil {
+		return err
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("⏹️  Stopped run %s of %s at stage %s", run.ID, name, currentStageName(run))))
+	fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Resume with: adhar gitops workflow trigger --name=%s", name)))
 	return nil
 }
 
-func deleteWorkflow(name string) error {
+func deleteWorkflow(ctx context.Context, cs kubernetes.Interface, name string) error {
 	if name == "" {
 		return fmt.Errorf("workflow name is required")
 	}
+	_, run, err := loadWorkflow(ctx, cs, name)
+	if err != nil {
+		return err
+	}
+	if (run.Active() && run.Status != runFailed && run.Status != runStopped) && !workflowForce {
+		return fmt.Errorf("workflow %s has a run in progress (%s); stop it first or pass --force", name, run.Status)
+	}
+	if err := cs.CoreV1().ConfigMaps(utils.ArgocdNamespace).Delete(ctx, workflowConfigMapName(name), metav1.DeleteOptions{}); err != nil {
+		return fmt.Errorf("failed to delete workflow %s: %w", name, err)
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("🗑️  Workflow %s deleted", name)))
+	return nil
+}
 
-	logger.Info("🗑️ Deleting workflow: " + name)
-
-	// TODO: Implement workflow deletion
-	// This should:
-	// - Check if workflow is running
-	// - Remove workflow definition
-	// - Clean up configurations
-	// - Verify deletion
+func listWorkflows(ctx context.Context, cs kubernetes.Interface) error {
+	fmt.Println(helpers.TitleStyle.Render("⚡ GitOps Workflows"))
+	list, err := cs.CoreV1().ConfigMaps(utils.ArgocdNamespace).List(ctx, metav1.ListOptions{LabelSelector: workflowLabel})
+	if err != nil {
+		return fmt.Errorf("failed to list workflows: %w", err)
+	}
+	if len(list.Items) == 0 {
+		fmt.Println(helpers.CreateMuted("   No workflows defined. Create one with `adhar gitops workflow create`"))
+		return nil
+	}
+	fmt.Printf("%-20s %-34s %-12s %-12s %-16s %s\n", "NAME", "STAGES", "VERSION", "STATUS", "STAGE", "UPDATED")
+	fmt.Println(strings.Repeat("─", 110))
+	for i := range list.Items {
+		wf, run, err := decodeWorkflow(&list.Items[i])
+		if err != nil {
+			fmt.Printf("%-20s %s\n", list.Items[i].Name, helpers.ErrorStyle.Render(err.Error()))
+			continue
+		}
+		version, status, stage, updated := "-", "Never run", "-", "-"
+		if run != nil {
+			version, status, stage, updated = run.Version, runIcon(run.Status)+" "+run.Status, currentStageName(run), age(run.UpdatedAt)
+		}
+		fmt.Printf("%-20s %-34s %-12s %-12s %-16s %s\n", wf.Name, truncate(stageNames(wf), 34), truncate(version, 12), status, stage, updated)
+	}
+	return nil
+}
 
-	logger.Info("✅ Workflow deleted successfully")
+func showWorkflow(ctx context.Context, cs kubernetes.Interface, name string) error {
+	wf, run, err := loadWorkflow(ctx, cs, name)
+	if err != nil {
+		return err
+	}
+	fmt.Println(helpers.TitleStyle.Render(fmt.Sprintf("⚡ Workflow %s", wf.Name)))
+	if wf.Description != "" {
+		fmt.Println(helpers.CreateMuted("   " + wf.Description))
+	}
+	fmt.Printf("Repository: %s (%s)\n", wf.Repo, valueOr(wf.Branch, "default branch"))
+	if run == nil {
+		fmt.Println(helpers.CreateMuted("   Never run"))
+	} else {
+		fmt.Printf("Run %s: %s %s %s, started by %s %s\n", run.ID, run.Version, runIcon(run.Status), run.Status, run.StartedBy, age(run.StartedAt))
+		if run.Message != "" {
+			fmt.Println(helpers.CreateMuted("   " + run.Message))
+		}
+	}
+	fmt.Println()
+	fmt.Printf("%-12s %-24s %-6s %-9s %-18s %s\n", "STAGE", "APP", "MODE", "GATE", "PHASE", "DETAIL")
+	fmt.Println(strings.Repeat("─", 100))
+	for i, s := range wf.Stages {
+		gate := "health"
+		if s.Approval {
+			gate = "approval"
+		}
+		phase, detail := "-", fmt.Sprintf("%s:%s", s.File, s.Path)
+		if run != nil && i < len(run.Stages) {
+			st := run.Stages[i]
+			phase = stageIcon(st.Phase) + " " + st.Phase
+			switch {
+			case st.Message != "":
+				detail = st.Message
+			case st.PR != 0 && st.Commit == "":
+				detail = fmt.Sprintf("PR #%d %s", st.PR, st.PRURL)
+			case st.Commit != "":
+				detail = fmt.Sprintf("%s → %s @ %s", valueOr(st.Previous, "?"), run.Version, shortSHA(st.Commit))
+			}
+			if st.ApprovedBy != "" {
+				detail += " (approved by " + st.ApprovedBy + ")"
+			}
+		}
+		fmt.Printf("%-12s %-24s %-6s %-9s %-18s %s\n", s.Name, truncate(s.App, 24), valueOr(s.Mode, promotionModeCommit), gate, phase, truncate(detail, 60))
+	}
 	return nil
 }
 
-func listWorkflows() error {
-	logger.Info("📋 Listing GitOps workflows...")
+func stageNames(wf promotionWorkflow) string {
+	names := make([]string, 0, len(wf.Stages))
+	for _, s := range wf.Stages {
+		names = append(names, s.Name)
+	}
+	return strings.Join(names, " → ")
+}
 
-	// TODO: Implement workflow listing
-	// This should show:
-	// - All defined workflows
-	// - Current status
-	// - Last execution time
-	// - Trigger configuration
+func currentStageName(run *promotionRun) string {
+	if run.Current < len(run.Stages) {
+		return run.Stages[run.Current].Name
+	}
+	return "-"
+}
 
-	logger.Info("✅ Workflows listed")
-	return nil
+func age(ts string) string {
+	t, err := time.Parse(time.RFC3339, ts)
+	if err != nil {
+		return "-"
+	}
+	return time.Since(t).Truncate(time.Second).String() + " ago"
+}
+
+func runIcon(status string) string {
+	switch status {
+	case runSucceeded:
+		return "✅"
+	case runFailed:
+		return "❌"
+	case runWaiting:
+		return "✋"
+	case runStopped:
+		return "⏹️"
+	default:
+		return "🔄"
+	}
+}
+
+func stageIcon(phase string) string {
+	switch phase {
+	case stageSucceeded:
+		return "✅"
+	case stageFailed:
+		return "❌"
+	case stageAwaitingApproval:
+		return "✋"
+	case stagePROpen:
+		return "🔀"
+	case stageCommitted, stageHealthy:
+		return "🔄"
+	default:
+		return "⚪"
+	}
 }
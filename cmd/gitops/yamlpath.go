@@ -0,0 +1,170 @@
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlPathSegment is one step of a path such as
+// `images[name=web].newTag` or `dependencies[0].version`.
+type yamlPathSegment struct {
+	Key      string // mapping key; empty for a bare selector
+	Index    int    // list index when Selector is "#"
+	Selector string // "", "#" (index) or "field=value"
+}
+
+func parseYAMLPath(path string) ([]yamlPathSegment, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty YAML path")
+	}
+	var segs []yamlPathSegment
+	for _, part := range strings.Split(path, ".") {
+		if part == "" {
+			return nil, fmt.Errorf("invalid YAML path %q: empty segment", path)
+		}
+		key, sel, hasSel := strings.Cut(part, "[")
+		if key != "" {
+			segs = append(segs, yamlPathSegment{Key: key})
+		}
+		for hasSel {
+			var inner string
+			inner, sel, _ = strings.Cut(sel, "]")
+			switch {
+			case inner == "":
+				return nil, fmt.Errorf("invalid YAML path %q: empty selector", path)
+			case strings.Contains(inner, "="):
+				segs = append(segs, yamlPathSegment{Selector: inner})
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("invalid YAML path %q: bad index %q", path, inner)
+				}
+				segs = append(segs, yamlPathSegment{Selector: "#", Index: i})
+			}
+			if sel == "" {
+				break
+			}
+			if !strings.HasPrefix(sel, "[") {
+				return nil, fmt.Errorf("invalid YAML path %q", path)
+			}
+			sel = sel[1:]
+		}
+	}
+	return segs, nil
+}
+
+// lookupYAMLNode walks path from the document root.
+func lookupYAMLNode(doc *yaml.Node, path string) (*yaml.Node, error) {
+	segs, err := parseYAMLPath(path)
+	if err != nil {
+		return nil, err
+	}
+	n := doc
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	for _, s := range segs {
+		switch {
+		case s.Key != "":
+			if n.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("%s: %q is not inside a mapping", path, s.Key)
+			}
+			var next *yaml.Node
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == s.Key {
+					next = n.Content[i+1]
+					break
+				}
+			}
+			if next == nil {
+				return nil, fmt.Errorf("%s: key %q not found", path, s.Key)
+			}
+			n = next
+		case s.Selector == "#":
+			if n.Kind != yaml.SequenceNode || s.Index >= len(n.Content) {
+				return nil, fmt.Errorf("%s: index %d out of range", path, s.Index)
+			}
+			n = n.Content[s.Index]
+		default:
+			if n.Kind != yaml.SequenceNode {
+				return nil, fmt.Errorf("%s: [%s] is not applied to a list", path, s.Selector)
+			}
+			field, want, _ := strings.Cut(s.Selector, "=")
+			var next *yaml.Node
+			for _, item := range n.Content {
+				if item.Kind != yaml.MappingNode {
+					continue
+				}
+				for i := 0; i+1 < len(item.Content); i += 2 {
+					if item.Content[i].Value == field && item.Content[i+1].Value == want {
+						next = item
+					}
+				}
+				if next != nil {
+					break
+				}
+			}
+			if next == nil {
+				return nil, fmt.Errorf("%s: no list item with %s", path, s.Selector)
+			}
+			n = next
+		}
+	}
+	if n.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("%s does not point at a scalar value", path)
+	}
+	return n, nil
+}
+
+// getYAMLValue returns the scalar at path.
+func getYAMLValue(data []byte, path string) (string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", err
+	}
+	n, err := lookupYAMLNode(&doc, path)
+	if err != nil {
+		return "", err
+	}
+	return n.Value, nil
+}
+
+// setYAMLValue replaces the scalar at path, keeping comments and key order.
+// It returns the new document and the previous value.
+func setYAMLValue(data []byte, path, value string) ([]byte, string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, "", err
+	}
+	n, err := lookupYAMLNode(&doc, path)
+	if err != nil {
+		return nil, "", err
+	}
+	old := n.Value
+	if old == value {
+		return data, old, nil
+	}
+	n.Value = value
+	// Keep the value a string so tags like 1.10 are not read back as floats.
+	n.Tag = "!!str"
+	if n.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) == 0 {
+		n.Style = 0
+		if _, err := strconv.ParseFloat(value, 64); err == nil || value == "true" || value == "false" {
+			n.Style = yaml.DoubleQuotedStyle
+		}
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, "", err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), old, nil
+}
@@ -91,6 +91,7 @@ func showComprehensiveHelp() {
 	fmt.Println("  " + helpers.BulletStyle.Render("•") + " security - Security scanning, vulnerability management, and policies")
 	fmt.Println("  " + helpers.BulletStyle.Render("•") + " auth     - Authentication, authorization, and user management")
 	fmt.Println("  " + helpers.BulletStyle.Render("•") + " secrets  - Manage secrets, certificates, and sensitive data")
+	fmt.Println("  " + helpers.BulletStyle.Render("•") + " certs    - Certificate inventory, expiry warnings, and renewal")
 	fmt.Println("  " + helpers.BulletStyle.Render("•") + " policy   - Platform policy management and governance")
 	fmt.Println()
 
@@ -104,6 +105,7 @@ func showComprehensiveHelp() {
 	// Infrastructure & Data
 	fmt.Println(helpers.HeaderStyle.Render("🏗️ Infrastructure & Data"))
 	fmt.Println("  " + helpers.BulletStyle.Render("•") + " network - Network diagnostics, policies, and connectivity testing")
+	fmt.Println("  " + helpers.BulletStyle.Render("•") + " domain  - Domain resolution, DNS providers, and DNS-01 readiness")
 	fmt.Println("  " + helpers.BulletStyle.Render("•") + " db      - Database management, operations, and monitoring")
 	fmt.Println("  " + helpers.BulletStyle.Render("•") + " storage - Storage management, volumes, and data persistence")
 	fmt.Println("  " + helpers.BulletStyle.Render("•") + " service - Service management, load balancing, and API endpoints")
@@ -161,8 +163,8 @@ func showCommandHelp(cmd *cobra.Command, args []string) {
 		fmt.Println()
 		fmt.Println("Available commands:")
 		fmt.Println("  " + helpers.CodeStyle.Render("up, down, get, apps, cluster, config, env, health, logs"))
-		fmt.Println("  " + helpers.CodeStyle.Render("security, auth, secrets, policy, gitops, pipeline, webhook"))
-		fmt.Println("  " + helpers.CodeStyle.Render("network, db, metrics, traces, storage, service, scale"))
+		fmt.Println("  " + helpers.CodeStyle.Render("security, auth, secrets, certs, policy, gitops, pipeline, webhook"))
+		fmt.Println("  " + helpers.CodeStyle.Render("network, domain, db, metrics, traces, storage, service, scale"))
 		fmt.Println("  " + helpers.CodeStyle.Render("backup, restore, help, version"))
 		fmt.Println()
 		fmt.Printf("Use %s to see all available commands.\n", helpers.CodeStyle.Render("adhar help"))
@@ -275,9 +277,11 @@ func showRelatedCommands(commandName string) {
 		"security": {"auth", "secrets", "policy", "compliance"},
 		"auth":     {"security", "secrets", "policy"},
 		"secrets":  {"auth", "security", "get"},
+		"certs":    {"secrets", "security", "health"},
 		"gitops":   {"apps", "cluster", "config"},
 		"pipeline": {"gitops", "apps", "webhook"},
 		"network":  {"health", "cluster", "get"},
+		"domain":   {"network", "certs", "config"},
 		"db":       {"storage", "backup", "restore"},
 		"metrics":  {"health", "logs", "traces"},
 		"traces":   {"metrics", "logs", "health"},
@@ -318,6 +322,7 @@ func getCommandDescription(commandName string) string {
 		"pipeline": "CI/CD pipeline creation, execution, and management",
 		"webhook":  "Webhook management and integration endpoints",
 		"network":  "Network diagnostics, policies, and connectivity testing",
+		"domain":   "Domain resolution, DNS providers, and DNS-01 readiness",
 		"db":       "Database management, operations, and monitoring",
 		"metrics":  "Access platform metrics and performance data",
 		"traces":   "View distributed tracing information",
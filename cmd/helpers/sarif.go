@@ -0,0 +1,104 @@
+package helpers
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// SARIFResult is one finding to report in a SARIF log. Level is one of
+// "error", "warning", "note" or "none".
+type SARIFResult struct {
+	RuleID  string
+	Level   string
+	Message string
+	URI     string
+	Line    int
+	Column  int
+}
+
+// SARIFRule describes a rule referenced by results; rules that only appear
+// in results are listed with their ID alone.
+type SARIFRule struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"-"`
+	HelpURI     string `json:"helpUri,omitempty"`
+}
+
+// WriteSARIF writes results as a SARIF 2.1.0 log with a single run, the
+// format consumed by GitHub code scanning and most CI security dashboards.
+func WriteSARIF(w io.Writer, tool, version, infoURI string, rules []SARIFRule, results []SARIFResult) error {
+	type text struct {
+		Text string `json:"text"`
+	}
+	type region struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+	type physical struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *region `json:"region,omitempty"`
+	}
+	type location struct {
+		PhysicalLocation physical `json:"physicalLocation"`
+	}
+	type result struct {
+		RuleID    string     `json:"ruleId"`
+		Level     string     `json:"level"`
+		Message   text       `json:"message"`
+		Locations []location `json:"locations,omitempty"`
+	}
+	type rule struct {
+		SARIFRule
+		ShortDescription *text `json:"shortDescription,omitempty"`
+	}
+
+	known := map[string]bool{}
+	driverRules := []rule{}
+	for _, r := range rules {
+		known[r.ID] = true
+		dr := rule{SARIFRule: r}
+		if r.Description != "" {
+			dr.ShortDescription = &text{Text: r.Description}
+		}
+		driverRules = append(driverRules, dr)
+	}
+	out := make([]result, 0, len(results))
+	for _, r := range results {
+		if !known[r.RuleID] {
+			known[r.RuleID] = true
+			driverRules = append(driverRules, rule{SARIFRule: SARIFRule{ID: r.RuleID}})
+		}
+		res := result{RuleID: r.RuleID, Level: r.Level, Message: text{Text: r.Message}}
+		if r.URI != "" {
+			var loc location
+			loc.PhysicalLocation.ArtifactLocation.URI = r.URI
+			if r.Line > 0 {
+				loc.PhysicalLocation.Region = &region{StartLine: r.Line, StartColumn: r.Column}
+			}
+			res.Locations = []location{loc}
+		}
+		out = append(out, res)
+	}
+	sort.SliceStable(driverRules, func(i, j int) bool { return driverRules[i].ID < driverRules[j].ID })
+
+	log := map[string]interface{}{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []interface{}{map[string]interface{}{
+			"tool": map[string]interface{}{"driver": map[string]interface{}{
+				"name":           tool,
+				"version":        version,
+				"informationUri": infoURI,
+				"rules":          driverRules,
+			}},
+			"results": out,
+		}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
@@ -0,0 +1,33 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteSARIFEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, "adhar", "dev", "", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules json.RawMessage `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results json.RawMessage `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil || len(log.Runs) != 1 {
+		t.Fatalf("invalid SARIF log: %v\n%s", err, buf.String())
+	}
+	if got := string(log.Runs[0].Tool.Driver.Rules); got != "[]" {
+		t.Errorf("rules = %s, want []", got)
+	}
+	if got := string(log.Runs[0].Results); got != "[]" {
+		t.Errorf("results = %s, want []", got)
+	}
+}
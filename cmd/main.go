@@ -26,10 +26,12 @@ import (
 	"adhar-io/adhar/cmd/apps"
 	"adhar-io/adhar/cmd/auth"
 	"adhar-io/adhar/cmd/backup"
+	"adhar-io/adhar/cmd/certs"
 	"adhar-io/adhar/cmd/cluster"
 	"adhar-io/adhar/cmd/config"
 	controllercmd "adhar-io/adhar/cmd/controller"
 	"adhar-io/adhar/cmd/db"
+	"adhar-io/adhar/cmd/domain"
 	"adhar-io/adhar/cmd/down"
 	"adhar-io/adhar/cmd/env"
 	"adhar-io/adhar/cmd/get"
@@ -130,10 +132,12 @@ func init() {
 	metrics.MetricsCmd.GroupID = GroupObservability
 	traces.TracesCmd.GroupID = GroupObservability
 	network.NetworkCmd.GroupID = GroupObservability
+	domain.DomainCmd.GroupID = GroupObservability
 
 	security.SecurityCmd.GroupID = GroupSecurity
 	auth.AuthCmd.GroupID = GroupSecurity
 	secrets.SecretsCmd.GroupID = GroupSecurity
+	certs.CertsCmd.GroupID = GroupSecurity
 	policy.PolicyCmd.GroupID = GroupSecurity
 
 	backup.BackupCmd.GroupID = GroupUtilities
@@ -160,6 +164,7 @@ func init() {
 		auth.AuthCmd,         // Auth command for authentication and authorization
 		gitops.GitOpsCmd,     // GitOps command for GitOps operations
 		network.NetworkCmd,   // Network command for network diagnostics
+		domain.DomainCmd,     // Domain command for DNS and DNS-01 readiness checks
 		db.DBCmd,             // DB command for database management
 		metrics.MetricsCmd,   // Metrics command for metrics management
 		traces.TracesCmd,     // Traces command for distributed tracing
@@ -167,6 +172,7 @@ func init() {
 		storage.StorageCmd,   // Storage command for storage management
 		webhook.WebhookCmd,   // Webhook command for webhook management
 		secrets.SecretsCmd,   // Secrets command for secrets management
+		certs.CertsCmd,       // Certs command for certificate expiry and renewal
 		service.ServiceCmd,   // Service command for service management
 		scale.ScaleCmd,       // Scale command for resource scaling
 		migrate.MigrateCmd,   // Migrate command for staged platform migrations (split-planes)
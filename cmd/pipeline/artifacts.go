@@ -0,0 +1,308 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/k8s"
+	"adhar-io/adhar/platform/logger"
+	"adhar-io/adhar/platform/utils/objectstore"
+
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+var artifactsCmd = &cobra.Command{
+	Use:   "artifacts <workflow>",
+	Short: "Download pipeline artifacts",
+	Long: `List and download the output artifacts of an Argo Workflow from its S3-compatible
+artifact repository (AWS S3, minio, Ceph RGW...).
+
+The repository is the one recorded on the workflow, falling back to the
+artifact-repositories ConfigMap in the workflow namespace and then the
+workflow controller's default. In-cluster endpoints such as minio:9000 are not
+reachable from a workstation; pass --endpoint with an ingress or port-forward
+address instead.
+
+Examples:
+  adhar pipeline artifacts build-x7k2p --list
+  adhar pipeline artifacts build-x7k2p --dest=./out
+  adhar pipeline artifacts build-x7k2p --step=build --endpoint=http://localhost:9000`,
+	Args: cobra.ExactArgs(1),
+	RunE: runArtifacts,
+}
+
+var (
+	artifactsDest     string
+	artifactsName     string
+	artifactsStep     string
+	artifactsEndpoint string
+	artifactsList     bool
+)
+
+const (
+	// workflowControllerNamespace is where the platform installs Argo Workflows.
+	workflowControllerNamespace = "adhar-system"
+	// defaultArtifactRepositoryAnnotation names the default key of an
+	// artifact-repositories ConfigMap.
+	defaultArtifactRepositoryAnnotation = "workflows.argoproj.io/default-artifact-repository"
+)
+
+func init() {
+	artifactsCmd.Flags().StringVar(&artifactsDest, "dest", ".", "Directory to download artifacts into")
+	artifactsCmd.Flags().StringVar(&artifactsName, "artifact", "", "Only download the artifact with this name")
+	artifactsCmd.Flags().StringVar(&artifactsStep, "step", "", "Only download artifacts of steps whose name contains this value")
+	artifactsCmd.Flags().StringVar(&artifactsEndpoint, "endpoint", "", "Override the S3 endpoint, e.g. http://localhost:9000")
+	artifactsCmd.Flags().BoolVar(&artifactsList, "list", false, "List artifacts without downloading them")
+}
+
+// workflowArtifact is an output artifact of one workflow step.
+type workflowArtifact struct {
+	Step string
+	Name string
+	// S3 holds the artifact's own s3 location; fields left empty come from
+	// the artifact repository.
+	S3 map[string]interface{}
+}
+
+func runArtifacts(cmd *cobra.Command, args []string) error {
+	ns := defaultNamespace()
+	client, err := getDynamicClient()
+	if err != nil {
+		return err
+	}
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return fmt.Errorf("create kubernetes client: %w", err)
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	wf, err := client.Resource(workflowsGVR).Namespace(ns).Get(ctx, args[0], metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return fmt.Errorf("workflow %q not found in namespace %q", args[0], ns)
+		}
+		return fmt.Errorf("get workflow: %w", err)
+	}
+
+	var artifacts []workflowArtifact
+	for _, a := range workflowArtifacts(wf) {
+		if (artifactsName == "" || a.Name == artifactsName) && (artifactsStep == "" || strings.Contains(a.Step, artifactsStep)) {
+			artifacts = append(artifacts, a)
+		}
+	}
+	if len(artifacts) == 0 {
+		fmt.Println(helpers.CreateWarning(fmt.Sprintf("Workflow %s has no matching S3 output artifacts", wf.GetName())))
+		return nil
+	}
+
+	if artifactsList {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "STEP\tARTIFACT\tKEY")
+		for _, a := range artifacts {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", a.Step, a.Name, valueOrDash(stringField(a.S3, "key")))
+		}
+		return w.Flush()
+	}
+
+	repo, err := artifactRepository(ctx, cs, wf)
+	if err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("📦 Downloading %d artifact(s) of %s to %s", len(artifacts), wf.GetName(), artifactsDest))
+	for _, a := range artifacts {
+		store, err := newArtifactStore(ctx, cs, ns, mergeS3(repo, a.S3), artifactsEndpoint)
+		if err != nil {
+			return err
+		}
+		key := stringField(a.S3, "key")
+		dest := filepath.Join(artifactsDest, strings.ReplaceAll(a.Step, "/", "_"), path.Base(key))
+		n, err := downloadArtifact(ctx, store, key, dest)
+		if err != nil {
+			return fmt.Errorf("artifact %s of step %s: %w", a.Name, a.Step, err)
+		}
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("   %s/%s → %s (%d bytes)", a.Step, a.Name, dest, n)))
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("Downloaded %d artifact(s)", len(artifacts))))
+	return nil
+}
+
+// workflowArtifacts returns the S3 output artifacts of every step in DAG
+// order. Artifacts stored elsewhere (git, http, raw) are skipped.
+func workflowArtifacts(wf *unstructured.Unstructured) []workflowArtifact {
+	var out []workflowArtifact
+	for _, n := range stepNodes(wf) {
+		list, _, _ := unstructured.NestedSlice(n.Outputs, "artifacts")
+		for _, item := range list {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			s3, _, _ := unstructured.NestedMap(m, "s3")
+			if stringField(s3, "key") == "" {
+				continue
+			}
+			out = append(out, workflowArtifact{Step: valueOrDash(n.DisplayName), Name: stringField(m, "name"), S3: s3})
+		}
+	}
+	return out
+}
+
+// artifactRepository returns the s3 section of the repository the workflow
+// stored its artifacts in.
+func artifactRepository(ctx context.Context, cs kubernetes.Interface, wf *unstructured.Unstructured) (map[string]interface{}, error) {
+	// Argo records the resolved repository on the workflow.
+	if s3, _, _ := unstructured.NestedMap(wf.Object, "status", "artifactRepositoryRef", "artifactRepository", "s3"); s3 != nil {
+		return s3, nil
+	}
+
+	ns := wf.GetNamespace()
+	cmName := valueOr(stringField(wf.Object, "status", "artifactRepositoryRef", "configMap"), "artifact-repositories")
+	if refNS := stringField(wf.Object, "status", "artifactRepositoryRef", "namespace"); refNS != "" {
+		ns = refNS
+	}
+	key := stringField(wf.Object, "status", "artifactRepositoryRef", "key")
+	if cm, err := cs.CoreV1().ConfigMaps(ns).Get(ctx, cmName, metav1.GetOptions{}); err == nil {
+		if key == "" {
+			key = valueOr(cm.Annotations[defaultArtifactRepositoryAnnotation], "default-v1")
+		}
+		if data, ok := cm.Data[key]; ok {
+			return parseArtifactRepository(data, fmt.Sprintf("%s/%s key %s", ns, cmName, key))
+		}
+	} else if !k8serrors.IsNotFound(err) {
+		return nil, fmt.Errorf("read artifact repositories %s/%s: %w", ns, cmName, err)
+	}
+
+	cm, err := cs.CoreV1().ConfigMaps(workflowControllerNamespace).Get(ctx, "workflow-controller-configmap", metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("no artifact repository configured for %s: %w", wf.GetName(), err)
+	}
+	data, ok := cm.Data["artifactRepository"]
+	if !ok {
+		return nil, fmt.Errorf("no artifact repository configured for %s", wf.GetName())
+	}
+	return parseArtifactRepository(data, workflowControllerNamespace+"/workflow-controller-configmap")
+}
+
+// parseArtifactRepository reads the s3 section of an Argo artifact
+// repository document.
+func parseArtifactRepository(data, source string) (map[string]interface{}, error) {
+	var repo map[string]interface{}
+	if err := yaml.Unmarshal([]byte(data), &repo); err != nil {
+		return nil, fmt.Errorf("parse artifact repository %s: %w", source, err)
+	}
+	s3, _, _ := unstructured.NestedMap(repo, "s3")
+	if s3 == nil {
+		return nil, fmt.Errorf("artifact repository %s is not S3-compatible; only s3 repositories are supported", source)
+	}
+	return s3, nil
+}
+
+// mergeS3 overlays an artifact's own s3 fields on the repository defaults.
+func mergeS3(repo, artifact map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(repo)+len(artifact))
+	for k, v := range repo {
+		out[k] = v
+	}
+	for k, v := range artifact {
+		out[k] = v
+	}
+	return out
+}
+
+// newArtifactStore builds a store from an Argo s3 artifact location, reading
+// the access keys from Secrets in ns. endpoint, when set, replaces the
+// configured endpoint.
+func newArtifactStore(ctx context.Context, cs kubernetes.Interface, ns string, s3 map[string]interface{}, endpoint string) (*objectstore.Store, error) {
+	store := &objectstore.Store{
+		Bucket: stringField(s3, "bucket"),
+		Region: valueOr(stringField(s3, "region"), "us-east-1"),
+		Client: &http.Client{Timeout: 10 * time.Minute},
+	}
+	if store.Bucket == "" {
+		return nil, fmt.Errorf("artifact repository has no bucket")
+	}
+	insecure, _, _ := unstructured.NestedBool(s3, "insecure")
+	store.Endpoint = s3Endpoint(valueOr(endpoint, stringField(s3, "endpoint")), insecure)
+	// Argo addresses everything but AWS itself in path style.
+	store.PathStyle = !strings.Contains(store.Endpoint, "amazonaws.com")
+
+	for _, ref := range []struct {
+		field string
+		value *string
+	}{{"accessKeySecret", &store.Creds.AccessKeyID}, {"secretKeySecret", &store.Creds.SecretAccessKey}} {
+		name, key := stringField(s3, ref.field, "name"), stringField(s3, ref.field, "key")
+		if name == "" {
+			continue
+		}
+		secret, err := cs.CoreV1().Secrets(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("read artifact repository credentials %s/%s: %w", ns, name, err)
+		}
+		*ref.value = string(secret.Data[key])
+	}
+	return store, nil
+}
+
+// s3Endpoint normalises an Argo endpoint (host[:port], no scheme) to a URL.
+func s3Endpoint(endpoint string, insecure bool) string {
+	endpoint = strings.TrimRight(endpoint, "/")
+	switch {
+	case endpoint == "":
+		return "https://s3.amazonaws.com"
+	case strings.Contains(endpoint, "://"):
+		return endpoint
+	case insecure:
+		return "http://" + endpoint
+	}
+	return "https://" + endpoint
+}
+
+// downloadArtifact fetches key from store into the file dest and returns its
+// size.
+func downloadArtifact(ctx context.Context, store *objectstore.Store, key, dest string) (int64, error) {
+	body, _, err := store.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return 0, err
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(f, body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return n, fmt.Errorf("write %s: %w", dest, err)
+	}
+	return n, nil
+}
+
+func valueOr(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"adhar-io/adhar/platform/utils/objectstore"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestWorkflowArtifacts(t *testing.T) {
+	wf := stepsWorkflow()
+	nodes, _, _ := unstructured.NestedMap(wf.Object, "status", "nodes")
+	nodes["wf-build"].(map[string]interface{})["outputs"] = map[string]interface{}{
+		"artifacts": []interface{}{
+			map[string]interface{}{"name": "binary", "s3": map[string]interface{}{"key": "wf/wf-build/binary.tgz"}},
+			map[string]interface{}{"name": "report", "git": map[string]interface{}{"repo": "x"}},
+		},
+	}
+	_ = unstructured.SetNestedMap(wf.Object, nodes, "status", "nodes")
+
+	got := workflowArtifacts(wf)
+	if len(got) != 1 || got[0].Step != "build" || got[0].Name != "binary" {
+		t.Fatalf("artifacts = %+v", got)
+	}
+
+	repo, err := parseArtifactRepository("s3:\n  bucket: artifacts\n  endpoint: minio:9000\n  insecure: true\n", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s3 := mergeS3(repo, got[0].S3)
+	if stringField(s3, "bucket") != "artifacts" || stringField(s3, "key") != "wf/wf-build/binary.tgz" {
+		t.Errorf("merged = %v", s3)
+	}
+	if _, err := parseArtifactRepository("gcs:\n  bucket: x\n", "test"); err == nil {
+		t.Errorf("non-S3 repositories should be rejected")
+	}
+	if s3Endpoint("minio:9000", true) != "http://minio:9000" || s3Endpoint("s3.example.com", false) != "https://s3.example.com" {
+		t.Errorf("s3Endpoint did not add the scheme")
+	}
+}
+
+func TestArtifactStoreDownload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/artifacts/wf/wf-build/binary.tgz" || r.Header.Get("Authorization") == "" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("payload"))
+	}))
+	defer srv.Close()
+
+	store := &objectstore.Store{Endpoint: srv.URL, Bucket: "artifacts", Region: "us-east-1", PathStyle: true, Client: srv.Client()}
+	store.Creds.AccessKeyID, store.Creds.SecretAccessKey = "minio", "minio123"
+	dest := filepath.Join(t.TempDir(), "build", "binary.tgz")
+	n, err := downloadArtifact(context.Background(), store, "wf/wf-build/binary.tgz", dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dest); n != 7 || string(data) != "payload" {
+		t.Errorf("downloaded %d bytes: %q", n, data)
+	}
+	if _, err := downloadArtifact(context.Background(), store, "missing", dest); err == nil {
+		t.Errorf("missing objects should fail")
+	}
+}
@@ -1,42 +1,396 @@
 package pipeline
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path"
+	"strings"
 
+	"adhar-io/adhar/api/v1alpha1"
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/k8s"
 	"adhar-io/adhar/platform/logger"
+	"adhar-io/adhar/platform/utils"
+	"adhar-io/adhar/platform/utils/gitopsrepo"
 
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
 )
 
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create new pipeline",
-	Long: `Create a new CI/CD pipeline.
-	
+	Long: `Create a CI pipeline (an Argo WorkflowTemplate) from a preset.
+
+Presets:
+  go, node, python   clone, run the language's tests, build the Dockerfile with kaniko
+  kaniko             clone and build the Dockerfile with kaniko
+  buildpacks         clone and build an image from source with Cloud Native Buildpacks
+
+The pipeline clones from the platform Gitea (--repo owner/name) and, when
+Harbor is installed, pushes to harbor.adhar-system.svc.cluster.local/library/<name>
+tagged with the built revision. The pipeline gets its own credentials, stored
+as Secrets in the pipeline namespace: a Gitea token that can only read the
+source repository and a Harbor robot account that can only push to the library
+project. Re-running create rotates them.
+
+When argo-events is installed, an EventSource and Sensor are added that submit
+the pipeline on every push to --branch, and a matching webhook is registered on
+the Gitea repository.
+
+With --app (an ArgoCD Application) or --gitops-repo the manifests, without
+Secrets, are also committed to <path>/pipelines/<name>.yaml in that GitOps
+repository.
+
 Examples:
-  adhar pipeline create --name=deploy --type=deploy
-  adhar pipeline create --name=build --type=build`,
+  adhar pipeline create --name=checkout --preset=go
+  adhar pipeline create --name=web --preset=node --repo=team/web --app=web
+  adhar pipeline create --name=api --preset=buildpacks --image=registry.example.com/api
+  adhar pipeline create --name=api --preset=python --dry-run`,
 	RunE: runCreate,
 }
 
+var (
+	createPreset         string
+	createRepo           string
+	createBranch         string
+	createImage          string
+	createRegistrySecret string
+	createApp            string
+	createGitopsRepo     string
+	createGitopsPath     string
+	createNoTrigger      bool
+	createDryRun         bool
+)
+
+func init() {
+	createCmd.Flags().StringVarP(&createPreset, "preset", "p", "", "Pipeline preset ("+strings.Join(presetNames(), ", ")+")")
+	createCmd.Flags().StringVarP(&createRepo, "repo", "r", "", "Source repository: owner/name on the platform Gitea or a clone URL (default <gitea admin>/<name>)")
+	createCmd.Flags().StringVarP(&createBranch, "branch", "b", "main", "Branch to build and watch for pushes")
+	createCmd.Flags().StringVar(&createImage, "image", "", "Image repository to push to, without tag (default Harbor library/<name> when Harbor is installed)")
+	createCmd.Flags().StringVar(&createRegistrySecret, "registry-secret", "", "Existing dockerconfigjson Secret used to push --image")
+	createCmd.Flags().StringVarP(&createApp, "app", "a", "", "ArgoCD Application whose GitOps repository receives the manifests")
+	createCmd.Flags().StringVar(&createGitopsRepo, "gitops-repo", "", "GitOps repository URL to commit the manifests to (overrides --app)")
+	createCmd.Flags().StringVar(&createGitopsPath, "gitops-path", "", "Directory in the GitOps repository (default the Application's path)")
+	createCmd.Flags().BoolVar(&createNoTrigger, "no-trigger", false, "Do not create the argo-events push trigger")
+	createCmd.Flags().BoolVar(&createDryRun, "dry-run", false, "Print the manifests without applying or committing them")
+}
+
+// Resources applied by create, keyed by kind.
+var createResources = map[string]schema.GroupVersionResource{
+	"WorkflowTemplate": workflowTemplatesGVR,
+	"EventSource":      {Group: "argoproj.io", Version: "v1alpha1", Resource: "eventsources"},
+	"Sensor":           {Group: "argoproj.io", Version: "v1alpha1", Resource: "sensors"},
+	"ServiceAccount":   {Version: "v1", Resource: "serviceaccounts"},
+	"Secret":           {Version: "v1", Resource: "secrets"},
+	"Role":             {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"},
+	"RoleBinding":      {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"},
+}
+
+var (
+	eventBusGVR    = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "eventbus"}
+	applicationGVR = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+)
+
+const (
+	// harborNamespace and harborService are where the Harbor package
+	// installs Harbor.
+	harborNamespace = "adhar-system"
+	harborService   = "harbor"
+	// harborServiceHost is Harbor as seen from pods; it serves plain HTTP.
+	harborServiceHost = harborService + "." + harborNamespace + ".svc.cluster.local"
+	harborAdminSecret = "harbor-core"
+	harborCoreConfig  = "harbor-core"
+	harborProject     = "library"
+	harborAdminUser   = "admin"
+	harborPasswordKey = "HARBOR_ADMIN_PASSWORD"
+	fieldManager      = "adhar-cli"
+)
+
+// platformPackages records which optional packages the pipeline can use.
+type platformPackages struct {
+	GiteaAuth      *githttp.BasicAuth
+	HarborPassword string
+	Events         bool
+	EventBus       bool
+}
+
 func runCreate(cmd *cobra.Command, args []string) error {
 	if pipelineName == "" {
 		return fmt.Errorf("--name is required for pipeline creation")
 	}
+	preset, ok := pipelinePresets[createPreset]
+	if !ok {
+		return fmt.Errorf("--preset must be one of: %s", strings.Join(presetNames(), ", "))
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ns := defaultNamespace()
+	if !createDryRun {
+		logger.Info(fmt.Sprintf("🔧 Creating pipeline %s from the %s preset in namespace %s", pipelineName, preset.Name, ns))
+	}
+
+	client, err := getDynamicClient()
+	var pkgs platformPackages
+	if err == nil {
+		pkgs, err = detectPackages(ctx, client, ns)
+	}
+	if err != nil {
+		if !createDryRun {
+			return err
+		}
+		// stderr keeps the dry-run output a clean YAML stream.
+		fmt.Fprintln(os.Stderr, helpers.CreateWarning("Cluster not reachable, rendering without platform packages: "+err.Error()))
+	}
+
+	spec := pipelineSpec{Name: pipelineName, Namespace: ns, Preset: preset, Branch: createBranch}
+	spec.RepoURL = sourceRepoURL(createRepo, pipelineName)
+	if gitopsrepo.IsGiteaURL(spec.RepoURL) && pkgs.GiteaAuth != nil {
+		spec.GitSecret = pipelineName + "-git"
+	}
+	spec.Image, spec.RegistrySecret = createImage, createRegistrySecret
+	harborPush := spec.Image == "" && pkgs.HarborPassword != ""
+	if harborPush {
+		spec.Image = harborServiceHost + "/" + harborProject + "/" + pipelineName
+		spec.RegistrySecret = pipelineName + "-registry"
+	}
+	spec.InsecureRegistry = strings.HasSuffix(registryHost(spec.Image), ".svc.cluster.local")
+	spec.Events = pkgs.Events && !createNoTrigger
+
+	objs, err := buildManifests(spec)
+	if err != nil {
+		return err
+	}
+	manifests, err := renderManifests(objs)
+	if err != nil {
+		return err
+	}
+	if createDryRun {
+		fmt.Print(string(manifests))
+		return nil
+	}
+
+	var gitAuth *githttp.BasicAuth
+	if spec.GitSecret != "" {
+		if gitAuth, err = issueGiteaToken(ctx, pkgs.GiteaAuth, spec); err != nil {
+			return err
+		}
+	}
+	var robot *harborRobot
+	if harborPush {
+		if robot, err = issueHarborRobot(ctx, pkgs.HarborPassword, harborProject, spec); err != nil {
+			return err
+		}
+	}
+	secrets, err := pipelineSecrets(spec, gitAuth, robot)
+	if err != nil {
+		return err
+	}
+	for _, obj := range append(secrets, objs...) {
+		if err := applyObject(ctx, client, obj); err != nil {
+			return err
+		}
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("   applied %s/%s", obj.GetKind(), obj.GetName())))
+	}
 
-	if pipelineType == "" {
-		return fmt.Errorf("--type is required for pipeline creation")
+	if spec.Events {
+		if !pkgs.EventBus {
+			fmt.Println(helpers.CreateWarning(fmt.Sprintf("No EventBus in namespace %s; the push trigger stays idle until one named \"default\" exists", ns)))
+		}
+		if gitopsrepo.IsGiteaURL(spec.RepoURL) && pkgs.GiteaAuth != nil {
+			created, err := ensurePushWebhook(ctx, pkgs.GiteaAuth, spec.RepoURL, spec.Branch, spec.webhookURL())
+			switch {
+			case err != nil:
+				fmt.Println(helpers.CreateWarning("Could not register the Gitea webhook: " + err.Error()))
+			case created:
+				fmt.Println(helpers.CreateMuted("   registered push webhook " + spec.webhookURL()))
+			}
+		} else {
+			fmt.Println(helpers.CreateMuted("   point the repository's push webhook at " + spec.webhookURL()))
+		}
 	}
 
-	logger.Info(fmt.Sprintf("🔧 Creating pipeline: %s (type: %s)", pipelineName, pipelineType))
+	if err := commitManifests(ctx, client, pkgs.GiteaAuth, manifests); err != nil {
+		return err
+	}
+
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("Pipeline %s created; start it with `adhar pipeline run --name=%s --namespace=%s`", pipelineName, pipelineName, ns)))
+	return nil
+}
+
+// sourceRepoURL turns --repo into a clone URL. owner/name (or an empty value,
+// meaning the Gitea admin's repository named after the pipeline) refers to the
+// platform Gitea.
+func sourceRepoURL(repo, name string) string {
+	if strings.Contains(repo, "://") || strings.HasPrefix(repo, "git@") {
+		return repo
+	}
+	if repo == "" {
+		repo = v1alpha1.GiteaAdminUserName + "/" + name
+	}
+	return gitopsrepo.ServiceURL + "/" + strings.TrimSuffix(repo, ".git") + ".git"
+}
+
+// detectPackages checks which of Gitea, Harbor and argo-events are installed.
+func detectPackages(ctx context.Context, client dynamic.Interface, ns string) (platformPackages, error) {
+	var pkgs platformPackages
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return pkgs, fmt.Errorf("could not connect to the cluster (is it running? try `adhar up`): %w", err)
+	}
+
+	if auth, err := gitopsrepo.AdminAuth(ctx); err == nil {
+		pkgs.GiteaAuth = auth
+	} else if !k8serrors.IsNotFound(err) {
+		return pkgs, err
+	}
+
+	if _, err := cs.CoreV1().Services(harborNamespace).Get(ctx, harborService, metav1.GetOptions{}); err == nil {
+		secret, err := cs.CoreV1().Secrets(harborNamespace).Get(ctx, harborAdminSecret, metav1.GetOptions{})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, helpers.CreateWarning(fmt.Sprintf("Harbor is installed but %s/%s is not readable; images will not be pushed", harborNamespace, harborAdminSecret)))
+		} else {
+			pkgs.HarborPassword = string(secret.Data[harborPasswordKey])
+		}
+	} else if !k8serrors.IsNotFound(err) {
+		return pkgs, fmt.Errorf("check for Harbor: %w", err)
+	}
+
+	// The List fails with NotFound when the argo-events CRDs are absent.
+	if _, err := client.Resource(createResources["Sensor"]).Namespace(ns).List(ctx, metav1.ListOptions{Limit: 1}); err == nil {
+		pkgs.Events = true
+		buses, err := client.Resource(eventBusGVR).Namespace(ns).List(ctx, metav1.ListOptions{Limit: 1})
+		pkgs.EventBus = err == nil && len(buses.Items) > 0
+	} else if !k8serrors.IsNotFound(err) {
+		return pkgs, fmt.Errorf("check for argo-events: %w", err)
+	}
+	return pkgs, nil
+}
+
+// pipelineSecrets returns the clone credentials and, with a robot account,
+// the Harbor push credentials for the pipeline namespace. They are applied to
+// the cluster but never committed.
+func pipelineSecrets(spec pipelineSpec, gitAuth *githttp.BasicAuth, robot *harborRobot) ([]*unstructured.Unstructured, error) {
+	var out []*unstructured.Unstructured
+	if gitAuth != nil {
+		s := newObject("v1", "Secret", spec, spec.GitSecret, map[string]interface{}{
+			"type": "kubernetes.io/basic-auth",
+			"stringData": map[string]interface{}{
+				"username": gitAuth.Username,
+				"password": gitAuth.Password,
+			},
+		})
+		out = append(out, s)
+	}
+	if robot != nil {
+		auth := base64.StdEncoding.EncodeToString([]byte(robot.Name + ":" + robot.Secret))
+		config, err := json.Marshal(map[string]interface{}{
+			"auths": map[string]interface{}{
+				harborServiceHost: map[string]string{"username": robot.Name, "password": robot.Secret, "auth": auth},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, newObject("v1", "Secret", spec, spec.RegistrySecret, map[string]interface{}{
+			"type":       "kubernetes.io/dockerconfigjson",
+			"stringData": map[string]interface{}{".dockerconfigjson": string(config)},
+		}))
+	}
+	return out, nil
+}
+
+// applyObject server-side applies obj so re-running create updates the
+// pipeline in place.
+func applyObject(ctx context.Context, client dynamic.Interface, obj *unstructured.Unstructured) error {
+	gvr, ok := createResources[obj.GetKind()]
+	if !ok {
+		return fmt.Errorf("no resource mapping for kind %s", obj.GetKind())
+	}
+	_, err := client.Resource(gvr).Namespace(obj.GetNamespace()).Apply(ctx, obj.GetName(), obj,
+		metav1.ApplyOptions{FieldManager: fieldManager, Force: true})
+	if err != nil {
+		return fmt.Errorf("apply %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return nil
+}
+
+// renderManifests joins objs into a multi-document YAML stream.
+func renderManifests(objs []*unstructured.Unstructured) ([]byte, error) {
+	var b strings.Builder
+	for i, obj := range objs {
+		data, err := yaml.Marshal(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("render %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		if i > 0 {
+			b.WriteString("---\n")
+		}
+		b.Write(data)
+	}
+	return []byte(b.String()), nil
+}
 
-	// TODO: Implement pipeline creation
-	// This should:
-	// - Validate pipeline type
-	// - Create pipeline definition
-	// - Configure stages
-	// - Set up triggers
+// commitManifests writes the manifests to the GitOps repository named by
+// --gitops-repo or --app. Without either it does nothing.
+func commitManifests(ctx context.Context, client dynamic.Interface, auth *githttp.BasicAuth, manifests []byte) error {
+	repoURL, dir, branch := createGitopsRepo, createGitopsPath, ""
+	if repoURL == "" && createApp == "" {
+		return nil
+	}
+	if repoURL == "" {
+		app, err := client.Resource(applicationGVR).Namespace(utils.ArgocdNamespace).Get(ctx, createApp, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("get application %s: %w", createApp, err)
+		}
+		source, _, _ := unstructured.NestedMap(app.Object, "spec", "source")
+		if source == nil {
+			if sources, _, _ := unstructured.NestedSlice(app.Object, "spec", "sources"); len(sources) > 0 {
+				source, _ = sources[0].(map[string]interface{})
+			}
+		}
+		repoURL = stringField(source, "repoURL")
+		branch = stringField(source, "targetRevision")
+		if dir == "" {
+			dir = stringField(source, "path")
+		}
+		if repoURL == "" {
+			return fmt.Errorf("application %s has no source repository", createApp)
+		}
+	}
+	if auth == nil {
+		return fmt.Errorf("committing to %s needs the platform Gitea credentials, which are not available", repoURL)
+	}
 
-	logger.Info("✅ Pipeline created successfully")
+	repo, err := gitopsrepo.Open(ctx, auth, repoURL, branch)
+	if err != nil {
+		return err
+	}
+	file := path.Join(strings.Trim(dir, "/"), "pipelines", pipelineName+".yaml")
+	changed, err := repo.WriteFile(file, manifests)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("   %s is already up to date in %s", file, repoURL)))
+		return nil
+	}
+	sha, err := repo.CommitAndPush(ctx, fmt.Sprintf("Add %s pipeline (%s preset)", pipelineName, createPreset), auth.Username)
+	if err != nil {
+		return err
+	}
+	fmt.Println(helpers.CreateMuted(fmt.Sprintf("   committed %s to %s@%s (%s)", file, repoURL, repo.Branch, sha[:7])))
 	return nil
 }
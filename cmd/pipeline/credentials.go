@@ -0,0 +1,218 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"adhar-io/adhar/platform/k8s"
+	"adhar-io/adhar/platform/utils"
+	"adhar-io/adhar/platform/utils/gitopsrepo"
+
+	"code.gitea.io/sdk/gitea"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// giteaReadRepositoryScope limits a Gitea token to reading repositories. The
+// SDK only defines the pre-1.19 scope names.
+const giteaReadRepositoryScope gitea.AccessTokenScope = "read:repository"
+
+// maxGiteaUsername is Gitea's limit on user name length.
+const maxGiteaUsername = 40
+
+var giteaUsernameInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// giteaPipelineUser names the Gitea account pipelines building owner/repo
+// clone as. Long names are shortened with a hash so they stay unique.
+func giteaPipelineUser(owner, repo string) string {
+	name := "ci-" + strings.Trim(giteaUsernameInvalid.ReplaceAllString(strings.ToLower(owner+"-"+repo), "-"), "-")
+	if len(name) <= maxGiteaUsername {
+		return name
+	}
+	sum := sha256.Sum256([]byte(owner + "/" + repo))
+	return strings.TrimRight(name[:maxGiteaUsername-9], "-") + "-" + hex.EncodeToString(sum[:])[:8]
+}
+
+// pipelineCredentialName names the Gitea token and Harbor robot account
+// issued to one pipeline.
+func pipelineCredentialName(spec pipelineSpec) string {
+	return "pipeline-" + spec.Namespace + "-" + spec.Name
+}
+
+// issueGiteaToken returns clone credentials for the pipeline that can only
+// read repoURL: a per-repository Gitea user with read access to it and a
+// token limited to the read:repository scope. Re-running replaces the
+// pipeline's token.
+func issueGiteaToken(ctx context.Context, admin *githttp.BasicAuth, spec pipelineSpec) (*githttp.BasicAuth, error) {
+	owner, repo, err := gitopsrepo.OwnerRepo(spec.RepoURL)
+	if err != nil {
+		return nil, err
+	}
+	client, err := gitopsrepo.Client(ctx, admin)
+	if err != nil {
+		return nil, err
+	}
+	user := giteaPipelineUser(owner, repo)
+	password, err := utils.GeneratePassword()
+	if err != nil {
+		return nil, err
+	}
+	mustChange := false
+	_, resp, err := client.GetUserInfo(user)
+	switch {
+	case err == nil:
+		// The password is only ever used here to issue the token, so
+		// resetting it does not break earlier pipelines.
+		if _, err := client.AdminEditUser(user, gitea.EditUserOption{LoginName: user, Password: password, MustChangePassword: &mustChange}); err != nil {
+			return nil, fmt.Errorf("failed to update Gitea user %s: %w", user, err)
+		}
+	case resp != nil && resp.StatusCode == http.StatusNotFound:
+		visibility := gitea.VisibleTypePrivate
+		_, _, err := client.AdminCreateUser(gitea.CreateUserOption{
+			Username:           user,
+			LoginName:          user,
+			FullName:           fmt.Sprintf("CI for %s/%s", owner, repo),
+			Email:              user + "@pipelines.adhar.local",
+			Password:           password,
+			MustChangePassword: &mustChange,
+			Visibility:         &visibility,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Gitea user %s: %w", user, err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to look up Gitea user %s: %w", user, err)
+	}
+
+	read := gitea.AccessModeRead
+	if _, err := client.AddCollaborator(owner, repo, user, gitea.AddCollaboratorOption{Permission: &read}); err != nil {
+		return nil, fmt.Errorf("failed to grant %s read access to %s/%s: %w", user, owner, repo, err)
+	}
+
+	bot, err := gitopsrepo.Client(ctx, &githttp.BasicAuth{Username: user, Password: password})
+	if err != nil {
+		return nil, err
+	}
+	name := pipelineCredentialName(spec)
+	tokens, _, err := bot.ListAccessTokens(gitea.ListAccessTokensOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens of %s: %w", user, err)
+	}
+	for _, t := range tokens {
+		if t.Name == name {
+			if _, err := bot.DeleteAccessToken(t.ID); err != nil {
+				return nil, fmt.Errorf("failed to replace token %s of %s: %w", name, user, err)
+			}
+		}
+	}
+	token, _, err := bot.CreateAccessToken(gitea.CreateAccessTokenOption{Name: name, Scopes: []gitea.AccessTokenScope{giteaReadRepositoryScope}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token %s for %s: %w", name, user, err)
+	}
+	return &githttp.BasicAuth{Username: user, Password: token.Token}, nil
+}
+
+// harborRobot is a Harbor robot account as returned by the robots API.
+type harborRobot struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+}
+
+// harborExternalURL reads Harbor's external endpoint from its core config.
+func harborExternalURL(ctx context.Context) (string, error) {
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return "", err
+	}
+	cm, err := cs.CoreV1().ConfigMaps(harborNamespace).Get(ctx, harborCoreConfig, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s/%s: %w", harborNamespace, harborCoreConfig, err)
+	}
+	endpoint := strings.TrimRight(cm.Data["EXT_ENDPOINT"], "/")
+	if endpoint == "" {
+		return "", fmt.Errorf("%s/%s has no EXT_ENDPOINT", harborNamespace, harborCoreConfig)
+	}
+	return endpoint, nil
+}
+
+// issueHarborRobot creates a robot account that can push and pull only in
+// project and returns its credentials. An earlier robot for the same
+// pipeline is deleted first, so re-running rotates the secret.
+func issueHarborRobot(ctx context.Context, adminPassword, project string, spec pipelineSpec) (*harborRobot, error) {
+	base, err := harborExternalURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := pipelineCredentialName(spec)
+	call := func(method, path string, body, out interface{}) (int, error) {
+		var r io.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			if err != nil {
+				return 0, err
+			}
+			r = bytes.NewReader(data)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, base+"/api/v2.0"+path, r)
+		if err != nil {
+			return 0, err
+		}
+		req.SetBasicAuth(harborAdminUser, adminPassword)
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := utils.GetHttpClient().Do(req)
+		if err != nil {
+			return 0, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			return resp.StatusCode, fmt.Errorf("harbor %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+		}
+		if out != nil {
+			return resp.StatusCode, json.NewDecoder(resp.Body).Decode(out)
+		}
+		return resp.StatusCode, nil
+	}
+
+	var existing []harborRobot
+	if _, err := call(http.MethodGet, "/robots?q="+url.QueryEscape("name=~"+name), nil, &existing); err != nil {
+		return nil, err
+	}
+	for _, r := range existing {
+		if strings.HasSuffix(r.Name, project+"+"+name) {
+			if _, err := call(http.MethodDelete, fmt.Sprintf("/robots/%d", r.ID), nil, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var robot harborRobot
+	_, err = call(http.MethodPost, "/robots", map[string]interface{}{
+		"name":        name,
+		"description": fmt.Sprintf("Pushes images for pipeline %s/%s", spec.Namespace, spec.Name),
+		"level":       "project",
+		"duration":    -1,
+		"permissions": []map[string]interface{}{{
+			"kind":      "project",
+			"namespace": project,
+			"access": []map[string]string{
+				{"resource": "repository", "action": "push"},
+				{"resource": "repository", "action": "pull"},
+			},
+		}},
+	}, &robot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a Harbor robot account for %s: %w", project, err)
+	}
+	return &robot, nil
+}
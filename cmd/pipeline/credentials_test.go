@@ -0,0 +1,54 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+func TestGiteaPipelineUser(t *testing.T) {
+	if got := giteaPipelineUser("Team", "web.app"); got != "ci-team-web-app" {
+		t.Errorf("giteaPipelineUser = %q, want ci-team-web-app", got)
+	}
+	long := giteaPipelineUser("platform-engineering", "customer-facing-checkout-service")
+	if len(long) > maxGiteaUsername || !strings.HasPrefix(long, "ci-platform-engineering-") {
+		t.Errorf("giteaPipelineUser(long) = %q (%d chars)", long, len(long))
+	}
+	if other := giteaPipelineUser("platform-engineering", "customer-facing-checkout-worker"); other == long {
+		t.Errorf("distinct repositories share the Gitea user %q", long)
+	}
+}
+
+func TestPipelineSecretsUseScopedCredentials(t *testing.T) {
+	spec := pipelineSpec{Name: "web", Namespace: "argo", GitSecret: "web-git", RegistrySecret: "web-registry"}
+	git := &githttp.BasicAuth{Username: "ci-team-web", Password: "token"}
+	robot := &harborRobot{Name: "robot$library+pipeline-argo-web", Secret: "s3cret"}
+
+	secrets, err := pipelineSecrets(spec, git, robot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secrets) != 2 {
+		t.Fatalf("got %d secrets, want 2", len(secrets))
+	}
+	data := secrets[0].Object["stringData"].(map[string]interface{})
+	if data["username"] != "ci-team-web" || data["password"] != "token" {
+		t.Errorf("git secret = %v, want the pipeline token", data)
+	}
+	var config struct {
+		Auths map[string]struct{ Username, Password string }
+	}
+	raw := secrets[1].Object["stringData"].(map[string]interface{})[".dockerconfigjson"].(string)
+	if err := json.Unmarshal([]byte(raw), &config); err != nil {
+		t.Fatal(err)
+	}
+	if a := config.Auths[harborServiceHost]; a.Username != robot.Name || a.Password != robot.Secret {
+		t.Errorf("registry secret = %+v, want the robot account", a)
+	}
+
+	if secrets, _ := pipelineSecrets(spec, nil, nil); len(secrets) != 0 {
+		t.Errorf("got %d secrets without credentials, want none", len(secrets))
+	}
+}
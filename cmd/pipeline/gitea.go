@@ -0,0 +1,45 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"adhar-io/adhar/platform/utils/gitopsrepo"
+
+	"code.gitea.io/sdk/gitea"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// ensurePushWebhook registers a push webhook on the source repository that
+// delivers to target, unless one with that URL already exists. It reports
+// whether a webhook was created.
+func ensurePushWebhook(ctx context.Context, auth *githttp.BasicAuth, repoURL, branch, target string) (bool, error) {
+	owner, repo, err := gitopsrepo.OwnerRepo(repoURL)
+	if err != nil {
+		return false, err
+	}
+	client, err := gitopsrepo.Client(ctx, auth)
+	if err != nil {
+		return false, err
+	}
+	hooks, _, err := client.ListRepoHooks(owner, repo, gitea.ListHooksOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to list webhooks of %s/%s: %w", owner, repo, err)
+	}
+	for _, h := range hooks {
+		if h.Config["url"] == target {
+			return false, nil
+		}
+	}
+	_, _, err = client.CreateRepoHook(owner, repo, gitea.CreateHookOption{
+		Type:         gitea.HookTypeGitea,
+		Config:       map[string]string{"url": target, "content_type": "json"},
+		Events:       []string{"push"},
+		BranchFilter: branch,
+		Active:       true,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook on %s/%s: %w", owner, repo, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,409 @@
+package pipeline
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/k8s"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs <workflow>",
+	Short: "Show pipeline step logs",
+	Long: `Show the logs of every step of an Argo Workflow in DAG order, each step
+headed by its template, phase and duration, followed by a timing summary.
+
+With --follow the command waits for steps that have not started yet and
+streams running steps until the workflow completes.
+
+Examples:
+  adhar pipeline logs build-x7k2p
+  adhar pipeline logs build-x7k2p --follow
+  adhar pipeline logs build-x7k2p --step=test`,
+	Args: cobra.ExactArgs(1),
+	RunE: runLogs,
+}
+
+var (
+	logsFollow bool
+	logsStep   string
+)
+
+// workflowPollInterval is how often a followed workflow is re-read.
+const workflowPollInterval = 2 * time.Second
+
+func init() {
+	logsCmd.Flags().BoolVar(&logsFollow, "follow", false, "Stream logs until the workflow completes")
+	logsCmd.Flags().StringVar(&logsStep, "step", "", "Only show steps whose name contains this value")
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	client, err := getDynamicClient()
+	if err != nil {
+		return err
+	}
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return fmt.Errorf("create kubernetes client: %w", err)
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return streamWorkflowLogs(ctx, client, cs, defaultNamespace(), args[0], logsFollow, os.Stdout)
+}
+
+// workflowNode is the part of an Argo Workflow status node the CLI uses.
+type workflowNode struct {
+	ID           string
+	Name         string
+	DisplayName  string
+	Type         string
+	TemplateName string
+	Phase        string
+	Message      string
+	StartedAt    time.Time
+	FinishedAt   time.Time
+	Children     []string
+	Outputs      map[string]interface{}
+}
+
+// Duration is the node's run time so far (until now while it runs).
+func (n workflowNode) Duration() time.Duration {
+	if n.StartedAt.IsZero() {
+		return 0
+	}
+	end := n.FinishedAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return end.Sub(n.StartedAt).Round(time.Second)
+}
+
+// Done reports whether the node will not change any more.
+func (n workflowNode) Done() bool {
+	switch n.Phase {
+	case "Succeeded", "Failed", "Error", "Skipped", "Omitted":
+		return true
+	}
+	return false
+}
+
+// workflowDone reports whether a workflow phase is final.
+func workflowDone(phase string) bool {
+	return phase == "Succeeded" || phase == "Failed" || phase == "Error"
+}
+
+// workflowNodes parses status.nodes of a Workflow.
+func workflowNodes(wf *unstructured.Unstructured) map[string]workflowNode {
+	raw, _, _ := unstructured.NestedMap(wf.Object, "status", "nodes")
+	nodes := make(map[string]workflowNode, len(raw))
+	for id, v := range raw {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		n := workflowNode{
+			ID:           id,
+			Name:         stringField(m, "name"),
+			DisplayName:  stringField(m, "displayName"),
+			Type:         stringField(m, "type"),
+			TemplateName: stringField(m, "templateName"),
+			Phase:        stringField(m, "phase"),
+			Message:      stringField(m, "message"),
+			StartedAt:    parseTime(stringField(m, "startedAt")),
+			FinishedAt:   parseTime(stringField(m, "finishedAt")),
+		}
+		n.Children, _, _ = unstructured.NestedStringSlice(m, "children")
+		n.Outputs, _, _ = unstructured.NestedMap(m, "outputs")
+		nodes[id] = n
+	}
+	return nodes
+}
+
+func parseTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339, s)
+	return t
+}
+
+// dagOrder returns the nodes topologically sorted along their children
+// edges, starting from root. Nodes that become ready together are ordered by
+// start time, then name, so parallel steps print in the order they ran.
+func dagOrder(nodes map[string]workflowNode, root string) []workflowNode {
+	indegree := map[string]int{}
+	for id, n := range nodes {
+		if _, ok := indegree[id]; !ok {
+			indegree[id] = 0
+		}
+		for _, c := range n.Children {
+			if _, ok := nodes[c]; ok {
+				indegree[c]++
+			}
+		}
+	}
+	less := func(a, b workflowNode) bool {
+		switch {
+		case a.StartedAt.IsZero() != b.StartedAt.IsZero():
+			return !a.StartedAt.IsZero()
+		case !a.StartedAt.Equal(b.StartedAt):
+			return a.StartedAt.Before(b.StartedAt)
+		}
+		return a.Name < b.Name
+	}
+
+	var ready []workflowNode
+	if n, ok := nodes[root]; ok && indegree[root] == 0 {
+		ready = append(ready, n)
+	} else {
+		for id, d := range indegree {
+			if d == 0 {
+				ready = append(ready, nodes[id])
+			}
+		}
+	}
+	var out []workflowNode
+	seen := map[string]bool{}
+	for len(ready) > 0 {
+		sort.SliceStable(ready, func(i, j int) bool { return less(ready[i], ready[j]) })
+		n := ready[0]
+		ready = ready[1:]
+		if seen[n.ID] {
+			continue
+		}
+		seen[n.ID] = true
+		out = append(out, n)
+		for _, c := range n.Children {
+			child, ok := nodes[c]
+			if !ok {
+				continue
+			}
+			indegree[c]--
+			if indegree[c] == 0 {
+				ready = append(ready, child)
+			}
+		}
+	}
+	return out
+}
+
+// stepNodes returns the Pod nodes of a workflow in DAG order; they are the
+// steps that produce logs.
+func stepNodes(wf *unstructured.Unstructured) []workflowNode {
+	var steps []workflowNode
+	for _, n := range dagOrder(workflowNodes(wf), wf.GetName()) {
+		if n.Type == "Pod" || n.Type == "Skipped" {
+			steps = append(steps, n)
+		}
+	}
+	return steps
+}
+
+// stepLabel is the user-facing name of a step node.
+func stepLabel(n workflowNode) string {
+	label := n.DisplayName
+	if label == "" {
+		label = n.Name
+	}
+	if n.TemplateName != "" && n.TemplateName != label {
+		label += " (" + n.TemplateName + ")"
+	}
+	return label
+}
+
+// streamWorkflowLogs prints the logs of each step of the workflow in DAG
+// order. With follow it waits for pending steps and streams running ones
+// until the workflow completes.
+func streamWorkflowLogs(ctx context.Context, client dynamic.Interface, cs kubernetes.Interface, ns, name string, follow bool, w io.Writer) error {
+	printed := map[string]bool{}
+	for {
+		wf, err := client.Resource(workflowsGVR).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return fmt.Errorf("workflow %q not found in namespace %q", name, ns)
+			}
+			return fmt.Errorf("get workflow: %w", err)
+		}
+		phase := stringField(wf.Object, "status", "phase")
+		pods, err := workflowPods(ctx, cs, ns, name)
+		if err != nil {
+			return err
+		}
+
+		for _, n := range stepNodes(wf) {
+			if printed[n.ID] {
+				continue
+			}
+			if logsStep != "" && !strings.Contains(n.DisplayName, logsStep) {
+				printed[n.ID] = true
+				continue
+			}
+			pod := pods[n.ID]
+			if !n.Done() && !follow {
+				// Show what is there so far without waiting.
+				fmt.Fprintln(w, helpers.CreateMuted(fmt.Sprintf("── %s  %s %s", stepLabel(n), phaseIcon(n.Phase), valueOrDash(n.Phase))))
+				if pod != "" {
+					if err := copyPodLogs(ctx, cs, ns, pod, false, w); err != nil {
+						return err
+					}
+				}
+				printed[n.ID] = true
+				continue
+			}
+			if !n.Done() && pod == "" {
+				// Keep DAG order: wait for this step's pod before printing
+				// anything after it.
+				break
+			}
+
+			fmt.Fprintln(w, helpers.CreateMuted("── "+stepLabel(n)))
+			if pod != "" {
+				if err := copyPodLogs(ctx, cs, ns, pod, !n.Done(), w); err != nil {
+					return err
+				}
+			}
+			if !n.Done() {
+				// The log stream ends with the container; wait for the node
+				// to record its result.
+				if n, err = waitForNode(ctx, client, ns, name, n.ID); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintln(w, stepResult(n))
+			printed[n.ID] = true
+		}
+
+		if !follow || workflowDone(phase) {
+			fmt.Fprintln(w)
+			return printStepSummary(wf, w)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(workflowPollInterval):
+		}
+	}
+}
+
+// workflowPods maps node IDs to the names of the pods that ran them.
+func workflowPods(ctx context.Context, cs kubernetes.Interface, ns, workflow string) (map[string]string, error) {
+	list, err := cs.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{LabelSelector: "workflows.argoproj.io/workflow=" + workflow})
+	if err != nil {
+		return nil, fmt.Errorf("list workflow pods: %w", err)
+	}
+	pods := map[string]string{}
+	for _, p := range list.Items {
+		if id := p.Annotations["workflows.argoproj.io/node-id"]; id != "" {
+			pods[id] = p.Name
+		}
+	}
+	return pods, nil
+}
+
+// copyPodLogs writes the main container log of pod to w, indented.
+func copyPodLogs(ctx context.Context, cs kubernetes.Interface, ns, pod string, follow bool, w io.Writer) error {
+	stream, err := cs.CoreV1().Pods(ns).GetLogs(pod, &corev1.PodLogOptions{Container: "main", Follow: follow}).Stream(ctx)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			fmt.Fprintln(w, helpers.CreateMuted("   (pod "+pod+" was deleted; logs are no longer available)"))
+			return nil
+		}
+		// Pods that never started have no log to read.
+		fmt.Fprintln(w, helpers.CreateMuted("   (no logs: "+err.Error()+")"))
+		return nil
+	}
+	defer stream.Close()
+	sc := bufio.NewScanner(stream)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		fmt.Fprintln(w, "   "+sc.Text())
+	}
+	if err := sc.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("read logs of %s: %w", pod, err)
+	}
+	return ctx.Err()
+}
+
+// waitForNode polls the workflow until node id has finished.
+func waitForNode(ctx context.Context, client dynamic.Interface, ns, workflow, id string) (workflowNode, error) {
+	for {
+		wf, err := client.Resource(workflowsGVR).Namespace(ns).Get(ctx, workflow, metav1.GetOptions{})
+		if err != nil {
+			return workflowNode{}, fmt.Errorf("get workflow: %w", err)
+		}
+		n := workflowNodes(wf)[id]
+		if n.Done() || workflowDone(stringField(wf.Object, "status", "phase")) {
+			return n, nil
+		}
+		select {
+		case <-ctx.Done():
+			return n, ctx.Err()
+		case <-time.After(workflowPollInterval):
+		}
+	}
+}
+
+func stepResult(n workflowNode) string {
+	line := fmt.Sprintf("%s %s in %s", phaseIcon(n.Phase), n.Phase, n.Duration())
+	if n.Message != "" && n.Phase != "Succeeded" {
+		line += ": " + n.Message
+	}
+	if n.Phase == "Succeeded" {
+		return helpers.CreateSuccess(line)
+	}
+	if n.Phase == "Skipped" || n.Phase == "Omitted" {
+		return helpers.CreateMuted(line)
+	}
+	return helpers.ErrorStyle.Render(line)
+}
+
+func phaseIcon(phase string) string {
+	switch phase {
+	case "Succeeded":
+		return "✅"
+	case "Failed", "Error":
+		return "❌"
+	case "Running":
+		return "🔄"
+	case "Skipped", "Omitted":
+		return "⏭"
+	}
+	return "⏳"
+}
+
+// printStepSummary prints one line per step with its phase and duration.
+func printStepSummary(wf *unstructured.Unstructured, w io.Writer) error {
+	steps := stepNodes(wf)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STEP\tTEMPLATE\tPHASE\tSTARTED\tDURATION")
+	for _, n := range steps {
+		started := "-"
+		if !n.StartedAt.IsZero() {
+			started = n.StartedAt.Local().Format("15:04:05")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s %s\t%s\t%s\n", valueOrDash(n.DisplayName), valueOrDash(n.TemplateName),
+			phaseIcon(n.Phase), valueOrDash(n.Phase), started, n.Duration())
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	phase := stringField(wf.Object, "status", "phase")
+	root := workflowNodes(wf)[wf.GetName()]
+	fmt.Fprintf(w, "\nWorkflow %s: %s %s in %s\n", wf.GetName(), phaseIcon(phase), valueOrDash(phase), root.Duration())
+	return nil
+}
@@ -0,0 +1,76 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// stepsWorkflow is a finished steps workflow: clone, then test and lint in
+// parallel, then build, which failed.
+func stepsWorkflow() *unstructured.Unstructured {
+	node := func(name, typ, phase, started, finished string, children ...string) map[string]interface{} {
+		n := map[string]interface{}{"name": "wf" + name, "displayName": strings.TrimPrefix(name, "."), "type": typ, "phase": phase}
+		if started != "" {
+			n["startedAt"] = "2026-01-01T10:00:" + started + "Z"
+		}
+		if finished != "" {
+			n["finishedAt"] = "2026-01-01T10:00:" + finished + "Z"
+		}
+		if len(children) > 0 {
+			var c []interface{}
+			for _, id := range children {
+				c = append(c, id)
+			}
+			n["children"] = c
+		}
+		return n
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "wf", "namespace": "argo"},
+		"spec":     map[string]interface{}{"shutdown": "Stop"},
+		"status": map[string]interface{}{
+			"phase": "Failed",
+			"nodes": map[string]interface{}{
+				"wf":       node("", "Steps", "Failed", "00", "30", "wf-sg1"),
+				"wf-sg1":   node("[0]", "StepGroup", "Succeeded", "00", "02", "wf-clone"),
+				"wf-clone": node(".clone", "Pod", "Succeeded", "00", "02", "wf-sg2"),
+				"wf-sg2":   node("[1]", "StepGroup", "Succeeded", "02", "20", "wf-test", "wf-lint"),
+				"wf-test":  node(".test", "Pod", "Succeeded", "05", "20", "wf-sg3"),
+				"wf-lint":  node(".lint", "Pod", "Succeeded", "03", "10", "wf-sg3"),
+				"wf-sg3":   node("[2]", "StepGroup", "Failed", "20", "30", "wf-build", "wf-exit"),
+				"wf-build": node(".build", "Pod", "Failed", "20", "30"),
+				"wf-exit":  node(".onExit", "Pod", "Succeeded", "30", "31"),
+			},
+		},
+	}}
+}
+
+func TestStepNodesDAGOrder(t *testing.T) {
+	var got []string
+	for _, n := range stepNodes(stepsWorkflow()) {
+		got = append(got, n.DisplayName+"="+n.Duration().String())
+	}
+	if want := "clone=2s,lint=7s,test=15s,build=10s,onExit=1s"; strings.Join(got, ",") != want {
+		t.Errorf("steps = %s, want %s", strings.Join(got, ","), want)
+	}
+}
+
+func TestCheckRetryable(t *testing.T) {
+	wf := stepsWorkflow()
+	if err := checkRetryable(wf); err != nil {
+		t.Errorf("a failed workflow must be retryable: %v", err)
+	}
+	_ = unstructured.SetNestedField(wf.Object, "Running", "status", "phase")
+	if err := checkRetryable(wf); err == nil {
+		t.Errorf("a running workflow cannot be retried")
+	}
+}
+
+func TestArgoRetryArgsUseAdharKubeconfig(t *testing.T) {
+	got := strings.Join(argoRetryArgs("/tmp/kubeconfig", "kind-adhar", "argo", "build-x7k2p"), " ")
+	if want := "retry build-x7k2p --namespace argo --kubeconfig /tmp/kubeconfig --context kind-adhar"; got != want {
+		t.Errorf("argo args = %q, want %q", got, want)
+	}
+}
@@ -40,7 +40,8 @@ Examples:
   adhar pipeline list                    # List all pipelines
   adhar pipeline create --name=deploy   # Create new pipeline
   adhar pipeline run --name=deploy      # Run pipeline
-  adhar pipeline status --name=deploy   # Check pipeline status`,
+  adhar pipeline status --name=deploy   # Check pipeline status
+  adhar pipeline logs deploy-x7k2p      # Show step logs`,
 	RunE: runPipeline,
 }
 
@@ -57,13 +58,13 @@ var (
 
 func init() {
 	// Pipeline command flags
-	PipelineCmd.Flags().StringVarP(&pipelineName, "name", "n", "", "Pipeline name")
-	PipelineCmd.Flags().StringVarP(&pipelineType, "type", "t", "", "Pipeline type (build, deploy, test)")
-	PipelineCmd.Flags().StringVarP(&namespace, "namespace", "s", "", "Namespace")
-	PipelineCmd.Flags().StringVarP(&service, "service", "e", "", "Service name")
-	PipelineCmd.Flags().StringVarP(&timeout, "timeout", "i", "30m", "Operation timeout")
-	PipelineCmd.Flags().StringVarP(&output, "output", "f", "", "Output format (table, json, yaml)")
-	PipelineCmd.Flags().BoolVarP(&detailed, "detailed", "d", false, "Show detailed information")
+	PipelineCmd.PersistentFlags().StringVarP(&pipelineName, "name", "n", "", "Pipeline name")
+	PipelineCmd.PersistentFlags().StringVarP(&pipelineType, "type", "t", "", "Pipeline type (build, deploy, test)")
+	PipelineCmd.PersistentFlags().StringVarP(&namespace, "namespace", "s", "", "Namespace")
+	PipelineCmd.PersistentFlags().StringVarP(&service, "service", "e", "", "Service name")
+	PipelineCmd.PersistentFlags().StringVarP(&timeout, "timeout", "i", "30m", "Operation timeout")
+	PipelineCmd.PersistentFlags().StringVarP(&output, "output", "f", "", "Output format (table, json, yaml)")
+	PipelineCmd.PersistentFlags().BoolVarP(&detailed, "detailed", "d", false, "Show detailed information")
 
 	// Add subcommands
 	PipelineCmd.AddCommand(listCmd)
@@ -71,16 +72,24 @@ func init() {
 	PipelineCmd.AddCommand(runCmd)
 	PipelineCmd.AddCommand(statusCmd)
 	PipelineCmd.AddCommand(templateCmd)
+	PipelineCmd.AddCommand(logsCmd)
+	PipelineCmd.AddCommand(artifactsCmd)
+	PipelineCmd.AddCommand(retryCmd)
+	PipelineCmd.AddCommand(stopCmd)
 }
 
 func runPipeline(cmd *cobra.Command, args []string) error {
 	logger.Info("🔧 Pipeline management - use subcommands for specific pipeline tasks")
 	logger.Info("Available subcommands:")
-	logger.Info("  list     - List all pipelines")
-	logger.Info("  create   - Create new pipelines")
-	logger.Info("  run      - Run pipelines")
-	logger.Info("  status   - Check pipeline status")
-	logger.Info("  template - Manage pipeline templates")
+	logger.Info("  list      - List all pipelines")
+	logger.Info("  create    - Create new pipelines")
+	logger.Info("  run       - Run pipelines")
+	logger.Info("  status    - Check pipeline status")
+	logger.Info("  template  - Manage pipeline templates")
+	logger.Info("  logs      - Show pipeline step logs")
+	logger.Info("  artifacts - Download pipeline artifacts")
+	logger.Info("  retry     - Retry a failed pipeline")
+	logger.Info("  stop      - Stop a running pipeline")
 
 	return cmd.Help()
 }
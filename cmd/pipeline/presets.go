@@ -0,0 +1,392 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Images used by the generated pipelines. They are pinned so a template
+// behaves the same on every cluster it is committed to.
+const (
+	gitImage        = "alpine/git:2.45.2"
+	kanikoImage     = "gcr.io/kaniko-project/executor:v1.23.2"
+	buildpacksImage = "paketobuildpacks/builder-jammy-base:0.4.372"
+
+	// eventSourcePort is the port the argo-events webhook EventSource listens on.
+	eventSourcePort = 12000
+)
+
+// pipelinePreset describes how a language or build style is tested and built.
+type pipelinePreset struct {
+	Name string
+	// TestImage and TestScript run after the clone; empty means no test step.
+	TestImage  string
+	TestScript string
+	// Buildpacks builds the image with the Cloud Native Buildpacks lifecycle
+	// instead of kaniko and a Dockerfile.
+	Buildpacks bool
+}
+
+var pipelinePresets = map[string]pipelinePreset{
+	"go": {
+		Name:       "go",
+		TestImage:  "golang:1.22",
+		TestScript: "go vet ./...\ngo test ./...",
+	},
+	"node": {
+		Name:       "node",
+		TestImage:  "node:20",
+		TestScript: "npm ci\nnpm test",
+	},
+	"python": {
+		Name:       "python",
+		TestImage:  "python:3.12",
+		TestScript: "pip install -r requirements.txt\npip install pytest\npython -m pytest",
+	},
+	"kaniko": {
+		Name: "kaniko",
+	},
+	"buildpacks": {
+		Name:       "buildpacks",
+		Buildpacks: true,
+	},
+}
+
+// presetNames returns the preset names in a stable order for help and errors.
+func presetNames() []string {
+	names := make([]string, 0, len(pipelinePresets))
+	for name := range pipelinePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pipelineSpec is everything needed to render a pipeline's manifests.
+type pipelineSpec struct {
+	Name      string
+	Namespace string
+	Preset    pipelinePreset
+	// RepoURL is the clone URL as seen from inside the cluster.
+	RepoURL string
+	Branch  string
+	// GitSecret holds username/password keys used to clone RepoURL.
+	GitSecret string
+	// Image is the image repository to push to, without a tag. When empty the
+	// image is built but not pushed.
+	Image string
+	// RegistrySecret is a kubernetes.io/dockerconfigjson Secret used to push.
+	RegistrySecret string
+	// InsecureRegistry allows pushing to a plain HTTP registry.
+	InsecureRegistry bool
+	// Events adds an argo-events EventSource and Sensor that submit the
+	// pipeline on pushes to Branch.
+	Events bool
+}
+
+// eventSourceName is the argo-events EventSource receiving the push webhook.
+func (s pipelineSpec) eventSourceName() string { return s.Name + "-push" }
+
+// webhookURL is the in-cluster address Gitea delivers push events to. The
+// argo-events controller exposes EventSources as <name>-eventsource-svc.
+func (s pipelineSpec) webhookURL() string {
+	return fmt.Sprintf("http://%s-eventsource-svc.%s.svc.cluster.local:%d/push", s.eventSourceName(), s.Namespace, eventSourcePort)
+}
+
+func (s pipelineSpec) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("pipeline name is required")
+	}
+	if s.RepoURL == "" {
+		return fmt.Errorf("source repository is required")
+	}
+	if s.Preset.Buildpacks && s.Image == "" {
+		return fmt.Errorf("the buildpacks preset pushes its result; set --image or enable Harbor")
+	}
+	return nil
+}
+
+// buildManifests renders the pipeline: a WorkflowTemplate and, with Events,
+// the EventSource, Sensor and the RBAC the Sensor needs to submit Workflows.
+// Secrets are never part of the result so the manifests can be committed.
+func buildManifests(s pipelineSpec) ([]*unstructured.Unstructured, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	objs := []*unstructured.Unstructured{workflowTemplate(s)}
+	if s.Events {
+		objs = append(objs, sensorRBAC(s)...)
+		objs = append(objs, eventSource(s), sensor(s))
+	}
+	return objs, nil
+}
+
+func newObject(apiVersion, kind string, s pipelineSpec, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	obj := map[string]interface{}{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": s.Namespace,
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/managed-by": "adhar",
+				"adhar.io/pipeline":            s.Name,
+			},
+		},
+	}
+	for k, v := range spec {
+		obj[k] = v
+	}
+	return &unstructured.Unstructured{Object: obj}
+}
+
+func workflowTemplate(s pipelineSpec) *unstructured.Unstructured {
+	workspaceMount := []interface{}{map[string]interface{}{"name": "workspace", "mountPath": "/workspace"}}
+
+	steps := []interface{}{
+		[]interface{}{map[string]interface{}{"name": "clone", "template": "clone"}},
+	}
+	templates := []interface{}{nil} // main, filled in below
+	templates = append(templates, cloneTemplate(s, workspaceMount))
+	if s.Preset.TestScript != "" {
+		steps = append(steps, []interface{}{map[string]interface{}{"name": "test", "template": "test"}})
+		templates = append(templates, map[string]interface{}{
+			"name": "test",
+			"script": map[string]interface{}{
+				"image":        s.Preset.TestImage,
+				"command":      []interface{}{"sh", "-e"},
+				"workingDir":   "/workspace/src",
+				"source":       s.Preset.TestScript + "\n",
+				"volumeMounts": workspaceMount,
+			},
+		})
+	}
+	steps = append(steps, []interface{}{map[string]interface{}{"name": "build", "template": "build"}})
+	templates = append(templates, buildTemplate(s, workspaceMount))
+	templates[0] = map[string]interface{}{"name": "main", "steps": steps}
+
+	params := []interface{}{
+		map[string]interface{}{"name": "repo", "value": s.RepoURL},
+		map[string]interface{}{"name": "revision", "value": s.Branch},
+	}
+	if s.Image != "" {
+		params = append(params, map[string]interface{}{"name": "image", "value": s.Image})
+	}
+
+	spec := map[string]interface{}{
+		"entrypoint": "main",
+		"arguments":  map[string]interface{}{"parameters": params},
+		"volumeClaimTemplates": []interface{}{map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "workspace"},
+			"spec": map[string]interface{}{
+				"accessModes": []interface{}{"ReadWriteOnce"},
+				"resources":   map[string]interface{}{"requests": map[string]interface{}{"storage": "1Gi"}},
+			},
+		}},
+		"templates": templates,
+	}
+	if s.Image != "" && s.RegistrySecret != "" {
+		spec["volumes"] = []interface{}{map[string]interface{}{
+			"name": "docker-config",
+			"secret": map[string]interface{}{
+				"secretName": s.RegistrySecret,
+				"items":      []interface{}{map[string]interface{}{"key": ".dockerconfigjson", "path": "config.json"}},
+			},
+		}}
+	}
+
+	wt := newObject("argoproj.io/v1alpha1", "WorkflowTemplate", s, s.Name, map[string]interface{}{"spec": spec})
+	wt.SetAnnotations(map[string]string{"adhar.io/pipeline-preset": s.Preset.Name})
+	return wt
+}
+
+func cloneTemplate(s pipelineSpec, mounts []interface{}) map[string]interface{} {
+	script := `rm -rf /workspace/src
+git clone "{{workflow.parameters.repo}}" /workspace/src
+cd /workspace/src
+git checkout "{{workflow.parameters.revision}}"
+git log -1 --oneline
+`
+	container := map[string]interface{}{
+		"image":        gitImage,
+		"command":      []interface{}{"sh", "-e"},
+		"source":       script,
+		"volumeMounts": mounts,
+	}
+	if s.GitSecret != "" {
+		// A credential helper keeps the password out of the clone URL and logs.
+		container["source"] = "git config --global credential.helper '!f() { echo username=$GIT_USERNAME; echo password=$GIT_PASSWORD; }; f'\n" + script
+		container["env"] = []interface{}{
+			secretEnv("GIT_USERNAME", s.GitSecret, "username"),
+			secretEnv("GIT_PASSWORD", s.GitSecret, "password"),
+		}
+	}
+	return map[string]interface{}{"name": "clone", "script": container}
+}
+
+func buildTemplate(s pipelineSpec, mounts []interface{}) map[string]interface{} {
+	destination := "{{workflow.parameters.image}}:{{workflow.parameters.revision}}"
+	if s.RegistrySecret != "" && s.Image != "" {
+		mounts = append(mounts, map[string]interface{}{"name": "docker-config", "mountPath": "/docker-config"})
+	}
+
+	if s.Preset.Buildpacks {
+		env := []interface{}{map[string]interface{}{"name": "CNB_PLATFORM_API", "value": "0.12"}}
+		if s.RegistrySecret != "" {
+			env = append(env, map[string]interface{}{"name": "DOCKER_CONFIG", "value": "/docker-config"})
+		}
+		if s.InsecureRegistry {
+			env = append(env, map[string]interface{}{"name": "CNB_INSECURE_REGISTRIES", "value": registryHost(s.Image)})
+		}
+		return map[string]interface{}{
+			"name": "build",
+			"container": map[string]interface{}{
+				"image":           buildpacksImage,
+				"command":         []interface{}{"/cnb/lifecycle/creator"},
+				"args":            []interface{}{"-app=/workspace/src", destination},
+				"env":             env,
+				"volumeMounts":    mounts,
+				"securityContext": map[string]interface{}{"runAsUser": int64(1000), "runAsGroup": int64(1000)},
+			},
+		}
+	}
+
+	args := []interface{}{"--context=dir:///workspace/src", "--dockerfile=Dockerfile"}
+	if s.Image == "" {
+		args = append(args, "--no-push")
+	} else {
+		args = append(args, "--destination="+destination)
+		if s.InsecureRegistry {
+			args = append(args, "--insecure")
+		}
+	}
+	container := map[string]interface{}{
+		"image":        kanikoImage,
+		"args":         args,
+		"volumeMounts": mounts,
+	}
+	if s.RegistrySecret != "" && s.Image != "" {
+		container["env"] = []interface{}{map[string]interface{}{"name": "DOCKER_CONFIG", "value": "/docker-config"}}
+	}
+	return map[string]interface{}{"name": "build", "container": container}
+}
+
+func secretEnv(name, secret, key string) map[string]interface{} {
+	return map[string]interface{}{
+		"name": name,
+		"valueFrom": map[string]interface{}{
+			"secretKeyRef": map[string]interface{}{"name": secret, "key": key},
+		},
+	}
+}
+
+// sensorServiceAccount is the identity the Sensor submits Workflows as.
+func (s pipelineSpec) sensorServiceAccount() string { return s.Name + "-sensor" }
+
+func sensorRBAC(s pipelineSpec) []*unstructured.Unstructured {
+	sa := s.sensorServiceAccount()
+	return []*unstructured.Unstructured{
+		newObject("v1", "ServiceAccount", s, sa, nil),
+		newObject("rbac.authorization.k8s.io/v1", "Role", s, sa, map[string]interface{}{
+			"rules": []interface{}{
+				map[string]interface{}{
+					"apiGroups": []interface{}{"argoproj.io"},
+					"resources": []interface{}{"workflows"},
+					"verbs":     []interface{}{"create", "get", "list", "watch"},
+				},
+				map[string]interface{}{
+					"apiGroups": []interface{}{"argoproj.io"},
+					"resources": []interface{}{"workflowtemplates"},
+					"verbs":     []interface{}{"get"},
+				},
+			},
+		}),
+		newObject("rbac.authorization.k8s.io/v1", "RoleBinding", s, sa, map[string]interface{}{
+			"roleRef": map[string]interface{}{
+				"apiGroup": "rbac.authorization.k8s.io",
+				"kind":     "Role",
+				"name":     sa,
+			},
+			"subjects": []interface{}{map[string]interface{}{
+				"kind":      "ServiceAccount",
+				"name":      sa,
+				"namespace": s.Namespace,
+			}},
+		}),
+	}
+}
+
+func eventSource(s pipelineSpec) *unstructured.Unstructured {
+	return newObject("argoproj.io/v1alpha1", "EventSource", s, s.eventSourceName(), map[string]interface{}{
+		"spec": map[string]interface{}{
+			"service": map[string]interface{}{
+				"ports": []interface{}{map[string]interface{}{"port": int64(eventSourcePort), "targetPort": int64(eventSourcePort)}},
+			},
+			"webhook": map[string]interface{}{
+				"push": map[string]interface{}{
+					"port":     fmt.Sprint(eventSourcePort),
+					"endpoint": "/push",
+					"method":   "POST",
+				},
+			},
+		},
+	})
+}
+
+func sensor(s pipelineSpec) *unstructured.Unstructured {
+	workflow := map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Workflow",
+		"metadata": map[string]interface{}{
+			"generateName": s.Name + "-",
+			"labels":       map[string]interface{}{"adhar.io/pipeline": s.Name},
+		},
+		"spec": map[string]interface{}{
+			"workflowTemplateRef": map[string]interface{}{"name": s.Name},
+			"arguments": map[string]interface{}{
+				"parameters": []interface{}{map[string]interface{}{"name": "revision", "value": s.Branch}},
+			},
+		},
+	}
+	return newObject("argoproj.io/v1alpha1", "Sensor", s, s.Name, map[string]interface{}{
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{"serviceAccountName": s.sensorServiceAccount()},
+			"dependencies": []interface{}{map[string]interface{}{
+				"name":            "push",
+				"eventSourceName": s.eventSourceName(),
+				"eventName":       "push",
+				"filters": map[string]interface{}{
+					"data": []interface{}{map[string]interface{}{
+						"path":  "body.ref",
+						"type":  "string",
+						"value": []interface{}{"refs/heads/" + s.Branch},
+					}},
+				},
+			}},
+			"triggers": []interface{}{map[string]interface{}{
+				"template": map[string]interface{}{
+					"name": "submit-" + s.Name,
+					"argoWorkflow": map[string]interface{}{
+						"operation": "submit",
+						"source":    map[string]interface{}{"resource": workflow},
+						// Build the pushed commit rather than the branch head at
+						// the time the Workflow starts.
+						"parameters": []interface{}{map[string]interface{}{
+							"src":  map[string]interface{}{"dependencyName": "push", "dataKey": "body.after"},
+							"dest": "spec.arguments.parameters.0.value",
+						}},
+					},
+				},
+			}},
+		},
+	})
+}
+
+// registryHost returns the registry part of an image reference.
+func registryHost(image string) string {
+	host, _, _ := strings.Cut(image, "/")
+	return host
+}
@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"adhar-io/adhar/platform/utils/gitopsrepo"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestBuildManifests(t *testing.T) {
+	spec := pipelineSpec{
+		Name:             "web",
+		Namespace:        "argo",
+		Preset:           pipelinePresets["node"],
+		RepoURL:          sourceRepoURL("team/web", "web"),
+		Branch:           "main",
+		GitSecret:        "web-git",
+		Image:            harborServiceHost + "/library/web",
+		RegistrySecret:   "web-registry",
+		InsecureRegistry: true,
+		Events:           true,
+	}
+	objs, err := buildManifests(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, o := range objs {
+		kinds = append(kinds, o.GetKind())
+		if _, ok := createResources[o.GetKind()]; !ok {
+			t.Errorf("no resource mapping for %s", o.GetKind())
+		}
+	}
+	if got := strings.Join(kinds, ","); got != "WorkflowTemplate,ServiceAccount,Role,RoleBinding,EventSource,Sensor" {
+		t.Fatalf("kinds = %s", got)
+	}
+
+	wt := objs[0].Object
+	steps, _, _ := unstructured.NestedSlice(wt, "spec", "templates")
+	if len(steps) != 4 {
+		t.Fatalf("templates = %d, want main, clone, test, build", len(steps))
+	}
+	data, err := renderManifests(objs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		"http://gitea-http.adhar-system.svc.cluster.local:3000/team/web.git",
+		"--destination={{workflow.parameters.image}}:{{workflow.parameters.revision}}",
+		"--insecure",
+		"secretName: web-registry",
+		"npm test",
+		"refs/heads/main",
+		"dataKey: body.after",
+		"serviceAccountName: web-sensor",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("manifests missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "kind: Secret") {
+		t.Errorf("committed manifests must not contain Secrets")
+	}
+	if strings.Count(out, "\n---\n") != len(objs)-1 {
+		t.Errorf("expected %d documents", len(objs))
+	}
+}
+
+func TestBuildManifestsWithoutRegistry(t *testing.T) {
+	spec := pipelineSpec{Name: "api", Namespace: "ci", Preset: pipelinePresets["kaniko"], RepoURL: "https://github.com/org/api.git", Branch: "main"}
+	objs, err := buildManifests(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objs) != 1 {
+		t.Fatalf("without events only the WorkflowTemplate is rendered, got %d objects", len(objs))
+	}
+	data, _ := renderManifests(objs)
+	if !strings.Contains(string(data), "--no-push") || strings.Contains(string(data), "docker-config") {
+		t.Errorf("kaniko without an image should build without pushing:\n%s", data)
+	}
+
+	spec.Preset = pipelinePresets["buildpacks"]
+	if _, err := buildManifests(spec); err == nil {
+		t.Errorf("buildpacks without an image should fail")
+	}
+}
+
+func TestSourceRepoURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                           gitopsrepo.ServiceURL + "/giteaAdmin/svc.git",
+		"team/web.git":               gitopsrepo.ServiceURL + "/team/web.git",
+		"https://github.com/o/r.git": "https://github.com/o/r.git",
+		"git@github.com:o/r.git":     "git@github.com:o/r.git",
+	} {
+		if got := sourceRepoURL(in, "svc"); got != want {
+			t.Errorf("sourceRepoURL(%q) = %s, want %s", in, got, want)
+		}
+	}
+	if !gitopsrepo.IsGiteaURL(sourceRepoURL("", "svc")) || gitopsrepo.IsGiteaURL("https://github.com/o/r.git") {
+		t.Errorf("IsGiteaURL misclassified")
+	}
+}
@@ -0,0 +1,111 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/k8s"
+	"adhar-io/adhar/platform/logger"
+
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var retryCmd = &cobra.Command{
+	Use:   "retry <workflow>",
+	Short: "Retry a failed pipeline",
+	Long: `Retry a failed Argo Workflow in place with ` + "`argo retry`" + `. Steps that
+succeeded keep their results; failed steps and everything that did not run
+because of them are run again by the workflow controller. With --follow the
+step logs are streamed until the workflow completes, and the command fails
+unless the workflow succeeds.
+
+The argo CLI must be on PATH. It is pointed at the same kubeconfig and
+context adhar uses, or talks to the Argo Server when ARGO_SERVER is set,
+which is needed for workflows whose node status is offloaded to a database.
+
+Examples:
+  adhar pipeline retry build-x7k2p
+  adhar pipeline retry build-x7k2p --follow`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRetry,
+}
+
+var retryFollow bool
+
+func init() {
+	retryCmd.Flags().BoolVar(&retryFollow, "follow", false, "Stream step logs until the workflow completes and fail unless it succeeds")
+}
+
+func runRetry(cmd *cobra.Command, args []string) error {
+	ns, name := defaultNamespace(), args[0]
+	argo, err := exec.LookPath("argo")
+	if err != nil {
+		return fmt.Errorf("retry needs the argo CLI on PATH (https://github.com/argoproj/argo-workflows/releases): %w", err)
+	}
+	kubeconfig, err := helpers.LoadKubeConfig()
+	if err != nil {
+		return err
+	}
+	client, err := getDynamicClient()
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	wf, err := client.Resource(workflowsGVR).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return fmt.Errorf("workflow %q not found in namespace %q", name, ns)
+		}
+		return fmt.Errorf("get workflow: %w", err)
+	}
+	if err := checkRetryable(wf); err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("🔁 Retrying workflow %s", name))
+	retryArgs := argoRetryArgs(helpers.GetKubeConfigPath(), kubeconfig.CurrentContext, ns, name)
+	if out, err := exec.CommandContext(ctx, argo, retryArgs...).CombinedOutput(); err != nil {
+		return fmt.Errorf("argo retry %s: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("Workflow %s restarted", name)))
+
+	if !retryFollow {
+		return nil
+	}
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return fmt.Errorf("create kubernetes client: %w", err)
+	}
+	return followWorkflow(ctx, cmd, client, cs, ns, name)
+}
+
+// checkRetryable rejects workflows that have not failed.
+func checkRetryable(wf *unstructured.Unstructured) error {
+	phase := stringField(wf.Object, "status", "phase")
+	if phase != "Failed" && phase != "Error" {
+		return fmt.Errorf("workflow %s is %s; only failed workflows can be retried", wf.GetName(), valueOrDash(phase))
+	}
+	return nil
+}
+
+// argoRetryArgs builds the argo retry command line. The workflow is retried
+// with the argo CLI so compressed and offloaded node status, pod cleanup and
+// exit handlers are handled by Argo itself; it is given the kubeconfig and
+// context the workflow was looked up through, not whatever the CLI defaults
+// to.
+func argoRetryArgs(kubeconfig, kubeContext, ns, name string) []string {
+	args := []string{"retry", name, "--namespace", ns, "--kubeconfig", kubeconfig}
+	if kubeContext != "" {
+		args = append(args, "--context", kubeContext)
+	}
+	return args
+}
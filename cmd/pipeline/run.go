@@ -5,12 +5,15 @@ import (
 	"fmt"
 
 	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/k8s"
 	"adhar-io/adhar/platform/logger"
 
 	"github.com/spf13/cobra"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
 )
 
 var runCmd = &cobra.Command{
@@ -19,14 +22,22 @@ var runCmd = &cobra.Command{
 	Long: `Run a pipeline by submitting an Argo Workflow from a WorkflowTemplate.
 
 The --name flag references an existing WorkflowTemplate in the namespace; a new
-Workflow is created from it.
+Workflow is created from it. With --follow the step logs are streamed until the
+workflow completes, and the command fails if the workflow does not succeed.
 
 Examples:
   adhar pipeline run --name=deploy
-  adhar pipeline run --name=build --namespace=argo`,
+  adhar pipeline run --name=build --namespace=argo
+  adhar pipeline run --name=build --follow`,
 	RunE: runRun,
 }
 
+var runFollow bool
+
+func init() {
+	runCmd.Flags().BoolVar(&runFollow, "follow", false, "Stream step logs until the workflow completes")
+}
+
 func runRun(cmd *cobra.Command, args []string) error {
 	if pipelineName == "" {
 		return fmt.Errorf("--name is required for running pipeline (references a WorkflowTemplate)")
@@ -73,5 +84,30 @@ func runRun(cmd *cobra.Command, args []string) error {
 	}
 
 	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("Pipeline started: workflow %s in namespace %s", created.GetName(), ns)))
+	if !runFollow {
+		return nil
+	}
+
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return fmt.Errorf("create kubernetes client: %w", err)
+	}
+	return followWorkflow(ctx, cmd, client, cs, ns, created.GetName())
+}
+
+// followWorkflow streams the step logs of a workflow until it completes and
+// fails unless it succeeded, so `--follow` can gate scripts.
+func followWorkflow(ctx context.Context, cmd *cobra.Command, client dynamic.Interface, cs kubernetes.Interface, ns, name string) error {
+	if err := streamWorkflowLogs(ctx, client, cs, ns, name, true, cmd.OutOrStdout()); err != nil {
+		return err
+	}
+	final, err := client.Resource(workflowsGVR).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get workflow: %w", err)
+	}
+	if phase := stringField(final.Object, "status", "phase"); phase != "Succeeded" {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("workflow %s finished with phase %s", name, phase)
+	}
 	return nil
 }
@@ -0,0 +1,70 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/logger"
+
+	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var stopCmd = &cobra.Command{
+	Use:   "stop <workflow>",
+	Short: "Stop a running pipeline",
+	Long: `Stop a running Argo Workflow. Running steps are stopped and exit handlers
+still run; with --terminate exit handlers are skipped as well.
+
+Examples:
+  adhar pipeline stop build-x7k2p
+  adhar pipeline stop build-x7k2p --terminate`,
+	Args: cobra.ExactArgs(1),
+	RunE: runStop,
+}
+
+var stopTerminate bool
+
+func init() {
+	stopCmd.Flags().BoolVar(&stopTerminate, "terminate", false, "Terminate immediately without running exit handlers")
+}
+
+func runStop(cmd *cobra.Command, args []string) error {
+	ns, name := defaultNamespace(), args[0]
+	client, err := getDynamicClient()
+	if err != nil {
+		return err
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	wf, err := client.Resource(workflowsGVR).Namespace(ns).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return fmt.Errorf("workflow %q not found in namespace %q", name, ns)
+		}
+		return fmt.Errorf("get workflow: %w", err)
+	}
+	if phase := stringField(wf.Object, "status", "phase"); workflowDone(phase) {
+		return fmt.Errorf("workflow %s already finished (%s)", name, phase)
+	}
+
+	// Argo's shutdown strategies: Stop runs exit handlers, Terminate does not.
+	strategy := "Stop"
+	if stopTerminate {
+		strategy = "Terminate"
+	}
+	logger.Info(fmt.Sprintf("🛑 Stopping workflow %s (%s)", name, strategy))
+	patch, _ := json.Marshal(map[string]interface{}{"spec": map[string]interface{}{"shutdown": strategy}})
+	if _, err := client.Resource(workflowsGVR).Namespace(ns).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("stop workflow: %w", err)
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("Workflow %s is shutting down (%s)", name, strategy)))
+	return nil
+}
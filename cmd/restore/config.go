@@ -1,53 +1,70 @@
 package restore
 
 import (
+	"context"
 	"fmt"
 
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/globals"
+
 	"github.com/spf13/cobra"
 )
 
 var (
 	configCmd = &cobra.Command{
-		Use:   "config [backup-path]",
+		Use:   "config [backup-name]",
 		Short: "Configuration restoration only",
-		Long: `Restore only configurations from a backup.
-This includes Kubernetes resources, application configs, and settings.`,
+		Long: `Restore only the Adhar platform configuration from a Velero backup:
+AdharPlatform, GitRepository and CustomPackage objects, plus the ConfigMaps of
+the platform namespace (or the namespaces given with --namespaces).
+
+The backup is compared with the live cluster first; see
+'adhar restore selective --help' for the --conflict policies.
+
+Examples:
+  adhar restore config nightly --dry-run --diff
+  adhar restore config nightly --conflict=overwrite
+  adhar restore config nightly --namespaces=adhar-system,team-a`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: runConfigRestore,
 	}
 
 	// Config restore specific flags
-	configTypes []string
-	namespaces  []string
-	overwrite   bool
+	namespaces []string
 )
 
+// adharConfigResources are the platform.adhar.io kinds that make up the
+// platform's declarative configuration.
+var adharConfigResources = []string{
+	"adharplatforms.platform.adhar.io",
+	"gitrepositories.platform.adhar.io",
+	"custompackages.platform.adhar.io",
+}
+
 func init() {
-	configCmd.Flags().StringArrayVarP(&configTypes, "types", "t", []string{}, "Configuration types to restore (k8s, apps, secrets, policies)")
-	configCmd.Flags().StringArrayVarP(&namespaces, "namespaces", "n", []string{}, "Namespaces to restore")
-	configCmd.Flags().BoolVarP(&overwrite, "overwrite", "o", false, "Overwrite existing configurations")
+	configCmd.Flags().StringSliceVarP(&namespaces, "namespaces", "n", []string{globals.AdharSystemNamespace}, "Namespaces whose ConfigMaps are restored")
+	addScopedRestoreFlags(configCmd)
 }
 
 func runConfigRestore(cmd *cobra.Command, args []string) error {
 	if len(args) > 0 {
 		backupPath = args[0]
 	}
-
 	if backupPath == "" {
-		return fmt.Errorf("backup path is required. Use --backup flag or provide as argument")
-	}
-
-	fmt.Printf("⚙️  Starting configuration restoration from: %s\n", backupPath)
-
-	if len(configTypes) > 0 {
-		fmt.Printf("🔧 Configuration types: %v\n", configTypes)
-	}
-	if len(namespaces) > 0 {
-		fmt.Printf("📦 Namespaces: %v\n", namespaces)
+		return fmt.Errorf("backup name is required. Use --backup flag or provide as argument")
 	}
-	fmt.Printf("🔄 Overwrite existing: %t\n", overwrite)
 
-	// TODO: Implement configuration restoration logic
-	fmt.Println("✅ Configuration restoration completed successfully!")
-	return nil
+	fmt.Println(helpers.TitleStyle.Render("⚙️  Configuration Restore"))
+	return runScopedRestore(context.Background(), "config", backupPath, []scopedRestore{
+		{
+			Suffix:    "platform",
+			Title:     "Adhar resources",
+			Selection: restoreSelection{Resources: adharConfigResources},
+		},
+		{
+			Suffix:    "configmaps",
+			Title:     "ConfigMaps",
+			Selection: restoreSelection{Namespaces: namespaces, Resources: []string{"configmaps"}},
+		},
+	})
 }
@@ -0,0 +1,222 @@
+package restore
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var downloadRequestGVR = schema.GroupVersionResource{
+	Group: "velero.io", Version: "v1", Resource: "downloadrequests",
+}
+
+// backupItem is one Kubernetes object stored in a Velero backup tarball.
+type backupItem struct {
+	// GroupResource is Velero's "<resource>.<group>" key, e.g. deployments.apps
+	// or configmaps for the core group.
+	GroupResource string
+	Namespace     string
+	Name          string
+	Object        *unstructured.Unstructured
+}
+
+// Key identifies the item independent of API version.
+func (i backupItem) Key() string {
+	if i.Namespace == "" {
+		return i.GroupResource + "/" + i.Name
+	}
+	return i.GroupResource + "/" + i.Namespace + "/" + i.Name
+}
+
+// GVR returns the resource to use when looking the item up in a live cluster.
+func (i backupItem) GVR() schema.GroupVersionResource {
+	gr := schema.ParseGroupResource(i.GroupResource)
+	gv, _ := schema.ParseGroupVersion(i.Object.GetAPIVersion())
+	return gr.WithVersion(gv.Version)
+}
+
+// openBackupContents returns a reader for a backup's tarball. A path to a local
+// file (e.g. one fetched with `velero backup download`) is opened directly;
+// otherwise ref is treated as a Velero Backup name and fetched through a
+// DownloadRequest. The signed URL Velero returns must be reachable from this
+// machine; for in-cluster minio set publicUrl on the BackupStorageLocation.
+func openBackupContents(ctx context.Context, dyn dynamic.Interface, ref string) (io.ReadCloser, error) {
+	if st, err := os.Stat(ref); err == nil && !st.IsDir() {
+		return os.Open(ref)
+	}
+
+	url, err := requestBackupDownload(ctx, dyn, ref)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download backup %q: %w", ref, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("failed to download backup %q: %s", ref, resp.Status)
+	}
+	return resp.Body, nil
+}
+
+// requestBackupDownload creates a Velero DownloadRequest for the backup
+// contents and waits for the signed download URL.
+func requestBackupDownload(ctx context.Context, dyn dynamic.Interface, backup string) (string, error) {
+	name := fmt.Sprintf("%s-%d", truncate(backup, 40), time.Now().Unix())
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "velero.io/v1",
+		"kind":       "DownloadRequest",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": veleroNamespace,
+			"labels":    map[string]interface{}{"adhar.io/managed-by": "adhar-cli"},
+		},
+		"spec": map[string]interface{}{
+			"target": map[string]interface{}{"kind": "BackupContents", "name": backup},
+		},
+	}}
+	res := dyn.Resource(downloadRequestGVR).Namespace(veleroNamespace)
+	if _, err := res.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+		if crdMissing(err) {
+			return "", fmt.Errorf("Velero DownloadRequest CRD not installed (velero not present in the cluster)")
+		}
+		return "", fmt.Errorf("failed to request download of backup %q: %w", backup, err)
+	}
+	defer func() { _ = res.Delete(context.Background(), name, metav1.DeleteOptions{}) }()
+
+	cctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	for {
+		got, err := res.Get(cctx, name, metav1.GetOptions{})
+		if err == nil && nestedString(got.Object, "status", "phase") == "Processed" {
+			if url := nestedString(got.Object, "status", "downloadURL"); url != "" {
+				return url, nil
+			}
+			return "", fmt.Errorf("velero returned no download URL for backup %q", backup)
+		}
+		select {
+		case <-cctx.Done():
+			return "", fmt.Errorf("timed out waiting for velero to sign a download URL for backup %q", backup)
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// readBackupArchive decodes every resource in a Velero backup tarball. Entries
+// live under resources/<resource.group>/[<version>/](namespaces/<ns>|cluster)/<name>.json;
+// when an object is stored under several API versions the preferred (or
+// unversioned) copy wins.
+func readBackupArchive(r io.Reader) ([]backupItem, error) {
+	items, undecodable, err := scanBackupArchive(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(undecodable) > 0 {
+		return nil, fmt.Errorf("failed to decode %s", strings.Join(firstN(undecodable, 3), ", "))
+	}
+	return items, nil
+}
+
+// scanBackupArchive is readBackupArchive that keeps going past entries that do
+// not decode, returning their paths instead. Archive-level corruption (bad
+// gzip/tar framing or checksum) is still an error.
+func scanBackupArchive(r io.Reader) ([]backupItem, []string, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("backup is not a gzip archive: %w", err)
+	}
+	defer gz.Close()
+
+	type ranked struct {
+		item backupItem
+		rank int
+	}
+	items := map[string]ranked{}
+	var undecodable []string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			// Drain the gzip trailer so a corrupt archive fails its CRC check.
+			if _, err := io.Copy(io.Discard, gz); err != nil {
+				return nil, nil, fmt.Errorf("backup archive failed its gzip checksum: %w", err)
+			}
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read backup archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		gr, ns, name, rank, ok := parseBackupEntry(hdr.Name)
+		if !ok {
+			continue
+		}
+		raw, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", hdr.Name, err)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := json.Unmarshal(raw, &obj.Object); err != nil || obj.GetKind() == "" || obj.GetAPIVersion() == "" {
+			undecodable = append(undecodable, hdr.Name)
+			continue
+		}
+		item := backupItem{GroupResource: gr, Namespace: ns, Name: name, Object: obj}
+		if cur, seen := items[item.Key()]; !seen || rank < cur.rank {
+			items[item.Key()] = ranked{item: item, rank: rank}
+		}
+	}
+
+	out := make([]backupItem, 0, len(items))
+	for _, r := range items {
+		out = append(out, r.item)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key() < out[j].Key() })
+	return out, undecodable, nil
+}
+
+// parseBackupEntry splits a tarball path into its resource coordinates. rank
+// orders duplicate copies: 0 unversioned, 1 preferred version, 2 other versions.
+func parseBackupEntry(name string) (gr, ns, obj string, rank int, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(path.Clean(name), "./"), "/")
+	if len(parts) < 4 || parts[0] != "resources" || !strings.HasSuffix(parts[len(parts)-1], ".json") {
+		return "", "", "", 0, false
+	}
+	gr = parts[1]
+	rest := parts[2:]
+	if rest[0] != "namespaces" && rest[0] != "cluster" {
+		rank = 2
+		if strings.HasSuffix(rest[0], "-preferredversion") {
+			rank = 1
+		}
+		rest = rest[1:]
+	}
+	obj = strings.TrimSuffix(rest[len(rest)-1], ".json")
+	switch {
+	case len(rest) == 3 && rest[0] == "namespaces":
+		return gr, rest[1], obj, rank, true
+	case len(rest) == 2 && rest[0] == "cluster":
+		return gr, "", obj, rank, true
+	}
+	return "", "", "", 0, false
+}
@@ -0,0 +1,135 @@
+package restore
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestParseBackupEntry(t *testing.T) {
+	cases := []struct {
+		path         string
+		gr, ns, name string
+		rank         int
+		ok           bool
+	}{
+		{"resources/deployments.apps/namespaces/team-a/web.json", "deployments.apps", "team-a", "web", 0, true},
+		{"resources/deployments.apps/v1-preferredversion/namespaces/team-a/web.json", "deployments.apps", "team-a", "web", 1, true},
+		{"resources/namespaces/cluster/team-a.json", "namespaces", "", "team-a", 0, true},
+		{"resources/customresourcedefinitions.apiextensions.k8s.io/v1/cluster/foo.json", "customresourcedefinitions.apiextensions.k8s.io", "", "foo", 2, true},
+		{"metadata/version", "", "", "", 0, false},
+		{"resources/configmaps/namespaces/team-a", "", "", "", 0, false},
+	}
+	for _, c := range cases {
+		gr, ns, name, rank, ok := parseBackupEntry(c.path)
+		if ok != c.ok || gr != c.gr || ns != c.ns || name != c.name || rank != c.rank {
+			t.Errorf("parseBackupEntry(%q) = (%q, %q, %q, %d, %t), want (%q, %q, %q, %d, %t)",
+				c.path, gr, ns, name, rank, ok, c.gr, c.ns, c.name, c.rank, c.ok)
+		}
+	}
+}
+
+func TestReadBackupArchiveAndSelect(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	add := func(name, body string) {
+		t.Helper()
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(body)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("metadata/version", "1")
+	add("resources/configmaps/namespaces/team-a/settings.json",
+		`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings","namespace":"team-a","labels":{"app":"web"}}}`)
+	add("resources/configmaps/v1-preferredversion/namespaces/team-a/settings.json",
+		`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings","namespace":"team-a","labels":{"app":"web"}}}`)
+	add("resources/deployments.apps/namespaces/team-b/api.json",
+		`{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"api","namespace":"team-b"}}`)
+	add("resources/adharplatforms.platform.adhar.io/namespaces/adhar-system/adhar.json",
+		`{"apiVersion":"platform.adhar.io/v1alpha1","kind":"AdharPlatform","metadata":{"name":"adhar","namespace":"adhar-system"}}`)
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := readBackupArchive(&buf)
+	if err != nil {
+		t.Fatalf("readBackupArchive: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 de-duplicated items, got %d", len(items))
+	}
+
+	sel := restoreSelection{Namespaces: []string{"team-a"}, Resources: []string{"ConfigMap"}, Selector: "app=web"}
+	got, err := selectItems(items, sel)
+	if err != nil {
+		t.Fatalf("selectItems: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "settings" {
+		t.Fatalf("expected only team-a/settings, got %+v", got)
+	}
+	if gvr := got[0].GVR(); gvr.Group != "" || gvr.Version != "v1" || gvr.Resource != "configmaps" {
+		t.Errorf("unexpected GVR %v", gvr)
+	}
+
+	cfg, err := selectItems(items, restoreSelection{Resources: adharConfigResources})
+	if err != nil {
+		t.Fatalf("selectItems: %v", err)
+	}
+	if len(cfg) != 1 || cfg[0].Object.GetKind() != "AdharPlatform" {
+		t.Fatalf("expected the AdharPlatform only, got %+v", cfg)
+	}
+}
+
+func TestDiffPaths(t *testing.T) {
+	backup := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "x", "resourceVersion": "1", "labels": map[string]interface{}{"velero.io/backup-name": "b"}},
+		"data":     map[string]interface{}{"a": "1", "b": "2"},
+	}
+	live := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "x", "resourceVersion": "99", "uid": "u"},
+		"data":     map[string]interface{}{"a": "1", "b": "3", "c": "4"},
+		"status":   map[string]interface{}{"ready": true},
+	}
+	got := diffPaths("", comparable(backup), comparable(live))
+	want := []string{"data.b", "data.c"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("diffPaths = %v, want %v", got, want)
+	}
+}
+
+func TestVeleroSpecResolvesResources(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, meta.RESTScopeNamespace)
+
+	sel := restoreSelection{Resources: []string{"Deployment", "configmaps", "deployments.apps"}}
+	spec, err := sel.veleroSpec(conflictSkip, nil, mapper)
+	if err != nil {
+		t.Fatalf("veleroSpec: %v", err)
+	}
+	got := spec["includedResources"].([]interface{})
+	want := []interface{}{"deployments.apps", "configmaps", "deployments.apps"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("includedResources = %v, want %v", got, want)
+	}
+
+	spec, err = (restoreSelection{Resources: []string{"adharplatforms.platform.adhar.io"}}).veleroSpec(conflictSkip, nil, mapper)
+	if err != nil || !reflect.DeepEqual(spec["includedResources"], []interface{}{"adharplatforms.platform.adhar.io"}) {
+		t.Errorf("a group-qualified resource without a CRD must be kept, got %v, %v", spec["includedResources"], err)
+	}
+	if _, err := (restoreSelection{Resources: []string{"Widget"}}).veleroSpec(conflictSkip, nil, mapper); err == nil {
+		t.Error("an unknown kind must be rejected, not passed to Velero")
+	}
+}
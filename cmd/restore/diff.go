@@ -0,0 +1,324 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"adhar-io/adhar/cmd/helpers"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Conflict policies for objects that already exist in the cluster.
+const (
+	conflictSkip            = "skip"
+	conflictOverwrite       = "overwrite"
+	conflictRenameNamespace = "rename-namespace"
+)
+
+// restoreSelection narrows a Velero backup to the objects a user asked for.
+type restoreSelection struct {
+	Namespaces []string
+	// Resources accepts Velero group-resources (deployments.apps), plain
+	// resources (deployments) or kinds (Deployment), case-insensitively.
+	Resources []string
+	Selector  string
+}
+
+// Matches reports whether a backup item falls inside the selection. With a
+// namespace filter, cluster-scoped items are only kept when their resource is
+// listed explicitly.
+func (s restoreSelection) Matches(item backupItem) (bool, error) {
+	resourceListed := len(s.Resources) > 0 && s.matchesResource(item)
+	if len(s.Resources) > 0 && !resourceListed {
+		return false, nil
+	}
+	if len(s.Namespaces) > 0 {
+		if item.Namespace == "" {
+			if !resourceListed {
+				return false, nil
+			}
+		} else if !containsString(s.Namespaces, item.Namespace) {
+			return false, nil
+		}
+	}
+	if s.Selector != "" {
+		sel, err := labels.Parse(s.Selector)
+		if err != nil {
+			return false, fmt.Errorf("invalid label selector %q: %w", s.Selector, err)
+		}
+		if !sel.Matches(labels.Set(item.Object.GetLabels())) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s restoreSelection) matchesResource(item backupItem) bool {
+	resource := strings.SplitN(item.GroupResource, ".", 2)[0]
+	kind := strings.ToLower(item.Object.GetKind())
+	for _, r := range s.Resources {
+		r = strings.ToLower(r)
+		if r == item.GroupResource || r == resource || r == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// veleroSpec converts the selection and conflict policy into Velero Restore
+// spec fields. namespaceMapping is only set for rename-namespace. mapper
+// resolves the selected resources to the names Velero matches on.
+func (s restoreSelection) veleroSpec(policy string, mapping map[string]string, mapper meta.RESTMapper) (map[string]interface{}, error) {
+	spec := map[string]interface{}{}
+	if len(s.Namespaces) > 0 {
+		spec["includedNamespaces"] = toInterfaceSlice(s.Namespaces)
+	}
+	if len(s.Resources) > 0 {
+		resources, err := veleroResources(mapper, s.Resources)
+		if err != nil {
+			return nil, err
+		}
+		spec["includedResources"] = toInterfaceSlice(resources)
+	}
+	if s.Selector != "" {
+		ls, err := metav1.ParseToLabelSelector(s.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", s.Selector, err)
+		}
+		raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ls)
+		if err != nil {
+			return nil, err
+		}
+		spec["labelSelector"] = raw
+	}
+	switch policy {
+	case conflictSkip:
+		spec["existingResourcePolicy"] = "none"
+	case conflictOverwrite:
+		spec["existingResourcePolicy"] = "update"
+	case conflictRenameNamespace:
+		m := map[string]interface{}{}
+		for from, to := range mapping {
+			m[from] = to
+		}
+		spec["namespaceMapping"] = m
+	default:
+		return nil, fmt.Errorf("unknown conflict policy %q (want %s, %s or %s)", policy, conflictSkip, conflictOverwrite, conflictRenameNamespace)
+	}
+	return spec, nil
+}
+
+// veleroResources resolves kinds and singular or plural resource names to the
+// plural, group-qualified resources Velero's includedResources expects:
+// Deployment becomes deployments.apps.
+func veleroResources(mapper meta.RESTMapper, names []string) ([]string, error) {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		gr := schema.ParseGroupResource(strings.ToLower(name))
+		gvr, err := mapper.ResourceFor(gr.WithVersion(""))
+		if err != nil {
+			// Group-qualified resources are already in Velero's form; their
+			// CRDs may only come back with the restore itself
+			if gr.Group != "" {
+				out = append(out, gr.String())
+				continue
+			}
+			return nil, fmt.Errorf("unknown resource %q: %w", name, err)
+		}
+		out = append(out, gvr.GroupResource().String())
+	}
+	return out, nil
+}
+
+// selectItems filters backup contents down to the selection.
+func selectItems(items []backupItem, sel restoreSelection) ([]backupItem, error) {
+	var out []backupItem
+	for _, item := range items {
+		ok, err := sel.Matches(item)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			out = append(out, item)
+		}
+	}
+	return out, nil
+}
+
+// namespaceMapping maps every namespace touched by items to name+suffix.
+func namespaceMapping(items []backupItem, suffix string) map[string]string {
+	m := map[string]string{}
+	for _, item := range items {
+		if item.Namespace != "" {
+			m[item.Namespace] = item.Namespace + suffix
+		}
+	}
+	return m
+}
+
+// Diff actions for a backup item compared with the live cluster.
+const (
+	diffCreate    = "create"
+	diffUpdate    = "update"
+	diffUnchanged = "unchanged"
+)
+
+// itemDiff is the comparison of one backup item against its live counterpart.
+type itemDiff struct {
+	Item   backupItem
+	Target string // namespace/name the item restores to
+	Action string
+	Paths  []string // differing field paths for updates
+}
+
+// diffAgainstLive compares each item with the live object it would restore
+// onto (after namespace mapping).
+func diffAgainstLive(ctx context.Context, dyn dynamic.Interface, items []backupItem, mapping map[string]string) ([]itemDiff, error) {
+	out := make([]itemDiff, 0, len(items))
+	for _, item := range items {
+		ns := item.Namespace
+		if to, ok := mapping[ns]; ok {
+			ns = to
+		}
+		d := itemDiff{Item: item, Target: item.Name}
+		if ns != "" {
+			d.Target = ns + "/" + item.Name
+		}
+
+		var live *unstructured.Unstructured
+		var err error
+		if ns == "" {
+			live, err = dyn.Resource(item.GVR()).Get(ctx, item.Name, metav1.GetOptions{})
+		} else {
+			live, err = dyn.Resource(item.GVR()).Namespace(ns).Get(ctx, item.Name, metav1.GetOptions{})
+		}
+		switch {
+		case k8serrors.IsNotFound(err):
+			d.Action = diffCreate
+		case err != nil:
+			return nil, fmt.Errorf("failed to get live %s %s: %w", item.GroupResource, d.Target, err)
+		default:
+			d.Paths = diffPaths("", comparable(item.Object.Object), comparable(live.Object))
+			d.Action = diffUnchanged
+			if len(d.Paths) > 0 {
+				d.Action = diffUpdate
+			}
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// comparable strips server-populated and restore-irrelevant fields so only
+// user intent is compared.
+func comparable(obj map[string]interface{}) map[string]interface{} {
+	c := runtime.DeepCopyJSON(obj)
+	delete(c, "status")
+	for _, f := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink", "ownerReferences", "namespace"} {
+		unstructured.RemoveNestedField(c, "metadata", f)
+	}
+	unstructured.RemoveNestedField(c, "metadata", "annotations", "kubectl.kubernetes.io/last-applied-configuration")
+	if lbls, found, _ := unstructured.NestedStringMap(c, "metadata", "labels"); found {
+		for k := range lbls {
+			if strings.HasPrefix(k, "velero.io/") {
+				delete(lbls, k)
+			}
+		}
+		_ = unstructured.SetNestedStringMap(c, lbls, "metadata", "labels")
+	}
+	for _, f := range []string{"labels", "annotations"} {
+		if m, found, _ := unstructured.NestedMap(c, "metadata", f); found && len(m) == 0 {
+			unstructured.RemoveNestedField(c, "metadata", f)
+		}
+	}
+	return c
+}
+
+// diffPaths returns the dotted paths at which a and b differ. Maps are walked
+// recursively; slices and scalars are compared as a whole.
+func diffPaths(prefix string, a, b map[string]interface{}) []string {
+	keys := map[string]struct{}{}
+	for k := range a {
+		keys[k] = struct{}{}
+	}
+	for k := range b {
+		keys[k] = struct{}{}
+	}
+	var out []string
+	for k := range keys {
+		p := k
+		if prefix != "" {
+			p = prefix + "." + k
+		}
+		av, aok := a[k]
+		bv, bok := b[k]
+		am, aIsMap := av.(map[string]interface{})
+		bm, bIsMap := bv.(map[string]interface{})
+		switch {
+		case aok && bok && aIsMap && bIsMap:
+			out = append(out, diffPaths(p, am, bm)...)
+		case !reflect.DeepEqual(av, bv) || aok != bok:
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// printDiff renders the preview table and returns the count per action.
+func printDiff(diffs []itemDiff, policy string, detailed bool) map[string]int {
+	counts := map[string]int{}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%-10s %-34s %s\n", "ACTION", "RESOURCE", "TARGET"))
+	b.WriteString(strings.Repeat("─", 90) + "\n")
+	for _, d := range diffs {
+		counts[d.Action]++
+		if d.Action == diffUnchanged && !detailed {
+			continue
+		}
+		b.WriteString(fmt.Sprintf("%-10s %-34s %s\n", diffLabel(d.Action, policy), truncate(d.Item.GroupResource, 32), d.Target))
+		if detailed {
+			for _, p := range firstN(d.Paths, 10) {
+				b.WriteString(fmt.Sprintf("%-10s   ~ %s\n", "", p))
+			}
+		}
+	}
+	fmt.Print(b.String())
+	fmt.Println(helpers.CreateMuted(fmt.Sprintf("   %d to create, %d differ from live, %d unchanged",
+		counts[diffCreate], counts[diffUpdate], counts[diffUnchanged])))
+	return counts
+}
+
+// diffLabel shows what the conflict policy will actually do with an item.
+func diffLabel(action, policy string) string {
+	switch {
+	case action == diffCreate:
+		return "+ create"
+	case action == diffUpdate && policy == conflictOverwrite:
+		return "~ update"
+	case action == diffUpdate:
+		return "! skip"
+	default:
+		return "= same"
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
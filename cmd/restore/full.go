@@ -1,117 +1,235 @@
 package restore
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
 
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
 )
 
 var (
 	fullCmd = &cobra.Command{
-		Use:   "full [backup-path]",
+		Use:   "full [backup-name]",
 		Short: "Full platform restoration",
-		Long: `Perform a complete restoration of the Adhar platform from a backup.
-This includes all components, data, configurations, and applications.`,
+		Long: `Perform an ordered restoration of the Adhar platform from a Velero backup.
+
+Stages run one after another and each is verified before the next starts:
+  1. crds        CustomResourceDefinitions (waits for Established)
+  2. foundation  Gitea, ArgoCD and Keycloak in adhar-system (waits for Ready)
+  3. databases   CloudNativePG clusters recovered from their latest CNPG backup
+  4. workloads   Everything else, including volumes (waits for Ready)
+
+ArgoCD auto-sync is paused while the restore runs — again right after each
+stage's resources land, before they are verified — and re-enabled at the end.
+If a stage fails, auto-sync stays paused so the GitOps controller does not
+overwrite a half-restored platform; re-enable it with --resume-sync.
+
+Examples:
+  adhar restore full nightly-20250101 --dry-run
+  adhar restore full nightly-20250101
+  adhar restore full --resume-sync`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: runFullRestore,
 	}
 
 	// Full restore specific flags
 	skipValidation bool
-	parallelJobs   int
+	stageTimeout   time.Duration
+	keepSyncPaused bool
+	resumeSyncOnly bool
 )
 
 func init() {
 	fullCmd.Flags().BoolVarP(&skipValidation, "skip-validation", "s", false, "Skip backup validation before restore")
-	fullCmd.Flags().IntVarP(&parallelJobs, "parallel", "p", 4, "Number of parallel restore jobs")
+	fullCmd.Flags().DurationVar(&stageTimeout, "stage-timeout", 20*time.Minute, "Maximum time to wait for each stage to restore and verify")
+	fullCmd.Flags().BoolVar(&keepSyncPaused, "keep-sync-paused", false, "Leave ArgoCD auto-sync paused after a successful restore")
+	fullCmd.Flags().BoolVar(&resumeSyncOnly, "resume-sync", false, "Only re-enable ArgoCD auto-sync paused by an earlier restore")
 }
 
 func runFullRestore(cmd *cobra.Command, args []string) error {
+	dyn, err := getDynamicClient()
+	if err != nil {
+		return unreachable(err)
+	}
+	ctx := context.Background()
+
+	if resumeSyncOnly {
+		return resumeSync(ctx, dyn)
+	}
+
 	if len(args) > 0 {
 		backupPath = args[0]
 	}
-
 	if backupPath == "" {
-		return fmt.Errorf("backup path is required. Use --backup flag or provide as argument")
+		return fmt.Errorf("backup name is required. Use --backup flag or provide as argument")
 	}
 
-	fmt.Printf("🔄 Starting full platform restoration from: %s\n", backupPath)
-	fmt.Printf("📁 Restore directory: %s\n", restoreDir)
-	fmt.Printf("🔍 Dry run: %t\n", dryRun)
-	fmt.Printf("⚡ Parallel jobs: %d\n", parallelJobs)
-
-	// Validate backup if not skipped
-	if !skipValidation && !validateOnly {
-		fmt.Println("\n🔍 Validating backup...")
-		if err := validateBackup(backupPath); err != nil {
-			if !forceRestore {
-				return fmt.Errorf("backup validation failed: %w", err)
-			}
-			fmt.Printf("⚠️  Backup validation failed but continuing due to --force: %v\n", err)
-		} else {
-			fmt.Println("✅ Backup validation passed")
+	fmt.Println(helpers.TitleStyle.Render("🔄 Full Platform Restore"))
+	fmt.Printf("📦 Backup:        %s\n", backupPath)
+	fmt.Printf("⏱️  Stage timeout: %s\n", stageTimeout)
+
+	var backup *unstructured.Unstructured
+	if skipValidation && !validateOnly {
+		fmt.Println(helpers.CreateWarning("⚠️  Skipping backup validation (--skip-validation)"))
+		// The Backup is still read, when it exists, so the plan can show
+		// what it contains
+		backup, _ = dyn.Resource(backupGVR).Namespace(veleroNamespace).Get(ctx, backupPath, metav1.GetOptions{})
+	} else if backup, err = validateBackup(ctx, dyn, backupPath); err != nil {
+		if !forceRestore {
+			return fmt.Errorf("backup validation failed: %w", err)
 		}
+		fmt.Println(helpers.CreateWarning(fmt.Sprintf("⚠️  Backup validation failed but continuing due to --force: %v", err)))
+	} else {
+		fmt.Println(helpers.CreateSuccess("✅ Backup validation passed"))
 	}
 
 	if validateOnly {
-		fmt.Println("✅ Validation completed successfully")
 		return nil
 	}
+	if backup == nil {
+		// --force or --skip-validation without a readable Backup object:
+		// synthesise one so the plan still has a name to restore from.
+		backup = &unstructured.Unstructured{Object: map[string]interface{}{}}
+		backup.SetName(backupPath)
+	}
+
+	run := newFullRestoreRun(dyn, backup, stageTimeout)
+	stages := fullRestoreStages(run)
 
 	if dryRun {
-		fmt.Println("\n🔍 DRY RUN - Showing what would be restored:")
-		return showRestorePlan(backupPath)
+		return showRestorePlan(ctx, run, stages)
 	}
 
-	// Create restore directory
-	if err := os.MkdirAll(restoreDir, 0755); err != nil {
-		return fmt.Errorf("failed to create restore directory: %w", err)
+	defs := make([]helpers.StageDef, len(stages))
+	for i, s := range stages {
+		defs[i] = helpers.StageDef{Label: s.Name, Detail: s.Detail}
+	}
+	tracker := helpers.NewStageTracker(os.Stderr, "Restoring from "+run.backup, defs, true)
+
+	seq := restoreSequence{
+		stages: stages,
+		run: func(ctx context.Context, s restoreStage) error {
+			return run.runStage(ctx, s, forceRestore)
+		},
+		verify: func(ctx context.Context, s restoreStage) error {
+			if s.Verify == nil {
+				return nil
+			}
+			return s.Verify(ctx, run, run.restored[s.Name])
+		},
+		pause: func(ctx context.Context) error {
+			_, err := pauseAutoSync(ctx, dyn)
+			return err
+		},
+	}
+	if !keepSyncPaused {
+		seq.resume = func(ctx context.Context) error { return resumeSync(ctx, dyn) }
 	}
 
-	fmt.Println("\n🚀 Starting restoration process...")
-
-	// TODO: Implement actual restoration logic
-	// This would typically involve:
-	// 1. Extracting backup contents
-	// 2. Restoring Kubernetes resources
-	// 3. Restoring persistent volumes
-	// 4. Restoring databases
-	// 5. Restoring configurations
-	// 6. Verifying restoration
-
-	fmt.Println("✅ Full platform restoration completed successfully!")
-	fmt.Printf("📦 Platform restored to: %s\n", restoreDir)
+	fmt.Println("⏸️  Pausing ArgoCD auto-sync for the duration of the restore")
+	tracker.Start()
+	if err := seq.execute(ctx, tracker); err != nil {
+		var stageErr *stageError
+		if errors.As(err, &stageErr) {
+			fmt.Println(helpers.CreateMuted("   ArgoCD auto-sync remains paused; re-enable with: adhar restore full --resume-sync"))
+		}
+		return err
+	}
+	if keepSyncPaused {
+		fmt.Println(helpers.CreateMuted("   ArgoCD auto-sync left paused; re-enable with: adhar restore full --resume-sync"))
+	}
 
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Platform restored from backup %q", run.backup)))
+	fmt.Println(helpers.CreateMuted("   Restores created: adhar restore list (label " + restoreRunLabel + "=" + run.runID + ")"))
 	return nil
 }
 
-func validateBackup(backupPath string) error {
-	// Check if backup exists
-	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
-		return fmt.Errorf("backup not found: %s", backupPath)
+// resumeSync re-enables ArgoCD auto-sync paused by a restore and reports it.
+func resumeSync(ctx context.Context, dyn dynamic.Interface) error {
+	resumed, err := resumeAutoSync(ctx, dyn)
+	if err != nil {
+		return fmt.Errorf("failed to resume ArgoCD auto-sync: %w", err)
 	}
+	if len(resumed) == 0 {
+		fmt.Println(helpers.CreateMuted("   No ArgoCD applications had auto-sync paused"))
+		return nil
+	}
+	fmt.Printf("▶️  Re-enabled auto-sync on %d ArgoCD application(s)\n", len(resumed))
+	return nil
+}
 
-	// TODO: Implement actual backup validation
-	// This would typically involve:
-	// 1. Checking file integrity
-	// 2. Verifying backup format
-	// 3. Checking backup contents
-	// 4. Validating backup metadata
+// validateBackup checks that the Velero Backup exists and completed cleanly.
+func validateBackup(ctx context.Context, dyn dynamic.Interface, name string) (*unstructured.Unstructured, error) {
+	cctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
 
-	return nil
+	obj, err := dyn.Resource(backupGVR).Namespace(veleroNamespace).Get(cctx, name, metav1.GetOptions{})
+	if err != nil {
+		if crdMissing(err) {
+			return nil, fmt.Errorf("Velero Backup CRD not installed (velero not present in the cluster)")
+		}
+		return nil, fmt.Errorf("failed to get backup %q: %w", name, err)
+	}
+	if phase := nestedString(obj.Object, "status", "phase"); phase != "Completed" {
+		return obj, fmt.Errorf("backup %q is not Completed (phase: %s)", name, phase)
+	}
+	if expiry := nestedString(obj.Object, "status", "expiration"); expiry != "" {
+		if t, err := time.Parse(time.RFC3339, expiry); err == nil && t.Before(time.Now()) {
+			return obj, fmt.Errorf("backup %q expired at %s", name, expiry)
+		}
+	}
+	return obj, nil
 }
 
-func showRestorePlan(backupPath string) error {
-	fmt.Println("📋 Restore Plan:")
-	fmt.Println("  • Platform components: All")
-	fmt.Println("  • Applications: All")
-	fmt.Println("  • Databases: All")
-	fmt.Println("  • Configurations: All")
-	fmt.Println("  • Persistent volumes: All")
-	fmt.Println("  • Secrets: All (encrypted)")
-	fmt.Println("  • Estimated time: 15-30 minutes")
-	fmt.Println("  • Estimated space: 5-10 GB")
+// showRestorePlan prints the stages, the Velero Restore each would create and
+// the Applications whose auto-sync would be paused, without changing anything.
+func showRestorePlan(ctx context.Context, run *restoreRun, stages []restoreStage) error {
+	fmt.Println("\n🔍 DRY RUN - Restore plan:")
+
+	var b strings.Builder
+	for i, s := range stages {
+		b.WriteString(fmt.Sprintf("%d. %-11s %s\n", i+1, s.Name, s.Detail))
+		switch {
+		case s.Spec != nil:
+			b.WriteString(fmt.Sprintf("   Restore %s\n", run.restoreName(s.Name)))
+			for _, key := range []string{"includedNamespaces", "excludedNamespaces", "includedResources", "excludedResources"} {
+				if vals, _, _ := unstructured.NestedStringSlice(s.Spec, key); len(vals) > 0 {
+					b.WriteString(fmt.Sprintf("     %-19s %s\n", key+":", strings.Join(vals, ", ")))
+				}
+			}
+		case s.Name == "databases":
+			targets, err := latestCNPGBackups(ctx, run.dyn)
+			if err != nil {
+				return err
+			}
+			if len(targets) == 0 {
+				b.WriteString("   CNPG backup catalog restored first; clusters are discovered from it\n")
+			}
+			for _, t := range targets {
+				b.WriteString(fmt.Sprintf("     %s/%s ← backup %s\n", t.Namespace, t.Cluster, t.Backup))
+			}
+		}
+	}
 
+	apps, err := run.dyn.Resource(argoApplicationGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err == nil {
+		n := 0
+		for _, item := range apps.Items {
+			if _, found, _ := unstructured.NestedMap(item.Object, "spec", "syncPolicy", "automated"); found {
+				n++
+			}
+		}
+		b.WriteString(fmt.Sprintf("\nArgoCD auto-sync paused during restore: %d application(s)", n))
+	}
+	fmt.Println(helpers.BorderStyle.Width(90).Render(strings.TrimRight(b.String(), "\n")))
 	return nil
 }
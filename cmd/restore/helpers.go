@@ -8,9 +8,12 @@ import (
 	"adhar-io/adhar/cmd/helpers"
 	"adhar-io/adhar/platform/k8s"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
 )
 
 // veleroNamespace is where Velero CRs live.
@@ -26,6 +29,15 @@ func getDynamicClient() (dynamic.Interface, error) {
 	return k8s.GetDynamicClient()
 }
 
+// getRESTMapper returns a RESTMapper backed by the cluster's discovery API.
+func getRESTMapper() (meta.RESTMapper, error) {
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return nil, err
+	}
+	return restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(cs.Discovery())), nil
+}
+
 // unreachable wraps a client-construction error with a friendly message.
 func unreachable(err error) error {
 	fmt.Println(helpers.ErrorStyle.Render("❌ Could not connect to the cluster"))
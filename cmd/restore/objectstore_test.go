@@ -0,0 +1,177 @@
+package restore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"adhar-io/adhar/platform/utils/objectstore"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestParseAWSCredentials(t *testing.T) {
+	ini := `
+[default]
+aws_access_key_id = minio
+aws_secret_access_key = minio123
+
+[profile backup]
+aws_access_key_id=AKIA
+aws_secret_access_key=secret
+aws_session_token=token
+`
+	creds, err := parseAWSCredentials(ini, "")
+	if err != nil || creds.AccessKeyID != "minio" || creds.SecretAccessKey != "minio123" {
+		t.Fatalf("default profile: got %+v, %v", creds, err)
+	}
+	creds, err = parseAWSCredentials(ini, "backup")
+	if err != nil || creds.AccessKeyID != "AKIA" || creds.SessionToken != "token" {
+		t.Fatalf("named profile: got %+v, %v", creds, err)
+	}
+	if _, err := parseAWSCredentials(ini, "missing"); err == nil {
+		t.Fatal("expected an error for a missing profile")
+	}
+}
+
+func TestObjectStoreBackupKey(t *testing.T) {
+	store := &objectStore{Prefix: "/adhar/"}
+	if key := store.backupKey("nightly", "nightly.tar.gz"); key != "adhar/backups/nightly/nightly.tar.gz" {
+		t.Fatalf("backupKey = %q", key)
+	}
+	store.Prefix = ""
+	if key := store.backupKey("nightly", "nightly.tar.gz"); key != "backups/nightly/nightly.tar.gz" {
+		t.Fatalf("backupKey without prefix = %q", key)
+	}
+}
+
+func TestObjectStoreGetSignsAndHashes(t *testing.T) {
+	payload := []byte("backup-bytes")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minio/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/velero/backups/b/b.tar.gz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"7a1ff6bbf3a2a2ae6ffc81c1a7cb7c8b"`)
+		_, _ = w.Write(payload)
+	}))
+	defer srv.Close()
+
+	store := &objectStore{Store: objectstore.Store{
+		Endpoint: srv.URL, Bucket: "velero", Region: "us-east-1", PathStyle: true,
+		Creds: aws.Credentials{AccessKeyID: "minio", SecretAccessKey: "minio123"},
+	}}
+	body, etag, err := store.Get(context.Background(), store.backupKey("b", "b.tar.gz"))
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer body.Close()
+	hr := newHashingReader(body)
+	got, _ := io.ReadAll(hr)
+	if !bytes.Equal(got, payload) || hr.n != int64(len(payload)) {
+		t.Fatalf("unexpected body %q", got)
+	}
+	if etag != "7a1ff6bbf3a2a2ae6ffc81c1a7cb7c8b" {
+		t.Errorf("etag = %q", etag)
+	}
+	if len(hr.SHA256()) != 64 || len(hr.MD5()) != 32 {
+		t.Errorf("unexpected digests %s / %s", hr.SHA256(), hr.MD5())
+	}
+
+	if _, _, err := store.Get(context.Background(), store.backupKey("missing", "missing.tar.gz")); !errors.Is(err, objectstore.ErrNotFound) {
+		t.Errorf("expected not-found error, got %v", err)
+	}
+}
+
+func TestMissingFromArchive(t *testing.T) {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("apps/v1")
+	obj.SetKind("Deployment")
+	items := []backupItem{{GroupResource: "deployments.apps", Namespace: "team-a", Name: "web", Object: obj}}
+	listed := map[string][]string{
+		"apps/v1/Deployment": {"team-a/web", "team-a/api"},
+	}
+	missing := missingFromArchive(listed, items)
+	if len(missing) != 1 || missing[0] != "apps/v1/Deployment: team-a/api" {
+		t.Fatalf("missingFromArchive = %v", missing)
+	}
+}
+
+// failingReader returns data and then a non-EOF error, like a dropped download.
+type failingReader struct{ data []byte }
+
+func (f *failingReader) Read(p []byte) (int, error) {
+	if len(f.data) == 0 {
+		return 0, errors.New("connection reset")
+	}
+	n := copy(p, f.data)
+	f.data = f.data[n:]
+	return n, nil
+}
+
+func TestChecksumsNotPinnedAfterPartialRead(t *testing.T) {
+	hr := newHashingReader(&failingReader{data: []byte("partial archive")})
+	card := &scorecard{}
+	_, ok := verifyArchive(card, hr)
+	if ok {
+		t.Fatal("verifyArchive accepted an archive whose read failed")
+	}
+	readErr := hr.Drain()
+	if readErr == nil {
+		t.Fatal("Drain reported a complete read after a read error")
+	}
+
+	backup := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	backup.SetName("nightly")
+	// A nil client panics if verifyChecksums tries to pin the partial hash.
+	verifyChecksums(context.Background(), nil, card, backup, hr, readErr, ok, "", true)
+	for _, c := range card.checks {
+		if c.Status == checkPass {
+			t.Errorf("check %s passed after a partial read: %s", c.Name, c.Detail)
+		}
+	}
+}
+
+func TestChecksumsNotPinnedForUnreadableArchive(t *testing.T) {
+	hr := newHashingReader(strings.NewReader("not a tarball"))
+	card := &scorecard{}
+	items, ok := verifyArchive(card, hr)
+	if ok || items != nil {
+		t.Fatal("verifyArchive accepted a non-archive")
+	}
+	if err := hr.Drain(); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+
+	backup := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	backup.SetName("nightly")
+	verifyChecksums(context.Background(), nil, card, backup, hr, nil, ok, "", true)
+	if last := card.checks[len(card.checks)-1]; last.Name != "sha256" || last.Status != checkSkip {
+		t.Errorf("expected the sha256 check to be skipped, got %+v", last)
+	}
+}
+
+func TestChecksumsNotPinnedWithoutFlag(t *testing.T) {
+	hr := newHashingReader(strings.NewReader("archive"))
+	if err := hr.Drain(); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+	backup := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	backup.SetName("nightly")
+	card := &scorecard{}
+	// A nil client panics if verifyChecksums writes to the Backup.
+	verifyChecksums(context.Background(), nil, card, backup, hr, nil, true, "", false)
+	if last := card.checks[len(card.checks)-1]; last.Name != "sha256" || last.Status != checkSkip {
+		t.Errorf("expected the sha256 check to be skipped without --pin, got %+v", last)
+	}
+}
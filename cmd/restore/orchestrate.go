@@ -0,0 +1,739 @@
+package restore
+
+// orchestrate.go drives `adhar restore full` as an ordered sequence of Velero
+// Restores: CRDs → foundation (Gitea, ArgoCD, Keycloak in adhar-system) →
+// CloudNativePG databases (recovered from their own CNPG backups) → workloads.
+// ArgoCD auto-sync is paused for the duration so the GitOps controller does not
+// fight objects that are half-way restored, and each stage is verified before
+// the next one starts.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"adhar-io/adhar/globals"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// restoreRunLabel groups every Restore created by a single orchestrated run.
+	restoreRunLabel = "adhar.io/restore-run"
+	// pausedSyncAnnotation stores the original spec.syncPolicy.automated block
+	// of an ArgoCD Application while a restore has auto-sync paused.
+	pausedSyncAnnotation = "adhar.io/restore-paused-sync"
+	// veleroRestoreNameLabel is set by Velero on every object it restores.
+	veleroRestoreNameLabel = "velero.io/restore-name"
+
+	restorePollInterval = 5 * time.Second
+)
+
+var (
+	backupGVR = schema.GroupVersionResource{
+		Group: "velero.io", Version: "v1", Resource: "backups",
+	}
+	argoApplicationGVR = schema.GroupVersionResource{
+		Group: "argoproj.io", Version: "v1alpha1", Resource: "applications",
+	}
+	cnpgClusterGVR = schema.GroupVersionResource{
+		Group: "postgresql.cnpg.io", Version: "v1", Resource: "clusters",
+	}
+	cnpgBackupGVR = schema.GroupVersionResource{
+		Group: "postgresql.cnpg.io", Version: "v1", Resource: "backups",
+	}
+	crdGVR = schema.GroupVersionResource{
+		Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions",
+	}
+	deploymentGVR  = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	statefulSetGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}
+	configMapGVR   = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+)
+
+// foundationComponents are the platform services every other stage depends on.
+// They all run in the shared platform namespace (ADR-0011).
+var foundationComponents = []string{"gitea", "argocd", "keycloak"}
+
+// cnpgResources are restored by the database stage only; the other stages
+// exclude them so Velero never recreates a Cluster with an empty initdb.
+var cnpgResources = []string{
+	"clusters.postgresql.cnpg.io",
+	"backups.postgresql.cnpg.io",
+	"scheduledbackups.postgresql.cnpg.io",
+}
+
+// restoreStage is one ordered step of a full-platform restore.
+type restoreStage struct {
+	Name   string
+	Detail string
+	// Spec is the Velero Restore spec for the stage (backupName is filled in
+	// by the runner). Stages with a custom Run leave it nil.
+	Spec map[string]interface{}
+	// Run overrides the default "create Restore and wait" behaviour.
+	Run func(ctx context.Context, r *restoreRun) error
+	// Verify checks the stage converged before the next one starts.
+	Verify func(ctx context.Context, r *restoreRun, restoreName string) error
+}
+
+// restoreRun carries state shared by every Restore one command creates.
+type restoreRun struct {
+	dyn          dynamic.Interface
+	backup       string
+	runID        string
+	namespaces   []string // namespaces covered by the backup; nil means all
+	stageTimeout time.Duration
+	// restored records the Restore name created for each stage.
+	restored map[string]string
+}
+
+func newRestoreRun(dyn dynamic.Interface, backup, prefix string, stageTimeout time.Duration) *restoreRun {
+	return &restoreRun{
+		dyn:          dyn,
+		backup:       backup,
+		runID:        prefix + "-" + time.Now().Format("20060102-150405"),
+		stageTimeout: stageTimeout,
+		restored:     map[string]string{},
+	}
+}
+
+func newFullRestoreRun(dyn dynamic.Interface, backup *unstructured.Unstructured, stageTimeout time.Duration) *restoreRun {
+	r := newRestoreRun(dyn, backup.GetName(), "full", stageTimeout)
+	nss, _, _ := unstructured.NestedStringSlice(backup.Object, "spec", "includedNamespaces")
+	if !(len(nss) == 1 && nss[0] == "*") {
+		r.namespaces = nss
+	}
+	return r
+}
+
+// fullRestoreStages returns the ordered stages for a full-platform restore.
+func fullRestoreStages(r *restoreRun) []restoreStage {
+	workloadNamespaces := []interface{}{"*"}
+	if len(r.namespaces) > 0 {
+		workloadNamespaces = toInterfaceSlice(r.namespaces)
+	}
+	return []restoreStage{
+		{
+			Name:   "crds",
+			Detail: "CustomResourceDefinitions",
+			Spec: map[string]interface{}{
+				"includedResources":       []interface{}{"customresourcedefinitions.apiextensions.k8s.io"},
+				"includeClusterResources": true,
+			},
+			Verify: verifyCRDsEstablished,
+		},
+		{
+			Name:   "foundation",
+			Detail: "Gitea, ArgoCD and Keycloak in " + globals.AdharSystemNamespace,
+			Spec: map[string]interface{}{
+				"includedNamespaces":      []interface{}{globals.AdharSystemNamespace},
+				"excludedResources":       toInterfaceSlice(cnpgResources),
+				"includeClusterResources": false,
+			},
+			Verify: verifyFoundation,
+		},
+		{
+			Name:   "databases",
+			Detail: "CloudNativePG clusters recovered from their latest backup",
+			Run:    runDatabaseStage,
+			Verify: verifyDatabases,
+		},
+		{
+			Name:   "workloads",
+			Detail: "Remaining namespaced resources and volumes",
+			Spec: map[string]interface{}{
+				"includedNamespaces": workloadNamespaces,
+				"excludedNamespaces": []interface{}{globals.AdharSystemNamespace, veleroNamespace},
+				"excludedResources": toInterfaceSlice(append([]string{
+					"customresourcedefinitions.apiextensions.k8s.io",
+				}, cnpgResources...)),
+			},
+			Verify: verifyWorkloads,
+		},
+	}
+}
+
+// restoreName builds the Restore name for a stage. Names stay well under the
+// 63-character label limit Velero applies to velero.io/restore-name.
+func (r *restoreRun) restoreName(stage string) string {
+	return r.runID + "-" + stage
+}
+
+// createRestore creates a Velero Restore for the backup with the given spec.
+func (r *restoreRun) createRestore(ctx context.Context, name string, spec map[string]interface{}) error {
+	full := runtime.DeepCopyJSON(spec)
+	full["backupName"] = r.backup
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "velero.io/v1",
+		"kind":       "Restore",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": veleroNamespace,
+			"labels": map[string]interface{}{
+				"adhar.io/managed-by": "adhar-cli",
+				restoreRunLabel:       r.runID,
+			},
+		},
+		"spec": full,
+	}}
+	if _, err := r.dyn.Resource(restoreGVR).Namespace(veleroNamespace).Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+		if crdMissing(err) {
+			return fmt.Errorf("Velero Restore CRD not installed (velero not present in the cluster)")
+		}
+		return fmt.Errorf("failed to create restore %q: %w", name, err)
+	}
+	return nil
+}
+
+// waitForRestore polls a Restore until it reaches a terminal phase. Only
+// Completed is treated as success unless allowPartial is set.
+func (r *restoreRun) waitForRestore(ctx context.Context, name string, allowPartial bool) error {
+	cctx, cancel := context.WithTimeout(ctx, r.stageTimeout)
+	defer cancel()
+
+	for {
+		obj, err := r.dyn.Resource(restoreGVR).Namespace(veleroNamespace).Get(cctx, name, metav1.GetOptions{})
+		if err == nil {
+			phase := nestedString(obj.Object, "status", "phase")
+			switch phase {
+			case "Completed":
+				return nil
+			case "PartiallyFailed":
+				if allowPartial {
+					return nil
+				}
+				return fmt.Errorf("restore %q partially failed (%d errors, %d warnings); inspect with `velero restore describe %s`",
+					name, countNested(obj.Object, "status", "errors"), countNested(obj.Object, "status", "warnings"), name)
+			case "Failed", "FailedValidation":
+				msg := nestedString(obj.Object, "status", "failureReason")
+				if errs, _, _ := unstructured.NestedStringSlice(obj.Object, "status", "validationErrors"); len(errs) > 0 {
+					msg = strings.Join(errs, "; ")
+				}
+				return fmt.Errorf("restore %q %s: %s", name, phase, msg)
+			}
+		} else if !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get restore %q: %w", name, err)
+		}
+
+		select {
+		case <-cctx.Done():
+			return fmt.Errorf("timed out after %s waiting for restore %q", r.stageTimeout, name)
+		case <-time.After(restorePollInterval):
+		}
+	}
+}
+
+// runStage executes a single stage: either its custom Run, or a Restore built
+// from its Spec followed by a wait.
+func (r *restoreRun) runStage(ctx context.Context, s restoreStage, allowPartial bool) error {
+	if s.Run != nil {
+		return s.Run(ctx, r)
+	}
+	name := r.restoreName(s.Name)
+	if err := r.createRestore(ctx, name, s.Spec); err != nil {
+		return err
+	}
+	r.restored[s.Name] = name
+	return r.waitForRestore(ctx, name, allowPartial)
+}
+
+// stageProgress is the part of helpers.StageTracker a restore sequence
+// reports to.
+type stageProgress interface {
+	Activate(i int)
+	Done(i int)
+	Fail(i int)
+	Stop()
+}
+
+// restoreSequence orders a full restore around ArgoCD auto-sync: sync is
+// paused before the first stage and again as soon as each stage's Restore
+// finishes — before Verify waits for the restored components — so an
+// Application brought back with auto-sync on is paused before ArgoCD comes up
+// to act on it. Sync is resumed only after every stage verified.
+type restoreSequence struct {
+	stages []restoreStage
+	run    func(ctx context.Context, s restoreStage) error
+	verify func(ctx context.Context, s restoreStage) error
+	pause  func(ctx context.Context) error
+	// resume is nil when auto-sync should stay paused after the restore.
+	resume func(ctx context.Context) error
+}
+
+// execute runs the sequence, reporting stage progress and stopping progress
+// before resuming sync. On failure auto-sync stays paused.
+func (q restoreSequence) execute(ctx context.Context, progress stageProgress) error {
+	if err := q.pause(ctx); err != nil {
+		progress.Stop()
+		return fmt.Errorf("failed to pause ArgoCD auto-sync: %w", err)
+	}
+	for i, s := range q.stages {
+		progress.Activate(i)
+		err := q.run(ctx, s)
+		if err == nil {
+			// The stage may have brought back Applications with auto-sync on.
+			err = q.pause(ctx)
+		}
+		if err == nil {
+			err = q.verify(ctx, s)
+		}
+		if err != nil {
+			progress.Fail(i)
+			progress.Stop()
+			return &stageError{stage: s.Name, err: err}
+		}
+		progress.Done(i)
+	}
+	progress.Stop()
+	if q.resume != nil {
+		return q.resume(ctx)
+	}
+	return nil
+}
+
+// stageError reports the stage a restore sequence failed in.
+type stageError struct {
+	stage string
+	err   error
+}
+
+func (e *stageError) Error() string { return fmt.Sprintf("stage %q failed: %v", e.stage, e.err) }
+func (e *stageError) Unwrap() error { return e.err }
+
+// pollUntil calls check until it reports done, errors, or the stage timeout
+// elapses. The last "not ready" reason is included in the timeout error.
+func (r *restoreRun) pollUntil(ctx context.Context, what string, check func(context.Context) (bool, string, error)) error {
+	cctx, cancel := context.WithTimeout(ctx, r.stageTimeout)
+	defer cancel()
+
+	reason := ""
+	for {
+		done, why, err := check(cctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		reason = why
+		select {
+		case <-cctx.Done():
+			return fmt.Errorf("timed out after %s waiting for %s: %s", r.stageTimeout, what, reason)
+		case <-time.After(restorePollInterval):
+		}
+	}
+}
+
+// verifyCRDsEstablished waits until every restored CRD reports Established.
+func verifyCRDsEstablished(ctx context.Context, r *restoreRun, restoreName string) error {
+	return r.pollUntil(ctx, "CRDs to be established", func(ctx context.Context) (bool, string, error) {
+		list, err := r.dyn.Resource(crdGVR).List(ctx, metav1.ListOptions{
+			LabelSelector: veleroRestoreNameLabel + "=" + restoreName,
+		})
+		if err != nil {
+			return false, "", fmt.Errorf("failed to list CRDs: %w", err)
+		}
+		var pending []string
+		for _, crd := range list.Items {
+			if !conditionTrue(crd.Object, "Established") {
+				pending = append(pending, crd.GetName())
+			}
+		}
+		return len(pending) == 0, "not established: " + strings.Join(pending, ", "), nil
+	})
+}
+
+// verifyFoundation waits until the Gitea, ArgoCD and Keycloak workloads in the
+// platform namespace are fully ready.
+func verifyFoundation(ctx context.Context, r *restoreRun, _ string) error {
+	return r.pollUntil(ctx, "foundation components", func(ctx context.Context) (bool, string, error) {
+		var pending []string
+		for _, component := range foundationComponents {
+			ready, found, err := componentReady(ctx, r.dyn, globals.AdharSystemNamespace, component)
+			if err != nil {
+				return false, "", err
+			}
+			if !found {
+				pending = append(pending, component+" (not found)")
+			} else if !ready {
+				pending = append(pending, component)
+			}
+		}
+		return len(pending) == 0, "not ready: " + strings.Join(pending, ", "), nil
+	})
+}
+
+// verifyWorkloads waits until every Deployment and StatefulSet restored by the
+// workload stage is ready.
+func verifyWorkloads(ctx context.Context, r *restoreRun, restoreName string) error {
+	selector := veleroRestoreNameLabel + "=" + restoreName
+	return r.pollUntil(ctx, "restored workloads", func(ctx context.Context) (bool, string, error) {
+		var pending []string
+		for _, gvr := range []schema.GroupVersionResource{deploymentGVR, statefulSetGVR} {
+			list, err := r.dyn.Resource(gvr).List(ctx, metav1.ListOptions{LabelSelector: selector})
+			if err != nil {
+				return false, "", fmt.Errorf("failed to list %s: %w", gvr.Resource, err)
+			}
+			for _, item := range list.Items {
+				if !workloadReady(item.Object) {
+					pending = append(pending, item.GetNamespace()+"/"+item.GetName())
+				}
+			}
+		}
+		return len(pending) == 0, fmt.Sprintf("%d not ready (%s)", len(pending), strings.Join(firstN(pending, 5), ", ")), nil
+	})
+}
+
+// componentReady reports whether every Deployment/StatefulSet in namespace
+// whose name contains component is ready, and whether any was found at all.
+func componentReady(ctx context.Context, dyn dynamic.Interface, namespace, component string) (ready, found bool, err error) {
+	ready = true
+	for _, gvr := range []schema.GroupVersionResource{deploymentGVR, statefulSetGVR} {
+		list, err := dyn.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false, false, fmt.Errorf("failed to list %s in %s: %w", gvr.Resource, namespace, err)
+		}
+		for _, item := range list.Items {
+			if !strings.Contains(item.GetName(), component) {
+				continue
+			}
+			found = true
+			if !workloadReady(item.Object) {
+				ready = false
+			}
+		}
+	}
+	return ready && found, found, nil
+}
+
+// workloadReady reports whether a Deployment or StatefulSet has all desired
+// replicas ready.
+func workloadReady(obj map[string]interface{}) bool {
+	desired, found, _ := unstructured.NestedInt64(obj, "spec", "replicas")
+	if !found {
+		desired = 1
+	}
+	ready, _, _ := unstructured.NestedInt64(obj, "status", "readyReplicas")
+	return ready >= desired
+}
+
+// conditionTrue reports whether status.conditions contains type=True.
+func conditionTrue(obj map[string]interface{}, condType string) bool {
+	conds, _, _ := unstructured.NestedSlice(obj, "status", "conditions")
+	for _, c := range conds {
+		m, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if m["type"] == condType && m["status"] == "True" {
+			return true
+		}
+	}
+	return false
+}
+
+// cnpgRecoveryTarget is the CNPG cluster to recover and the Backup to use.
+type cnpgRecoveryTarget struct {
+	Namespace string
+	Cluster   string
+	Backup    string
+}
+
+// runDatabaseStage restores CNPG Backup objects (with their status, which holds
+// the object-store location) and their credential Secrets, then restores the
+// Clusters with spec.bootstrap rewritten to recover from the latest completed
+// Backup of each cluster via a Velero resource modifier.
+func runDatabaseStage(ctx context.Context, r *restoreRun) error {
+	nss := []interface{}{"*"}
+	if len(r.namespaces) > 0 {
+		nss = toInterfaceSlice(r.namespaces)
+	}
+
+	catalogName := r.restoreName("db-catalog")
+	if err := r.createRestore(ctx, catalogName, map[string]interface{}{
+		"includedNamespaces": nss,
+		"includedResources":  []interface{}{"backups.postgresql.cnpg.io", "secrets"},
+		"restoreStatus": map[string]interface{}{
+			"includedResources": []interface{}{"backups.postgresql.cnpg.io"},
+		},
+	}); err != nil {
+		return err
+	}
+	if err := r.waitForRestore(ctx, catalogName, false); err != nil {
+		return err
+	}
+
+	targets, err := latestCNPGBackups(ctx, r.dyn)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	modifier := r.restoreName("db-modifiers")
+	if err := ensureRecoveryModifier(ctx, r.dyn, modifier, targets); err != nil {
+		return err
+	}
+
+	clustersName := r.restoreName("databases")
+	if err := r.createRestore(ctx, clustersName, map[string]interface{}{
+		"includedNamespaces": nss,
+		"includedResources":  []interface{}{"clusters.postgresql.cnpg.io", "scheduledbackups.postgresql.cnpg.io"},
+		"resourceModifier": map[string]interface{}{
+			"kind": "ConfigMap",
+			"name": modifier,
+		},
+	}); err != nil {
+		return err
+	}
+	r.restored["databases"] = clustersName
+	return r.waitForRestore(ctx, clustersName, false)
+}
+
+// latestCNPGBackups returns, per CNPG cluster, the most recent completed Backup.
+func latestCNPGBackups(ctx context.Context, dyn dynamic.Interface) ([]cnpgRecoveryTarget, error) {
+	list, err := dyn.Resource(cnpgBackupGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if crdMissing(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list CloudNativePG backups: %w", err)
+	}
+	return selectLatestCNPGBackups(list.Items), nil
+}
+
+// selectLatestCNPGBackups picks the newest completed Backup for each cluster,
+// ordered by stoppedAt/startedAt and falling back to creation time.
+func selectLatestCNPGBackups(items []unstructured.Unstructured) []cnpgRecoveryTarget {
+	type candidate struct {
+		target cnpgRecoveryTarget
+		at     time.Time
+	}
+	latest := map[string]candidate{}
+	for _, b := range items {
+		if nestedString(b.Object, "status", "phase") != "completed" {
+			continue
+		}
+		cluster := nestedString(b.Object, "spec", "cluster", "name")
+		if cluster == "" {
+			continue
+		}
+		at := b.GetCreationTimestamp().Time
+		for _, field := range []string{"stoppedAt", "startedAt"} {
+			if ts := nestedString(b.Object, "status", field); ts != "" {
+				if t, err := time.Parse(time.RFC3339, ts); err == nil {
+					at = t
+					break
+				}
+			}
+		}
+		key := b.GetNamespace() + "/" + cluster
+		if cur, ok := latest[key]; !ok || at.After(cur.at) {
+			latest[key] = candidate{
+				target: cnpgRecoveryTarget{Namespace: b.GetNamespace(), Cluster: cluster, Backup: b.GetName()},
+				at:     at,
+			}
+		}
+	}
+
+	out := make([]cnpgRecoveryTarget, 0, len(latest))
+	for _, c := range latest {
+		out = append(out, c.target)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Namespace != out[j].Namespace {
+			return out[i].Namespace < out[j].Namespace
+		}
+		return out[i].Cluster < out[j].Cluster
+	})
+	return out
+}
+
+// recoveryModifierRules renders the Velero resource-modifier document that
+// points each cluster's bootstrap at its recovery Backup.
+func recoveryModifierRules(targets []cnpgRecoveryTarget) (string, error) {
+	rules := make([]interface{}, 0, len(targets))
+	for _, t := range targets {
+		bootstrap, err := json.Marshal(map[string]interface{}{
+			"recovery": map[string]interface{}{
+				"backup": map[string]interface{}{"name": t.Backup},
+			},
+		})
+		if err != nil {
+			return "", err
+		}
+		rules = append(rules, map[string]interface{}{
+			"conditions": map[string]interface{}{
+				"groupResource":     "clusters.postgresql.cnpg.io",
+				"resourceNameRegex": "^" + t.Cluster + "$",
+				"namespaces":        []interface{}{t.Namespace},
+			},
+			"patches": []interface{}{
+				map[string]interface{}{
+					"operation": "add",
+					"path":      "/spec/bootstrap",
+					"value":     string(bootstrap),
+				},
+			},
+		})
+	}
+	out, err := yaml.Marshal(map[string]interface{}{
+		"version":               "v1",
+		"resourceModifierRules": rules,
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// ensureRecoveryModifier writes the resource-modifier ConfigMap consumed by the
+// database Restore into the Velero namespace.
+func ensureRecoveryModifier(ctx context.Context, dyn dynamic.Interface, name string, targets []cnpgRecoveryTarget) error {
+	rules, err := recoveryModifierRules(targets)
+	if err != nil {
+		return fmt.Errorf("failed to render CNPG recovery rules: %w", err)
+	}
+	cm := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: veleroNamespace, Labels: map[string]string{"adhar.io/managed-by": "adhar-cli"}},
+		Data:       map[string]string{"cnpg-recovery.yaml": rules},
+	}
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cm)
+	if err != nil {
+		return err
+	}
+	if _, err := dyn.Resource(configMapGVR).Namespace(veleroNamespace).Create(ctx, &unstructured.Unstructured{Object: raw}, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create resource modifier %q: %w", name, err)
+	}
+	return nil
+}
+
+// verifyDatabases waits until every restored CNPG cluster reports all
+// instances ready.
+func verifyDatabases(ctx context.Context, r *restoreRun, restoreName string) error {
+	if restoreName == "" {
+		return nil // no CNPG backups in this restore
+	}
+	return r.pollUntil(ctx, "CloudNativePG clusters", func(ctx context.Context) (bool, string, error) {
+		list, err := r.dyn.Resource(cnpgClusterGVR).List(ctx, metav1.ListOptions{
+			LabelSelector: veleroRestoreNameLabel + "=" + restoreName,
+		})
+		if err != nil {
+			return false, "", fmt.Errorf("failed to list CloudNativePG clusters: %w", err)
+		}
+		var pending []string
+		for _, c := range list.Items {
+			instances, _, _ := unstructured.NestedInt64(c.Object, "spec", "instances")
+			ready, _, _ := unstructured.NestedInt64(c.Object, "status", "readyInstances")
+			if ready < instances {
+				pending = append(pending, fmt.Sprintf("%s/%s (%d/%d, %s)",
+					c.GetNamespace(), c.GetName(), ready, instances, nestedString(c.Object, "status", "phase")))
+			}
+		}
+		return len(pending) == 0, strings.Join(pending, ", "), nil
+	})
+}
+
+// pauseAutoSync disables automated sync on every ArgoCD Application that has
+// it, stashing the original policy in an annotation. It is idempotent, so it
+// can run again after a stage restores more Applications.
+func pauseAutoSync(ctx context.Context, dyn dynamic.Interface) ([]string, error) {
+	list, err := dyn.Resource(argoApplicationGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if crdMissing(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list ArgoCD applications: %w", err)
+	}
+
+	var paused []string
+	for _, item := range list.Items {
+		automated, found, _ := unstructured.NestedMap(item.Object, "spec", "syncPolicy", "automated")
+		if !found {
+			continue
+		}
+		raw, err := json.Marshal(automated)
+		if err != nil {
+			return paused, err
+		}
+		patch, _ := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{pausedSyncAnnotation: string(raw)},
+			},
+			"spec": map[string]interface{}{
+				"syncPolicy": map[string]interface{}{"automated": nil},
+			},
+		})
+		if _, err := dyn.Resource(argoApplicationGVR).Namespace(item.GetNamespace()).
+			Patch(ctx, item.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return paused, fmt.Errorf("failed to pause auto-sync on %s/%s: %w", item.GetNamespace(), item.GetName(), err)
+		}
+		paused = append(paused, item.GetNamespace()+"/"+item.GetName())
+	}
+	return paused, nil
+}
+
+// resumeAutoSync re-enables automated sync on every Application paused by
+// pauseAutoSync, restoring its original policy.
+func resumeAutoSync(ctx context.Context, dyn dynamic.Interface) ([]string, error) {
+	list, err := dyn.Resource(argoApplicationGVR).Namespace(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if crdMissing(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list ArgoCD applications: %w", err)
+	}
+
+	var resumed []string
+	for _, item := range list.Items {
+		raw, ok := item.GetAnnotations()[pausedSyncAnnotation]
+		if !ok {
+			continue
+		}
+		automated := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(raw), &automated); err != nil {
+			return resumed, fmt.Errorf("invalid %s annotation on %s/%s: %w", pausedSyncAnnotation, item.GetNamespace(), item.GetName(), err)
+		}
+		patch, _ := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{pausedSyncAnnotation: nil},
+			},
+			"spec": map[string]interface{}{
+				"syncPolicy": map[string]interface{}{"automated": automated},
+			},
+		})
+		if _, err := dyn.Resource(argoApplicationGVR).Namespace(item.GetNamespace()).
+			Patch(ctx, item.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return resumed, fmt.Errorf("failed to resume auto-sync on %s/%s: %w", item.GetNamespace(), item.GetName(), err)
+		}
+		resumed = append(resumed, item.GetNamespace()+"/"+item.GetName())
+	}
+	return resumed, nil
+}
+
+func toInterfaceSlice(in []string) []interface{} {
+	out := make([]interface{}, len(in))
+	for i, s := range in {
+		out[i] = s
+	}
+	return out
+}
+
+func firstN(in []string, n int) []string {
+	if len(in) <= n {
+		return in
+	}
+	return append(append([]string{}, in[:n]...), fmt.Sprintf("+%d more", len(in)-n))
+}
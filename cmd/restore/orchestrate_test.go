@@ -0,0 +1,147 @@
+package restore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func cnpgBackup(ns, name, cluster, phase, stoppedAt string) unstructured.Unstructured {
+	u := unstructured.Unstructured{Object: map[string]interface{}{
+		"spec":   map[string]interface{}{"cluster": map[string]interface{}{"name": cluster}},
+		"status": map[string]interface{}{"phase": phase, "stoppedAt": stoppedAt},
+	}}
+	u.SetNamespace(ns)
+	u.SetName(name)
+	u.SetCreationTimestamp(metav1.Now())
+	return u
+}
+
+func TestSelectLatestCNPGBackups(t *testing.T) {
+	items := []unstructured.Unstructured{
+		cnpgBackup("apps", "pg-old", "pg", "completed", "2025-01-01T00:00:00Z"),
+		cnpgBackup("apps", "pg-new", "pg", "completed", "2025-01-02T00:00:00Z"),
+		cnpgBackup("apps", "pg-failed", "pg", "failed", "2025-01-03T00:00:00Z"),
+		cnpgBackup("adhar-system", "gitea-db-1", "gitea-db", "completed", "2025-01-01T00:00:00Z"),
+		cnpgBackup("apps", "orphan", "", "completed", "2025-01-04T00:00:00Z"),
+	}
+
+	got := selectLatestCNPGBackups(items)
+	want := []cnpgRecoveryTarget{
+		{Namespace: "adhar-system", Cluster: "gitea-db", Backup: "gitea-db-1"},
+		{Namespace: "apps", Cluster: "pg", Backup: "pg-new"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d targets, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("target %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestRecoveryModifierRules(t *testing.T) {
+	rules, err := recoveryModifierRules([]cnpgRecoveryTarget{{Namespace: "apps", Cluster: "pg", Backup: "pg-new"}})
+	if err != nil {
+		t.Fatalf("recoveryModifierRules: %v", err)
+	}
+	for _, want := range []string{
+		"version: v1",
+		"groupResource: clusters.postgresql.cnpg.io",
+		"resourceNameRegex: ^pg$",
+		"path: /spec/bootstrap",
+		`{"recovery":{"backup":{"name":"pg-new"}}}`,
+	} {
+		if !strings.Contains(rules, want) {
+			t.Errorf("rules missing %q:\n%s", want, rules)
+		}
+	}
+}
+
+// recordingProgress records stage progress alongside the sequence's calls.
+type recordingProgress struct{ events *[]string }
+
+func (p recordingProgress) Activate(i int) {
+	*p.events = append(*p.events, fmt.Sprintf("activate:%d", i))
+}
+func (p recordingProgress) Done(i int) { *p.events = append(*p.events, fmt.Sprintf("done:%d", i)) }
+func (p recordingProgress) Fail(i int) { *p.events = append(*p.events, fmt.Sprintf("fail:%d", i)) }
+func (p recordingProgress) Stop()      { *p.events = append(*p.events, "stop") }
+
+func recordingSequence(events *[]string, failVerify string) restoreSequence {
+	return restoreSequence{
+		stages: []restoreStage{{Name: "crds"}, {Name: "foundation"}, {Name: "workloads"}},
+		run: func(_ context.Context, s restoreStage) error {
+			*events = append(*events, "run:"+s.Name)
+			return nil
+		},
+		verify: func(_ context.Context, s restoreStage) error {
+			*events = append(*events, "verify:"+s.Name)
+			if s.Name == failVerify {
+				return errors.New("not ready")
+			}
+			return nil
+		},
+		pause: func(context.Context) error {
+			*events = append(*events, "pause")
+			return nil
+		},
+		resume: func(context.Context) error {
+			*events = append(*events, "resume")
+			return nil
+		},
+	}
+}
+
+func TestRestoreSequencePausesBeforeVerify(t *testing.T) {
+	var events []string
+	if err := recordingSequence(&events, "").execute(context.Background(), recordingProgress{&events}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	want := []string{
+		"pause",
+		"activate:0", "run:crds", "pause", "verify:crds", "done:0",
+		"activate:1", "run:foundation", "pause", "verify:foundation", "done:1",
+		"activate:2", "run:workloads", "pause", "verify:workloads", "done:2",
+		"stop", "resume",
+	}
+	if strings.Join(events, " ") != strings.Join(want, " ") {
+		t.Errorf("unexpected order:\n got  %v\n want %v", events, want)
+	}
+}
+
+func TestRestoreSequenceFailureKeepsSyncPaused(t *testing.T) {
+	var events []string
+	err := recordingSequence(&events, "foundation").execute(context.Background(), recordingProgress{&events})
+	var stageErr *stageError
+	if !errors.As(err, &stageErr) || stageErr.stage != "foundation" {
+		t.Fatalf("expected foundation stage error, got %v", err)
+	}
+	want := []string{
+		"pause",
+		"activate:0", "run:crds", "pause", "verify:crds", "done:0",
+		"activate:1", "run:foundation", "pause", "verify:foundation", "fail:1",
+		"stop",
+	}
+	if strings.Join(events, " ") != strings.Join(want, " ") {
+		t.Errorf("unexpected order:\n got  %v\n want %v", events, want)
+	}
+}
+
+func TestRestoreSequenceKeepSyncPaused(t *testing.T) {
+	var events []string
+	seq := recordingSequence(&events, "")
+	seq.resume = nil
+	if err := seq.execute(context.Background(), recordingProgress{&events}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if last := events[len(events)-1]; last != "stop" {
+		t.Errorf("expected no resume with auto-sync kept paused, last event %q", last)
+	}
+}
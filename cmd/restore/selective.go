@@ -1,60 +1,218 @@
 package restore
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
 
 	"github.com/spf13/cobra"
 )
 
 var (
 	selectiveCmd = &cobra.Command{
-		Use:   "selective [backup-path]",
+		Use:   "selective [backup-name]",
 		Short: "Selective component restoration",
-		Long: `Restore specific components from a backup.
-Choose which components, applications, or data to restore.`,
+		Long: `Restore a subset of a Velero backup, chosen by namespace, resource kind and/or
+label selector.
+
+Before anything is applied the backup contents are downloaded and compared
+with the live cluster, so you can see what would be created, what differs and
+what is unchanged. Objects that already exist are handled by --conflict:
+  skip              leave the live object untouched (default)
+  overwrite         update the live object from the backup
+  rename-namespace  restore into <namespace><suffix> instead
+
+Examples:
+  adhar restore selective nightly --namespaces=team-a --dry-run
+  adhar restore selective nightly --kinds=deployments.apps,configmaps -l app=web
+  adhar restore selective nightly -n team-a --conflict=rename-namespace --namespace-suffix=-copy
+  adhar restore selective ./nightly-data.tar.gz -n team-a --dry-run --diff`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: runSelectiveRestore,
 	}
 
 	// Selective restore specific flags
-	components   []string
-	applications []string
-	databases    []string
-	configs      []string
+	selectNamespaces []string
+	selectKinds      []string
+	selectLabels     string
+)
+
+// Flags shared by the selective and config restores.
+var (
+	conflictPolicy  string
+	namespaceSuffix string
+	showDiff        bool
+	skipPreview     bool
+	waitForRestore  bool
 )
 
 func init() {
-	selectiveCmd.Flags().StringArrayVarP(&components, "components", "c", []string{}, "Components to restore (e.g., argocd, gitea, keycloak)")
-	selectiveCmd.Flags().StringArrayVarP(&applications, "apps", "a", []string{}, "Applications to restore")
-	selectiveCmd.Flags().StringArrayVarP(&databases, "databases", "d", []string{}, "Databases to restore")
-	selectiveCmd.Flags().StringArrayVarP(&configs, "configs", "", []string{}, "Configurations to restore")
+	selectiveCmd.Flags().StringSliceVarP(&selectNamespaces, "namespaces", "n", nil, "Namespaces to restore")
+	selectiveCmd.Flags().StringSliceVarP(&selectKinds, "kinds", "k", nil, "Resources or kinds to restore (e.g. deployments.apps, configmaps, Secret)")
+	selectiveCmd.Flags().StringVarP(&selectLabels, "selector", "l", "", "Label selector the restored objects must match")
+	addScopedRestoreFlags(selectiveCmd)
+}
+
+// addScopedRestoreFlags registers the preview and conflict flags shared by
+// `restore selective` and `restore config`.
+func addScopedRestoreFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&conflictPolicy, "conflict", conflictSkip, "How to handle objects that already exist: skip, overwrite or rename-namespace")
+	cmd.Flags().StringVar(&namespaceSuffix, "namespace-suffix", "-restored", "Suffix for target namespaces with --conflict=rename-namespace")
+	cmd.Flags().BoolVar(&showDiff, "diff", false, "Show the differing fields of every changed object")
+	cmd.Flags().BoolVar(&skipPreview, "no-preview", false, "Skip downloading the backup to compare it against live objects")
+	cmd.Flags().BoolVar(&waitForRestore, "wait", true, "Wait for the Velero restore to finish")
+	cmd.Flags().DurationVar(&stageTimeout, "timeout", 20*time.Minute, "Maximum time to wait for the restore to finish")
 }
 
 func runSelectiveRestore(cmd *cobra.Command, args []string) error {
 	if len(args) > 0 {
 		backupPath = args[0]
 	}
-
 	if backupPath == "" {
-		return fmt.Errorf("backup path is required. Use --backup flag or provide as argument")
+		return fmt.Errorf("backup name is required. Use --backup flag or provide as argument")
+	}
+	if len(selectNamespaces) == 0 && len(selectKinds) == 0 && selectLabels == "" {
+		return fmt.Errorf("nothing selected: pass --namespaces, --kinds and/or --selector (use `adhar restore full` for everything)")
+	}
+
+	fmt.Println(helpers.TitleStyle.Render("🎯 Selective Restore"))
+	return runScopedRestore(context.Background(), "selective", backupPath, []scopedRestore{{
+		Suffix: "resources",
+		Selection: restoreSelection{
+			Namespaces: selectNamespaces,
+			Resources:  selectKinds,
+			Selector:   selectLabels,
+		},
+	}})
+}
+
+// scopedRestore is one Velero Restore of a selection out of a backup.
+type scopedRestore struct {
+	Suffix    string
+	Title     string
+	Selection restoreSelection
+}
+
+// runScopedRestore previews each selection against the live cluster, asks for
+// confirmation when live objects would be overwritten, then creates one
+// Velero Restore per selection.
+func runScopedRestore(ctx context.Context, prefix, ref string, parts []scopedRestore) error {
+	switch conflictPolicy {
+	case conflictSkip, conflictOverwrite, conflictRenameNamespace:
+	default:
+		return fmt.Errorf("unknown conflict policy %q (want %s, %s or %s)", conflictPolicy, conflictSkip, conflictOverwrite, conflictRenameNamespace)
 	}
 
-	fmt.Printf("🔄 Starting selective restoration from: %s\n", backupPath)
+	dyn, err := getDynamicClient()
+	if err != nil {
+		return unreachable(err)
+	}
+	mapper, err := getRESTMapper()
+	if err != nil {
+		return unreachable(err)
+	}
+
+	_, statErr := os.Stat(ref)
+	localArchive := statErr == nil
+	if localArchive && !dryRun {
+		return fmt.Errorf("%s is a local archive and can only be previewed with --dry-run; pass the Velero backup name to restore", ref)
+	}
+	if skipPreview && conflictPolicy == conflictRenameNamespace && len(parts[0].Selection.Namespaces) == 0 {
+		return fmt.Errorf("--conflict=rename-namespace with --no-preview needs explicit --namespaces")
+	}
 
-	if len(components) > 0 {
-		fmt.Printf("🔧 Components: %v\n", components)
+	var contents []backupItem
+	if !skipPreview {
+		fmt.Printf("📥 Reading contents of %s\n", ref)
+		rc, err := openBackupContents(ctx, dyn, ref)
+		if err != nil {
+			return err
+		}
+		contents, err = readBackupArchive(rc)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
 	}
-	if len(applications) > 0 {
-		fmt.Printf("📱 Applications: %v\n", applications)
+
+	specs := make([]map[string]interface{}, len(parts))
+	overwrites := 0
+	for i, part := range parts {
+		mapping := map[string]string{}
+		if conflictPolicy == conflictRenameNamespace {
+			for _, ns := range part.Selection.Namespaces {
+				mapping[ns] = ns + namespaceSuffix
+			}
+		}
+
+		if !skipPreview {
+			items, err := selectItems(contents, part.Selection)
+			if err != nil {
+				return err
+			}
+			if conflictPolicy == conflictRenameNamespace && len(mapping) == 0 {
+				mapping = namespaceMapping(items, namespaceSuffix)
+			}
+			if part.Title != "" {
+				fmt.Println(helpers.CreateSection(part.Title))
+			}
+			if len(items) == 0 {
+				fmt.Println(helpers.CreateMuted("   No objects in the backup match this selection"))
+			} else {
+				diffs, err := diffAgainstLive(ctx, dyn, items, mapping)
+				if err != nil {
+					return err
+				}
+				counts := printDiff(diffs, conflictPolicy, showDiff)
+				if conflictPolicy == conflictOverwrite {
+					overwrites += counts[diffUpdate]
+				}
+			}
+		}
+
+		spec, err := part.Selection.veleroSpec(conflictPolicy, mapping, mapper)
+		if err != nil {
+			return err
+		}
+		specs[i] = spec
 	}
-	if len(databases) > 0 {
-		fmt.Printf("🗄️  Databases: %v\n", databases)
+
+	if dryRun {
+		fmt.Println(helpers.CreateMuted("   DRY RUN - no Restore objects created"))
+		return nil
 	}
-	if len(configs) > 0 {
-		fmt.Printf("⚙️  Configurations: %v\n", configs)
+
+	if overwrites > 0 && !forceRestore {
+		fmt.Printf("⚠️  %d live object(s) will be overwritten from the backup. Continue? (y/N): ", overwrites)
+		var response string
+		_, _ = fmt.Scanln(&response)
+		if response != "y" && response != "Y" {
+			fmt.Println("❌ Restore cancelled")
+			return nil
+		}
 	}
 
-	// TODO: Implement selective restoration logic
-	fmt.Println("✅ Selective restoration completed successfully!")
+	run := newRestoreRun(dyn, ref, prefix, stageTimeout)
+	for i, part := range parts {
+		name := run.restoreName(part.Suffix)
+		if err := run.createRestore(ctx, name, specs[i]); err != nil {
+			return err
+		}
+		fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Restore %q created from backup %q", name, ref)))
+		if !waitForRestore {
+			fmt.Println(helpers.CreateMuted("   Track progress with: adhar restore status " + name))
+			continue
+		}
+		if err := run.waitForRestore(ctx, name, forceRestore); err != nil {
+			return err
+		}
+		fmt.Println(helpers.CreateSuccess(fmt.Sprintf("✅ Restore %q completed", name)))
+	}
+	if conflictPolicy == conflictRenameNamespace {
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("   Objects were restored into namespaces suffixed %q", namespaceSuffix)))
+	}
 	return nil
 }
@@ -0,0 +1,295 @@
+package security
+
+// alerts.go turns Falco and Tetragon events into incidents. Both packages run
+// in adhar-system and write one JSON event per line to stdout (Falco with
+// json_output, Tetragon through its export-stdout sidecar), so the pod logs
+// are the event stream.
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// alertSource is a runtime security agent whose events can open incidents.
+type alertSource struct {
+	Name      string
+	Selector  string
+	Container string
+	Parse     func(line []byte) (*alert, bool)
+}
+
+var alertSources = []alertSource{
+	{Name: "falco", Selector: "app.kubernetes.io/name=falco", Container: "falco", Parse: parseFalcoEvent},
+	{Name: "tetragon", Selector: "app.kubernetes.io/name=tetragon", Container: "export-stdout", Parse: parseTetragonEvent},
+}
+
+// alert is a normalised runtime security event.
+type alert struct {
+	Source    string
+	Rule      string
+	Severity  string
+	Output    string
+	Namespace string
+	Pod       string
+	Node      string
+	Time      time.Time
+}
+
+// fingerprint identifies repeats of the same alert so they update one
+// incident instead of opening many.
+func (a *alert) fingerprint() string {
+	return strings.Join([]string{a.Source, a.Rule, a.Namespace, a.Pod}, "|")
+}
+
+// falcoSeverity maps Falco priorities onto incident severities.
+func falcoSeverity(priority string) string {
+	switch strings.ToLower(priority) {
+	case "emergency", "alert", "critical":
+		return "CRITICAL"
+	case "error":
+		return "HIGH"
+	case "warning":
+		return "MEDIUM"
+	}
+	return "LOW"
+}
+
+func parseFalcoEvent(line []byte) (*alert, bool) {
+	var ev struct {
+		Output       string                 `json:"output"`
+		Priority     string                 `json:"priority"`
+		Rule         string                 `json:"rule"`
+		Time         time.Time              `json:"time"`
+		Hostname     string                 `json:"hostname"`
+		OutputFields map[string]interface{} `json:"output_fields"`
+	}
+	if err := json.Unmarshal(line, &ev); err != nil || ev.Rule == "" {
+		return nil, false
+	}
+	field := func(k string) string {
+		if v, ok := ev.OutputFields[k].(string); ok {
+			return v
+		}
+		return ""
+	}
+	// output_fields["user.name"] is the Linux user inside the container,
+	// not a platform account, so it is not recorded as the incident's user.
+	return &alert{
+		Source:    "falco",
+		Rule:      ev.Rule,
+		Severity:  falcoSeverity(ev.Priority),
+		Output:    ev.Output,
+		Namespace: field("k8s.ns.name"),
+		Pod:       field("k8s.pod.name"),
+		Node:      ev.Hostname,
+		Time:      ev.Time,
+	}, true
+}
+
+// parseTetragonEvent accepts events raised by a TracingPolicy (kprobe,
+// tracepoint, uprobe and lsm events carrying a policy name). Plain process
+// exec/exit events are observability, not alerts. Policies that enforce
+// (SIGKILL, override) are treated as critical, detections as high.
+func parseTetragonEvent(line []byte) (*alert, bool) {
+	type policyEvent struct {
+		Process struct {
+			Binary    string `json:"binary"`
+			Arguments string `json:"arguments"`
+			Pod       struct {
+				Namespace string `json:"namespace"`
+				Name      string `json:"name"`
+			} `json:"pod"`
+		} `json:"process"`
+		FunctionName string `json:"function_name"`
+		PolicyName   string `json:"policy_name"`
+		Action       string `json:"action"`
+	}
+	var ev struct {
+		ProcessKprobe     *policyEvent `json:"process_kprobe"`
+		ProcessTracepoint *policyEvent `json:"process_tracepoint"`
+		ProcessUprobe     *policyEvent `json:"process_uprobe"`
+		ProcessLsm        *policyEvent `json:"process_lsm"`
+		NodeName          string       `json:"node_name"`
+		Time              time.Time    `json:"time"`
+	}
+	if err := json.Unmarshal(line, &ev); err != nil {
+		return nil, false
+	}
+	var p *policyEvent
+	for _, candidate := range []*policyEvent{ev.ProcessKprobe, ev.ProcessTracepoint, ev.ProcessUprobe, ev.ProcessLsm} {
+		if candidate != nil {
+			p = candidate
+			break
+		}
+	}
+	if p == nil || p.PolicyName == "" {
+		return nil, false
+	}
+	sev := "HIGH"
+	if strings.Contains(p.Action, "SIGKILL") || strings.Contains(p.Action, "OVERRIDE") {
+		sev = "CRITICAL"
+	}
+	return &alert{
+		Source:    "tetragon",
+		Rule:      p.PolicyName,
+		Severity:  sev,
+		Output:    strings.TrimSpace(fmt.Sprintf("%s %s (%s)", p.Process.Binary, p.Process.Arguments, valueOr(p.FunctionName, "policy match"))),
+		Namespace: p.Process.Pod.Namespace,
+		Pod:       p.Process.Pod.Name,
+		Node:      ev.NodeName,
+		Time:      ev.Time,
+	}, true
+}
+
+// readAlerts streams events from every pod of each source since the given
+// time, calling handle for each alert at or above minSeverity. With follow it
+// keeps streaming until ctx is cancelled.
+func readAlerts(ctx context.Context, cs kubernetes.Interface, sources []alertSource, since time.Duration, follow bool, minSeverity string, handle func(*alert)) error {
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		found int
+	)
+	sinceSeconds := int64(since.Seconds())
+	for _, src := range sources {
+		pods, err := cs.CoreV1().Pods(incidentNamespace).List(ctx, metav1.ListOptions{LabelSelector: src.Selector})
+		if err != nil {
+			return fmt.Errorf("list %s pods: %w", src.Name, err)
+		}
+		for _, pod := range pods.Items {
+			if pod.Status.Phase != corev1.PodRunning {
+				continue
+			}
+			opts := &corev1.PodLogOptions{Container: src.Container, Follow: follow}
+			if sinceSeconds > 0 {
+				opts.SinceSeconds = &sinceSeconds
+			}
+			stream, err := cs.CoreV1().Pods(incidentNamespace).GetLogs(pod.Name, opts).Stream(ctx)
+			if err != nil {
+				return fmt.Errorf("read %s events from %s: %w", src.Name, pod.Name, err)
+			}
+			found++
+			wg.Add(1)
+			go func(src alertSource, r io.ReadCloser) {
+				defer wg.Done()
+				defer r.Close()
+				scanAlerts(r, src.Parse, minSeverity, func(a *alert) {
+					mu.Lock()
+					defer mu.Unlock()
+					handle(a)
+				})
+			}(src, stream)
+		}
+	}
+	if found == 0 {
+		return fmt.Errorf("no running falco or tetragon pods in %s; enable the falco or tetragon security package first", incidentNamespace)
+	}
+	wg.Wait()
+	return nil
+}
+
+// scanAlerts parses one event stream.
+func scanAlerts(r io.Reader, parse func([]byte) (*alert, bool), minSeverity string, handle func(*alert)) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			continue
+		}
+		a, ok := parse(line)
+		if !ok || severityRank(a.Severity) < severityRank(minSeverity) {
+			continue
+		}
+		handle(a)
+	}
+}
+
+// alertTracker opens incidents for alerts and folds repeats of the same
+// alert into the unresolved incident with its fingerprint. Alerts already
+// recorded by an earlier sync (not newer than the incident's last sighting)
+// are ignored, so overlapping --since windows do not double count.
+type alertTracker struct {
+	cs    kubernetes.Interface
+	known map[string]*incident // latest incident per fingerprint
+}
+
+func newAlertTracker(ctx context.Context, cs kubernetes.Interface) (*alertTracker, error) {
+	existing, err := listIncidentRecords(ctx, cs, "", "")
+	if err != nil {
+		return nil, err
+	}
+	t := &alertTracker{cs: cs, known: map[string]*incident{}}
+	// existing is newest first; keep the first per fingerprint.
+	for _, in := range existing {
+		if in.Fingerprint != "" && t.known[in.Fingerprint] == nil {
+			t.known[in.Fingerprint] = in
+		}
+	}
+	return t, nil
+}
+
+// observe records a, returning "opened", "updated" or "" when a was already
+// recorded.
+func (t *alertTracker) observe(ctx context.Context, a *alert) (string, *incident, error) {
+	fp := a.fingerprint()
+	seen := a.Time
+	if seen.IsZero() {
+		seen = time.Now().UTC()
+	}
+	if prev := t.known[fp]; prev != nil {
+		if !seen.After(prev.LastSeen) {
+			return "", prev, nil
+		}
+		if prev.Status != "resolved" {
+			updated, err := updateIncident(ctx, t.cs, prev.ID, func(in *incident) error {
+				in.Occurrences++
+				in.LastSeen = seen
+				if severityRank(a.Severity) > severityRank(in.Severity) {
+					in.record(a.Source, "escalated", in.Severity+" → "+a.Severity+": "+a.Output)
+					in.Severity = a.Severity
+				}
+				return nil
+			})
+			if err != nil {
+				return "", nil, err
+			}
+			t.known[fp] = updated
+			return "updated", updated, nil
+		}
+	}
+
+	now := time.Now().UTC()
+	in := &incident{
+		ID:          newIncidentID(now),
+		Title:       a.Rule,
+		Type:        "runtime",
+		Severity:    a.Severity,
+		Status:      "open",
+		Source:      a.Source,
+		Rule:        a.Rule,
+		Namespace:   a.Namespace,
+		Pod:         a.Pod,
+		Node:        a.Node,
+		Fingerprint: fp,
+		Occurrences: 1,
+		Created:     now,
+		LastSeen:    seen,
+	}
+	in.record(a.Source, "opened", a.Output)
+	if err := createIncidentRecord(ctx, t.cs, in); err != nil {
+		return "", nil, err
+	}
+	t.known[fp] = in
+	return "opened", in, nil
+}
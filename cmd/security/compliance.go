@@ -0,0 +1,482 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/k8s"
+	"adhar-io/adhar/platform/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var complianceCmd = &cobra.Command{
+	Use:   "compliance",
+	Short: "CIS, NSA and MITRE compliance reports from kubescape",
+	Long: `Run kubescape framework scans (CIS, NSA, MITRE, SOC2), keep a summary of every
+run in the cluster so the compliance score can be tracked over time, compare
+runs, and export a markdown report for auditors.
+
+Runs are stored as ConfigMaps in adhar-system labelled adhar.io/compliance-run.
+Runs are referenced by ID, "latest" or "latest~N" (N runs before the latest).
+
+Examples:
+  adhar security compliance scan                    # Frameworks from compliance policies, or CIS+NSA+MITRE
+  adhar security compliance scan nsa --fail-below=70
+  adhar security compliance scan --from-file=results.json
+  adhar security compliance history
+  adhar security compliance diff                    # latest~1 → latest
+  adhar security compliance report --out=compliance.md`,
+}
+
+func init() {
+	complianceCmd.AddCommand(complianceScanCmd)
+	complianceCmd.AddCommand(complianceHistoryCmd)
+	complianceCmd.AddCommand(complianceDiffCmd)
+	complianceCmd.AddCommand(complianceReportCmd)
+}
+
+// --- scan ---
+
+var complianceScanCmd = &cobra.Command{
+	Use:   "scan [framework...]",
+	Short: "Run a framework scan and store its summary",
+	Long: `Run a kubescape framework scan through the kubescape operator and store the
+summary. Frameworks are cis, nsa, mitre, soc2 or any kubescape framework name;
+without arguments the frameworks requested by the cluster's compliance
+policies are scanned, or CIS, NSA and MITRE if there are none.
+
+--from-file reads a report written by "kubescape scan framework ... --format
+json --output FILE" instead of scanning in the cluster.`,
+	RunE: runComplianceScan,
+}
+
+var (
+	compFromFile  string
+	compNoSave    bool
+	compKeep      int
+	compFailBelow float64
+	compOutput    string
+)
+
+func init() {
+	complianceScanCmd.Flags().StringVar(&compFromFile, "from-file", "", "Read a kubescape JSON report instead of scanning")
+	complianceScanCmd.Flags().BoolVar(&compNoSave, "no-save", false, "Do not store the run")
+	complianceScanCmd.Flags().IntVar(&compKeep, "keep", 30, "Number of runs to keep (0 keeps all)")
+	complianceScanCmd.Flags().Float64Var(&compFailBelow, "fail-below", 0, "Exit non-zero if the compliance score is below this")
+	complianceScanCmd.Flags().StringVarP(&compOutput, "output", "o", "table", "Output format (table, json, yaml, markdown)")
+	complianceScanCmd.Flags().StringVarP(&timeout, "timeout", "t", "5m", "How long to wait for the scan")
+}
+
+func runComplianceScan(cmd *cobra.Command, args []string) error {
+	if err := checkComplianceOutput(compOutput, "markdown"); err != nil {
+		return err
+	}
+	wait, err := time.ParseDuration(timeout)
+	if err != nil {
+		return fmt.Errorf("invalid --timeout %q: %w", timeout, err)
+	}
+	ctx := commandContext(cmd)
+	table := compOutput == "" || compOutput == "table"
+
+	var run *complianceRun
+	if compFromFile != "" {
+		if run, err = readPostureReport(compFromFile); err != nil {
+			return err
+		}
+	} else {
+		cs, err := incidentClient()
+		if err != nil {
+			return err
+		}
+		frameworks := args
+		if len(frameworks) == 0 {
+			if dyn, err := k8s.GetDynamicClient(); err == nil {
+				if frameworks, err = policyFrameworks(ctx, dyn); err != nil {
+					return err
+				}
+			}
+			if len(frameworks) == 0 {
+				frameworks = defaultFrameworks
+			}
+		}
+		if table {
+			logger.Info("📋 Scanning " + strings.Join(frameworks, ", ") + " with kubescape...")
+		}
+		scanCtx, cancel := context.WithTimeout(ctx, wait)
+		defer cancel()
+		if run, err = runKubescapeScan(scanCtx, cs, frameworks); err != nil {
+			return err
+		}
+	}
+	run.Time = time.Now().UTC()
+	run.ID = newComplianceRunID(run.Time)
+
+	if !compNoSave {
+		cs, err := incidentClient()
+		if err != nil {
+			return err
+		}
+		if err := saveComplianceRun(ctx, cs, run, compKeep); err != nil {
+			return err
+		}
+	}
+
+	switch compOutput {
+	case "json":
+		err = helpers.PrintJSON(run)
+	case "yaml":
+		err = helpers.PrintYAML(run)
+	case "markdown":
+		err = writeComplianceMarkdown(os.Stdout, run)
+	default:
+		printComplianceRun(os.Stdout, run)
+		if !compNoSave {
+			fmt.Println(helpers.CreateMuted("Stored as " + run.ID))
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if compFailBelow > 0 && run.Score < compFailBelow {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("compliance score %.1f%% is below %.1f%%", run.Score, compFailBelow)
+	}
+	return nil
+}
+
+// maxTableControls caps the failed controls listed in table output.
+const maxTableControls = 30
+
+func printComplianceRun(w io.Writer, run *complianceRun) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FRAMEWORK\tSCORE")
+	for _, f := range run.Frameworks {
+		fmt.Fprintf(tw, "%s\t%.1f%%\n", f.Name, f.Score)
+	}
+	fmt.Fprintf(tw, "overall\t%.1f%%\n", run.Score)
+	_ = tw.Flush()
+
+	failed := run.failedControls()
+	if len(failed) == 0 {
+		fmt.Fprintln(w, "\n"+helpers.CreateSuccess("All controls passed"))
+		return
+	}
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONTROL\tSEVERITY\tFAILED\tNAME")
+	for i, c := range failed {
+		if i == maxTableControls {
+			break
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%s\n", c.ID, c.Severity, c.Failed, c.Failed+c.Passed, truncate(c.Name, 60))
+	}
+	_ = tw.Flush()
+	if n := len(failed) - maxTableControls; n > 0 {
+		fmt.Fprintln(w, helpers.CreateMuted(fmt.Sprintf("… and %d more; use `compliance report` for the full list", n)))
+	}
+	fmt.Fprintf(w, "\n%d of %d control(s) failed\n", len(failed), len(run.Controls))
+}
+
+// --- history ---
+
+var complianceHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the compliance score of stored runs",
+	RunE:  runComplianceHistory,
+}
+
+var (
+	compHistFramework string
+	compHistLimit     int
+)
+
+func init() {
+	complianceHistoryCmd.Flags().StringVar(&compHistFramework, "framework", "", "Show the score of this framework instead of the overall score")
+	complianceHistoryCmd.Flags().IntVar(&compHistLimit, "limit", 20, "Number of runs to show")
+	complianceHistoryCmd.Flags().StringVarP(&compOutput, "output", "o", "table", "Output format (table, json, yaml)")
+}
+
+// historyEntry is one run in the history, with the change from the run
+// before it.
+type historyEntry struct {
+	ID             string    `json:"id"`
+	Time           time.Time `json:"time"`
+	Frameworks     []string  `json:"frameworks"`
+	Score          float64   `json:"score"`
+	Delta          *float64  `json:"delta,omitempty"`
+	FailedControls int       `json:"failedControls"`
+}
+
+// complianceHistory scores each run, overall or for one framework; runs
+// without that framework are skipped.
+func complianceHistory(runs []*complianceRun, framework string) []historyEntry {
+	var out []historyEntry
+	for _, r := range runs {
+		score, ok := r.Score, true
+		if framework != "" {
+			ok = false
+			for _, f := range r.Frameworks {
+				if strings.EqualFold(f.Name, framework) || strings.EqualFold(f.Name, kubescapeFrameworks[strings.ToLower(framework)]) {
+					score, ok = f.Score, true
+				}
+			}
+		}
+		if ok {
+			out = append(out, historyEntry{ID: r.ID, Time: r.Time, Frameworks: r.frameworkNames(), Score: score,
+				FailedControls: len(r.failedControls())})
+		}
+	}
+	for i := 0; i+1 < len(out); i++ {
+		delta := round1(out[i].Score - out[i+1].Score)
+		out[i].Delta = &delta
+	}
+	return out
+}
+
+func runComplianceHistory(cmd *cobra.Command, args []string) error {
+	if err := checkComplianceOutput(compOutput); err != nil {
+		return err
+	}
+	cs, err := incidentClient()
+	if err != nil {
+		return err
+	}
+	runs, err := listComplianceRuns(commandContext(cmd), cs)
+	if err != nil {
+		return err
+	}
+	history := complianceHistory(runs, compHistFramework)
+	if compHistLimit > 0 && len(history) > compHistLimit {
+		history = history[:compHistLimit]
+	}
+	switch compOutput {
+	case "json":
+		return helpers.PrintJSON(history)
+	case "yaml":
+		return helpers.PrintYAML(history)
+	}
+	if len(history) == 0 {
+		fmt.Println(helpers.CreateMuted("No compliance runs stored; run `adhar security compliance scan`"))
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN\tTIME\tFRAMEWORKS\tSCORE\tCHANGE\tFAILED CONTROLS")
+	for _, h := range history {
+		change := "-"
+		if h.Delta != nil {
+			change = fmt.Sprintf("%+.1f", *h.Delta)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.1f%%\t%s\t%d\n", h.ID, h.Time.Local().Format("2006-01-02 15:04"),
+			truncate(strings.Join(h.Frameworks, ","), 40), h.Score, change, h.FailedControls)
+	}
+	return tw.Flush()
+}
+
+// --- diff ---
+
+var complianceDiffCmd = &cobra.Command{
+	Use:   "diff [from] [to]",
+	Short: "Compare two compliance runs",
+	Long: `Compare two stored runs: score changes per framework, controls that started
+or stopped failing, and resources that started or stopped failing a control.
+The runs default to latest~1 and latest.`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runComplianceDiff,
+}
+
+func init() {
+	complianceDiffCmd.Flags().StringVarP(&compOutput, "output", "o", "table", "Output format (table, json, yaml)")
+}
+
+func runComplianceDiff(cmd *cobra.Command, args []string) error {
+	if err := checkComplianceOutput(compOutput); err != nil {
+		return err
+	}
+	fromID, toID := "latest~1", "latest"
+	if len(args) > 0 {
+		fromID = args[0]
+	}
+	if len(args) > 1 {
+		toID = args[1]
+	}
+	cs, err := incidentClient()
+	if err != nil {
+		return err
+	}
+	runs, err := listComplianceRuns(commandContext(cmd), cs)
+	if err != nil {
+		return err
+	}
+	from, err := findComplianceRun(runs, fromID)
+	if err != nil {
+		return err
+	}
+	to, err := findComplianceRun(runs, toID)
+	if err != nil {
+		return err
+	}
+	d := diffComplianceRuns(from, to)
+	switch compOutput {
+	case "json":
+		return helpers.PrintJSON(d)
+	case "yaml":
+		return helpers.PrintYAML(d)
+	}
+	printComplianceDiff(os.Stdout, d)
+	return nil
+}
+
+func printComplianceDiff(w io.Writer, d complianceDiff) {
+	fmt.Fprintf(w, "%s → %s: score %+.1f\n\n", d.From, d.To, d.Score)
+	if len(d.Frameworks) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "FRAMEWORK\tFROM\tTO\tCHANGE")
+		for _, f := range d.Frameworks {
+			fmt.Fprintf(tw, "%s\t%.1f%%\t%.1f%%\t%+.1f\n", f.Name, f.From, f.To, f.Delta)
+		}
+		_ = tw.Flush()
+		fmt.Fprintln(w)
+	}
+	if len(d.NewFailing)+len(d.Fixed)+len(d.Changed) == 0 {
+		fmt.Fprintln(w, helpers.CreateMuted("No control changed"))
+		return
+	}
+	for _, c := range d.NewFailing {
+		fmt.Fprintln(w, helpers.ErrorStyle.Render(fmt.Sprintf("+ %s %s (%s): %d failed", c.ID, c.Name, c.Severity, c.Failed)))
+	}
+	for _, c := range d.Fixed {
+		fmt.Fprintln(w, helpers.CreateSuccess(fmt.Sprintf("%s %s (%s) now passes", c.ID, c.Name, c.Severity)))
+	}
+	for _, c := range d.Changed {
+		fmt.Fprintf(w, "~ %s %s (%s): %d → %d failed\n", c.ID, c.Name, c.Severity, c.From, c.To)
+		for _, r := range c.Added {
+			fmt.Fprintln(w, "    + "+r)
+		}
+		for _, r := range c.Removed {
+			fmt.Fprintln(w, helpers.CreateMuted("    - "+r))
+		}
+	}
+}
+
+// --- report ---
+
+var complianceReportCmd = &cobra.Command{
+	Use:   "report [run]",
+	Short: "Export a markdown compliance report",
+	Long: `Write a markdown report of a stored run (default latest) for auditors: scores
+per framework, and every failed control with its ID, severity and the failed
+resources.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runComplianceReport,
+}
+
+var compReportOut string
+
+func init() {
+	complianceReportCmd.Flags().StringVar(&compReportOut, "out", "", "Write the report to this file instead of stdout")
+}
+
+func runComplianceReport(cmd *cobra.Command, args []string) error {
+	id := ""
+	if len(args) > 0 {
+		id = args[0]
+	}
+	cs, err := incidentClient()
+	if err != nil {
+		return err
+	}
+	runs, err := listComplianceRuns(commandContext(cmd), cs)
+	if err != nil {
+		return err
+	}
+	run, err := findComplianceRun(runs, id)
+	if err != nil {
+		return err
+	}
+	if compReportOut == "" {
+		return writeComplianceMarkdown(os.Stdout, run)
+	}
+	f, err := os.Create(compReportOut)
+	if err != nil {
+		return err
+	}
+	if err := writeComplianceMarkdown(f, run); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Println(helpers.CreateSuccess("Report for " + run.ID + " written to " + compReportOut))
+	return nil
+}
+
+// writeComplianceMarkdown renders a run as a markdown audit report.
+func writeComplianceMarkdown(w io.Writer, run *complianceRun) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Compliance report %s\n\n", run.ID)
+	fmt.Fprintf(&b, "- Scanned: %s\n", run.Time.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "- Source: %s\n", valueOr(run.Source, "kubescape"))
+	fmt.Fprintf(&b, "- Overall score: %.1f%%\n\n", run.Score)
+
+	b.WriteString("## Frameworks\n\n| Framework | Score |\n|---|---|\n")
+	for _, f := range run.Frameworks {
+		fmt.Fprintf(&b, "| %s | %.1f%% |\n", mdEscape(f.Name), f.Score)
+	}
+
+	failed := run.failedControls()
+	fmt.Fprintf(&b, "\n## Failed controls (%d of %d)\n\n", len(failed), len(run.Controls))
+	if len(failed) == 0 {
+		b.WriteString("All controls passed.\n")
+	} else {
+		b.WriteString("| Control | Severity | Name | Frameworks | Failed resources |\n|---|---|---|---|---|\n")
+		for _, c := range failed {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %d of %d |\n", c.ID, c.Severity, mdEscape(c.Name),
+				mdEscape(strings.Join(c.Frameworks, ", ")), c.Failed, c.Failed+c.Passed)
+		}
+		for _, c := range failed {
+			fmt.Fprintf(&b, "\n### %s %s\n\n", c.ID, mdEscape(c.Name))
+			fmt.Fprintf(&b, "Severity %s. %d resource(s) failed", c.Severity, c.Failed)
+			if len(c.FailedResources) < c.Failed {
+				fmt.Fprintf(&b, " (first %d listed)", len(c.FailedResources))
+			}
+			b.WriteString(":\n\n")
+			for _, r := range c.FailedResources {
+				fmt.Fprintf(&b, "- `%s`\n", r)
+			}
+		}
+	}
+
+	var passed []string
+	for _, c := range run.Controls {
+		if c.Status == "passed" {
+			passed = append(passed, c.ID)
+		}
+	}
+	if len(passed) > 0 {
+		fmt.Fprintf(&b, "\n## Passed controls (%d)\n\n%s\n", len(passed), strings.Join(passed, ", "))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mdEscape keeps table cells intact.
+func mdEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// checkComplianceOutput validates -o for a compliance subcommand.
+func checkComplianceOutput(format string, extra ...string) error {
+	formats := append([]string{"table", "json", "yaml"}, extra...)
+	if format == "" || containsString(formats, format) {
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %q (%s)", format, strings.Join(formats, ", "))
+}
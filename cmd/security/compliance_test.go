@@ -0,0 +1,178 @@
+package security
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// kubescapeReport is a trimmed `kubescape scan framework nsa,mitre --format json`.
+const kubescapeReport = `{
+  "summaryDetails": {
+    "complianceScore": 71.46,
+    "frameworks": [
+      {"name": "NSA", "complianceScore": 68.2, "controls": {"C-0016": {}, "C-0017": {}}},
+      {"name": "MITRE", "complianceScore": 80.04, "controls": {"C-0016": {}, "C-0035": {}}}
+    ],
+    "controls": {
+      "C-0016": {"controlID": "C-0016", "name": "Allow privilege escalation", "statusInfo": {"status": "failed"},
+                 "scoreFactor": 6, "ResourceCounters": {"passedResources": 8, "failedResources": 2}},
+      "C-0017": {"controlID": "C-0017", "name": "Immutable container filesystem", "status": "failed",
+                 "scoreFactor": 3, "ResourceCounters": {"passedResources": 1, "failedResources": 9}},
+      "C-0035": {"controlID": "C-0035", "name": "Administrative Roles", "statusInfo": {"status": "passed"},
+                 "scoreFactor": 6, "ResourceCounters": {"passedResources": 4}}
+    }
+  },
+  "results": [
+    {"resourceID": "apps/v1/prod/Deployment/api", "controls": [
+      {"controlID": "C-0016", "status": {"status": "failed"}},
+      {"controlID": "C-0017", "status": {"status": "failed"}},
+      {"controlID": "C-0035", "status": {"status": "passed"}}]},
+    {"resourceID": "apps/v1/prod/Deployment/web", "controls": [
+      {"controlID": "C-0016", "status": {"status": "failed"}}]}
+  ],
+  "resources": [
+    {"resourceID": "apps/v1/prod/Deployment/api",
+     "object": {"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "api", "namespace": "prod"}}}
+  ]
+}`
+
+func TestParsePostureReport(t *testing.T) {
+	run, err := parsePostureReport([]byte(kubescapeReport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Score != 71.5 || len(run.Frameworks) != 2 || run.Frameworks[1].Score != 80 {
+		t.Errorf("scores = %v %+v", run.Score, run.Frameworks)
+	}
+	if len(run.Controls) != 3 {
+		t.Fatalf("controls = %+v", run.Controls)
+	}
+	first := run.Controls[0]
+	if first.ID != "C-0016" || first.Severity != "MEDIUM" || first.Failed != 2 ||
+		strings.Join(first.FailedResources, ",") != "prod/Deployment/api,prod/Deployment/web" ||
+		strings.Join(first.Frameworks, ",") != "MITRE,NSA" {
+		t.Errorf("C-0016 = %+v", first)
+	}
+	if run.Controls[1].ID != "C-0017" || run.Controls[1].Severity != "LOW" || run.Controls[2].Status != "passed" {
+		t.Errorf("order/status = %+v", run.Controls)
+	}
+	if _, err := parsePostureReport([]byte(`{"results": []}`)); err == nil {
+		t.Error("expected an error for a report without a summary")
+	}
+}
+
+func TestComplianceStoreAndDiff(t *testing.T) {
+	cs := fake.NewSimpleClientset()
+	ctx := context.Background()
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i, score := range []float64{60, 65, 71.5} {
+		run, err := parsePostureReport([]byte(kubescapeReport))
+		if err != nil {
+			t.Fatal(err)
+		}
+		run.Score, run.Time = score, base.Add(time.Duration(i)*time.Hour)
+		run.ID = newComplianceRunID(run.Time)
+		if i == 0 {
+			// C-0035 failed on the first run and C-0016 passed.
+			run.Controls[0].Status, run.Controls[2].Status = "passed", "failed"
+		}
+		if i == 1 {
+			run.Controls[0].FailedResources = []string{"prod/Deployment/api"}
+			run.Controls[0].Failed = 1
+		}
+		if err := saveComplianceRun(ctx, cs, run, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runs, err := listComplianceRuns(ctx, cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || !strings.HasPrefix(runs[0].ID, "compliance-20261001-140000-") {
+		t.Fatalf("runs after pruning = %d, newest %s", len(runs), runs[0].ID)
+	}
+	if now := time.Now(); newComplianceRunID(now) == newComplianceRunID(now) {
+		t.Error("runs started in the same second share an ID")
+	}
+	prev, err := findComplianceRun(runs, "latest~1")
+	if err != nil || prev.Score != 65 {
+		t.Fatalf("latest~1 = %+v, %v", prev, err)
+	}
+	if _, err := findComplianceRun(runs, "latest~2"); err == nil {
+		t.Error("expected an error past the stored runs")
+	}
+
+	history := complianceHistory(runs, "")
+	if history[0].Delta == nil || *history[0].Delta != 6.5 || history[1].Delta != nil {
+		t.Errorf("history = %+v", history)
+	}
+	if nsa := complianceHistory(runs, "nsa"); len(nsa) != 2 || nsa[0].Score != 68.2 {
+		t.Errorf("nsa history = %+v", nsa)
+	}
+
+	d := diffComplianceRuns(prev, runs[0])
+	if d.Score != 6.5 || len(d.Changed) != 1 || strings.Join(d.Changed[0].Added, ",") != "prod/Deployment/web" {
+		t.Errorf("diff = %+v", d)
+	}
+
+	first, _ := parsePostureReport([]byte(kubescapeReport))
+	first.Controls[0].Status, first.Controls[2].Status = "passed", "failed"
+	d = diffComplianceRuns(first, runs[0])
+	if len(d.NewFailing) != 1 || d.NewFailing[0].ID != "C-0016" || len(d.Fixed) != 1 || d.Fixed[0].ID != "C-0035" {
+		t.Errorf("diff new/fixed = %+v", d)
+	}
+}
+
+func TestComplianceMarkdown(t *testing.T) {
+	run, err := parsePostureReport([]byte(kubescapeReport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	run.ID = "compliance-20261018-090000"
+	var buf bytes.Buffer
+	if err := writeComplianceMarkdown(&buf, run); err != nil {
+		t.Fatal(err)
+	}
+	md := buf.String()
+	for _, want := range []string{
+		"# Compliance report compliance-20261018-090000",
+		"| NSA | 68.2% |",
+		"| C-0016 | MEDIUM | Allow privilege escalation | MITRE, NSA | 2 of 10 |",
+		"### C-0017 Immutable container filesystem",
+		"9 resource(s) failed (first 1 listed)",
+		"- `prod/Deployment/web`",
+		"## Passed controls (1)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestPolicyFrameworks(t *testing.T) {
+	claim := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "platform.adhar.io/v1alpha1", "kind": "CompositeCompliancePolicy",
+		"metadata": map[string]interface{}{"name": "baseline", "namespace": "prod"},
+		"spec": map[string]interface{}{"parameters": map[string]interface{}{
+			"complianceStandards": []interface{}{"pod-security-standard", "soc2", "cis-kubernetes"},
+		}},
+	}}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{compliancePoliciesGVR: "CompositeCompliancePolicyList"}, claim)
+	got, err := policyFrameworks(context.Background(), dyn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, ",") != "cis,soc2" {
+		t.Errorf("frameworks = %v", got)
+	}
+}
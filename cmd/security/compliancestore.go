@@ -0,0 +1,195 @@
+package security
+
+// compliancestore.go keeps compliance run summaries as labelled ConfigMaps in
+// adhar-system, one per run, so scores can be tracked over time and any two
+// runs compared. Older runs beyond the retention count are pruned on save.
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	complianceLabel   = "adhar.io/compliance-run"
+	complianceDataKey = "run.json"
+)
+
+// newComplianceRunID returns a name like compliance-20261018-143005-3fa2c1.
+// The random suffix keeps runs started in the same second apart.
+func newComplianceRunID(now time.Time) string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return "compliance-" + now.UTC().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+// saveComplianceRun stores run and prunes all but the newest keep runs.
+func saveComplianceRun(ctx context.Context, cs kubernetes.Interface, run *complianceRun, keep int) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      run.ID,
+			Namespace: incidentNamespace,
+			Labels: map[string]string{
+				complianceLabel:                "true",
+				"app.kubernetes.io/managed-by": "adhar",
+			},
+		},
+		Data: map[string]string{complianceDataKey: string(data)},
+	}
+	if _, err := cs.CoreV1().ConfigMaps(incidentNamespace).Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("save compliance run %s: %w", run.ID, err)
+	}
+	if keep <= 0 {
+		return nil
+	}
+	runs, err := listComplianceRuns(ctx, cs)
+	if err != nil {
+		return err
+	}
+	for _, old := range runs[min(keep, len(runs)):] {
+		err := cs.CoreV1().ConfigMaps(incidentNamespace).Delete(ctx, old.ID, metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return fmt.Errorf("prune compliance run %s: %w", old.ID, err)
+		}
+	}
+	return nil
+}
+
+// listComplianceRuns returns the stored runs, newest first.
+func listComplianceRuns(ctx context.Context, cs kubernetes.Interface) ([]*complianceRun, error) {
+	list, err := cs.CoreV1().ConfigMaps(incidentNamespace).List(ctx, metav1.ListOptions{LabelSelector: complianceLabel + "=true"})
+	if err != nil {
+		return nil, fmt.Errorf("list compliance runs: %w", err)
+	}
+	var out []*complianceRun
+	for i := range list.Items {
+		var run complianceRun
+		if err := json.Unmarshal([]byte(list.Items[i].Data[complianceDataKey]), &run); err != nil {
+			fmt.Fprintf(os.Stderr, "compliance run %s is corrupt: %v\n", list.Items[i].Name, err)
+			continue
+		}
+		out = append(out, &run)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Time.After(out[j].Time) })
+	return out, nil
+}
+
+// findComplianceRun picks a run by ID, or the newest with "" ("latest").
+// "latest~N" is the run N before the newest.
+func findComplianceRun(runs []*complianceRun, id string) (*complianceRun, error) {
+	if id == "" || id == "latest" {
+		id = "latest~0"
+	}
+	var back int
+	if n, err := fmt.Sscanf(id, "latest~%d", &back); err == nil && n == 1 {
+		if back < 0 || back >= len(runs) {
+			return nil, fmt.Errorf("only %d compliance run(s) stored", len(runs))
+		}
+		return runs[back], nil
+	}
+	for _, r := range runs {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("compliance run %q not found", id)
+}
+
+// complianceDiff compares two runs.
+type complianceDiff struct {
+	From       string           `json:"from"`
+	To         string           `json:"to"`
+	Score      float64          `json:"scoreDelta"`
+	Frameworks []frameworkDelta `json:"frameworks"`
+	NewFailing []controlResult  `json:"newFailing"`
+	Fixed      []controlResult  `json:"fixed"`
+	Changed    []controlChange  `json:"changed"`
+}
+
+// frameworkDelta is a framework's score in both runs.
+type frameworkDelta struct {
+	Name  string  `json:"name"`
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Delta float64 `json:"delta"`
+}
+
+// controlChange is a control failing in both runs with a different set of
+// failed resources.
+type controlChange struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Severity string   `json:"severity"`
+	From     int      `json:"from"`
+	To       int      `json:"to"`
+	Added    []string `json:"added,omitempty"`
+	Removed  []string `json:"removed,omitempty"`
+}
+
+// diffComplianceRuns reports what changed from one run to another.
+func diffComplianceRuns(from, to *complianceRun) complianceDiff {
+	d := complianceDiff{From: from.ID, To: to.ID, Score: round1(to.Score - from.Score),
+		NewFailing: []controlResult{}, Fixed: []controlResult{}, Changed: []controlChange{}}
+
+	before := map[string]float64{}
+	for _, f := range from.Frameworks {
+		before[f.Name] = f.Score
+	}
+	for _, f := range to.Frameworks {
+		if old, ok := before[f.Name]; ok {
+			d.Frameworks = append(d.Frameworks, frameworkDelta{Name: f.Name, From: old, To: f.Score, Delta: round1(f.Score - old)})
+		}
+	}
+
+	old := map[string]controlResult{}
+	for _, c := range from.Controls {
+		old[c.ID] = c
+	}
+	// Controls missing from the newer run (a framework dropped from the scan)
+	// are not counted as fixed.
+	for _, c := range to.Controls {
+		prev, existed := old[c.ID]
+		switch {
+		case c.Status == "failed" && (!existed || prev.Status != "failed"):
+			d.NewFailing = append(d.NewFailing, c)
+		case c.Status != "failed" && existed && prev.Status == "failed":
+			d.Fixed = append(d.Fixed, prev)
+		case c.Status == "failed":
+			added, removed := setDiff(c.FailedResources, prev.FailedResources), setDiff(prev.FailedResources, c.FailedResources)
+			if c.Failed != prev.Failed || len(added) > 0 || len(removed) > 0 {
+				d.Changed = append(d.Changed, controlChange{ID: c.ID, Name: c.Name, Severity: c.Severity,
+					From: prev.Failed, To: c.Failed, Added: added, Removed: removed})
+			}
+		}
+	}
+	return d
+}
+
+// setDiff returns the items of a not in b.
+func setDiff(a, b []string) []string {
+	in := map[string]bool{}
+	for _, s := range b {
+		in[s] = true
+	}
+	var out []string
+	for _, s := range a {
+		if !in[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
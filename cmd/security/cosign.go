@@ -0,0 +1,499 @@
+package security
+
+// cosign.go verifies cosign signatures and attestations straight from the
+// registry. cosign stores them next to the image as OCI artifacts tagged
+// sha256-<digest>.sig and sha256-<digest>.att: signature layers carry a
+// simple-signing payload with the signature in an annotation, attestation
+// layers are DSSE envelopes around in-toto statements. Keyless signatures
+// also carry the Fulcio certificate whose identity and issuer are checked,
+// and the Rekor bundle proving when the signature was logged; both are
+// verified against the Sigstore trusted root with sigstore-go.
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	protocommon "github.com/sigstore/protobuf-specs/gen/pb-go/common/v1"
+	rekorv1 "github.com/sigstore/protobuf-specs/gen/pb-go/rekor/v1"
+	"github.com/sigstore/sigstore-go/pkg/root"
+	"github.com/sigstore/sigstore-go/pkg/tlog"
+	"github.com/sigstore/sigstore-go/pkg/verify"
+)
+
+// cosign annotations and media types.
+const (
+	cosignSignatureAnnotation   = "dev.cosignproject.cosign/signature"
+	cosignCertificateAnnotation = "dev.sigstore.cosign/certificate"
+	cosignBundleAnnotation      = "dev.sigstore.cosign/bundle"
+	dsseMediaType               = "application/vnd.dsse.envelope.v1+json"
+)
+
+// Fulcio certificate extensions naming the OIDC issuer: the original raw
+// string and its DER-encoded successor.
+var (
+	fulcioIssuerV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+	fulcioIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// attestationTypes maps the short names accepted by --attestation to the
+// in-toto predicate types that satisfy them.
+var attestationTypes = map[string][]string{
+	"sbom": {"https://spdx.dev/Document", "https://cyclonedx.org/bom"},
+	"slsa": {"https://slsa.dev/provenance/v0.2", "https://slsa.dev/provenance/v1"},
+	"vuln": {"https://cosign.sigstore.dev/attestation/vuln/v1"},
+}
+
+// attestationName returns the short name for a predicate type, or the type
+// itself.
+func attestationName(predicateType string) string {
+	for name, types := range attestationTypes {
+		for _, t := range types {
+			if strings.HasPrefix(predicateType, t) {
+				return name
+			}
+		}
+	}
+	return predicateType
+}
+
+// trustedKey is a public key signatures may be made with.
+type trustedKey struct {
+	Name string
+	PEM  string
+	Key  crypto.PublicKey
+}
+
+// trustPolicy says which signers are trusted and what must be attested.
+type trustPolicy struct {
+	Keys           []trustedKey
+	Identity       string
+	IdentityRegexp *regexp.Regexp
+	Issuer         string
+	// Trusted holds the Fulcio CAs and Rekor keys keyless signatures are
+	// verified against. Keyless signatures are refused without it.
+	Trusted      root.TrustedMaterial
+	Attestations []string
+}
+
+func (p *trustPolicy) keyless() bool {
+	return p.Identity != "" || p.IdentityRegexp != nil
+}
+
+// loadPublicKey reads a PEM public key (cosign.pub).
+func loadPublicKey(path string) (trustedKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return trustedKey{}, err
+	}
+	key, err := parsePublicKey(data)
+	if err != nil {
+		return trustedKey{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return trustedKey{Name: path, PEM: strings.TrimSpace(string(data)), Key: key}, nil
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM public key found")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+// verifyDigest checks a signature over data with key, using the digest
+// cosign signs with for the key type.
+func verifyDigest(key crypto.PublicKey, data, sig []byte) bool {
+	sum := sha256.Sum256(data)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(k, sum[:], sig)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig) == nil ||
+			rsa.VerifyPSS(k, crypto.SHA256, sum[:], sig, nil) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(k, data, sig)
+	}
+	return false
+}
+
+// ociManifest is the part of an OCI image manifest cosign artifacts use.
+type ociManifest struct {
+	Layers []struct {
+		MediaType   string            `json:"mediaType"`
+		Digest      string            `json:"digest"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"layers"`
+}
+
+// imageVerification is the result for one image.
+type imageVerification struct {
+	Image        string   `json:"image"`
+	Digest       string   `json:"digest"`
+	Workloads    []string `json:"workloads,omitempty"`
+	Status       string   `json:"status"` // verified, unsigned, untrusted, incomplete, error
+	Signers      []string `json:"signers,omitempty"`
+	Attestations []string `json:"attestations,omitempty"`
+	Missing      []string `json:"missingAttestations,omitempty"`
+	Problems     []string `json:"problems,omitempty"`
+	// predicates are the verified predicate types, for policy generation.
+	predicates []string
+}
+
+// attested reports whether a required attestation, by short name or
+// predicate type, was verified.
+func (r *imageVerification) attested(want string) bool {
+	return containsString(r.Attestations, want) || containsString(r.predicates, want)
+}
+
+// cosignVerifier checks images against a trust policy.
+type cosignVerifier struct {
+	registry *registryClient
+	policy   *trustPolicy
+}
+
+// verify checks the signatures and attestations of image at digest.
+func (v *cosignVerifier) verify(ctx context.Context, image, digest string) imageVerification {
+	res := imageVerification{Image: image, Digest: digest}
+	host, repo, _ := splitImage(image)
+	tag := strings.Replace(digest, ":", "-", 1)
+
+	sigFound, err := v.verifySignatures(ctx, host, repo, tag+".sig", digest, &res)
+	if err != nil {
+		res.Status = "error"
+		res.Problems = append(res.Problems, err.Error())
+		return res
+	}
+	if _, err := v.verifyAttestations(ctx, host, repo, tag+".att", digest, &res); err != nil {
+		res.Problems = append(res.Problems, err.Error())
+	}
+	for _, want := range v.policy.Attestations {
+		if !res.attested(want) {
+			res.Missing = append(res.Missing, want)
+		}
+	}
+	switch {
+	case len(res.Signers) == 0 && !sigFound:
+		res.Status = "unsigned"
+	case len(res.Signers) == 0:
+		res.Status = "untrusted"
+	case len(res.Missing) > 0:
+		res.Status = "incomplete"
+	default:
+		res.Status = "verified"
+	}
+	sort.Strings(res.Attestations)
+	return res
+}
+
+// verifySignatures verifies the simple-signing layers of the .sig artifact.
+// It reports whether any signature exists at all.
+func (v *cosignVerifier) verifySignatures(ctx context.Context, host, repo, tag, digest string, res *imageVerification) (bool, error) {
+	data, err := v.registry.manifest(ctx, host, repo, tag)
+	if errors.Is(err, errNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var m ociManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return false, fmt.Errorf("parse signature manifest: %w", err)
+	}
+	for _, layer := range m.Layers {
+		sig, err := base64.StdEncoding.DecodeString(layer.Annotations[cosignSignatureAnnotation])
+		if err != nil || len(sig) == 0 {
+			continue
+		}
+		payload, err := v.registry.blob(ctx, host, repo, layer.Digest)
+		if err != nil {
+			res.Problems = append(res.Problems, "signature payload: "+err.Error())
+			continue
+		}
+		var simple struct {
+			Critical struct {
+				Image struct {
+					Digest string `json:"docker-manifest-digest"`
+				} `json:"image"`
+			} `json:"critical"`
+		}
+		if err := json.Unmarshal(payload, &simple); err != nil || simple.Critical.Image.Digest != digest {
+			res.Problems = append(res.Problems, "signature payload does not name this digest")
+			continue
+		}
+		signer, err := v.checkSigner(layer.Annotations, payload, sig)
+		if err != nil {
+			res.Problems = append(res.Problems, "signature: "+err.Error())
+			continue
+		}
+		if !containsString(res.Signers, signer) {
+			res.Signers = append(res.Signers, signer)
+		}
+	}
+	return true, nil
+}
+
+// verifyAttestations verifies the DSSE envelopes of the .att artifact and
+// records the predicate types of those that check out.
+func (v *cosignVerifier) verifyAttestations(ctx context.Context, host, repo, tag, digest string, res *imageVerification) (bool, error) {
+	data, err := v.registry.manifest(ctx, host, repo, tag)
+	if errors.Is(err, errNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	var m ociManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return false, fmt.Errorf("parse attestation manifest: %w", err)
+	}
+	for _, layer := range m.Layers {
+		if layer.MediaType != dsseMediaType {
+			continue
+		}
+		raw, err := v.registry.blob(ctx, host, repo, layer.Digest)
+		if err != nil {
+			res.Problems = append(res.Problems, "attestation: "+err.Error())
+			continue
+		}
+		predicate, err := v.checkEnvelope(layer.Annotations, raw, digest)
+		if err != nil {
+			res.Problems = append(res.Problems, "attestation: "+err.Error())
+			continue
+		}
+		if name := attestationName(predicate); !containsString(res.Attestations, name) {
+			res.Attestations = append(res.Attestations, name)
+		}
+		if !containsString(res.predicates, predicate) {
+			res.predicates = append(res.predicates, predicate)
+		}
+	}
+	return true, nil
+}
+
+// checkEnvelope verifies a DSSE envelope and returns its predicate type.
+func (v *cosignVerifier) checkEnvelope(annotations map[string]string, raw []byte, digest string) (string, error) {
+	var env struct {
+		PayloadType string `json:"payloadType"`
+		Payload     string `json:"payload"`
+		Signatures  []struct {
+			Sig string `json:"sig"`
+		} `json:"signatures"`
+	}
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return "", fmt.Errorf("parse envelope: %w", err)
+	}
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return "", fmt.Errorf("decode envelope payload: %w", err)
+	}
+	pae := dssePAE(env.PayloadType, payload)
+	verified := false
+	var lastErr error
+	for _, s := range env.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(s.Sig)
+		if err != nil {
+			continue
+		}
+		if _, lastErr = v.checkSigner(annotations, pae, sig); lastErr == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		if lastErr == nil {
+			lastErr = errors.New("envelope is not signed")
+		}
+		return "", lastErr
+	}
+
+	var statement struct {
+		PredicateType string `json:"predicateType"`
+		Subject       []struct {
+			Digest map[string]string `json:"digest"`
+		} `json:"subject"`
+	}
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return "", fmt.Errorf("parse in-toto statement: %w", err)
+	}
+	algo, hexDigest, _ := strings.Cut(digest, ":")
+	for _, s := range statement.Subject {
+		if s.Digest[algo] == hexDigest {
+			return statement.PredicateType, nil
+		}
+	}
+	return "", fmt.Errorf("%s statement is about another image", attestationName(statement.PredicateType))
+}
+
+// dssePAE is the DSSE pre-authentication encoding that is actually signed.
+func dssePAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// checkSigner verifies sig over data with a trusted key or, for keyless
+// signatures, with the attached certificate, returning who signed.
+func (v *cosignVerifier) checkSigner(annotations map[string]string, data, sig []byte) (string, error) {
+	for _, k := range v.policy.Keys {
+		if verifyDigest(k.Key, data, sig) {
+			return "key:" + k.Name, nil
+		}
+	}
+	certPEM := annotations[cosignCertificateAnnotation]
+	if certPEM == "" || !v.policy.keyless() {
+		return "", errors.New("not signed by a trusted key")
+	}
+	if v.policy.Trusted == nil {
+		return "", errors.New("keyless signature cannot be checked without a trusted root")
+	}
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return "", err
+	}
+	if !verifyDigest(cert.PublicKey, data, sig) {
+		return "", errors.New("signature does not match its certificate")
+	}
+	identity, issuer := certIdentity(cert), certIssuer(cert)
+	if err := v.checkIdentity(identity, issuer); err != nil {
+		return "", err
+	}
+	if err := verifyKeyless(cert, data, sig, annotations[cosignBundleAnnotation], v.policy.Trusted); err != nil {
+		return "", err
+	}
+	return "keyless:" + identity + " (" + issuer + ")", nil
+}
+
+func (v *cosignVerifier) checkIdentity(identity, issuer string) error {
+	p := v.policy
+	if p.Identity != "" && identity != p.Identity {
+		return fmt.Errorf("signed by %s, not %s", identity, p.Identity)
+	}
+	if p.IdentityRegexp != nil && !p.IdentityRegexp.MatchString(identity) {
+		return fmt.Errorf("signer %s does not match %s", identity, p.IdentityRegexp)
+	}
+	if p.Issuer != "" && issuer != p.Issuer {
+		return fmt.Errorf("certificate issued for %s, not %s", issuer, p.Issuer)
+	}
+	return nil
+}
+
+func parseCertificate(data string) (*x509.Certificate, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("invalid signing certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// certIdentity returns the subject alternative name Fulcio put the signer's
+// identity in: an email or a URI (workflow or service account).
+func certIdentity(cert *x509.Certificate) string {
+	if len(cert.EmailAddresses) > 0 {
+		return cert.EmailAddresses[0]
+	}
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	return ""
+}
+
+func certIssuer(cert *x509.Certificate) string {
+	for _, ext := range cert.Extensions {
+		switch {
+		case ext.Id.Equal(fulcioIssuerV2):
+			var s string
+			if _, err := asn1.Unmarshal(ext.Value, &s); err == nil {
+				return s
+			}
+		case ext.Id.Equal(fulcioIssuerV1):
+			return string(ext.Value)
+		}
+	}
+	return ""
+}
+
+// verifyKeyless checks that the signature was entered in a trusted Rekor log
+// while its certificate was valid, and that the certificate chains to a
+// trusted Fulcio CA at that time. Fulcio certificates only live for minutes,
+// so the log's integrated time is what dates the signature; it is used only
+// after the bundle's signed entry timestamp has been verified.
+func verifyKeyless(cert *x509.Certificate, data, sig []byte, bundle string, trusted root.TrustedMaterial) error {
+	if bundle == "" {
+		return errors.New("keyless signature has no transparency log bundle")
+	}
+	entry, err := rekorBundleEntry(bundle)
+	if err != nil {
+		return fmt.Errorf("invalid transparency log bundle: %w", err)
+	}
+	if err := tlog.VerifySET(entry, trusted.RekorLogs()); err != nil {
+		return fmt.Errorf("transparency log bundle is not trusted: %w", err)
+	}
+	if logged, ok := entry.PublicKey().(*x509.Certificate); !ok || !logged.Equal(cert) {
+		return errors.New("transparency log entry is for another certificate")
+	}
+	if digest, _, ok := entry.GetHashedRekordDigest(); ok {
+		sum := sha256.Sum256(data)
+		if !bytes.Equal(digest, sum[:]) || !bytes.Equal(entry.Signature(), sig) {
+			return errors.New("transparency log entry is for another signature")
+		}
+	}
+	if _, err := verify.VerifyLeafCertificate(entry.IntegratedTime(), cert, trusted); err != nil {
+		return fmt.Errorf("signing certificate is not trusted: %w", err)
+	}
+	return nil
+}
+
+// rekorBundleEntry decodes the Rekor bundle cosign attaches to a signature:
+// the log entry and the signed entry timestamp (SET) promising its inclusion.
+func rekorBundleEntry(bundle string) (*tlog.Entry, error) {
+	var b struct {
+		SignedEntryTimestamp []byte `json:"SignedEntryTimestamp"`
+		Payload              struct {
+			Body           string `json:"body"`
+			IntegratedTime int64  `json:"integratedTime"`
+			LogIndex       int64  `json:"logIndex"`
+			LogID          string `json:"logID"`
+		} `json:"Payload"`
+	}
+	if err := json.Unmarshal([]byte(bundle), &b); err != nil {
+		return nil, err
+	}
+	body, err := base64.StdEncoding.DecodeString(b.Payload.Body)
+	if err != nil {
+		return nil, fmt.Errorf("entry body: %w", err)
+	}
+	logID, err := hex.DecodeString(b.Payload.LogID)
+	if err != nil {
+		return nil, fmt.Errorf("log ID: %w", err)
+	}
+	var kind struct {
+		Kind       string `json:"kind"`
+		APIVersion string `json:"apiVersion"`
+	}
+	if err := json.Unmarshal(body, &kind); err != nil {
+		return nil, fmt.Errorf("entry body: %w", err)
+	}
+	return tlog.NewTlogEntry(&rekorv1.TransparencyLogEntry{
+		LogIndex:          b.Payload.LogIndex,
+		LogId:             &protocommon.LogId{KeyId: logID},
+		KindVersion:       &rekorv1.KindVersion{Kind: kind.Kind, Version: kind.APIVersion},
+		IntegratedTime:    b.Payload.IntegratedTime,
+		InclusionPromise:  &rekorv1.InclusionPromise{SignedEntryTimestamp: b.SignedEntryTimestamp},
+		CanonicalizedBody: body,
+	})
+}
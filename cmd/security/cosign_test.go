@@ -0,0 +1,409 @@
+package security
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sigstore/sigstore-go/pkg/root"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// fakeRegistry is an in-memory OCI registry serving manifests and blobs.
+type fakeRegistry struct {
+	t         *testing.T
+	manifests map[string][]byte // repo:reference → manifest
+	blobs     map[string][]byte // digest → content
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, *httptest.Server) {
+	r := &fakeRegistry{t: t, manifests: map[string][]byte{}, blobs: map[string][]byte{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		path := strings.TrimPrefix(req.URL.Path, "/v2/")
+		if repo, ref, ok := strings.Cut(path, "/manifests/"); ok {
+			data, found := r.manifests[repo+":"+ref]
+			if !found {
+				http.NotFound(w, req)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", digestOf(data))
+			if req.Method == http.MethodGet {
+				_, _ = w.Write(data)
+			}
+			return
+		}
+		if _, digest, ok := strings.Cut(path, "/blobs/"); ok {
+			if data, found := r.blobs[digest]; found {
+				_, _ = w.Write(data)
+				return
+			}
+		}
+		http.NotFound(w, req)
+	}))
+	t.Cleanup(srv.Close)
+	return r, srv
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// push stores an image manifest under tag and returns its digest.
+func (r *fakeRegistry) push(repo, tag string) string {
+	data := []byte(`{"schemaVersion":2,"config":{"digest":"` + repo + tag + `"}}`)
+	r.manifests[repo+":"+tag] = data
+	digest := digestOf(data)
+	r.manifests[repo+":"+digest] = data
+	return digest
+}
+
+// attach stores a cosign artifact (.sig or .att) with one layer.
+func (r *fakeRegistry) attach(repo, digest, suffix, mediaType string, content []byte, annotations map[string]string) {
+	r.blobs[digestOf(content)] = content
+	m := map[string]interface{}{
+		"schemaVersion": 2,
+		"layers": []interface{}{map[string]interface{}{
+			"mediaType": mediaType, "digest": digestOf(content), "size": len(content), "annotations": annotations,
+		}},
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	r.manifests[repo+":"+strings.Replace(digest, ":", "-", 1)+suffix] = data
+}
+
+func signPayload(t *testing.T, key *ecdsa.PrivateKey, data []byte) string {
+	sum := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func simpleSigning(digest string) []byte {
+	return []byte(`{"critical":{"identity":{"docker-reference":"x"},"image":{"docker-manifest-digest":"` + digest +
+		`"},"type":"cosign container image signature"},"optional":null}`)
+}
+
+func (r *fakeRegistry) sign(t *testing.T, repo, digest string, key *ecdsa.PrivateKey, annotations map[string]string) {
+	payload := simpleSigning(digest)
+	a := map[string]string{cosignSignatureAnnotation: signPayload(t, key, payload)}
+	for k, v := range annotations {
+		a[k] = v
+	}
+	r.attach(repo, digest, ".sig", "application/vnd.dev.cosign.simplesigning.v1+json", payload, a)
+}
+
+func (r *fakeRegistry) attest(t *testing.T, repo, digest, predicateType string, key *ecdsa.PrivateKey) {
+	_, hexDigest, _ := strings.Cut(digest, ":")
+	statement, _ := json.Marshal(map[string]interface{}{
+		"_type":         "https://in-toto.io/Statement/v0.1",
+		"predicateType": predicateType,
+		"subject":       []interface{}{map[string]interface{}{"name": repo, "digest": map[string]string{"sha256": hexDigest}}},
+		"predicate":     map[string]interface{}{},
+	})
+	payloadType := "application/vnd.in-toto+json"
+	envelope, _ := json.Marshal(map[string]interface{}{
+		"payloadType": payloadType,
+		"payload":     base64.StdEncoding.EncodeToString(statement),
+		"signatures":  []interface{}{map[string]string{"sig": signPayload(t, key, dssePAE(payloadType, statement))}},
+	})
+	r.attach(repo, digest, ".att", dsseMediaType, envelope, map[string]string{"predicateType": predicateType})
+}
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestVerifyKeyed(t *testing.T) {
+	reg, srv := newFakeRegistry(t)
+	host := strings.TrimPrefix(srv.URL, "http://")
+	trusted, other := newKey(t), newKey(t)
+
+	signed := reg.push("team/api", "1.0")
+	reg.sign(t, "team/api", signed, trusted, nil)
+	reg.attest(t, "team/api", signed, "https://slsa.dev/provenance/v0.2", trusted)
+	foreign := reg.push("team/web", "2.0")
+	reg.sign(t, "team/web", foreign, other, nil)
+	reg.push("team/bare", "3.0")
+
+	trust := &trustPolicy{Keys: []trustedKey{{Name: "cosign.pub", Key: &trusted.PublicKey}}, Attestations: []string{"slsa"}}
+	v := &cosignVerifier{registry: &registryClient{HTTP: srv.Client(), Scheme: "http"}, policy: trust}
+	results := verifyDeployedImages(context.Background(), v, []deployedImage{
+		{Image: host + "/team/api:1.0"},
+		{Image: host + "/team/web:2.0"},
+		{Image: host + "/team/bare:3.0"},
+	})
+	status := map[string]string{}
+	for _, r := range results {
+		status[strings.TrimPrefix(r.Image, host+"/")] = r.Status
+	}
+	if status["team/api:1.0"] != "verified" || status["team/web:2.0"] != "untrusted" || status["team/bare:3.0"] != "unsigned" {
+		t.Fatalf("statuses = %v", status)
+	}
+	if results[0].Status != "unsigned" {
+		t.Errorf("unsigned images should sort first, got %s", results[0].Status)
+	}
+
+	trust.Attestations = []string{"slsa", "sbom"}
+	res := v.verify(context.Background(), host+"/team/api:1.0", signed)
+	if res.Status != "incomplete" || strings.Join(res.Missing, ",") != "sbom" {
+		t.Fatalf("verify with sbom required = %+v", res)
+	}
+	if res.Signers[0] != "key:cosign.pub" {
+		t.Errorf("signers = %v", res.Signers)
+	}
+}
+
+func TestVerifyRejectsPayloadForOtherDigest(t *testing.T) {
+	reg, srv := newFakeRegistry(t)
+	key := newKey(t)
+	a := reg.push("team/api", "1.0")
+	b := reg.push("team/api", "1.1")
+	// A valid signature over a's digest copied to b.
+	payload := simpleSigning(a)
+	reg.attach("team/api", b, ".sig", "application/vnd.dev.cosign.simplesigning.v1+json", payload,
+		map[string]string{cosignSignatureAnnotation: signPayload(t, key, payload)})
+
+	v := &cosignVerifier{registry: &registryClient{HTTP: srv.Client(), Scheme: "http"},
+		policy: &trustPolicy{Keys: []trustedKey{{Name: "k", Key: &key.PublicKey}}}}
+	res := v.verify(context.Background(), strings.TrimPrefix(srv.URL, "http://")+"/team/api:1.1", b)
+	if res.Status != "untrusted" {
+		t.Fatalf("status = %s, want untrusted", res.Status)
+	}
+}
+
+// fulcioCert issues a code-signing certificate for identity and issuer and
+// returns its key, the certificate PEM and the issuing CA.
+func fulcioCert(t *testing.T, identity, issuer string) (*ecdsa.PrivateKey, string, *x509.Certificate) {
+	caKey := newKey(t)
+	caTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "sigstore"},
+		NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour),
+		IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+	issuerExt, _ := asn1.Marshal(issuer)
+	leafKey := newKey(t)
+	leafTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2), NotBefore: time.Now().Add(-time.Minute), NotAfter: time.Now().Add(10 * time.Minute),
+		EmailAddresses:  []string{identity},
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		ExtraExtensions: []pkix.Extension{{Id: fulcioIssuerV2, Value: issuerExt}},
+	}
+	leafDER, err := x509.CreateCertificate(rand.Reader, leafTmpl, ca, &leafKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leafKey, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leafDER})), ca
+}
+
+// rekorLogID is the log ID Rekor derives from its public key.
+func rekorLogID(t *testing.T, key *ecdsa.PrivateKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(der)
+	return sum[:]
+}
+
+// trustedRoot trusts the Fulcio CA ca and the Rekor log signing with rekorKey.
+func trustedRoot(t *testing.T, ca *x509.Certificate, rekorKey *ecdsa.PrivateKey) *root.TrustedRoot {
+	logID := rekorLogID(t, rekorKey)
+	start := time.Now().Add(-time.Hour)
+	tr, err := root.NewTrustedRoot(root.TrustedRootMediaType01,
+		[]root.CertificateAuthority{&root.FulcioCertificateAuthority{Root: ca, ValidityPeriodStart: start}},
+		nil, nil,
+		map[string]*root.TransparencyLog{hex.EncodeToString(logID): {
+			BaseURL: "https://rekor.example", ID: logID, ValidityPeriodStart: start,
+			HashFunc: crypto.SHA256, PublicKey: &rekorKey.PublicKey, SignatureHashFunc: crypto.SHA256,
+		}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tr
+}
+
+// rekorBundle logs a hashedrekord entry for sig over payload and returns the
+// bundle annotation with a signed entry timestamp from rekorKey.
+func rekorBundle(t *testing.T, rekorKey *ecdsa.PrivateKey, certPEM string, payload []byte, sig string) string {
+	sum := sha256.Sum256(payload)
+	body, _ := json.Marshal(map[string]interface{}{
+		"apiVersion": "0.0.1",
+		"kind":       "hashedrekord",
+		"spec": map[string]interface{}{
+			"data": map[string]interface{}{"hash": map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(sum[:])}},
+			"signature": map[string]interface{}{
+				"content":   sig,
+				"publicKey": map[string]string{"content": base64.StdEncoding.EncodeToString([]byte(certPEM))},
+			},
+		},
+	})
+	entry := map[string]interface{}{
+		"body":           base64.StdEncoding.EncodeToString(body),
+		"integratedTime": time.Now().Unix(),
+		"logIndex":       42,
+		"logID":          hex.EncodeToString(rekorLogID(t, rekorKey)),
+	}
+	// encoding/json sorts map keys and adds no whitespace, which is the
+	// canonical form Rekor signs for this payload.
+	canonical, _ := json.Marshal(entry)
+	set := signPayload(t, rekorKey, canonical)
+	bundle, _ := json.Marshal(map[string]interface{}{"SignedEntryTimestamp": set, "Payload": entry})
+	return string(bundle)
+}
+
+func TestVerifyKeyless(t *testing.T) {
+	reg, srv := newFakeRegistry(t)
+	host := strings.TrimPrefix(srv.URL, "http://")
+	key, cert, ca := fulcioCert(t, "ci@acme.dev", "https://keycloak.example/realms/adhar")
+	rekorKey := newKey(t)
+
+	digest := reg.push("team/api", "1.0")
+	payload := simpleSigning(digest)
+	sig := signPayload(t, key, payload)
+	reg.attach("team/api", digest, ".sig", "application/vnd.dev.cosign.simplesigning.v1+json", payload, map[string]string{
+		cosignSignatureAnnotation:   sig,
+		cosignCertificateAnnotation: cert,
+		cosignBundleAnnotation:      rekorBundle(t, rekorKey, cert, payload, sig),
+	})
+	unlogged := reg.push("team/api", "1.1")
+	reg.sign(t, "team/api", unlogged, key, map[string]string{cosignCertificateAnnotation: cert})
+
+	digests := map[string]string{"1.0": digest, "1.1": unlogged}
+
+	_, _, otherCA := fulcioCert(t, "ci@acme.dev", "https://keycloak.example/realms/adhar")
+	trusted := trustedRoot(t, ca, rekorKey)
+	issuer := "https://keycloak.example/realms/adhar"
+	rc := &registryClient{HTTP: srv.Client(), Scheme: "http"}
+	for name, tc := range map[string]struct {
+		policy *trustPolicy
+		tag    string
+		want   string
+	}{
+		"identity":        {&trustPolicy{Identity: "ci@acme.dev", Issuer: issuer, Trusted: trusted}, "1.0", "verified"},
+		"regexp":          {&trustPolicy{IdentityRegexp: regexp.MustCompile(`@acme\.dev$`), Issuer: issuer, Trusted: trusted}, "1.0", "verified"},
+		"other identity":  {&trustPolicy{Identity: "dev@acme.dev", Issuer: issuer, Trusted: trusted}, "1.0", "untrusted"},
+		"other issuer":    {&trustPolicy{Identity: "ci@acme.dev", Issuer: "https://accounts.google.com", Trusted: trusted}, "1.0", "untrusted"},
+		"no trusted root": {&trustPolicy{Identity: "ci@acme.dev", Issuer: issuer}, "1.0", "untrusted"},
+		"untrusted CA":    {&trustPolicy{Identity: "ci@acme.dev", Issuer: issuer, Trusted: trustedRoot(t, otherCA, rekorKey)}, "1.0", "untrusted"},
+		"untrusted rekor": {&trustPolicy{Identity: "ci@acme.dev", Issuer: issuer, Trusted: trustedRoot(t, ca, newKey(t))}, "1.0", "untrusted"},
+		"not logged":      {&trustPolicy{Identity: "ci@acme.dev", Issuer: issuer, Trusted: trusted}, "1.1", "untrusted"},
+	} {
+		v := &cosignVerifier{registry: rc, policy: tc.policy}
+		if res := v.verify(context.Background(), host+"/team/api:"+tc.tag, digests[tc.tag]); res.Status != tc.want {
+			t.Errorf("%s: status = %s (%v), want %s", name, res.Status, res.Problems, tc.want)
+		}
+	}
+}
+
+func TestKyvernoVerifyPolicy(t *testing.T) {
+	trust := &trustPolicy{Identity: "ci@acme.dev", Issuer: "https://issuer", Attestations: []string{"slsa"}}
+	results := []imageVerification{
+		{Image: "ghcr.io/acme/api:1.0", Status: "verified", predicates: []string{"https://slsa.dev/provenance/v0.2", "https://spdx.dev/Document"}},
+		{Image: "nginx:1.25", Status: "unsigned"},
+	}
+	policy, err := kyvernoVerifyPolicy(results, trust, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(policy)
+	s := string(data)
+	for _, want := range []string{`"validationFailureAction":"Enforce"`, `"imageReferences":["ghcr.io/acme/api:*","ghcr.io/acme/api@*"]`,
+		`"type":"https://slsa.dev/provenance/v0.2"`, `"subject":"ci@acme.dev"`, `"url":"https://rekor.sigstore.dev"`} {
+		if !strings.Contains(s, want) {
+			t.Errorf("policy missing %s: %s", want, s)
+		}
+	}
+	if strings.Contains(s, "nginx") || strings.Contains(s, "spdx") {
+		t.Errorf("policy covers unverified images or unrequired attestations: %s", s)
+	}
+	if _, err := kyvernoVerifyPolicy(results[1:], trust, false); err == nil {
+		t.Error("expected an error without verified images")
+	}
+
+	_, _, ca := fulcioCert(t, "ci@acme.dev", "https://issuer")
+	trust.Trusted = trustedRoot(t, ca, newKey(t))
+	policy, err = kyvernoVerifyPolicy(results, trust, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = json.Marshal(policy)
+	if !strings.Contains(string(data), `"url":"https://rekor.example"`) {
+		t.Errorf("policy does not use the trusted root's Rekor log: %s", data)
+	}
+}
+
+func TestPodImages(t *testing.T) {
+	controller := true
+	pod := func(ns, name, image, imageID string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: name, OwnerReferences: []metav1.OwnerReference{
+				{Kind: "ReplicaSet", Name: "api-7d9f8b6c5d", Controller: &controller}}},
+			Spec:   corev1.PodSpec{Containers: []corev1.Container{{Name: "app", Image: image}}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "app", Image: image, ImageID: imageID}}},
+		}
+	}
+	cs := fake.NewSimpleClientset(
+		pod("prod", "api-1", "ghcr.io/acme/api:1.0", "ghcr.io/acme/api@sha256:aaa"),
+		pod("prod", "api-2", "ghcr.io/acme/api:1.0", "ghcr.io/acme/api@sha256:aaa"),
+		pod("prod", "local", "app:dev", "sha256:bbb"),
+		pod("kube-system", "dns", "coredns:1.11", "registry.k8s.io/coredns@sha256:ccc"),
+	)
+	images, err := podImages(context.Background(), cs, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 2 {
+		t.Fatalf("images = %+v", images)
+	}
+	for _, img := range images {
+		switch img.Image {
+		case "ghcr.io/acme/api:1.0":
+			if img.Digest != "sha256:aaa" || len(img.Workloads) != 1 || img.Workloads[0] != "prod/Deployment/api" {
+				t.Errorf("api = %+v", img)
+			}
+		case "app:dev":
+			if img.Digest != "" {
+				t.Errorf("node-local image got digest %s", img.Digest)
+			}
+		default:
+			t.Errorf("unexpected image %s", img.Image)
+		}
+	}
+	if all, _ := podImages(context.Background(), cs, "", true); len(all) != 3 {
+		t.Errorf("--all-namespaces found %d images", len(all))
+	}
+}
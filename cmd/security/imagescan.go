@@ -0,0 +1,159 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"adhar-io/adhar/platform/k8s"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// trivyNamespace is where the platform runs the Trivy Operator and where
+	// one-off image scans are scheduled.
+	trivyNamespace = "adhar-system"
+	// trivyImage matches the scanner the operator is configured with
+	// (trivy.repository/trivy.tag in the trivy package).
+	trivyImage = "mirror.gcr.io/aquasec/trivy:0.71.0"
+)
+
+// scanImageWithJob scans an image the operator has no report for by running
+// trivy in a one-off Job and parsing its JSON output.
+func scanImageWithJob(ctx context.Context, ref string, wait time.Duration) ([]finding, error) {
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the cluster (is it running? try `adhar up`): %w", err)
+	}
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "adhar-image-scan-",
+			Namespace:    trivyNamespace,
+			Labels:       map[string]string{"app.kubernetes.io/managed-by": "adhar", "adhar.io/scan": "image"},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            int32Ptr(0),
+			TTLSecondsAfterFinished: int32Ptr(600),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:  "trivy",
+						Image: trivyImage,
+						Args:  []string{"image", "--format=json", "--quiet", "--scanners=vuln,secret", "--timeout=" + wait.String(), ref},
+					}},
+				},
+			},
+		},
+	}
+	created, err := cs.BatchV1().Jobs(trivyNamespace).Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("create scan job: %w", err)
+	}
+	defer func() {
+		policy := metav1.DeletePropagationBackground
+		_ = cs.BatchV1().Jobs(trivyNamespace).Delete(context.Background(), created.Name, metav1.DeleteOptions{PropagationPolicy: &policy})
+	}()
+
+	pod, err := waitForJobPod(ctx, cs, created.Name, wait)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := cs.CoreV1().Pods(trivyNamespace).GetLogs(pod, &corev1.PodLogOptions{Container: "trivy"}).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read scan output: %w", err)
+	}
+	defer stream.Close()
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, fmt.Errorf("read scan output: %w", err)
+	}
+	return parseTrivyJSON(data, ref)
+}
+
+// waitForJobPod waits for the job to finish and returns its pod name.
+func waitForJobPod(ctx context.Context, cs kubernetes.Interface, job string, wait time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+	for {
+		j, err := cs.BatchV1().Jobs(trivyNamespace).Get(ctx, job, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("scan job %s: %w", job, err)
+		}
+		if j.Status.Succeeded > 0 || j.Status.Failed > 0 {
+			pods, err := cs.CoreV1().Pods(trivyNamespace).List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + job})
+			if err != nil || len(pods.Items) == 0 {
+				return "", fmt.Errorf("scan job %s has no pod: %v", job, err)
+			}
+			if j.Status.Failed > 0 {
+				return "", fmt.Errorf("image scan failed; see `kubectl logs -n %s %s`", trivyNamespace, pods.Items[0].Name)
+			}
+			return pods.Items[0].Name, nil
+		}
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("image scan did not finish within %s", wait)
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// trivyOutput is the subset of `trivy image --format=json` the CLI reads.
+type trivyOutput struct {
+	Results []struct {
+		Target          string `json:"Target"`
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+			Title            string `json:"Title"`
+			PrimaryURL       string `json:"PrimaryURL"`
+		} `json:"Vulnerabilities"`
+		Secrets []struct {
+			RuleID   string `json:"RuleID"`
+			Severity string `json:"Severity"`
+			Title    string `json:"Title"`
+		} `json:"Secrets"`
+	} `json:"Results"`
+}
+
+// parseTrivyJSON converts trivy's JSON report for image into findings.
+func parseTrivyJSON(data []byte, image string) ([]finding, error) {
+	// Skip anything trivy printed before the document.
+	if i := strings.IndexByte(string(data), '{'); i > 0 {
+		data = data[i:]
+	}
+	var out trivyOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("parse trivy output: %w", err)
+	}
+	var fs []finding
+	for _, r := range out.Results {
+		for _, v := range r.Vulnerabilities {
+			fs = append(fs, finding{
+				Kind: kindVulnerability, ID: v.VulnerabilityID, Severity: strings.ToUpper(v.Severity), Title: v.Title,
+				Package: v.PkgName, Installed: v.InstalledVersion, Fixed: v.FixedVersion, Image: image,
+				Namespace: "-", Workload: "image", Link: v.PrimaryURL,
+			})
+		}
+		for _, s := range r.Secrets {
+			fs = append(fs, finding{
+				Kind: kindSecret, ID: s.RuleID, Severity: strings.ToUpper(s.Severity), Title: s.Title,
+				Package: r.Target, Image: image, Namespace: "-", Workload: "image",
+			})
+		}
+	}
+	sortFindings(fs)
+	return fs, nil
+}
+
+func int32Ptr(v int32) *int32 { return &v }
@@ -1,104 +1,535 @@
 package security
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/k8s"
 	"adhar-io/adhar/platform/logger"
 
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
 )
 
 var incidentsCmd = &cobra.Command{
 	Use:   "incidents",
 	Short: "Handle security incidents",
-	Long: `Manage and respond to security incidents.
-	
+	Long: `Track security incidents and respond to them.
+
+Incidents are stored as labelled ConfigMaps in adhar-system, each with its
+status (open, investigating, contained, resolved) and an audit timeline of
+everything that happened to it. ` + "`sync`" + ` opens incidents from Falco and
+Tetragon alerts at or above a severity; ` + "`respond`" + ` runs playbooks
+against the pod, node or user an incident names.
+
 Examples:
   adhar security incidents list
-  adhar security incidents create --type=breach
-  adhar security incidents respond --id=incident-123`,
+  adhar security incidents sync --since=1h --min-severity=high
+  adhar security incidents sync --follow
+  adhar security incidents create --type=breach --title="Leaked token" --user=alice
+  adhar security incidents show inc-20261018-3fa2c1
+  adhar security incidents respond inc-20261018-3fa2c1 --playbook=snapshot-logs,isolate-pod
+  adhar security incidents update inc-20261018-3fa2c1 --status=resolved --note="Image rebuilt"`,
 	RunE: runIncidents,
 }
 
 var (
 	incidentType string
 	incidentID   string
-	respond      bool
 )
 
 func init() {
-	incidentsCmd.Flags().StringVarP(&incidentType, "type", "t", "", "Type of incident (breach, vulnerability, policy-violation)")
-	incidentsCmd.Flags().StringVarP(&incidentID, "id", "i", "", "Incident ID")
-	incidentsCmd.Flags().BoolVar(&respond, "respond", false, "Respond to incident")
+	incidentsCmd.Flags().StringVarP(&incidentType, "type", "t", "", "Create an incident of this type (breach, vulnerability, policy-violation)")
+	incidentsCmd.Flags().StringVarP(&incidentID, "id", "i", "", "Show this incident")
+
+	incidentsCmd.AddCommand(incidentListCmd)
+	incidentsCmd.AddCommand(incidentShowCmd)
+	incidentsCmd.AddCommand(incidentCreateCmd)
+	incidentsCmd.AddCommand(incidentUpdateCmd)
+	incidentsCmd.AddCommand(incidentRespondCmd)
+	incidentsCmd.AddCommand(incidentSyncCmd)
 }
 
 func runIncidents(cmd *cobra.Command, args []string) error {
-	logger.Info("🚨 Managing security incidents...")
-
 	if incidentID != "" {
-		if respond {
-			return respondToIncident(incidentID)
-		}
-		return showIncident(incidentID)
+		return runIncidentShow(cmd, []string{incidentID})
 	}
-
 	if incidentType != "" {
-		return createIncident(incidentType)
+		incCreateType = incidentType
+		return runIncidentCreate(cmd, args)
+	}
+	return runIncidentList(cmd, args)
+}
+
+func incidentClient() (kubernetes.Interface, error) {
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the cluster (is it running? try `adhar up`): %w", err)
+	}
+	return cs, nil
+}
+
+func commandContext(cmd *cobra.Command) context.Context {
+	if ctx := cmd.Context(); ctx != nil {
+		return ctx
 	}
+	return context.Background()
+}
 
-	return listIncidents()
+// --- list ---
+
+var incidentListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List incidents",
+	RunE:  runIncidentList,
 }
 
-func createIncident(incidentType string) error {
-	logger.Info("🚨 Creating security incident: " + incidentType)
+var (
+	incListStatus   string
+	incListSeverity string
+	incOutput       string
+)
+
+func init() {
+	incidentListCmd.Flags().StringVar(&incListStatus, "status", "", "Only incidents in this status (open, investigating, contained, resolved)")
+	incidentListCmd.Flags().StringVarP(&incListSeverity, "severity", "s", "", "Minimum severity (critical, high, medium, low)")
+	incidentListCmd.Flags().StringVarP(&incOutput, "output", "o", "table", "Output format (table, json, yaml)")
+}
+
+func runIncidentList(cmd *cobra.Command, args []string) error {
+	minSeverity, err := parseSeverity(incListSeverity)
+	if err != nil {
+		return err
+	}
+	if incListStatus != "" && !containsString(incidentStatuses, incListStatus) {
+		return fmt.Errorf("invalid --status %q (open, investigating, contained, resolved)", incListStatus)
+	}
+	cs, err := incidentClient()
+	if err != nil {
+		return err
+	}
+	incidents, err := listIncidentRecords(commandContext(cmd), cs, incListStatus, minSeverity)
+	if err != nil {
+		return err
+	}
+	switch incOutput {
+	case "json":
+		return helpers.PrintJSON(incidents)
+	case "yaml":
+		return helpers.PrintYAML(incidents)
+	case "", "table":
+	default:
+		return fmt.Errorf("unsupported output format %q (table, json, yaml)", incOutput)
+	}
+	if len(incidents) == 0 {
+		fmt.Println(helpers.CreateSuccess("No incidents"))
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSEVERITY\tSTATUS\tSOURCE\tTITLE\tTARGET\tSEEN\tAGE")
+	for _, in := range incidents {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n", in.ID, in.Severity, in.Status, in.Source,
+			truncate(in.Title, 40), incidentTarget(in), in.Occurrences, age(in.Created))
+	}
+	return tw.Flush()
+}
+
+func incidentTarget(in *incident) string {
+	switch {
+	case in.Pod != "":
+		return in.Namespace + "/" + in.Pod
+	case in.Node != "":
+		return "node/" + in.Node
+	case in.User != "":
+		return "user/" + in.User
+	}
+	return "-"
+}
+
+func age(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// --- show ---
+
+var incidentShowCmd = &cobra.Command{
+	Use:   "show <id>",
+	Short: "Show an incident and its timeline",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runIncidentShow,
+}
+
+func init() {
+	incidentShowCmd.Flags().StringVarP(&incOutput, "output", "o", "table", "Output format (table, json, yaml)")
+}
+
+func runIncidentShow(cmd *cobra.Command, args []string) error {
+	cs, err := incidentClient()
+	if err != nil {
+		return err
+	}
+	in, err := getIncident(commandContext(cmd), cs, args[0])
+	if err != nil {
+		return err
+	}
+	switch incOutput {
+	case "json":
+		return helpers.PrintJSON(in)
+	case "yaml":
+		return helpers.PrintYAML(in)
+	}
+	printIncident(in)
+	return nil
+}
+
+func printIncident(in *incident) {
+	fmt.Printf("%s  %s  [%s]\n", in.ID, in.Title, strings.ToUpper(in.Status))
+	fmt.Printf("Severity:  %s\n", in.Severity)
+	fmt.Printf("Type:      %s (source: %s)\n", in.Type, in.Source)
+	if in.Rule != "" {
+		fmt.Printf("Rule:      %s\n", in.Rule)
+	}
+	if in.Pod != "" {
+		fmt.Printf("Pod:       %s/%s\n", in.Namespace, in.Pod)
+	}
+	if in.Node != "" {
+		fmt.Printf("Node:      %s\n", in.Node)
+	}
+	if in.User != "" {
+		fmt.Printf("User:      %s\n", in.User)
+	}
+	fmt.Printf("Seen:      %d time(s), last %s\n", in.Occurrences, in.LastSeen.Local().Format(time.RFC3339))
+	fmt.Println("\nTimeline:")
+	for _, e := range in.Timeline {
+		line := fmt.Sprintf("  %s  %-10s %-14s", e.Time.Local().Format("2006-01-02 15:04:05"), e.Actor, e.Action)
+		if e.Detail != "" {
+			line += " " + e.Detail
+		}
+		fmt.Println(line)
+	}
+}
+
+// --- create ---
+
+var incidentCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Open an incident by hand",
+	RunE:  runIncidentCreate,
+}
+
+var (
+	incCreateType     string
+	incCreateTitle    string
+	incCreateSeverity string
+	incNamespace      string
+	incPod            string
+	incNode           string
+	incUser           string
+	incNote           string
+)
 
-	// TODO: Implement incident creation
-	// This should:
-	// - Create incident record
-	// - Assign severity level
-	// - Notify security team
-	// - Start incident response process
+func init() {
+	f := incidentCreateCmd.Flags()
+	f.StringVarP(&incCreateType, "type", "t", "breach", "Incident type (breach, vulnerability, policy-violation, runtime)")
+	f.StringVar(&incCreateTitle, "title", "", "Short description")
+	f.StringVarP(&incCreateSeverity, "severity", "s", "high", "Severity (critical, high, medium, low)")
+	f.StringVarP(&incNamespace, "namespace", "n", "", "Namespace of the affected pod")
+	f.StringVar(&incPod, "pod", "", "Affected pod")
+	f.StringVar(&incNode, "node", "", "Affected node")
+	f.StringVar(&incUser, "user", "", "Keycloak user involved")
+	f.StringVar(&incNote, "note", "", "Initial note for the timeline")
+}
 
-	logger.Info("✅ Security incident created")
+func runIncidentCreate(cmd *cobra.Command, args []string) error {
+	sev, err := parseSeverity(incCreateSeverity)
+	if err != nil {
+		return err
+	}
+	if sev == "" {
+		sev = "HIGH"
+	}
+	if incPod != "" && incNamespace == "" {
+		return fmt.Errorf("--pod requires --namespace")
+	}
+	cs, err := incidentClient()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	in := &incident{
+		ID:          newIncidentID(now),
+		Title:       valueOr(incCreateTitle, incCreateType+" incident"),
+		Type:        incCreateType,
+		Severity:    sev,
+		Status:      "open",
+		Source:      "manual",
+		Namespace:   incNamespace,
+		Pod:         incPod,
+		Node:        incNode,
+		User:        incUser,
+		Occurrences: 1,
+		Created:     now,
+		LastSeen:    now,
+	}
+	in.record(currentActor(), "opened", incNote)
+	if err := createIncidentRecord(commandContext(cmd), cs, in); err != nil {
+		return err
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("Incident %s opened (%s)", in.ID, in.Severity)))
 	return nil
 }
 
-func respondToIncident(incidentID string) error {
-	logger.Info("🚨 Responding to incident: " + incidentID)
+// --- update ---
 
-	// TODO: Implement incident response
-	// This should:
-	// - Load incident details
-	// - Execute response playbook
-	// - Update incident status
-	// - Document response actions
+var incidentUpdateCmd = &cobra.Command{
+	Use:   "update <id>",
+	Short: "Change an incident's status or severity, or add a note",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runIncidentUpdate,
+}
+
+var (
+	incUpdateStatus   string
+	incUpdateSeverity string
+)
 
-	logger.Info("✅ Incident response completed")
+func init() {
+	f := incidentUpdateCmd.Flags()
+	f.StringVar(&incUpdateStatus, "status", "", "New status (open, investigating, contained, resolved)")
+	f.StringVarP(&incUpdateSeverity, "severity", "s", "", "New severity (critical, high, medium, low)")
+	f.StringVar(&incNote, "note", "", "Note for the timeline")
+}
+
+func runIncidentUpdate(cmd *cobra.Command, args []string) error {
+	if incUpdateStatus == "" && incUpdateSeverity == "" && incNote == "" {
+		return fmt.Errorf("nothing to update; pass --status, --severity or --note")
+	}
+	sev, err := parseSeverity(incUpdateSeverity)
+	if err != nil {
+		return err
+	}
+	cs, err := incidentClient()
+	if err != nil {
+		return err
+	}
+	actor := currentActor()
+	in, err := updateIncident(commandContext(cmd), cs, args[0], func(in *incident) error {
+		if sev != "" && sev != in.Severity {
+			in.record(actor, "severity", in.Severity+" → "+sev)
+			in.Severity = sev
+		}
+		if incUpdateStatus != "" {
+			return in.setStatus(actor, incUpdateStatus, incNote)
+		}
+		if incNote != "" {
+			in.record(actor, "note", incNote)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("Incident %s is %s (%s)", in.ID, in.Status, in.Severity)))
 	return nil
 }
 
-func showIncident(incidentID string) error {
-	logger.Info("📋 Showing incident: " + incidentID)
+// --- respond ---
+
+var incidentRespondCmd = &cobra.Command{
+	Use:   "respond <id>",
+	Short: "Run response playbooks for an incident",
+	Long: `Run one or more playbooks against the pod, node or user an incident names,
+recording each action in the incident timeline:
+
+  isolate-pod      deny all traffic to and from the pod with a CiliumNetworkPolicy
+  cordon-node      mark the pod's node unschedulable
+  snapshot-logs    save the pod manifest and container logs under --dest
+  revoke-sessions  log the user (--user or the incident's) out of Keycloak
 
-	// TODO: Implement incident display
-	// This should show:
-	// - Incident details
-	// - Timeline of events
-	// - Response actions
-	// - Current status
+Playbooks run in the order given; containment playbooks move an open
+incident to contained.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runIncidentRespond,
+}
+
+var (
+	incPlaybooks   []string
+	incDryRun      bool
+	incDest        string
+	incKeycloakURL string
+)
+
+func init() {
+	f := incidentRespondCmd.Flags()
+	f.StringSliceVarP(&incPlaybooks, "playbook", "p", nil, "Playbooks to run ("+playbookNames()+")")
+	f.BoolVar(&incDryRun, "dry-run", false, "Show what would be done without doing it")
+	f.StringVar(&incDest, "dest", ".", "Directory for snapshot-logs output")
+	f.StringVar(&incUser, "user", "", "Keycloak user for revoke-sessions (defaults to the incident's user)")
+	f.StringVar(&incKeycloakURL, "keycloak-url", defaultKeycloakURL, "Keycloak base URL for revoke-sessions")
+	_ = incidentRespondCmd.MarkFlagRequired("playbook")
+}
 
-	logger.Info("✅ Incident details displayed")
+func runIncidentRespond(cmd *cobra.Command, args []string) error {
+	var selected []playbook
+	for _, name := range incPlaybooks {
+		p, err := findPlaybook(name)
+		if err != nil {
+			return err
+		}
+		selected = append(selected, p)
+	}
+	cs, err := incidentClient()
+	if err != nil {
+		return err
+	}
+	ctx := commandContext(cmd)
+	in, err := getIncident(ctx, cs, args[0])
+	if err != nil {
+		return err
+	}
+	if in.Status == "resolved" {
+		return fmt.Errorf("incident %s is resolved; reopen it with `update --status=open` first", in.ID)
+	}
+	if incDryRun {
+		fmt.Printf("Would run on incident %s (%s):\n", in.ID, incidentTarget(in))
+		for _, p := range selected {
+			fmt.Printf("  • %-16s %s\n", p.Name, p.Description)
+		}
+		return nil
+	}
+
+	dyn, err := k8s.GetDynamicClient()
+	if err != nil {
+		return fmt.Errorf("create dynamic client: %w", err)
+	}
+	env := newPlaybookEnv(cs, dyn, incDest, incUser, incKeycloakURL)
+	actor := currentActor()
+	var failed []string
+	for _, p := range selected {
+		logger.Info(fmt.Sprintf("▶️  Running %s on %s", p.Name, in.ID))
+		detail, runErr := p.Run(ctx, env, in)
+		// Record the outcome even when the playbook failed: the attempt is
+		// part of the audit trail.
+		_, err := updateIncident(ctx, cs, in.ID, func(stored *incident) error {
+			if runErr != nil {
+				stored.record(actor, p.Name+" failed", runErr.Error())
+				return nil
+			}
+			stored.Node, stored.User = valueOr(stored.Node, in.Node), valueOr(stored.User, in.User)
+			stored.record(actor, p.Name, detail)
+			if p.Contains && (stored.Status == "open" || stored.Status == "investigating") {
+				return stored.setStatus(actor, "contained", p.Name)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if runErr != nil {
+			fmt.Println(helpers.ErrorStyle.Render("✗ " + p.Name + ": " + runErr.Error()))
+			failed = append(failed, p.Name)
+			continue
+		}
+		fmt.Println(helpers.CreateSuccess(p.Name + ": " + detail))
+	}
+	if len(failed) > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("playbook(s) failed: %s", strings.Join(failed, ", "))
+	}
 	return nil
 }
 
-func listIncidents() error {
-	logger.Info("📋 Listing security incidents...")
+// --- sync ---
+
+var incidentSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Open incidents from Falco and Tetragon alerts",
+	Long: `Read Falco and Tetragon events from their pods in adhar-system and open an
+incident for each alert at or above --min-severity. Repeats of an alert (same
+source, rule and pod) are counted on the unresolved incident instead of
+opening new ones, and alerts already recorded by an earlier sync are skipped,
+so sync can run on a schedule with overlapping windows. --follow keeps
+watching until interrupted.
+
+Falco priorities map to critical (emergency, alert, critical), high (error),
+medium (warning) and low (notice and below). Tetragon TracingPolicy matches
+are high, or critical when the policy enforced (SIGKILL or override).`,
+	RunE: runIncidentSync,
+}
+
+var (
+	incSince       time.Duration
+	incMinSeverity string
+	incFollow      bool
+	incSources     []string
+)
 
-	// TODO: Implement incident listing
-	// This should show:
-	// - All incidents
-	// - Severity levels
-	// - Status
-	// - Response progress
+func init() {
+	f := incidentSyncCmd.Flags()
+	f.DurationVar(&incSince, "since", time.Hour, "How far back to read events")
+	f.StringVar(&incMinSeverity, "min-severity", "high", "Minimum alert severity that opens an incident")
+	f.BoolVar(&incFollow, "follow", false, "Keep watching for new alerts")
+	f.StringSliceVar(&incSources, "source", []string{"falco", "tetragon"}, "Alert sources to read")
+}
 
-	logger.Info("✅ Incidents listed")
+func runIncidentSync(cmd *cobra.Command, args []string) error {
+	minSeverity, err := parseSeverity(incMinSeverity)
+	if err != nil {
+		return fmt.Errorf("--min-severity: %w", err)
+	}
+	var sources []alertSource
+	for _, name := range incSources {
+		found := false
+		for _, s := range alertSources {
+			if s.Name == name {
+				sources, found = append(sources, s), true
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown alert source %q (falco, tetragon)", name)
+		}
+	}
+	cs, err := incidentClient()
+	if err != nil {
+		return err
+	}
+	ctx := commandContext(cmd)
+	tracker, err := newAlertTracker(ctx, cs)
+	if err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("📡 Reading %s alerts from the last %s...", strings.Join(incSources, " and "), incSince))
+
+	opened, updated := 0, 0
+	err = readAlerts(ctx, cs, sources, incSince, incFollow, minSeverity, func(a *alert) {
+		action, in, err := tracker.observe(ctx, a)
+		switch {
+		case err != nil:
+			fmt.Fprintln(os.Stderr, helpers.CreateWarning(err.Error()))
+		case action == "opened":
+			opened++
+			fmt.Println(helpers.CreateSuccess(fmt.Sprintf("Opened %s  %s  %s  %s", in.ID, in.Severity, in.Rule, incidentTarget(in))))
+		case action == "updated":
+			updated++
+		}
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n%d incident(s) opened, %d updated with repeat alerts\n", opened, updated)
 	return nil
 }
@@ -0,0 +1,189 @@
+package security
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseAlerts(t *testing.T) {
+	falco := `{"output":"Shell spawned in container","priority":"Critical","rule":"Terminal shell in container","time":"2026-10-18T10:00:00Z","hostname":"worker-1","output_fields":{"k8s.ns.name":"prod","k8s.pod.name":"web-abc","user.name":"root"}}`
+	a, ok := parseFalcoEvent([]byte(falco))
+	if !ok || a.Severity != "CRITICAL" || a.Pod != "web-abc" || a.Node != "worker-1" {
+		t.Fatalf("falco alert = %+v", a)
+	}
+	tetragon := `{"process_kprobe":{"process":{"binary":"/bin/cat","arguments":"/etc/shadow","pod":{"namespace":"prod","name":"web-abc"}},"function_name":"security_file_permission","policy_name":"sensitive-files","action":"KPROBE_ACTION_SIGKILL"},"node_name":"worker-1","time":"2026-10-18T10:00:00Z"}`
+	if a, ok := parseTetragonEvent([]byte(tetragon)); !ok || a.Severity != "CRITICAL" || a.Rule != "sensitive-files" {
+		t.Errorf("tetragon alert = %+v", a)
+	}
+	if _, ok := parseTetragonEvent([]byte(`{"process_exec":{"process":{"binary":"/bin/sh"}}}`)); ok {
+		t.Errorf("process_exec events should not raise alerts")
+	}
+
+	var got []*alert
+	stream := "not json\n" + falco + "\n" + strings.Replace(falco, "Critical", "Notice", 1) + "\n"
+	scanAlerts(strings.NewReader(stream), parseFalcoEvent, "HIGH", func(a *alert) { got = append(got, a) })
+	if len(got) != 1 {
+		t.Errorf("scanAlerts kept %d alerts, want 1", len(got))
+	}
+}
+
+func TestAlertTracker(t *testing.T) {
+	ctx := context.Background()
+	cs := fake.NewSimpleClientset()
+	tracker, err := newAlertTracker(ctx, cs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC)
+	a := &alert{Source: "falco", Rule: "shell", Severity: "HIGH", Namespace: "prod", Pod: "web", Time: t0}
+
+	action, first, err := tracker.observe(ctx, a)
+	if err != nil || action != "opened" {
+		t.Fatalf("first alert: %s %v", action, err)
+	}
+	repeat := *a
+	repeat.Time, repeat.Severity = t0.Add(time.Minute), "CRITICAL"
+	if action, _, _ := tracker.observe(ctx, &repeat); action != "updated" {
+		t.Errorf("repeat alert action = %q", action)
+	}
+	if action, _, _ := tracker.observe(ctx, a); action != "" {
+		t.Errorf("already recorded alert action = %q", action)
+	}
+
+	// A new sync sees the stored state.
+	tracker, _ = newAlertTracker(ctx, cs)
+	if action, _, _ := tracker.observe(ctx, &repeat); action != "" {
+		t.Errorf("alert recorded by an earlier sync reopened: %q", action)
+	}
+	stored, err := getIncident(ctx, cs, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Occurrences != 2 || stored.Severity != "CRITICAL" || len(stored.Timeline) != 2 {
+		t.Errorf("stored incident = %+v", stored)
+	}
+
+	// Once resolved, the next alert opens a new incident.
+	if _, err := updateIncident(ctx, cs, first.ID, func(in *incident) error { return in.setStatus("test", "resolved", "") }); err != nil {
+		t.Fatal(err)
+	}
+	tracker, _ = newAlertTracker(ctx, cs)
+	later := *a
+	later.Time = t0.Add(time.Hour)
+	if action, in, _ := tracker.observe(ctx, &later); action != "opened" || in.ID == first.ID {
+		t.Errorf("alert after resolution: %q", action)
+	}
+	if open, _ := listIncidentRecords(ctx, cs, "open", ""); len(open) != 1 {
+		t.Errorf("open incidents = %d", len(open))
+	}
+}
+
+func TestPlaybooks(t *testing.T) {
+	ctx := context.Background()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "prod", Labels: map[string]string{"app": "web"}},
+		Spec:       corev1.PodSpec{NodeName: "worker-1", Containers: []corev1.Container{{Name: "app"}}},
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker-1"}}
+	cs := fake.NewSimpleClientset(pod, node)
+	in := &incident{ID: "inc-1", Namespace: "prod", Pod: "web"}
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{ciliumNetworkPolicyGVR: "CiliumNetworkPolicyList"})
+	env := newPlaybookEnv(cs, dyn, t.TempDir(), "", "")
+
+	if _, err := isolatePod(ctx, env, in); err != nil {
+		t.Fatal(err)
+	}
+	cnp, err := dyn.Resource(ciliumNetworkPolicyGVR).Namespace("prod").Get(ctx, "quarantine-inc-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sel, _, _ := unstructured.NestedString(cnp.Object, "spec", "endpointSelector", "matchLabels", quarantineLabel); sel != "inc-1" {
+		t.Errorf("policy selects %q, want the quarantine label", sel)
+	}
+	ingress, _, _ := unstructured.NestedSlice(cnp.Object, "spec", "ingressDeny")
+	egress, _, _ := unstructured.NestedSlice(cnp.Object, "spec", "egressDeny")
+	if len(ingress) != 1 || len(egress) != 1 {
+		t.Errorf("policy must deny all ingress and egress: %v", cnp.Object["spec"])
+	}
+	if p, _ := cs.CoreV1().Pods("prod").Get(ctx, "web", metav1.GetOptions{}); p.Labels[quarantineLabel] != "inc-1" || p.Labels["app"] != "web" {
+		t.Errorf("pod labels = %v", p.Labels)
+	}
+
+	if _, err := cordonNode(ctx, env, in); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := cs.CoreV1().Nodes().Get(ctx, "worker-1", metav1.GetOptions{}); !n.Spec.Unschedulable || in.Node != "worker-1" {
+		t.Errorf("node not cordoned")
+	}
+
+	if _, err := snapshotLogs(ctx, env, in); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"pod.json", "app.log"} {
+		if _, err := os.Stat(filepath.Join(env.dest, "inc-1", "web", f)); err != nil {
+			t.Errorf("snapshot missing %s", f)
+		}
+	}
+
+	if _, err := revokeSessions(ctx, env, in); err == nil || !strings.Contains(err.Error(), "--user") {
+		t.Errorf("revoke-sessions without a user: %v", err)
+	}
+}
+
+func TestRevokeSessions(t *testing.T) {
+	loggedOut := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/realms/master/protocol/openid-connect/token":
+			if r.FormValue("username") != "adhar-admin" || r.FormValue("password") != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"access_token":"tok"}`))
+		case r.Header.Get("Authorization") != "Bearer tok":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/admin/realms/adhar/users" && r.URL.Query().Get("username") == "alice":
+			_, _ = w.Write([]byte(`[{"id":"u-1","username":"alice"}]`))
+		case r.URL.Path == "/admin/realms/adhar/users/u-1/logout" && r.Method == http.MethodPost:
+			loggedOut = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: keycloakSecret, Namespace: incidentNamespace},
+		Data:       map[string][]byte{"KEYCLOAK_ADMIN_PASSWORD": []byte("s3cret")},
+	}
+	env := newPlaybookEnv(fake.NewSimpleClientset(secret), nil, "", "alice", srv.URL)
+	env.http = srv.Client()
+	in := &incident{ID: "inc-1"}
+	if _, err := revokeSessions(context.Background(), env, in); err != nil {
+		t.Fatal(err)
+	}
+	if !loggedOut || in.User != "alice" {
+		t.Errorf("user was not logged out")
+	}
+
+	env.user = ""
+	if _, err := revokeSessions(context.Background(), env, &incident{ID: "inc-2"}); err == nil {
+		t.Errorf("revoke-sessions without a user must fail")
+	}
+}
@@ -0,0 +1,215 @@
+package security
+
+// incidentstore.go keeps incidents as labelled ConfigMaps in adhar-system: the
+// labels carry status, severity and source for filtering, and the incident
+// itself (including its timeline) is stored as JSON under one key.
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	incidentNamespace   = "adhar-system"
+	incidentLabel       = "adhar.io/incident"
+	incidentStatusLabel = "adhar.io/incident-status"
+	incidentSevLabel    = "adhar.io/incident-severity"
+	incidentSourceLabel = "adhar.io/incident-source"
+	incidentDataKey     = "incident.json"
+)
+
+// Incident statuses, in lifecycle order.
+var incidentStatuses = []string{"open", "investigating", "contained", "resolved"}
+
+// incident is one security incident and its audit timeline.
+type incident struct {
+	ID          string          `json:"id"`
+	Title       string          `json:"title"`
+	Type        string          `json:"type"`
+	Severity    string          `json:"severity"`
+	Status      string          `json:"status"`
+	Source      string          `json:"source"`
+	Rule        string          `json:"rule,omitempty"`
+	Namespace   string          `json:"namespace,omitempty"`
+	Pod         string          `json:"pod,omitempty"`
+	Node        string          `json:"node,omitempty"`
+	User        string          `json:"user,omitempty"`
+	Fingerprint string          `json:"fingerprint,omitempty"`
+	Occurrences int             `json:"occurrences"`
+	Created     time.Time       `json:"created"`
+	LastSeen    time.Time       `json:"lastSeen"`
+	Timeline    []timelineEntry `json:"timeline"`
+}
+
+// timelineEntry is one audited event in an incident.
+type timelineEntry struct {
+	Time   time.Time `json:"time"`
+	Actor  string    `json:"actor"`
+	Action string    `json:"action"`
+	Detail string    `json:"detail,omitempty"`
+}
+
+// record appends a timeline entry.
+func (in *incident) record(actor, action, detail string) {
+	in.Timeline = append(in.Timeline, timelineEntry{Time: time.Now().UTC(), Actor: actor, Action: action, Detail: detail})
+}
+
+// setStatus moves the incident to status and records the change.
+func (in *incident) setStatus(actor, status, reason string) error {
+	if !containsString(incidentStatuses, status) {
+		return fmt.Errorf("invalid status %q (open, investigating, contained, resolved)", status)
+	}
+	if in.Status == status {
+		return nil
+	}
+	detail := in.Status + " → " + status
+	if reason != "" {
+		detail += ": " + reason
+	}
+	in.Status = status
+	in.record(actor, "status", detail)
+	return nil
+}
+
+// newIncidentID returns a name like inc-20261018-3fa2c1.
+func newIncidentID(now time.Time) string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return "inc-" + now.UTC().Format("20060102") + "-" + hex.EncodeToString(b)
+}
+
+// currentActor names the person running the CLI in timeline entries.
+func currentActor() string {
+	if u := os.Getenv("USER"); u != "" {
+		return u
+	}
+	return "adhar-cli"
+}
+
+func incidentToConfigMap(in *incident) (*corev1.ConfigMap, error) {
+	data, err := json.MarshalIndent(in, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      in.ID,
+			Namespace: incidentNamespace,
+			Labels: map[string]string{
+				incidentLabel:                  "true",
+				incidentStatusLabel:            in.Status,
+				incidentSevLabel:               in.Severity,
+				incidentSourceLabel:            in.Source,
+				"app.kubernetes.io/managed-by": "adhar",
+			},
+		},
+		Data: map[string]string{incidentDataKey: string(data)},
+	}, nil
+}
+
+func incidentFromConfigMap(cm *corev1.ConfigMap) (*incident, error) {
+	var in incident
+	if err := json.Unmarshal([]byte(cm.Data[incidentDataKey]), &in); err != nil {
+		return nil, fmt.Errorf("incident %s is corrupt: %w", cm.Name, err)
+	}
+	return &in, nil
+}
+
+// createIncidentRecord stores a new incident.
+func createIncidentRecord(ctx context.Context, cs kubernetes.Interface, in *incident) error {
+	cm, err := incidentToConfigMap(in)
+	if err != nil {
+		return err
+	}
+	if _, err := cs.CoreV1().ConfigMaps(incidentNamespace).Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("create incident %s: %w", in.ID, err)
+	}
+	return nil
+}
+
+// getIncident loads one incident by ID.
+func getIncident(ctx context.Context, cs kubernetes.Interface, id string) (*incident, error) {
+	cm, err := cs.CoreV1().ConfigMaps(incidentNamespace).Get(ctx, id, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, fmt.Errorf("incident %q not found", id)
+		}
+		return nil, fmt.Errorf("get incident %s: %w", id, err)
+	}
+	if cm.Labels[incidentLabel] != "true" {
+		return nil, fmt.Errorf("incident %q not found", id)
+	}
+	return incidentFromConfigMap(cm)
+}
+
+// updateIncident applies change to the stored incident, retrying on
+// conflicts so concurrent responders and `sync` never lose timeline entries.
+func updateIncident(ctx context.Context, cs kubernetes.Interface, id string, change func(*incident) error) (*incident, error) {
+	var out *incident
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm, err := cs.CoreV1().ConfigMaps(incidentNamespace).Get(ctx, id, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		in, err := incidentFromConfigMap(cm)
+		if err != nil {
+			return err
+		}
+		if err := change(in); err != nil {
+			return err
+		}
+		updated, err := incidentToConfigMap(in)
+		if err != nil {
+			return err
+		}
+		updated.ResourceVersion = cm.ResourceVersion
+		if _, err := cs.CoreV1().ConfigMaps(incidentNamespace).Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+		out = in
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("update incident %s: %w", id, err)
+	}
+	return out, nil
+}
+
+// listIncidentRecords returns incidents, newest first, optionally filtered by
+// status and minimum severity.
+func listIncidentRecords(ctx context.Context, cs kubernetes.Interface, status, minSeverity string) ([]*incident, error) {
+	selector := incidentLabel + "=true"
+	if status != "" {
+		selector += "," + incidentStatusLabel + "=" + status
+	}
+	list, err := cs.CoreV1().ConfigMaps(incidentNamespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("list incidents: %w", err)
+	}
+	var out []*incident
+	for i := range list.Items {
+		in, err := incidentFromConfigMap(&list.Items[i])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		if minSeverity != "" && severityRank(in.Severity) < severityRank(minSeverity) {
+			continue
+		}
+		out = append(out, in)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Created.After(out[j].Created) })
+	return out, nil
+}
@@ -0,0 +1,350 @@
+package security
+
+// kubescape.go runs framework scans (CIS, NSA, MITRE, SOC2) through the
+// kubescape operator's HTTP API, reached via the API server's service proxy,
+// and condenses the posture report into a complianceRun that can be stored
+// and compared. Reports saved by `kubescape scan framework --format json` are
+// read the same way.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"adhar-io/adhar/globals"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// kubescapeService is the operator's scan API (service:port).
+const kubescapeService = "kubescape:8080"
+
+// kubescapeFrameworks maps the names the CLI accepts to kubescape framework
+// names; anything else is passed through as is.
+var kubescapeFrameworks = map[string]string{
+	"cis":   "cis-v1.23-t1.0.1",
+	"nsa":   "NSA",
+	"mitre": "MITRE",
+	"soc2":  "SOC2",
+}
+
+// defaultFrameworks are scanned when neither arguments nor compliance
+// policies name any.
+var defaultFrameworks = []string{"cis", "nsa", "mitre"}
+
+// compliancePoliciesGVR is the claim of compliancepolicy.xrd.yaml; its
+// complianceStandards select frameworks kubescape can report on.
+var compliancePoliciesGVR = schema.GroupVersionResource{Group: "platform.adhar.io", Version: "v1alpha1", Resource: "compositecompliancepolicies"}
+
+// standardFrameworks maps complianceStandards to kubescape frameworks.
+var standardFrameworks = map[string]string{
+	"cis-kubernetes": "cis",
+	"soc2":           "soc2",
+}
+
+// complianceRun is the stored summary of one scan.
+type complianceRun struct {
+	ID         string           `json:"id"`
+	Time       time.Time        `json:"time"`
+	Source     string           `json:"source"`
+	Score      float64          `json:"score"`
+	Frameworks []frameworkScore `json:"frameworks"`
+	Controls   []controlResult  `json:"controls"`
+}
+
+// frameworkScore is the compliance score (0-100) of one framework.
+type frameworkScore struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+// controlResult is one control and the resources failing it.
+type controlResult struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Severity        string   `json:"severity"`
+	Status          string   `json:"status"` // passed, failed, skipped
+	Frameworks      []string `json:"frameworks,omitempty"`
+	Passed          int      `json:"passed"`
+	Failed          int      `json:"failed"`
+	FailedResources []string `json:"failedResources,omitempty"`
+}
+
+// maxStoredResources caps the failed resources kept per control so a run
+// fits in a ConfigMap; the failed count stays exact.
+const maxStoredResources = 50
+
+// failedControls returns the failed controls, most severe first.
+func (r *complianceRun) failedControls() []controlResult {
+	var out []controlResult
+	for _, c := range r.Controls {
+		if c.Status == "failed" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (r *complianceRun) frameworkNames() []string {
+	names := make([]string, 0, len(r.Frameworks))
+	for _, f := range r.Frameworks {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// kubescapeSeverity converts a control's score factor to a severity, the
+// way kubescape labels controls.
+func kubescapeSeverity(scoreFactor float64) string {
+	switch {
+	case scoreFactor >= 9:
+		return "CRITICAL"
+	case scoreFactor >= 7:
+		return "HIGH"
+	case scoreFactor >= 4:
+		return "MEDIUM"
+	case scoreFactor >= 1:
+		return "LOW"
+	}
+	return "UNKNOWN"
+}
+
+// postureReport is the part of kubescape's v2 JSON report used here.
+type postureReport struct {
+	SummaryDetails struct {
+		ComplianceScore float64 `json:"complianceScore"`
+		Frameworks      []struct {
+			Name            string                    `json:"name"`
+			ComplianceScore float64                   `json:"complianceScore"`
+			Controls        map[string]controlSummary `json:"controls"`
+		} `json:"frameworks"`
+		Controls map[string]controlSummary `json:"controls"`
+	} `json:"summaryDetails"`
+	Results []struct {
+		ResourceID string `json:"resourceID"`
+		Controls   []struct {
+			ControlID string `json:"controlID"`
+			Status    struct {
+				Status string `json:"status"`
+			} `json:"status"`
+		} `json:"controls"`
+	} `json:"results"`
+	Resources []struct {
+		ResourceID string                 `json:"resourceID"`
+		Object     map[string]interface{} `json:"object"`
+	} `json:"resources"`
+}
+
+type controlSummary struct {
+	ControlID   string  `json:"controlID"`
+	Name        string  `json:"name"`
+	Status      string  `json:"status"`
+	ScoreFactor float64 `json:"scoreFactor"`
+	StatusInfo  struct {
+		Status string `json:"status"`
+	} `json:"statusInfo"`
+	Counters struct {
+		Passed  int `json:"passedResources"`
+		Failed  int `json:"failedResources"`
+		Skipped int `json:"skippedResources"`
+	} `json:"ResourceCounters"`
+}
+
+// parsePostureReport condenses a kubescape report into a run.
+func parsePostureReport(data []byte) (*complianceRun, error) {
+	var report postureReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parse kubescape report: %w", err)
+	}
+	if len(report.SummaryDetails.Controls) == 0 && len(report.SummaryDetails.Frameworks) == 0 {
+		return nil, fmt.Errorf("kubescape report has no summary (was it written with --format json?)")
+	}
+	run := &complianceRun{Score: round1(report.SummaryDetails.ComplianceScore)}
+
+	controlFrameworks := map[string][]string{}
+	for _, f := range report.SummaryDetails.Frameworks {
+		run.Frameworks = append(run.Frameworks, frameworkScore{Name: f.Name, Score: round1(f.ComplianceScore)})
+		for id := range f.Controls {
+			controlFrameworks[id] = append(controlFrameworks[id], f.Name)
+		}
+	}
+
+	names := map[string]string{}
+	for _, r := range report.Resources {
+		names[r.ResourceID] = resourceLabel(r.ResourceID, r.Object)
+	}
+	failing := map[string][]string{}
+	for _, r := range report.Results {
+		label := names[r.ResourceID]
+		if label == "" {
+			label = resourceLabel(r.ResourceID, nil)
+		}
+		for _, c := range r.Controls {
+			if c.Status.Status == "failed" {
+				failing[c.ControlID] = append(failing[c.ControlID], label)
+			}
+		}
+	}
+
+	for id, c := range report.SummaryDetails.Controls {
+		status := c.StatusInfo.Status
+		if status == "" {
+			status = c.Status
+		}
+		if status != "failed" && status != "passed" {
+			status = "skipped"
+		}
+		resources := failing[id]
+		sort.Strings(resources)
+		if len(resources) > maxStoredResources {
+			resources = resources[:maxStoredResources]
+		}
+		failed := c.Counters.Failed
+		if failed == 0 {
+			failed = len(failing[id])
+		}
+		frameworks := controlFrameworks[id]
+		sort.Strings(frameworks)
+		run.Controls = append(run.Controls, controlResult{
+			ID: valueOr(c.ControlID, id), Name: c.Name, Severity: kubescapeSeverity(c.ScoreFactor), Status: status,
+			Frameworks: frameworks, Passed: c.Counters.Passed, Failed: failed, FailedResources: resources,
+		})
+	}
+	sortControls(run.Controls)
+	return run, nil
+}
+
+// sortControls orders failed controls first, then by severity and ID.
+func sortControls(cs []controlResult) {
+	sort.Slice(cs, func(i, j int) bool {
+		a, b := cs[i], cs[j]
+		if (a.Status == "failed") != (b.Status == "failed") {
+			return a.Status == "failed"
+		}
+		if severityRank(a.Severity) != severityRank(b.Severity) {
+			return severityRank(a.Severity) > severityRank(b.Severity)
+		}
+		return a.ID < b.ID
+	})
+}
+
+// resourceLabel names a resource namespace/Kind/name. kubescape resource IDs
+// look like group/version/namespace/Kind/name when the object is missing.
+func resourceLabel(id string, obj map[string]interface{}) string {
+	if obj != nil {
+		kind, name, ns := str(obj, "kind"), str(obj, "metadata", "name"), str(obj, "metadata", "namespace")
+		if kind != "" && name != "" {
+			if ns == "" {
+				return kind + "/" + name
+			}
+			return ns + "/" + kind + "/" + name
+		}
+	}
+	parts := strings.Split(id, "/")
+	if len(parts) >= 3 {
+		ns, kind, name := parts[len(parts)-3], parts[len(parts)-2], parts[len(parts)-1]
+		if ns == "" {
+			return kind + "/" + name
+		}
+		return ns + "/" + kind + "/" + name
+	}
+	return id
+}
+
+func round1(f float64) float64 {
+	return float64(int(f*10+0.5)) / 10
+}
+
+// scanRequest is the body of the operator's POST /v1/scan.
+type scanRequest struct {
+	TargetType  string   `json:"targetType"`
+	TargetNames []string `json:"targetNames"`
+	Submit      bool     `json:"submit"`
+}
+
+// runKubescapeScan asks the in-cluster kubescape operator to scan the
+// frameworks and waits for the report.
+func runKubescapeScan(ctx context.Context, cs kubernetes.Interface, frameworks []string) (*complianceRun, error) {
+	targets := make([]string, 0, len(frameworks))
+	for _, f := range frameworks {
+		targets = append(targets, valueOr(kubescapeFrameworks[strings.ToLower(f)], f))
+	}
+	body, err := json.Marshal(scanRequest{TargetType: "framework", TargetNames: targets})
+	if err != nil {
+		return nil, err
+	}
+	raw, err := cs.CoreV1().RESTClient().Post().
+		AbsPath("/api/v1/namespaces/"+globals.AdharSystemNamespace+"/services/"+kubescapeService+"/proxy/v1/scan").
+		Param("wait", "true").
+		Param("keep", "false").
+		SetHeader("Content-Type", "application/json").
+		Body(body).
+		Do(ctx).Raw()
+	if err != nil {
+		return nil, fmt.Errorf("kubescape scan failed (is the kubescape package enabled?): %w", err)
+	}
+	var resp struct {
+		ID       string          `json:"id"`
+		Type     string          `json:"type"`
+		Response json.RawMessage `json:"response"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("parse kubescape response: %w", err)
+	}
+	if resp.Type == "error" {
+		var msg string
+		_ = json.Unmarshal(resp.Response, &msg)
+		return nil, fmt.Errorf("kubescape scan %s failed: %s", resp.ID, valueOr(msg, string(resp.Response)))
+	}
+	run, err := parsePostureReport(resp.Response)
+	if err != nil {
+		return nil, err
+	}
+	run.Source = "kubescape-operator"
+	return run, nil
+}
+
+// readPostureReport reads a report written by the kubescape CLI.
+func readPostureReport(path string) (*complianceRun, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	run, err := parsePostureReport(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	run.Source = path
+	return run, nil
+}
+
+// policyFrameworks returns the frameworks the cluster's compliance policies
+// ask for, limited to those kubescape has.
+func policyFrameworks(ctx context.Context, dyn dynamic.Interface) ([]string, error) {
+	list, err := dyn.Resource(compliancePoliciesGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if crdMissing(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("list compliance policies: %w", err)
+	}
+	var out []string
+	for _, p := range list.Items {
+		standards, _, _ := unstructured.NestedStringSlice(p.Object, "spec", "parameters", "complianceStandards")
+		for _, s := range standards {
+			if f := standardFrameworks[s]; f != "" && !containsString(out, f) {
+				out = append(out, f)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
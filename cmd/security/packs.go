@@ -0,0 +1,178 @@
+package security
+
+// packs.go reports the Kyverno policy packs the platform ships: the baseline
+// kyverno-policies package and the opt-in packs in policy-packs, each policy
+// labelled with adhar.io/policy-pack.
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+var (
+	clusterPoliciesGVR      = schema.GroupVersionResource{Group: "kyverno.io", Version: "v1", Resource: "clusterpolicies"}
+	policyReportsGVR        = schema.GroupVersionResource{Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "policyreports"}
+	clusterPolicyReportsGVR = schema.GroupVersionResource{Group: "wgpolicyk8s.io", Version: "v1alpha2", Resource: "clusterpolicyreports"}
+)
+
+const (
+	policyPackLabel = "adhar.io/policy-pack"
+	// baselinePackName is the always-on kyverno-policies package; its
+	// policies carry app.kubernetes.io/part-of instead of a pack label.
+	baselinePackName = "kyverno-policies"
+)
+
+// knownPacks are the packs shipped in platform/stack/packages/security, so
+// packs that are not installed can still be listed as available.
+var knownPacks = []struct{ Name, Description string }{
+	{baselinePackName, "Pod Security Standards (restricted) and supply-chain checks"},
+	{"cis", "CIS Kubernetes benchmark controls"},
+	{"soc2", "SOC2 change-management and availability evidence"},
+	{"plane-isolation", "Keeps application workloads off the control plane"},
+}
+
+// policyPack summarises the ClusterPolicies of one pack.
+type policyPack struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Active      bool     `json:"active"`
+	Policies    []string `json:"policies"`
+	Mode        string   `json:"mode"` // Audit, Enforce or mixed
+	Ready       int      `json:"ready"`
+	Failures    int      `json:"failures"`
+}
+
+// packOf returns the pack a ClusterPolicy belongs to, or "" for policies the
+// platform did not ship.
+func packOf(p *unstructured.Unstructured) string {
+	labels := p.GetLabels()
+	if pack := labels[policyPackLabel]; pack != "" {
+		return pack
+	}
+	if labels["app.kubernetes.io/part-of"] == baselinePackName {
+		return baselinePackName
+	}
+	return ""
+}
+
+// policyMode reads the validation failure action, which Kyverno accepts on
+// the spec or per validate rule.
+func policyMode(p *unstructured.Unstructured) string {
+	if mode := str(p.Object, "spec", "validationFailureAction"); mode != "" {
+		return mode
+	}
+	rules, _, _ := unstructured.NestedSlice(p.Object, "spec", "rules")
+	for _, r := range rules {
+		if m, ok := r.(map[string]interface{}); ok {
+			if mode := str(m, "validate", "failureAction"); mode != "" {
+				return mode
+			}
+		}
+	}
+	return "Audit"
+}
+
+func policyReady(p *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(p.Object, "status", "conditions")
+	for _, c := range conditions {
+		if m, ok := c.(map[string]interface{}); ok && str(m, "type") == "Ready" {
+			return str(m, "status") == "True"
+		}
+	}
+	return false
+}
+
+// listPolicyPacks groups the installed ClusterPolicies into packs and counts
+// failed results per pack from the policy reports.
+func listPolicyPacks(ctx context.Context, dyn dynamic.Interface) ([]policyPack, error) {
+	list, err := dyn.Resource(clusterPoliciesGVR).List(ctx, metav1.ListOptions{})
+	if err != nil && !crdMissing(err) {
+		return nil, fmt.Errorf("list kyverno cluster policies: %w", err)
+	}
+	packs := map[string]*policyPack{}
+	for _, k := range knownPacks {
+		packs[k.Name] = &policyPack{Name: k.Name, Description: k.Description}
+	}
+	policyToPack := map[string]string{}
+	if list != nil {
+		for i := range list.Items {
+			p := &list.Items[i]
+			name := packOf(p)
+			if name == "" {
+				continue
+			}
+			pack := packs[name]
+			if pack == nil {
+				pack = &policyPack{Name: name}
+				packs[name] = pack
+			}
+			pack.Active = true
+			pack.Policies = append(pack.Policies, p.GetName())
+			policyToPack[p.GetName()] = name
+			switch mode := policyMode(p); {
+			case pack.Mode == "":
+				pack.Mode = mode
+			case pack.Mode != mode:
+				pack.Mode = "mixed"
+			}
+			if policyReady(p) {
+				pack.Ready++
+			}
+		}
+	}
+
+	failures, err := policyFailures(ctx, dyn)
+	if err != nil {
+		return nil, err
+	}
+	for name, n := range failures {
+		if pack := policyToPack[name]; pack != "" {
+			packs[pack].Failures += n
+		}
+	}
+
+	var out []policyPack
+	for _, p := range packs {
+		sort.Strings(p.Policies)
+		if p.Policies == nil {
+			p.Policies = []string{}
+		}
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Active != out[j].Active {
+			return out[i].Active
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+// policyFailures counts failed results per policy across all policy reports.
+func policyFailures(ctx context.Context, dyn dynamic.Interface) (map[string]int, error) {
+	failures := map[string]int{}
+	for _, gvr := range []schema.GroupVersionResource{policyReportsGVR, clusterPolicyReportsGVR} {
+		reports, err := dyn.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			if crdMissing(err) {
+				continue
+			}
+			return nil, fmt.Errorf("list %s: %w", gvr.Resource, err)
+		}
+		for _, r := range reports.Items {
+			results, _, _ := unstructured.NestedSlice(r.Object, "results")
+			for _, res := range results {
+				if m, ok := res.(map[string]interface{}); ok && str(m, "result") == "fail" {
+					failures[str(m, "policy")]++
+				}
+			}
+		}
+	}
+	return failures, nil
+}
@@ -0,0 +1,319 @@
+package security
+
+// playbooks.go holds the response actions `incidents respond` can run. Each
+// playbook acts on the resources named in the incident and returns a line for
+// the incident timeline.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"adhar-io/adhar/platform/utils"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	quarantineLabel = "adhar.io/quarantine"
+	// defaultKeycloakURL is the platform's Keycloak (same default as `adhar auth`).
+	defaultKeycloakURL = "https://keycloak.adhar.localtest.me:8443"
+	keycloakRealm      = "adhar"
+	keycloakAdminUser  = "adhar-admin"
+	keycloakSecret     = "keycloak-config"
+)
+
+var ciliumNetworkPolicyGVR = schema.GroupVersionResource{Group: "cilium.io", Version: "v2", Resource: "ciliumnetworkpolicies"}
+
+// playbookEnv carries what playbooks need besides the incident.
+type playbookEnv struct {
+	cs          kubernetes.Interface
+	dyn         dynamic.Interface
+	dest        string
+	user        string
+	keycloakURL string
+	http        *http.Client
+}
+
+// playbook is a named response action.
+type playbook struct {
+	Name        string
+	Description string
+	// Contains marks actions that stop the threat, moving an open incident
+	// to contained.
+	Contains bool
+	Run      func(ctx context.Context, env *playbookEnv, in *incident) (string, error)
+}
+
+var playbooks = []playbook{
+	{Name: "isolate-pod", Description: "Deny all traffic to and from the pod with a CiliumNetworkPolicy", Contains: true, Run: isolatePod},
+	{Name: "cordon-node", Description: "Mark the pod's node unschedulable", Contains: true, Run: cordonNode},
+	{Name: "snapshot-logs", Description: "Save the pod's manifest and container logs locally", Run: snapshotLogs},
+	{Name: "revoke-sessions", Description: "Log the user out of every Keycloak session", Contains: true, Run: revokeSessions},
+}
+
+func findPlaybook(name string) (playbook, error) {
+	var names []string
+	for _, p := range playbooks {
+		if p.Name == name {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	return playbook{}, fmt.Errorf("unknown playbook %q (%s)", name, strings.Join(names, ", "))
+}
+
+func requirePod(in *incident) error {
+	if in.Namespace == "" || in.Pod == "" {
+		return fmt.Errorf("incident %s does not name a pod", in.ID)
+	}
+	return nil
+}
+
+// isolatePod labels the pod with the incident ID and applies a
+// CiliumNetworkPolicy denying all traffic to and from that label. Deny rules
+// win over every allow rule, whereas a plain deny-all NetworkPolicy would be
+// unioned with the policies that already admit the pod's traffic. Labelling
+// only the affected pod keeps its replicas serving; the owning controller
+// ignores the extra label.
+func isolatePod(ctx context.Context, env *playbookEnv, in *incident) (string, error) {
+	if err := requirePod(in); err != nil {
+		return "", err
+	}
+	patch := fmt.Sprintf(`{"metadata":{"labels":{%q:%q}}}`, quarantineLabel, in.ID)
+	if _, err := env.cs.CoreV1().Pods(in.Namespace).Patch(ctx, in.Pod, types.MergePatchType, []byte(patch), metav1.PatchOptions{}); err != nil {
+		return "", fmt.Errorf("label pod %s/%s: %w", in.Namespace, in.Pod, err)
+	}
+	name := "quarantine-" + in.ID
+	cnp := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cilium.io/v2",
+		"kind":       "CiliumNetworkPolicy",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": in.Namespace,
+			"labels":    map[string]interface{}{incidentLabel: in.ID, "app.kubernetes.io/managed-by": "adhar"},
+		},
+		"spec": map[string]interface{}{
+			"endpointSelector": map[string]interface{}{"matchLabels": map[string]interface{}{quarantineLabel: in.ID}},
+			"ingressDeny":      []interface{}{map[string]interface{}{"fromEntities": []interface{}{"all"}}},
+			"egressDeny":       []interface{}{map[string]interface{}{"toEntities": []interface{}{"all"}}},
+		},
+	}}
+	_, err := env.dyn.Resource(ciliumNetworkPolicyGVR).Namespace(in.Namespace).Create(ctx, cnp, metav1.CreateOptions{})
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("create CiliumNetworkPolicy: %w", err)
+	}
+	return fmt.Sprintf("pod %s/%s isolated by CiliumNetworkPolicy %s", in.Namespace, in.Pod, name), nil
+}
+
+// cordonNode marks the incident's node (or the pod's) unschedulable.
+func cordonNode(ctx context.Context, env *playbookEnv, in *incident) (string, error) {
+	node := in.Node
+	if node == "" {
+		if err := requirePod(in); err != nil {
+			return "", fmt.Errorf("incident %s names neither a node nor a pod", in.ID)
+		}
+		pod, err := env.cs.CoreV1().Pods(in.Namespace).Get(ctx, in.Pod, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("get pod %s/%s: %w", in.Namespace, in.Pod, err)
+		}
+		node = pod.Spec.NodeName
+		in.Node = node
+	}
+	patch := []byte(`{"spec":{"unschedulable":true}}`)
+	if _, err := env.cs.CoreV1().Nodes().Patch(ctx, node, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return "", fmt.Errorf("cordon node %s: %w", node, err)
+	}
+	return "node " + node + " cordoned", nil
+}
+
+// snapshotLogs writes the pod manifest and the current and previous logs of
+// every container to <dest>/<incident>/<pod>/.
+func snapshotLogs(ctx context.Context, env *playbookEnv, in *incident) (string, error) {
+	if err := requirePod(in); err != nil {
+		return "", err
+	}
+	pod, err := env.cs.CoreV1().Pods(in.Namespace).Get(ctx, in.Pod, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("get pod %s/%s: %w", in.Namespace, in.Pod, err)
+	}
+	dir := filepath.Join(env.dest, in.ID, in.Pod)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	manifest, err := json.MarshalIndent(pod, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pod.json"), manifest, 0o600); err != nil {
+		return "", err
+	}
+	files := 1
+
+	restarted := map[string]bool{}
+	for _, s := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		restarted[s.Name] = s.RestartCount > 0
+	}
+	var containers []string
+	for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		containers = append(containers, c.Name)
+	}
+	for _, c := range containers {
+		if err := saveLogs(ctx, env.cs, pod, c, false, filepath.Join(dir, c+".log")); err != nil {
+			return "", err
+		}
+		files++
+		if restarted[c] {
+			if err := saveLogs(ctx, env.cs, pod, c, true, filepath.Join(dir, c+".previous.log")); err == nil {
+				files++
+			}
+		}
+	}
+	return fmt.Sprintf("saved %d file(s) to %s", files, dir), nil
+}
+
+func saveLogs(ctx context.Context, cs kubernetes.Interface, pod *corev1.Pod, container string, previous bool, path string) error {
+	stream, err := cs.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+		Container: container, Previous: previous, Timestamps: true,
+	}).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("read logs of %s/%s: %w", pod.Name, container, err)
+	}
+	defer stream.Close()
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, stream)
+	return err
+}
+
+// revokeSessions logs the user out of every session in the platform realm
+// through the Keycloak admin API, authenticating as the bootstrap admin whose
+// password the keycloak package keeps in the keycloak-config secret.
+func revokeSessions(ctx context.Context, env *playbookEnv, in *incident) (string, error) {
+	user := valueOr(env.user, in.User)
+	if user == "" {
+		return "", fmt.Errorf("incident %s names no user; pass --user", in.ID)
+	}
+	secret, err := env.cs.CoreV1().Secrets(incidentNamespace).Get(ctx, keycloakSecret, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("read keycloak admin credentials: %w", err)
+	}
+	kc := &keycloakAdmin{baseURL: strings.TrimRight(env.keycloakURL, "/"), http: env.http}
+	if err := kc.login(ctx, keycloakAdminUser, string(secret.Data["KEYCLOAK_ADMIN_PASSWORD"])); err != nil {
+		return "", err
+	}
+	id, err := kc.userID(ctx, user)
+	if err != nil {
+		return "", err
+	}
+	if err := kc.logout(ctx, id); err != nil {
+		return "", err
+	}
+	in.User = user
+	return "keycloak sessions of " + user + " revoked", nil
+}
+
+// keycloakAdmin is the slice of the Keycloak admin API the playbooks use.
+type keycloakAdmin struct {
+	baseURL string
+	http    *http.Client
+	token   string
+}
+
+func (k *keycloakAdmin) login(ctx context.Context, user, password string) error {
+	form := url.Values{"grant_type": {"password"}, "client_id": {"admin-cli"}, "username": {user}, "password": {password}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, k.baseURL+"/realms/master/protocol/openid-connect/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var tok struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := k.do(req, &tok); err != nil {
+		return fmt.Errorf("keycloak admin login: %w", err)
+	}
+	k.token = tok.AccessToken
+	return nil
+}
+
+func (k *keycloakAdmin) userID(ctx context.Context, username string) (string, error) {
+	u := fmt.Sprintf("%s/admin/realms/%s/users?exact=true&username=%s", k.baseURL, keycloakRealm, url.QueryEscape(username))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	var users []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	}
+	if err := k.do(req, &users); err != nil {
+		return "", fmt.Errorf("look up keycloak user %s: %w", username, err)
+	}
+	if len(users) == 0 {
+		return "", fmt.Errorf("keycloak user %q not found in realm %s", username, keycloakRealm)
+	}
+	return users[0].ID, nil
+}
+
+func (k *keycloakAdmin) logout(ctx context.Context, id string) error {
+	u := fmt.Sprintf("%s/admin/realms/%s/users/%s/logout", k.baseURL, keycloakRealm, url.PathEscape(id))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, nil)
+	if err != nil {
+		return err
+	}
+	if err := k.do(req, nil); err != nil {
+		return fmt.Errorf("revoke keycloak sessions: %w", err)
+	}
+	return nil
+}
+
+func (k *keycloakAdmin) do(req *http.Request, out interface{}) error {
+	if k.token != "" {
+		req.Header.Set("Authorization", "Bearer "+k.token)
+	}
+	resp, err := k.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func newPlaybookEnv(cs kubernetes.Interface, dyn dynamic.Interface, dest, user, keycloakURL string) *playbookEnv {
+	return &playbookEnv{cs: cs, dyn: dyn, dest: dest, user: user, keycloakURL: valueOr(keycloakURL, defaultKeycloakURL), http: utils.GetHttpClient()}
+}
+
+// playbookNames lists the playbooks for help text.
+func playbookNames() string {
+	var names []string
+	for _, p := range playbooks {
+		names = append(names, p.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
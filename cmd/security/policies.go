@@ -1,90 +1,373 @@
 package security
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
 
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/platform/k8s"
 	"adhar-io/adhar/platform/logger"
 
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 var policiesCmd = &cobra.Command{
 	Use:   "policies",
 	Short: "Manage security policies",
-	Long: `Manage security policies and compliance rules.
-	
+	Long: `Report and roll out Pod Security Standards and Kyverno policy packs.
+
+Pod Security Admission is configured per namespace with the
+pod-security.kubernetes.io/{audit,warn,enforce} labels. ` + "`check`" + ` shows which
+workloads a level would reject, and ` + "`rollout`" + ` moves namespaces through
+audit, then warn, then enforce, holding enforcement back while workloads
+still violate the level.
+
 Examples:
   adhar security policies list
-  adhar security policies apply --policy=strict
-  adhar security policies validate`,
-	RunE: runPolicies,
+  adhar security policies check prod --level=restricted
+  adhar security policies rollout --level=baseline                 # plan the next stage everywhere
+  adhar security policies rollout prod staging --level=restricted --apply
+  adhar security policies rollout prod --stage=audit --apply       # step back to audit`,
+	RunE: runPoliciesList,
 }
 
-var (
-	policyName     string
-	applyPolicy    bool
-	validatePolicy bool
-)
+var policyOutput string
 
 func init() {
-	policiesCmd.Flags().StringVarP(&policyName, "policy", "p", "", "Policy name to apply or validate")
-	policiesCmd.Flags().BoolVar(&applyPolicy, "apply", false, "Apply security policy")
-	policiesCmd.Flags().BoolVar(&validatePolicy, "validate", false, "Validate security policy")
+	policiesCmd.PersistentFlags().StringVarP(&policyOutput, "output", "o", "table", "Output format (table, json, yaml)")
+
+	policiesCmd.AddCommand(policiesListCmd)
+	policiesCmd.AddCommand(policiesCheckCmd)
+	policiesCmd.AddCommand(policiesRolloutCmd)
 }
 
-func runPolicies(cmd *cobra.Command, args []string) error {
-	logger.Info("📋 Managing security policies...")
+func policyClients() (kubernetes.Interface, error) {
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the cluster (is it running? try `adhar up`): %w", err)
+	}
+	return cs, nil
+}
+
+func isSystemNamespace(ns string) bool {
+	return containsString(systemNamespaces, ns)
+}
 
-	if policyName != "" {
-		if applyPolicy {
-			return applySecurityPolicy(policyName)
+// targetNamespaces returns the named namespaces, or every non-system
+// namespace when none are named.
+func targetNamespaces(ctx context.Context, cs kubernetes.Interface, names []string) ([]corev1.Namespace, error) {
+	if len(names) > 0 {
+		var out []corev1.Namespace
+		for _, name := range names {
+			ns, err := cs.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("namespace %s: %w", name, err)
+			}
+			out = append(out, *ns)
 		}
-		if validatePolicy {
-			return validateSecurityPolicy(policyName)
+		return out, nil
+	}
+	list, err := cs.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces: %w", err)
+	}
+	var out []corev1.Namespace
+	for _, ns := range list.Items {
+		if !isSystemNamespace(ns.Name) {
+			out = append(out, ns)
 		}
 	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
 
-	return listSecurityPolicies()
+// checkPolicyOutput validates -o for the policies subcommands.
+func checkPolicyOutput() error {
+	switch policyOutput {
+	case "", "table", "json", "yaml":
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %q (table, json, yaml)", policyOutput)
 }
 
-func applySecurityPolicy(policyName string) error {
-	logger.Info(fmt.Sprintf("🔧 Applying security policy: %s", policyName))
+func checkLevelFlag(level string) error {
+	if level != "baseline" && level != "restricted" {
+		return fmt.Errorf("--level must be baseline or restricted")
+	}
+	return nil
+}
 
-	// TODO: Implement policy application
-	// This should:
-	// - Load policy configuration
-	// - Apply to Kubernetes cluster
-	// - Update security settings
-	// - Verify policy enforcement
+// --- list ---
 
-	logger.Info("✅ Security policy applied successfully")
-	return nil
+var policiesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Show Pod Security levels per namespace and active policy packs",
+	RunE:  runPoliciesList,
+}
+
+// policyPosture is the JSON/YAML form of `policies list`.
+type policyPosture struct {
+	Namespaces []psaLabels  `json:"namespaces"`
+	Packs      []policyPack `json:"packs"`
+}
+
+func runPoliciesList(cmd *cobra.Command, args []string) error {
+	cs, err := policyClients()
+	if err != nil {
+		return err
+	}
+	dyn, err := k8s.GetDynamicClient()
+	if err != nil {
+		return fmt.Errorf("could not connect to the cluster: %w", err)
+	}
+	ctx := commandContext(cmd)
+	list, err := cs.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list namespaces: %w", err)
+	}
+	posture := policyPosture{}
+	for i := range list.Items {
+		posture.Namespaces = append(posture.Namespaces, namespacePSA(&list.Items[i]))
+	}
+	sort.Slice(posture.Namespaces, func(i, j int) bool { return posture.Namespaces[i].Namespace < posture.Namespaces[j].Namespace })
+	if posture.Packs, err = listPolicyPacks(ctx, dyn); err != nil {
+		return err
+	}
+
+	switch policyOutput {
+	case "json":
+		return helpers.PrintJSON(posture)
+	case "yaml":
+		return helpers.PrintYAML(posture)
+	case "", "table":
+	default:
+		return fmt.Errorf("unsupported output format %q (table, json, yaml)", policyOutput)
+	}
+
+	fmt.Println(helpers.InfoStyle.Render("Pod Security Admission"))
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tENFORCE\tWARN\tAUDIT")
+	unlabelled := 0
+	for _, p := range posture.Namespaces {
+		name := p.Namespace
+		if isSystemNamespace(name) {
+			name += " (platform)"
+		}
+		if len(p.Levels) == 0 {
+			unlabelled++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, p.describe("enforce"), p.describe("warn"), p.describe("audit"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if unlabelled > 0 {
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("%d namespace(s) have no Pod Security labels and admit privileged pods; see `adhar security policies rollout`", unlabelled)))
+	}
+
+	fmt.Println()
+	fmt.Println(helpers.InfoStyle.Render("Kyverno policy packs"))
+	tw = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACK\tSTATUS\tPOLICIES\tMODE\tREADY\tVIOLATIONS\tDESCRIPTION")
+	for _, p := range posture.Packs {
+		status := "available"
+		if p.Active {
+			status = "active"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d\t%d\t%s\n", p.Name, status, len(p.Policies), dash(p.Mode), p.Ready, p.Failures, p.Description)
+	}
+	return tw.Flush()
+}
+
+// --- check ---
+
+var policiesCheckCmd = &cobra.Command{
+	Use:   "check [namespace...]",
+	Short: "Show which workloads a Pod Security level would reject",
+	Long: `Evaluate the pod templates of every Deployment, StatefulSet, DaemonSet,
+CronJob and Job (and of unowned pods) against a Pod Security Standards level,
+using the same checks as the API server's Pod Security Admission. Nothing is
+changed. Without namespaces every non-platform namespace is checked.`,
+	RunE: runPoliciesCheck,
+}
+
+var (
+	pssLevel   string
+	pssVersion string
+)
+
+func init() {
+	for _, c := range []*cobra.Command{policiesCheckCmd, policiesRolloutCmd} {
+		c.Flags().StringVar(&pssLevel, "level", "restricted", "Pod Security Standards level (baseline, restricted)")
+		c.Flags().StringVar(&pssVersion, "version", "latest", "Pod Security Standards version (latest or v1.x)")
+	}
 }
 
-func validateSecurityPolicy(policyName string) error {
-	logger.Info(fmt.Sprintf("✅ Validating security policy: %s", policyName))
+// namespaceViolations evaluates every workload in ns.
+func namespaceViolations(ctx context.Context, cs kubernetes.Interface, ns string) ([]workloadViolation, error) {
+	templates, err := namespaceTemplates(ctx, cs, ns)
+	if err != nil {
+		return nil, err
+	}
+	return evaluateTemplates(ns, templates, pssLevel, pssVersion)
+}
 
-	// TODO: Implement policy validation
-	// This should:
-	// - Check policy syntax
-	// - Validate against cluster
-	// - Check for conflicts
-	// - Report validation results
+func runPoliciesCheck(cmd *cobra.Command, args []string) error {
+	if err := checkLevelFlag(pssLevel); err != nil {
+		return err
+	}
+	cs, err := policyClients()
+	if err != nil {
+		return err
+	}
+	ctx := commandContext(cmd)
+	namespaces, err := targetNamespaces(ctx, cs, args)
+	if err != nil {
+		return err
+	}
+	violations := []workloadViolation{}
+	for _, ns := range namespaces {
+		v, err := namespaceViolations(ctx, cs, ns.Name)
+		if err != nil {
+			return err
+		}
+		violations = append(violations, v...)
+	}
 
-	logger.Info("✅ Security policy validation completed")
+	switch policyOutput {
+	case "json":
+		return helpers.PrintJSON(violations)
+	case "yaml":
+		return helpers.PrintYAML(violations)
+	case "", "table":
+	default:
+		return fmt.Errorf("unsupported output format %q (table, json, yaml)", policyOutput)
+	}
+	if len(violations) == 0 {
+		fmt.Println(helpers.CreateSuccess(fmt.Sprintf("All workloads in %d namespace(s) meet %s", len(namespaces), pssLevel)))
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tWORKLOAD\tVIOLATIONS")
+	for _, v := range violations {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", v.Namespace, v.Workload, strings.Join(v.Reasons, "; "))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d workload(s) would be rejected at %s\n", len(violations), pssLevel)
 	return nil
 }
 
-func listSecurityPolicies() error {
-	logger.Info("📋 Listing security policies...")
+// --- rollout ---
+
+var policiesRolloutCmd = &cobra.Command{
+	Use:   "rollout [namespace...]",
+	Short: "Plan and apply staged Pod Security enforcement",
+	Long: `Move namespaces through the Pod Security rollout stages for a level:
 
-	// TODO: Implement policy listing
-	// This should show:
-	// - Available policies
-	// - Currently active policies
-	// - Policy descriptions
-	// - Compliance status
+  audit    violations are recorded in the API server audit log
+  warn     clients see warnings when they create violating workloads
+  enforce  violating pods are rejected
 
-	logger.Info("✅ Security policies listed")
+Each run advances every namespace one stage (or to --stage). Enforcement is
+held back for namespaces whose workloads still violate the level, unless
+--force. Without --apply the plan is only printed. Without namespaces every
+non-platform namespace is planned.`,
+	RunE: runPoliciesRollout,
+}
+
+var (
+	rolloutStage string
+	rolloutApply bool
+	rolloutForce bool
+)
+
+func init() {
+	policiesRolloutCmd.Flags().StringVar(&rolloutStage, "stage", "", "Move straight to this stage (audit, warn, enforce) instead of the next one")
+	policiesRolloutCmd.Flags().BoolVar(&rolloutApply, "apply", false, "Label the namespaces (default: print the plan only)")
+	policiesRolloutCmd.Flags().BoolVar(&rolloutForce, "force", false, "Enforce even when workloads violate the level")
+}
+
+func runPoliciesRollout(cmd *cobra.Command, args []string) error {
+	// Validated up front: --apply relabels namespaces before the plan prints
+	if err := checkPolicyOutput(); err != nil {
+		return err
+	}
+	if err := checkLevelFlag(pssLevel); err != nil {
+		return err
+	}
+	if rolloutStage != "" && stageRank(rolloutStage) == 0 {
+		return fmt.Errorf("--stage must be audit, warn or enforce")
+	}
+	if _, err := levelVersion(pssLevel, pssVersion); err != nil {
+		return err
+	}
+	cs, err := policyClients()
+	if err != nil {
+		return err
+	}
+	ctx := commandContext(cmd)
+	namespaces, err := targetNamespaces(ctx, cs, args)
+	if err != nil {
+		return err
+	}
+
+	plan := []rolloutStep{}
+	for i := range namespaces {
+		violations, err := namespaceViolations(ctx, cs, namespaces[i].Name)
+		if err != nil {
+			return err
+		}
+		plan = append(plan, planStep(namespacePSA(&namespaces[i]), pssLevel, rolloutStage, len(violations), rolloutForce))
+	}
+
+	if rolloutApply {
+		for i, step := range plan {
+			if step.Next == step.Current {
+				continue
+			}
+			if err := applyStage(ctx, cs, &namespaces[i], pssLevel, pssVersion, step.Next); err != nil {
+				return err
+			}
+			if policyOutput == "" || policyOutput == "table" {
+				logger.Info(fmt.Sprintf("🔒 %s: %s → %s", step.Namespace, step.Current, step.Next))
+			}
+		}
+	}
+
+	switch policyOutput {
+	case "json":
+		return helpers.PrintJSON(plan)
+	case "yaml":
+		return helpers.PrintYAML(plan)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAMESPACE\tCURRENT\tNEXT\tVIOLATIONS\tACTION")
+	held := 0
+	for _, s := range plan {
+		if s.Blocked {
+			held++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", s.Namespace, s.Current, s.Next, s.Violations, s.Action)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if held > 0 {
+		fmt.Println(helpers.CreateMuted(fmt.Sprintf("\n%d namespace(s) held at their current stage; run `adhar security policies check --level=%s` to see why", held, pssLevel)))
+	}
+	if !rolloutApply {
+		fmt.Println(helpers.CreateMuted("\nPlan only; re-run with --apply to label the namespaces."))
+	} else {
+		fmt.Println(helpers.CreateSuccess("Rollout stage applied"))
+	}
 	return nil
 }
@@ -0,0 +1,318 @@
+package security
+
+// pss.go evaluates workloads against the Pod Security Standards and plans the
+// staged rollout of Pod Security Admission labels: audit first, then warn,
+// then enforce, one namespace at a time.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	psaapi "k8s.io/pod-security-admission/api"
+	psapolicy "k8s.io/pod-security-admission/policy"
+)
+
+// systemNamespaces are left alone by checks and rollouts unless named
+// explicitly; the policy packs exclude the same set.
+var systemNamespaces = []string{"kube-system", "kube-public", "kube-node-lease", "adhar-system", "crossplane-system", "local-path-storage"}
+
+// PSA modes in rollout order.
+var psaModes = []string{"audit", "warn", "enforce"}
+
+// psaLabel returns the namespace label for a PSA mode, e.g.
+// pod-security.kubernetes.io/enforce.
+func psaLabel(mode string) string {
+	return "pod-security.kubernetes.io/" + mode
+}
+
+// psaLabels is the Pod Security Admission configuration of a namespace.
+type psaLabels struct {
+	Namespace string            `json:"namespace"`
+	Levels    map[string]string `json:"levels"`   // mode → level
+	Versions  map[string]string `json:"versions"` // mode → version
+}
+
+func namespacePSA(ns *corev1.Namespace) psaLabels {
+	p := psaLabels{Namespace: ns.Name, Levels: map[string]string{}, Versions: map[string]string{}}
+	for _, mode := range psaModes {
+		if v := ns.Labels[psaLabel(mode)]; v != "" {
+			p.Levels[mode] = v
+		}
+		if v := ns.Labels[psaLabel(mode)+"-version"]; v != "" {
+			p.Versions[mode] = v
+		}
+	}
+	return p
+}
+
+// levelRank orders PSS levels; an unset mode is privileged.
+func levelRank(level string) int {
+	switch level {
+	case string(psaapi.LevelRestricted):
+		return 2
+	case string(psaapi.LevelBaseline):
+		return 1
+	}
+	return 0
+}
+
+// stage returns how far the namespace's rollout to level has got: "none",
+// "audit", "warn" or "enforce" (the strictest mode set to level or stricter).
+// A namespace labelled only enforce=restricted is already at enforce; the
+// weaker modes add nothing to it.
+func (p psaLabels) stage(level string) string {
+	stage := "none"
+	for _, mode := range psaModes {
+		if levelRank(p.Levels[mode]) >= levelRank(level) {
+			stage = mode
+		}
+	}
+	return stage
+}
+
+// stricterAfter returns the first mode beyond stage whose level is stricter
+// than level, or "" if there is none. Rollouts never weaken such a mode.
+func (p psaLabels) stricterAfter(stage, level string) string {
+	for _, mode := range psaModes[stageRank(stage):] {
+		if levelRank(p.Levels[mode]) > levelRank(level) {
+			return mode
+		}
+	}
+	return ""
+}
+
+// describe renders a mode as level@version.
+func (p psaLabels) describe(mode string) string {
+	level := p.Levels[mode]
+	if level == "" {
+		return "-"
+	}
+	if v := p.Versions[mode]; v != "" {
+		return level + "@" + v
+	}
+	return level
+}
+
+// workloadViolation is a workload whose pods would be rejected at a level.
+type workloadViolation struct {
+	Namespace string   `json:"namespace"`
+	Workload  string   `json:"workload"`
+	Reasons   []string `json:"reasons"`
+}
+
+// podTemplate is a workload's pod metadata and spec.
+type podTemplate struct {
+	Workload string
+	Meta     *metav1.ObjectMeta
+	Spec     *corev1.PodSpec
+}
+
+// namespaceTemplates collects the pod templates of every workload in the
+// namespace, plus pods no controller owns.
+func namespaceTemplates(ctx context.Context, cs kubernetes.Interface, ns string) ([]podTemplate, error) {
+	var out []podTemplate
+	add := func(kind string, meta metav1.ObjectMeta, tmpl corev1.PodTemplateSpec) {
+		t := tmpl
+		out = append(out, podTemplate{Workload: kind + "/" + meta.Name, Meta: &t.ObjectMeta, Spec: &t.Spec})
+	}
+	opts := metav1.ListOptions{}
+	deployments, err := cs.AppsV1().Deployments(ns).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list deployments in %s: %w", ns, err)
+	}
+	for _, d := range deployments.Items {
+		add("Deployment", d.ObjectMeta, d.Spec.Template)
+	}
+	statefulSets, err := cs.AppsV1().StatefulSets(ns).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list statefulsets in %s: %w", ns, err)
+	}
+	for _, s := range statefulSets.Items {
+		add("StatefulSet", s.ObjectMeta, s.Spec.Template)
+	}
+	daemonSets, err := cs.AppsV1().DaemonSets(ns).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list daemonsets in %s: %w", ns, err)
+	}
+	for _, d := range daemonSets.Items {
+		add("DaemonSet", d.ObjectMeta, d.Spec.Template)
+	}
+	cronJobs, err := cs.BatchV1().CronJobs(ns).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list cronjobs in %s: %w", ns, err)
+	}
+	for _, c := range cronJobs.Items {
+		add("CronJob", c.ObjectMeta, c.Spec.JobTemplate.Spec.Template)
+	}
+	jobs, err := cs.BatchV1().Jobs(ns).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list jobs in %s: %w", ns, err)
+	}
+	for _, j := range jobs.Items {
+		if !ownedBy(j.OwnerReferences, "CronJob") {
+			add("Job", j.ObjectMeta, j.Spec.Template)
+		}
+	}
+	pods, err := cs.CoreV1().Pods(ns).List(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list pods in %s: %w", ns, err)
+	}
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		if len(p.OwnerReferences) == 0 {
+			out = append(out, podTemplate{Workload: "Pod/" + p.Name, Meta: &p.ObjectMeta, Spec: &p.Spec})
+		}
+	}
+	return out, nil
+}
+
+func ownedBy(refs []metav1.OwnerReference, kind string) bool {
+	for _, r := range refs {
+		if r.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateTemplates returns the templates that violate level at version.
+func evaluateTemplates(ns string, templates []podTemplate, level, version string) ([]workloadViolation, error) {
+	lv, err := levelVersion(level, version)
+	if err != nil {
+		return nil, err
+	}
+	evaluator, err := psapolicy.NewEvaluator(psapolicy.DefaultChecks(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var out []workloadViolation
+	for _, t := range templates {
+		result := psapolicy.AggregateCheckResults(evaluator.EvaluatePod(lv, t.Meta, t.Spec))
+		if result.Allowed {
+			continue
+		}
+		v := workloadViolation{Namespace: ns, Workload: t.Workload}
+		for i, reason := range result.ForbiddenReasons {
+			if detail := result.ForbiddenDetails[i]; detail != "" {
+				reason += " (" + detail + ")"
+			}
+			v.Reasons = append(v.Reasons, reason)
+		}
+		out = append(out, v)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Workload < out[j].Workload })
+	return out, nil
+}
+
+func levelVersion(level, version string) (psaapi.LevelVersion, error) {
+	l, err := psaapi.ParseLevel(level)
+	if err != nil {
+		return psaapi.LevelVersion{}, fmt.Errorf("invalid level %q (baseline, restricted)", level)
+	}
+	v, err := psaapi.ParseVersion(valueOr(version, "latest"))
+	if err != nil {
+		return psaapi.LevelVersion{}, fmt.Errorf("invalid version %q (latest or v1.x)", version)
+	}
+	return psaapi.LevelVersion{Level: l, Version: v}, nil
+}
+
+// rolloutStep is the plan for one namespace.
+type rolloutStep struct {
+	Namespace  string `json:"namespace"`
+	Current    string `json:"currentStage"`
+	Next       string `json:"nextStage"`
+	Violations int    `json:"violations"`
+	Action     string `json:"action"`
+	// Blocked is set when enforcing would reject existing workloads.
+	Blocked bool `json:"blocked,omitempty"`
+}
+
+// planStep decides the next stage for a namespace. With target "" the
+// namespace advances one stage; otherwise it moves straight to target.
+// Enforcement is held back while workloads violate the level unless force.
+func planStep(p psaLabels, level, target string, violations int, force bool) rolloutStep {
+	s := rolloutStep{Namespace: p.Namespace, Current: p.stage(level), Violations: violations}
+	s.Next = target
+	if target == "" {
+		s.Next = nextStage(s.Current)
+	}
+	switch {
+	case s.Next == s.Current:
+		s.Action = "no change"
+	case s.Next == "enforce" && violations > 0 && !force:
+		s.Blocked = true
+		s.Action = fmt.Sprintf("hold: %d workload(s) would be rejected", violations)
+		s.Next = s.Current
+	case stageRank(s.Next) < stageRank(s.Current) && p.stricterAfter(s.Next, level) != "":
+		mode := p.stricterAfter(s.Next, level)
+		s.Action = fmt.Sprintf("keep: %s=%s is stricter than %s", mode, p.Levels[mode], level)
+		s.Next = s.Current
+	case stageRank(s.Next) < stageRank(s.Current):
+		s.Action = "relax to " + s.Next
+	default:
+		s.Action = "set " + strings.Join(modesUpTo(s.Next), "+") + "=" + level
+	}
+	return s
+}
+
+func nextStage(current string) string {
+	switch current {
+	case "none":
+		return "audit"
+	case "audit":
+		return "warn"
+	}
+	return "enforce"
+}
+
+func stageRank(stage string) int {
+	for i, m := range psaModes {
+		if m == stage {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// modesUpTo lists the modes a stage turns on.
+func modesUpTo(stage string) []string {
+	return psaModes[:stageRank(stage)]
+}
+
+// applyStage sets the PSA labels for stage. Modes beyond the stage that are
+// at level are removed, so relaxing a rollout takes effect. Modes already
+// stricter than level are never touched, and modes set to a weaker level
+// beyond the stage are left as they were.
+func applyStage(ctx context.Context, cs kubernetes.Interface, ns *corev1.Namespace, level, version, stage string) error {
+	labels := map[string]interface{}{}
+	on := map[string]bool{}
+	for _, mode := range modesUpTo(stage) {
+		on[mode] = true
+		if levelRank(ns.Labels[psaLabel(mode)]) > levelRank(level) {
+			continue
+		}
+		labels[psaLabel(mode)] = level
+		labels[psaLabel(mode)+"-version"] = valueOr(version, "latest")
+	}
+	for _, mode := range psaModes {
+		if !on[mode] && ns.Labels[psaLabel(mode)] != "" && levelRank(ns.Labels[psaLabel(mode)]) == levelRank(level) {
+			labels[psaLabel(mode)] = nil
+			labels[psaLabel(mode)+"-version"] = nil
+		}
+	}
+	patch, err := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"labels": labels}})
+	if err != nil {
+		return err
+	}
+	if _, err := cs.CoreV1().Namespaces().Patch(ctx, ns.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("label namespace %s: %w", ns.Name, err)
+	}
+	return nil
+}
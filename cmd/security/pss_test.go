@@ -0,0 +1,207 @@
+package security
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func deployment(name string, sc *corev1.SecurityContext) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "prod"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "app:1", SecurityContext: sc}},
+		}}},
+	}
+}
+
+func TestNamespaceViolations(t *testing.T) {
+	no, yes := false, true
+	restricted := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: &no,
+		RunAsNonRoot:             &yes,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+	cs := fake.NewSimpleClientset(
+		deployment("privileged", &corev1.SecurityContext{Privileged: &yes}),
+		deployment("default", nil),
+		deployment("hardened", restricted),
+	)
+	ctx := context.Background()
+	templates, err := namespaceTemplates(ctx, cs, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baseline, err := evaluateTemplates("prod", templates, "baseline", "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(baseline) != 1 || baseline[0].Workload != "Deployment/privileged" || !strings.Contains(baseline[0].Reasons[0], "privileged") {
+		t.Errorf("baseline violations = %+v", baseline)
+	}
+	strict, _ := evaluateTemplates("prod", templates, "restricted", "latest")
+	if len(strict) != 2 || strict[0].Workload != "Deployment/default" {
+		t.Errorf("restricted violations = %+v", strict)
+	}
+	if _, err := evaluateTemplates("prod", templates, "strict", "latest"); err == nil {
+		t.Errorf("invalid level accepted")
+	}
+}
+
+func TestRolloutPlan(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{
+		"pod-security.kubernetes.io/audit": "restricted",
+		"pod-security.kubernetes.io/warn":  "baseline",
+	}}}
+	p := namespacePSA(ns)
+	if got := p.stage("restricted"); got != "audit" {
+		t.Errorf("stage(restricted) = %s", got)
+	}
+	if got := p.stage("baseline"); got != "warn" {
+		t.Errorf("stage(baseline) = %s", got)
+	}
+
+	if s := planStep(p, "restricted", "", 0, false); s.Next != "warn" || s.Action != "set audit+warn=restricted" {
+		t.Errorf("next step = %+v", s)
+	}
+	if s := planStep(p, "restricted", "enforce", 3, false); !s.Blocked || s.Next != "audit" {
+		t.Errorf("enforce with violations = %+v", s)
+	}
+	if s := planStep(p, "restricted", "enforce", 3, true); s.Blocked || s.Next != "enforce" {
+		t.Errorf("forced enforce = %+v", s)
+	}
+
+	cs := fake.NewSimpleClientset(ns)
+	ctx := context.Background()
+	if err := applyStage(ctx, cs, ns, "restricted", "v1.36", "warn"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := cs.CoreV1().Namespaces().Get(ctx, "prod", metav1.GetOptions{})
+	if got.Labels["pod-security.kubernetes.io/warn"] != "restricted" || got.Labels["pod-security.kubernetes.io/warn-version"] != "v1.36" {
+		t.Errorf("labels after warn = %v", got.Labels)
+	}
+	if err := applyStage(ctx, cs, got, "restricted", "latest", "audit"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = cs.CoreV1().Namespaces().Get(ctx, "prod", metav1.GetOptions{})
+	if _, ok := got.Labels["pod-security.kubernetes.io/warn"]; ok || got.Labels["pod-security.kubernetes.io/audit"] != "restricted" {
+		t.Errorf("labels after relaxing to audit = %v", got.Labels)
+	}
+}
+
+func TestRolloutKeepsStricterLevels(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "payments", Labels: map[string]string{
+		"pod-security.kubernetes.io/enforce": "restricted",
+	}}}
+	p := namespacePSA(ns)
+	for _, level := range []string{"restricted", "baseline"} {
+		if got := p.stage(level); got != "enforce" {
+			t.Errorf("stage(%s) = %s, want enforce", level, got)
+		}
+		if s := planStep(p, level, "", 0, false); s.Next != "enforce" || s.Action != "no change" {
+			t.Errorf("planStep(%s) = %+v, want no change", level, s)
+		}
+	}
+	if s := planStep(p, "baseline", "audit", 0, false); s.Next != "enforce" || !strings.HasPrefix(s.Action, "keep:") {
+		t.Errorf("relaxing below a stricter enforce label = %+v", s)
+	}
+
+	cs := fake.NewSimpleClientset(ns)
+	ctx := context.Background()
+	if err := applyStage(ctx, cs, ns, "baseline", "", "audit"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := cs.CoreV1().Namespaces().Get(ctx, "payments", metav1.GetOptions{})
+	if got.Labels["pod-security.kubernetes.io/enforce"] != "restricted" {
+		t.Errorf("enforce label removed or weakened: %v", got.Labels)
+	}
+
+	strict := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ledger", Labels: map[string]string{
+		"pod-security.kubernetes.io/audit": "restricted",
+	}}}
+	cs = fake.NewSimpleClientset(strict)
+	if err := applyStage(ctx, cs, strict, "baseline", "", "warn"); err != nil {
+		t.Fatal(err)
+	}
+	got, _ = cs.CoreV1().Namespaces().Get(ctx, "ledger", metav1.GetOptions{})
+	if got.Labels["pod-security.kubernetes.io/audit"] != "restricted" || got.Labels["pod-security.kubernetes.io/warn"] != "baseline" {
+		t.Errorf("labels after warn=baseline = %v, want audit kept at restricted", got.Labels)
+	}
+}
+
+func TestListPolicyPacks(t *testing.T) {
+	policy := func(name string, labels map[string]interface{}, mode string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "kyverno.io/v1", "kind": "ClusterPolicy",
+			"metadata": map[string]interface{}{"name": name, "labels": labels},
+			"spec":     map[string]interface{}{"validationFailureAction": mode},
+			"status": map[string]interface{}{"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			}},
+		}}
+	}
+	report := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "wgpolicyk8s.io/v1alpha2", "kind": "PolicyReport",
+		"metadata": map[string]interface{}{"name": "r1", "namespace": "prod"},
+		"results": []interface{}{
+			map[string]interface{}{"policy": "cis-disallow-privileged-containers", "result": "fail"},
+			map[string]interface{}{"policy": "cis-disallow-privileged-containers", "result": "pass"},
+			map[string]interface{}{"policy": "disallow-capabilities", "result": "fail"},
+		},
+	}}
+	scheme := runtime.NewScheme()
+	dyn := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme, map[schema.GroupVersionResource]string{
+		clusterPoliciesGVR:      "ClusterPolicyList",
+		policyReportsGVR:        "PolicyReportList",
+		clusterPolicyReportsGVR: "ClusterPolicyReportList",
+	},
+		policy("disallow-capabilities", map[string]interface{}{"app.kubernetes.io/part-of": "kyverno-policies"}, "Audit"),
+		policy("cis-disallow-privileged-containers", map[string]interface{}{policyPackLabel: "cis"}, "Audit"),
+		policy("cis-require-limits", map[string]interface{}{policyPackLabel: "cis"}, "Enforce"),
+		policy("team-policy", nil, "Enforce"),
+		report,
+	)
+	packs, err := listPolicyPacks(context.Background(), dyn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]policyPack{}
+	for _, p := range packs {
+		byName[p.Name] = p
+	}
+	cis := byName["cis"]
+	if !cis.Active || len(cis.Policies) != 2 || cis.Mode != "mixed" || cis.Failures != 1 || cis.Ready != 2 {
+		t.Errorf("cis pack = %+v", cis)
+	}
+	if base := byName[baselinePackName]; !base.Active || base.Failures != 1 || base.Mode != "Audit" {
+		t.Errorf("baseline pack = %+v", base)
+	}
+	if soc2 := byName["soc2"]; soc2.Active {
+		t.Errorf("soc2 should only be available")
+	}
+	if !packs[0].Active || packs[len(packs)-1].Active {
+		t.Errorf("active packs should be listed first: %+v", packs)
+	}
+}
+
+func TestRolloutRejectsOutputBeforeApplying(t *testing.T) {
+	oldOutput, oldApply := policyOutput, rolloutApply
+	t.Cleanup(func() { policyOutput, rolloutApply = oldOutput, oldApply })
+	policyOutput, rolloutApply = "jsno", true
+
+	err := runPoliciesRollout(policiesRolloutCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "unsupported output format") {
+		t.Fatalf("err = %v, want the -o error before any cluster access", err)
+	}
+}
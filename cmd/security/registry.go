@@ -0,0 +1,385 @@
+package security
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// registryClient lists image tags through the OCI distribution API, using
+// anonymous bearer tokens where the registry asks for them (Docker Hub, GHCR,
+// Quay and most others do for public repositories).
+type registryClient struct {
+	HTTP   *http.Client
+	Scheme string // https unless a test or plain-HTTP registry says otherwise
+	tokens map[string]string
+}
+
+func newRegistryClient() *registryClient {
+	return &registryClient{HTTP: http.DefaultClient, Scheme: "https"}
+}
+
+// splitImage splits a reference into registry host, repository and tag.
+// Docker Hub is addressed through registry-1.docker.io.
+func splitImage(ref string) (host, repo, tag string) {
+	n := normalizeImage(ref)
+	host, rest, _ := strings.Cut(n, "/")
+	if i := strings.Index(rest, "@"); i >= 0 {
+		rest = rest[:i]
+	} else if i := strings.LastIndex(rest, ":"); i >= 0 {
+		rest, tag = rest[:i], rest[i+1:]
+	}
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+	return host, rest, tag
+}
+
+// listTags returns every tag of the image's repository, following Link
+// pagination.
+func (c *registryClient) listTags(ctx context.Context, ref string) ([]string, error) {
+	host, repo, _ := splitImage(ref)
+	path := "/v2/" + repo + "/tags/list?n=1000"
+	var tags []string
+	for path != "" {
+		resp, err := c.do(ctx, http.MethodGet, host, repo, path, "application/json")
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("list tags of %s/%s: %s", host, repo, resp.Status)
+		}
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("list tags of %s/%s: %w", host, repo, err)
+		}
+		tags = append(tags, page.Tags...)
+		path = ""
+		if next := nextLink(resp.Request.URL, resp.Header.Get("Link")); next != nil {
+			path = next.RequestURI()
+		}
+	}
+	return tags, nil
+}
+
+// do sends a request to the registry, answering a Bearer challenge with an
+// anonymous token for repo and retrying once. Tokens are reused per
+// repository.
+func (c *registryClient) do(ctx context.Context, method, host, repo, path, accept string) (*http.Response, error) {
+	key := host + "/" + repo
+	u := c.Scheme + "://" + host + path
+	resp, err := c.send(ctx, method, u, accept, c.tokens[key])
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized || c.tokens[key] != "" {
+		return resp, nil
+	}
+	challenge := resp.Header.Get("Www-Authenticate")
+	resp.Body.Close()
+	token, err := c.anonymousToken(ctx, challenge, repo)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", host, err)
+	}
+	if c.tokens == nil {
+		c.tokens = map[string]string{}
+	}
+	c.tokens[key] = token
+	return c.send(ctx, method, u, accept, token)
+}
+
+func (c *registryClient) send(ctx context.Context, method, u, accept, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return c.HTTP.Do(req)
+}
+
+// manifestAccept lists the manifest media types the CLI understands.
+const manifestAccept = "application/vnd.oci.image.index.v1+json, application/vnd.oci.image.manifest.v1+json, " +
+	"application/vnd.docker.distribution.manifest.list.v2+json, application/vnd.docker.distribution.manifest.v2+json"
+
+// errNotFound is returned for manifests and blobs the registry does not have.
+var errNotFound = errors.New("not found")
+
+// resolveDigest returns the manifest digest a tag points at.
+func (c *registryClient) resolveDigest(ctx context.Context, ref string) (string, error) {
+	host, repo, tag := splitImage(ref)
+	resp, err := c.do(ctx, http.MethodHead, host, repo, "/v2/"+repo+"/manifests/"+valueOr(tag, "latest"), manifestAccept)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("resolve %s: %s", ref, resp.Status)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("resolve %s: registry returned no digest", ref)
+	}
+	return digest, nil
+}
+
+// manifest fetches a manifest by tag or digest.
+func (c *registryClient) manifest(ctx context.Context, host, repo, reference string) ([]byte, error) {
+	return c.fetch(ctx, host, repo, "/v2/"+repo+"/manifests/"+reference, manifestAccept, reference)
+}
+
+// blob fetches a blob and checks it against its digest.
+func (c *registryClient) blob(ctx context.Context, host, repo, digest string) ([]byte, error) {
+	return c.fetch(ctx, host, repo, "/v2/"+repo+"/blobs/"+digest, "*/*", digest)
+}
+
+func (c *registryClient) fetch(ctx context.Context, host, repo, path, accept, reference string) ([]byte, error) {
+	resp, err := c.do(ctx, http.MethodGet, host, repo, path, accept)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s/%s@%s: %s", host, repo, reference, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(reference, "sha256:") {
+		if sum := sha256.Sum256(data); "sha256:"+hex.EncodeToString(sum[:]) != reference {
+			return nil, fmt.Errorf("%s/%s@%s: content does not match its digest", host, repo, reference)
+		}
+	}
+	return data, nil
+}
+
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// anonymousToken answers a Bearer challenge without credentials.
+func (c *registryClient) anonymousToken(ctx context.Context, challenge, repo string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", fmt.Errorf("registry requires credentials")
+	}
+	params := map[string]string{}
+	for _, m := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid auth challenge %q", challenge)
+	}
+	q := realm.Query()
+	if s := params["service"]; s != "" {
+		q.Set("service", s)
+	}
+	q.Set("scope", valueOr(params["scope"], "repository:"+repo+":pull"))
+	realm.RawQuery = q.Encode()
+
+	resp, err := c.send(ctx, http.MethodGet, realm.String(), "application/json", "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request: %s", resp.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("token request: %w", err)
+	}
+	return valueOr(body.Token, body.AccessToken), nil
+}
+
+// nextLink resolves a `<url>; rel="next"` Link header against the current
+// page, or returns nil on the last page.
+func nextLink(current *url.URL, header string) *url.URL {
+	for _, part := range strings.Split(header, ",") {
+		target, params, ok := strings.Cut(part, ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		u, err := url.Parse(strings.Trim(strings.TrimSpace(target), "<>"))
+		if err != nil {
+			return nil
+		}
+		return current.ResolveReference(u)
+	}
+	return nil
+}
+
+// proposeTag picks the newest tag in the same major version and variant
+// (the suffix after the version, e.g. -alpine) that is newer than current.
+// It returns "" when current is not a version or nothing newer exists.
+func proposeTag(current string, tags []string) string {
+	cur, variant, precision, ok := parseTag(current)
+	if !ok {
+		return ""
+	}
+	var best *semver.Version
+	bestTag := ""
+	for _, t := range tags {
+		// Keep the precision of the current tag: 1.25 moves to 1.27, not 1.27.3.
+		v, vv, p, ok := parseTag(t)
+		if !ok || vv != variant || p != precision || v.Major() != cur.Major() || !v.GreaterThan(cur) {
+			continue
+		}
+		if best == nil || v.GreaterThan(best) {
+			best, bestTag = v, t
+		}
+	}
+	return bestTag
+}
+
+var tagVersion = regexp.MustCompile(`^v?(\d+(?:\.\d+){0,2})(-.+)?$`)
+
+// parseTag splits tags like 1.25.3-alpine into a version, a variant and the
+// number of version segments. Pre-releases (rc, beta, alpha) are not
+// considered versions.
+func parseTag(tag string) (*semver.Version, string, int, bool) {
+	m := tagVersion.FindStringSubmatch(tag)
+	if m == nil {
+		return nil, "", 0, false
+	}
+	variant := m[2]
+	lower := strings.ToLower(variant)
+	for _, pre := range []string{"rc", "beta", "alpha", "dev", "snapshot"} {
+		if strings.Contains(lower, pre) {
+			return nil, "", 0, false
+		}
+	}
+	v, err := semver.NewVersion(m[1])
+	if err != nil {
+		return nil, "", 0, false
+	}
+	return v, variant, strings.Count(m[1], ".") + 1, true
+}
+
+// imageBump is a proposed tag change for one image.
+type imageBump struct {
+	Image     string       `json:"image"`
+	Current   string       `json:"currentTag"`
+	Proposed  string       `json:"proposedTag,omitempty"`
+	Fixable   int          `json:"fixableVulnerabilities"`
+	Critical  int          `json:"critical"`
+	Workloads []bumpTarget `json:"workloads"`
+	Note      string       `json:"note,omitempty"`
+}
+
+// bumpTarget is a container running the image.
+type bumpTarget struct {
+	Namespace string `json:"namespace"`
+	Workload  string `json:"workload"`
+	Container string `json:"container,omitempty"`
+}
+
+// planBumps groups fixable vulnerabilities by image and asks the registry for
+// a newer tag of each.
+func planBumps(ctx context.Context, rc *registryClient, fs []finding) []imageBump {
+	byImage := map[string]*imageBump{}
+	for _, f := range fs {
+		if f.Kind != kindVulnerability || !f.Fixable() || f.Image == "" {
+			continue
+		}
+		b := byImage[f.Image]
+		if b == nil {
+			_, _, tag := splitImage(f.Image)
+			b = &imageBump{Image: f.Image, Current: tag}
+			byImage[f.Image] = b
+		}
+		b.Fixable++
+		if f.Severity == "CRITICAL" {
+			b.Critical++
+		}
+		t := bumpTarget{Namespace: f.Namespace, Workload: f.Workload, Container: f.Container}
+		if !containsTarget(b.Workloads, t) {
+			b.Workloads = append(b.Workloads, t)
+		}
+	}
+
+	var bumps []imageBump
+	for _, b := range byImage {
+		sort.Slice(b.Workloads, func(i, j int) bool {
+			x, y := b.Workloads[i], b.Workloads[j]
+			return x.Namespace+"/"+x.Workload+"/"+x.Container < y.Namespace+"/"+y.Workload+"/"+y.Container
+		})
+		b.Proposed, b.Note = proposeBump(ctx, rc, b.Image, b.Current)
+		bumps = append(bumps, *b)
+	}
+	sort.Slice(bumps, func(i, j int) bool {
+		if bumps[i].Critical != bumps[j].Critical {
+			return bumps[i].Critical > bumps[j].Critical
+		}
+		if bumps[i].Fixable != bumps[j].Fixable {
+			return bumps[i].Fixable > bumps[j].Fixable
+		}
+		return bumps[i].Image < bumps[j].Image
+	})
+	return bumps
+}
+
+// proposeBump returns the proposed tag for image, or a note explaining why
+// there is none.
+func proposeBump(ctx context.Context, rc *registryClient, image, current string) (string, string) {
+	if current == "" {
+		return "", "pinned by digest"
+	}
+	if _, _, _, ok := parseTag(current); !ok {
+		return "", fmt.Sprintf("tag %q is not a version; pin a versioned tag first", current)
+	}
+	tags, err := rc.listTags(ctx, image)
+	if err != nil {
+		return "", err.Error()
+	}
+	if tag := proposeTag(current, tags); tag != "" {
+		return tag, ""
+	}
+	return "", "no newer tag in the same major version; rebuild the image or update its base"
+}
+
+// withTag replaces the tag (or digest) of an image reference; an empty tag
+// strips it.
+func withTag(ref, tag string) string {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	} else if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	if tag == "" {
+		return ref
+	}
+	return ref + ":" + tag
+}
+
+func containsTarget(list []bumpTarget, t bumpTarget) bool {
+	for _, v := range list {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
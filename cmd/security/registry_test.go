@@ -0,0 +1,85 @@
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProposeTag(t *testing.T) {
+	tags := []string{"latest", "1.25.3", "1.25.5", "1.27.1", "1.27.1-alpine", "1.28.0-rc1", "2.0.0", "1.26", "1.27",
+		"1.25.3-alpine", "1.25.4-alpine"}
+	for current, want := range map[string]string{
+		"1.25.3":        "1.27.1",
+		"1.25.3-alpine": "1.27.1-alpine",
+		"1.25":          "1.27",
+		"v1.25.3":       "1.27.1",
+		"2.0.0":         "",
+		"latest":        "",
+	} {
+		if got := proposeTag(current, tags); got != want {
+			t.Errorf("proposeTag(%q) = %q, want %q", current, got, want)
+		}
+	}
+}
+
+func TestSplitImage(t *testing.T) {
+	host, repo, tag := splitImage("nginx:1.25")
+	if host != "registry-1.docker.io" || repo != "library/nginx" || tag != "1.25" {
+		t.Errorf("splitImage(nginx:1.25) = %s %s %s", host, repo, tag)
+	}
+	if _, _, tag := splitImage("ghcr.io/org/app@sha256:abc"); tag != "" {
+		t.Errorf("digest reference returned tag %q", tag)
+	}
+	if got := withTag("localhost:5000/app:1.0", "1.1"); got != "localhost:5000/app:1.1" {
+		t.Errorf("withTag = %s", got)
+	}
+}
+
+func TestRegistryListTags(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.URL.Query().Get("scope") != "repository:team/api:pull" {
+				http.Error(w, "bad scope", http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "secret"})
+		case r.Header.Get("Authorization") != "Bearer secret":
+			w.Header().Set("Www-Authenticate", `Bearer realm="`+srv.URL+`/token",service="registry",scope="repository:team/api:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Query().Get("last") == "":
+			w.Header().Set("Link", `</v2/team/api/tags/list?last=1.0.1&n=2>; rel="next"`)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"tags": []string{"1.0.0", "1.0.1"}})
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"tags": []string{"1.0.2"}})
+		}
+	}))
+	defer srv.Close()
+
+	host := strings.TrimPrefix(srv.URL, "http://")
+	rc := &registryClient{HTTP: srv.Client(), Scheme: "http"}
+	tags, err := rc.listTags(context.Background(), host+"/team/api:1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(tags, ",") != "1.0.0,1.0.1,1.0.2" {
+		t.Fatalf("tags = %v", tags)
+	}
+
+	bumps := planBumps(context.Background(), rc, []finding{
+		{Kind: kindVulnerability, ID: "CVE-1", Severity: "CRITICAL", Fixed: "2", Image: host + "/team/api:1.0.0", Namespace: "prod", Workload: "Deployment/api", Container: "api"},
+		{Kind: kindVulnerability, ID: "CVE-2", Severity: "LOW", Image: host + "/team/api:1.0.0", Namespace: "prod", Workload: "Deployment/api", Container: "api"},
+		{Kind: kindVulnerability, ID: "CVE-3", Severity: "HIGH", Fixed: "3", Image: host + "/team/web@sha256:abc", Namespace: "prod", Workload: "Deployment/web"},
+	})
+	if len(bumps) != 2 || bumps[0].Proposed != "1.0.2" || bumps[0].Fixable != 1 || len(bumps[0].Workloads) != 1 {
+		t.Fatalf("bumps = %+v", bumps)
+	}
+	if bumps[1].Proposed != "" || bumps[1].Note != "pinned by digest" {
+		t.Errorf("digest bump = %+v", bumps[1])
+	}
+}
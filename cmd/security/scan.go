@@ -1,79 +1,198 @@
 package security
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"time"
 
+	"adhar-io/adhar/cmd/helpers"
 	"adhar-io/adhar/platform/logger"
 
 	"github.com/spf13/cobra"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
 )
 
 var scanCmd = &cobra.Command{
 	Use:   "scan",
 	Short: "Run security scans",
-	Long: `Run comprehensive security scans on the platform.
-	
+	Long: `Report vulnerabilities, failed configuration checks and exposed secrets found
+by the Trivy Operator, aggregated by image, workload or namespace.
+
+Without --image or --namespace the whole cluster is reported. --rescan deletes
+the existing reports so the operator scans again, and waits (up to --timeout)
+for the new ones. An image that no workload runs is scanned with a one-off
+trivy Job in adhar-system.
+
+--fail-on makes the command exit non-zero when a finding at or above the given
+severity exists, for use as a CI gate; --output=sarif produces a log for code
+scanning dashboards.
+
 Examples:
   adhar security scan
-  adhar security scan --image=nginx:latest
-  adhar security scan --namespace=prod --policy=strict`,
+  adhar security scan --namespace=prod --group-by=image
+  adhar security scan --image=nginx:1.25 --severity=high
+  adhar security scan --namespace=prod --fail-on=critical --output=sarif > trivy.sarif
+  adhar security scan --namespace=prod --rescan --timeout=10m`,
 	RunE: runScan,
 }
 
-func runScan(cmd *cobra.Command, args []string) error {
-	logger.Info("🔍 Running security scan...")
-
-	if image != "" {
-		return scanImage(image)
-	}
-
-	if namespace != "" {
-		return scanNamespace(namespace)
-	}
+var (
+	scanFailOn  string
+	scanGroupBy string
+	scanRescan  bool
+)
 
-	return runComprehensiveScan()
+func init() {
+	scanCmd.Flags().StringVarP(&image, "image", "i", "", "Scan specific container image")
+	scanCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Scan specific namespace")
+	scanCmd.Flags().StringVarP(&output, "output", "o", "table", "Output format (table, json, yaml, sarif)")
+	scanCmd.Flags().StringVarP(&severity, "severity", "s", "", "Minimum severity to report (critical, high, medium, low)")
+	scanCmd.Flags().StringVarP(&timeout, "timeout", "t", "5m", "How long to wait for rescans and image scan jobs")
+	scanCmd.Flags().StringVar(&scanFailOn, "fail-on", "", "Exit non-zero if a finding at or above this severity exists")
+	scanCmd.Flags().StringVar(&scanGroupBy, "group-by", "", "Aggregate by image, workload or namespace (default depends on the scope)")
+	scanCmd.Flags().BoolVar(&scanRescan, "rescan", false, "Delete existing reports and wait for the operator to scan again")
 }
 
-func scanImage(imageName string) error {
-	logger.Info(fmt.Sprintf("🔍 Scanning image: %s", imageName))
+func runScan(cmd *cobra.Command, args []string) error {
+	minSeverity, err := parseSeverity(severity)
+	if err != nil {
+		return err
+	}
+	failOn, err := parseSeverity(scanFailOn)
+	if err != nil {
+		return fmt.Errorf("--fail-on: %w", err)
+	}
+	wait, err := time.ParseDuration(timeout)
+	if err != nil {
+		return fmt.Errorf("invalid --timeout %q: %w", timeout, err)
+	}
+	scope := scanScope{Namespace: namespace, Image: image}
+	label, groupBy := "cluster", "namespace"
+	switch {
+	case image != "":
+		label, groupBy = "image "+image, "workload"
+	case namespace != "":
+		label, groupBy = "namespace "+namespace, "workload"
+	}
+	if scanGroupBy != "" {
+		groupBy = scanGroupBy
+	}
+	if groupBy != "image" && groupBy != "workload" && groupBy != "namespace" {
+		return fmt.Errorf("--group-by must be image, workload or namespace")
+	}
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	table := output == "" || output == "table"
+	if table {
+		logger.Info("🔍 Scanning " + label + "...")
+	}
 
-	// TODO: Implement image security scanning
-	// This should:
-	// - Use Trivy or similar tool for image scanning
-	// - Check for known vulnerabilities
-	// - Check for malware
-	// - Check for secrets in images
-	// - Generate detailed report
+	findings, err := scanFindings(ctx, scope, wait, table)
+	if err != nil {
+		return err
+	}
 
-	logger.Info("✅ Image security scan completed")
+	report := newScanReport(label, groupBy, filterSeverity(findings, minSeverity))
+	if err := printScanReport(os.Stdout, report, output); err != nil {
+		return err
+	}
+	if failOn != "" {
+		if n := len(filterSeverity(findings, failOn)); n > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d finding(s) at or above %s severity", n, failOn)
+		}
+	}
 	return nil
 }
 
-func scanNamespace(namespaceName string) error {
-	logger.Info(fmt.Sprintf("🔍 Scanning namespace: %s", namespaceName))
-
-	// TODO: Implement namespace security scanning
-	// This should:
-	// - Check pod security policies
-	// - Check network policies
-	// - Check RBAC permissions
-	// - Check resource security settings
-
-	logger.Info("✅ Namespace security scan completed")
-	return nil
+// scanFindings returns the findings for scope from the operator's reports,
+// rescanning first with --rescan. Images without reports are scanned by a
+// trivy Job.
+func scanFindings(ctx context.Context, scope scanScope, wait time.Duration, verbose bool) ([]finding, error) {
+	dyn, err := trivyClient(ctx)
+	if err != nil {
+		if scope.Image != "" {
+			if verbose {
+				fmt.Fprintln(os.Stderr, helpers.CreateMuted("   "+err.Error()+"; scanning the image directly"))
+			}
+			return scanImageWithJob(ctx, scope.Image, wait)
+		}
+		return nil, err
+	}
+	if scanRescan {
+		if err := triggerRescan(ctx, dyn, scope, wait, verbose); err != nil {
+			return nil, err
+		}
+	}
+	if scope.Image != "" {
+		reports, err := listReports(ctx, dyn, vulnerabilityReportsGVR, scope)
+		if err != nil {
+			return nil, err
+		}
+		if len(reports) == 0 {
+			if verbose {
+				logger.Info("No workload runs " + scope.Image + "; scanning it with a trivy job...")
+			}
+			return scanImageWithJob(ctx, scope.Image, wait)
+		}
+	}
+	return collectFindings(ctx, dyn, scope)
 }
 
-func runComprehensiveScan() error {
-	logger.Info("🔍 Running comprehensive security scan...")
-
-	// TODO: Implement comprehensive security scan
-	// This should:
-	// - Scan all container images
-	// - Check cluster security posture
-	// - Verify security policies
-	// - Check compliance status
-	// - Generate security report
+// triggerRescan deletes the reports in scope and waits until the operator has
+// written them again.
+func triggerRescan(ctx context.Context, dyn dynamic.Interface, scope scanScope, wait time.Duration, verbose bool) error {
+	type ref struct {
+		gvr       schema.GroupVersionResource
+		namespace string
+		name      string
+	}
+	var deleted []ref
+	for _, gvr := range []schema.GroupVersionResource{vulnerabilityReportsGVR, exposedSecretReportsGVR, configAuditReportsGVR} {
+		reports, err := listReports(ctx, dyn, gvr, scope)
+		if err != nil {
+			return err
+		}
+		for _, r := range reports {
+			err := dyn.Resource(gvr).Namespace(r.GetNamespace()).Delete(ctx, r.GetName(), metav1.DeleteOptions{})
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return fmt.Errorf("delete %s %s/%s: %w", gvr.Resource, r.GetNamespace(), r.GetName(), err)
+			}
+			deleted = append(deleted, ref{gvr, r.GetNamespace(), r.GetName()})
+		}
+	}
+	if len(deleted) == 0 {
+		return nil
+	}
+	if verbose {
+		logger.Info(fmt.Sprintf("♻️  Deleted %d report(s); waiting up to %s for the Trivy Operator to rescan...", len(deleted), wait))
+	}
 
-	logger.Info("✅ Comprehensive security scan completed")
-	return nil
+	deadline := time.Now().Add(wait)
+	for {
+		missing := 0
+		for _, r := range deleted {
+			if _, err := dyn.Resource(r.gvr).Namespace(r.namespace).Get(ctx, r.name, metav1.GetOptions{}); err != nil {
+				missing++
+			}
+		}
+		if missing == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			fmt.Fprintln(os.Stderr, helpers.CreateWarning(fmt.Sprintf("%d report(s) were not regenerated within %s; results are partial", missing, wait)))
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
 }
@@ -37,11 +37,13 @@ This command provides:
 • Incident response tools
 
 Examples:
-  adhar security scan                    # Run comprehensive security scan
-  adhar security scan --image=nginx:latest # Scan specific image
-  adhar security scan --namespace=prod   # Scan production namespace
-  adhar security scan --policy=strict    # Use strict security policy
-  adhar security scan --auto-fix         # Auto-fix security issues`,
+  adhar security scan                          # Report findings across the cluster
+  adhar security scan --image=nginx:latest     # Scan specific image
+  adhar security scan --namespace=prod         # Scan production namespace
+  adhar security scan --fail-on=critical       # Fail CI on critical findings
+  adhar security vulnerabilities fix           # Propose image tag bumps
+  adhar security verify-images --key=ci.pub    # Verify deployed image signatures
+  adhar security compliance scan               # CIS/NSA/MITRE scan with trend history`,
 	RunE: runSecurity,
 }
 
@@ -62,7 +64,7 @@ func init() {
 	SecurityCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Scan specific namespace")
 	SecurityCmd.Flags().StringVarP(&policy, "policy", "p", "default", "Security policy (default, strict, custom)")
 	SecurityCmd.Flags().BoolVar(&autoFix, "auto-fix", false, "Automatically fix security issues")
-	SecurityCmd.Flags().StringVarP(&output, "output", "o", "", "Output format (table, json, yaml, html)")
+	SecurityCmd.Flags().StringVarP(&output, "output", "o", "", "Output format (table, json, yaml, sarif)")
 	SecurityCmd.Flags().StringVarP(&severity, "severity", "s", "", "Minimum severity level (low, medium, high, critical)")
 	SecurityCmd.Flags().StringVarP(&timeout, "timeout", "t", "5m", "Scan timeout")
 
@@ -71,6 +73,8 @@ func init() {
 	SecurityCmd.AddCommand(vulnerabilitiesCmd)
 	SecurityCmd.AddCommand(policiesCmd)
 	SecurityCmd.AddCommand(incidentsCmd)
+	SecurityCmd.AddCommand(verifyImagesCmd)
+	SecurityCmd.AddCommand(complianceCmd)
 }
 
 func runSecurity(cmd *cobra.Command, args []string) error {
@@ -80,6 +84,8 @@ func runSecurity(cmd *cobra.Command, args []string) error {
 	logger.Info("  vulnerabilities - Manage vulnerabilities")
 	logger.Info("  policies        - Manage security policies")
 	logger.Info("  incidents       - Handle security incidents")
+	logger.Info("  verify-images   - Verify image signatures and attestations")
+	logger.Info("  compliance      - CIS/NSA/MITRE compliance reports and trends")
 
 	return cmd.Help()
 }
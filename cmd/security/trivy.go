@@ -0,0 +1,523 @@
+package security
+
+// trivy.go reads the reports the Trivy Operator writes for every workload
+// (VulnerabilityReports, ConfigAuditReports and ExposedSecretReports) and
+// flattens them into findings that can be filtered, aggregated and rendered.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/globals"
+	"adhar-io/adhar/platform/k8s"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Report kinds written by the Trivy Operator (aquasecurity.github.io/v1alpha1).
+var (
+	vulnerabilityReportsGVR = schema.GroupVersionResource{Group: "aquasecurity.github.io", Version: "v1alpha1", Resource: "vulnerabilityreports"}
+	configAuditReportsGVR   = schema.GroupVersionResource{Group: "aquasecurity.github.io", Version: "v1alpha1", Resource: "configauditreports"}
+	exposedSecretReportsGVR = schema.GroupVersionResource{Group: "aquasecurity.github.io", Version: "v1alpha1", Resource: "exposedsecretreports"}
+)
+
+// Finding kinds.
+const (
+	kindVulnerability    = "vulnerability"
+	kindMisconfiguration = "misconfiguration"
+	kindSecret           = "secret"
+)
+
+// severities in descending order.
+var severities = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "UNKNOWN"}
+
+// severityRank orders severities; higher is worse.
+func severityRank(s string) int {
+	switch strings.ToUpper(s) {
+	case "CRITICAL":
+		return 4
+	case "HIGH":
+		return 3
+	case "MEDIUM":
+		return 2
+	case "LOW":
+		return 1
+	}
+	return 0
+}
+
+// parseSeverity validates a --severity or --fail-on value. Empty means no
+// threshold.
+func parseSeverity(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	up := strings.ToUpper(s)
+	for _, v := range severities {
+		if v == up {
+			return up, nil
+		}
+	}
+	return "", fmt.Errorf("invalid severity %q (critical, high, medium, low, unknown)", s)
+}
+
+// finding is one vulnerability, failed configuration check or exposed secret.
+type finding struct {
+	Kind      string `json:"kind"`
+	ID        string `json:"id"`
+	Severity  string `json:"severity"`
+	Title     string `json:"title,omitempty"`
+	Package   string `json:"package,omitempty"`
+	Installed string `json:"installedVersion,omitempty"`
+	Fixed     string `json:"fixedVersion,omitempty"`
+	Image     string `json:"image,omitempty"`
+	Namespace string `json:"namespace"`
+	Workload  string `json:"workload"`
+	Container string `json:"container,omitempty"`
+	Link      string `json:"link,omitempty"`
+}
+
+func (f finding) Fixable() bool { return f.Fixed != "" }
+
+// scanScope selects the reports to read.
+type scanScope struct {
+	Namespace string // empty for all namespaces
+	Image     string // only reports for this image
+}
+
+// trivyClient returns a dynamic client after checking that the Trivy
+// Operator CRDs are installed.
+func trivyClient(ctx context.Context) (dynamic.Interface, error) {
+	dyn, err := k8s.GetDynamicClient()
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the cluster (is it running? try `adhar up`): %w", err)
+	}
+	if _, err := dyn.Resource(vulnerabilityReportsGVR).List(ctx, metav1.ListOptions{Limit: 1}); err != nil {
+		if crdMissing(err) {
+			return nil, fmt.Errorf("trivy operator reports are not available; enable the trivy security package first")
+		}
+		return nil, fmt.Errorf("list vulnerability reports: %w", err)
+	}
+	return dyn, nil
+}
+
+func crdMissing(err error) bool {
+	return err != nil && (strings.Contains(err.Error(), "could not find") || strings.Contains(err.Error(), "the server could not find the requested resource") ||
+		strings.Contains(err.Error(), "no matches for kind"))
+}
+
+// listReports lists one report kind within scope.
+func listReports(ctx context.Context, dyn dynamic.Interface, gvr schema.GroupVersionResource, scope scanScope) ([]unstructured.Unstructured, error) {
+	list, err := dyn.Resource(gvr).Namespace(scope.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		if crdMissing(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("list %s: %w", gvr.Resource, err)
+	}
+	if scope.Image == "" {
+		return list.Items, nil
+	}
+	var out []unstructured.Unstructured
+	for _, r := range list.Items {
+		if sameImage(reportImage(r.Object), scope.Image) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// collectFindings reads every report kind in scope. Configuration audits are
+// per workload rather than per image, so they are skipped for image scans.
+func collectFindings(ctx context.Context, dyn dynamic.Interface, scope scanScope) ([]finding, error) {
+	var out []finding
+	kinds := []schema.GroupVersionResource{vulnerabilityReportsGVR, exposedSecretReportsGVR}
+	if scope.Image == "" {
+		kinds = append(kinds, configAuditReportsGVR)
+	}
+	for _, gvr := range kinds {
+		reports, err := listReports(ctx, dyn, gvr, scope)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range reports {
+			out = append(out, reportFindings(gvr, r.Object)...)
+		}
+	}
+	sortFindings(out)
+	return out, nil
+}
+
+// reportFindings flattens one report into findings.
+func reportFindings(gvr schema.GroupVersionResource, obj map[string]interface{}) []finding {
+	u := unstructured.Unstructured{Object: obj}
+	labels := u.GetLabels()
+	base := finding{
+		Namespace: u.GetNamespace(),
+		Workload:  workloadName(labels["trivy-operator.resource.kind"], labels["trivy-operator.resource.name"]),
+		Container: labels["trivy-operator.container.name"],
+		Image:     reportImage(obj),
+	}
+	if base.Namespace == "" {
+		base.Namespace = labels["trivy-operator.resource.namespace"]
+	}
+
+	var out []finding
+	switch gvr {
+	case vulnerabilityReportsGVR:
+		items, _, _ := unstructured.NestedSlice(obj, "report", "vulnerabilities")
+		for _, item := range items {
+			v, _ := item.(map[string]interface{})
+			f := base
+			f.Kind = kindVulnerability
+			f.ID = str(v, "vulnerabilityID")
+			f.Severity = strings.ToUpper(str(v, "severity"))
+			f.Title = str(v, "title")
+			f.Package = str(v, "resource")
+			f.Installed = str(v, "installedVersion")
+			f.Fixed = str(v, "fixedVersion")
+			f.Link = str(v, "primaryLink")
+			out = append(out, f)
+		}
+	case exposedSecretReportsGVR:
+		items, _, _ := unstructured.NestedSlice(obj, "report", "secrets")
+		for _, item := range items {
+			s, _ := item.(map[string]interface{})
+			f := base
+			f.Kind = kindSecret
+			f.ID = str(s, "ruleID")
+			f.Severity = strings.ToUpper(str(s, "severity"))
+			f.Title = str(s, "title")
+			f.Package = str(s, "target")
+			out = append(out, f)
+		}
+	case configAuditReportsGVR:
+		base.Image = ""
+		items, _, _ := unstructured.NestedSlice(obj, "report", "checks")
+		for _, item := range items {
+			c, _ := item.(map[string]interface{})
+			if ok, _, _ := unstructured.NestedBool(c, "success"); ok {
+				continue
+			}
+			f := base
+			f.Kind = kindMisconfiguration
+			f.ID = str(c, "checkID")
+			f.Severity = strings.ToUpper(str(c, "severity"))
+			f.Title = str(c, "title")
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// reportImage rebuilds the scanned image reference from a report.
+func reportImage(obj map[string]interface{}) string {
+	repo := str(obj, "report", "artifact", "repository")
+	if repo == "" {
+		return ""
+	}
+	ref := repo
+	if server := str(obj, "report", "registry", "server"); server != "" {
+		ref = server + "/" + repo
+	}
+	if tag := str(obj, "report", "artifact", "tag"); tag != "" {
+		ref += ":" + tag
+	} else if digest := str(obj, "report", "artifact", "digest"); digest != "" {
+		ref += "@" + digest
+	}
+	return ref
+}
+
+// normalizeImage expands Docker Hub shorthands so nginx, docker.io/nginx and
+// index.docker.io/library/nginx:latest compare equal.
+func normalizeImage(ref string) string {
+	name, tag := ref, ""
+	if i := strings.Index(ref, "@"); i >= 0 {
+		name, tag = ref[:i], ref[i:]
+	} else if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		name, tag = ref[:i], ref[i:]
+	} else {
+		tag = ":latest"
+	}
+	first, rest, found := strings.Cut(name, "/")
+	if !found || (!strings.ContainsAny(first, ".:") && first != "localhost") {
+		name = "docker.io/" + name
+		first, rest, _ = strings.Cut(name, "/")
+	}
+	if first == "index.docker.io" || first == "registry-1.docker.io" {
+		first = "docker.io"
+	}
+	if first == "docker.io" && !strings.Contains(rest, "/") {
+		rest = "library/" + rest
+	}
+	return first + "/" + rest + tag
+}
+
+func sameImage(a, b string) bool {
+	return a != "" && b != "" && normalizeImage(a) == normalizeImage(b)
+}
+
+// podTemplateHash matches the suffix the Deployment controller appends to
+// ReplicaSet names.
+var podTemplateHash = regexp.MustCompile(`-[a-z0-9]{8,10}$`)
+
+// workloadName reports ReplicaSets scanned on behalf of a Deployment as the
+// Deployment, which is what users act on.
+func workloadName(kind, name string) string {
+	if kind == "" {
+		return name
+	}
+	if kind == "ReplicaSet" && podTemplateHash.MatchString(name) {
+		return "Deployment/" + podTemplateHash.ReplaceAllString(name, "")
+	}
+	return kind + "/" + name
+}
+
+func sortFindings(fs []finding) {
+	sort.SliceStable(fs, func(i, j int) bool {
+		a, b := fs[i], fs[j]
+		if ra, rb := severityRank(a.Severity), severityRank(b.Severity); ra != rb {
+			return ra > rb
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Workload < b.Workload
+	})
+}
+
+// filterSeverity keeps findings at or above min.
+func filterSeverity(fs []finding, min string) []finding {
+	if min == "" {
+		return fs
+	}
+	var out []finding
+	for _, f := range fs {
+		if severityRank(f.Severity) >= severityRank(min) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// severityCounts tallies findings by severity.
+type severityCounts map[string]int
+
+func (c severityCounts) Total() int {
+	n := 0
+	for _, v := range c {
+		n += v
+	}
+	return n
+}
+
+// findingGroup aggregates findings by image, workload or namespace.
+type findingGroup struct {
+	Key      string         `json:"key"`
+	Counts   severityCounts `json:"counts"`
+	Fixable  int            `json:"fixable"`
+	Findings int            `json:"findings"`
+}
+
+func groupKey(f finding, by string) string {
+	switch by {
+	case "image":
+		if f.Image == "" {
+			return "(workload configuration)"
+		}
+		return f.Image
+	case "workload":
+		return f.Namespace + "/" + f.Workload
+	}
+	return f.Namespace
+}
+
+// aggregate groups findings by image, workload or namespace, worst first.
+func aggregate(fs []finding, by string) []findingGroup {
+	index := map[string]*findingGroup{}
+	var keys []string
+	for _, f := range fs {
+		k := groupKey(f, by)
+		g, ok := index[k]
+		if !ok {
+			g = &findingGroup{Key: k, Counts: severityCounts{}}
+			index[k] = g
+			keys = append(keys, k)
+		}
+		g.Counts[f.Severity]++
+		g.Findings++
+		if f.Fixable() {
+			g.Fixable++
+		}
+	}
+	out := make([]findingGroup, 0, len(keys))
+	for _, k := range keys {
+		out = append(out, *index[k])
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		for _, s := range severities {
+			if out[i].Counts[s] != out[j].Counts[s] {
+				return out[i].Counts[s] > out[j].Counts[s]
+			}
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// scanReport is the JSON/YAML form of a scan.
+type scanReport struct {
+	Scope    string         `json:"scope"`
+	GroupBy  string         `json:"groupBy"`
+	Summary  severityCounts `json:"summary"`
+	Groups   []findingGroup `json:"groups"`
+	Findings []finding      `json:"findings"`
+}
+
+func newScanReport(scope, by string, fs []finding) scanReport {
+	summary := severityCounts{}
+	for _, f := range fs {
+		summary[f.Severity]++
+	}
+	if fs == nil {
+		fs = []finding{}
+	}
+	return scanReport{Scope: scope, GroupBy: by, Summary: summary, Groups: aggregate(fs, by), Findings: fs}
+}
+
+// maxTableFindings caps the findings listed in table output; JSON, YAML and
+// SARIF always contain everything.
+const maxTableFindings = 50
+
+// printScanReport renders a report as table, json, yaml or sarif.
+func printScanReport(w io.Writer, r scanReport, format string) error {
+	switch format {
+	case "json":
+		return helpers.PrintJSON(r)
+	case "yaml":
+		return helpers.PrintYAML(r)
+	case "sarif":
+		return writeFindingsSARIF(w, r.Findings)
+	case "", "table":
+	default:
+		return fmt.Errorf("unsupported output format %q (table, json, yaml, sarif)", format)
+	}
+
+	if len(r.Findings) == 0 {
+		fmt.Fprintln(w, helpers.CreateSuccess("No findings for "+r.Scope))
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tCRITICAL\tHIGH\tMEDIUM\tLOW\tUNKNOWN\tFIXABLE\n", strings.ToUpper(r.GroupBy))
+	for _, g := range r.Groups {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\n", g.Key, g.Counts["CRITICAL"], g.Counts["HIGH"],
+			g.Counts["MEDIUM"], g.Counts["LOW"], g.Counts["UNKNOWN"], g.Fixable)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEVERITY\tID\tKIND\tTARGET\tWORKLOAD\tFIXED IN")
+	for i, f := range r.Findings {
+		if i == maxTableFindings {
+			break
+		}
+		target := f.Package
+		if f.Installed != "" {
+			target += "@" + f.Installed
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s/%s\t%s\n", f.Severity, f.ID, f.Kind, truncate(dash(target), 40),
+			f.Namespace, f.Workload, dash(f.Fixed))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if n := len(r.Findings) - maxTableFindings; n > 0 {
+		fmt.Fprintln(w, helpers.CreateMuted(fmt.Sprintf("… and %d more; use --output=json for the full list", n)))
+	}
+	fmt.Fprintf(w, "\nTotal: %d critical, %d high, %d medium, %d low, %d unknown\n", r.Summary["CRITICAL"],
+		r.Summary["HIGH"], r.Summary["MEDIUM"], r.Summary["LOW"], r.Summary["UNKNOWN"])
+	return nil
+}
+
+// writeFindingsSARIF renders findings as SARIF. Cluster objects have no file,
+// so locations use k8s://namespace/Kind/name URIs.
+func writeFindingsSARIF(w io.Writer, fs []finding) error {
+	var rules []helpers.SARIFRule
+	seen := map[string]bool{}
+	var results []helpers.SARIFResult
+	for _, f := range fs {
+		if !seen[f.ID] {
+			seen[f.ID] = true
+			rules = append(rules, helpers.SARIFRule{ID: f.ID, Name: f.Kind, Description: f.Title, HelpURI: f.Link})
+		}
+		msg := f.Title
+		if f.Package != "" {
+			msg = fmt.Sprintf("%s in %s %s", valueOr(f.Title, f.ID), f.Package, f.Installed)
+		}
+		if f.Fixed != "" {
+			msg += " (fixed in " + f.Fixed + ")"
+		}
+		if f.Image != "" {
+			msg += " [" + f.Image + "]"
+		}
+		results = append(results, helpers.SARIFResult{
+			RuleID:  f.ID,
+			Level:   sarifLevel(f.Severity),
+			Message: msg,
+			URI:     "k8s://" + f.Namespace + "/" + f.Workload,
+		})
+	}
+	return helpers.WriteSARIF(w, "adhar-security", globals.Version, "https://aquasecurity.github.io/trivy-operator", rules, results)
+}
+
+func sarifLevel(severity string) string {
+	switch severityRank(severity) {
+	case 4, 3:
+		return "error"
+	case 2:
+		return "warning"
+	}
+	return "note"
+}
+
+// str returns a nested string field, or "".
+func str(obj map[string]interface{}, fields ...string) string {
+	s, _, _ := unstructured.NestedString(obj, fields...)
+	return s
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func valueOr(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-1] + "…"
+}
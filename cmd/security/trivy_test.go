@@ -0,0 +1,145 @@
+package security
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func vulnerabilityReport() map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":      "replicaset-web-7d9f8b6c5d-nginx",
+			"namespace": "prod",
+			"labels": map[string]interface{}{
+				"trivy-operator.resource.kind":  "ReplicaSet",
+				"trivy-operator.resource.name":  "web-7d9f8b6c5d",
+				"trivy-operator.container.name": "nginx",
+			},
+		},
+		"report": map[string]interface{}{
+			"registry": map[string]interface{}{"server": "index.docker.io"},
+			"artifact": map[string]interface{}{"repository": "library/nginx", "tag": "1.25.3"},
+			"vulnerabilities": []interface{}{
+				map[string]interface{}{"vulnerabilityID": "CVE-2024-0001", "severity": "HIGH", "resource": "openssl",
+					"installedVersion": "3.0.1", "fixedVersion": "3.0.2"},
+				map[string]interface{}{"vulnerabilityID": "CVE-2024-0002", "severity": "CRITICAL", "resource": "zlib",
+					"installedVersion": "1.2.11"},
+			},
+		},
+	}
+}
+
+func TestReportFindings(t *testing.T) {
+	fs := reportFindings(vulnerabilityReportsGVR, vulnerabilityReport())
+	sortFindings(fs)
+	if len(fs) != 2 {
+		t.Fatalf("findings = %+v", fs)
+	}
+	if fs[0].ID != "CVE-2024-0002" || fs[0].Fixable() || !fs[1].Fixable() {
+		t.Errorf("findings not sorted worst first: %+v", fs)
+	}
+	if fs[0].Workload != "Deployment/web" || fs[0].Container != "nginx" || fs[0].Namespace != "prod" {
+		t.Errorf("workload = %s/%s container %s", fs[0].Namespace, fs[0].Workload, fs[0].Container)
+	}
+	if !sameImage(fs[0].Image, "nginx:1.25.3") {
+		t.Errorf("image %s should match nginx:1.25.3", fs[0].Image)
+	}
+
+	audit := map[string]interface{}{
+		"metadata": map[string]interface{}{"namespace": "prod", "labels": map[string]interface{}{
+			"trivy-operator.resource.kind": "StatefulSet", "trivy-operator.resource.name": "db"}},
+		"report": map[string]interface{}{"checks": []interface{}{
+			map[string]interface{}{"checkID": "KSV001", "severity": "MEDIUM", "success": false},
+			map[string]interface{}{"checkID": "KSV002", "severity": "HIGH", "success": true},
+		}},
+	}
+	if got := reportFindings(configAuditReportsGVR, audit); len(got) != 1 || got[0].ID != "KSV001" || got[0].Workload != "StatefulSet/db" {
+		t.Errorf("config audit findings = %+v", got)
+	}
+}
+
+func TestNormalizeImage(t *testing.T) {
+	for in, want := range map[string]string{
+		"nginx":                            "docker.io/library/nginx:latest",
+		"nginx:1.25":                       "docker.io/library/nginx:1.25",
+		"index.docker.io/library/nginx:1":  "docker.io/library/nginx:1",
+		"bitnami/redis:7":                  "docker.io/bitnami/redis:7",
+		"ghcr.io/org/app@sha256:abc":       "ghcr.io/org/app@sha256:abc",
+		"localhost:5000/app":               "localhost:5000/app:latest",
+		"harbor.example.com:8443/team/api": "harbor.example.com:8443/team/api:latest",
+	} {
+		if got := normalizeImage(in); got != want {
+			t.Errorf("normalizeImage(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := workloadName("ReplicaSet", "manual"); got != "ReplicaSet/manual" {
+		t.Errorf("ReplicaSet without a hash = %s", got)
+	}
+}
+
+func TestAggregateAndThresholds(t *testing.T) {
+	fs := []finding{
+		{ID: "a", Severity: "LOW", Namespace: "dev", Workload: "Deployment/x", Image: "x:1"},
+		{ID: "b", Severity: "CRITICAL", Namespace: "prod", Workload: "Deployment/y", Image: "y:1", Fixed: "2"},
+		{ID: "c", Severity: "HIGH", Namespace: "prod", Workload: "Deployment/z", Image: "y:1"},
+	}
+	groups := aggregate(fs, "namespace")
+	if len(groups) != 2 || groups[0].Key != "prod" || groups[0].Findings != 2 || groups[0].Fixable != 1 {
+		t.Fatalf("groups = %+v", groups)
+	}
+	if got := aggregate(fs, "image"); got[0].Key != "y:1" || got[0].Counts.Total() != 2 {
+		t.Errorf("image groups = %+v", got)
+	}
+	if got := filterSeverity(fs, "HIGH"); len(got) != 2 {
+		t.Errorf("filterSeverity(HIGH) kept %d findings", len(got))
+	}
+	if _, err := parseSeverity("severe"); err == nil {
+		t.Errorf("invalid severity accepted")
+	}
+}
+
+func TestParseTrivyJSON(t *testing.T) {
+	data := []byte(`2024-01-01T00:00:00Z INFO noise
+{"Results":[{"Target":"app","Vulnerabilities":[{"VulnerabilityID":"CVE-1","PkgName":"musl","InstalledVersion":"1.2.3","FixedVersion":"1.2.4","Severity":"high"}],
+"Secrets":[{"RuleID":"aws-access-key-id","Severity":"CRITICAL","Title":"AWS Access Key ID"}]}]}`)
+	fs, err := parseTrivyJSON(data, "app:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fs) != 2 || fs[0].Kind != kindSecret || fs[1].Severity != "HIGH" || fs[1].Image != "app:1" {
+		t.Errorf("findings = %+v", fs)
+	}
+}
+
+func TestWriteFindingsSARIF(t *testing.T) {
+	fs := reportFindings(vulnerabilityReportsGVR, vulnerabilityReport())
+	var buf bytes.Buffer
+	if err := printScanReport(&buf, newScanReport("namespace prod", "workload", fs), "sarif"); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Runs []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID string `json:"ruleId"`
+				Level  string `json:"level"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v\n%s", err, buf.String())
+	}
+	if len(log.Runs) != 1 || len(log.Runs[0].Results) != 2 || len(log.Runs[0].Tool.Driver.Rules) != 2 {
+		t.Fatalf("SARIF = %s", buf.String())
+	}
+	if log.Runs[0].Results[0].Level != "error" {
+		t.Errorf("critical finding level = %s", log.Runs[0].Results[0].Level)
+	}
+}
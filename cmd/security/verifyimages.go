@@ -0,0 +1,550 @@
+package security
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/globals"
+	"adhar-io/adhar/platform/k8s"
+	"adhar-io/adhar/platform/logger"
+
+	"github.com/sigstore/sigstore-go/pkg/root"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+var verifyImagesCmd = &cobra.Command{
+	Use:   "verify-images",
+	Short: "Verify cosign signatures and attestations of deployed images",
+	Long: `Verify the cosign signatures and attestations (SBOM, SLSA provenance) of the
+images running in the cluster, or of the images an ArgoCD application renders.
+
+Signatures are trusted when made with one of the --key public keys, or keyless
+by a Fulcio certificate whose identity and OIDC issuer match
+--certificate-identity (or --certificate-identity-regexp) and
+--certificate-oidc-issuer. A keyless signature must also carry a Rekor bundle:
+its signed entry timestamp is verified against the Rekor keys, and the
+certificate must chain to a Fulcio CA that was valid when the entry was logged.
+Both come from the Sigstore public-good trusted root, fetched over TUF, unless
+--trusted-root names the trusted_root.json of a private Sigstore deployment.
+
+Each image is reported as verified, unsigned, untrusted (signed, but not by a
+trusted signer), incomplete (a required --attestation is missing) or error.
+System namespaces are skipped unless --all-namespaces or --namespace is set.
+
+--policy-out writes a Kyverno verifyImages ClusterPolicy for the repositories
+of the verified images, with the same signers and attestations, in Audit mode
+unless --enforce.
+
+Examples:
+  adhar security verify-images --key cosign.pub
+  adhar security verify-images -n prod --key cosign.pub --attestation sbom,slsa --fail-on-unsigned
+  adhar security verify-images --app payments \
+    --certificate-identity-regexp 'https://github.com/acme/.*' \
+    --certificate-oidc-issuer https://token.actions.githubusercontent.com
+  adhar security verify-images -n prod --key cosign.pub --policy-out verify-prod.yaml`,
+	RunE: runVerifyImages,
+}
+
+var (
+	verifyAllNamespaces  bool
+	verifyApp            string
+	verifyKeys           []string
+	verifyIdentity       string
+	verifyIdentityRegexp string
+	verifyIssuer         string
+	verifyTrustedRoot    string
+	verifyAttestations   []string
+	verifyFailOnUnsigned bool
+	verifyPlainHTTP      bool
+	verifyPolicyOut      string
+	verifyEnforce        bool
+)
+
+func init() {
+	verifyImagesCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Only images running in this namespace")
+	verifyImagesCmd.Flags().BoolVarP(&verifyAllNamespaces, "all-namespaces", "A", false, "Include system namespaces")
+	verifyImagesCmd.Flags().StringVar(&verifyApp, "app", "", "Verify the images of this ArgoCD application instead of running pods")
+	verifyImagesCmd.Flags().StringArrayVar(&verifyKeys, "key", nil, "Trusted cosign public key (PEM file, repeatable)")
+	verifyImagesCmd.Flags().StringVar(&verifyIdentity, "certificate-identity", "", "Trusted keyless signer identity (email or URI)")
+	verifyImagesCmd.Flags().StringVar(&verifyIdentityRegexp, "certificate-identity-regexp", "", "Regular expression for trusted keyless signer identities")
+	verifyImagesCmd.Flags().StringVar(&verifyIssuer, "certificate-oidc-issuer", "", "OIDC issuer keyless certificates must name")
+	verifyImagesCmd.Flags().StringVar(&verifyTrustedRoot, "trusted-root", "", "Sigstore trusted_root.json with the Fulcio CAs and Rekor keys for keyless signatures (default: public-good Sigstore)")
+	verifyImagesCmd.Flags().StringSliceVar(&verifyAttestations, "attestation", nil, "Required attestations (sbom, slsa, vuln or a predicate type URI)")
+	verifyImagesCmd.Flags().BoolVar(&verifyFailOnUnsigned, "fail-on-unsigned", false, "Exit non-zero unless every image is verified")
+	verifyImagesCmd.Flags().BoolVar(&verifyPlainHTTP, "plain-http", false, "Talk to registries over HTTP (local registries)")
+	verifyImagesCmd.Flags().StringVar(&verifyPolicyOut, "policy-out", "", "Write a Kyverno verifyImages policy for the verified images to this file (- for stdout)")
+	verifyImagesCmd.Flags().BoolVar(&verifyEnforce, "enforce", false, "Generate the policy in Enforce instead of Audit mode")
+	verifyImagesCmd.Flags().StringVarP(&output, "output", "o", "table", "Output format (table, json, yaml)")
+	verifyImagesCmd.Flags().StringVarP(&timeout, "timeout", "t", "5m", "Overall timeout")
+}
+
+func runVerifyImages(cmd *cobra.Command, args []string) error {
+	trust, err := buildTrustPolicy()
+	if err != nil {
+		return err
+	}
+	wait, err := time.ParseDuration(timeout)
+	if err != nil {
+		return fmt.Errorf("invalid --timeout %q: %w", timeout, err)
+	}
+	if output != "" && output != "table" && output != "json" && output != "yaml" {
+		return fmt.Errorf("unsupported output format %q (table, json, yaml)", output)
+	}
+	ctx, cancel := context.WithTimeout(commandContext(cmd), wait)
+	defer cancel()
+
+	rc := newRegistryClient()
+	if verifyPlainHTTP {
+		rc.Scheme = "http"
+	}
+	table := output == "" || output == "table"
+
+	var images []deployedImage
+	if verifyApp != "" {
+		if table {
+			logger.Info("🔏 Verifying images of application " + verifyApp + "...")
+		}
+		dyn, err := k8s.GetDynamicClient()
+		if err != nil {
+			return fmt.Errorf("could not connect to the cluster (is it running? try `adhar up`): %w", err)
+		}
+		images, err = applicationImages(ctx, dyn, verifyApp)
+		if err != nil {
+			return err
+		}
+	} else {
+		if table {
+			logger.Info("🔏 Verifying images of running pods...")
+		}
+		cs, err := incidentClient()
+		if err != nil {
+			return err
+		}
+		images, err = podImages(ctx, cs, namespace, verifyAllNamespaces)
+		if err != nil {
+			return err
+		}
+	}
+
+	v := &cosignVerifier{registry: rc, policy: trust}
+	results := verifyDeployedImages(ctx, v, images)
+
+	if verifyPolicyOut != "" {
+		if err := writeVerifyPolicy(results, trust); err != nil {
+			return err
+		}
+	}
+	if verifyPolicyOut != "-" {
+		if err := printVerifications(os.Stdout, results, output); err != nil {
+			return err
+		}
+	}
+	if verifyFailOnUnsigned {
+		if n := len(results) - countStatus(results, "verified"); n > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d image(s) not verified", n)
+		}
+	}
+	return nil
+}
+
+// buildTrustPolicy assembles the trust policy from the flags.
+func buildTrustPolicy() (*trustPolicy, error) {
+	trust := &trustPolicy{Identity: verifyIdentity, Issuer: verifyIssuer}
+	for _, path := range verifyKeys {
+		key, err := loadPublicKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("--key: %w", err)
+		}
+		trust.Keys = append(trust.Keys, key)
+	}
+	if verifyIdentityRegexp != "" {
+		re, err := regexp.Compile(verifyIdentityRegexp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --certificate-identity-regexp: %w", err)
+		}
+		trust.IdentityRegexp = re
+	}
+	if len(trust.Keys) == 0 && !trust.keyless() {
+		return nil, fmt.Errorf("specify --key or --certificate-identity(-regexp) to say whose signatures to trust")
+	}
+	if trust.keyless() && trust.Issuer == "" {
+		return nil, fmt.Errorf("--certificate-oidc-issuer is required with keyless verification")
+	}
+	if trust.keyless() {
+		var err error
+		if verifyTrustedRoot != "" {
+			trust.Trusted, err = root.NewTrustedRootFromPath(verifyTrustedRoot)
+			if err != nil {
+				return nil, fmt.Errorf("--trusted-root: %w", err)
+			}
+		} else if trust.Trusted, err = root.FetchTrustedRoot(); err != nil {
+			return nil, fmt.Errorf("failed to fetch the Sigstore trusted root (use --trusted-root): %w", err)
+		}
+	}
+	for _, a := range verifyAttestations {
+		a = strings.TrimSpace(a)
+		if _, ok := attestationTypes[a]; !ok && !strings.Contains(a, "://") {
+			return nil, fmt.Errorf("unknown attestation %q (sbom, slsa, vuln or a predicate type URI)", a)
+		}
+		trust.Attestations = append(trust.Attestations, a)
+	}
+	return trust, nil
+}
+
+// deployedImage is an image at a digest and the workloads running it.
+type deployedImage struct {
+	Image     string
+	Digest    string
+	Workloads []string
+}
+
+// podImages collects the images of running pods, keyed by the digest the
+// kubelet resolved (containerStatuses imageID).
+func podImages(ctx context.Context, cs kubernetes.Interface, ns string, all bool) ([]deployedImage, error) {
+	pods, err := cs.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+	byKey := map[string]*deployedImage{}
+	var order []string
+	for _, p := range pods.Items {
+		if ns == "" && !all && containsString(systemNamespaces, p.Namespace) {
+			continue
+		}
+		workload := p.Namespace + "/" + podWorkload(&p)
+		statuses := append(append([]corev1.ContainerStatus{}, p.Status.InitContainerStatuses...), p.Status.ContainerStatuses...)
+		for _, c := range statuses {
+			ref, digest := imageDigest(c.Image, c.ImageID)
+			if specImage := containerImage(&p, c.Name); specImage != "" {
+				ref = specImage
+			}
+			key := imageRepo(ref) + "@" + digest
+			if digest == "" {
+				key = ref
+			}
+			d := byKey[key]
+			if d == nil {
+				d = &deployedImage{Image: ref, Digest: digest}
+				byKey[key] = d
+				order = append(order, key)
+			}
+			if !containsString(d.Workloads, workload) {
+				d.Workloads = append(d.Workloads, workload)
+			}
+		}
+	}
+	out := make([]deployedImage, 0, len(order))
+	for _, k := range order {
+		out = append(out, *byKey[k])
+	}
+	return out, nil
+}
+
+func podWorkload(p *corev1.Pod) string {
+	for _, ref := range p.OwnerReferences {
+		if ref.Controller != nil && *ref.Controller {
+			return workloadName(ref.Kind, ref.Name)
+		}
+	}
+	return "Pod/" + p.Name
+}
+
+func containerImage(p *corev1.Pod, name string) string {
+	for _, c := range append(append([]corev1.Container{}, p.Spec.InitContainers...), p.Spec.Containers...) {
+		if c.Name == name {
+			return c.Image
+		}
+	}
+	return ""
+}
+
+// imageDigest returns the image and its manifest digest from a container
+// status. An imageID without a repository (images loaded straight into the
+// node) names a local config digest, not a registry manifest, so it is
+// ignored.
+func imageDigest(image, imageID string) (string, string) {
+	id := strings.TrimPrefix(imageID, "docker-pullable://")
+	if _, digest, ok := strings.Cut(id, "@"); ok {
+		return image, digest
+	}
+	if _, digest, ok := strings.Cut(image, "@"); ok {
+		return image, digest
+	}
+	return image, ""
+}
+
+// imageRepo strips the tag and digest from a reference.
+func imageRepo(ref string) string {
+	host, repo, _ := splitImage(ref)
+	if host == "registry-1.docker.io" {
+		host = "docker.io"
+	}
+	return host + "/" + repo
+}
+
+var applicationGVR = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+
+// applicationImages reads the images ArgoCD rendered for an application
+// (status.summary.images). Digests are resolved from the registry.
+func applicationImages(ctx context.Context, dyn dynamic.Interface, name string) ([]deployedImage, error) {
+	app, err := dyn.Resource(applicationGVR).Namespace(globals.AdharSystemNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get application %s: %w", name, err)
+	}
+	images, _, _ := unstructured.NestedStringSlice(app.Object, "status", "summary", "images")
+	if len(images) == 0 {
+		return nil, fmt.Errorf("application %s reports no images (has it synced?)", name)
+	}
+	out := make([]deployedImage, 0, len(images))
+	for _, img := range images {
+		_, digest := imageDigest(img, "")
+		out = append(out, deployedImage{Image: img, Digest: digest, Workloads: []string{"Application/" + name}})
+	}
+	return out, nil
+}
+
+// verifyDeployedImages verifies each image, resolving tags to digests first.
+func verifyDeployedImages(ctx context.Context, v *cosignVerifier, images []deployedImage) []imageVerification {
+	results := make([]imageVerification, 0, len(images))
+	for _, img := range images {
+		digest := img.Digest
+		if digest == "" {
+			d, err := v.registry.resolveDigest(ctx, img.Image)
+			if err != nil {
+				results = append(results, imageVerification{Image: img.Image, Workloads: img.Workloads, Status: "error",
+					Problems: []string{err.Error()}})
+				continue
+			}
+			digest = d
+		}
+		res := v.verify(ctx, img.Image, digest)
+		res.Workloads = img.Workloads
+		results = append(results, res)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if statusRank(results[i].Status) != statusRank(results[j].Status) {
+			return statusRank(results[i].Status) < statusRank(results[j].Status)
+		}
+		return results[i].Image < results[j].Image
+	})
+	return results
+}
+
+// statusRank lists the worst results first.
+func statusRank(status string) int {
+	switch status {
+	case "unsigned":
+		return 0
+	case "untrusted":
+		return 1
+	case "incomplete":
+		return 2
+	case "error":
+		return 3
+	}
+	return 4
+}
+
+func countStatus(results []imageVerification, status string) int {
+	n := 0
+	for _, r := range results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func printVerifications(w io.Writer, results []imageVerification, format string) error {
+	switch format {
+	case "json":
+		return helpers.PrintJSON(results)
+	case "yaml":
+		return helpers.PrintYAML(results)
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(w, helpers.CreateMuted("No images found"))
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tIMAGE\tSIGNED BY\tATTESTATIONS\tWORKLOADS")
+	for _, r := range results {
+		attestations := strings.Join(r.Attestations, ",")
+		if len(r.Missing) > 0 {
+			attestations = strings.TrimPrefix(attestations+",missing:"+strings.Join(r.Missing, "+"), ",")
+		}
+		workloads := ""
+		if len(r.Workloads) > 0 {
+			workloads = r.Workloads[0]
+			if len(r.Workloads) > 1 {
+				workloads += fmt.Sprintf(" (+%d)", len(r.Workloads)-1)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Status, truncate(r.Image, 60), truncate(dash(strings.Join(r.Signers, ", ")), 50),
+			dash(attestations), dash(workloads))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, r := range results {
+		for _, p := range r.Problems {
+			fmt.Fprintln(w, helpers.CreateMuted("   "+r.Image+": "+p))
+		}
+	}
+	verified := countStatus(results, "verified")
+	summary := fmt.Sprintf("\n%d of %d image(s) verified, %d unsigned, %d untrusted, %d incomplete, %d error(s)", verified, len(results),
+		countStatus(results, "unsigned"), countStatus(results, "untrusted"), countStatus(results, "incomplete"), countStatus(results, "error"))
+	if verified == len(results) {
+		fmt.Fprintln(w, helpers.CreateSuccess(strings.TrimPrefix(summary, "\n")))
+	} else {
+		fmt.Fprintln(w, summary)
+	}
+	return nil
+}
+
+// writeVerifyPolicy writes the generated Kyverno policy to --policy-out.
+func writeVerifyPolicy(results []imageVerification, trust *trustPolicy) error {
+	policy, err := kyvernoVerifyPolicy(results, trust, verifyEnforce)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	if verifyPolicyOut == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(verifyPolicyOut, data, 0o644); err != nil {
+		return fmt.Errorf("write policy: %w", err)
+	}
+	fmt.Fprintln(os.Stderr, helpers.CreateSuccess("Kyverno policy written to "+verifyPolicyOut))
+	return nil
+}
+
+// kyvernoVerifyPolicy builds a ClusterPolicy requiring the repositories of the
+// verified images to be signed, and attested, the way they are now. Like the
+// supply-chain policies the platform ships it excludes system namespaces and
+// fails open on webhook errors.
+func kyvernoVerifyPolicy(results []imageVerification, trust *trustPolicy, enforce bool) (map[string]interface{}, error) {
+	var repos, predicates []string
+	for _, r := range results {
+		if r.Status != "verified" {
+			continue
+		}
+		// repo:* and repo@* rather than repo*, which would also match
+		// sibling repositories such as repo-debug.
+		repo := imageRepo(r.Image)
+		for _, ref := range []string{repo + ":*", repo + "@*"} {
+			if !containsString(repos, ref) {
+				repos = append(repos, ref)
+			}
+		}
+		for _, p := range r.predicates {
+			required := containsString(trust.Attestations, p) || containsString(trust.Attestations, attestationName(p))
+			if required && !containsString(predicates, p) {
+				predicates = append(predicates, p)
+			}
+		}
+	}
+	if len(repos) == 0 {
+		return nil, fmt.Errorf("no verified images to generate a policy for")
+	}
+	sort.Strings(repos)
+	sort.Strings(predicates)
+
+	attestors := []interface{}{map[string]interface{}{"entries": attestorEntries(trust)}}
+	verify := map[string]interface{}{
+		"imageReferences": repos,
+		"attestors":       attestors,
+		"mutateDigest":    true,
+		"verifyDigest":    true,
+		"required":        true,
+	}
+	if len(predicates) > 0 {
+		var attestations []interface{}
+		for _, p := range predicates {
+			attestations = append(attestations, map[string]interface{}{"type": p, "attestors": attestors})
+		}
+		verify["attestations"] = attestations
+	}
+	action := "Audit"
+	if enforce {
+		action = "Enforce"
+	}
+	return map[string]interface{}{
+		"apiVersion": "kyverno.io/v1",
+		"kind":       "ClusterPolicy",
+		"metadata": map[string]interface{}{
+			"name": "verify-deployed-images",
+			"annotations": map[string]interface{}{
+				"policies.kyverno.io/title":       "Verify Deployed Images",
+				"policies.kyverno.io/category":    "Supply Chain (ADR-0019)",
+				"policies.kyverno.io/description": "Generated by adhar security verify-images from the images verified on " + time.Now().UTC().Format("2006-01-02") + ".",
+			},
+		},
+		"spec": map[string]interface{}{
+			"validationFailureAction": action,
+			"background":              false,
+			"webhookTimeoutSeconds":   15,
+			"failurePolicy":           "Ignore",
+			"rules": []interface{}{map[string]interface{}{
+				"name":         "verify-signatures",
+				"match":        map[string]interface{}{"any": []interface{}{map[string]interface{}{"resources": map[string]interface{}{"kinds": []string{"Pod"}}}}},
+				"exclude":      map[string]interface{}{"any": []interface{}{map[string]interface{}{"resources": map[string]interface{}{"namespaces": systemNamespaces}}}},
+				"verifyImages": []interface{}{verify},
+			}},
+		},
+	}, nil
+}
+
+func attestorEntries(trust *trustPolicy) []interface{} {
+	var entries []interface{}
+	for _, k := range trust.Keys {
+		entries = append(entries, map[string]interface{}{"keys": map[string]interface{}{"publicKeys": k.PEM}})
+	}
+	if trust.keyless() {
+		keyless := map[string]interface{}{"issuer": trust.Issuer, "rekor": map[string]interface{}{"url": rekorURL(trust.Trusted)}}
+		if trust.Identity != "" {
+			keyless["subject"] = trust.Identity
+		} else {
+			keyless["subjectRegExp"] = trust.IdentityRegexp.String()
+		}
+		entries = append(entries, map[string]interface{}{"keyless": keyless})
+	}
+	return entries
+}
+
+// rekorURL is the Rekor log of the trusted root, so policies generated with
+// --trusted-root point at the same private Sigstore deployment. When the root
+// lists several logs the most recently started one wins.
+func rekorURL(trusted root.TrustedMaterial) string {
+	url := "https://rekor.sigstore.dev"
+	if trusted == nil {
+		return url
+	}
+	var latest time.Time
+	for _, log := range trusted.RekorLogs() {
+		if log.BaseURL != "" && !log.ValidityPeriodStart.Before(latest) {
+			url, latest = log.BaseURL, log.ValidityPeriodStart
+		}
+	}
+	return url
+}
@@ -1,6 +1,15 @@
 package security
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"adhar-io/adhar/cmd/helpers"
 	"adhar-io/adhar/platform/logger"
 
 	"github.com/spf13/cobra"
@@ -9,77 +18,292 @@ import (
 var vulnerabilitiesCmd = &cobra.Command{
 	Use:   "vulnerabilities",
 	Short: "Manage security vulnerabilities",
-	Long: `Manage and track security vulnerabilities in the platform.
-	
+	Long: `List the vulnerabilities the Trivy Operator found in running images and
+propose image tag bumps that fix them.
+
 Examples:
   adhar security vulnerabilities list
-  adhar security vulnerabilities fix --id=CVE-2024-1234
-  adhar security vulnerabilities report`,
+  adhar security vulnerabilities list --namespace=prod --severity=high --fixable
+  adhar security vulnerabilities list --id=CVE-2024-1234
+  adhar security vulnerabilities fix --namespace=prod
+  adhar security vulnerabilities fix --id=CVE-2024-1234 --output=json`,
 	RunE: runVulnerabilities,
 }
 
+var vulnListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List vulnerabilities by CVE",
+	RunE:  runVulnList,
+}
+
+var vulnFixCmd = &cobra.Command{
+	Use:   "fix",
+	Short: "Propose image tag bumps for fixable vulnerabilities",
+	Long: `Group fixable vulnerabilities by image and look up a newer tag of each image
+in its registry: the newest release in the same major version and variant
+(e.g. 1.25.3-alpine moves to 1.25.5-alpine or 1.27.1-alpine, never to 2.x or
+to a Debian build). Nothing is changed in the cluster; the printed kubectl
+commands or a GitOps change apply the bump, and a rescan confirms it.`,
+	RunE: runVulnFix,
+}
+
 var (
-	vulnID string
-	fixAll bool
+	vulnID      string
+	vulnFixable bool
+	fixAll      bool
 )
 
 func init() {
-	vulnerabilitiesCmd.Flags().StringVarP(&vulnID, "id", "i", "", "Specific vulnerability ID")
-	vulnerabilitiesCmd.Flags().BoolVar(&fixAll, "fix-all", false, "Fix all fixable vulnerabilities")
+	flags := vulnerabilitiesCmd.PersistentFlags()
+	flags.StringVarP(&vulnID, "id", "i", "", "Specific vulnerability ID")
+	flags.StringVarP(&namespace, "namespace", "n", "", "Only workloads in this namespace")
+	flags.StringVarP(&output, "output", "o", "table", "Output format (table, json, yaml)")
+	flags.StringVarP(&severity, "severity", "s", "", "Minimum severity (critical, high, medium, low)")
+	vulnerabilitiesCmd.Flags().BoolVar(&fixAll, "fix-all", false, "Propose fixes for all fixable vulnerabilities (same as fix)")
+	vulnerabilitiesCmd.Flags().BoolVar(&vulnFixable, "fixable", false, "Only vulnerabilities with a fixed version")
+	vulnListCmd.Flags().BoolVar(&vulnFixable, "fixable", false, "Only vulnerabilities with a fixed version")
+
+	vulnerabilitiesCmd.AddCommand(vulnListCmd)
+	vulnerabilitiesCmd.AddCommand(vulnFixCmd)
 }
 
 func runVulnerabilities(cmd *cobra.Command, args []string) error {
-	logger.Info("🔍 Managing security vulnerabilities...")
-
-	if vulnID != "" {
-		return manageVulnerability(vulnID)
+	if fixAll {
+		return runVulnFix(cmd, args)
 	}
+	return runVulnList(cmd, args)
+}
 
-	if fixAll {
-		return fixAllVulnerabilities()
+// vulnerabilityFindings returns the vulnerabilities in --namespace, filtered
+// by --id and --severity.
+func vulnerabilityFindings(ctx context.Context) ([]finding, error) {
+	minSeverity, err := parseSeverity(severity)
+	if err != nil {
+		return nil, err
 	}
+	dyn, err := trivyClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fs, err := collectFindings(ctx, dyn, scanScope{Namespace: namespace})
+	if err != nil {
+		return nil, err
+	}
+	var out []finding
+	for _, f := range filterSeverity(fs, minSeverity) {
+		if f.Kind != kindVulnerability || (vulnID != "" && !strings.EqualFold(f.ID, vulnID)) {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
 
-	return listVulnerabilities()
+// vulnRow is one CVE across every image it was found in.
+type vulnRow struct {
+	ID        string       `json:"id"`
+	Severity  string       `json:"severity"`
+	Title     string       `json:"title,omitempty"`
+	Package   string       `json:"package"`
+	Installed []string     `json:"installedVersions"`
+	Fixed     string       `json:"fixedVersion,omitempty"`
+	Link      string       `json:"link,omitempty"`
+	Images    []string     `json:"images"`
+	Workloads []bumpTarget `json:"workloads"`
 }
 
-func manageVulnerability(vulnID string) error {
-	logger.Info("🔍 Managing vulnerability: " + vulnID)
+// vulnRows merges findings of the same CVE and package.
+func vulnRows(fs []finding) []vulnRow {
+	index := map[string]int{}
+	var rows []vulnRow
+	for _, f := range fs {
+		key := f.ID + "|" + f.Package
+		i, ok := index[key]
+		if !ok {
+			i = len(rows)
+			index[key] = i
+			rows = append(rows, vulnRow{ID: f.ID, Severity: f.Severity, Title: f.Title, Package: f.Package, Fixed: f.Fixed, Link: f.Link})
+		}
+		r := &rows[i]
+		if f.Installed != "" && !containsString(r.Installed, f.Installed) {
+			r.Installed = append(r.Installed, f.Installed)
+		}
+		if f.Image != "" && !containsString(r.Images, f.Image) {
+			r.Images = append(r.Images, f.Image)
+		}
+		t := bumpTarget{Namespace: f.Namespace, Workload: f.Workload, Container: f.Container}
+		if !containsTarget(r.Workloads, t) {
+			r.Workloads = append(r.Workloads, t)
+		}
+		if r.Fixed == "" {
+			r.Fixed = f.Fixed
+		}
+	}
+	for i := range rows {
+		sort.Strings(rows[i].Installed)
+		sort.Strings(rows[i].Images)
+	}
+	return rows
+}
 
-	// TODO: Implement vulnerability management
-	// This should:
-	// - Show vulnerability details
-	// - Check if fix is available
-	// - Apply fix if possible
-	// - Update vulnerability status
+func runVulnList(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	fs, err := vulnerabilityFindings(ctx)
+	if err != nil {
+		return err
+	}
+	if vulnFixable {
+		var fixable []finding
+		for _, f := range fs {
+			if f.Fixable() {
+				fixable = append(fixable, f)
+			}
+		}
+		fs = fixable
+	}
+	rows := vulnRows(fs)
 
-	logger.Info("✅ Vulnerability managed successfully")
+	switch output {
+	case "json":
+		return helpers.PrintJSON(rows)
+	case "yaml":
+		return helpers.PrintYAML(rows)
+	case "", "table":
+	default:
+		return fmt.Errorf("unsupported output format %q (table, json, yaml)", output)
+	}
+	if len(rows) == 0 {
+		if vulnID != "" {
+			fmt.Println(helpers.CreateSuccess(vulnID + " was not found in any running image"))
+		} else {
+			fmt.Println(helpers.CreateSuccess("No vulnerabilities found"))
+		}
+		return nil
+	}
+	if vulnID != "" {
+		return printVulnDetail(os.Stdout, rows)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSEVERITY\tPACKAGE\tINSTALLED\tFIXED\tIMAGES")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\n", r.ID, r.Severity, truncate(r.Package, 30),
+			truncate(strings.Join(r.Installed, ","), 30), dash(r.Fixed), len(r.Images))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d vulnerabilities; use --id=<CVE> to see the affected workloads\n", len(rows))
 	return nil
 }
 
-func fixAllVulnerabilities() error {
-	logger.Info("🔧 Fixing all fixable vulnerabilities...")
-
-	// TODO: Implement bulk vulnerability fixing
-	// This should:
-	// - Identify all fixable vulnerabilities
-	// - Apply fixes systematically
-	// - Report on success/failure
-	// - Update vulnerability database
-
-	logger.Info("✅ All vulnerabilities fixed successfully")
+// printVulnDetail shows one CVE and where it runs.
+func printVulnDetail(w io.Writer, rows []vulnRow) error {
+	for _, r := range rows {
+		fmt.Fprintf(w, "%s  %s\n", r.ID, r.Severity)
+		if r.Title != "" {
+			fmt.Fprintln(w, helpers.CreateMuted(r.Title))
+		}
+		fmt.Fprintf(w, "Package:   %s %s\n", r.Package, strings.Join(r.Installed, ", "))
+		fmt.Fprintf(w, "Fixed in:  %s\n", valueOr(r.Fixed, "no fix available"))
+		if r.Link != "" {
+			fmt.Fprintf(w, "Details:   %s\n", r.Link)
+		}
+		fmt.Fprintln(w)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAMESPACE\tWORKLOAD\tCONTAINER")
+		for _, t := range r.Workloads {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", t.Namespace, t.Workload, dash(t.Container))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintln(w)
+	}
 	return nil
 }
 
-func listVulnerabilities() error {
-	logger.Info("📋 Listing all vulnerabilities...")
+func runVulnFix(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	table := output == "" || output == "table"
+	if table {
+		logger.Info("🔧 Looking up newer tags for images with fixable vulnerabilities...")
+	}
+	fs, err := vulnerabilityFindings(ctx)
+	if err != nil {
+		return err
+	}
+	bumps := planBumps(ctx, newRegistryClient(), fs)
 
-	// TODO: Implement vulnerability listing
-	// This should show:
-	// - Vulnerability IDs and descriptions
-	// - Severity levels
-	// - Affected resources
-	// - Fix status and availability
+	switch output {
+	case "json":
+		return helpers.PrintJSON(bumps)
+	case "yaml":
+		return helpers.PrintYAML(bumps)
+	case "", "table":
+	default:
+		return fmt.Errorf("unsupported output format %q (table, json, yaml)", output)
+	}
+	return printBumps(os.Stdout, bumps)
+}
+
+// printBumps renders proposed bumps and the commands that apply them.
+func printBumps(w io.Writer, bumps []imageBump) error {
+	if len(bumps) == 0 {
+		fmt.Fprintln(w, helpers.CreateSuccess("No fixable vulnerabilities found"))
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "IMAGE\tCURRENT\tPROPOSED\tFIXABLE\tCRITICAL\tWORKLOADS")
+	for _, b := range bumps {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\n", truncate(withTag(b.Image, ""), 50), dash(b.Current),
+			dash(b.Proposed), b.Fixable, b.Critical, len(b.Workloads))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
 
-	logger.Info("✅ Vulnerability list displayed")
+	var notes, commands []string
+	for _, b := range bumps {
+		if b.Proposed == "" {
+			notes = append(notes, fmt.Sprintf("%s: %s", b.Image, b.Note))
+			continue
+		}
+		for _, t := range b.Workloads {
+			if t.Container == "" || !strings.Contains(t.Workload, "/") {
+				continue
+			}
+			commands = append(commands, fmt.Sprintf("kubectl -n %s set image %s %s=%s", t.Namespace,
+				strings.ToLower(t.Workload), t.Container, withTag(b.Image, b.Proposed)))
+		}
+	}
+	if len(notes) > 0 {
+		fmt.Fprintln(w)
+		for _, n := range notes {
+			fmt.Fprintln(w, helpers.CreateMuted("• "+n))
+		}
+	}
+	if len(commands) > 0 {
+		fmt.Fprintln(w, "\nApply the bumps (or change the image in Git for GitOps-managed workloads):")
+		for _, c := range commands {
+			fmt.Fprintln(w, "  "+c)
+		}
+		fmt.Fprintln(w, helpers.CreateMuted("\nRun `adhar security scan --rescan` afterwards to confirm the fixes."))
+	}
 	return nil
 }
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
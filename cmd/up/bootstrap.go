@@ -29,6 +29,7 @@ import (
 	"adhar-io/adhar/globals"
 	"adhar-io/adhar/platform/config"
 	"adhar-io/adhar/platform/controllers"
+	"adhar-io/adhar/platform/domain"
 	"adhar-io/adhar/platform/k8s"
 	"adhar-io/adhar/platform/logger"
 	pfactory "adhar-io/adhar/platform/providers"
@@ -259,6 +260,16 @@ func bootstrapPlatformOnCluster(ctx context.Context, result *pfactory.ProvisionR
 		return fmt.Errorf("installing in-cluster controller manager: %w", err)
 	}
 
+	// DNS provider and split-horizon zone selected for this environment in
+	// config.yaml; the Gateway and cert-manager come from the stack above.
+	if envConfig != nil && envConfig.DNS != nil {
+		domainConfig := pfactory.BuildDomainConfig(envConfig)
+		domainConfig.BaseDomain = host
+		if err := domain.NewManager(domainConfig, "").SetupDNS(ctx, result.Cluster); err != nil {
+			logger.Warnf("DNS setup for %s failed: %v (check with `adhar domain verify`)", host, err)
+		}
+	}
+
 	logger.Infof("✅ Platform bootstrapped on cluster %s (HA mode: %t)", result.Cluster.ID, enableHA)
 	return nil
 }
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"fmt"
+	"os"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/globals"
+	"adhar-io/adhar/platform/webhooks"
+
+	"github.com/spf13/cobra"
+)
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Print the platform's admission webhook configurations",
+	Long: `Print the webhook configurations for the platform validators and defaulters.
+
+The in-cluster controller manager ('adhar controller --enable-webhooks') serves
+validators for the platform XRs (CompositeCluster, CompositeDatabase,
+CompositeNetwork, CompositeApplication, CompositeEnvironment) and the DataPlane
+and AdharPlatform resources, and defaulters for DataPlane and AdharPlatform. It
+installs the ValidatingWebhookConfiguration and MutatingWebhookConfiguration
+itself once its serving certificate is ready. Use this command to review the
+rules or to manage the configurations through GitOps instead.
+
+Examples:
+  adhar webhook generate
+  adhar webhook generate --no-header --ca-bundle=ca.crt > platform-validation.yaml
+  adhar webhook generate --webhook-namespace=platform -o json`,
+	RunE: runGenerate,
+}
+
+var (
+	generateNamespace string
+	generateService   string
+	generateCABundle  string
+	generateOutput    string
+)
+
+func init() {
+	generateCmd.Flags().StringVar(&generateNamespace, "webhook-namespace", globals.AdharSystemNamespace, "Namespace of the webhook Service")
+	generateCmd.Flags().StringVar(&generateService, "service", webhooks.ServiceName, "Service in front of the controller manager's webhook server")
+	generateCmd.Flags().StringVar(&generateCABundle, "ca-bundle", "", "PEM file with the CA that signed the serving certificate")
+	generateCmd.Flags().StringVarP(&generateOutput, "output", "o", "yaml", "Output format (yaml, json)")
+}
+
+func runGenerate(cmd *cobra.Command, args []string) error {
+	var caBundle []byte
+	if generateCABundle != "" {
+		data, err := os.ReadFile(generateCABundle)
+		if err != nil {
+			return fmt.Errorf("reading CA bundle: %w", err)
+		}
+		caBundle = data
+	}
+
+	configs := []interface{}{
+		webhooks.ValidatingWebhookConfiguration(generateNamespace, generateService, caBundle),
+		webhooks.MutatingWebhookConfiguration(generateNamespace, generateService, caBundle),
+	}
+	switch generateOutput {
+	case "yaml":
+		for i, cfg := range configs {
+			if i > 0 {
+				fmt.Println("---")
+			}
+			if err := helpers.PrintYAML(cfg); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		return helpers.PrintJSON(map[string]interface{}{"apiVersion": "v1", "kind": "List", "items": configs})
+	default:
+		return fmt.Errorf("unsupported output format %q (use yaml or json)", generateOutput)
+	}
+}
@@ -0,0 +1,276 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"adhar-io/adhar/cmd/helpers"
+	"adhar-io/adhar/globals"
+	"adhar-io/adhar/platform/logger"
+	"adhar-io/adhar/platform/notify"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var sinkCmd = &cobra.Command{
+	Use:   "sink",
+	Short: "Manage outbound notification sinks",
+	Long: `Manage the sinks platform controllers send notifications to.
+
+The controllers publish AdharPlatform condition changes, DataPlane readiness
+transitions, degraded ArgoCD applications and failed Velero backups. Each sink
+receives the events it subscribes to (all by default), rendered for its type:
+
+• slack   - a Slack incoming webhook ({"text": ...})
+• teams   - a Microsoft Teams incoming webhook (MessageCard)
+• webhook - any HTTP endpoint; the event as JSON, or the rendered --template
+
+Sinks are stored as Secrets in adhar-system. Event types: platform.condition,
+dataplane.ready, application.degraded, application.recovered, backup.failed.
+
+Examples:
+  adhar webhook sink add ops --type=slack --url=https://hooks.slack.com/services/...
+  adhar webhook sink add pager --type=webhook --url=https://alerts.example.com/adhar \
+      --events='backup.*,dataplane.*' --header=Authorization='Bearer ...'
+  adhar webhook sink list
+  adhar webhook sink test ops`,
+}
+
+var sinkAddCmd = &cobra.Command{
+	Use:   "add NAME",
+	Short: "Add or replace a notification sink",
+	Long: `Add or replace a notification sink.
+
+--template is a Go text/template rendered with the event (.Type, .Severity,
+.Title, .Message, .Kind, .Namespace, .Name, .Reason, .Time, .Resource). For
+Slack and Teams it replaces the message text; for webhooks it is the request
+body.
+
+Examples:
+  adhar webhook sink add ops --type=slack --url=https://hooks.slack.com/services/...
+  adhar webhook sink add teams --type=teams --url=https://example.webhook.office.com/... --events='dataplane.*'
+  adhar webhook sink add audit --type=webhook --url=https://audit.example.com/events \
+      --template-file=event.tmpl`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSinkAdd,
+}
+
+var sinkListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List notification sinks",
+	Long: `List notification sinks. URLs are shown without their path, which
+usually embeds the webhook's secret.
+
+Examples:
+  adhar webhook sink list
+  adhar webhook sink list -o json`,
+	Args: cobra.NoArgs,
+	RunE: runSinkList,
+}
+
+var sinkTestCmd = &cobra.Command{
+	Use:   "test NAME",
+	Short: "Send a test notification to a sink",
+	Long: `Send a test event to a sink, with the same payload rendering and retries
+the controllers use.
+
+Examples:
+  adhar webhook sink test ops`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSinkTest,
+}
+
+var sinkRemoveCmd = &cobra.Command{
+	Use:     "remove NAME",
+	Aliases: []string{"rm"},
+	Short:   "Remove a notification sink",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runSinkRemove,
+}
+
+var (
+	sinkType         string
+	sinkURL          string
+	sinkEvents       []string
+	sinkTemplate     string
+	sinkTemplateFile string
+	sinkHeaders      []string
+	sinkOutput       string
+)
+
+func init() {
+	sinkAddCmd.Flags().StringVar(&sinkType, "type", notify.SinkWebhook, "Sink type ("+strings.Join(notify.SinkTypes, ", ")+")")
+	sinkAddCmd.Flags().StringVar(&sinkURL, "url", "", "Webhook URL")
+	sinkAddCmd.Flags().StringSliceVar(&sinkEvents, "events", nil, "Event types to deliver, globs allowed (default all)")
+	sinkAddCmd.Flags().StringVar(&sinkTemplate, "template", "", "Payload template (Go text/template)")
+	sinkAddCmd.Flags().StringVar(&sinkTemplateFile, "template-file", "", "File containing the payload template")
+	sinkAddCmd.Flags().StringArrayVar(&sinkHeaders, "header", nil, "Extra request header as key=value (repeatable)")
+	_ = sinkAddCmd.MarkFlagRequired("url")
+	sinkAddCmd.MarkFlagsMutuallyExclusive("template", "template-file")
+
+	sinkListCmd.Flags().StringVarP(&sinkOutput, "output", "o", "table", "Output format (table, json)")
+
+	sinkCmd.AddCommand(sinkAddCmd)
+	sinkCmd.AddCommand(sinkListCmd)
+	sinkCmd.AddCommand(sinkTestCmd)
+	sinkCmd.AddCommand(sinkRemoveCmd)
+}
+
+func sinkClient() (client.Client, error) {
+	conf, err := helpers.GetKubeConfig()
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the cluster (is it running? try `adhar up`): %w", err)
+	}
+	return helpers.GetKubeClient(conf)
+}
+
+func runSinkAdd(cmd *cobra.Command, args []string) error {
+	s := notify.Sink{
+		Name:     args[0],
+		Type:     sinkType,
+		URL:      sinkURL,
+		Events:   sinkEvents,
+		Template: sinkTemplate,
+	}
+	if sinkTemplateFile != "" {
+		data, err := os.ReadFile(sinkTemplateFile)
+		if err != nil {
+			return fmt.Errorf("reading template: %w", err)
+		}
+		s.Template = string(data)
+	}
+	for _, h := range sinkHeaders {
+		key, value, ok := strings.Cut(h, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid header %q, expected key=value", h)
+		}
+		if s.Headers == nil {
+			s.Headers = map[string]string{}
+		}
+		s.Headers[key] = value
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	c, err := sinkClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := notify.SaveSink(ctx, c, globals.AdharSystemNamespace, s); err != nil {
+		return err
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("Notification sink %q saved", s.Name)))
+	return nil
+}
+
+// sinkRow is the list view of a sink; the URL is reduced to its host.
+type sinkRow struct {
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Host     string   `json:"host"`
+	Events   []string `json:"events"`
+	Template bool     `json:"template"`
+}
+
+func runSinkList(cmd *cobra.Command, args []string) error {
+	c, err := sinkClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	sinks, err := notify.ListSinks(ctx, c, globals.AdharSystemNamespace)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]sinkRow, 0, len(sinks))
+	for _, s := range sinks {
+		rows = append(rows, sinkRow{Name: s.Name, Type: s.Type, Host: maskURL(s.URL), Events: s.Events, Template: s.Template != ""})
+	}
+
+	switch sinkOutput {
+	case "json":
+		return helpers.PrintJSON(rows)
+	case "table":
+	default:
+		return fmt.Errorf("unsupported output format %q (use table or json)", sinkOutput)
+	}
+
+	if len(rows) == 0 {
+		fmt.Println(helpers.CreateMuted("No notification sinks configured. Add one with `adhar webhook sink add`."))
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%-20s %-8s %-34s %s\n", "NAME", "TYPE", "HOST", "EVENTS"))
+	b.WriteString(strings.Repeat("─", 90) + "\n")
+	for _, r := range rows {
+		events := "all"
+		if len(r.Events) > 0 {
+			events = strings.Join(r.Events, ",")
+		}
+		b.WriteString(fmt.Sprintf("%-20s %-8s %-34s %s\n", trunc(r.Name, 20), r.Type, trunc(r.Host, 34), events))
+	}
+	fmt.Println(helpers.BorderStyle.Render(b.String()))
+	fmt.Println(helpers.CreateMuted(fmt.Sprintf("%d sink(s)", len(rows))))
+	return nil
+}
+
+// maskURL drops everything but the scheme and host: Slack and Teams embed
+// the webhook secret in the path.
+func maskURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "***"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+func runSinkTest(cmd *cobra.Command, args []string) error {
+	c, err := sinkClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	s, err := notify.GetSink(ctx, c, globals.AdharSystemNamespace, args[0])
+	if err != nil {
+		return err
+	}
+
+	logger.Info(fmt.Sprintf("📨 Sending a test notification to %q...", s.Name))
+	e := notify.Event{
+		Type:     notify.EventTest,
+		Severity: notify.SeverityInfo,
+		Title:    "Adhar test notification",
+		Message:  fmt.Sprintf("Sink %s is configured correctly", s.Name),
+		Time:     time.Now().UTC(),
+	}
+	if err := notify.NewNotifier(c, globals.AdharSystemNamespace).Deliver(ctx, s, e); err != nil {
+		return fmt.Errorf("test notification failed: %w", err)
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("Test notification delivered to %q", s.Name)))
+	return nil
+}
+
+func runSinkRemove(cmd *cobra.Command, args []string) error {
+	c, err := sinkClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := notify.DeleteSink(ctx, c, globals.AdharSystemNamespace, args[0]); err != nil {
+		return err
+	}
+	fmt.Println(helpers.CreateSuccess(fmt.Sprintf("Notification sink %q removed", args[0])))
+	return nil
+}
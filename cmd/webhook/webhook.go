@@ -35,12 +35,15 @@ This command provides:
 • Security and authentication setup
 • Event routing and filtering
 • Webhook monitoring and logging
+• Outbound notification sinks (Slack, Teams, generic webhooks)
 
 Examples:
   adhar webhook list                    # List all webhooks
   adhar webhook create --name=github    # Create new webhook
+  adhar webhook generate                # Platform webhook configurations
   adhar webhook test --name=github     # Test webhook
-  adhar webhook monitor --name=github  # Monitor webhook activity`,
+  adhar webhook monitor --name=github  # Monitor webhook activity
+  adhar webhook sink list               # Notification sinks`,
 	RunE: runWebhook,
 }
 
@@ -70,9 +73,11 @@ func init() {
 	// Add subcommands
 	WebhookCmd.AddCommand(listCmd)
 	WebhookCmd.AddCommand(createCmd)
+	WebhookCmd.AddCommand(generateCmd)
 	WebhookCmd.AddCommand(testCmd)
 	WebhookCmd.AddCommand(monitorCmd)
 	WebhookCmd.AddCommand(securityCmd)
+	WebhookCmd.AddCommand(sinkCmd)
 }
 
 func runWebhook(cmd *cobra.Command, args []string) error {
@@ -80,9 +85,11 @@ func runWebhook(cmd *cobra.Command, args []string) error {
 	logger.Info("Available subcommands:")
 	logger.Info("  list     - List all webhooks")
 	logger.Info("  create   - Create new webhooks")
+	logger.Info("  generate - Print the platform webhook configurations")
 	logger.Info("  test     - Test webhooks")
 	logger.Info("  monitor  - Monitor webhook activity")
 	logger.Info("  security - Manage webhook security")
+	logger.Info("  sink     - Manage outbound notification sinks")
 
 	return cmd.Help()
 }
@@ -0,0 +1,268 @@
+// Package certs inventories the TLS certificates a platform depends on and
+// renews them before they expire: cert-manager Certificates, the self-signed
+// platform certificate (adhar-cert, which doubles as the platform CA), the
+// controller manager's webhook serving certificate and, on kubeadm clusters,
+// the control-plane certificates under /etc/kubernetes.
+package certs
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"time"
+
+	"adhar-io/adhar/globals"
+	"adhar-io/adhar/platform/webhooks"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Certificate sources.
+const (
+	SourceCertManager = "cert-manager"
+	SourcePlatform    = "platform"
+	SourceWebhook     = "webhook"
+	SourceKubeadm     = "kubeadm"
+)
+
+// Status is how close a certificate is to expiry.
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusWarning  Status = "warning"
+	StatusCritical Status = "critical"
+	StatusExpired  Status = "expired"
+	// StatusUnknown is reported for cert-manager Certificates not issued yet.
+	StatusUnknown Status = "unknown"
+)
+
+// Certificate is one inventoried certificate.
+type Certificate struct {
+	Source string `json:"source"`
+	// Namespace is the Kubernetes namespace, or the node for kubeadm certs.
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+	DNSNames  []string  `json:"dnsNames,omitempty"`
+	Issuer    string    `json:"issuer,omitempty"`
+	IsCA      bool      `json:"isCA,omitempty"`
+	NotAfter  time.Time `json:"notAfter,omitempty"`
+	Status    Status    `json:"status"`
+	// Message carries cert-manager's Ready condition while not ready.
+	Message string `json:"message,omitempty"`
+}
+
+// ID identifies the certificate on the command line, source/namespace/name.
+func (c Certificate) ID() string {
+	if c.Namespace == "" {
+		return c.Source + "/" + c.Name
+	}
+	return c.Source + "/" + c.Namespace + "/" + c.Name
+}
+
+// Thresholds are the remaining lifetimes below which a certificate is
+// reported as warning and critical.
+type Thresholds struct {
+	Warning  time.Duration
+	Critical time.Duration
+}
+
+// DefaultThresholds warns 30 days and escalates 7 days before expiry.
+func DefaultThresholds() Thresholds {
+	return Thresholds{Warning: 30 * 24 * time.Hour, Critical: 7 * 24 * time.Hour}
+}
+
+// Status classifies a certificate expiring at notAfter.
+func (t Thresholds) Status(notAfter, now time.Time) Status {
+	switch left := notAfter.Sub(now); {
+	case notAfter.IsZero():
+		return StatusUnknown
+	case left <= 0:
+		return StatusExpired
+	case left <= t.Critical:
+		return StatusCritical
+	case left <= t.Warning:
+		return StatusWarning
+	}
+	return StatusOK
+}
+
+// NeedsRenewal reports whether the status calls for renewal.
+func (s Status) NeedsRenewal() bool {
+	return s == StatusWarning || s == StatusCritical || s == StatusExpired
+}
+
+// Options configures Inventory.
+type Options struct {
+	Thresholds Thresholds
+	// Now overrides the clock; zero means time.Now.
+	Now time.Time
+	// Kubeadm, when set, also inventories the control-plane certificates of
+	// a kubeadm cluster over SSH.
+	Kubeadm *KubeadmTarget
+}
+
+// PlatformSecrets are the TLS Secrets holding the self-signed platform
+// certificate. Local (Kind) platforms keep it in adhar-system, cloud
+// platforms set up by the domain manager in ingress-nginx; ArgoCD serves a
+// copy.
+var PlatformSecrets = []client.ObjectKey{
+	{Namespace: globals.AdharSystemNamespace, Name: globals.SelfSignedCertSecretName},
+	{Namespace: "ingress-nginx", Name: globals.SelfSignedCertSecretName},
+	{Namespace: globals.AdharSystemNamespace, Name: "argocd-server-tls"},
+}
+
+// caBundleSecret is the CA-only copy of the platform certificate that
+// clients mount as a trust bundle.
+var caBundleSecret = client.ObjectKey{Namespace: corev1.NamespaceDefault, Name: globals.SelfSignedCertCMName}
+
+// webhookSecret is the controller manager's self-signed serving certificate.
+var webhookSecret = client.ObjectKey{Namespace: globals.AdharSystemNamespace, Name: webhooks.SecretName}
+
+var certificateListGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "CertificateList"}
+
+// Inventory lists the platform's certificates, soonest expiry first.
+func Inventory(ctx context.Context, c client.Client, opts Options) ([]Certificate, error) {
+	if opts.Thresholds == (Thresholds{}) {
+		opts.Thresholds = DefaultThresholds()
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	certs, err := certManagerCertificates(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range PlatformSecrets {
+		cert, err := secretCertificate(ctx, c, key, SourcePlatform)
+		if err != nil {
+			return nil, err
+		}
+		if cert != nil {
+			certs = append(certs, *cert)
+		}
+	}
+	webhook, err := secretCertificate(ctx, c, webhookSecret, SourceWebhook)
+	if err != nil {
+		return nil, err
+	}
+	if webhook != nil {
+		certs = append(certs, *webhook)
+	}
+	if opts.Kubeadm != nil {
+		kubeadm, err := opts.Kubeadm.Inventory()
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, kubeadm...)
+	}
+
+	for i := range certs {
+		certs[i].Status = opts.Thresholds.Status(certs[i].NotAfter, now)
+	}
+	sort.SliceStable(certs, func(i, j int) bool {
+		if certs[i].NotAfter.IsZero() != certs[j].NotAfter.IsZero() {
+			return certs[i].NotAfter.IsZero()
+		}
+		return certs[i].NotAfter.Before(certs[j].NotAfter)
+	})
+	return certs, nil
+}
+
+// certManagerCertificates lists cert-manager Certificates in all namespaces;
+// none when cert-manager is not installed.
+func certManagerCertificates(ctx context.Context, c client.Client) ([]Certificate, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(certificateListGVK)
+	if err := c.List(ctx, list); err != nil {
+		if meta.IsNoMatchError(err) || k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("listing cert-manager certificates: %w", err)
+	}
+
+	var certs []Certificate
+	for _, item := range list.Items {
+		cert := Certificate{
+			Source:    SourceCertManager,
+			Namespace: item.GetNamespace(),
+			Name:      item.GetName(),
+		}
+		cert.DNSNames, _, _ = unstructured.NestedStringSlice(item.Object, "spec", "dnsNames")
+		cert.IsCA, _, _ = unstructured.NestedBool(item.Object, "spec", "isCA")
+		if kind, _, _ := unstructured.NestedString(item.Object, "spec", "issuerRef", "kind"); kind != "" {
+			name, _, _ := unstructured.NestedString(item.Object, "spec", "issuerRef", "name")
+			cert.Issuer = kind + "/" + name
+		}
+		if notAfter, _, _ := unstructured.NestedString(item.Object, "status", "notAfter"); notAfter != "" {
+			if t, err := time.Parse(time.RFC3339, notAfter); err == nil {
+				cert.NotAfter = t
+			}
+		}
+		conditions, _, _ := unstructured.NestedSlice(item.Object, "status", "conditions")
+		for _, raw := range conditions {
+			cond, _ := raw.(map[string]interface{})
+			if cond["type"] == "Ready" && cond["status"] != "True" {
+				cert.Message, _ = cond["message"].(string)
+			}
+		}
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
+
+// secretCertificate parses tls.crt of the Secret at key; nil when the Secret
+// does not exist. Secrets issued by cert-manager are skipped, they are
+// reported through their Certificate.
+func secretCertificate(ctx context.Context, c client.Client, key client.ObjectKey, source string) (*Certificate, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, key, secret); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting secret %s: %w", key, err)
+	}
+	if _, ok := secret.Annotations["cert-manager.io/certificate-name"]; ok {
+		return nil, nil
+	}
+	parsed, err := ParsePEM(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: %w", key, err)
+	}
+	cert := fromX509(parsed)
+	cert.Source = source
+	cert.Namespace = key.Namespace
+	cert.Name = key.Name
+	return &cert, nil
+}
+
+// ParsePEM parses the first certificate in data.
+func ParsePEM(data []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no PEM certificate found")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func fromX509(cert *x509.Certificate) Certificate {
+	issuer := cert.Issuer.CommonName
+	if issuer == "" && len(cert.Issuer.Organization) > 0 {
+		issuer = cert.Issuer.Organization[0]
+	}
+	return Certificate{
+		DNSNames: cert.DNSNames,
+		Issuer:   issuer,
+		IsCA:     cert.IsCA,
+		NotAfter: cert.NotAfter,
+	}
+}
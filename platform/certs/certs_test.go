@@ -0,0 +1,333 @@
+package certs
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"adhar-io/adhar/api/v1alpha1"
+	"adhar-io/adhar/globals"
+	"adhar-io/adhar/platform/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	scheme.AddKnownTypeWithName(certificateGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(certificateListGVK, &unstructured.UnstructuredList{})
+	return scheme
+}
+
+func tlsSecret(key client.ObjectKey, cert, privateKey []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: cert, corev1.TLSPrivateKeyKey: privateKey},
+	}
+}
+
+func certManagerCertificate(namespace, name, notAfter string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"secretName": name,
+			"dnsNames":   []interface{}{"keycloak.example.com"},
+			"issuerRef":  map[string]interface{}{"kind": "ClusterIssuer", "name": "letsencrypt-prod"},
+		},
+		"status": map[string]interface{}{
+			"notAfter": notAfter,
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			},
+		},
+	}}
+	u.SetGroupVersionKind(certificateGVK)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+// consumer is a Deployment mounting the platform certificate.
+func consumer(name string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: globals.AdharSystemNamespace, Name: name},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{{
+				Name:         "platform-ca",
+				VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: globals.SelfSignedCertSecretName}},
+			}},
+		}}},
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	th := DefaultThresholds()
+	day := 24 * time.Hour
+
+	assert.Equal(t, StatusOK, th.Status(now.Add(90*day), now))
+	assert.Equal(t, StatusWarning, th.Status(now.Add(20*day), now))
+	assert.Equal(t, StatusCritical, th.Status(now.Add(3*day), now))
+	assert.Equal(t, StatusExpired, th.Status(now.Add(-time.Hour), now))
+	assert.Equal(t, StatusUnknown, th.Status(time.Time{}, now))
+	assert.True(t, StatusCritical.NeedsRenewal())
+	assert.False(t, StatusUnknown.NeedsRenewal())
+}
+
+func TestInventory(t *testing.T) {
+	cert, key, err := domain.CreateSelfSignedCertificate([]string{"adhar.localtest.me", "*.adhar.localtest.me"})
+	require.NoError(t, err)
+	webhookCert, webhookKey, err := domain.CreateSelfSignedCertificate([]string{"adhar-webhook.adhar-system.svc"})
+	require.NoError(t, err)
+
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(
+		tlsSecret(PlatformSecrets[0], cert, key),
+		tlsSecret(webhookSecret, webhookCert, webhookKey),
+		certManagerCertificate("keycloak", "keycloak-tls", time.Now().Add(5*24*time.Hour).UTC().Format(time.RFC3339)),
+	).Build()
+
+	list, err := Inventory(context.Background(), c, Options{})
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+
+	// Soonest expiry first
+	assert.Equal(t, "cert-manager/keycloak/keycloak-tls", list[0].ID())
+	assert.Equal(t, StatusCritical, list[0].Status)
+	assert.Equal(t, "ClusterIssuer/letsencrypt-prod", list[0].Issuer)
+
+	ids := []string{list[1].ID(), list[2].ID()}
+	assert.Contains(t, ids, "platform/adhar-system/adhar-cert")
+	assert.Contains(t, ids, "webhook/adhar-system/adhar-webhook-tls")
+	for _, cert := range list[1:] {
+		assert.Equal(t, StatusOK, cert.Status)
+		assert.True(t, cert.IsCA)
+	}
+}
+
+func TestInventoryWithoutCertManager(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	list, err := Inventory(context.Background(), c, Options{})
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestKubeadmInventoryAndRenew(t *testing.T) {
+	cert, _, err := domain.CreateSelfSignedCertificate([]string{"kubernetes"})
+	require.NoError(t, err)
+	b64 := base64.StdEncoding.EncodeToString(cert)
+
+	var commands []string
+	target := &KubeadmTarget{
+		Nodes: []Node{{Name: "cp-1", IP: "10.0.0.1"}, {Name: "cp-2", IP: "10.0.0.2"}},
+		Run: func(ip, command string) (string, error) {
+			commands = append(commands, ip+": "+command)
+			if command == kubeadmHealthScript {
+				return "ok\n", nil
+			}
+			return "pki/apiserver.crt " + b64 + "\npki/etcd/server.crt " + b64 + "\nadmin.conf " + b64 + "\n", nil
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).Build()
+	list, err := Inventory(context.Background(), c, Options{Kubeadm: target})
+	require.NoError(t, err)
+	require.Len(t, list, 6)
+	var ids []string
+	for _, cert := range list {
+		ids = append(ids, cert.ID())
+	}
+	assert.Contains(t, ids, "kubeadm/cp-1/apiserver.crt")
+	assert.Contains(t, ids, "kubeadm/cp-2/etcd/server.crt")
+	assert.Contains(t, ids, "kubeadm/cp-1/admin.conf")
+
+	commands = nil
+	leaf := Certificate{Source: SourceKubeadm, Namespace: "cp-2", Name: "apiserver.crt"}
+	ca := Certificate{Source: SourceKubeadm, Namespace: "cp-2", Name: "ca.crt", IsCA: true}
+	result, err := Renew(context.Background(), c, []Certificate{leaf, leaf, ca}, target)
+	require.NoError(t, err)
+	require.Len(t, commands, 2, "one renewal per node, then a health check")
+	assert.True(t, strings.HasPrefix(commands[0], "10.0.0.2: kubeadm certs renew all"))
+	assert.Equal(t, "10.0.0.2: "+kubeadmHealthScript, commands[1])
+	assert.Equal(t, []string{"kubeadm/cp-2/*"}, result.Renewed)
+	assert.Len(t, result.Skipped, 1)
+}
+
+func TestKubeadmRenewWaitsForEachNode(t *testing.T) {
+	kubeadmHealthInterval = time.Millisecond
+	defer func() { kubeadmHealthInterval = 10 * time.Second }()
+
+	var commands []string
+	checks := map[string]int{}
+	target := &KubeadmTarget{
+		Nodes: []Node{{Name: "cp-1", IP: "10.0.0.1"}, {Name: "cp-2", IP: "10.0.0.2"}},
+		Run: func(ip, command string) (string, error) {
+			if command != kubeadmHealthScript {
+				commands = append(commands, ip+": renew")
+				return "", nil
+			}
+			// Each node's control plane needs a few checks to come back.
+			if checks[ip]++; checks[ip] < 3 {
+				commands = append(commands, ip+": down")
+				return "", fmt.Errorf("exit status 7")
+			}
+			commands = append(commands, ip+": ok")
+			return "ok", nil
+		},
+	}
+	certs := []Certificate{
+		{Source: SourceKubeadm, Namespace: "cp-1", Name: "apiserver.crt"},
+		{Source: SourceKubeadm, Namespace: "cp-2", Name: "apiserver.crt"},
+	}
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).Build()
+	_, err := Renew(context.Background(), c, certs, target)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"10.0.0.1: renew", "10.0.0.1: down", "10.0.0.1: down", "10.0.0.1: ok",
+		"10.0.0.2: renew", "10.0.0.2: down", "10.0.0.2: down", "10.0.0.2: ok",
+	}, commands)
+
+	kubeadmHealthTimeout = 20 * time.Millisecond
+	defer func() { kubeadmHealthTimeout = 5 * time.Minute }()
+	commands = nil
+	target.Run = func(ip, command string) (string, error) {
+		commands = append(commands, ip)
+		return "", fmt.Errorf("exit status 7")
+	}
+	_, err = Renew(context.Background(), c, certs, target)
+	require.Error(t, err, "a node that does not come back stops the renewal")
+	for _, ip := range commands {
+		assert.Equal(t, "10.0.0.1", ip, "the next node must not be touched")
+	}
+}
+
+func TestRenewPlatformCertificateKeepsKey(t *testing.T) {
+	cert, key, err := domain.CreateSelfSignedCertificate([]string{"adhar.localtest.me"})
+	require.NoError(t, err)
+	platform := &v1alpha1.AdharPlatform{ObjectMeta: metav1.ObjectMeta{Name: "adhar"}}
+	platform.Spec.BuildCustomization.SelfSignedCert = string(cert)
+
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(
+		tlsSecret(PlatformSecrets[0], cert, key),
+		tlsSecret(PlatformSecrets[2], cert, key),
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: caBundleSecret.Namespace, Name: caBundleSecret.Name},
+			Data:       map[string][]byte{globals.SelfSignedCertCMKeyName: cert},
+		},
+		platform,
+		consumer("gitea"),
+	).Build()
+	ctx := context.Background()
+
+	list, err := Inventory(ctx, c, Options{})
+	require.NoError(t, err)
+	result, err := Renew(ctx, c, list, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"platform/adhar-system/adhar-cert", "platform/adhar-system/argocd-server-tls"}, result.Renewed)
+	assert.Equal(t, []string{"adhar-system/Deployment/gitea"}, result.Restarted)
+
+	newCert, newKey, err := PlatformCertificate(ctx, c)
+	require.NoError(t, err)
+	assert.NotEqual(t, cert, newCert)
+	assert.Equal(t, key, newKey)
+	old, err := ParsePEM(cert)
+	require.NoError(t, err)
+	renewed, err := ParsePEM(newCert)
+	require.NoError(t, err)
+	assert.Equal(t, old.DNSNames, renewed.DNSNames)
+	assert.NoError(t, renewed.CheckSignatureFrom(old), "the renewed certificate verifies against the old one")
+
+	bundle := &corev1.Secret{}
+	require.NoError(t, c.Get(ctx, caBundleSecret, bundle))
+	assert.Equal(t, newCert, bundle.Data[globals.SelfSignedCertCMKeyName])
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(platform), platform))
+	assert.Equal(t, string(newCert), platform.Spec.BuildCustomization.SelfSignedCert)
+	deploy := &appsv1.Deployment{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: globals.AdharSystemNamespace, Name: "gitea"}, deploy))
+	assert.NotEmpty(t, deploy.Spec.Template.Annotations[restartedAtAnnotation])
+}
+
+func TestRotateCA(t *testing.T) {
+	cert, key, err := domain.CreateSelfSignedCertificate([]string{"adhar.localtest.me", "*.adhar.localtest.me"})
+	require.NoError(t, err)
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(
+		tlsSecret(PlatformSecrets[0], cert, key),
+		consumer("headlamp"),
+	).Build()
+	ctx := context.Background()
+
+	result, err := RotateCA(ctx, c)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform/adhar-system/adhar-cert"}, result.Renewed)
+	assert.Equal(t, []string{"adhar-system/Deployment/headlamp"}, result.Restarted)
+
+	newCert, newKey, err := PlatformCertificate(ctx, c)
+	require.NoError(t, err)
+	assert.NotEqual(t, key, newKey)
+	old, err := ParsePEM(cert)
+	require.NoError(t, err)
+	rotated, err := ParsePEM(newCert)
+	require.NoError(t, err)
+	assert.Equal(t, old.DNSNames, rotated.DNSNames)
+	assert.Error(t, rotated.CheckSignatureFrom(old), "a rotated CA is not trusted through the old one")
+}
+
+func TestRenewCertManagerCertificate(t *testing.T) {
+	cm := certManagerCertificate("keycloak", "keycloak-tls", "2026-01-01T00:00:00Z")
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(cm).WithStatusSubresource(cm).Build()
+	ctx := context.Background()
+
+	result, err := Renew(ctx, c, []Certificate{{Source: SourceCertManager, Namespace: "keycloak", Name: "keycloak-tls"}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cert-manager/keycloak/keycloak-tls"}, result.Renewed)
+
+	got := &unstructured.Unstructured{}
+	got.SetGroupVersionKind(certificateGVK)
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "keycloak", Name: "keycloak-tls"}, got))
+	conditions, _, _ := unstructured.NestedSlice(got.Object, "status", "conditions")
+	require.Len(t, conditions, 2)
+	issuing := conditions[1].(map[string]interface{})
+	assert.Equal(t, "Issuing", issuing["type"])
+	assert.Equal(t, "True", issuing["status"])
+	assert.Equal(t, "ManuallyTriggered", issuing["reason"])
+}
+
+func TestRenewWebhookCertificate(t *testing.T) {
+	cert, key, err := domain.CreateSelfSignedCertificate([]string{"adhar-webhook.adhar-system.svc"})
+	require.NoError(t, err)
+	webhook := Certificate{Source: SourceWebhook, Namespace: webhookSecret.Namespace, Name: webhookSecret.Name}
+	ctx := context.Background()
+
+	// Without the controller manager nothing would issue a new certificate
+	c := fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(tlsSecret(webhookSecret, cert, key)).Build()
+	result, err := Renew(ctx, c, []Certificate{webhook}, nil)
+	require.NoError(t, err)
+	assert.Len(t, result.Skipped, 1)
+	require.NoError(t, c.Get(ctx, webhookSecret, &corev1.Secret{}))
+
+	manager := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: controllerManager.Namespace, Name: controllerManager.Name}}
+	c = fake.NewClientBuilder().WithScheme(newScheme(t)).WithObjects(tlsSecret(webhookSecret, cert, key), manager).Build()
+	result, err = Renew(ctx, c, []Certificate{webhook}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"adhar-system/Deployment/adhar-controller-manager"}, result.Restarted)
+	err = c.Get(ctx, webhookSecret, &corev1.Secret{})
+	assert.True(t, k8serrors.IsNotFound(err), "the controller manager issues a new certificate on restart")
+}
@@ -0,0 +1,179 @@
+package certs
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	provider "adhar-io/adhar/platform/providers"
+
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Node is a kubeadm control-plane node reachable over SSH.
+type Node struct {
+	Name string
+	IP   string
+}
+
+// KubeadmTarget reaches the control-plane nodes of a kubeadm cluster.
+type KubeadmTarget struct {
+	Nodes []Node
+	// Run executes a root shell command on the node at ip.
+	Run func(ip, command string) (string, error)
+}
+
+// NewKubeadmTarget drives nodes over SSH with the cluster key, the same way
+// the providers bootstrap and upgrade kubeadm clusters.
+func NewKubeadmTarget(signer ssh.Signer, user string, nodes []Node) *KubeadmTarget {
+	return &KubeadmTarget{
+		Nodes: nodes,
+		Run: func(ip, command string) (string, error) {
+			return provider.SSHRun(signer, user, ip, command, 5*time.Minute)
+		},
+	}
+}
+
+// ControlPlaneNodes returns the cluster's control-plane nodes, addressed by
+// external IP where they have one.
+func ControlPlaneNodes(ctx context.Context, c client.Client) ([]Node, error) {
+	var list corev1.NodeList
+	if err := c.List(ctx, &list, client.HasLabels{"node-role.kubernetes.io/control-plane"}); err != nil {
+		return nil, fmt.Errorf("listing control-plane nodes: %w", err)
+	}
+	var nodes []Node
+	for _, n := range list.Items {
+		var internal, external string
+		for _, addr := range n.Status.Addresses {
+			switch addr.Type {
+			case corev1.NodeExternalIP:
+				external = addr.Address
+			case corev1.NodeInternalIP:
+				internal = addr.Address
+			}
+		}
+		ip := external
+		if ip == "" {
+			ip = internal
+		}
+		if ip != "" {
+			nodes = append(nodes, Node{Name: n.Name, IP: ip})
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no control-plane nodes with an address found")
+	}
+	return nodes, nil
+}
+
+// kubeadmListScript prints "<name> <base64 PEM>" for every certificate kubeadm
+// manages: the PKI files and the client certificates embedded in the
+// control-plane kubeconfigs. kubelet.conf is left out; kubelet rotates its own
+// client certificate.
+const kubeadmListScript = `cd /etc/kubernetes || exit 1
+for f in pki/*.crt pki/etcd/*.crt; do [ -f "$f" ] && echo "$f $(base64 -w0 "$f")"; done
+for f in admin.conf super-admin.conf controller-manager.conf scheduler.conf; do
+  [ -f "$f" ] && echo "$f $(awk '/client-certificate-data:/ {print $2}' "$f")"
+done
+true`
+
+// kubeadmRenewScript renews every non-CA certificate and restarts the static
+// control-plane pods so they load them; kubelet recreates the stopped
+// containers.
+const kubeadmRenewScript = `kubeadm certs renew all && ` +
+	`for c in kube-apiserver kube-controller-manager kube-scheduler etcd; do ` +
+	`crictl ps --name "^$c$" -q | xargs -r crictl stop; done`
+
+// kubeadmHealthScript prints ok once the local API server is ready and, on
+// nodes running stacked etcd, the local etcd member reports healthy on the
+// metrics port kubeadm's own liveness probe uses.
+const kubeadmHealthScript = `curl -ksf https://127.0.0.1:6443/readyz >/dev/null && ` +
+	`{ [ ! -f /etc/kubernetes/manifests/etcd.yaml ] || curl -sf http://127.0.0.1:2381/health | grep -q '"health":"true"'; } && ` +
+	`echo ok`
+
+// How long Renew waits for a node's control plane to come back, and how
+// often it checks.
+var (
+	kubeadmHealthTimeout  = 5 * time.Minute
+	kubeadmHealthInterval = 10 * time.Second
+)
+
+// Inventory lists the kubeadm certificates of every control-plane node.
+func (k *KubeadmTarget) Inventory() ([]Certificate, error) {
+	var certs []Certificate
+	for _, node := range k.Nodes {
+		out, err := k.Run(node.IP, kubeadmListScript)
+		if err != nil {
+			return nil, fmt.Errorf("reading kubeadm certificates on %s: %w", node.Name, err)
+		}
+		parsed, err := parseKubeadmCertificates(node.Name, out)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, parsed...)
+	}
+	return certs, nil
+}
+
+// Renew renews the kubeadm certificates on one node and waits until its API
+// server and etcd member are healthy again. Renewing restarts the node's
+// control plane, so callers must not move on to the next node before Renew
+// returns or an HA control plane can lose etcd quorum.
+func (k *KubeadmTarget) Renew(ctx context.Context, node string) error {
+	for _, n := range k.Nodes {
+		if n.Name != node {
+			continue
+		}
+		if out, err := k.Run(n.IP, kubeadmRenewScript); err != nil {
+			return fmt.Errorf("renewing kubeadm certificates on %s: %w (output: %s)", node, err, provider.LastLines(out, 10))
+		}
+		return k.waitHealthy(ctx, n)
+	}
+	return fmt.Errorf("control-plane node %q not found", node)
+}
+
+// waitHealthy waits until the API server on node answers its readiness
+// endpoint locally and its etcd member is healthy.
+func (k *KubeadmTarget) waitHealthy(ctx context.Context, node Node) error {
+	waitCtx, cancel := context.WithTimeout(ctx, kubeadmHealthTimeout)
+	defer cancel()
+	for {
+		out, err := k.Run(node.IP, kubeadmHealthScript)
+		if err == nil && strings.TrimSpace(out) == "ok" {
+			return nil
+		}
+		select {
+		case <-waitCtx.Done():
+			return fmt.Errorf("control plane on %s not healthy %s after renewing its certificates (last error: %v)", node.Name, kubeadmHealthTimeout, err)
+		case <-time.After(kubeadmHealthInterval):
+		}
+	}
+}
+
+func parseKubeadmCertificates(node, out string) ([]Certificate, error) {
+	var certs []Certificate
+	for _, line := range strings.Split(out, "\n") {
+		name, data, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || data == "" {
+			continue
+		}
+		pemData, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s on %s: %w", name, node, err)
+		}
+		parsed, err := ParsePEM(pemData)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s on %s: %w", name, node, err)
+		}
+		cert := fromX509(parsed)
+		cert.Source = SourceKubeadm
+		cert.Namespace = node
+		cert.Name = strings.TrimPrefix(name, "pki/")
+		certs = append(certs, cert)
+	}
+	return certs, nil
+}
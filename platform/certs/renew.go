@@ -0,0 +1,347 @@
+package certs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"adhar-io/adhar/api/v1alpha1"
+	"adhar-io/adhar/globals"
+	"adhar-io/adhar/platform/domain"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// controllerManager is the Deployment serving the platform webhooks.
+var controllerManager = client.ObjectKey{Namespace: globals.AdharSystemNamespace, Name: "adhar-controller-manager"}
+
+// restartedAtAnnotation is the pod template annotation `kubectl rollout
+// restart` sets.
+const restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// Result reports what a renewal or rotation changed.
+type Result struct {
+	Renewed []string `json:"renewed,omitempty"`
+	// Restarted lists namespace/Kind/name of workloads restarted to pick up
+	// new certificate material.
+	Restarted []string `json:"restarted,omitempty"`
+	// Skipped lists certificates that cannot be renewed here, with the reason.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+func (r *Result) merge(other Result) {
+	r.Renewed = append(r.Renewed, other.Renewed...)
+	r.Restarted = append(r.Restarted, other.Restarted...)
+	r.Skipped = append(r.Skipped, other.Skipped...)
+}
+
+// Renew renews certs. The platform certificate copies share one key pair and
+// are renewed together, as are the kubeadm certificates of a node. Kubeadm
+// nodes are renewed one at a time, each back to healthy before the next.
+func Renew(ctx context.Context, c client.Client, certs []Certificate, kubeadm *KubeadmTarget) (Result, error) {
+	var result Result
+	done := map[string]bool{}
+	for _, cert := range certs {
+		switch cert.Source {
+		case SourceCertManager:
+			if err := triggerCertManagerRenewal(ctx, c, cert.Namespace, cert.Name); err != nil {
+				return result, err
+			}
+			result.Renewed = append(result.Renewed, cert.ID())
+
+		case SourcePlatform:
+			if done[SourcePlatform] {
+				continue
+			}
+			done[SourcePlatform] = true
+			r, err := renewPlatformCertificate(ctx, c)
+			if err != nil {
+				return result, err
+			}
+			result.merge(r)
+
+		case SourceWebhook:
+			r, err := renewWebhookCertificate(ctx, c)
+			if err != nil {
+				return result, err
+			}
+			result.merge(r)
+
+		case SourceKubeadm:
+			if cert.IsCA {
+				result.Skipped = append(result.Skipped, cert.ID()+": kubeadm does not renew CAs")
+				continue
+			}
+			if kubeadm == nil {
+				result.Skipped = append(result.Skipped, cert.ID()+": no SSH access to the control plane")
+				continue
+			}
+			if done[SourceKubeadm+"/"+cert.Namespace] {
+				continue
+			}
+			done[SourceKubeadm+"/"+cert.Namespace] = true
+			if err := kubeadm.Renew(ctx, cert.Namespace); err != nil {
+				return result, err
+			}
+			result.Renewed = append(result.Renewed, SourceKubeadm+"/"+cert.Namespace+"/*")
+		}
+	}
+	return result, nil
+}
+
+// RotateCA replaces the self-signed platform certificate, which is also the
+// platform CA, with one on a new key for the same SANs.
+func RotateCA(ctx context.Context, c client.Client) (Result, error) {
+	cert, _, err := PlatformCertificate(ctx, c)
+	if err != nil {
+		return Result{}, err
+	}
+	parsed, err := ParsePEM(cert)
+	if err != nil {
+		return Result{}, fmt.Errorf("parsing platform certificate: %w", err)
+	}
+	newCert, newKey, err := domain.CreateSelfSignedCertificate(parsed.DNSNames)
+	if err != nil {
+		return Result{}, err
+	}
+	return distributePlatformCertificate(ctx, c, newCert, newKey)
+}
+
+// renewPlatformCertificate re-signs the platform certificate with its
+// current key, so existing trust in it carries over.
+func renewPlatformCertificate(ctx context.Context, c client.Client) (Result, error) {
+	cert, key, err := PlatformCertificate(ctx, c)
+	if err != nil {
+		return Result{}, err
+	}
+	renewed, err := domain.ResignSelfSignedCertificate(cert, key)
+	if err != nil {
+		return Result{}, fmt.Errorf("renewing platform certificate: %w", err)
+	}
+	return distributePlatformCertificate(ctx, c, renewed, key)
+}
+
+// PlatformCertificate returns the certificate and key of the first platform
+// Secret found.
+func PlatformCertificate(ctx context.Context, c client.Client) ([]byte, []byte, error) {
+	for _, key := range PlatformSecrets {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, key, secret); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return nil, nil, fmt.Errorf("getting secret %s: %w", key, err)
+		}
+		if len(secret.Data[corev1.TLSCertKey]) > 0 && len(secret.Data[corev1.TLSPrivateKeyKey]) > 0 {
+			return secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey], nil
+		}
+	}
+	return nil, nil, fmt.Errorf("no self-signed platform certificate found (is the platform using cert-manager?)")
+}
+
+// distributePlatformCertificate writes cert and key to every copy of the
+// platform certificate and the trust bundles derived from it, then restarts
+// the workloads that mount them: they read the CA at startup.
+func distributePlatformCertificate(ctx context.Context, c client.Client, cert, key []byte) (Result, error) {
+	var result Result
+	var updated []client.ObjectKey
+	for _, k := range PlatformSecrets {
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, k, secret); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return result, fmt.Errorf("getting secret %s: %w", k, err)
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[corev1.TLSCertKey] = cert
+		secret.Data[corev1.TLSPrivateKeyKey] = key
+		if err := c.Update(ctx, secret); err != nil {
+			return result, fmt.Errorf("updating secret %s: %w", k, err)
+		}
+		updated = append(updated, k)
+		result.Renewed = append(result.Renewed, SourcePlatform+"/"+k.String())
+	}
+
+	bundle := &corev1.Secret{}
+	if err := c.Get(ctx, caBundleSecret, bundle); err == nil {
+		if bundle.Data == nil {
+			bundle.Data = map[string][]byte{}
+		}
+		bundle.Data[globals.SelfSignedCertCMKeyName] = cert
+		if err := c.Update(ctx, bundle); err != nil {
+			return result, fmt.Errorf("updating CA bundle %s: %w", caBundleSecret, err)
+		}
+		updated = append(updated, caBundleSecret)
+	} else if !k8serrors.IsNotFound(err) {
+		return result, fmt.Errorf("getting CA bundle %s: %w", caBundleSecret, err)
+	}
+
+	// AdharPlatform carries the CA for templating; keep it current so
+	// `adhar up` does not see a configuration change.
+	var platforms v1alpha1.AdharPlatformList
+	if err := c.List(ctx, &platforms); err != nil {
+		return result, fmt.Errorf("listing platforms: %w", err)
+	}
+	for i := range platforms.Items {
+		p := &platforms.Items[i]
+		if p.Spec.BuildCustomization.SelfSignedCert == "" {
+			continue
+		}
+		p.Spec.BuildCustomization.SelfSignedCert = string(cert)
+		if err := c.Update(ctx, p); err != nil {
+			return result, fmt.Errorf("updating platform %s: %w", p.Name, err)
+		}
+	}
+
+	restarted, err := restartSecretConsumers(ctx, c, updated)
+	result.Restarted = restarted
+	return result, err
+}
+
+// renewWebhookCertificate deletes the webhook serving certificate and
+// restarts the controller manager, which issues a new one on startup and
+// re-registers its CA bundle with the webhook configurations.
+func renewWebhookCertificate(ctx context.Context, c client.Client) (Result, error) {
+	id := SourceWebhook + "/" + webhookSecret.String()
+	deploy := &appsv1.Deployment{}
+	if err := c.Get(ctx, controllerManager, deploy); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return Result{Skipped: []string{id + ": the controller manager that issues it is not deployed"}}, nil
+		}
+		return Result{}, fmt.Errorf("getting controller manager: %w", err)
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: webhookSecret.Namespace, Name: webhookSecret.Name}}
+	if err := c.Delete(ctx, secret); err != nil && !k8serrors.IsNotFound(err) {
+		return Result{}, fmt.Errorf("deleting webhook certificate: %w", err)
+	}
+	if err := restart(ctx, c, deploy, &deploy.Spec.Template); err != nil {
+		return Result{}, err
+	}
+	return Result{Renewed: []string{id}, Restarted: []string{workloadName(deploy)}}, nil
+}
+
+// triggerCertManagerRenewal asks cert-manager to reissue a Certificate now,
+// the way `cmctl renew` does.
+func triggerCertManagerRenewal(ctx context.Context, c client.Client, namespace, name string) error {
+	cert := &unstructured.Unstructured{}
+	cert.SetGroupVersionKind(certificateGVK)
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, cert); err != nil {
+		return fmt.Errorf("getting certificate %s/%s: %w", namespace, name, err)
+	}
+	conditions, _, _ := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	kept := conditions[:0]
+	for _, raw := range conditions {
+		if cond, _ := raw.(map[string]interface{}); cond["type"] != "Issuing" {
+			kept = append(kept, raw)
+		}
+	}
+	kept = append(kept, map[string]interface{}{
+		"type":               "Issuing",
+		"status":             "True",
+		"reason":             "ManuallyTriggered",
+		"message":            "Certificate re-issuance manually triggered",
+		"lastTransitionTime": time.Now().UTC().Format(time.RFC3339),
+	})
+	if err := unstructured.SetNestedSlice(cert.Object, kept, "status", "conditions"); err != nil {
+		return err
+	}
+	if err := c.Status().Update(ctx, cert); err != nil {
+		return fmt.Errorf("triggering renewal of certificate %s/%s: %w", namespace, name, err)
+	}
+	return nil
+}
+
+// restartSecretConsumers rolls the Deployments, StatefulSets and DaemonSets
+// that mount any of secrets.
+func restartSecretConsumers(ctx context.Context, c client.Client, secrets []client.ObjectKey) ([]string, error) {
+	mounts := func(namespace string, spec *corev1.PodTemplateSpec) bool {
+		for _, v := range spec.Spec.Volumes {
+			if v.Secret == nil {
+				continue
+			}
+			for _, s := range secrets {
+				if s.Namespace == namespace && s.Name == v.Secret.SecretName {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	var restarted []string
+	var deployments appsv1.DeploymentList
+	if err := c.List(ctx, &deployments); err != nil {
+		return restarted, fmt.Errorf("listing deployments: %w", err)
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		if mounts(d.Namespace, &d.Spec.Template) {
+			if err := restart(ctx, c, d, &d.Spec.Template); err != nil {
+				return restarted, err
+			}
+			restarted = append(restarted, workloadName(d))
+		}
+	}
+	var statefulSets appsv1.StatefulSetList
+	if err := c.List(ctx, &statefulSets); err != nil {
+		return restarted, fmt.Errorf("listing statefulsets: %w", err)
+	}
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		if mounts(s.Namespace, &s.Spec.Template) {
+			if err := restart(ctx, c, s, &s.Spec.Template); err != nil {
+				return restarted, err
+			}
+			restarted = append(restarted, workloadName(s))
+		}
+	}
+	var daemonSets appsv1.DaemonSetList
+	if err := c.List(ctx, &daemonSets); err != nil {
+		return restarted, fmt.Errorf("listing daemonsets: %w", err)
+	}
+	for i := range daemonSets.Items {
+		d := &daemonSets.Items[i]
+		if mounts(d.Namespace, &d.Spec.Template) {
+			if err := restart(ctx, c, d, &d.Spec.Template); err != nil {
+				return restarted, err
+			}
+			restarted = append(restarted, workloadName(d))
+		}
+	}
+	return restarted, nil
+}
+
+// restart triggers a rolling restart of obj through its pod template.
+func restart(ctx context.Context, c client.Client, obj client.Object, template *corev1.PodTemplateSpec) error {
+	base := obj.DeepCopyObject().(client.Object)
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	template.Annotations[restartedAtAnnotation] = time.Now().Format(time.RFC3339)
+	if err := c.Patch(ctx, obj, client.MergeFrom(base)); err != nil {
+		return fmt.Errorf("restarting %s: %w", workloadName(obj), err)
+	}
+	return nil
+}
+
+func workloadName(obj client.Object) string {
+	kind := "Deployment"
+	switch obj.(type) {
+	case *appsv1.StatefulSet:
+		kind = "StatefulSet"
+	case *appsv1.DaemonSet:
+		kind = "DaemonSet"
+	}
+	return obj.GetNamespace() + "/" + kind + "/" + obj.GetName()
+}
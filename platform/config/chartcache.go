@@ -0,0 +1,218 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HelmCache locates Helm's repositories.yaml and its downloaded repository
+// indexes, so chart versions can be checked without network access.
+type HelmCache struct {
+	// RepositoryConfig is the path to repositories.yaml.
+	RepositoryConfig string
+	// RepositoryCache is the directory holding <name>-index.yaml files.
+	RepositoryCache string
+}
+
+// resolve fills unset paths the way Helm does: HELM_REPOSITORY_CONFIG and
+// HELM_REPOSITORY_CACHE first, then Helm's config and cache homes.
+func (h HelmCache) resolve() HelmCache {
+	if h.RepositoryConfig == "" {
+		h.RepositoryConfig = os.Getenv("HELM_REPOSITORY_CONFIG")
+	}
+	if h.RepositoryConfig == "" {
+		h.RepositoryConfig = helmPath("HELM_CONFIG_HOME", "XDG_CONFIG_HOME", helmConfigBase(runtime.GOOS), "repositories.yaml")
+	}
+	if h.RepositoryCache == "" {
+		h.RepositoryCache = os.Getenv("HELM_REPOSITORY_CACHE")
+	}
+	if h.RepositoryCache == "" {
+		h.RepositoryCache = helmPath("HELM_CACHE_HOME", "XDG_CACHE_HOME", helmCacheBase(runtime.GOOS), "repository")
+	}
+	return h
+}
+
+// helmPath follows Helm's helmpath rules: the HELM_*_HOME variable names the
+// Helm directory itself; otherwise "helm" is joined to the XDG variable, or
+// to the platform default base when that is unset too.
+func helmPath(helmVar, xdgVar, defaultBase string, elem ...string) string {
+	home := os.Getenv(helmVar)
+	if home == "" {
+		base := os.Getenv(xdgVar)
+		if base == "" {
+			base = defaultBase
+		}
+		home = filepath.Join(base, "helm")
+	}
+	return filepath.Join(append([]string{home}, elem...)...)
+}
+
+// helmConfigBase is Helm's default config base for goos.
+func helmConfigBase(goos string) string {
+	home, _ := os.UserHomeDir()
+	switch goos {
+	case "darwin":
+		return filepath.Join(home, "Library", "Preferences")
+	case "windows":
+		return os.Getenv("APPDATA")
+	default:
+		return filepath.Join(home, ".config")
+	}
+}
+
+// helmCacheBase is Helm's default cache base for goos.
+func helmCacheBase(goos string) string {
+	home, _ := os.UserHomeDir()
+	switch goos {
+	case "darwin":
+		return filepath.Join(home, "Library", "Caches")
+	case "windows":
+		return os.Getenv("TEMP")
+	default:
+		return filepath.Join(home, ".cache")
+	}
+}
+
+// repositories maps normalized repository URLs to their Helm repo names.
+func (h HelmCache) repositories() map[string]string {
+	data, err := os.ReadFile(h.RepositoryConfig)
+	if err != nil {
+		return nil
+	}
+	var file struct {
+		Repositories []struct {
+			Name string `yaml:"name"`
+			URL  string `yaml:"url"`
+		} `yaml:"repositories"`
+	}
+	if yaml.Unmarshal(data, &file) != nil {
+		return nil
+	}
+	repos := map[string]string{}
+	for _, r := range file.Repositories {
+		repos[strings.TrimSuffix(r.URL, "/")] = r.Name
+	}
+	return repos
+}
+
+// chartVersions reads the cached index of repo and returns the versions of
+// each chart in it.
+func (h HelmCache) chartVersions(repo string) (map[string][]string, error) {
+	data, err := os.ReadFile(filepath.Join(h.RepositoryCache, repo+"-index.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var index struct {
+		Entries map[string][]struct {
+			Version string `yaml:"version"`
+		} `yaml:"entries"`
+	}
+	if err := yaml.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("parse %s index: %w", repo, err)
+	}
+	versions := make(map[string][]string, len(index.Entries))
+	for name, entries := range index.Entries {
+		for _, e := range entries {
+			versions[name] = append(versions[name], e.Version)
+		}
+	}
+	return versions, nil
+}
+
+type chartRef struct {
+	path  []string
+	chart ChartConfig
+}
+
+// charts lists every chart referenced by templates and environments.
+func (c *Config) charts() []chartRef {
+	var refs []chartRef
+	collect := func(prefix []string, services map[string]ServiceConfig, addons []AddonConfig) {
+		for _, name := range sortedKeys(services) {
+			refs = append(refs, chartRef{path: append(append([]string{}, prefix...), "coreServices", name, "chart", "version"), chart: services[name].Chart})
+		}
+		for i, a := range addons {
+			refs = append(refs, chartRef{path: append(append([]string{}, prefix...), "addons", fmt.Sprint(i), "chart", "version"), chart: a.Chart})
+		}
+	}
+	for _, name := range sortedKeys(c.EnvironmentTemplates) {
+		t := c.EnvironmentTemplates[name]
+		collect([]string{"environmentTemplates", name}, t.CoreServices, t.Addons)
+	}
+	for _, name := range sortedKeys(c.Environments) {
+		e := c.Environments[name]
+		collect([]string{"environments", name}, e.CoreServices, e.Addons)
+	}
+	return refs
+}
+
+// checkCharts verifies each chart version exists in the offline Helm index
+// cache. Repositories that are not cached are reported once as a warning,
+// since their versions cannot be checked without network access.
+func (l *linter) checkCharts(cfg *Config, cache HelmCache) {
+	refs := cfg.charts()
+	if len(refs) == 0 {
+		return
+	}
+	cache = cache.resolve()
+	repos := cache.repositories()
+	if len(repos) == 0 {
+		l.add(SeverityWarning, "chart-cache", nil,
+			fmt.Sprintf("chart versions not checked: no Helm repositories configured in %s", cache.RepositoryConfig))
+		return
+	}
+	indexes := map[string]map[string][]string{}
+	uncached := map[string]bool{}
+
+	for _, ref := range refs {
+		url := strings.TrimSuffix(ref.chart.RepoURL, "/")
+		if url == "" || ref.chart.Version == "" || strings.HasPrefix(url, "oci://") {
+			continue
+		}
+		if uncached[url] {
+			continue
+		}
+		versions, ok := indexes[url]
+		if !ok {
+			name, known := repos[url]
+			var err error
+			if known {
+				versions, err = cache.chartVersions(name)
+			}
+			if !known || err != nil {
+				uncached[url] = true
+				l.add(SeverityWarning, "chart-cache", ref.path,
+					fmt.Sprintf("repository %s is not in the local Helm cache; run `helm repo add` and `helm repo update` to check chart versions offline", url))
+				continue
+			}
+			indexes[url] = versions
+		}
+		available, found := versions[ref.chart.Name]
+		if !found {
+			l.add(SeverityError, "chart-version", ref.path,
+				fmt.Sprintf("chart %s not found in repository %s", ref.chart.Name, url))
+			continue
+		}
+		if !containsString(available, ref.chart.Version) {
+			msg := fmt.Sprintf("version %s of chart %s not found in repository %s", ref.chart.Version, ref.chart.Name, url)
+			if len(available) > 0 {
+				msg += fmt.Sprintf(" (latest cached: %s)", available[0])
+			}
+			l.add(SeverityError, "chart-version", ref.path, msg)
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s || strings.TrimPrefix(v, "v") == strings.TrimPrefix(s, "v") {
+			return true
+		}
+	}
+	return false
+}
@@ -85,6 +85,7 @@ type EnvironmentConfig struct {
 	ClusterConfig []KeyValueConfig         `mapstructure:"clusterConfig" json:"clusterConfig"`
 	CoreServices  map[string]ServiceConfig `mapstructure:"coreServices" json:"coreServices,omitempty"`
 	Addons        []AddonConfig            `mapstructure:"addons" json:"addons,omitempty"`
+	DNS           *DNSConfig               `mapstructure:"dns" json:"dns,omitempty"`
 }
 
 // EnvironmentTemplateConfig holds environment template configuration
@@ -92,6 +93,25 @@ type EnvironmentTemplateConfig struct {
 	ClusterConfig []KeyValueConfig         `mapstructure:"clusterConfig" json:"clusterConfig"`
 	CoreServices  map[string]ServiceConfig `mapstructure:"coreServices" json:"coreServices"`
 	Addons        []AddonConfig            `mapstructure:"addons" json:"addons,omitempty"`
+	DNS           *DNSConfig               `mapstructure:"dns" json:"dns,omitempty"`
+}
+
+// DNSConfig selects the DNS provider that publishes an environment's public
+// records and answers ACME DNS-01 challenges
+type DNSConfig struct {
+	Provider          string             `mapstructure:"provider" json:"provider"`
+	Zone              string             `mapstructure:"zone" json:"zone,omitempty"`
+	CredentialsSecret string             `mapstructure:"credentialsSecret" json:"credentialsSecret,omitempty"`
+	Config            map[string]string  `mapstructure:"config" json:"config,omitempty"`
+	SplitHorizon      SplitHorizonConfig `mapstructure:"splitHorizon" json:"splitHorizon,omitempty"`
+}
+
+// SplitHorizonConfig serves the environment's domain from CoreDNS inside the
+// cluster alongside the public records
+type SplitHorizonConfig struct {
+	Enabled      bool   `mapstructure:"enabled" json:"enabled"`
+	InternalZone string `mapstructure:"internalZone" json:"internalZone,omitempty"`
+	Target       string `mapstructure:"target" json:"target,omitempty"`
 }
 
 // KeyValueConfig holds key-value configuration pairs
@@ -132,6 +152,7 @@ type ResolvedEnvironmentConfig struct {
 	ResolvedCoreServices  *ResolvedCoreServices `json:"resolvedCoreServices,omitempty"`
 	ResolvedAddons        []AddonConfig         `json:"resolvedAddons,omitempty"`
 	GlobalSettings        *GlobalSettings       `json:"globalSettings,omitempty"`
+	DNS                   *DNSConfig            `json:"dns,omitempty"`
 	// ProviderConfig is the full provider block from `providers.<name>` for the
 	// resolved provider. Without it the provisioning path only sees region +
 	// cluster-config key/values, silently dropping credentials (token) and the
@@ -211,6 +232,11 @@ func LoadConfig(configFile string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// Credentials in an exported config are ${VAR} references
+	for name, p := range config.Providers {
+		config.Providers[name] = ExpandProviderEnv(p)
+	}
+
 	// If no config file found and no providers configured, set up Kind as default
 	if !configFound && len(config.Providers) == 0 {
 		config = getDefaultKindConfig()
@@ -638,6 +664,14 @@ func (c *Config) resolveEnvironment(envName string, envConfig EnvironmentConfig)
 		}
 	}
 
+	// Resolve DNS - environment replaces the template's provider entirely
+	resolved.DNS = envConfig.DNS
+	if resolved.DNS == nil && envConfig.Template != "" {
+		if template, exists := c.EnvironmentTemplates[envConfig.Template]; exists {
+			resolved.DNS = template.DNS
+		}
+	}
+
 	// Set global settings
 	resolved.GlobalSettings = &GlobalSettings{
 		AdharContext: c.GlobalSettings.AdharContext,
@@ -0,0 +1,35 @@
+package config
+
+import "testing"
+
+func TestResolveEnvironmentDNS(t *testing.T) {
+	templateDNS := &DNSConfig{Provider: "cloudflare"}
+	cfg := &Config{
+		Providers: map[string]ConfigProviderConfig{"aws": {Type: "aws"}},
+		EnvironmentTemplates: map[string]EnvironmentTemplateConfig{
+			"prod-defaults": {DNS: templateDNS},
+		},
+		Environments: map[string]EnvironmentConfig{
+			"staging": {Template: "prod-defaults"},
+			"production": {Template: "prod-defaults", DNS: &DNSConfig{
+				Provider:     "route53",
+				Config:       map[string]string{"region": "us-east-1"},
+				SplitHorizon: SplitHorizonConfig{Enabled: true},
+			}},
+			"dev": {},
+		},
+	}
+	if err := cfg.ResolveEnvironments(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := cfg.ResolvedEnvironments["staging"].DNS; got != templateDNS {
+		t.Errorf("staging DNS = %+v, want the template's", got)
+	}
+	if got := cfg.ResolvedEnvironments["production"].DNS; got == nil || got.Provider != "route53" || !got.SplitHorizon.Enabled {
+		t.Errorf("production DNS = %+v, want the environment's route53 settings", got)
+	}
+	if got := cfg.ResolvedEnvironments["dev"].DNS; got != nil {
+		t.Errorf("dev DNS = %+v, want none", got)
+	}
+}
@@ -0,0 +1,391 @@
+package config
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"gopkg.in/yaml.v3"
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// DefaultSchema is config.schema.json from the repository root, embedded so
+// validation works without a checkout. Keep the two files identical.
+//
+//go:embed config.schema.json
+var DefaultSchema []byte
+
+// Diagnostic severities.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic is a single problem found in a configuration document, located
+// at the line and column of the offending YAML node when it can be found.
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Path     string `json:"path,omitempty"`
+	Message  string `json:"message"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+func (d Diagnostic) String() string {
+	loc := ""
+	if d.Line > 0 {
+		loc = fmt.Sprintf("%d:%d: ", d.Line, d.Column)
+	}
+	if d.Path != "" {
+		return fmt.Sprintf("%s%s: %s [%s]", loc, d.Path, d.Message, d.Rule)
+	}
+	return fmt.Sprintf("%s%s [%s]", loc, d.Message, d.Rule)
+}
+
+// LintOptions tunes ValidateDocument.
+type LintOptions struct {
+	// Schema overrides the embedded JSON Schema.
+	Schema []byte
+	// SkipCharts disables the offline chart version check.
+	SkipCharts bool
+	// ChartCache locates Helm's repository config and index cache; the zero
+	// value uses Helm's own defaults and environment variables.
+	ChartCache HelmCache
+}
+
+// ValidateFile reads path and runs ValidateDocument on it.
+func ValidateFile(path string, opts LintOptions) ([]Diagnostic, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateDocument(data, opts)
+}
+
+// ValidateDocument runs every check on a config.yaml document and returns all
+// problems at once: YAML syntax, JSON Schema, the SchemaValidator rules and
+// the cross-reference checks that need the whole document. The error return
+// is reserved for problems with the validator itself, such as a bad schema.
+func ValidateDocument(data []byte, opts LintOptions) ([]Diagnostic, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return []Diagnostic{syntaxDiagnostic(err)}, nil
+	}
+	if len(root.Content) == 0 {
+		return []Diagnostic{{Severity: SeverityError, Rule: "syntax", Message: "document is empty"}}, nil
+	}
+	l := &linter{root: root.Content[0]}
+
+	if err := l.checkSchema(data, opts.Schema); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]interface{}
+	if err := root.Decode(&raw); err != nil {
+		l.add(SeverityError, "syntax", nil, err.Error())
+		return l.diags, nil
+	}
+	var cfg Config
+	dec, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{WeaklyTypedInput: true, Result: &cfg})
+	if err != nil {
+		return nil, err
+	}
+	if err := dec.Decode(raw); err != nil {
+		// The schema diagnostics already explain type mismatches.
+		l.sort()
+		return l.diags, nil
+	}
+
+	if err := NewSchemaValidator().ValidateConfig(&cfg); err != nil {
+		if verrs, ok := err.(ValidationErrors); ok {
+			for _, ve := range verrs {
+				l.add(SeverityError, "config", strings.Split(ve.Field, "."), ve.Message)
+			}
+		}
+	}
+	l.checkReferences(&cfg)
+	l.checkPrimary(&cfg)
+	l.checkCIDRs(&cfg)
+	if !opts.SkipCharts {
+		l.checkCharts(&cfg, opts.ChartCache)
+	}
+	l.sort()
+	return l.diags, nil
+}
+
+// HasErrors reports whether any diagnostic is an error rather than a warning.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+type linter struct {
+	root  *yaml.Node
+	diags []Diagnostic
+}
+
+func (l *linter) add(severity, rule string, path []string, msg string) {
+	d := Diagnostic{Severity: severity, Rule: rule, Path: strings.Join(path, "."), Message: msg}
+	if n := locate(l.root, path); n != nil {
+		d.Line, d.Column = n.Line, n.Column
+	}
+	// The SchemaValidator and the JSON Schema often agree; report once.
+	for _, prev := range l.diags {
+		if prev.Path == d.Path && prev.Message == d.Message {
+			return
+		}
+	}
+	l.diags = append(l.diags, d)
+}
+
+func (l *linter) sort() {
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		if l.diags[i].Line != l.diags[j].Line {
+			return l.diags[i].Line < l.diags[j].Line
+		}
+		return l.diags[i].Column < l.diags[j].Column
+	})
+}
+
+var yamlLineRE = regexp.MustCompile(`line (\d+)`)
+
+func syntaxDiagnostic(err error) Diagnostic {
+	d := Diagnostic{Severity: SeverityError, Rule: "syntax", Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+	if m := yamlLineRE.FindStringSubmatch(err.Error()); m != nil {
+		d.Line, _ = strconv.Atoi(m[1])
+		d.Column = 1
+	}
+	return d
+}
+
+// locate walks path (mapping keys and sequence indexes) from n and returns
+// the deepest node reached, so a diagnostic for a missing key still points
+// at its parent.
+func locate(n *yaml.Node, path []string) *yaml.Node {
+	for _, seg := range path {
+		next := child(n, seg)
+		if next == nil {
+			return n
+		}
+		n = next
+	}
+	return n
+}
+
+func child(n *yaml.Node, seg string) *yaml.Node {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == seg {
+				return n.Content[i+1]
+			}
+		}
+	case yaml.SequenceNode:
+		if i, err := strconv.Atoi(seg); err == nil && i >= 0 && i < len(n.Content) {
+			return n.Content[i]
+		}
+	}
+	return nil
+}
+
+// checkSchema validates the document against the JSON Schema and reports
+// each leaf error at its instance location.
+func (l *linter) checkSchema(data, schemaDoc []byte) error {
+	if schemaDoc == nil {
+		schemaDoc = DefaultSchema
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schemaDoc))
+	if err != nil {
+		return fmt.Errorf("parse schema: %w", err)
+	}
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+	if err := c.AddResource("config.schema.json", doc); err != nil {
+		return fmt.Errorf("load schema: %w", err)
+	}
+	sch, err := c.Compile("config.schema.json")
+	if err != nil {
+		return fmt.Errorf("compile schema: %w", err)
+	}
+
+	js, err := sigsyaml.YAMLToJSON(data)
+	if err != nil {
+		l.add(SeverityError, "syntax", nil, err.Error())
+		return nil
+	}
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(js))
+	if err != nil {
+		l.add(SeverityError, "syntax", nil, err.Error())
+		return nil
+	}
+	verr, ok := sch.Validate(inst).(*jsonschema.ValidationError)
+	if !ok {
+		return nil
+	}
+	p := message.NewPrinter(language.English)
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, c := range e.Causes {
+				walk(c)
+			}
+			return
+		}
+		path := e.InstanceLocation
+		// Point unknown keys at the key itself rather than its parent.
+		if ap, ok := e.ErrorKind.(*kind.AdditionalProperties); ok && len(ap.Properties) > 0 {
+			path = append(append([]string{}, path...), ap.Properties[0])
+		}
+		l.add(SeverityError, "schema", path, e.ErrorKind.LocalizedString(p))
+	}
+	walk(verr)
+	return nil
+}
+
+// checkReferences reports templates and providers that are referenced but
+// not defined.
+func (l *linter) checkReferences(cfg *Config) {
+	for _, name := range sortedKeys(cfg.Environments) {
+		env := cfg.Environments[name]
+		if env.Template != "" {
+			if _, ok := cfg.EnvironmentTemplates[env.Template]; !ok {
+				l.add(SeverityError, "template-ref", []string{"environments", name, "template"},
+					fmt.Sprintf("template %q is not defined in environmentTemplates", env.Template))
+			}
+		}
+		if env.Provider != "" {
+			if _, ok := cfg.Providers[env.Provider]; !ok {
+				l.add(SeverityError, "provider-ref", []string{"environments", name, "provider"},
+					fmt.Sprintf("provider %q is not defined in providers", env.Provider))
+			}
+		}
+	}
+	for key, name := range map[string]string{
+		"productionProvider":    cfg.GlobalSettings.ProductionProvider,
+		"nonProductionProvider": cfg.GlobalSettings.NonProductionProvider,
+	} {
+		if name == "" {
+			continue
+		}
+		if _, ok := cfg.Providers[name]; !ok {
+			l.add(SeverityError, "provider-ref", []string{"globalSettings", key},
+				fmt.Sprintf("provider %q is not defined in providers", name))
+		}
+	}
+}
+
+// checkPrimary requires exactly one primary provider. A lone provider is
+// implicitly primary, which is allowed but worth making explicit.
+func (l *linter) checkPrimary(cfg *Config) {
+	var primaries []string
+	for _, name := range sortedKeys(cfg.Providers) {
+		if cfg.Providers[name].Primary {
+			primaries = append(primaries, name)
+		}
+	}
+	switch {
+	case len(primaries) > 1:
+		for _, name := range primaries[1:] {
+			l.add(SeverityError, "primary-provider", []string{"providers", name, "primary"},
+				fmt.Sprintf("only one provider can be primary; %s is already primary", primaries[0]))
+		}
+	case len(primaries) == 0 && len(cfg.Providers) == 1:
+		name := sortedKeys(cfg.Providers)[0]
+		l.add(SeverityWarning, "primary-provider", []string{"providers", name},
+			"provider is implicitly primary; set primary: true to make this explicit")
+	case len(primaries) == 0 && len(cfg.Providers) > 1:
+		l.add(SeverityError, "primary-provider", []string{"providers"}, "exactly one provider must be marked primary: true")
+	}
+}
+
+type envCIDR struct {
+	env, provider string
+	path          []string
+	net           *net.IPNet
+}
+
+// checkCIDRs reports CIDR values in environment cluster config (including
+// values inherited from templates) that overlap between environments. Only
+// environments on the same provider are errors; across providers the
+// clusters are isolated unless peered, so the overlap is a warning.
+func (l *linter) checkCIDRs(cfg *Config) {
+	var all []envCIDR
+	for _, name := range sortedKeys(cfg.Environments) {
+		env := cfg.Environments[name]
+		provider := cfg.environmentProvider(env)
+		// Environment values override template values with the same key.
+		entries := map[string][]string{}
+		values := map[string]string{}
+		if tpl, ok := cfg.EnvironmentTemplates[env.Template]; ok && env.Template != "" {
+			for i, kv := range tpl.ClusterConfig {
+				entries[kv.Key] = []string{"environmentTemplates", env.Template, "clusterConfig", strconv.Itoa(i), "value"}
+				values[kv.Key] = kv.Value
+			}
+		}
+		for i, kv := range env.ClusterConfig {
+			entries[kv.Key] = []string{"environments", name, "clusterConfig", strconv.Itoa(i), "value"}
+			values[kv.Key] = kv.Value
+		}
+		for _, key := range sortedKeys(entries) {
+			if !strings.Contains(strings.ToLower(key), "cidr") {
+				continue
+			}
+			_, n, err := net.ParseCIDR(values[key])
+			if err != nil {
+				l.add(SeverityError, "cidr", entries[key], fmt.Sprintf("%s is not a valid CIDR: %q", key, values[key]))
+				continue
+			}
+			all = append(all, envCIDR{env: name, provider: provider, path: entries[key], net: n})
+		}
+	}
+	for i := range all {
+		for j := i + 1; j < len(all); j++ {
+			a, b := all[i], all[j]
+			if a.env == b.env || !(a.net.Contains(b.net.IP) || b.net.Contains(a.net.IP)) {
+				continue
+			}
+			severity := SeverityError
+			if a.provider != b.provider {
+				severity = SeverityWarning
+			}
+			l.add(severity, "cidr-overlap", b.path,
+				fmt.Sprintf("%s in environment %s overlaps %s in environment %s", b.net, b.env, a.net, a.env))
+		}
+	}
+}
+
+// environmentProvider mirrors the provider resolution in resolveEnvironment.
+func (c *Config) environmentProvider(env EnvironmentConfig) string {
+	if env.Provider != "" {
+		return env.Provider
+	}
+	if p, err := c.GetPrimaryProvider(); err == nil {
+		return p
+	}
+	return ""
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const lintDoc = `globalSettings:
+  adharContext: adhar-mgmt
+  defaultHost: cloud.example.com
+  defaultHttpPort: 80
+  defaultHttpsPort: 8443
+  email: ops@example.com
+  productionProvider: aws
+providers:
+  gcp:
+    type: gcp
+    region: asia-south1
+    primary: true
+  digitalocean:
+    type: digitalocean
+    region: blr1
+    primary: true
+    colour: blue
+environmentTemplates:
+  base:
+    clusterConfig:
+      - key: podCIDR
+        value: 10.244.0.0/16
+    coreServices:
+      argocd:
+        chart:
+          repoURL: https://argoproj.github.io/argo-helm
+          name: argo-cd
+          version: 6.11.1
+      cilium:
+        chart:
+          repoURL: https://helm.cilium.io/
+          name: cilium
+          version: 9.9.9
+environments:
+  dev:
+    type: non-production
+    provider: gcp
+    template: base
+    clusterConfig:
+      - key: name
+        value: adhar-dev
+  prod:
+    type: production
+    provider: gcp
+    template: missing
+    clusterConfig:
+      - key: podCIDR
+        value: 10.244.128.0/17
+`
+
+func TestValidateDocument(t *testing.T) {
+	dir := t.TempDir()
+	repoConfig := filepath.Join(dir, "repositories.yaml")
+	writeFile(t, repoConfig, "repositories:\n- name: argo\n  url: https://argoproj.github.io/argo-helm\n- name: cilium\n  url: https://helm.cilium.io\n")
+	writeFile(t, filepath.Join(dir, "argo-index.yaml"), "entries:\n  argo-cd:\n  - version: 6.11.1\n")
+	writeFile(t, filepath.Join(dir, "cilium-index.yaml"), "entries:\n  cilium:\n  - version: 1.15.7\n")
+
+	diags, err := ValidateDocument([]byte(lintDoc), LintOptions{ChartCache: HelmCache{RepositoryConfig: repoConfig, RepositoryCache: dir}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{ // rule -> line
+		"schema":           17, // providers.digitalocean.colour
+		"primary-provider": 12,
+		"provider-ref":     7,
+		"template-ref":     45,
+		"cidr-overlap":     48,
+		"chart-version":    33,
+	}
+	for _, d := range diags {
+		if line, ok := want[d.Rule]; ok && d.Line == line {
+			delete(want, d.Rule)
+		}
+	}
+	if len(want) > 0 {
+		var got []string
+		for _, d := range diags {
+			got = append(got, d.String())
+		}
+		t.Errorf("missing diagnostics %v in:\n%s", want, strings.Join(got, "\n"))
+	}
+	if !HasErrors(diags) {
+		t.Errorf("expected errors")
+	}
+
+	if d, _ := ValidateDocument([]byte("providers: [\n"), LintOptions{}); len(d) != 1 || d[0].Rule != "syntax" || d[0].Line == 0 {
+		t.Errorf("syntax error = %+v", d)
+	}
+}
+
+func TestEmbeddedSchemaMatchesRepository(t *testing.T) {
+	root, err := os.ReadFile("../../config.schema.json")
+	if err != nil {
+		t.Skip("repository schema not available")
+	}
+	if !bytes.Equal(root, DefaultSchema) {
+		t.Errorf("platform/config/config.schema.json is out of date; copy it from the repository root")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateDocumentDNS(t *testing.T) {
+	doc := `providers:
+  aws:
+    type: aws
+    region: us-east-1
+environments:
+  prod:
+    type: production
+    template: base
+    clusterConfig:
+      - key: name
+        value: adhar-prod
+    dns:
+      provider: %s
+      config:
+        region: us-east-1
+      splitHorizon:
+        enabled: true
+        internalZone: corp.internal
+`
+	for provider, wantSchemaError := range map[string]bool{"route53": false, "cloudflare": false, "godaddy": true} {
+		diags, err := ValidateDocument([]byte(strings.Replace(doc, "%s", provider, 1)), LintOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var schemaErrors []string
+		for _, d := range diags {
+			if d.Rule == "schema" {
+				schemaErrors = append(schemaErrors, d.String())
+			}
+		}
+		if got := len(schemaErrors) > 0; got != wantSchemaError {
+			t.Errorf("provider %s: schema errors = %v, want errors: %v", provider, schemaErrors, wantSchemaError)
+		}
+	}
+}
+
+func TestHelmCacheResolve(t *testing.T) {
+	for _, v := range []string{"HELM_REPOSITORY_CONFIG", "HELM_REPOSITORY_CACHE", "HELM_CONFIG_HOME", "HELM_CACHE_HOME", "XDG_CONFIG_HOME", "XDG_CACHE_HOME"} {
+		t.Setenv(v, "")
+	}
+	home, _ := os.UserHomeDir()
+
+	if got, want := helmConfigBase("darwin"), filepath.Join(home, "Library", "Preferences"); got != want {
+		t.Errorf("darwin config base = %q, want %q", got, want)
+	}
+	if got, want := helmCacheBase("darwin"), filepath.Join(home, "Library", "Caches"); got != want {
+		t.Errorf("darwin cache base = %q, want %q", got, want)
+	}
+	if got, want := helmConfigBase("linux"), filepath.Join(home, ".config"); got != want {
+		t.Errorf("linux config base = %q, want %q", got, want)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	t.Setenv("XDG_CACHE_HOME", "/xdg/cache")
+	c := HelmCache{}.resolve()
+	if c.RepositoryConfig != filepath.Join("/xdg/config", "helm", "repositories.yaml") || c.RepositoryCache != filepath.Join("/xdg/cache", "helm", "repository") {
+		t.Errorf("XDG paths: got %+v", c)
+	}
+
+	t.Setenv("HELM_CONFIG_HOME", "/helm/config")
+	t.Setenv("HELM_CACHE_HOME", "/helm/cache")
+	c = HelmCache{}.resolve()
+	if c.RepositoryConfig != filepath.Join("/helm/config", "repositories.yaml") || c.RepositoryCache != filepath.Join("/helm/cache", "repository") {
+		t.Errorf("HELM_*_HOME paths: got %+v", c)
+	}
+
+	t.Setenv("HELM_REPOSITORY_CONFIG", "/explicit/repositories.yaml")
+	if c = (HelmCache{}).resolve(); c.RepositoryConfig != "/explicit/repositories.yaml" {
+		t.Errorf("HELM_REPOSITORY_CONFIG ignored: got %+v", c)
+	}
+}
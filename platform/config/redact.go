@@ -0,0 +1,208 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RedactedValue replaces credentials when they are masked rather than turned
+// into environment variable references.
+const RedactedValue = "REDACTED"
+
+// credentialEnvVars are the environment variables each provider reads its
+// credentials from when useEnvironment is set.
+var credentialEnvVars = map[string]map[string]string{
+	"aws": {
+		"accessKeyId":     "AWS_ACCESS_KEY_ID",
+		"secretAccessKey": "AWS_SECRET_ACCESS_KEY",
+		"sessionToken":    "AWS_SESSION_TOKEN",
+	},
+	"azure": {
+		"clientId":     "AZURE_CLIENT_ID",
+		"clientSecret": "AZURE_CLIENT_SECRET",
+	},
+	"gcp": {
+		"serviceAccountKey": "GOOGLE_CREDENTIALS",
+	},
+	"digitalocean": {"token": "DIGITALOCEAN_TOKEN"},
+	"civo":         {"token": "CIVO_TOKEN"},
+}
+
+// sensitiveConfigKeys are substrings that mark a provider `config:` key as a
+// credential.
+var sensitiveConfigKeys = []string{"password", "secret", "token", "apikey", "api_key", "private_key", "privatekey", "access_key"}
+
+// CredentialEnvVar returns the environment variable a provider credential
+// field is read from, falling back to ADHAR_<PROVIDER>_<FIELD>.
+func CredentialEnvVar(providerName, providerType, field string) string {
+	if v, ok := credentialEnvVars[providerType][field]; ok {
+		return v
+	}
+	return "ADHAR_" + envToken(providerName) + "_" + envToken(field)
+}
+
+// RedactProvider returns a copy of p with every credential removed. With
+// envRefs the credentials become ${VAR} references to the variables the
+// provider reads and useEnvironment is enabled; otherwise they are masked.
+// The returned list names the redacted fields.
+func RedactProvider(name string, p ConfigProviderConfig, envRefs bool) (ConfigProviderConfig, []string) {
+	var redacted []string
+	replace := func(field string, value *string) {
+		if *value == "" {
+			return
+		}
+		redacted = append(redacted, field)
+		if envRefs {
+			*value = "${" + CredentialEnvVar(name, p.Type, field) + "}"
+		} else {
+			*value = RedactedValue
+		}
+	}
+	replace("accessKeyId", &p.AccessKeyID)
+	replace("secretAccessKey", &p.SecretAccessKey)
+	replace("sessionToken", &p.SessionToken)
+	replace("clientId", &p.ClientID)
+	replace("clientSecret", &p.ClientSecret)
+	replace("serviceAccountKey", &p.ServiceAccountKey)
+	replace("token", &p.Token)
+
+	if p.Config != nil {
+		var configRedacted []string
+		p.Config, configRedacted = redactMap(name, "config", p.Config, envRefs)
+		redacted = append(redacted, configRedacted...)
+	}
+	if envRefs && len(redacted) > 0 {
+		p.UseEnvironment = true
+	}
+	sort.Strings(redacted)
+	return p, redacted
+}
+
+// envRefPattern matches a whole value that is a ${VAR} reference.
+var envRefPattern = regexp.MustCompile(`^\$\{([A-Za-z_][A-Za-z0-9_]*)\}$`)
+
+// ExpandProviderEnv returns a copy of p with every credential that is a
+// ${VAR} reference, as written by RedactProvider, replaced by the value of
+// the variable. Only whole-value references are expanded, so credentials
+// that merely contain a $ are left alone.
+func ExpandProviderEnv(p ConfigProviderConfig) ConfigProviderConfig {
+	for _, value := range []*string{&p.AccessKeyID, &p.SecretAccessKey, &p.SessionToken, &p.ClientID, &p.ClientSecret, &p.ServiceAccountKey, &p.Token} {
+		*value = expandEnvRef(*value)
+	}
+	if p.Config != nil {
+		p.Config = expandMap(p.Config)
+	}
+	return p
+}
+
+func expandEnvRef(s string) string {
+	if m := envRefPattern.FindStringSubmatch(s); m != nil {
+		return os.Getenv(m[1])
+	}
+	return s
+}
+
+// expandMap deep-copies m, expanding ${VAR} references in string values.
+func expandMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = expandValue(v)
+	}
+	return out
+}
+
+func expandValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return expandEnvRef(val)
+	case map[string]interface{}:
+		return expandMap(val)
+	case []interface{}:
+		items := make([]interface{}, len(val))
+		for i, item := range val {
+			items[i] = expandValue(item)
+		}
+		return items
+	default:
+		return v
+	}
+}
+
+// redactMap deep-copies m, redacting values under sensitive keys.
+func redactMap(provider, prefix string, m map[string]interface{}, envRefs bool) (map[string]interface{}, []string) {
+	out := make(map[string]interface{}, len(m))
+	var redacted []string
+	for k, v := range m {
+		path := prefix + "." + k
+		if s, ok := v.(string); ok && s != "" && isSensitiveKey(k) {
+			redacted = append(redacted, path)
+			if envRefs {
+				out[k] = "${ADHAR_" + envToken(provider) + "_" + envToken(strings.TrimPrefix(path, "config.")) + "}"
+			} else {
+				out[k] = RedactedValue
+			}
+			continue
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			var nested []string
+			out[k], nested = redactMap(provider, path, val, envRefs)
+			redacted = append(redacted, nested...)
+		case []interface{}:
+			items := make([]interface{}, len(val))
+			for i, item := range val {
+				if im, ok := item.(map[string]interface{}); ok {
+					var nested []string
+					items[i], nested = redactMap(provider, fmt.Sprintf("%s.%d", path, i), im, envRefs)
+					redacted = append(redacted, nested...)
+				} else {
+					items[i] = item
+				}
+			}
+			out[k] = items
+		default:
+			out[k] = v
+		}
+	}
+	return out, redacted
+}
+
+func isSensitiveKey(key string) bool {
+	k := strings.ToLower(key)
+	// Paths to credential files are safe to share; the files are not.
+	if strings.HasSuffix(k, "path") || strings.HasSuffix(k, "file") {
+		return false
+	}
+	for _, s := range sensitiveConfigKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// envToken upper-cases s and replaces anything that cannot appear in an
+// environment variable name with an underscore.
+func envToken(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z':
+			b.WriteRune(r - 'a' + 'A')
+		case r >= 'A' && r <= 'Z':
+			// Split camelCase so clientSecret becomes CLIENT_SECRET.
+			if i > 0 && s[i-1] >= 'a' && s[i-1] <= 'z' {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
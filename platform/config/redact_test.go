@@ -0,0 +1,32 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandProviderEnvReversesRedaction(t *testing.T) {
+	p := ConfigProviderConfig{
+		Type:  "civo",
+		Token: "civo-token",
+		Config: map[string]interface{}{
+			"region":   "LON1",
+			"registry": map[string]interface{}{"password": "hunter2"},
+			"note":     "costs $5",
+		},
+	}
+	redacted, _ := RedactProvider("civo", p, true)
+	if redacted.Token != "${CIVO_TOKEN}" {
+		t.Fatalf("token = %q, want a reference", redacted.Token)
+	}
+
+	t.Setenv("CIVO_TOKEN", "civo-token")
+	t.Setenv("ADHAR_CIVO_REGISTRY_PASSWORD", "hunter2")
+	expanded := ExpandProviderEnv(redacted)
+	if expanded.Token != p.Token || !reflect.DeepEqual(expanded.Config, p.Config) {
+		t.Errorf("expanded = %+v, want the original credentials", expanded)
+	}
+	if got := redacted.Config["registry"].(map[string]interface{})["password"]; got != "${ADHAR_CIVO_REGISTRY_PASSWORD}" {
+		t.Errorf("expansion modified its input's config map: %v", got)
+	}
+}
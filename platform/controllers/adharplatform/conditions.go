@@ -1,7 +1,10 @@
 package adharplatform
 
 import (
+	"fmt"
+
 	"adhar-io/adhar/api/v1alpha1"
+	"adhar-io/adhar/platform/notify"
 
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -82,3 +85,33 @@ func syncConditions(resource *v1alpha1.AdharPlatform, failureReason, failureMess
 	}
 	meta.SetStatusCondition(&resource.Status.Conditions, ready)
 }
+
+// conditionEvents returns a notification for every condition whose status
+// differs from before. Conditions appearing for the first time are not
+// reported, so a fresh platform does not announce every component as down.
+func conditionEvents(resource *v1alpha1.AdharPlatform, before []metav1.Condition) []notify.Event {
+	var events []notify.Event
+	for _, c := range resource.Status.Conditions {
+		prev := meta.FindStatusCondition(before, c.Type)
+		if prev == nil || prev.Status == c.Status {
+			continue
+		}
+		severity := notify.SeverityInfo
+		if c.Status != metav1.ConditionTrue {
+			severity = notify.SeverityWarning
+			if c.Type == ConditionReady {
+				severity = notify.SeverityCritical
+			}
+		}
+		events = append(events, notify.Event{
+			Type:     notify.EventPlatformCondition,
+			Severity: severity,
+			Title:    fmt.Sprintf("Platform %s condition %s is now %s", resource.Name, c.Type, c.Status),
+			Message:  c.Message,
+			Kind:     "AdharPlatform",
+			Name:     resource.Name,
+			Reason:   c.Reason,
+		})
+	}
+	return events
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"adhar-io/adhar/api/v1alpha1"
+	"adhar-io/adhar/platform/notify"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -51,3 +52,34 @@ func TestSyncConditions(t *testing.T) {
 	ready = meta.FindStatusCondition(res.Status.Conditions, ConditionReady)
 	assert.Equal(t, metav1.ConditionFalse, ready.Status)
 }
+
+func TestConditionEvents(t *testing.T) {
+	res := &v1alpha1.AdharPlatform{}
+	res.Name = "adhar"
+
+	// Conditions appearing for the first time are not reported.
+	syncConditions(res, "", "")
+	assert.Empty(t, conditionEvents(res, nil))
+
+	before := append([]metav1.Condition(nil), res.Status.Conditions...)
+	res.Status.ArgoCD.Available = true
+	syncConditions(res, "", "")
+	events := conditionEvents(res, before)
+	assert.Len(t, events, 1)
+	assert.Equal(t, notify.EventPlatformCondition, events[0].Type)
+	assert.Equal(t, notify.SeverityInfo, events[0].Severity)
+	assert.Contains(t, events[0].Title, ConditionArgoCDReady)
+
+	// A reconcile failure changes the reason but not the status: no event.
+	before = append([]metav1.Condition(nil), res.Status.Conditions...)
+	syncConditions(res, "CorePackageInstallFailed", "boom")
+	assert.Empty(t, conditionEvents(res, before))
+
+	// ArgoCD going away again is a warning.
+	before = append([]metav1.Condition(nil), res.Status.Conditions...)
+	res.Status.ArgoCD.Available = false
+	syncConditions(res, "", "")
+	events = conditionEvents(res, before)
+	assert.Len(t, events, 1)
+	assert.Equal(t, notify.SeverityWarning, events[0].Severity)
+}
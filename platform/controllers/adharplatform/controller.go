@@ -49,6 +49,7 @@ import (
 
 	"adhar-io/adhar/api/v1alpha1"
 	"adhar-io/adhar/globals"
+	"adhar-io/adhar/platform/notify"
 	"adhar-io/adhar/platform/utils"
 )
 
@@ -78,6 +79,9 @@ type AdharPlatformReconciler struct {
 	TempDir        string
 	StackDir       string // Path to the platform/stack directory on the host filesystem
 	RepoMap        *utils.RepoMap
+	// Notifier publishes condition changes to the configured sinks; nil
+	// disables notifications.
+	Notifier *notify.Notifier
 
 	// lastFailureReason/lastFailureMessage describe the most recent reconcile
 	// failure; they are surfaced on the aggregate Ready condition and cleared
@@ -131,6 +135,9 @@ func (r *AdharPlatformReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		logger.Error(err, "unable to fetch Resource")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	// Objects admitted while the defaulting webhook was off still carry
+	// empty fields.
+	localBuild.Default()
 	r.lastFailureReason, r.lastFailureMessage = "", ""
 	defer r.postProcessReconcile(ctx, req, &localBuild)
 
@@ -784,6 +791,7 @@ func (r *AdharPlatformReconciler) postProcessReconcile(ctx context.Context, req
 	// Retry on conflict: losing this update on the last pass would permanently
 	// drop ControlPlaneApplied/Ready in local mode (no controller remains).
 	resource.Status.ObservedGeneration = resource.GetGeneration()
+	before := append([]metav1.Condition(nil), resource.Status.Conditions...)
 	syncConditions(resource, r.lastFailureReason, r.lastFailureMessage)
 	desired := resource.Status.DeepCopy()
 	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
@@ -795,6 +803,10 @@ func (r *AdharPlatformReconciler) postProcessReconcile(ctx context.Context, req
 		return r.Status().Update(ctx, latest)
 	}); err != nil {
 		logger.Error(err, "Failed to update resource status after reconcile")
+	} else {
+		for _, e := range conditionEvents(resource, before) {
+			r.Notifier.Publish(ctx, e)
+		}
 	}
 
 	logger.Info("Checking if we should shutdown")
@@ -18,6 +18,7 @@ package dataplane
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -25,6 +26,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"adhar-io/adhar/api/v1alpha1"
+	"adhar-io/adhar/platform/notify"
 )
 
 // dataPlaneFinalizer guards orderly teardown (deregister ArgoCD, delete
@@ -78,3 +80,34 @@ func condFrom(enabled bool) metav1.ConditionStatus {
 	}
 	return metav1.ConditionFalse
 }
+
+// notifyReady publishes a notification when the Ready condition changed
+// status during this pass. A plane that is still provisioning (no previous
+// condition, now False) is not reported.
+func (r *DataPlaneReconciler) notifyReady(ctx context.Context, dp *v1alpha1.DataPlane, before *metav1.Condition) {
+	ready := meta.FindStatusCondition(dp.Status.Conditions, v1alpha1.DataPlaneReady)
+	if ready == nil {
+		return
+	}
+	if before == nil && ready.Status != metav1.ConditionTrue {
+		return
+	}
+	if before != nil && before.Status == ready.Status {
+		return
+	}
+	e := notify.Event{
+		Type:      notify.EventDataPlaneReady,
+		Severity:  notify.SeverityInfo,
+		Title:     fmt.Sprintf("DataPlane %s is ready", dp.Name),
+		Message:   ready.Message,
+		Kind:      "DataPlane",
+		Namespace: dp.Namespace,
+		Name:      dp.Name,
+		Reason:    ready.Reason,
+	}
+	if ready.Status != metav1.ConditionTrue {
+		e.Severity = notify.SeverityCritical
+		e.Title = fmt.Sprintf("DataPlane %s is no longer ready", dp.Name)
+	}
+	r.Notifier.Publish(ctx, e)
+}
@@ -24,6 +24,7 @@ import (
 	"context"
 	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -36,6 +37,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"adhar-io/adhar/api/v1alpha1"
+	"adhar-io/adhar/platform/notify"
 )
 
 const (
@@ -67,6 +69,9 @@ var compositeClusterGVK = schema.GroupVersionKind{Group: "platform.adhar.io", Ve
 type DataPlaneReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Notifier publishes Ready transitions to the configured sinks; nil
+	// disables notifications.
+	Notifier *notify.Notifier
 }
 
 // +kubebuilder:rbac:groups=platform.adhar.io,resources=dataplanes,verbs=get;list;watch;create;update;patch;delete
@@ -88,6 +93,9 @@ func (r *DataPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	if err := r.Get(ctx, req.NamespacedName, dp); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	// Objects admitted while the defaulting webhook was off still carry
+	// empty fields.
+	dp.Default()
 
 	// Finalizer for orderly teardown (deregister ArgoCD, delete
 	// CompositeCluster/vcluster for controller-created infra only).
@@ -98,6 +106,8 @@ func (r *DataPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		controllerutil.AddFinalizer(dp, dataPlaneFinalizer)
 		return ctrl.Result{Requeue: true}, r.Update(ctx, dp)
 	}
+	wasReady := meta.FindStatusCondition(dp.Status.Conditions, v1alpha1.DataPlaneReady).DeepCopy()
+	defer func() { r.notifyReady(ctx, dp, wasReady) }()
 
 	// Phase 1 — infra: obtain a client to the data plane once reachable.
 	kube, ready, err := r.ensureInfra(ctx, dp)
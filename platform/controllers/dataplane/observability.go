@@ -27,10 +27,6 @@ import (
 	"adhar-io/adhar/api/v1alpha1"
 )
 
-// defaultObservabilityHub is the control-plane mesh identity that stores
-// telemetry when a DataPlane does not override it.
-const defaultObservabilityHub = "adhar-mgmt"
-
 // ensureObservability applies the `observability-hub` ConfigMap onto the data
 // plane so its Alloy agent knows the hub identity and ingest endpoints. Uses the
 // data-plane client; tolerates a nil client (infra not yet reachable).
@@ -41,7 +37,7 @@ func (r *DataPlaneReconciler) ensureObservability(ctx context.Context, dp *v1alp
 
 	hub := dp.Spec.Observability.Hub
 	if hub == "" {
-		hub = defaultObservabilityHub
+		hub = v1alpha1.DefaultObservabilityHub
 	}
 
 	cm := &corev1.ConfigMap{
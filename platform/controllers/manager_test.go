@@ -66,6 +66,13 @@ func TestManagerManifestsRender(t *testing.T) {
 	args, _ := container["args"].([]interface{})
 	assert.Contains(t, args, "controller")
 
+	assert.Contains(t, args, "--enable-webhooks")
+
+	svc, ok := objs["Service"]
+	require.True(t, ok, "webhook Service must be present")
+	assert.Equal(t, "adhar-webhook", svc.GetName())
+	assert.Equal(t, "adhar-system", svc.GetNamespace())
+
 	// Service-link env injection is disabled per ADR-0011.
 	enableServiceLinks, found, err := unstructured.NestedBool(deploy.Object, "spec", "template", "spec", "enableServiceLinks")
 	require.NoError(t, err)
@@ -0,0 +1,217 @@
+// Package notification watches workloads the platform does not own — ArgoCD
+// Applications and Velero Backups — and publishes their health changes to the
+// notification sinks. AdharPlatform and DataPlane publish their own condition
+// changes from their reconcilers.
+package notification
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"adhar-io/adhar/platform/notify"
+)
+
+var (
+	applicationGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Application"}
+	backupGVK      = schema.GroupVersionKind{Group: "velero.io", Version: "v1", Kind: "Backup"}
+)
+
+const healthDegraded = "Degraded"
+
+// ApplicationReconciler reports ArgoCD Applications turning Degraded and
+// recovering.
+//
+// The last seen health is kept in memory only: the first observation of an
+// application after a controller start is recorded, not reported, so a
+// restart never replays old degradations.
+type ApplicationReconciler struct {
+	client.Client
+	Notifier *notify.Notifier
+
+	mu     sync.Mutex
+	health map[types.NamespacedName]string
+}
+
+// +kubebuilder:rbac:groups=argoproj.io,resources=applications,verbs=get;list;watch
+
+func (r *ApplicationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	app := &unstructured.Unstructured{}
+	app.SetGroupVersionKind(applicationGVK)
+	if err := r.Get(ctx, req.NamespacedName, app); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			r.mu.Lock()
+			delete(r.health, req.NamespacedName)
+			r.mu.Unlock()
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	health, _, _ := unstructured.NestedString(app.Object, "status", "health", "status")
+	message, _, _ := unstructured.NestedString(app.Object, "status", "health", "message")
+
+	r.mu.Lock()
+	if r.health == nil {
+		r.health = map[types.NamespacedName]string{}
+	}
+	prev, seen := r.health[req.NamespacedName]
+	r.health[req.NamespacedName] = health
+	r.mu.Unlock()
+
+	if e, ok := applicationEvent(app, prev, health, message); seen && ok {
+		r.Notifier.Publish(ctx, e)
+	}
+	return ctrl.Result{}, nil
+}
+
+// applicationEvent returns the notification for a health change from prev to
+// health, if any.
+func applicationEvent(app *unstructured.Unstructured, prev, health, message string) (notify.Event, bool) {
+	e := notify.Event{
+		Kind:      "Application",
+		Namespace: app.GetNamespace(),
+		Name:      app.GetName(),
+		Message:   message,
+		Reason:    health,
+	}
+	switch {
+	case health == healthDegraded && prev != healthDegraded:
+		e.Type = notify.EventAppDegraded
+		e.Severity = notify.SeverityWarning
+		e.Title = fmt.Sprintf("Application %s is degraded", app.GetName())
+	case prev == healthDegraded && health == "Healthy":
+		e.Type = notify.EventAppRecovered
+		e.Severity = notify.SeverityInfo
+		e.Title = fmt.Sprintf("Application %s recovered", app.GetName())
+	default:
+		return notify.Event{}, false
+	}
+	return e, true
+}
+
+func (r *ApplicationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	app := &unstructured.Unstructured{}
+	app.SetGroupVersionKind(applicationGVK)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("application-notifier").
+		For(app).
+		Complete(r)
+}
+
+// BackupReconciler reports Velero Backups that finish Failed or
+// PartiallyFailed. Backups completed before the controller started are
+// ignored; each failed backup is reported once per controller lifetime.
+type BackupReconciler struct {
+	client.Client
+	Notifier *notify.Notifier
+
+	started  time.Time
+	mu       sync.Mutex
+	notified map[types.UID]bool
+}
+
+// +kubebuilder:rbac:groups=velero.io,resources=backups,verbs=get;list;watch
+
+func (r *BackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	backup := &unstructured.Unstructured{}
+	backup.SetGroupVersionKind(backupGVK)
+	if err := r.Get(ctx, req.NamespacedName, backup); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	e, ok := backupEvent(backup, r.started)
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+
+	r.mu.Lock()
+	if r.notified == nil {
+		r.notified = map[types.UID]bool{}
+	}
+	already := r.notified[backup.GetUID()]
+	r.notified[backup.GetUID()] = true
+	r.mu.Unlock()
+
+	if !already {
+		r.Notifier.Publish(ctx, e)
+	}
+	return ctrl.Result{}, nil
+}
+
+// backupEvent returns the notification for a failed backup that completed
+// after since.
+func backupEvent(backup *unstructured.Unstructured, since time.Time) (notify.Event, bool) {
+	phase, _, _ := unstructured.NestedString(backup.Object, "status", "phase")
+	if phase != "Failed" && phase != "PartiallyFailed" {
+		return notify.Event{}, false
+	}
+	if completed, _, _ := unstructured.NestedString(backup.Object, "status", "completionTimestamp"); completed != "" {
+		if t, err := time.Parse(time.RFC3339, completed); err == nil && t.Before(since) {
+			return notify.Event{}, false
+		}
+	}
+	reason, _, _ := unstructured.NestedString(backup.Object, "status", "failureReason")
+	errs, _, _ := unstructured.NestedInt64(backup.Object, "status", "errors")
+
+	e := notify.Event{
+		Type:      notify.EventBackupFailed,
+		Severity:  notify.SeverityCritical,
+		Title:     fmt.Sprintf("Backup %s failed", backup.GetName()),
+		Message:   reason,
+		Kind:      "Backup",
+		Namespace: backup.GetNamespace(),
+		Name:      backup.GetName(),
+		Reason:    phase,
+	}
+	if phase == "PartiallyFailed" {
+		e.Severity = notify.SeverityWarning
+		e.Title = fmt.Sprintf("Backup %s partially failed", backup.GetName())
+		if e.Message == "" {
+			e.Message = fmt.Sprintf("%d item(s) could not be backed up", errs)
+		}
+	}
+	return e, true
+}
+
+func (r *BackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.started = time.Now()
+	backup := &unstructured.Unstructured{}
+	backup.SetGroupVersionKind(backupGVK)
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("backup-notifier").
+		For(backup).
+		Complete(r)
+}
+
+// Setup registers the notification controllers whose CRDs are installed.
+// ArgoCD and Velero are optional on a cluster, and a watch on a missing kind
+// would keep the manager from syncing its caches.
+func Setup(ctx context.Context, mgr ctrl.Manager, notifier *notify.Notifier) error {
+	logger := log.FromContext(ctx)
+	if installed(mgr, applicationGVK) {
+		if err := (&ApplicationReconciler{Client: mgr.GetClient(), Notifier: notifier}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("setting up application notifier: %w", err)
+		}
+	} else {
+		logger.V(1).Info("ArgoCD Applications not installed, not watching application health")
+	}
+	if installed(mgr, backupGVK) {
+		if err := (&BackupReconciler{Client: mgr.GetClient(), Notifier: notifier}).SetupWithManager(mgr); err != nil {
+			return fmt.Errorf("setting up backup notifier: %w", err)
+		}
+	} else {
+		logger.V(1).Info("Velero Backups not installed, not watching backups")
+	}
+	return nil
+}
+
+func installed(mgr ctrl.Manager, gvk schema.GroupVersionKind) bool {
+	_, err := mgr.GetRESTMapper().RESTMapping(gvk.GroupKind(), gvk.Version)
+	return err == nil
+}
@@ -0,0 +1,65 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"adhar-io/adhar/platform/notify"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestApplicationEvent(t *testing.T) {
+	app := &unstructured.Unstructured{}
+	app.SetNamespace("adhar-system")
+	app.SetName("shop")
+
+	e, ok := applicationEvent(app, "Healthy", "Degraded", "0/2 replicas ready")
+	assert.True(t, ok)
+	assert.Equal(t, notify.EventAppDegraded, e.Type)
+	assert.Equal(t, "0/2 replicas ready", e.Message)
+
+	_, ok = applicationEvent(app, "Degraded", "Degraded", "")
+	assert.False(t, ok, "no repeat while still degraded")
+
+	_, ok = applicationEvent(app, "Degraded", "Progressing", "")
+	assert.False(t, ok, "progressing is not a recovery yet")
+
+	e, ok = applicationEvent(app, "Degraded", "Healthy", "")
+	assert.True(t, ok)
+	assert.Equal(t, notify.EventAppRecovered, e.Type)
+	assert.Equal(t, notify.SeverityInfo, e.Severity)
+
+	_, ok = applicationEvent(app, "Progressing", "Healthy", "")
+	assert.False(t, ok)
+}
+
+func backup(phase, completed string) *unstructured.Unstructured {
+	b := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{"phase": phase, "completionTimestamp": completed, "errors": int64(3)},
+	}}
+	b.SetNamespace("velero")
+	b.SetName("nightly")
+	return b
+}
+
+func TestBackupEvent(t *testing.T) {
+	started := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	e, ok := backupEvent(backup("Failed", "2026-01-01T13:00:00Z"), started)
+	assert.True(t, ok)
+	assert.Equal(t, notify.EventBackupFailed, e.Type)
+	assert.Equal(t, notify.SeverityCritical, e.Severity)
+
+	e, ok = backupEvent(backup("PartiallyFailed", "2026-01-01T13:00:00Z"), started)
+	assert.True(t, ok)
+	assert.Equal(t, notify.SeverityWarning, e.Severity)
+	assert.Equal(t, "3 item(s) could not be backed up", e.Message)
+
+	_, ok = backupEvent(backup("Failed", "2026-01-01T11:00:00Z"), started)
+	assert.False(t, ok, "failures from before the controller started are not replayed")
+
+	_, ok = backupEvent(backup("Completed", "2026-01-01T13:00:00Z"), started)
+	assert.False(t, ok)
+}
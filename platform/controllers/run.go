@@ -4,9 +4,12 @@ import (
 	"context"
 
 	"adhar-io/adhar/api/v1alpha1"
+	"adhar-io/adhar/globals"
 	"adhar-io/adhar/platform/controllers/adharplatform"
 	"adhar-io/adhar/platform/controllers/custompackage"
 	"adhar-io/adhar/platform/controllers/dataplane"
+	"adhar-io/adhar/platform/controllers/notification"
+	"adhar-io/adhar/platform/notify"
 	"adhar-io/adhar/platform/utils"
 
 	"adhar-io/adhar/platform/controllers/gitrepository"
@@ -28,6 +31,7 @@ func RunControllers(
 	logger := log.FromContext(ctx)
 
 	repoMap := utils.NewRepoLock()
+	notifier := notify.NewNotifier(mgr.GetClient(), globals.AdharSystemNamespace)
 
 	// Run AdharPlatform controller
 	if err := (&adharplatform.AdharPlatformReconciler{
@@ -39,6 +43,7 @@ func RunControllers(
 		TempDir:    tmpDir,
 		StackDir:   stackDir,
 		RepoMap:    repoMap,
+		Notifier:   notifier,
 	}).SetupWithManager(mgr); err != nil {
 		logger.Error(err, "unable to create adharplatform controller")
 		return err
@@ -69,11 +74,16 @@ func RunControllers(
 	}
 
 	if err := (&dataplane.DataPlaneReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Notifier: notifier,
 	}).SetupWithManager(mgr); err != nil {
 		logger.Error(err, "unable to create dataplane controller")
 	}
+
+	if err := notification.Setup(ctx, mgr, notifier); err != nil {
+		logger.Error(err, "unable to create notification controllers")
+	}
 	// Start our manager in another goroutine
 	logger.V(1).Info("starting manager")
 
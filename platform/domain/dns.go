@@ -0,0 +1,388 @@
+package domain
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"adhar-io/adhar/platform/types"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// dnsCredentialsMountPath is where file-based provider credentials are mounted
+// in the external-dns container.
+const dnsCredentialsMountPath = "/etc/secrets/dns"
+
+// DNSProvider plugs a DNS service into external-dns, which publishes the
+// public records, and cert-manager, which answers ACME DNS-01 challenges.
+type DNSProvider interface {
+	// Name is the provider name used in config.yaml.
+	Name() string
+	// DefaultCredentialsSecret is the Secret read when credentialsSecret is unset.
+	DefaultCredentialsSecret() string
+	// CredentialKeys lists the keys the credentials Secret must contain.
+	CredentialKeys(cfg types.DNSConfig) []string
+	// Validate reports missing or malformed provider settings.
+	Validate(cfg types.DNSConfig) error
+	// ExternalDNS returns the provider's external-dns container settings.
+	ExternalDNS(cfg types.DNSConfig, secret string) ExternalDNSSettings
+	// Solver returns the provider block of a cert-manager dns01 solver.
+	Solver(cfg types.DNSConfig, secret string) map[string]interface{}
+}
+
+// ExternalDNSSettings are the provider-specific parts of the external-dns
+// Deployment.
+type ExternalDNSSettings struct {
+	Args         []string
+	Env          []corev1.EnvVar
+	Volumes      []corev1.Volume
+	VolumeMounts []corev1.VolumeMount
+}
+
+var (
+	dnsProviders = map[string]DNSProvider{}
+	dnsAliases   = map[string]string{
+		"aws":    "route53",
+		"google": "clouddns",
+		"gcp":    "clouddns",
+		"azure":  "azuredns",
+		"do":     "digitalocean",
+	}
+)
+
+// RegisterDNSProvider makes a DNS provider selectable by name.
+func RegisterDNSProvider(p DNSProvider) {
+	dnsProviders[p.Name()] = p
+}
+
+// GetDNSProvider returns the DNS provider registered under name or one of its
+// aliases (aws, google, gcp, azure, do).
+func GetDNSProvider(name string) (DNSProvider, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if canonical, ok := dnsAliases[name]; ok {
+		name = canonical
+	}
+	if p, ok := dnsProviders[name]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("unsupported DNS provider %q (supported: %s)", name, strings.Join(DNSProviderNames(), ", "))
+}
+
+// DNSProviderNames returns the registered DNS provider names, sorted.
+func DNSProviderNames() []string {
+	names := make([]string, 0, len(dnsProviders))
+	for name := range dnsProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CredentialsSecretName returns the Secret holding the provider credentials.
+func CredentialsSecretName(p DNSProvider, cfg types.DNSConfig) string {
+	if cfg.CredentialsSecret != "" {
+		return cfg.CredentialsSecret
+	}
+	return p.DefaultCredentialsSecret()
+}
+
+func init() {
+	RegisterDNSProvider(route53Provider{})
+	RegisterDNSProvider(cloudDNSProvider{})
+	RegisterDNSProvider(azureDNSProvider{})
+	RegisterDNSProvider(digitalOceanProvider{})
+	RegisterDNSProvider(cloudflareProvider{})
+	RegisterDNSProvider(rfc2136Provider{})
+}
+
+// requireSettings returns an error naming the settings missing from cfg.Config.
+func requireSettings(provider string, cfg types.DNSConfig, keys ...string) error {
+	var missing []string
+	for _, k := range keys {
+		if cfg.Config[k] == "" {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s DNS provider requires dns.config.%s", provider, strings.Join(missing, ", dns.config."))
+	}
+	return nil
+}
+
+func secretEnv(name, secret, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secret},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+func secretRef(secret, key string) map[string]interface{} {
+	return map[string]interface{}{"name": secret, "key": key}
+}
+
+// mountCredentials mounts the credentials Secret as files for providers whose
+// clients only read credentials from disk.
+func mountCredentials(s *ExternalDNSSettings, secret string) {
+	s.Volumes = append(s.Volumes, corev1.Volume{
+		Name: "dns-credentials",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: secret},
+		},
+	})
+	s.VolumeMounts = append(s.VolumeMounts, corev1.VolumeMount{
+		Name:      "dns-credentials",
+		MountPath: dnsCredentialsMountPath,
+		ReadOnly:  true,
+	})
+}
+
+// route53Provider manages records in AWS Route 53.
+type route53Provider struct{}
+
+func (route53Provider) Name() string                     { return "route53" }
+func (route53Provider) DefaultCredentialsSecret() string { return "aws-credentials" }
+
+func (route53Provider) CredentialKeys(types.DNSConfig) []string {
+	return []string{"access-key-id", "secret-access-key"}
+}
+
+func (p route53Provider) Validate(cfg types.DNSConfig) error {
+	return requireSettings(p.Name(), cfg, "region")
+}
+
+func (route53Provider) ExternalDNS(cfg types.DNSConfig, secret string) ExternalDNSSettings {
+	s := ExternalDNSSettings{
+		Args: []string{"--provider=aws", "--aws-zone-type=public"},
+		Env: []corev1.EnvVar{
+			{Name: "AWS_DEFAULT_REGION", Value: cfg.Config["region"]},
+			secretEnv("AWS_ACCESS_KEY_ID", secret, "access-key-id"),
+			secretEnv("AWS_SECRET_ACCESS_KEY", secret, "secret-access-key"),
+		},
+	}
+	if id := cfg.Config["hostedZoneID"]; id != "" {
+		s.Args = append(s.Args, "--zone-id-filter="+id)
+	}
+	return s
+}
+
+func (route53Provider) Solver(cfg types.DNSConfig, secret string) map[string]interface{} {
+	solver := map[string]interface{}{
+		"region":                   cfg.Config["region"],
+		"accessKeyIDSecretRef":     secretRef(secret, "access-key-id"),
+		"secretAccessKeySecretRef": secretRef(secret, "secret-access-key"),
+	}
+	if id := cfg.Config["hostedZoneID"]; id != "" {
+		solver["hostedZoneID"] = id
+	}
+	return map[string]interface{}{"route53": solver}
+}
+
+// cloudDNSProvider manages records in Google Cloud DNS using a service
+// account key.
+type cloudDNSProvider struct{}
+
+func (cloudDNSProvider) Name() string                     { return "clouddns" }
+func (cloudDNSProvider) DefaultCredentialsSecret() string { return "clouddns-credentials" }
+
+func (cloudDNSProvider) CredentialKeys(types.DNSConfig) []string {
+	return []string{"credentials.json"}
+}
+
+func (p cloudDNSProvider) Validate(cfg types.DNSConfig) error {
+	return requireSettings(p.Name(), cfg, "project")
+}
+
+func (cloudDNSProvider) ExternalDNS(cfg types.DNSConfig, secret string) ExternalDNSSettings {
+	s := ExternalDNSSettings{
+		Args: []string{
+			"--provider=google",
+			"--google-project=" + cfg.Config["project"],
+			"--google-zone-visibility=public",
+		},
+		Env: []corev1.EnvVar{
+			{Name: "GOOGLE_APPLICATION_CREDENTIALS", Value: dnsCredentialsMountPath + "/credentials.json"},
+		},
+	}
+	mountCredentials(&s, secret)
+	return s
+}
+
+func (cloudDNSProvider) Solver(cfg types.DNSConfig, secret string) map[string]interface{} {
+	return map[string]interface{}{"cloudDNS": map[string]interface{}{
+		"project":                 cfg.Config["project"],
+		"serviceAccountSecretRef": secretRef(secret, "credentials.json"),
+	}}
+}
+
+// azureDNSProvider manages records in Azure DNS with a service principal.
+// external-dns reads the principal from an azure.json file while cert-manager
+// takes the client secret directly, so the Secret carries both.
+type azureDNSProvider struct{}
+
+func (azureDNSProvider) Name() string                     { return "azuredns" }
+func (azureDNSProvider) DefaultCredentialsSecret() string { return "azuredns-credentials" }
+
+func (azureDNSProvider) CredentialKeys(types.DNSConfig) []string {
+	return []string{"azure.json", "client-secret"}
+}
+
+func (p azureDNSProvider) Validate(cfg types.DNSConfig) error {
+	return requireSettings(p.Name(), cfg, "subscriptionID", "resourceGroup", "tenantID", "clientID")
+}
+
+func (azureDNSProvider) ExternalDNS(cfg types.DNSConfig, secret string) ExternalDNSSettings {
+	s := ExternalDNSSettings{
+		Args: []string{
+			"--provider=azure",
+			"--azure-resource-group=" + cfg.Config["resourceGroup"],
+			"--azure-subscription-id=" + cfg.Config["subscriptionID"],
+			"--azure-config-file=" + dnsCredentialsMountPath + "/azure.json",
+		},
+	}
+	mountCredentials(&s, secret)
+	return s
+}
+
+func (azureDNSProvider) Solver(cfg types.DNSConfig, secret string) map[string]interface{} {
+	environment := cfg.Config["environment"]
+	if environment == "" {
+		environment = "AzurePublicCloud"
+	}
+	return map[string]interface{}{"azureDNS": map[string]interface{}{
+		"subscriptionID":        cfg.Config["subscriptionID"],
+		"resourceGroupName":     cfg.Config["resourceGroup"],
+		"hostedZoneName":        cfg.Zone,
+		"environment":           environment,
+		"tenantID":              cfg.Config["tenantID"],
+		"clientID":              cfg.Config["clientID"],
+		"clientSecretSecretRef": secretRef(secret, "client-secret"),
+	}}
+}
+
+// digitalOceanProvider manages records in DigitalOcean DNS.
+type digitalOceanProvider struct{}
+
+func (digitalOceanProvider) Name() string                     { return "digitalocean" }
+func (digitalOceanProvider) DefaultCredentialsSecret() string { return "digitalocean-credentials" }
+
+func (digitalOceanProvider) CredentialKeys(types.DNSConfig) []string {
+	return []string{"token"}
+}
+
+func (digitalOceanProvider) Validate(types.DNSConfig) error { return nil }
+
+func (digitalOceanProvider) ExternalDNS(_ types.DNSConfig, secret string) ExternalDNSSettings {
+	return ExternalDNSSettings{
+		Args: []string{"--provider=digitalocean"},
+		Env:  []corev1.EnvVar{secretEnv("DO_TOKEN", secret, "token")},
+	}
+}
+
+func (digitalOceanProvider) Solver(_ types.DNSConfig, secret string) map[string]interface{} {
+	return map[string]interface{}{"digitalocean": map[string]interface{}{
+		"tokenSecretRef": secretRef(secret, "token"),
+	}}
+}
+
+// cloudflareProvider manages records in Cloudflare with a scoped API token.
+// Records are proxied unless dns.config.proxied is "false".
+type cloudflareProvider struct{}
+
+func (cloudflareProvider) Name() string                     { return "cloudflare" }
+func (cloudflareProvider) DefaultCredentialsSecret() string { return "cloudflare-credentials" }
+
+func (cloudflareProvider) CredentialKeys(types.DNSConfig) []string {
+	return []string{"api-token"}
+}
+
+func (cloudflareProvider) Validate(types.DNSConfig) error { return nil }
+
+func (cloudflareProvider) ExternalDNS(cfg types.DNSConfig, secret string) ExternalDNSSettings {
+	s := ExternalDNSSettings{
+		Args: []string{"--provider=cloudflare"},
+		Env:  []corev1.EnvVar{secretEnv("CF_API_TOKEN", secret, "api-token")},
+	}
+	if cfg.Config["proxied"] != "false" {
+		s.Args = append(s.Args, "--cloudflare-proxied")
+	}
+	return s
+}
+
+func (cloudflareProvider) Solver(_ types.DNSConfig, secret string) map[string]interface{} {
+	return map[string]interface{}{"cloudflare": map[string]interface{}{
+		"apiTokenSecretRef": secretRef(secret, "api-token"),
+	}}
+}
+
+// rfc2136Provider manages records on any nameserver accepting RFC 2136
+// dynamic updates (BIND, Knot, PowerDNS), authenticated with TSIG when
+// dns.config.tsigKeyName is set.
+type rfc2136Provider struct{}
+
+func (rfc2136Provider) Name() string                     { return "rfc2136" }
+func (rfc2136Provider) DefaultCredentialsSecret() string { return "rfc2136-credentials" }
+
+func (rfc2136Provider) CredentialKeys(cfg types.DNSConfig) []string {
+	if cfg.Config["tsigKeyName"] == "" {
+		return nil
+	}
+	return []string{"tsig-secret"}
+}
+
+func (p rfc2136Provider) Validate(cfg types.DNSConfig) error {
+	if err := requireSettings(p.Name(), cfg, "nameserver"); err != nil {
+		return err
+	}
+	if _, _, err := net.SplitHostPort(cfg.Config["nameserver"]); err != nil {
+		return fmt.Errorf("rfc2136 dns.config.nameserver must be host:port: %w", err)
+	}
+	return nil
+}
+
+// tsigAlgorithm returns the TSIG algorithm in cert-manager's spelling
+// (HMACSHA256) and external-dns's (hmac-sha256).
+func tsigAlgorithm(cfg types.DNSConfig) (certManager, externalDNS string) {
+	alg := strings.ToUpper(strings.ReplaceAll(cfg.Config["tsigAlgorithm"], "-", ""))
+	if alg == "" {
+		alg = "HMACSHA256"
+	}
+	return alg, "hmac-" + strings.ToLower(strings.TrimPrefix(alg, "HMAC"))
+}
+
+func (rfc2136Provider) ExternalDNS(cfg types.DNSConfig, secret string) ExternalDNSSettings {
+	host, port, _ := net.SplitHostPort(cfg.Config["nameserver"])
+	s := ExternalDNSSettings{
+		Args: []string{
+			"--provider=rfc2136",
+			"--rfc2136-host=" + host,
+			"--rfc2136-port=" + port,
+			"--rfc2136-zone=" + cfg.Zone,
+		},
+	}
+	if key := cfg.Config["tsigKeyName"]; key != "" {
+		_, alg := tsigAlgorithm(cfg)
+		s.Args = append(s.Args, "--rfc2136-tsig-keyname="+key, "--rfc2136-tsig-secret-alg="+alg, "--rfc2136-tsig-axfr")
+		s.Env = append(s.Env, secretEnv("EXTERNAL_DNS_RFC2136_TSIG_SECRET", secret, "tsig-secret"))
+	} else {
+		s.Args = append(s.Args, "--rfc2136-insecure")
+	}
+	return s
+}
+
+func (rfc2136Provider) Solver(cfg types.DNSConfig, secret string) map[string]interface{} {
+	solver := map[string]interface{}{"nameserver": cfg.Config["nameserver"]}
+	if key := cfg.Config["tsigKeyName"]; key != "" {
+		alg, _ := tsigAlgorithm(cfg)
+		solver["tsigKeyName"] = key
+		solver["tsigAlgorithm"] = alg
+		solver["tsigSecretSecretRef"] = secretRef(secret, "tsig-secret")
+	}
+	return map[string]interface{}{"rfc2136": solver}
+}
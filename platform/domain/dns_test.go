@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"adhar-io/adhar/platform/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGetDNSProvider(t *testing.T) {
+	for name, want := range map[string]string{
+		"route53":       "route53",
+		"aws":           "route53",
+		"GCP":           "clouddns",
+		"google":        "clouddns",
+		"azure":         "azuredns",
+		"do":            "digitalocean",
+		"cloudflare":    "cloudflare",
+		"rfc2136":       "rfc2136",
+		" digitalocean": "digitalocean",
+	} {
+		p, err := GetDNSProvider(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, p.Name(), name)
+	}
+
+	_, err := GetDNSProvider("godaddy")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cloudflare, digitalocean, rfc2136, route53")
+}
+
+func TestDNSProviderValidate(t *testing.T) {
+	tests := []struct {
+		provider string
+		config   map[string]string
+		wantErr  string
+	}{
+		{"route53", nil, "dns.config.region"},
+		{"route53", map[string]string{"region": "us-east-1"}, ""},
+		{"clouddns", nil, "dns.config.project"},
+		{"azuredns", map[string]string{"subscriptionID": "sub"}, "dns.config.resourceGroup, dns.config.tenantID, dns.config.clientID"},
+		{"digitalocean", nil, ""},
+		{"cloudflare", nil, ""},
+		{"rfc2136", nil, "dns.config.nameserver"},
+		{"rfc2136", map[string]string{"nameserver": "10.0.0.53"}, "host:port"},
+		{"rfc2136", map[string]string{"nameserver": "10.0.0.53:53"}, ""},
+	}
+	for _, tt := range tests {
+		p, err := GetDNSProvider(tt.provider)
+		require.NoError(t, err)
+		err = p.Validate(types.DNSConfig{Provider: tt.provider, Config: tt.config})
+		if tt.wantErr == "" {
+			assert.NoError(t, err, tt.provider)
+		} else {
+			require.Error(t, err, tt.provider)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		}
+	}
+}
+
+func renderExternalDNS(t *testing.T, dns types.DNSConfig) appsv1.Deployment {
+	t.Helper()
+	m := NewManager(&types.DomainConfig{BaseDomain: "example.com", DNS: dns}, "")
+	out, err := m.generateExternalDNSConfig("example.com")
+	require.NoError(t, err)
+	docs := strings.Split(out, "---\n")
+	var deployment appsv1.Deployment
+	require.NoError(t, yaml.Unmarshal([]byte(docs[len(docs)-1]), &deployment))
+	require.Equal(t, "Deployment", deployment.Kind)
+	return deployment
+}
+
+func TestGenerateExternalDNSConfig(t *testing.T) {
+	d := renderExternalDNS(t, types.DNSConfig{
+		Provider: "aws",
+		Config:   map[string]string{"region": "eu-west-1", "hostedZoneID": "Z123"},
+	})
+	c := d.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, externalDNSImage, c.Image)
+	assert.Equal(t, []string{
+		"--source=service", "--source=ingress", "--domain-filter=example.com",
+		"--provider=aws", "--aws-zone-type=public", "--zone-id-filter=Z123",
+	}, c.Args)
+	require.Len(t, c.Env, 3)
+	assert.Equal(t, "eu-west-1", c.Env[0].Value)
+	assert.Equal(t, "aws-credentials", c.Env[1].ValueFrom.SecretKeyRef.Name)
+
+	// File-based credentials are mounted from the (custom) credentials Secret
+	d = renderExternalDNS(t, types.DNSConfig{
+		Provider:          "clouddns",
+		Zone:              "apps.example.com",
+		CredentialsSecret: "gcp-sa",
+		Config:            map[string]string{"project": "my-project"},
+		SplitHorizon:      types.SplitHorizonConfig{Enabled: true, InternalZone: "internal.example.com"},
+	})
+	c = d.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, c.Args, "--domain-filter=apps.example.com")
+	assert.Contains(t, c.Args, "--exclude-domains=internal.example.com")
+	assert.Contains(t, c.Args, "--google-project=my-project")
+	assert.Equal(t, "gcp-sa", d.Spec.Template.Spec.Volumes[0].Secret.SecretName)
+	assert.Equal(t, dnsCredentialsMountPath, c.VolumeMounts[0].MountPath)
+
+	d = renderExternalDNS(t, types.DNSConfig{
+		Provider: "rfc2136",
+		Config:   map[string]string{"nameserver": "ns1.example.com:53", "tsigKeyName": "adhar", "tsigAlgorithm": "hmac-sha512"},
+	})
+	c = d.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, c.Args, "--rfc2136-host=ns1.example.com")
+	assert.Contains(t, c.Args, "--rfc2136-zone=example.com")
+	assert.Contains(t, c.Args, "--rfc2136-tsig-secret-alg=hmac-sha512")
+	assert.Equal(t, "EXTERNAL_DNS_RFC2136_TSIG_SECRET", c.Env[0].Name)
+
+	m := NewManager(&types.DomainConfig{DNS: types.DNSConfig{Provider: "route53"}}, "")
+	_, err := m.generateExternalDNSConfig("example.com")
+	assert.ErrorContains(t, err, "dns.config.region")
+}
+
+func TestGenerateIssuer(t *testing.T) {
+	m := NewManager(&types.DomainConfig{
+		DNS:     types.DNSConfig{Provider: "cloudflare"},
+		Ingress: types.IngressConfig{Provider: "nginx"},
+	}, "")
+	out, err := m.generateIssuer("example.com", "ops@example.com", "production")
+	require.NoError(t, err)
+
+	var issuer map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(out), &issuer))
+	assert.Equal(t, "letsencrypt-prod", issuer["metadata"].(map[string]interface{})["name"])
+	acme := issuer["spec"].(map[string]interface{})["acme"].(map[string]interface{})
+	assert.Equal(t, "https://acme-v02.api.letsencrypt.org/directory", acme["server"])
+
+	solvers := acme["solvers"].([]interface{})
+	require.Len(t, solvers, 2)
+	dns01 := solvers[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{"example.com"}, dns01["selector"].(map[string]interface{})["dnsZones"])
+	cloudflare := dns01["dns01"].(map[string]interface{})["cloudflare"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"name": "cloudflare-credentials", "key": "api-token"}, cloudflare["apiTokenSecretRef"])
+	assert.Contains(t, solvers[1], "http01")
+
+	// Without a DNS provider only HTTP-01 is available
+	m = NewManager(&types.DomainConfig{Ingress: types.IngressConfig{Provider: "nginx"}}, "")
+	out, err = m.generateIssuer("example.com", "ops@example.com", "staging")
+	require.NoError(t, err)
+	assert.NotContains(t, out, "dns01")
+	assert.Contains(t, out, "name: letsencrypt\n")
+
+	// Platform clusters without an ingress controller solve through the Gateway
+	m = NewManager(&types.DomainConfig{}, "")
+	out, err = m.generateIssuer("example.com", "ops@example.com", "staging")
+	require.NoError(t, err)
+	assert.Contains(t, out, "gatewayHTTPRoute")
+	assert.Contains(t, out, "name: adhar-gateway")
+}
+
+func TestInternalZones(t *testing.T) {
+	m := NewManager(&types.DomainConfig{DNS: types.DNSConfig{
+		SplitHorizon: types.SplitHorizonConfig{Enabled: true, InternalZone: "corp.internal"},
+	}}, "")
+	zones := m.internalZones("example.com")
+	assert.Equal(t, []string{"example.com", "corp.internal"}, zones)
+	assert.Equal(t, gatewayTarget, m.internalTarget(&types.Cluster{Provider: "aws"}))
+	assert.Equal(t, gatewayTarget, m.internalTarget(&types.Cluster{Provider: "kind"}))
+	m.config.Ingress.Provider = "nginx"
+	assert.Equal(t, ingressTarget, m.internalTarget(&types.Cluster{Provider: "aws"}))
+
+	// A subzone of the domain is already covered by the domain's server block
+	m.config.DNS.SplitHorizon.InternalZone = "internal.example.com"
+	assert.Equal(t, []string{"example.com"}, m.internalZones("example.com"))
+
+	blocks := internalZoneBlocks(zones, ingressTarget)
+	assert.True(t, strings.HasPrefix(blocks, "example.com:53 {"))
+	assert.Contains(t, blocks, "\ncorp.internal:53 {")
+	assert.Contains(t, blocks, `name regex (.*)\.example\.com `+ingressTarget+" answer auto")
+	assert.Contains(t, blocks, "kubernetes cluster.local")
+}
+
+func TestWithInternalZones(t *testing.T) {
+	corefile := ".:53 {\n    errors\n    forward . /etc/resolv.conf\n}\n"
+	zones := []string{"example.com", "corp.internal"}
+
+	patched := withInternalZones(corefile, zones, gatewayTarget)
+	assert.True(t, strings.HasPrefix(patched, corefile), "the existing server blocks are kept")
+	assert.Contains(t, patched, internalZonesBegin+"\nexample.com:53 {")
+	assert.True(t, strings.HasSuffix(patched, internalZonesEnd+"\n"))
+
+	// Re-running replaces the managed blocks instead of adding more
+	again := withInternalZones(patched, zones[:1], ingressTarget)
+	assert.Equal(t, 1, strings.Count(again, internalZonesBegin))
+	assert.NotContains(t, again, "corp.internal:53")
+	assert.NotContains(t, again, gatewayTarget)
+	assert.Contains(t, again, ingressTarget)
+
+	assert.Equal(t, corefile, withInternalZones(patched, nil, ""))
+}
@@ -1,16 +1,43 @@
 // Package domain manages cluster domain setup, including cert-manager and
-// Let's Encrypt issuers, external-dns, ingress controllers, and CoreDNS
-// configuration for local (Kind) and production clusters.
+// Let's Encrypt issuers, external-dns with pluggable DNS providers, ingress
+// controllers, and CoreDNS configuration for local (Kind) clusters and
+// split-horizon production clusters.
 package domain
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 
 	"adhar-io/adhar/platform/types"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// externalDNSImage matches the release in the platform external-dns package.
+	externalDNSImage = "registry.k8s.io/external-dns/external-dns:v0.15.1"
+
+	// gatewayTarget and ingressTarget are the in-cluster services the
+	// internal CoreDNS zone resolves platform hostnames to.
+	gatewayTarget = "cilium-gateway-adhar-gateway.adhar-system.svc.cluster.local"
+	ingressTarget = "ingress-nginx-controller.ingress-nginx.svc.cluster.local"
+
+	// internalZonesBegin and internalZonesEnd delimit the internal zone
+	// server blocks adhar manages in the CoreDNS Corefile.
+	internalZonesBegin = "# BEGIN adhar internal zones"
+	internalZonesEnd   = "# END adhar internal zones"
+
+	// DomainConfigMapName is the adhar-system ConfigMap recording the domain setup.
+	DomainConfigMapName = "adhar-domain-config"
 )
 
 // Manager handles domain setup and management for clusters
@@ -42,6 +69,13 @@ func (m *Manager) SetupDomain(ctx context.Context, cluster *types.Cluster) error
 		fmt.Printf("Using domain: %s\n", domain)
 	}
 
+	// Reject an unusable DNS provider before installing anything
+	if m.config.DNS.Provider != "" {
+		if _, err := m.dnsProvider(domain); err != nil {
+			return err
+		}
+	}
+
 	// Install cert-manager if TLS is enabled
 	if m.config.TLS.Enabled {
 		if !suppressOutput {
@@ -60,7 +94,7 @@ func (m *Manager) SetupDomain(ctx context.Context, cluster *types.Cluster) error
 	// Install external-dns for production clusters
 	if cluster.Provider != "kind" && m.config.DNS.Provider != "" {
 		fmt.Printf("Installing external-dns...\n")
-		if err := m.installExternalDNS(ctx); err != nil {
+		if err := m.installExternalDNS(ctx, domain); err != nil {
 			return fmt.Errorf("failed to install external-dns: %w", err)
 		}
 	}
@@ -73,12 +107,14 @@ func (m *Manager) SetupDomain(ctx context.Context, cluster *types.Cluster) error
 		return fmt.Errorf("failed to install ingress controller: %w", err)
 	}
 
-	// Configure CoreDNS for local resolution (Kind clusters)
-	if cluster.Provider == "kind" {
+	// Serve the domain from CoreDNS inside the cluster: always for Kind, where
+	// the domain has no public records, and for split-horizon clusters, where
+	// pods should reach platform services without leaving the cluster.
+	if cluster.Provider == "kind" || m.config.DNS.SplitHorizon.Enabled {
 		if !suppressOutput {
 			fmt.Printf("Configuring CoreDNS for local domain resolution...\n")
 		}
-		if err := m.setupCoreDNSForKind(ctx, domain); err != nil {
+		if err := m.setupInternalZone(ctx, m.internalZones(domain), m.internalTarget(cluster)); err != nil {
 			if !suppressOutput {
 				fmt.Printf("⚠️  Warning: Failed to configure CoreDNS: %v\n", err)
 			}
@@ -100,6 +136,36 @@ func (m *Manager) SetupDomain(ctx context.Context, cluster *types.Cluster) error
 	return nil
 }
 
+// SetupDNS configures only the DNS side of the domain - the DNS-01 issuer,
+// external-dns and the split-horizon zone - for clusters whose ingress and
+// cert-manager come from the platform stack.
+func (m *Manager) SetupDNS(ctx context.Context, cluster *types.Cluster) error {
+	domain := m.getDomainForCluster(cluster)
+
+	if m.config.DNS.Provider != "" {
+		if _, err := m.dnsProvider(domain); err != nil {
+			return err
+		}
+		if m.config.TLS.Enabled {
+			if err := m.createLetsEncryptIssuer(ctx, domain); err != nil {
+				return fmt.Errorf("failed to create Let's Encrypt issuer: %w", err)
+			}
+		}
+		if err := m.installExternalDNS(ctx, domain); err != nil {
+			return fmt.Errorf("failed to install external-dns: %w", err)
+		}
+	}
+
+	if m.config.DNS.SplitHorizon.Enabled {
+		if err := m.setupInternalZone(ctx, m.internalZones(domain), m.internalTarget(cluster)); err != nil {
+			return fmt.Errorf("failed to configure split-horizon DNS: %w", err)
+		}
+		fmt.Printf("✓ CoreDNS serves %s inside the cluster\n", strings.Join(m.internalZones(domain), ", "))
+	}
+
+	return m.storeClusterConfig(ctx, cluster, domain)
+}
+
 // getDomainForCluster returns the appropriate domain for the cluster
 func (m *Manager) getDomainForCluster(cluster *types.Cluster) string {
 	if cluster.Provider == "kind" {
@@ -165,34 +231,11 @@ func (m *Manager) createLetsEncryptIssuer(ctx context.Context, domain string) er
 		environment = "staging"
 	}
 
-	issuerName := "letsencrypt" // Default issuer name since we removed IssuerName field
-	if issuerName == "" {
-		issuerName = "letsencrypt"
-	}
-
-	// Determine Let's Encrypt server URL
-	serverURL := "https://acme-staging-v02.api.letsencrypt.org/directory"
-	if environment == "production" {
-		serverURL = "https://acme-v02.api.letsencrypt.org/directory"
-		issuerName = issuerName + "-prod"
+	issuerYAML, err := m.generateIssuer(domain, email, environment)
+	if err != nil {
+		return err
 	}
 
-	issuerYAML := fmt.Sprintf(`apiVersion: cert-manager.io/v1
-kind: ClusterIssuer
-metadata:
-  name: %s
-spec:
-  acme:
-    server: %s
-    email: %s
-    privateKeySecretRef:
-      name: %s-private-key
-    solvers:
-    - http01:
-        ingress:
-          class: %s
-`, issuerName, serverURL, email, issuerName, m.config.Ingress.Provider)
-
 	// Apply the issuer
 	cmd := exec.CommandContext(ctx, "kubectl", "apply", "-f", "-")
 	cmd.Stdin = strings.NewReader(issuerYAML)
@@ -201,12 +244,108 @@ spec:
 		return fmt.Errorf("failed to create Let's Encrypt issuer: %w\nOutput: %s", err, string(output))
 	}
 
-	fmt.Printf("✓ Created Let's Encrypt issuer: %s\n", issuerName)
+	fmt.Printf("✓ Created Let's Encrypt issuer: %s\n", IssuerName(environment))
 	return nil
 }
 
+// generateIssuer renders the Let's Encrypt ClusterIssuer. With a DNS provider
+// configured, certificates in its zone are validated over DNS-01 (which also
+// covers wildcards and clusters without a public ingress); everything else
+// falls back to HTTP-01 through the ingress controller or platform Gateway.
+func (m *Manager) generateIssuer(domain, email, environment string) (string, error) {
+	issuerName := IssuerName(environment)
+
+	// Determine Let's Encrypt server URL
+	serverURL := "https://acme-staging-v02.api.letsencrypt.org/directory"
+	if environment == "production" {
+		serverURL = "https://acme-v02.api.letsencrypt.org/directory"
+	}
+
+	var solvers []interface{}
+	if m.config.DNS.Provider != "" {
+		p, err := m.dnsProvider(domain)
+		if err != nil {
+			return "", err
+		}
+		cfg := m.dnsConfig(domain)
+		dns01 := p.Solver(cfg, CredentialsSecretName(p, cfg))
+		solvers = append(solvers, map[string]interface{}{
+			"selector": map[string]interface{}{"dnsZones": []string{cfg.Zone}},
+			"dns01":    dns01,
+		})
+	}
+	http01 := map[string]interface{}{
+		"ingress": map[string]interface{}{"class": m.config.Ingress.Provider},
+	}
+	if m.config.Ingress.Provider == "" {
+		// Without an ingress controller challenges go through the platform Gateway
+		http01 = map[string]interface{}{
+			"gatewayHTTPRoute": map[string]interface{}{
+				"parentRefs": []interface{}{map[string]interface{}{
+					"kind": "Gateway", "name": "adhar-gateway", "namespace": "adhar-system",
+				}},
+			},
+		}
+	}
+	solvers = append(solvers, map[string]interface{}{"http01": http01})
+
+	issuer := map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "ClusterIssuer",
+		"metadata":   map[string]interface{}{"name": issuerName},
+		"spec": map[string]interface{}{
+			"acme": map[string]interface{}{
+				"server":              serverURL,
+				"email":               email,
+				"privateKeySecretRef": map[string]interface{}{"name": issuerName + "-private-key"},
+				"solvers":             solvers,
+			},
+		},
+	}
+	out, err := yaml.Marshal(issuer)
+	if err != nil {
+		return "", fmt.Errorf("failed to render Let's Encrypt issuer: %w", err)
+	}
+	return string(out), nil
+}
+
+// IssuerName returns the ClusterIssuer name for a Let's Encrypt environment.
+func IssuerName(environment string) string {
+	if environment == "production" {
+		return "letsencrypt-prod"
+	}
+	return "letsencrypt"
+}
+
+// dnsZone returns the public zone managed by the DNS provider.
+func (m *Manager) dnsZone(domain string) string {
+	if m.config.DNS.Zone != "" {
+		return m.config.DNS.Zone
+	}
+	return domain
+}
+
+// dnsConfig returns the DNS configuration with its zone resolved.
+func (m *Manager) dnsConfig(domain string) types.DNSConfig {
+	cfg := m.config.DNS
+	cfg.Zone = m.dnsZone(domain)
+	return cfg
+}
+
+// dnsProvider returns the configured DNS provider after validating its settings.
+func (m *Manager) dnsProvider(domain string) (DNSProvider, error) {
+	p, err := GetDNSProvider(m.config.DNS.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.Validate(m.dnsConfig(domain)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
 // installExternalDNS installs external-dns for automatic DNS management
-func (m *Manager) installExternalDNS(ctx context.Context) error {
+func (m *Manager) installExternalDNS(ctx context.Context, domain string) error {
 	// Check if external-dns is already installed
 	cmd := exec.CommandContext(ctx, "kubectl", "get", "deployment", "external-dns", "-n", "kube-system")
 	if err := cmd.Run(); err == nil {
@@ -215,7 +354,10 @@ func (m *Manager) installExternalDNS(ctx context.Context) error {
 	}
 
 	// Create external-dns configuration based on provider
-	externalDNSYAML := m.generateExternalDNSConfig()
+	externalDNSYAML, err := m.generateExternalDNSConfig(domain)
+	if err != nil {
+		return err
+	}
 
 	// Apply external-dns configuration
 	cmd = exec.CommandContext(ctx, "kubectl", "apply", "-f", "-")
@@ -229,16 +371,8 @@ func (m *Manager) installExternalDNS(ctx context.Context) error {
 	return nil
 }
 
-// generateExternalDNSConfig generates external-dns configuration based on DNS provider
-func (m *Manager) generateExternalDNSConfig() string {
-	provider := m.config.DNS.Provider
-	var domainFilters []string
-	if m.config.BaseDomain != "" {
-		domainFilters = []string{m.config.BaseDomain}
-	}
-
-	// Base external-dns configuration
-	config := fmt.Sprintf(`apiVersion: v1
+// externalDNSRBAC grants external-dns read access to the sources it publishes.
+const externalDNSRBAC = `apiVersion: v1
 kind: ServiceAccount
 metadata:
   name: external-dns
@@ -271,64 +405,52 @@ subjects:
 - kind: ServiceAccount
   name: external-dns
   namespace: kube-system
----
-apiVersion: apps/v1
-kind: Deployment
-metadata:
-  name: external-dns
-  namespace: kube-system
-spec:
-  strategy:
-    type: Recreate
-  selector:
-    matchLabels:
-      app: external-dns
-  template:
-    metadata:
-      labels:
-        app: external-dns
-    spec:
-      serviceAccountName: external-dns
-      containers:
-      - name: external-dns
-        image: k8s.gcr.io/external-dns/external-dns:v0.13.6
-        args:
-        - --source=service
-        - --source=ingress
-        - --provider=%s`, provider)
-
-	// Add domain filters if specified
-	for _, domain := range domainFilters {
-		config += fmt.Sprintf("\n        - --domain-filter=%s", domain)
-	}
-
-	// Add provider-specific configuration
-	switch provider {
-	case "cloudflare":
-		config += `
-        - --cloudflare-proxied
-        env:
-        - name: CF_API_TOKEN
-          valueFrom:
-            secretKeyRef:
-              name: cloudflare-credentials
-              key: api-token`
-	case "route53":
-		config += `
-        env:
-        - name: AWS_ACCESS_KEY_ID
-          valueFrom:
-            secretKeyRef:
-              name: aws-credentials
-              key: access-key-id
-        - name: AWS_SECRET_ACCESS_KEY
-          valueFrom:
-            secretKeyRef:
-              name: aws-credentials
-              key: secret-access-key`
-	}
-
-	return config
+`
+
+// generateExternalDNSConfig generates external-dns configuration based on DNS provider
+func (m *Manager) generateExternalDNSConfig(domain string) (string, error) {
+	p, err := m.dnsProvider(domain)
+	if err != nil {
+		return "", err
+	}
+	cfg := m.dnsConfig(domain)
+	settings := p.ExternalDNS(cfg, CredentialsSecretName(p, cfg))
+
+	args := []string{"--source=service", "--source=ingress", "--domain-filter=" + cfg.Zone}
+	// The internal zone of a split-horizon setup must never be published
+	if cfg.SplitHorizon.Enabled && cfg.SplitHorizon.InternalZone != "" {
+		args = append(args, "--exclude-domains="+cfg.SplitHorizon.InternalZone)
+	}
+	args = append(args, settings.Args...)
+
+	labels := map[string]string{"app": "external-dns"}
+	deployment := appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "external-dns", Namespace: "kube-system"},
+		Spec: appsv1.DeploymentSpec{
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: "external-dns",
+					Containers: []corev1.Container{{
+						Name:         "external-dns",
+						Image:        externalDNSImage,
+						Args:         args,
+						Env:          settings.Env,
+						VolumeMounts: settings.VolumeMounts,
+					}},
+					Volumes: settings.Volumes,
+				},
+			},
+		},
+	}
+	out, err := yaml.Marshal(deployment)
+	if err != nil {
+		return "", fmt.Errorf("failed to render external-dns deployment: %w", err)
+	}
+	return externalDNSRBAC + "---\n" + string(out), nil
 }
 
 // installIngressController installs the specified ingress controller
@@ -414,23 +536,13 @@ metadata:
 	}
 
 	// Create domain configuration ConfigMap
-	configMapYAML := fmt.Sprintf(`apiVersion: v1
-kind: ConfigMap
-metadata:
-  name: adhar-domain-config
-  namespace: adhar-system
-  labels:
-    adhar.io/managed-by: adhar
-data:
-  domain: "%s"
-  provider: "%s"
-  tls-enabled: "%t"
-  tls-environment: "%s"
-  ingress-controller: "%s"
-  dns-provider: "%s"`, domain, cluster.Provider, m.config.TLS.Enabled, m.config.TLS.Environment, m.config.Ingress.Provider, m.config.DNS.Provider)
+	configMap, err := yaml.Marshal(m.domainConfigMap(cluster, domain))
+	if err != nil {
+		return fmt.Errorf("failed to render domain configuration ConfigMap: %w", err)
+	}
 
 	cmd = exec.CommandContext(ctx, "kubectl", "apply", "-f", "-")
-	cmd.Stdin = strings.NewReader(configMapYAML)
+	cmd.Stdin = strings.NewReader(string(configMap))
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to create domain configuration ConfigMap: %w", err)
 	}
@@ -439,53 +551,139 @@ data:
 	return nil
 }
 
-// setupCoreDNSForKind configures CoreDNS for local domain resolution in Kind clusters
-func (m *Manager) setupCoreDNSForKind(ctx context.Context, domain string) error {
-	// Get current CoreDNS ConfigMap
-	cmd := exec.CommandContext(ctx, "kubectl", "get", "configmap", "coredns", "-n", "kube-system", "-o", "yaml")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to get CoreDNS ConfigMap: %w", err)
+// internalZones returns the zones served by CoreDNS inside the cluster: the
+// platform domain and, for split-horizon, an internal zone outside of it.
+func (m *Manager) internalZones(domain string) []string {
+	zones := []string{domain}
+	internal := m.config.DNS.SplitHorizon.InternalZone
+	if m.config.DNS.SplitHorizon.Enabled && internal != "" && internal != domain && !strings.HasSuffix(internal, "."+domain) {
+		zones = append(zones, internal)
 	}
+	return zones
+}
 
-	// Check if our custom configuration already exists
-	if strings.Contains(string(output), domain) {
-		fmt.Printf("CoreDNS already configured for %s\n", domain)
-		return nil
+// internalTarget returns the service platform hostnames resolve to inside the
+// cluster.
+func (m *Manager) internalTarget(cluster *types.Cluster) string {
+	if t := m.config.DNS.SplitHorizon.Target; t != "" {
+		return t
 	}
+	if cluster.Provider == "kind" || m.config.Ingress.Provider == "" {
+		return gatewayTarget
+	}
+	return ingressTarget
+}
 
-	// Create custom CoreDNS configuration for local domain
-	coreDNSConfig := fmt.Sprintf(`apiVersion: v1
-kind: ConfigMap
-metadata:
-  name: coredns-custom
-  namespace: kube-system
-data:
-  %s.server: |
-    %s:53 {
-        errors
-        cache 30
-        reload
-        loadbalance
-        rewrite name exact %s cilium-gateway-adhar-gateway.adhar-system.svc.cluster.local
-        rewrite stop {
-            name regex (.*).%s cilium-gateway-adhar-gateway.adhar-system.svc.cluster.local answer auto
-        }
-        forward . /etc/resolv.conf
-    }`, domain, domain, domain, domain)
-
-	// Apply the custom CoreDNS configuration
-	cmd = exec.CommandContext(ctx, "kubectl", "apply", "-f", "-")
-	cmd.Stdin = strings.NewReader(coreDNSConfig)
-	output, err = cmd.CombinedOutput()
+// internalZoneBlocks renders a CoreDNS server block per zone that answers the
+// zone and its subdomains with the target service. The rewritten name is
+// answered by the block's own kubernetes plugin, since the upstream resolvers
+// know nothing about cluster.local.
+func internalZoneBlocks(zones []string, target string) string {
+	var b strings.Builder
+	for _, zone := range zones {
+		fmt.Fprintf(&b, `%s:53 {
+    errors
+    cache 30
+    loadbalance
+    rewrite name exact %s %s
+    rewrite stop {
+        name regex (.*)\.%s %s answer auto
+    }
+    kubernetes cluster.local in-addr.arpa ip6.arpa {
+        pods insecure
+        fallthrough in-addr.arpa ip6.arpa
+    }
+    forward . /etc/resolv.conf
+}
+`, zone, zone, target, strings.ReplaceAll(zone, ".", "\\."), target)
+	}
+	return b.String()
+}
+
+// withInternalZones returns corefile with the adhar-managed server blocks for
+// zones in place of any earlier ones.
+func withInternalZones(corefile string, zones []string, target string) string {
+	if i := strings.Index(corefile, internalZonesBegin); i >= 0 {
+		rest := corefile[i:]
+		if j := strings.Index(rest, internalZonesEnd); j >= 0 {
+			rest = rest[j+len(internalZonesEnd):]
+		} else {
+			rest = ""
+		}
+		corefile = corefile[:i] + strings.TrimLeft(rest, "\n")
+	}
+	corefile = strings.TrimRight(corefile, "\n")
+	if len(zones) == 0 {
+		return corefile + "\n"
+	}
+	return corefile + "\n" + internalZonesBegin + "\n" + internalZoneBlocks(zones, target) + internalZonesEnd + "\n"
+}
+
+// domainConfigMap records the domain setup for later commands such as
+// `adhar domain verify`. Only names and settings are stored, never credentials.
+func (m *Manager) domainConfigMap(cluster *types.Cluster, domain string) *corev1.ConfigMap {
+	data := map[string]string{
+		"domain":                 domain,
+		"provider":               cluster.Provider,
+		"tls-enabled":            strconv.FormatBool(m.config.TLS.Enabled),
+		"tls-environment":        m.config.TLS.Environment,
+		"ingress-controller":     m.config.Ingress.Provider,
+		"dns-provider":           m.config.DNS.Provider,
+		"dns-zone":               m.dnsZone(domain),
+		"dns-credentials-secret": m.config.DNS.CredentialsSecret,
+		"split-horizon":          strconv.FormatBool(m.config.DNS.SplitHorizon.Enabled),
+		"internal-zone":          m.config.DNS.SplitHorizon.InternalZone,
+		"internal-target":        m.internalTarget(cluster),
+	}
+	for k, v := range m.config.DNS.Config {
+		data["dns-config."+k] = v
+	}
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DomainConfigMapName,
+			Namespace: "adhar-system",
+			Labels:    map[string]string{"adhar.io/managed-by": "adhar"},
+		},
+		Data: data,
+	}
+}
+
+// setupInternalZone configures CoreDNS to resolve the zones to an in-cluster
+// service instead of their public records. The server blocks are written
+// into the Corefile itself: stock CoreDNS deployments import no extra
+// ConfigMaps.
+func (m *Manager) setupInternalZone(ctx context.Context, zones []string, target string) error {
+	cmd := exec.CommandContext(ctx, "kubectl", "get", "configmap", "coredns", "-n", "kube-system", "-o", "json")
+	output, err := cmd.Output()
 	if err != nil {
+		return fmt.Errorf("failed to read the CoreDNS configuration: %w", err)
+	}
+	var cm corev1.ConfigMap
+	if err := json.Unmarshal(output, &cm); err != nil {
+		return fmt.Errorf("failed to parse the CoreDNS configuration: %w", err)
+	}
+	corefile, ok := cm.Data["Corefile"]
+	if !ok {
+		return fmt.Errorf("kube-system/coredns has no Corefile")
+	}
+	cm.Data["Corefile"] = withInternalZones(corefile, zones, target)
+	coreDNSConfig, err := json.Marshal(cm)
+	if err != nil {
+		return fmt.Errorf("failed to render CoreDNS configuration: %w", err)
+	}
+
+	// Replace rather than apply so a concurrent change to the Corefile
+	// conflicts instead of being overwritten
+	cmd = exec.CommandContext(ctx, "kubectl", "replace", "-f", "-")
+	cmd.Stdin = bytes.NewReader(coreDNSConfig)
+	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to apply CoreDNS configuration: %w\nOutput: %s", err, string(output))
 	}
 
 	// Restart CoreDNS to pick up the new configuration
 	cmd = exec.CommandContext(ctx, "kubectl", "rollout", "restart", "deployment/coredns", "-n", "kube-system")
-	output, err = cmd.CombinedOutput()
-	if err != nil {
+	if _, err := cmd.CombinedOutput(); err != nil {
 		// Don't fail if restart fails, just warn
 		fmt.Printf("⚠️  Warning: Failed to restart CoreDNS: %v\n", err)
 	}
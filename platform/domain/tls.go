@@ -125,6 +125,32 @@ func createSelfSignedCertificate(sans []string) ([]byte, []byte, error) {
 		return nil, nil, fmt.Errorf("generating private key: %w", err)
 	}
 
+	certOut, err := signSelfSignedCertificate(privateKey, sans)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var keyB bytes.Buffer
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("marshal private key: %w", err)
+	}
+
+	err = pem.Encode(io.Writer(&keyB), &pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyBytes})
+	if err != nil {
+		return nil, nil, fmt.Errorf("encoding private key: %w", err)
+	}
+	privateKeyOut, err := io.ReadAll(&keyB)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading buffer: %w", err)
+	}
+
+	return certOut, privateKeyOut, nil
+}
+
+// signSelfSignedCertificate issues a new self-signed CA certificate for sans,
+// valid from now, and returns it PEM encoded.
+func signSelfSignedCertificate(privateKey *ecdsa.PrivateKey, sans []string) ([]byte, error) {
 	keyUsage := x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign
 	notBefore := time.Now()
 	notAfter := notBefore.Add(certificateValidLength)
@@ -132,7 +158,7 @@ func createSelfSignedCertificate(sans []string) ([]byte, []byte, error) {
 	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
 	serialNumber, err := rand.Int(rand.Reader, serialNumberLimit)
 	if err != nil {
-		return nil, nil, fmt.Errorf("generating certificate serial number: %w", err)
+		return nil, fmt.Errorf("generating certificate serial number: %w", err)
 	}
 
 	cert := x509.Certificate{
@@ -151,36 +177,15 @@ func createSelfSignedCertificate(sans []string) ([]byte, []byte, error) {
 
 	certBytes, err := x509.CreateCertificate(rand.Reader, &cert, &cert, &privateKey.PublicKey, privateKey)
 	if err != nil {
-		return nil, nil, fmt.Errorf("creating certificate: %w", err)
+		return nil, fmt.Errorf("creating certificate: %w", err)
 	}
 
 	var certB bytes.Buffer
-	var keyB bytes.Buffer
 	err = pem.Encode(io.Writer(&certB), &pem.Block{Type: "CERTIFICATE", Bytes: certBytes})
 	if err != nil {
-		return nil, nil, fmt.Errorf("encoding cert: %w", err)
+		return nil, fmt.Errorf("encoding cert: %w", err)
 	}
-
-	certOut, err := io.ReadAll(&certB)
-	if err != nil {
-		return nil, nil, fmt.Errorf("reading buffer: %w", err)
-	}
-
-	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
-	if err != nil {
-		return nil, nil, fmt.Errorf("marshal private key: %w", err)
-	}
-
-	err = pem.Encode(io.Writer(&keyB), &pem.Block{Type: "PRIVATE KEY", Bytes: privateKeyBytes})
-	if err != nil {
-		return nil, nil, fmt.Errorf("encoding private key: %w", err)
-	}
-	privateKeyOut, err := io.ReadAll(&keyB)
-	if err != nil {
-		return nil, nil, fmt.Errorf("reading buffer: %w", err)
-	}
-
-	return certOut, privateKeyOut, nil
+	return certB.Bytes(), nil
 }
 
 func setupSelfSignedCertificate(ctx context.Context, logger logr.Logger, kubeclient client.Client, config v1alpha1.BuildCustomizationSpec) ([]byte, error) {
@@ -225,3 +230,45 @@ func setupSelfSignedCertificate(ctx context.Context, logger logr.Logger, kubecli
 	}
 	return cert, nil
 }
+
+// GetOrCreateSelfSignedCertificate returns the certificate and key stored in
+// the TLS secret name/namespace, creating the secret from a new self-signed
+// certificate for sans when it does not exist. The certificate is its own CA,
+// so it doubles as the CA bundle clients should trust.
+func GetOrCreateSelfSignedCertificate(ctx context.Context, kubeClient client.Client, name, namespace string, sans []string) ([]byte, []byte, error) {
+	return getOrCreateIngressCertificateAndKey(ctx, kubeClient, name, namespace, sans)
+}
+
+// CreateSelfSignedCertificate returns a new self-signed CA certificate and
+// PKCS#8 key for sans, both PEM encoded.
+func CreateSelfSignedCertificate(sans []string) ([]byte, []byte, error) {
+	return createSelfSignedCertificate(sans)
+}
+
+// ResignSelfSignedCertificate issues a fresh validity period for a
+// certificate created by CreateSelfSignedCertificate, keeping its key and
+// SANs. Clients that trust the old certificate keep verifying the new one,
+// since subject and public key are unchanged.
+func ResignSelfSignedCertificate(certPEM, keyPEM []byte) ([]byte, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("decoding certificate: no PEM data")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing certificate: %w", err)
+	}
+	keyBlock, _ := pem.Decode(keyPEM)
+	if keyBlock == nil {
+		return nil, fmt.Errorf("decoding private key: no PEM data")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+	privateKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signSelfSignedCertificate(privateKey, cert.DNSNames)
+}
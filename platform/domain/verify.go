@@ -0,0 +1,356 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"adhar-io/adhar/platform/types"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CheckStatus is the outcome of a verification check.
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+	CheckSkip CheckStatus = "skip"
+)
+
+// Check is a single verification result.
+type Check struct {
+	Name    string      `json:"name"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+// Resolver is the part of net.Resolver used for verification.
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupNS(ctx context.Context, name string) ([]*net.NS, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// VerifyOptions describes the domain setup to verify.
+type VerifyOptions struct {
+	// Domain is the platform domain.
+	Domain string
+	// Hosts are additional hostnames that must resolve publicly.
+	Hosts []string
+	// DNS is the DNS provider configuration.
+	DNS types.DNSConfig
+	// TLSEnvironment selects the Let's Encrypt issuer (staging, production).
+	TLSEnvironment string
+	// Resolver answers the public lookups; net.DefaultResolver when nil.
+	Resolver Resolver
+	// ClusterResolver queries the cluster DNS Service. The split-horizon
+	// zones fail verification without it.
+	ClusterResolver Resolver
+}
+
+// clusterIssuerGVK is the cert-manager ClusterIssuer kind.
+var clusterIssuerGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "ClusterIssuer"}
+
+// certManagerNamespace is where cert-manager resolves ClusterIssuer secrets.
+const certManagerNamespace = "cert-manager"
+
+// internalZoneProbe is the host looked up in each internal zone; any name in
+// the zone resolves to the internal target.
+const internalZoneProbe = "adhar-verify"
+
+// ClusterDomainConfig reads the domain setup recorded by SetupDomain. It
+// returns the platform domain and its configuration.
+func ClusterDomainConfig(ctx context.Context, c client.Client) (string, *types.DomainConfig, error) {
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "adhar-system", Name: DomainConfigMapName}, cm); err != nil {
+		return "", nil, fmt.Errorf("failed to read domain configuration: %w", err)
+	}
+	data := cm.Data
+	cfg := &types.DomainConfig{
+		BaseDomain: data["domain"],
+		TLS: types.TLSConfig{
+			Enabled:     data["tls-enabled"] == "true",
+			Environment: data["tls-environment"],
+		},
+		DNS: types.DNSConfig{
+			Provider:          data["dns-provider"],
+			Zone:              data["dns-zone"],
+			CredentialsSecret: data["dns-credentials-secret"],
+			SplitHorizon: types.SplitHorizonConfig{
+				Enabled:      data["split-horizon"] == "true",
+				InternalZone: data["internal-zone"],
+				Target:       data["internal-target"],
+			},
+		},
+		Ingress: types.IngressConfig{Provider: data["ingress-controller"]},
+	}
+	for k, v := range data {
+		if key, ok := strings.CutPrefix(k, "dns-config."); ok {
+			if cfg.DNS.Config == nil {
+				cfg.DNS.Config = map[string]string{}
+			}
+			cfg.DNS.Config[key] = v
+		}
+	}
+	return data["domain"], cfg, nil
+}
+
+// Verify checks that the domain resolves publicly and that ACME DNS-01
+// challenges can be answered: the provider settings, zone delegation, stale
+// challenge records and, when c is not nil, the credentials Secrets, the
+// ClusterIssuer and the split-horizon CoreDNS zone.
+func Verify(ctx context.Context, c client.Client, opts VerifyOptions) []Check {
+	if opts.Resolver == nil {
+		opts.Resolver = net.DefaultResolver
+	}
+	r := opts.Resolver
+	m := &Manager{config: &types.DomainConfig{BaseDomain: opts.Domain, DNS: opts.DNS}}
+	cfg := m.dnsConfig(opts.Domain)
+
+	var checks []Check
+	for _, host := range append([]string{opts.Domain}, opts.Hosts...) {
+		checks = append(checks, checkResolves(ctx, r, host))
+	}
+	// Records of the internal zone must never be published
+	if internal := cfg.SplitHorizon.InternalZone; cfg.SplitHorizon.Enabled && internal != "" {
+		if addrs, err := r.LookupHost(ctx, internal); err == nil && len(addrs) > 0 {
+			checks = append(checks, Check{"internal zone " + internal + " (public)", CheckWarn, "resolves publicly to " + strings.Join(addrs, ", ") + "; internal records should not be published"})
+		}
+	}
+
+	if cfg.Provider == "" {
+		checks = append(checks, Check{"dns provider", CheckWarn, "no DNS provider configured; records are not published and certificates cannot use DNS-01"})
+		return append(checks, verifyCluster(ctx, c, m, opts, nil)...)
+	}
+
+	p, err := m.dnsProvider(opts.Domain)
+	if err != nil {
+		checks = append(checks, Check{"dns provider", CheckFail, err.Error()})
+		return checks
+	}
+	checks = append(checks, Check{"dns provider", CheckPass, fmt.Sprintf("%s manages zone %s", p.Name(), cfg.Zone)})
+	checks = append(checks, checkZone(ctx, r, opts.Domain, cfg.Zone), checkChallenge(ctx, r, opts.Domain))
+	return append(checks, verifyCluster(ctx, c, m, opts, p)...)
+}
+
+func checkResolves(ctx context.Context, r Resolver, host string) Check {
+	name := "resolve " + host
+	addrs, err := r.LookupHost(ctx, host)
+	if err != nil {
+		return Check{name, CheckFail, err.Error()}
+	}
+	return Check{name, CheckPass, strings.Join(addrs, ", ")}
+}
+
+// checkZone verifies the zone is delegated (has NS records) and covers the domain.
+func checkZone(ctx context.Context, r Resolver, domain, zone string) Check {
+	name := "zone " + zone
+	if domain != zone && !strings.HasSuffix(domain, "."+zone) {
+		return Check{name, CheckFail, fmt.Sprintf("%s is outside zone %s; its DNS-01 challenges cannot be answered", domain, zone)}
+	}
+	ns, err := r.LookupNS(ctx, zone)
+	if err != nil || len(ns) == 0 {
+		return Check{name, CheckFail, fmt.Sprintf("no NS records for %s; delegate the zone to the DNS provider's nameservers", zone)}
+	}
+	hosts := make([]string, 0, len(ns))
+	for _, n := range ns {
+		hosts = append(hosts, strings.TrimSuffix(n.Host, "."))
+	}
+	sort.Strings(hosts)
+	return Check{name, CheckPass, "delegated to " + strings.Join(hosts, ", ")}
+}
+
+// checkChallenge looks for _acme-challenge CNAME delegation and leftover TXT
+// records from earlier challenges.
+func checkChallenge(ctx context.Context, r Resolver, domain string) Check {
+	challenge := "_acme-challenge." + domain
+	name := "acme challenge"
+	if cname, err := r.LookupCNAME(ctx, challenge); err == nil {
+		if target := strings.TrimSuffix(cname, "."); target != challenge {
+			return Check{name, CheckWarn, fmt.Sprintf("%s is a CNAME to %s; the issuer's solver needs cnameStrategy: Follow and write access to that zone", challenge, target)}
+		}
+	}
+	if txt, err := r.LookupTXT(ctx, challenge); err == nil && len(txt) > 0 {
+		return Check{name, CheckWarn, fmt.Sprintf("%d stale TXT record(s) at %s; remove them if no issuance is in progress", len(txt), challenge)}
+	}
+	return Check{name, CheckPass, challenge + " is free for DNS-01 challenges"}
+}
+
+// verifyCluster runs the checks that need the cluster. p is nil when no DNS
+// provider is configured.
+func verifyCluster(ctx context.Context, c client.Client, m *Manager, opts VerifyOptions, p DNSProvider) []Check {
+	if c == nil {
+		return []Check{{"cluster", CheckSkip, "cluster not reachable; in-cluster checks skipped"}}
+	}
+	cfg := m.dnsConfig(opts.Domain)
+
+	var checks []Check
+	if p != nil {
+		secret := CredentialsSecretName(p, cfg)
+		if keys := p.CredentialKeys(cfg); len(keys) > 0 {
+			for _, ns := range []string{"kube-system", certManagerNamespace} {
+				checks = append(checks, checkSecret(ctx, c, ns, secret, keys))
+			}
+		}
+		checks = append(checks, checkIssuer(ctx, c, IssuerName(opts.TLSEnvironment), p, cfg, secret))
+	}
+
+	if cfg.SplitHorizon.Enabled {
+		checks = append(checks, checkInternalZones(ctx, opts.ClusterResolver, m.internalZones(opts.Domain), cfg.SplitHorizon.Target)...)
+	}
+	return checks
+}
+
+func checkSecret(ctx context.Context, c client.Client, namespace, name string, keys []string) Check {
+	check := Check{Name: fmt.Sprintf("credentials %s/%s", namespace, name)}
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+		check.Status, check.Message = CheckFail, fmt.Sprintf("secret not found (needs keys %s)", strings.Join(keys, ", "))
+		if !apierrors.IsNotFound(err) {
+			check.Message = err.Error()
+		}
+		return check
+	}
+	var missing []string
+	for _, k := range keys {
+		if len(secret.Data[k]) == 0 {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		check.Status, check.Message = CheckFail, "missing keys "+strings.Join(missing, ", ")
+		return check
+	}
+	check.Status, check.Message = CheckPass, "has "+strings.Join(keys, ", ")
+	return check
+}
+
+// checkIssuer verifies the ClusterIssuer has a DNS-01 solver for the provider
+// and has registered its ACME account.
+func checkIssuer(ctx context.Context, c client.Client, issuerName string, p DNSProvider, cfg types.DNSConfig, secret string) Check {
+	check := Check{Name: "issuer " + issuerName}
+	issuer := &unstructured.Unstructured{}
+	issuer.SetGroupVersionKind(clusterIssuerGVK)
+	if err := c.Get(ctx, client.ObjectKey{Name: issuerName}, issuer); err != nil {
+		check.Status = CheckFail
+		switch {
+		case meta.IsNoMatchError(err):
+			check.Message = "cert-manager is not installed"
+		case apierrors.IsNotFound(err):
+			check.Message = "ClusterIssuer not found"
+		default:
+			check.Message = err.Error()
+		}
+		return check
+	}
+
+	var solverKey string
+	for k := range p.Solver(cfg, secret) {
+		solverKey = k
+	}
+	solvers, _, _ := unstructured.NestedSlice(issuer.Object, "spec", "acme", "solvers")
+	found := false
+	for _, s := range solvers {
+		solver, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if _, ok, _ := unstructured.NestedMap(solver, "dns01", solverKey); ok {
+			found = true
+			break
+		}
+	}
+	if !found {
+		check.Status, check.Message = CheckFail, fmt.Sprintf("no dns01 %s solver; re-run domain setup", solverKey)
+		return check
+	}
+
+	ready, reason := issuerReady(issuer)
+	if !ready {
+		check.Status, check.Message = CheckWarn, "dns01 solver present but ACME account not ready: "+reason
+		return check
+	}
+	check.Status, check.Message = CheckPass, fmt.Sprintf("dns01 %s solver ready", solverKey)
+	return check
+}
+
+func issuerReady(issuer *unstructured.Unstructured) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(issuer.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != "Ready" {
+			continue
+		}
+		msg, _ := cond["message"].(string)
+		return cond["status"] == "True", msg
+	}
+	return false, "no Ready condition"
+}
+
+func checkInternalZones(ctx context.Context, r Resolver, zones []string, target string) []Check {
+	if r == nil {
+		return []Check{{"split horizon", CheckFail, "cluster DNS Service not reachable; internal zones cannot be resolved"}}
+	}
+	// Setups recorded before the target was stored used one of the defaults
+	targets := []string{target}
+	if target == "" {
+		targets = []string{gatewayTarget, ingressTarget}
+	}
+	want := map[string]bool{}
+	var lookupErr error
+	for _, t := range targets {
+		addrs, err := r.LookupHost(ctx, strings.TrimSuffix(t, ".")+".")
+		if err != nil {
+			lookupErr = err
+		}
+		for _, a := range addrs {
+			want[a] = true
+		}
+	}
+	if len(want) == 0 {
+		return []Check{{"split horizon", CheckFail, fmt.Sprintf("target %s does not resolve in the cluster: %v", strings.Join(targets, " or "), lookupErr)}}
+	}
+
+	checks := make([]Check, 0, len(zones))
+	for _, zone := range zones {
+		name := "internal zone " + zone
+		// Absolute names keep the search domains out of the lookup
+		probe := internalZoneProbe + "." + zone
+		addrs, err := r.LookupHost(ctx, probe+".")
+		if err != nil {
+			checks = append(checks, Check{name, CheckFail, fmt.Sprintf("%s does not resolve through cluster DNS: %v", probe, err)})
+			continue
+		}
+		served := false
+		for _, a := range addrs {
+			served = served || want[a]
+		}
+		if !served {
+			checks = append(checks, Check{name, CheckFail, fmt.Sprintf("%s resolves to %s through cluster DNS, not to %s; CoreDNS does not serve the zone", probe, strings.Join(addrs, ", "), strings.Join(targets, " or "))})
+			continue
+		}
+		checks = append(checks, Check{name, CheckPass, fmt.Sprintf("%s resolves to %s through cluster DNS", probe, strings.Join(addrs, ", "))})
+	}
+	return checks
+}
+
+// Failed reports whether any check failed.
+func Failed(checks []Check) bool {
+	for _, c := range checks {
+		if c.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"adhar-io/adhar/platform/types"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var errNoSuchHost = errors.New("no such host")
+
+// fakeResolver answers from static record tables.
+type fakeResolver struct {
+	hosts map[string][]string
+	ns    map[string][]string
+	cname map[string]string
+	txt   map[string][]string
+}
+
+func (r fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if addrs, ok := r.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, errNoSuchHost
+}
+
+func (r fakeResolver) LookupNS(_ context.Context, name string) ([]*net.NS, error) {
+	var out []*net.NS
+	for _, h := range r.ns[name] {
+		out = append(out, &net.NS{Host: h})
+	}
+	if len(out) == 0 {
+		return nil, errNoSuchHost
+	}
+	return out, nil
+}
+
+func (r fakeResolver) LookupCNAME(_ context.Context, host string) (string, error) {
+	if target, ok := r.cname[host]; ok {
+		return target, nil
+	}
+	return "", errNoSuchHost
+}
+
+func (r fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	if txt, ok := r.txt[name]; ok {
+		return txt, nil
+	}
+	return nil, errNoSuchHost
+}
+
+func publicDNS() fakeResolver {
+	return fakeResolver{
+		hosts: map[string][]string{"example.com": {"203.0.113.10"}, "argocd.example.com": {"203.0.113.10"}},
+		ns:    map[string][]string{"example.com": {"ns-2.awsdns.org.", "ns-1.awsdns.com."}},
+	}
+}
+
+// clusterDNS answers like CoreDNS serving the internal zones of example.com.
+func clusterDNS() fakeResolver {
+	return fakeResolver{hosts: map[string][]string{
+		ingressTarget + ".":           {"10.96.0.20"},
+		"adhar-verify.example.com.":   {"10.96.0.20"},
+		"adhar-verify.corp.internal.": {"10.96.0.20"},
+	}}
+}
+
+func statuses(checks []Check) map[string]CheckStatus {
+	out := map[string]CheckStatus{}
+	for _, c := range checks {
+		out[c.Name] = c.Status
+	}
+	return out
+}
+
+func route53DNS() types.DNSConfig {
+	return types.DNSConfig{
+		Provider:     "route53",
+		Config:       map[string]string{"region": "us-east-1"},
+		SplitHorizon: types.SplitHorizonConfig{Enabled: true, InternalZone: "corp.internal"},
+	}
+}
+
+func readyIssuer(solver string) *unstructured.Unstructured {
+	issuer := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"acme": map[string]interface{}{"solvers": []interface{}{
+			map[string]interface{}{"dns01": map[string]interface{}{solver: map[string]interface{}{}}},
+			map[string]interface{}{"http01": map[string]interface{}{}},
+		}}},
+		"status": map[string]interface{}{"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True"},
+		}},
+	}}
+	issuer.SetGroupVersionKind(clusterIssuerGVK)
+	issuer.SetName("letsencrypt-prod")
+	return issuer
+}
+
+func newVerifyClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	scheme.AddKnownTypeWithName(clusterIssuerGVK, &unstructured.Unstructured{})
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func credentials(namespace string, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "aws-credentials", Namespace: namespace}, Data: data}
+}
+
+func TestVerifyReady(t *testing.T) {
+	keys := map[string][]byte{"access-key-id": []byte("id"), "secret-access-key": []byte("secret")}
+	c := newVerifyClient(t, credentials("kube-system", keys), credentials("cert-manager", keys), readyIssuer("route53"))
+
+	checks := Verify(context.Background(), c, VerifyOptions{
+		Domain:          "example.com",
+		Hosts:           []string{"argocd.example.com"},
+		DNS:             route53DNS(),
+		TLSEnvironment:  "production",
+		Resolver:        publicDNS(),
+		ClusterResolver: clusterDNS(),
+	})
+	assert.False(t, Failed(checks), "%+v", checks)
+	assert.Equal(t, map[string]CheckStatus{
+		"resolve example.com":                      CheckPass,
+		"resolve argocd.example.com":               CheckPass,
+		"dns provider":                             CheckPass,
+		"zone example.com":                         CheckPass,
+		"acme challenge":                           CheckPass,
+		"credentials kube-system/aws-credentials":  CheckPass,
+		"credentials cert-manager/aws-credentials": CheckPass,
+		"issuer letsencrypt-prod":                  CheckPass,
+		"internal zone example.com":                CheckPass,
+		"internal zone corp.internal":              CheckPass,
+	}, statuses(checks))
+	for _, c := range checks {
+		if c.Name == "zone example.com" {
+			assert.Equal(t, "delegated to ns-1.awsdns.com, ns-2.awsdns.org", c.Message)
+		}
+	}
+}
+
+func TestVerifyProblems(t *testing.T) {
+	r := publicDNS()
+	delete(r.ns, "example.com")
+	r.txt = map[string][]string{"_acme-challenge.example.com": {"stale"}}
+	r.hosts["corp.internal"] = []string{"198.51.100.7"}
+
+	// Missing cert-manager secret keys, an issuer without a DNS-01 solver and
+	// CoreDNS forwarding the zones upstream instead of serving them
+	cluster := clusterDNS()
+	cluster.hosts["adhar-verify.example.com."] = []string{"203.0.113.10"}
+	delete(cluster.hosts, "adhar-verify.corp.internal.")
+	issuer := readyIssuer("cloudflare")
+	c := newVerifyClient(t,
+		credentials("kube-system", map[string][]byte{"access-key-id": []byte("id"), "secret-access-key": []byte("secret")}),
+		credentials("cert-manager", map[string][]byte{"access-key-id": []byte("id")}),
+		issuer,
+	)
+	checks := Verify(context.Background(), c, VerifyOptions{
+		Domain:          "example.com",
+		Hosts:           []string{"grafana.example.com"},
+		DNS:             route53DNS(),
+		TLSEnvironment:  "production",
+		Resolver:        r,
+		ClusterResolver: cluster,
+	})
+	assert.True(t, Failed(checks))
+	got := statuses(checks)
+	assert.Equal(t, CheckFail, got["resolve grafana.example.com"])
+	assert.Equal(t, CheckFail, got["zone example.com"])
+	assert.Equal(t, CheckWarn, got["acme challenge"])
+	assert.Equal(t, CheckPass, got["credentials kube-system/aws-credentials"])
+	assert.Equal(t, CheckFail, got["credentials cert-manager/aws-credentials"])
+	assert.Equal(t, CheckFail, got["issuer letsencrypt-prod"])
+	assert.Equal(t, CheckFail, got["internal zone example.com"])
+	assert.Equal(t, CheckFail, got["internal zone corp.internal"])
+	assert.Equal(t, CheckWarn, got["internal zone corp.internal (public)"])
+
+	// Without the cluster DNS Service the zones cannot pass
+	checks = Verify(context.Background(), c, VerifyOptions{Domain: "example.com", DNS: route53DNS(), Resolver: r})
+	assert.Equal(t, CheckFail, statuses(checks)["split horizon"])
+	assert.NotContains(t, statuses(checks), "internal zone example.com")
+}
+
+func TestVerifyWithoutCluster(t *testing.T) {
+	r := publicDNS()
+	r.cname = map[string]string{"_acme-challenge.example.com": "example-com.acme.example.net."}
+
+	checks := Verify(context.Background(), nil, VerifyOptions{Domain: "example.com", DNS: route53DNS(), Resolver: r})
+	got := statuses(checks)
+	assert.Equal(t, CheckWarn, got["acme challenge"])
+	assert.Equal(t, CheckSkip, got["cluster"])
+
+	// An unusable provider stops before any provider checks
+	checks = Verify(context.Background(), nil, VerifyOptions{Domain: "example.com", DNS: types.DNSConfig{Provider: "route53"}, Resolver: r})
+	assert.Equal(t, CheckFail, statuses(checks)["dns provider"])
+	assert.NotContains(t, statuses(checks), "zone example.com")
+
+	// A zone that does not contain the domain cannot answer its challenges
+	dns := route53DNS()
+	dns.Zone = "example.org"
+	assert.Equal(t, CheckFail, statuses(Verify(context.Background(), nil, VerifyOptions{Domain: "example.com", DNS: dns, Resolver: r}))["zone example.org"])
+}
+
+func TestClusterDomainConfig(t *testing.T) {
+	dns := route53DNS()
+	dns.CredentialsSecret = "route53"
+	m := NewManager(&types.DomainConfig{
+		TLS:     types.TLSConfig{Enabled: true, Environment: "production"},
+		DNS:     dns,
+		Ingress: types.IngressConfig{Provider: "nginx"},
+	}, "")
+	c := newVerifyClient(t, m.domainConfigMap(&types.Cluster{Provider: "aws"}, "example.com"))
+
+	name, cfg, err := ClusterDomainConfig(context.Background(), c)
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", name)
+	assert.Equal(t, "production", cfg.TLS.Environment)
+	dns.Zone = "example.com"
+	dns.SplitHorizon.Target = ingressTarget
+	assert.Equal(t, dns, cfg.DNS)
+}
@@ -0,0 +1,299 @@
+// Package notify delivers platform events (AdharPlatform condition changes,
+// DataPlane readiness transitions, degraded ArgoCD applications, failed
+// backups) to outbound sinks: Slack and Microsoft Teams incoming webhooks and
+// generic JSON webhooks. Sinks are stored as labelled Secrets in adhar-system
+// (see store.go) and managed with `adhar webhook sink`.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"text/template"
+	"time"
+
+	provider "adhar-io/adhar/platform/providers"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Event types published by the platform controllers.
+const (
+	EventPlatformCondition = "platform.condition"
+	EventDataPlaneReady    = "dataplane.ready"
+	EventAppDegraded       = "application.degraded"
+	EventAppRecovered      = "application.recovered"
+	EventBackupFailed      = "backup.failed"
+	EventTest              = "test"
+)
+
+// Event severities.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Sink types.
+const (
+	SinkSlack   = "slack"
+	SinkTeams   = "teams"
+	SinkWebhook = "webhook"
+)
+
+// SinkTypes lists the supported sink types.
+var SinkTypes = []string{SinkSlack, SinkTeams, SinkWebhook}
+
+// Event is one notification.
+type Event struct {
+	Type      string    `json:"type"`
+	Severity  string    `json:"severity"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message,omitempty"`
+	Kind      string    `json:"kind,omitempty"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// Resource names the object the event is about, namespace/Kind/name.
+func (e Event) Resource() string {
+	if e.Kind == "" {
+		return e.Name
+	}
+	if e.Namespace == "" {
+		return e.Kind + "/" + e.Name
+	}
+	return e.Namespace + "/" + e.Kind + "/" + e.Name
+}
+
+// Sink is a configured notification destination.
+type Sink struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	URL  string `json:"url"`
+	// Events filters the event types delivered; empty means all. Entries may
+	// end in ".*" to match a family, e.g. "dataplane.*".
+	Events []string `json:"events,omitempty"`
+	// Template is a text/template rendered with the Event. For Slack and
+	// Teams it replaces the message text; for webhooks it is the whole body.
+	Template string            `json:"template,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// Matches reports whether the sink subscribes to eventType.
+func (s Sink) Matches(eventType string) bool {
+	if len(s.Events) == 0 || eventType == EventTest {
+		return true
+	}
+	for _, pattern := range s.Events {
+		if ok, _ := path.Match(pattern, eventType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the sink configuration, including its template.
+func (s Sink) Validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("sink name is required")
+	}
+	// The name becomes part of the sink's Secret name.
+	if errs := validation.IsDNS1123Subdomain(secretName(s.Name)); len(errs) > 0 {
+		return fmt.Errorf("invalid sink name %q: %s", s.Name, strings.Join(errs, "; "))
+	}
+	switch s.Type {
+	case SinkSlack, SinkTeams, SinkWebhook:
+	default:
+		return fmt.Errorf("unknown sink type %q (want one of %s)", s.Type, strings.Join(SinkTypes, ", "))
+	}
+	if !strings.HasPrefix(s.URL, "https://") && !strings.HasPrefix(s.URL, "http://") {
+		return fmt.Errorf("sink URL must be http(s), got %q", s.URL)
+	}
+	if s.Template != "" {
+		if _, err := template.New(s.Name).Parse(s.Template); err != nil {
+			return fmt.Errorf("parsing template: %w", err)
+		}
+	}
+	return nil
+}
+
+// defaultText is the message text used when a Slack or Teams sink has no
+// template.
+const defaultText = `{{ .Title }}{{ with .Resource }} ({{ . }}){{ end }}{{ with .Message }}: {{ . }}{{ end }}`
+
+// Payload renders the request body sent to the sink for e.
+func (s Sink) Payload(e Event) ([]byte, error) {
+	if s.Type == SinkWebhook && s.Template == "" {
+		return json.Marshal(e)
+	}
+	text, err := render(s.Name, valueOr(s.Template, defaultText), e)
+	if err != nil {
+		return nil, err
+	}
+	switch s.Type {
+	case SinkSlack:
+		return json.Marshal(map[string]string{"text": text})
+	case SinkTeams:
+		return json.Marshal(map[string]interface{}{
+			"@type":      "MessageCard",
+			"@context":   "http://schema.org/extensions",
+			"summary":    e.Title,
+			"themeColor": themeColor(e.Severity),
+			"title":      e.Title,
+			"text":       text,
+		})
+	}
+	return []byte(text), nil
+}
+
+func render(name, tmpl string, e Event) (string, error) {
+	t, err := template.New(name).Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parsing template for sink %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, e); err != nil {
+		return "", fmt.Errorf("rendering template for sink %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+func themeColor(severity string) string {
+	switch severity {
+	case SeverityCritical:
+		return "D70000"
+	case SeverityWarning:
+		return "FFA500"
+	}
+	return "2EB886"
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
+
+// Notifier publishes events to the sinks stored in the cluster.
+type Notifier struct {
+	Client     client.Client
+	Namespace  string
+	HTTPClient *http.Client
+	// Retry controls redelivery of failed requests; nil uses DefaultRetryConfig.
+	Retry *provider.RetryConfig
+}
+
+// NewNotifier returns a Notifier reading sinks from namespace.
+func NewNotifier(c client.Client, namespace string) *Notifier {
+	return &Notifier{Client: c, Namespace: namespace}
+}
+
+// DefaultRetryConfig retries a delivery a few times over roughly half a
+// minute; notifications are not worth holding a reconcile for longer.
+func DefaultRetryConfig() *provider.RetryConfig {
+	return &provider.RetryConfig{
+		MaxAttempts:   4,
+		InitialDelay:  2 * time.Second,
+		MaxDelay:      15 * time.Second,
+		BackoffFactor: 2.0,
+		Timeout:       time.Minute,
+	}
+}
+
+// Publish delivers e in the background so reconcilers never wait on a slow
+// or failing sink. Failures are logged.
+func (n *Notifier) Publish(ctx context.Context, e Event) {
+	if n == nil {
+		return
+	}
+	logger := log.FromContext(ctx)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		if err := n.Send(ctx, e); err != nil {
+			logger.Error(err, "Delivering notification", "event", e.Type, "resource", e.Resource())
+		}
+	}()
+}
+
+// Send delivers e to every matching sink and returns the combined errors.
+func (n *Notifier) Send(ctx context.Context, e Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	sinks, err := ListSinks(ctx, n.Client, n.Namespace)
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, s := range sinks {
+		if !s.Matches(e.Type) {
+			continue
+		}
+		if err := n.Deliver(ctx, s, e); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Deliver sends e to one sink, retrying transient failures.
+func (n *Notifier) Deliver(ctx context.Context, s Sink, e Event) error {
+	body, err := s.Payload(e)
+	if err != nil {
+		return err
+	}
+	retry := n.Retry
+	if retry == nil {
+		retry = DefaultRetryConfig()
+	}
+	httpClient := n.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return provider.Retry(ctx, retry, "notify "+s.Name, func() error {
+		return post(ctx, httpClient, s, body)
+	})
+}
+
+// post sends one request. Transport failures, 429 and 5xx responses are
+// returned as NetworkErrors so providers.Retry tries again; other non-2xx
+// responses are permanent.
+func post(ctx context.Context, httpClient *http.Client, s Sink, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "adhar-notify")
+	for k, v := range s.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return &provider.NetworkError{Operation: "notify", Provider: s.Name, Reason: err.Error()}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return &provider.NetworkError{Operation: "notify", Provider: s.Name, Reason: resp.Status}
+	}
+	return fmt.Errorf("sink %s rejected the notification: %s %s", s.Name, resp.Status, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,217 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	provider "adhar-io/adhar/platform/providers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "adhar-system"
+
+// receiver records the requests sent to it and answers with the queued
+// status codes, then 200.
+type receiver struct {
+	mu       sync.Mutex
+	bodies   [][]byte
+	headers  []http.Header
+	statuses []int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) requests() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.bodies)
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, string) {
+	t.Helper()
+	r := &receiver{statuses: statuses}
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return r, srv.URL
+}
+
+func fastRetry() *provider.RetryConfig {
+	return &provider.RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, BackoffFactor: 1, Timeout: 5 * time.Second}
+}
+
+func testNotifier(t *testing.T) *Notifier {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	n := NewNotifier(fake.NewClientBuilder().WithScheme(scheme).Build(), testNamespace)
+	n.Retry = fastRetry()
+	return n
+}
+
+var degraded = Event{
+	Type:      EventAppDegraded,
+	Severity:  SeverityWarning,
+	Title:     "Application shop is degraded",
+	Message:   "Deployment shop has 0/2 ready replicas",
+	Kind:      "Application",
+	Namespace: "adhar-system",
+	Name:      "shop",
+	Reason:    "Degraded",
+}
+
+func TestPayload(t *testing.T) {
+	slack, err := Sink{Name: "s", Type: SinkSlack}.Payload(degraded)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"text":"Application shop is degraded (adhar-system/Application/shop): Deployment shop has 0/2 ready replicas"}`, string(slack))
+
+	teams, err := Sink{Name: "t", Type: SinkTeams}.Payload(degraded)
+	require.NoError(t, err)
+	var card map[string]string
+	require.NoError(t, json.Unmarshal(teams, &card))
+	assert.Equal(t, "MessageCard", card["@type"])
+	assert.Equal(t, "FFA500", card["themeColor"])
+	assert.Equal(t, degraded.Title, card["title"])
+
+	hook, err := Sink{Name: "w", Type: SinkWebhook}.Payload(degraded)
+	require.NoError(t, err)
+	var got Event
+	require.NoError(t, json.Unmarshal(hook, &got))
+	assert.Equal(t, degraded, got)
+
+	templated, err := Sink{Name: "w", Type: SinkWebhook, Template: `{"alert":"{{ .Type }}","target":"{{ .Resource }}"}`}.Payload(degraded)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"alert":"application.degraded","target":"adhar-system/Application/shop"}`, string(templated))
+
+	custom, err := Sink{Name: "s", Type: SinkSlack, Template: `[{{ .Severity }}] {{ .Name }}`}.Payload(degraded)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"text":"[warning] shop"}`, string(custom))
+}
+
+func TestSinkMatchesAndValidate(t *testing.T) {
+	all := Sink{}
+	assert.True(t, all.Matches(EventBackupFailed))
+
+	s := Sink{Events: []string{"dataplane.*", EventBackupFailed}}
+	assert.True(t, s.Matches(EventDataPlaneReady))
+	assert.True(t, s.Matches(EventBackupFailed))
+	assert.False(t, s.Matches(EventAppDegraded))
+	assert.True(t, s.Matches(EventTest), "test events reach every sink")
+
+	assert.NoError(t, Sink{Name: "ok", Type: SinkSlack, URL: "https://hooks.slack.com/x"}.Validate())
+	assert.ErrorContains(t, Sink{Name: "x", Type: "pagerduty", URL: "https://x"}.Validate(), "unknown sink type")
+	assert.ErrorContains(t, Sink{Name: "x", Type: SinkWebhook, URL: "ftp://x"}.Validate(), "http(s)")
+	assert.ErrorContains(t, Sink{Name: "x", Type: SinkWebhook, URL: "https://x", Template: "{{ .Title"}.Validate(), "parsing template")
+	assert.ErrorContains(t, Sink{Name: "Ops Alerts", Type: SinkSlack, URL: "https://x"}.Validate(), "invalid sink name")
+}
+
+func TestDeliverRetriesTransientFailures(t *testing.T) {
+	r, url := newReceiver(t, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	n := testNotifier(t)
+
+	s := Sink{Name: "hook", Type: SinkWebhook, URL: url, Headers: map[string]string{"Authorization": "Bearer token"}}
+	require.NoError(t, n.Deliver(context.Background(), s, degraded))
+	assert.Equal(t, 3, r.requests())
+	assert.Equal(t, "Bearer token", r.headers[2].Get("Authorization"))
+	assert.Equal(t, "application/json", r.headers[2].Get("Content-Type"))
+}
+
+func TestDeliverDoesNotRetryRejections(t *testing.T) {
+	r, url := newReceiver(t, http.StatusBadRequest)
+	n := testNotifier(t)
+
+	err := n.Deliver(context.Background(), Sink{Name: "hook", Type: SinkWebhook, URL: url}, degraded)
+	assert.ErrorContains(t, err, "sink hook rejected the notification: 400")
+	assert.Equal(t, 1, r.requests())
+}
+
+func TestDeliverGivesUp(t *testing.T) {
+	r, url := newReceiver(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+	n := testNotifier(t)
+
+	err := n.Deliver(context.Background(), Sink{Name: "hook", Type: SinkWebhook, URL: url}, degraded)
+	assert.Error(t, err)
+	assert.Equal(t, 3, r.requests())
+}
+
+func TestSendToStoredSinks(t *testing.T) {
+	slack, slackURL := newReceiver(t)
+	backups, backupsURL := newReceiver(t)
+	n := testNotifier(t)
+	ctx := context.Background()
+
+	require.NoError(t, SaveSink(ctx, n.Client, testNamespace, Sink{Name: "ops", Type: SinkSlack, URL: slackURL}))
+	require.NoError(t, SaveSink(ctx, n.Client, testNamespace, Sink{
+		Name:    "backups",
+		Type:    SinkWebhook,
+		URL:     backupsURL,
+		Events:  []string{"backup.*"},
+		Headers: map[string]string{"X-Token": "abc"},
+	}))
+
+	sinks, err := ListSinks(ctx, n.Client, testNamespace)
+	require.NoError(t, err)
+	require.Len(t, sinks, 2)
+	assert.Equal(t, "backups", sinks[0].Name)
+	assert.Equal(t, []string{"backup.*"}, sinks[0].Events)
+	assert.Equal(t, map[string]string{"X-Token": "abc"}, sinks[0].Headers)
+
+	require.NoError(t, n.Send(ctx, degraded))
+	assert.Equal(t, 1, slack.requests())
+	assert.Equal(t, 0, backups.requests(), "backup sink does not subscribe to application events")
+
+	require.NoError(t, n.Send(ctx, Event{Type: EventBackupFailed, Title: "Backup nightly failed"}))
+	assert.Equal(t, 2, slack.requests())
+	require.Equal(t, 1, backups.requests())
+	var got Event
+	require.NoError(t, json.Unmarshal(backups.bodies[0], &got))
+	assert.Equal(t, EventBackupFailed, got.Type)
+	assert.False(t, got.Time.IsZero(), "Send stamps the event time")
+
+	require.NoError(t, DeleteSink(ctx, n.Client, testNamespace, "ops"))
+	_, err = GetSink(ctx, n.Client, testNamespace, "ops")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestSaveSinkRejectsInvalid(t *testing.T) {
+	n := testNotifier(t)
+	err := SaveSink(context.Background(), n.Client, testNamespace, Sink{Name: "bad", Type: "email", URL: "https://x"})
+	assert.ErrorContains(t, err, "unknown sink type")
+}
+
+func TestGetSinkRejectsCorruptHeaders(t *testing.T) {
+	n := testNotifier(t)
+	ctx := context.Background()
+	require.NoError(t, n.Client.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName("ops"), Namespace: testNamespace, Labels: map[string]string{SinkLabel: "true"}},
+		Data:       map[string][]byte{"type": []byte(SinkWebhook), "url": []byte("https://x"), "headers": []byte("{not json")},
+	}))
+
+	_, err := GetSink(ctx, n.Client, testNamespace, "ops")
+	assert.ErrorContains(t, err, "decoding headers")
+	_, err = ListSinks(ctx, n.Client, testNamespace)
+	assert.ErrorContains(t, err, "decoding headers")
+}
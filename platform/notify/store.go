@@ -0,0 +1,128 @@
+package notify
+
+// store.go keeps sinks as Secrets, one per sink, since sink URLs (Slack and
+// Teams incoming webhooks) and headers carry credentials.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SinkLabel marks a Secret as a notification sink.
+	SinkLabel = "adhar.io/notification-sink"
+
+	sinkSecretPrefix = "adhar-sink-"
+)
+
+// secretName is the Secret holding the sink called name.
+func secretName(name string) string {
+	return sinkSecretPrefix + name
+}
+
+// SaveSink creates or replaces the sink.
+func SaveSink(ctx context.Context, c client.Client, namespace string, s Sink) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName(s.Name), Namespace: namespace}}
+	err := c.Get(ctx, client.ObjectKeyFromObject(secret), secret)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("getting sink %s: %w", s.Name, err)
+	}
+	exists := err == nil
+
+	headers, err := json.Marshal(s.Headers)
+	if err != nil {
+		return err
+	}
+	secret.Labels = map[string]string{
+		SinkLabel:                      "true",
+		"app.kubernetes.io/managed-by": "adhar",
+	}
+	secret.Type = corev1.SecretTypeOpaque
+	secret.Data = map[string][]byte{
+		"type":     []byte(s.Type),
+		"url":      []byte(s.URL),
+		"events":   []byte(strings.Join(s.Events, ",")),
+		"template": []byte(s.Template),
+		"headers":  headers,
+	}
+	if exists {
+		err = c.Update(ctx, secret)
+	} else {
+		err = c.Create(ctx, secret)
+	}
+	if err != nil {
+		return fmt.Errorf("saving sink %s: %w", s.Name, err)
+	}
+	return nil
+}
+
+// ListSinks returns the stored sinks sorted by name.
+func ListSinks(ctx context.Context, c client.Client, namespace string) ([]Sink, error) {
+	var secrets corev1.SecretList
+	if err := c.List(ctx, &secrets, client.InNamespace(namespace), client.MatchingLabels{SinkLabel: "true"}); err != nil {
+		return nil, fmt.Errorf("listing notification sinks: %w", err)
+	}
+	sinks := make([]Sink, 0, len(secrets.Items))
+	for i := range secrets.Items {
+		s, err := sinkFromSecret(&secrets.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	sort.Slice(sinks, func(i, j int) bool { return sinks[i].Name < sinks[j].Name })
+	return sinks, nil
+}
+
+// GetSink returns the sink called name.
+func GetSink(ctx context.Context, c client.Client, namespace, name string) (Sink, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: secretName(name)}, secret); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return Sink{}, fmt.Errorf("notification sink %q not found", name)
+		}
+		return Sink{}, fmt.Errorf("getting sink %s: %w", name, err)
+	}
+	return sinkFromSecret(secret)
+}
+
+// DeleteSink removes the sink called name.
+func DeleteSink(ctx context.Context, c client.Client, namespace, name string) error {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName(name), Namespace: namespace}}
+	if err := c.Delete(ctx, secret); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return fmt.Errorf("notification sink %q not found", name)
+		}
+		return fmt.Errorf("deleting sink %s: %w", name, err)
+	}
+	return nil
+}
+
+func sinkFromSecret(secret *corev1.Secret) (Sink, error) {
+	s := Sink{
+		Name:     strings.TrimPrefix(secret.Name, sinkSecretPrefix),
+		Type:     string(secret.Data["type"]),
+		URL:      string(secret.Data["url"]),
+		Template: string(secret.Data["template"]),
+	}
+	if events := string(secret.Data["events"]); events != "" {
+		s.Events = strings.Split(events, ",")
+	}
+	if headers := secret.Data["headers"]; len(headers) > 0 {
+		if err := json.Unmarshal(headers, &s.Headers); err != nil {
+			return Sink{}, fmt.Errorf("sink %s: decoding headers: %w", s.Name, err)
+		}
+	}
+	return s, nil
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 
 	provider "adhar-io/adhar/platform/providers"
 	"adhar-io/adhar/platform/types"
@@ -121,6 +122,7 @@ type Provider struct {
 	config    *Config
 	awsConfig aws.Config
 	ec2Client *ec2.Client
+	elbClient *elasticloadbalancingv2.Client
 }
 
 // Config holds AWS provider configuration
@@ -232,6 +234,7 @@ func NewProvider(config *Config) (*Provider, error) {
 		config:    config,
 		awsConfig: cfg,
 		ec2Client: ec2.NewFromConfig(cfg),
+		elbClient: elasticloadbalancingv2.NewFromConfig(cfg),
 	}, nil
 }
 
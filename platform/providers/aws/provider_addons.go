@@ -62,20 +62,25 @@ func (p *Provider) UpgradeCluster(ctx context.Context, clusterID string, version
 	if err != nil {
 		return fmt.Errorf("failed to get cluster infrastructure: %w", err)
 	}
-	if len(infrastructure.MasterNodes) == 0 || infrastructure.MasterNodes[0].PublicIP == "" {
+	var masterIPs, workerIPs []string
+	for _, m := range infrastructure.MasterNodes {
+		if m.PublicIP != "" {
+			masterIPs = append(masterIPs, m.PublicIP)
+		}
+	}
+	if len(masterIPs) == 0 {
 		return fmt.Errorf("no reachable control-plane instance for cluster %s", clusterName)
 	}
 	signer, err := provider.LoadClusterSSHKey(clusterName)
 	if err != nil {
 		return err
 	}
-	var workerIPs []string
 	for _, w := range infrastructure.WorkerNodes {
 		if w.PublicIP != "" {
 			workerIPs = append(workerIPs, w.PublicIP)
 		}
 	}
-	if err := provider.KubeadmUpgradeCluster(ctx, signer, awsSSHUser, infrastructure.MasterNodes[0].PublicIP, workerIPs, version); err != nil {
+	if err := provider.KubeadmUpgradeCluster(ctx, signer, awsSSHUser, masterIPs, workerIPs, version); err != nil {
 		return fmt.Errorf("kubeadm upgrade of cluster %s failed: %w", clusterName, err)
 	}
 	log.Printf("Successfully upgraded cluster %s to %s", clusterName, version)
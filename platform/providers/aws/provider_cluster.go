@@ -26,9 +26,11 @@ func (p *Provider) CreateCluster(ctx context.Context, spec *types.ClusterSpec) (
 		return nil, fmt.Errorf("provider mismatch: expected aws, got %s", spec.Provider)
 	}
 
-	if spec.ControlPlane.Replicas > 1 || spec.ControlPlane.HighAvailability {
-		return nil, fmt.Errorf("HA control plane not yet supported: self-managed AWS clusters run a single control-plane node")
+	replicas, err := provider.ControlPlaneReplicas(spec.ControlPlane)
+	if err != nil {
+		return nil, err
 	}
+	spec.ControlPlane.Replicas = replicas
 
 	fmt.Printf("🚀 Creating self-managed Kubernetes cluster '%s' on EC2 instances...\n", spec.Name)
 	fmt.Printf("⏳ This will take several minutes to provision real AWS infrastructure...\n")
@@ -84,7 +86,9 @@ func (p *Provider) CreateCluster(ctx context.Context, spec *types.ClusterSpec) (
 
 	fmt.Printf("🌐 Step 3/3: Configuring cluster endpoint and domain management...\n")
 	// Update cluster endpoint with actual infrastructure details
-	if len(infrastructure.MasterNodes) > 0 {
+	if infrastructure.LoadBalancerDNS != "" {
+		cluster.Endpoint = fmt.Sprintf("https://%s:6443", infrastructure.LoadBalancerDNS)
+	} else if len(infrastructure.MasterNodes) > 0 {
 		masterIP := infrastructure.MasterNodes[0].PublicIP
 		if masterIP != "" {
 			cluster.Endpoint = fmt.Sprintf("https://%s:6443", masterIP)
@@ -184,25 +188,43 @@ func (p *Provider) createClusterInfrastructure(ctx context.Context, clusterName
 // setupKubernetesCluster bootstraps Kubernetes on the created infrastructure
 // by driving kubeadm over SSH: kube-proxy is skipped and no CNI is installed
 // here — the platform bootstrap installs Cilium with kubeProxyReplacement, so
-// nodes stay NotReady until then.
+// nodes stay NotReady until then. With several master nodes the control plane
+// is made HA (stacked etcd) behind a network load balancer.
 func (p *Provider) setupKubernetesCluster(ctx context.Context, spec *types.ClusterSpec, cluster *types.Cluster, infrastructure *ClusterInfrastructure) error {
 	fmt.Printf("⚙️  Setting up Kubernetes cluster with kubeadm...\n")
 
 	if len(infrastructure.MasterNodes) == 0 {
 		return fmt.Errorf("no master nodes found")
 	}
-	if len(infrastructure.MasterNodes) > 1 {
-		return fmt.Errorf("HA control plane not yet supported")
-	}
 
 	signer, err := provider.LoadClusterSSHKey(cluster.Name)
 	if err != nil {
 		return err
 	}
 
+	for _, master := range infrastructure.MasterNodes {
+		if master.PublicIP == "" {
+			return fmt.Errorf("master node %s has no public IP", master.InstanceId)
+		}
+	}
 	primaryMaster := infrastructure.MasterNodes[0]
-	if primaryMaster.PublicIP == "" {
-		return fmt.Errorf("master node %s has no public IP", primaryMaster.InstanceId)
+
+	var cp provider.KubeadmControlPlane
+	if len(infrastructure.MasterNodes) > 1 {
+		for _, master := range infrastructure.MasterNodes {
+			cp.ExtraSANs = append(cp.ExtraSANs, master.PublicIP, master.PrivateIP)
+		}
+		if len(infrastructure.SubnetIds) == 0 {
+			return fmt.Errorf("no public subnet for the API load balancer")
+		}
+		fmt.Printf("⚖️  Creating network load balancer for %d control-plane nodes...\n", len(infrastructure.MasterNodes))
+		lbDNS, err := p.ensureAPILoadBalancer(ctx, cluster.Name, infrastructure.VPCId, infrastructure.SubnetIds[0], infrastructure.MasterNodes)
+		if err != nil {
+			return err
+		}
+		infrastructure.LoadBalancerDNS = lbDNS
+		cp.Endpoint = lbDNS
+		fmt.Printf("✓ API load balancer ready: %s\n", lbDNS)
 	}
 
 	fmt.Printf("⏳ Waiting for node preparation on master %s (%s)...\n", primaryMaster.InstanceId, primaryMaster.PublicIP)
@@ -211,11 +233,21 @@ func (p *Provider) setupKubernetesCluster(ctx context.Context, spec *types.Clust
 	}
 
 	fmt.Printf("🎯 Running kubeadm init on primary master %s...\n", primaryMaster.InstanceId)
-	joinCmd, err := provider.KubeadmInitMaster(signer, awsSSHUser, primaryMaster.PublicIP, primaryMaster.PrivateIP)
+	join, err := provider.KubeadmInitControlPlane(signer, awsSSHUser, primaryMaster.PublicIP, primaryMaster.PrivateIP, cp)
 	if err != nil {
 		return fmt.Errorf("failed to initialize primary master: %w", err)
 	}
 
+	for i, master := range infrastructure.MasterNodes[1:] {
+		fmt.Printf("🎛️  Joining control-plane node %d: %s\n", i+2, master.InstanceId)
+		if err := provider.WaitForNodePrep(ctx, signer, awsSSHUser, master.PublicIP, 15*time.Minute); err != nil {
+			return fmt.Errorf("control-plane node %s not ready: %w", master.InstanceId, err)
+		}
+		if err := provider.KubeadmJoinControlPlane(signer, awsSSHUser, master.PublicIP, master.PrivateIP, join, cp); err != nil {
+			return fmt.Errorf("failed to join control-plane node %s: %w", master.InstanceId, err)
+		}
+	}
+
 	for i, worker := range infrastructure.WorkerNodes {
 		fmt.Printf("👷 Joining worker node %d: %s\n", i+1, worker.InstanceId)
 		if worker.PublicIP == "" {
@@ -224,14 +256,18 @@ func (p *Provider) setupKubernetesCluster(ctx context.Context, spec *types.Clust
 		if err := provider.WaitForNodePrep(ctx, signer, awsSSHUser, worker.PublicIP, 15*time.Minute); err != nil {
 			return fmt.Errorf("worker node %s not ready: %w", worker.InstanceId, err)
 		}
-		if err := provider.KubeadmJoinWorker(signer, awsSSHUser, worker.PublicIP, joinCmd); err != nil {
+		if err := provider.KubeadmJoinWorker(signer, awsSSHUser, worker.PublicIP, join.Worker); err != nil {
 			return fmt.Errorf("failed to join worker node %s: %w", worker.InstanceId, err)
 		}
 	}
 
 	fmt.Printf("✅ Kubernetes cluster setup complete!\n")
 
-	cluster.Endpoint = fmt.Sprintf("https://%s:6443", primaryMaster.PublicIP)
+	endpoint := primaryMaster.PublicIP
+	if cp.HA() {
+		endpoint = cp.Endpoint
+	}
+	cluster.Endpoint = fmt.Sprintf("https://%s:6443", endpoint)
 
 	// Setup domain management if configured
 	if spec.Domain != nil {
@@ -319,8 +355,19 @@ func (p *Provider) DeleteCluster(ctx context.Context, clusterID string) error {
 		p.printResourceSummary(tracker)
 	}
 
-	// Step 1: Terminate all EC2 instances first (this releases ENIs and other attached resources)
-	fmt.Printf("\n🖥️  Step 1/8: Terminating EC2 instances...\n")
+	// Step 1: Delete the API load balancer of an HA control plane (its
+	// network interfaces would otherwise hold the subnets)
+	fmt.Printf("\n⚖️  Step 1/9: Deleting API load balancer...\n")
+	err = p.deleteAPILoadBalancer(ctx, clusterName)
+	if err != nil {
+		log.Printf("Warning: Failed to delete API load balancer: %v", err)
+		fmt.Printf("⚠️  Warning: Failed to delete API load balancer: %v\n", err)
+	} else {
+		fmt.Printf("✓ API load balancer removed\n")
+	}
+
+	// Step 2: Terminate all EC2 instances first (this releases ENIs and other attached resources)
+	fmt.Printf("\n🖥️  Step 2/9: Terminating EC2 instances...\n")
 	err = p.deleteClusterInstancesComprehensive(ctx, clusterName, tracker)
 	if err != nil {
 		log.Printf("Warning: Failed to delete some cluster instances: %v", err)
@@ -329,8 +376,8 @@ func (p *Provider) DeleteCluster(ctx context.Context, clusterID string) error {
 		fmt.Printf("✓ All cluster instances terminated\n")
 	}
 
-	// Step 2: Release Elastic IPs
-	fmt.Printf("\n💰 Step 2/8: Releasing Elastic IPs...\n")
+	// Step 3: Release Elastic IPs
+	fmt.Printf("\n💰 Step 3/9: Releasing Elastic IPs...\n")
 	err = p.deleteElasticIPs(ctx, clusterName, tracker)
 	if err != nil {
 		log.Printf("Warning: Failed to release some Elastic IPs: %v", err)
@@ -339,8 +386,8 @@ func (p *Provider) DeleteCluster(ctx context.Context, clusterID string) error {
 		fmt.Printf("✓ Elastic IPs released\n")
 	}
 
-	// Step 3: Delete NAT Gateways (must be done before deleting subnets)
-	fmt.Printf("\n🌐 Step 3/8: Deleting NAT Gateways...\n")
+	// Step 4: Delete NAT Gateways (must be done before deleting subnets)
+	fmt.Printf("\n🌐 Step 4/9: Deleting NAT Gateways...\n")
 	err = p.deleteNATGateways(ctx, clusterName, tracker)
 	if err != nil {
 		log.Printf("Warning: Failed to delete some NAT Gateways: %v", err)
@@ -349,8 +396,8 @@ func (p *Provider) DeleteCluster(ctx context.Context, clusterID string) error {
 		fmt.Printf("✓ NAT Gateways deleted\n")
 	}
 
-	// Step 4: Delete Network Interfaces (should be auto-deleted with instances, but clean up any orphans)
-	fmt.Printf("\n� Step 4/8: Cleaning up Network Interfaces...\n")
+	// Step 5: Delete Network Interfaces (should be auto-deleted with instances, but clean up any orphans)
+	fmt.Printf("\n� Step 5/9: Cleaning up Network Interfaces...\n")
 	err = p.deleteNetworkInterfaces(ctx, clusterName, tracker)
 	if err != nil {
 		log.Printf("Warning: Failed to delete some Network Interfaces: %v", err)
@@ -359,8 +406,8 @@ func (p *Provider) DeleteCluster(ctx context.Context, clusterID string) error {
 		fmt.Printf("✓ Network Interfaces cleaned up\n")
 	}
 
-	// Step 5: Delete Security Groups (except default VPC security group)
-	fmt.Printf("\n🔒 Step 5/8: Deleting security groups...\n")
+	// Step 6: Delete Security Groups (except default VPC security group)
+	fmt.Printf("\n🔒 Step 6/9: Deleting security groups...\n")
 	err = p.deleteClusterSecurityGroupsComprehensive(ctx, clusterName, tracker)
 	if err != nil {
 		log.Printf("Warning: Failed to delete security groups: %v", err)
@@ -369,8 +416,8 @@ func (p *Provider) DeleteCluster(ctx context.Context, clusterID string) error {
 		fmt.Printf("✓ Security groups deleted\n")
 	}
 
-	// Step 6: Delete Route Tables (except main route table)
-	fmt.Printf("\n🛣️  Step 6/8: Deleting route tables...\n")
+	// Step 7: Delete Route Tables (except main route table)
+	fmt.Printf("\n🛣️  Step 7/9: Deleting route tables...\n")
 	err = p.deleteRouteTables(ctx, clusterName, tracker)
 	if err != nil {
 		log.Printf("Warning: Failed to delete some route tables: %v", err)
@@ -379,8 +426,8 @@ func (p *Provider) DeleteCluster(ctx context.Context, clusterID string) error {
 		fmt.Printf("✓ Route tables deleted\n")
 	}
 
-	// Step 7: Delete Subnets
-	fmt.Printf("\n📡 Step 7/8: Deleting subnets...\n")
+	// Step 8: Delete Subnets
+	fmt.Printf("\n📡 Step 8/9: Deleting subnets...\n")
 	err = p.deleteClusterSubnetsComprehensive(ctx, clusterName, tracker)
 	if err != nil {
 		log.Printf("Warning: Failed to delete subnets: %v", err)
@@ -389,8 +436,8 @@ func (p *Provider) DeleteCluster(ctx context.Context, clusterID string) error {
 		fmt.Printf("✓ Subnets deleted\n")
 	}
 
-	// Step 8: Delete Internet Gateways and VPC
-	fmt.Printf("\n🌍 Step 8/8: Deleting Internet Gateway and VPC...\n")
+	// Step 9: Delete Internet Gateways and VPC
+	fmt.Printf("\n🌍 Step 9/9: Deleting Internet Gateway and VPC...\n")
 	err = p.deleteVPCAndGateway(ctx, clusterName, tracker)
 	if err != nil {
 		log.Printf("Warning: Failed to delete VPC/Gateway: %v", err)
@@ -496,26 +543,18 @@ func (p *Provider) UpdateCluster(ctx context.Context, clusterID string, spec *ty
 		return fmt.Errorf("failed to get cluster infrastructure: %w", err)
 	}
 
-	// Handle control plane scaling
+	// Control-plane size is fixed at creation: a stacked-etcd member cannot
+	// simply be started or terminated, it has to join with kubeadm, be
+	// registered with the API load balancer, and leave etcd before it goes.
 	currentMasterCount := len(infrastructure.MasterNodes)
-	desiredMasterCount := spec.ControlPlane.Replicas
+	desiredMasterCount, err := provider.ControlPlaneReplicas(spec.ControlPlane)
+	if err != nil {
+		return err
+	}
 
 	log.Printf("Current cluster state: %d masters, %d workers", currentMasterCount, len(infrastructure.WorkerNodes))
-	log.Printf("Desired control plane replicas: %d", desiredMasterCount)
-
-	// Scale master nodes if needed
-	if desiredMasterCount > currentMasterCount {
-		log.Printf("Scaling up master nodes from %d to %d", currentMasterCount, desiredMasterCount)
-		err = p.scaleUpMasterNodes(ctx, infrastructure, spec, desiredMasterCount-currentMasterCount)
-		if err != nil {
-			return fmt.Errorf("failed to scale up master nodes: %w", err)
-		}
-	} else if desiredMasterCount < currentMasterCount {
-		log.Printf("Scaling down master nodes from %d to %d", currentMasterCount, desiredMasterCount)
-		err = p.scaleDownMasterNodes(ctx, infrastructure, currentMasterCount-desiredMasterCount)
-		if err != nil {
-			return fmt.Errorf("failed to scale down master nodes: %w", err)
-		}
+	if desiredMasterCount != currentMasterCount {
+		return fmt.Errorf("changing the control plane from %d to %d nodes is not supported; recreate the cluster with the new size", currentMasterCount, desiredMasterCount)
 	}
 
 	// Handle node group scaling (process only the first node group for simplicity)
@@ -555,9 +594,15 @@ func (p *Provider) GetCluster(ctx context.Context, clusterID string) (*types.Clu
 		return nil, fmt.Errorf("failed to get cluster infrastructure: %w", err)
 	}
 
-	// Determine endpoint from master nodes
+	// Determine endpoint from the API load balancer or the master nodes
 	endpoint := "https://api.kubernetes.local:6443" // Default
-	if len(infrastructure.MasterNodes) > 0 {
+	var lbDNS string
+	if len(infrastructure.MasterNodes) > 1 {
+		lbDNS = p.getAPILoadBalancerDNS(ctx, clusterName)
+	}
+	if lbDNS != "" {
+		endpoint = fmt.Sprintf("https://%s:6443", lbDNS)
+	} else if len(infrastructure.MasterNodes) > 0 {
 		masterIP := infrastructure.MasterNodes[0].PublicIP
 		if masterIP != "" {
 			endpoint = fmt.Sprintf("https://%s:6443", masterIP)
@@ -420,65 +420,6 @@ func (p *Provider) cleanupAllAdharInstances(ctx context.Context) error {
 	return nil
 }
 
-// scaleUpMasterNodes adds new master nodes to the cluster
-func (p *Provider) scaleUpMasterNodes(ctx context.Context, infrastructure *ClusterInfrastructure, spec *types.ClusterSpec, count int) error {
-	if len(infrastructure.SubnetIds) == 0 || len(infrastructure.SecurityGroups) == 0 {
-		return fmt.Errorf("missing infrastructure information for scaling")
-	}
-
-	clusterName := extractClusterNameFromSG(infrastructure.SecurityGroups[0])
-	subnetID := infrastructure.SubnetIds[0] // Use first subnet
-	sgID := infrastructure.SecurityGroups[0]
-
-	// Create additional master nodes
-	newMasters, err := p.createMasterNodes(ctx, subnetID, sgID, clusterName, spec)
-	if err != nil {
-		return fmt.Errorf("failed to create additional master nodes: %w", err)
-	}
-
-	// In a production environment, you would also need to:
-	// 1. Join the new masters to the existing cluster
-	// 2. Update the kubeconfig with new master endpoints
-	// 3. Update load balancer configuration if using one
-	// 4. Ensure etcd cluster is properly expanded
-
-	log.Printf("✓ Added %d master nodes", len(newMasters))
-	return nil
-}
-
-// scaleDownMasterNodes removes master nodes from the cluster
-func (p *Provider) scaleDownMasterNodes(ctx context.Context, infrastructure *ClusterInfrastructure, count int) error {
-	if count >= len(infrastructure.MasterNodes) {
-		return fmt.Errorf("cannot remove all master nodes - cluster would become unavailable")
-	}
-
-	// Select nodes to remove (remove the newest ones first)
-	nodesToRemove := infrastructure.MasterNodes[len(infrastructure.MasterNodes)-count:]
-
-	var instanceIds []string
-	for _, node := range nodesToRemove {
-		instanceIds = append(instanceIds, node.InstanceId)
-	}
-
-	log.Printf("Removing master nodes: %v", instanceIds)
-
-	// In a production environment, you would need to:
-	// 1. Drain the nodes first
-	// 2. Remove them from the etcd cluster
-	// 3. Update kubeconfig and load balancer
-
-	// Terminate the instances
-	_, err := p.ec2Client.TerminateInstances(ctx, &ec2.TerminateInstancesInput{
-		InstanceIds: instanceIds,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to terminate master instances: %w", err)
-	}
-
-	log.Printf("✓ Removed %d master nodes", count)
-	return nil
-}
-
 // scaleUpWorkerNodes adds new worker nodes to the cluster
 func (p *Provider) scaleUpWorkerNodes(ctx context.Context, infrastructure *ClusterInfrastructure, spec *types.ClusterSpec, count int) error {
 	if len(infrastructure.SubnetIds) == 0 || len(infrastructure.SecurityGroups) == 0 {
@@ -10,6 +10,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	elbv2types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
 
 	"adhar-io/adhar/platform/types"
 )
@@ -1045,6 +1047,162 @@ func (p *Provider) GetLoadBalancer(ctx context.Context, lbID string) (*types.Loa
 	}, nil
 }
 
+// apiLoadBalancerName names the network load balancer (and its target group)
+// in front of an HA control plane. ELBv2 names are limited to 32 characters,
+// so long cluster names are shortened.
+func apiLoadBalancerName(clusterName string) string {
+	name := "adhar-" + clusterName
+	if len(name) > 28 {
+		name = strings.TrimRight(name[:28], "-")
+	}
+	return name + "-api"
+}
+
+// ensureAPILoadBalancer returns the DNS name of an internet-facing network
+// load balancer forwarding TCP 6443 to the control-plane nodes, creating the
+// load balancer, its target group and listener when missing. Targets are
+// registered by private IP so a control-plane node reaching the API through
+// the load balancer is not dropped as a hairpin connection.
+func (p *Provider) ensureAPILoadBalancer(ctx context.Context, clusterName, vpcID, subnetID string, masters []NodeInfo) (string, error) {
+	name := apiLoadBalancerName(clusterName)
+	tags := []elbv2types.Tag{
+		{Key: aws.String("Name"), Value: aws.String(name)},
+		{Key: aws.String("Cluster"), Value: aws.String(clusterName)},
+	}
+
+	var lb elbv2types.LoadBalancer
+	if out, err := p.elbClient.DescribeLoadBalancers(ctx, &elasticloadbalancingv2.DescribeLoadBalancersInput{
+		Names: []string{name},
+	}); err == nil && len(out.LoadBalancers) > 0 {
+		lb = out.LoadBalancers[0]
+	} else {
+		created, err := p.elbClient.CreateLoadBalancer(ctx, &elasticloadbalancingv2.CreateLoadBalancerInput{
+			Name:    aws.String(name),
+			Type:    elbv2types.LoadBalancerTypeEnumNetwork,
+			Scheme:  elbv2types.LoadBalancerSchemeEnumInternetFacing,
+			Subnets: []string{subnetID},
+			Tags:    tags,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create API load balancer %s: %w", name, err)
+		}
+		lb = created.LoadBalancers[0]
+	}
+
+	var targetGroupArn string
+	if out, err := p.elbClient.DescribeTargetGroups(ctx, &elasticloadbalancingv2.DescribeTargetGroupsInput{
+		Names: []string{name},
+	}); err == nil && len(out.TargetGroups) > 0 {
+		targetGroupArn = aws.ToString(out.TargetGroups[0].TargetGroupArn)
+	} else {
+		created, err := p.elbClient.CreateTargetGroup(ctx, &elasticloadbalancingv2.CreateTargetGroupInput{
+			Name:                       aws.String(name),
+			Protocol:                   elbv2types.ProtocolEnumTcp,
+			Port:                       aws.Int32(6443),
+			VpcId:                      aws.String(vpcID),
+			TargetType:                 elbv2types.TargetTypeEnumIp,
+			HealthCheckProtocol:        elbv2types.ProtocolEnumTcp,
+			HealthCheckIntervalSeconds: aws.Int32(10),
+			HealthyThresholdCount:      aws.Int32(2),
+			UnhealthyThresholdCount:    aws.Int32(2),
+			Tags:                       tags,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create API target group %s: %w", name, err)
+		}
+		targetGroupArn = aws.ToString(created.TargetGroups[0].TargetGroupArn)
+	}
+
+	targets := make([]elbv2types.TargetDescription, 0, len(masters))
+	for _, m := range masters {
+		targets = append(targets, elbv2types.TargetDescription{Id: aws.String(m.PrivateIP), Port: aws.Int32(6443)})
+	}
+	if _, err := p.elbClient.RegisterTargets(ctx, &elasticloadbalancingv2.RegisterTargetsInput{
+		TargetGroupArn: aws.String(targetGroupArn),
+		Targets:        targets,
+	}); err != nil {
+		return "", fmt.Errorf("failed to register control-plane targets: %w", err)
+	}
+
+	listeners, err := p.elbClient.DescribeListeners(ctx, &elasticloadbalancingv2.DescribeListenersInput{
+		LoadBalancerArn: lb.LoadBalancerArn,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe API load balancer listeners: %w", err)
+	}
+	if len(listeners.Listeners) == 0 {
+		if _, err := p.elbClient.CreateListener(ctx, &elasticloadbalancingv2.CreateListenerInput{
+			LoadBalancerArn: lb.LoadBalancerArn,
+			Protocol:        elbv2types.ProtocolEnumTcp,
+			Port:            aws.Int32(6443),
+			DefaultActions: []elbv2types.Action{
+				{Type: elbv2types.ActionTypeEnumForward, TargetGroupArn: aws.String(targetGroupArn)},
+			},
+		}); err != nil {
+			return "", fmt.Errorf("failed to create API load balancer listener: %w", err)
+		}
+	}
+
+	waiter := elasticloadbalancingv2.NewLoadBalancerAvailableWaiter(p.elbClient)
+	if err := waiter.Wait(ctx, &elasticloadbalancingv2.DescribeLoadBalancersInput{
+		LoadBalancerArns: []string{aws.ToString(lb.LoadBalancerArn)},
+	}, 10*time.Minute); err != nil {
+		return "", fmt.Errorf("API load balancer %s did not become active: %w", name, err)
+	}
+
+	return aws.ToString(lb.DNSName), nil
+}
+
+// getAPILoadBalancerDNS returns the DNS name of the cluster's API load
+// balancer, or "" for a single control-plane cluster without one.
+func (p *Provider) getAPILoadBalancerDNS(ctx context.Context, clusterName string) string {
+	out, err := p.elbClient.DescribeLoadBalancers(ctx, &elasticloadbalancingv2.DescribeLoadBalancersInput{
+		Names: []string{apiLoadBalancerName(clusterName)},
+	})
+	if err != nil || len(out.LoadBalancers) == 0 {
+		return ""
+	}
+	return aws.ToString(out.LoadBalancers[0].DNSName)
+}
+
+// deleteAPILoadBalancer removes the API load balancer and its target group.
+// The target group can only go once the load balancer (and with it the
+// listener) is gone, so deletion waits for that first.
+func (p *Provider) deleteAPILoadBalancer(ctx context.Context, clusterName string) error {
+	name := apiLoadBalancerName(clusterName)
+
+	if out, err := p.elbClient.DescribeLoadBalancers(ctx, &elasticloadbalancingv2.DescribeLoadBalancersInput{
+		Names: []string{name},
+	}); err == nil && len(out.LoadBalancers) > 0 {
+		lbArn := aws.ToString(out.LoadBalancers[0].LoadBalancerArn)
+		if _, err := p.elbClient.DeleteLoadBalancer(ctx, &elasticloadbalancingv2.DeleteLoadBalancerInput{
+			LoadBalancerArn: aws.String(lbArn),
+		}); err != nil {
+			return fmt.Errorf("failed to delete API load balancer %s: %w", name, err)
+		}
+		waiter := elasticloadbalancingv2.NewLoadBalancersDeletedWaiter(p.elbClient)
+		if err := waiter.Wait(ctx, &elasticloadbalancingv2.DescribeLoadBalancersInput{
+			LoadBalancerArns: []string{lbArn},
+		}, 10*time.Minute); err != nil {
+			return fmt.Errorf("API load balancer %s was not deleted: %w", name, err)
+		}
+		log.Printf("✓ Deleted API load balancer %s", name)
+	}
+
+	if out, err := p.elbClient.DescribeTargetGroups(ctx, &elasticloadbalancingv2.DescribeTargetGroupsInput{
+		Names: []string{name},
+	}); err == nil && len(out.TargetGroups) > 0 {
+		if _, err := p.elbClient.DeleteTargetGroup(ctx, &elasticloadbalancingv2.DeleteTargetGroupInput{
+			TargetGroupArn: out.TargetGroups[0].TargetGroupArn,
+		}); err != nil {
+			return fmt.Errorf("failed to delete API target group %s: %w", name, err)
+		}
+		log.Printf("✓ Deleted API target group %s", name)
+	}
+
+	return nil
+}
+
 // cleanupVPCNetworkInterfaces deletes network interfaces in a VPC
 func (p *Provider) cleanupVPCNetworkInterfaces(ctx context.Context, vpcId string) error {
 	result, err := p.ec2Client.DescribeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{
@@ -2330,7 +2330,7 @@ func (p *Provider) UpgradeCluster(ctx context.Context, clusterID string, version
 		}
 	}
 
-	if err := provider.KubeadmUpgradeCluster(ctx, signer, azureSSHUser, masterIP, workerIPs, version); err != nil {
+	if err := provider.KubeadmUpgradeCluster(ctx, signer, azureSSHUser, []string{masterIP}, workerIPs, version); err != nil {
 		return fmt.Errorf("kubeadm upgrade of cluster %s failed: %w", cluster.Name, err)
 	}
 	log.Printf("Successfully upgraded cluster %s to %s", cluster.Name, version)
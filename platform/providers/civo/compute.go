@@ -5,12 +5,15 @@ package civo
 // flow — containerd runtime, kube-proxy skipped (Cilium installed by the
 // adhar bootstrap replaces it), and no CNI preinstalled. Nodes therefore stay
 // NotReady until the platform bootstrap installs Cilium; the cluster is
-// considered created once the API server answers.
+// considered created once the API server answers. HA control planes (3 or 5
+// control-plane instances, stacked etcd) sit behind a Civo load balancer
+// forwarding the API port.
 
 import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
@@ -204,14 +207,108 @@ func (p *Provider) createComputeInstance(ctx context.Context, name, networkID, f
 	}
 }
 
+// computeAPILoadBalancerName names the load balancer in front of an HA
+// control plane.
+func computeAPILoadBalancerName(clusterName string) string {
+	return fmt.Sprintf("adhar-%s-api", clusterName)
+}
+
+// ensureAPILoadBalancer returns the public IP of the load balancer forwarding
+// the Kubernetes API port to the control-plane instances' private IPs,
+// creating it when missing, pointing an existing one at the current masters,
+// and waiting until it is available.
+func (p *Provider) ensureAPILoadBalancer(ctx context.Context, clusterName, networkID string, masters []*civogo.Instance) (string, error) {
+	lbName := computeAPILoadBalancerName(clusterName)
+	var existing *civogo.LoadBalancer
+	if lbs, err := p.client.ListLoadBalancers(); err == nil {
+		for i := range lbs {
+			if lbs[i].Name == lbName {
+				existing = &lbs[i]
+				break
+			}
+		}
+	}
+
+	var backends []civogo.LoadBalancerBackendConfig
+	for _, m := range masters {
+		backends = append(backends, civogo.LoadBalancerBackendConfig{
+			IP:              m.PrivateIP,
+			Protocol:        "TCP",
+			SourcePort:      6443,
+			TargetPort:      6443,
+			HealthCheckPort: 6443,
+		})
+	}
+
+	var lbID string
+	if existing == nil {
+		lb, err := p.client.CreateLoadBalancer(&civogo.LoadBalancerConfig{
+			Region:        p.config.Region,
+			Name:          lbName,
+			NetworkID:     networkID,
+			Algorithm:     "round_robin",
+			Backends:      backends,
+			FirewallRules: "6443",
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create API load balancer %s: %w", lbName, err)
+		}
+		lbID = lb.ID
+	} else {
+		lbID = existing.ID
+		// An adopted load balancer may still point at the masters of an
+		// earlier attempt; Civo replaces the backend list on update.
+		if !sameBackendIPs(existing.Backends, masters) {
+			if _, err := p.client.UpdateLoadBalancer(lbID, &civogo.LoadBalancerUpdateConfig{
+				Region:   p.config.Region,
+				Backends: backends,
+			}); err != nil {
+				return "", fmt.Errorf("failed to update the backends of %s: %w", lbName, err)
+			}
+		}
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	for {
+		lb, err := p.client.GetLoadBalancer(lbID)
+		if err == nil && lb.State == "available" && lb.PublicIP != "" {
+			return lb.PublicIP, nil
+		}
+		select {
+		case <-waitCtx.Done():
+			return "", fmt.Errorf("API load balancer %s did not become available in time", lbName)
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+// sameBackendIPs reports whether backends are exactly the masters' private IPs.
+func sameBackendIPs(backends []civogo.LoadBalancerBackend, masters []*civogo.Instance) bool {
+	if len(backends) != len(masters) {
+		return false
+	}
+	ips := map[string]bool{}
+	for _, b := range backends {
+		ips[b.IP] = true
+	}
+	for _, m := range masters {
+		if !ips[m.PrivateIP] {
+			return false
+		}
+	}
+	return true
+}
+
 // createComputeCluster provisions Civo instances and bootstraps Kubernetes on
 // them with kubeadm.
 func (p *Provider) createComputeCluster(ctx context.Context, spec *types.ClusterSpec) (*types.Cluster, error) {
 	name := computeClusterName(spec.Name)
 	log.Printf("Creating self-managed Kubernetes cluster %q on Civo instances", name)
 
-	if spec.ControlPlane.Replicas > 1 || spec.ControlPlane.HighAvailability {
-		return nil, fmt.Errorf("compute mode currently supports a single control-plane node; HA control planes require a load balancer and stacked etcd and are not implemented yet")
+	controlPlaneReplicas, err := provider.ControlPlaneReplicas(spec.ControlPlane)
+	if err != nil {
+		return nil, err
 	}
 
 	k8sMinor := provider.K8sMinorFromVersion(spec.Version)
@@ -235,14 +332,34 @@ func (p *Provider) createComputeCluster(ctx context.Context, spec *types.Cluster
 		return nil, err
 	}
 
-	// Control-plane instance
+	// Control-plane instances
 	masterSize := spec.ControlPlane.InstanceType
 	if masterSize == "" {
 		masterSize = p.config.Size
 	}
-	master, err := p.createComputeInstance(ctx, fmt.Sprintf("adhar-%s-master-1", name), networkID, firewallID, sshKeyID, imageID, masterSize, userData, []string{tag, computeMasterTag})
-	if err != nil {
-		return nil, err
+	masters := make([]*civogo.Instance, 0, controlPlaneReplicas)
+	for i := 1; i <= controlPlaneReplicas; i++ {
+		master, err := p.createComputeInstance(ctx, fmt.Sprintf("adhar-%s-master-%d", name, i), networkID, firewallID, sshKeyID, imageID, masterSize, userData, []string{tag, computeMasterTag})
+		if err != nil {
+			return nil, err
+		}
+		masters = append(masters, master)
+	}
+	master := masters[0]
+
+	// HA control planes are reached through a load balancer; every
+	// control-plane address stays valid on the API certificate.
+	var cp provider.KubeadmControlPlane
+	if controlPlaneReplicas > 1 {
+		for _, m := range masters {
+			cp.ExtraSANs = append(cp.ExtraSANs, m.PublicIP, m.PrivateIP)
+		}
+		lbIP, err := p.ensureAPILoadBalancer(ctx, name, networkID, masters)
+		if err != nil {
+			return nil, err
+		}
+		cp.Endpoint = lbIP
+		log.Printf("Control plane of %d instances behind load balancer %s", controlPlaneReplicas, lbIP)
 	}
 
 	// Worker instances (default pool when the spec declares none)
@@ -284,20 +401,33 @@ func (p *Provider) createComputeCluster(ctx context.Context, spec *types.Cluster
 		return nil, fmt.Errorf("control-plane node not ready: %w", err)
 	}
 
-	joinCmd, err := provider.KubeadmInitMaster(signer, computeSSHUser, master.PublicIP, master.PrivateIP)
+	join, err := provider.KubeadmInitControlPlane(signer, computeSSHUser, master.PublicIP, master.PrivateIP, cp)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, m := range masters[1:] {
+		if err := provider.WaitForNodePrep(ctx, signer, computeSSHUser, m.PublicIP, 15*time.Minute); err != nil {
+			return nil, fmt.Errorf("control-plane %s not ready: %w", m.Hostname, err)
+		}
+		if err := provider.KubeadmJoinControlPlane(signer, computeSSHUser, m.PublicIP, m.PrivateIP, join, cp); err != nil {
+			return nil, fmt.Errorf("control-plane %s: %w", m.Hostname, err)
+		}
+	}
+
 	for _, instance := range workerInstances {
 		if err := provider.WaitForNodePrep(ctx, signer, computeSSHUser, instance.PublicIP, 15*time.Minute); err != nil {
 			return nil, fmt.Errorf("worker %s not ready: %w", instance.Hostname, err)
 		}
-		if err := provider.KubeadmJoinWorker(signer, computeSSHUser, instance.PublicIP, joinCmd); err != nil {
+		if err := provider.KubeadmJoinWorker(signer, computeSSHUser, instance.PublicIP, join.Worker); err != nil {
 			return nil, fmt.Errorf("worker %s: %w", instance.Hostname, err)
 		}
 	}
 
+	endpoint := master.PublicIP
+	if cp.HA() {
+		endpoint = cp.Endpoint
+	}
 	cluster := &types.Cluster{
 		ID:        tag,
 		Name:      name,
@@ -305,15 +435,16 @@ func (p *Provider) createComputeCluster(ctx context.Context, spec *types.Cluster
 		Region:    p.config.Region,
 		Version:   k8sMinor,
 		Status:    types.ClusterStatusRunning,
-		Endpoint:  fmt.Sprintf("https://%s:6443", master.PublicIP),
+		Endpoint:  fmt.Sprintf("https://%s:6443", endpoint),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		Metadata: map[string]interface{}{
-			"mode":        "compute",
-			"region":      p.config.Region,
-			"network":     networkID,
-			"masterIP":    master.PublicIP,
-			"workerNodes": len(workerInstances),
+			"mode":              "compute",
+			"region":            p.config.Region,
+			"network":           networkID,
+			"masterIP":          master.PublicIP,
+			"controlPlaneNodes": len(masters),
+			"workerNodes":       len(workerInstances),
 		},
 	}
 	p.clusters[cluster.ID] = cluster
@@ -443,13 +574,24 @@ func (p *Provider) listComputeClusters() ([]*types.Cluster, error) {
 }
 
 // deleteComputeCluster tears down all cloud resources of a compute cluster:
-// instances (by tag), the firewall, the per-cluster network, the registered
-// SSH key, and local state.
+// the API load balancer, instances (by tag), the firewall, the per-cluster
+// network, the registered SSH key, and local state.
 func (p *Provider) deleteComputeCluster(ctx context.Context, clusterID string) error {
 	name := computeClusterName(clusterID)
 	tag := computeClusterTag(name)
 	log.Printf("Deleting self-managed cluster %q (instances tagged %s)", name, tag)
 
+	// API load balancer of an HA control plane (it holds a network member)
+	if lbs, err := p.client.ListLoadBalancers(); err == nil {
+		for _, lb := range lbs {
+			if lb.Name == computeAPILoadBalancerName(name) {
+				if _, err := p.client.DeleteLoadBalancer(lb.ID); err != nil {
+					log.Printf("Warning: failed to delete load balancer %s: %v", lb.Name, err)
+				}
+			}
+		}
+	}
+
 	instances, err := p.computeClusterInstances(name)
 	if err != nil {
 		return err
@@ -531,7 +673,8 @@ func (p *Provider) isComputeCluster(clusterID string) bool {
 }
 
 // upgradeComputeCluster performs an in-place kubeadm upgrade of a compute
-// cluster: control plane first, then every worker.
+// cluster: control-plane instances one at a time (in name order), then every
+// worker.
 func (p *Provider) upgradeComputeCluster(ctx context.Context, clusterID, version string) error {
 	name := computeClusterName(clusterID)
 	log.Printf("Upgrading self-managed cluster %q to %s via kubeadm", name, version)
@@ -540,26 +683,26 @@ func (p *Provider) upgradeComputeCluster(ctx context.Context, clusterID, version
 	if err != nil {
 		return err
 	}
-	var masterIP string
-	var workerIPs []string
+	sort.Slice(instances, func(i, j int) bool { return instances[i].Hostname < instances[j].Hostname })
+	var masterIPs, workerIPs []string
 	for i := range instances {
 		if instances[i].PublicIP == "" {
 			continue
 		}
 		if isComputeMaster(&instances[i]) {
-			masterIP = instances[i].PublicIP
+			masterIPs = append(masterIPs, instances[i].PublicIP)
 		} else {
 			workerIPs = append(workerIPs, instances[i].PublicIP)
 		}
 	}
-	if masterIP == "" {
+	if len(masterIPs) == 0 {
 		return fmt.Errorf("no reachable control-plane instance for cluster %s", name)
 	}
 	signer, err := provider.LoadClusterSSHKey(name)
 	if err != nil {
 		return err
 	}
-	if err := provider.KubeadmUpgradeCluster(ctx, signer, computeSSHUser, masterIP, workerIPs, version); err != nil {
+	if err := provider.KubeadmUpgradeCluster(ctx, signer, computeSSHUser, masterIPs, workerIPs, version); err != nil {
 		return fmt.Errorf("kubeadm upgrade of cluster %s failed: %w", name, err)
 	}
 	log.Printf("Successfully upgraded cluster %q to %s", name, version)
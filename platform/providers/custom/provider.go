@@ -9,12 +9,17 @@ package custom
 // bootstrap installs Cilium; the cluster counts as created once the API
 // server answers. The machines themselves are never created or destroyed:
 // DeleteCluster only runs `kubeadm reset` on them.
+//
+// Three or five control-plane hosts form an HA control plane with stacked
+// etcd behind controlPlaneEndpoint: a kube-vip VIP announced by the hosts
+// themselves, or the user's own load balancer (externalLoadBalancer).
 
 import (
 	"context"
 	"encoding/base64"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -59,6 +64,15 @@ func init() {
 		if keyPath, ok := config["sshKeyPath"].(string); ok {
 			customConfig.SSHKeyPath = keyPath
 		}
+		if endpoint, ok := config["controlPlaneEndpoint"].(string); ok {
+			customConfig.ControlPlaneEndpoint = strings.TrimSpace(endpoint)
+		}
+		if external, ok := config["externalLoadBalancer"].(bool); ok {
+			customConfig.ExternalLoadBalancer = external
+		}
+		if iface, ok := config["vipInterface"].(string); ok {
+			customConfig.VIPInterface = strings.TrimSpace(iface)
+		}
 		switch port := config["sshPort"].(type) {
 		case int:
 			customConfig.SSHPort = port
@@ -105,12 +119,21 @@ type Provider struct {
 // reachable over SSH on port 22 with the user's own private key; adhar never
 // provisions or deletes them.
 type Config struct {
-	MasterIPs  []string `json:"masterIPs"`  // Control-plane host IPs; exactly one is supported
+	MasterIPs  []string `json:"masterIPs"`  // Control-plane host IPs: 1, or 3/5 for an HA control plane
 	WorkerIPs  []string `json:"workerIPs"`  // Worker host IPs
 	SSHUser    string   `json:"sshUser"`    // SSH user (root, or a user with passwordless sudo); legacy key: "username"
 	SSHKeyPath string   `json:"sshKeyPath"` // Path to the user's passphrase-less SSH private key
 	SSHPort    int      `json:"sshPort"`    // Legacy; only 22 is supported
 
+	// ControlPlaneEndpoint is the shared API server address of an HA control
+	// plane (required with several masterIPs): a free IP on the hosts'
+	// network that kube-vip announces, or — with ExternalLoadBalancer — the
+	// address of the user's own load balancer forwarding TCP 6443 to every
+	// control-plane host.
+	ControlPlaneEndpoint string `json:"controlPlaneEndpoint"`
+	ExternalLoadBalancer bool   `json:"externalLoadBalancer"`
+	VIPInterface         string `json:"vipInterface"` // kube-vip interface; detected from the default route when empty
+
 	// NodeIPs is the legacy host list: the first entry is the master and the
 	// rest are workers. Used only when MasterIPs is empty.
 	NodeIPs []string `json:"nodeIPs"`
@@ -131,8 +154,11 @@ func NewProvider(config *Config) (*Provider, error) {
 	if len(config.MasterIPs) == 0 {
 		return nil, fmt.Errorf("at least one control-plane host IP is required (masterIPs)")
 	}
-	if len(config.MasterIPs) > 1 {
-		return nil, fmt.Errorf("exactly one control-plane host is supported; HA control planes require a load balancer and stacked etcd and are not implemented yet")
+	if _, err := provider.ControlPlaneReplicas(types.ControlPlaneSpec{Replicas: len(config.MasterIPs)}); err != nil {
+		return nil, fmt.Errorf("masterIPs: %w", err)
+	}
+	if err := validateControlPlaneEndpoint(config); err != nil {
+		return nil, err
 	}
 	if config.SSHUser == "" {
 		return nil, fmt.Errorf("SSH user is required (sshUser)")
@@ -156,6 +182,32 @@ func NewProvider(config *Config) (*Provider, error) {
 	return &Provider{config: config}, nil
 }
 
+// validateControlPlaneEndpoint checks the shared endpoint of an HA control
+// plane. A kube-vip VIP must be an IP address not taken by any host.
+func validateControlPlaneEndpoint(config *Config) error {
+	if len(config.MasterIPs) == 1 {
+		if config.ControlPlaneEndpoint != "" {
+			return fmt.Errorf("controlPlaneEndpoint requires an HA control plane: list 3 or 5 masterIPs")
+		}
+		return nil
+	}
+	if config.ControlPlaneEndpoint == "" {
+		return fmt.Errorf("an HA control plane needs controlPlaneEndpoint: a free IP for the kube-vip VIP, or your load balancer's address with externalLoadBalancer: true")
+	}
+	if config.ExternalLoadBalancer {
+		return nil
+	}
+	if net.ParseIP(config.ControlPlaneEndpoint) == nil {
+		return fmt.Errorf("controlPlaneEndpoint %q must be an IP address for the kube-vip VIP (set externalLoadBalancer: true for a load balancer hostname)", config.ControlPlaneEndpoint)
+	}
+	for _, ip := range append(append([]string{}, config.MasterIPs...), config.WorkerIPs...) {
+		if ip == config.ControlPlaneEndpoint {
+			return fmt.Errorf("controlPlaneEndpoint %s is a host IP; the kube-vip VIP must be a free address on the hosts' network", ip)
+		}
+	}
+	return nil
+}
+
 // Name returns the provider name
 func (p *Provider) Name() string {
 	return "custom"
@@ -166,14 +218,37 @@ func (p *Provider) Region() string {
 	return "on-premises"
 }
 
-// masterIP returns the (single) control-plane host IP.
+// masterIP returns the first control-plane host IP, which runs kubeadm init.
 func (p *Provider) masterIP() string {
 	return p.config.MasterIPs[0]
 }
 
-// allIPs returns the master followed by all workers.
+// allIPs returns the control-plane hosts followed by all workers.
 func (p *Provider) allIPs() []string {
-	return append([]string{p.masterIP()}, p.config.WorkerIPs...)
+	return append(append([]string{}, p.config.MasterIPs...), p.config.WorkerIPs...)
+}
+
+// controlPlane describes how the API server is exposed: a single host, or
+// the shared endpoint of an HA control plane.
+func (p *Provider) controlPlane() provider.KubeadmControlPlane {
+	if len(p.config.MasterIPs) == 1 {
+		return provider.KubeadmControlPlane{}
+	}
+	return provider.KubeadmControlPlane{
+		Endpoint:     p.config.ControlPlaneEndpoint,
+		VIP:          !p.config.ExternalLoadBalancer,
+		VIPInterface: p.config.VIPInterface,
+		ExtraSANs:    p.config.MasterIPs,
+	}
+}
+
+// apiEndpoint returns the API server URL clients use.
+func (p *Provider) apiEndpoint() string {
+	host := p.masterIP()
+	if cp := p.controlPlane(); cp.HA() {
+		host = cp.Endpoint
+	}
+	return fmt.Sprintf("https://%s", net.JoinHostPort(host, "6443"))
 }
 
 // sshSigner loads (once) the user's private key configured via sshKeyPath.
@@ -252,14 +327,23 @@ func (p *Provider) prepareHost(ip, k8sMinor string) error {
 }
 
 // CreateCluster bootstraps Kubernetes with kubeadm on the configured hosts:
-// node preparation on every host, kubeadm init on the master, kubeadm join on
-// every worker. All steps are idempotent, so a failed run can be retried.
+// node preparation on every host, kubeadm init on the first master, kubeadm
+// control-plane join on the other masters, kubeadm join on every worker. All
+// steps are idempotent, so a failed run can be retried.
 func (p *Provider) CreateCluster(ctx context.Context, spec *types.ClusterSpec) (*types.Cluster, error) {
 	if spec.Provider != "custom" {
 		return nil, fmt.Errorf("provider mismatch: expected custom, got %s", spec.Provider)
 	}
+	// The hosts define the control-plane size; an explicit HA request must
+	// match them.
 	if spec.ControlPlane.Replicas > 1 || spec.ControlPlane.HighAvailability {
-		return nil, fmt.Errorf("the custom provider currently supports a single control-plane node; HA control planes require a load balancer and stacked etcd and are not implemented yet")
+		replicas, err := provider.ControlPlaneReplicas(spec.ControlPlane)
+		if err != nil {
+			return nil, err
+		}
+		if replicas != len(p.config.MasterIPs) {
+			return nil, fmt.Errorf("the cluster spec requests %d control-plane nodes but masterIPs lists %d hosts", replicas, len(p.config.MasterIPs))
+		}
 	}
 
 	signer, err := p.sshSigner()
@@ -268,7 +352,8 @@ func (p *Provider) CreateCluster(ctx context.Context, spec *types.ClusterSpec) (
 	}
 
 	masterIP := p.masterIP()
-	log.Printf("Bootstrapping Kubernetes with kubeadm on user-supplied hosts: master %s, %d worker(s)", masterIP, len(p.config.WorkerIPs))
+	cp := p.controlPlane()
+	log.Printf("Bootstrapping Kubernetes with kubeadm on user-supplied hosts: master(s) %s, %d worker(s)", strings.Join(p.config.MasterIPs, ", "), len(p.config.WorkerIPs))
 
 	k8sMinor := provider.K8sMinorFromVersion(spec.Version)
 	for _, ip := range p.allIPs() {
@@ -278,13 +363,20 @@ func (p *Provider) CreateCluster(ctx context.Context, spec *types.ClusterSpec) (
 		}
 	}
 
-	joinCmd, err := provider.KubeadmInitMaster(signer, p.config.SSHUser, masterIP, masterIP)
+	join, err := provider.KubeadmInitControlPlane(signer, p.config.SSHUser, masterIP, masterIP, cp)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, ip := range p.config.MasterIPs[1:] {
+		log.Printf("Joining control-plane host %s", ip)
+		if err := provider.KubeadmJoinControlPlane(signer, p.config.SSHUser, ip, ip, join, cp); err != nil {
+			return nil, fmt.Errorf("control-plane host %s: %w", ip, err)
+		}
+	}
+
 	for _, ip := range p.config.WorkerIPs {
-		if err := provider.KubeadmJoinWorker(signer, p.config.SSHUser, ip, joinCmd); err != nil {
+		if err := provider.KubeadmJoinWorker(signer, p.config.SSHUser, ip, join.Worker); err != nil {
 			return nil, fmt.Errorf("worker %s: %w", ip, err)
 		}
 	}
@@ -296,13 +388,14 @@ func (p *Provider) CreateCluster(ctx context.Context, spec *types.ClusterSpec) (
 		Region:    "on-premises",
 		Version:   k8sMinor,
 		Status:    types.ClusterStatusRunning,
-		Endpoint:  fmt.Sprintf("https://%s:6443", masterIP),
+		Endpoint:  p.apiEndpoint(),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		Metadata: map[string]interface{}{
-			"mode":        "byo-kubeadm",
-			"masterIP":    masterIP,
-			"workerNodes": len(p.config.WorkerIPs),
+			"mode":              "byo-kubeadm",
+			"masterIP":          masterIP,
+			"controlPlaneNodes": len(p.config.MasterIPs),
+			"workerNodes":       len(p.config.WorkerIPs),
 		},
 	}
 
@@ -318,9 +411,12 @@ func (p *Provider) DeleteCluster(ctx context.Context, clusterID string) error {
 	log.Printf("Removing Kubernetes from the hosts of cluster %q (the machines themselves are kept)", name)
 
 	resetCmd := "kubeadm reset -f && rm -rf /etc/cni/net.d"
-	// Workers first, master last, so the control plane can still answer while
-	// workers leave.
-	hosts := append(append([]string{}, p.config.WorkerIPs...), p.masterIP())
+	// Workers first, then the control plane in reverse join order with the
+	// first master last, so etcd keeps quorum while members leave.
+	hosts := append([]string{}, p.config.WorkerIPs...)
+	for i := len(p.config.MasterIPs) - 1; i >= 0; i-- {
+		hosts = append(hosts, p.config.MasterIPs[i])
+	}
 	for _, ip := range hosts {
 		if out, err := p.run(ip, resetCmd, 10*time.Minute); err != nil {
 			log.Printf("Warning: kubeadm reset on host %s failed: %v (%s)", ip, err, provider.LastLines(out, 5))
@@ -364,7 +460,7 @@ func (p *Provider) GetCluster(ctx context.Context, clusterID string) (*types.Clu
 		Region:    "on-premises",
 		Version:   version,
 		Status:    types.ClusterStatusRunning,
-		Endpoint:  fmt.Sprintf("https://%s:6443", masterIP),
+		Endpoint:  p.apiEndpoint(),
 		UpdatedAt: time.Now(),
 		Metadata: map[string]interface{}{
 			"mode":       "byo-kubeadm",
@@ -409,8 +505,8 @@ func (p *Provider) ListClusters(ctx context.Context) ([]*types.Cluster, error) {
 	return []*types.Cluster{cluster}, nil
 }
 
-// GetKubeconfig fetches the admin kubeconfig from the control-plane host and
-// rewrites loopback endpoints to the master IP.
+// GetKubeconfig fetches the admin kubeconfig from the first control-plane
+// host; it already points at the shared endpoint of an HA control plane.
 func (p *Provider) GetKubeconfig(ctx context.Context, clusterID string) (string, error) {
 	signer, err := p.sshSigner()
 	if err != nil {
@@ -502,8 +598,8 @@ func (p *Provider) GetStorage(ctx context.Context, storageID string) (*types.Sto
 	return nil, fmt.Errorf("not supported for the custom provider: storage for user-supplied hosts is managed by the user")
 }
 
-// UpgradeCluster performs an in-place kubeadm upgrade: control plane first,
-// then every worker.
+// UpgradeCluster performs an in-place kubeadm upgrade: control-plane hosts one
+// at a time, then every worker.
 func (p *Provider) UpgradeCluster(ctx context.Context, clusterID string, version string) error {
 	v := strings.TrimPrefix(strings.TrimSpace(version), "v")
 	if strings.Count(v, ".") < 2 {
@@ -515,7 +611,7 @@ func (p *Provider) UpgradeCluster(ctx context.Context, clusterID string, version
 		return err
 	}
 	log.Printf("Upgrading cluster %s to %s via kubeadm", extractClusterName(clusterID), version)
-	if err := provider.KubeadmUpgradeCluster(ctx, signer, p.config.SSHUser, p.masterIP(), p.config.WorkerIPs, version); err != nil {
+	if err := provider.KubeadmUpgradeCluster(ctx, signer, p.config.SSHUser, p.config.MasterIPs, p.config.WorkerIPs, version); err != nil {
 		return fmt.Errorf("kubeadm upgrade failed: %w", err)
 	}
 	log.Printf("Successfully upgraded cluster %s to %s", extractClusterName(clusterID), version)
@@ -0,0 +1,26 @@
+package custom
+
+import "testing"
+
+func TestValidateControlPlaneEndpoint(t *testing.T) {
+	masters := []string{"10.0.0.11", "10.0.0.12", "10.0.0.13"}
+	cases := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "single master", config: Config{MasterIPs: []string{"10.0.0.11"}}},
+		{name: "single master with endpoint", config: Config{MasterIPs: []string{"10.0.0.11"}, ControlPlaneEndpoint: "10.0.0.10"}, wantErr: true},
+		{name: "ha without endpoint", config: Config{MasterIPs: masters}, wantErr: true},
+		{name: "ha with vip", config: Config{MasterIPs: masters, ControlPlaneEndpoint: "10.0.0.10"}},
+		{name: "vip is a host ip", config: Config{MasterIPs: masters, ControlPlaneEndpoint: "10.0.0.12"}, wantErr: true},
+		{name: "vip hostname", config: Config{MasterIPs: masters, ControlPlaneEndpoint: "api.example.com"}, wantErr: true},
+		{name: "external lb hostname", config: Config{MasterIPs: masters, ControlPlaneEndpoint: "api.example.com", ExternalLoadBalancer: true}},
+	}
+	for _, c := range cases {
+		err := validateControlPlaneEndpoint(&c.config)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: validateControlPlaneEndpoint() error = %v, wantErr %v", c.name, err, c.wantErr)
+		}
+	}
+}
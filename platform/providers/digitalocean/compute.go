@@ -5,12 +5,15 @@ package digitalocean
 // containerd runtime, kube-proxy skipped (Cilium installed by the adhar
 // bootstrap replaces it), and no CNI preinstalled. Nodes therefore stay
 // NotReady until the platform bootstrap installs Cilium; the cluster is
-// considered created once the API server answers.
+// considered created once the API server answers. HA control planes (3 or 5
+// control-plane droplets, stacked etcd) sit behind a DigitalOcean load
+// balancer forwarding the API port.
 
 import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"time"
 
@@ -208,14 +211,91 @@ func (p *Provider) createComputeDroplet(ctx context.Context, name, vpcUUID strin
 	}
 }
 
+// computeAPILoadBalancerName names the load balancer in front of an HA
+// control plane.
+func computeAPILoadBalancerName(clusterName string) string {
+	return fmt.Sprintf("adhar-%s-api", clusterName)
+}
+
+// ensureAPILoadBalancer returns the IP of the load balancer forwarding the
+// Kubernetes API port to the control-plane droplets, creating it (or adding
+// missing droplets to an existing one) and waiting until it is active.
+func (p *Provider) ensureAPILoadBalancer(ctx context.Context, clusterName, vpcUUID string, dropletIDs []int) (string, error) {
+	lbName := computeAPILoadBalancerName(clusterName)
+	var lb *godo.LoadBalancer
+	if lbs, _, err := p.client.LoadBalancers.List(ctx, &godo.ListOptions{PerPage: 200}); err == nil {
+		for i := range lbs {
+			if lbs[i].Name == lbName {
+				lb = &lbs[i]
+				break
+			}
+		}
+	}
+
+	if lb == nil {
+		created, _, err := p.client.LoadBalancers.Create(ctx, &godo.LoadBalancerRequest{
+			Name:    lbName,
+			Region:  p.config.Region,
+			VPCUUID: vpcUUID,
+			ForwardingRules: []godo.ForwardingRule{
+				{EntryProtocol: "tcp", EntryPort: 6443, TargetProtocol: "tcp", TargetPort: 6443},
+			},
+			HealthCheck: &godo.HealthCheck{
+				Protocol:               "tcp",
+				Port:                   6443,
+				CheckIntervalSeconds:   3,
+				ResponseTimeoutSeconds: 3,
+				HealthyThreshold:       2,
+				UnhealthyThreshold:     2,
+			},
+			DropletIDs: dropletIDs,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create API load balancer %s: %w", lbName, err)
+		}
+		lb = created
+	} else {
+		attached := map[int]bool{}
+		for _, id := range lb.DropletIDs {
+			attached[id] = true
+		}
+		var missing []int
+		for _, id := range dropletIDs {
+			if !attached[id] {
+				missing = append(missing, id)
+			}
+		}
+		if len(missing) > 0 {
+			if _, err := p.client.LoadBalancers.AddDroplets(ctx, lb.ID, missing...); err != nil {
+				return "", fmt.Errorf("failed to add control-plane droplets to %s: %w", lbName, err)
+			}
+		}
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	for {
+		current, _, err := p.client.LoadBalancers.Get(waitCtx, lb.ID)
+		if err == nil && current.Status == "active" && current.IP != "" {
+			return current.IP, nil
+		}
+		select {
+		case <-waitCtx.Done():
+			return "", fmt.Errorf("API load balancer %s did not become active in time", lbName)
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
 // createComputeCluster provisions droplets and bootstraps Kubernetes on them
 // with kubeadm.
 func (p *Provider) createComputeCluster(ctx context.Context, spec *types.ClusterSpec) (*types.Cluster, error) {
 	name := computeClusterName(spec.Name)
 	log.Printf("Creating self-managed Kubernetes cluster %q on DigitalOcean droplets", name)
 
-	if spec.ControlPlane.Replicas > 1 || spec.ControlPlane.HighAvailability {
-		return nil, fmt.Errorf("compute mode currently supports a single control-plane node; HA control planes require a load balancer and stacked etcd and are not implemented yet")
+	controlPlaneReplicas, err := provider.ControlPlaneReplicas(spec.ControlPlane)
+	if err != nil {
+		return nil, err
 	}
 
 	k8sMinor := provider.K8sMinorFromVersion(spec.Version)
@@ -234,7 +314,7 @@ func (p *Provider) createComputeCluster(ctx context.Context, spec *types.Cluster
 		return nil, err
 	}
 
-	// Control-plane droplet
+	// Control-plane droplets
 	masterSize := spec.ControlPlane.InstanceType
 	if masterSize == "" {
 		masterSize = p.config.DropletSize
@@ -251,18 +331,41 @@ func (p *Provider) createComputeCluster(ctx context.Context, spec *types.Cluster
 	// Adopt already-provisioned droplets so an interrupted `adhar up` can be
 	// re-run without duplicating infrastructure; kubeadm init/join and the
 	// cloud-integration steps below are individually idempotent.
-	masterName := fmt.Sprintf("adhar-%s-master-1", name)
-	master := existingByName[masterName]
-	if master == nil {
-		master, err = p.createComputeDroplet(ctx, masterName, vpcUUID, key.ID, masterSize, userData, []string{tag, computeMasterTag})
+	masters := make([]*godo.Droplet, 0, controlPlaneReplicas)
+	for i := 1; i <= controlPlaneReplicas; i++ {
+		masterName := fmt.Sprintf("adhar-%s-master-%d", name, i)
+		master := existingByName[masterName]
+		if master == nil {
+			master, err = p.createComputeDroplet(ctx, masterName, vpcUUID, key.ID, masterSize, userData, []string{tag, computeMasterTag})
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			log.Printf("Adopting existing control-plane droplet %s", masterName)
+		}
+		masters = append(masters, master)
+	}
+	masterIP, _ := masters[0].PublicIPv4()
+	masterPrivateIP, _ := masters[0].PrivateIPv4()
+
+	// HA control planes are reached through a load balancer; every
+	// control-plane address stays valid on the API certificate.
+	var cp provider.KubeadmControlPlane
+	if controlPlaneReplicas > 1 {
+		var ids []int
+		for _, m := range masters {
+			ids = append(ids, m.ID)
+			pub, _ := m.PublicIPv4()
+			priv, _ := m.PrivateIPv4()
+			cp.ExtraSANs = append(cp.ExtraSANs, pub, priv)
+		}
+		lbIP, err := p.ensureAPILoadBalancer(ctx, name, vpcUUID, ids)
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		log.Printf("Adopting existing control-plane droplet %s", masterName)
+		cp.Endpoint = lbIP
+		log.Printf("Control plane of %d droplets behind load balancer %s", controlPlaneReplicas, lbIP)
 	}
-	masterIP, _ := master.PublicIPv4()
-	masterPrivateIP, _ := master.PrivateIPv4()
 
 	// Worker droplets (default pool when the spec declares none)
 	type workerReq struct {
@@ -311,11 +414,25 @@ func (p *Provider) createComputeCluster(ctx context.Context, spec *types.Cluster
 	if err := enableExternalCloudProvider(signer, masterIP, masterPrivateIP); err != nil {
 		return nil, fmt.Errorf("failed to enable external cloud provider on master: %w", err)
 	}
-	joinCmd, err := provider.KubeadmInitMaster(signer, computeSSHUser, masterIP, masterPrivateIP)
+	join, err := provider.KubeadmInitControlPlane(signer, computeSSHUser, masterIP, masterPrivateIP, cp)
 	if err != nil {
 		return nil, err
 	}
 
+	for _, m := range masters[1:] {
+		ip, _ := m.PublicIPv4()
+		privIP, _ := m.PrivateIPv4()
+		if err := provider.WaitForNodePrep(ctx, signer, computeSSHUser, ip, 15*time.Minute); err != nil {
+			return nil, fmt.Errorf("control-plane %s not ready: %w", m.Name, err)
+		}
+		if err := enableExternalCloudProvider(signer, ip, privIP); err != nil {
+			return nil, fmt.Errorf("control-plane %s: %w", m.Name, err)
+		}
+		if err := provider.KubeadmJoinControlPlane(signer, computeSSHUser, ip, privIP, join, cp); err != nil {
+			return nil, fmt.Errorf("control-plane %s: %w", m.Name, err)
+		}
+	}
+
 	for _, d := range workerDroplets {
 		ip, _ := d.PublicIPv4()
 		if err := provider.WaitForNodePrep(ctx, signer, computeSSHUser, ip, 15*time.Minute); err != nil {
@@ -325,7 +442,7 @@ func (p *Provider) createComputeCluster(ctx context.Context, spec *types.Cluster
 		if err := enableExternalCloudProvider(signer, ip, privIP); err != nil {
 			return nil, fmt.Errorf("worker %s: %w", d.Name, err)
 		}
-		if err := provider.KubeadmJoinWorker(signer, computeSSHUser, ip, joinCmd); err != nil {
+		if err := provider.KubeadmJoinWorker(signer, computeSSHUser, ip, join.Worker); err != nil {
 			return nil, fmt.Errorf("worker %s: %w", d.Name, err)
 		}
 	}
@@ -334,6 +451,10 @@ func (p *Provider) createComputeCluster(ctx context.Context, spec *types.Cluster
 		return nil, err
 	}
 
+	endpoint := masterIP
+	if cp.HA() {
+		endpoint = cp.Endpoint
+	}
 	cluster := &types.Cluster{
 		ID:        tag,
 		Name:      name,
@@ -341,15 +462,16 @@ func (p *Provider) createComputeCluster(ctx context.Context, spec *types.Cluster
 		Region:    p.config.Region,
 		Version:   k8sMinor,
 		Status:    types.ClusterStatusRunning,
-		Endpoint:  fmt.Sprintf("https://%s:6443", masterIP),
+		Endpoint:  fmt.Sprintf("https://%s:6443", endpoint),
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 		Metadata: map[string]interface{}{
-			"mode":        "compute",
-			"region":      p.config.Region,
-			"vpc":         vpcUUID,
-			"masterIP":    masterIP,
-			"workerNodes": len(workerDroplets),
+			"mode":              "compute",
+			"region":            p.config.Region,
+			"vpc":               vpcUUID,
+			"masterIP":          masterIP,
+			"controlPlaneNodes": len(masters),
+			"workerNodes":       len(workerDroplets),
 		},
 	}
 	p.clusters[cluster.ID] = cluster
@@ -492,8 +614,8 @@ func (p *Provider) listComputeClusters(ctx context.Context) ([]*types.Cluster, e
 }
 
 // deleteComputeCluster tears down all cloud resources of a compute cluster:
-// droplets (by tag), the firewall, the per-cluster VPC, the registered SSH
-// key, and local state.
+// droplets (by tag), the API load balancer, the firewall, the per-cluster
+// VPC, the registered SSH key, and local state.
 func (p *Provider) deleteComputeCluster(ctx context.Context, clusterName string) error {
 	name := computeClusterName(clusterName)
 	tag := computeClusterTag(name)
@@ -520,6 +642,17 @@ func (p *Provider) deleteComputeCluster(ctx context.Context, clusterName string)
 		break
 	}
 
+	// API load balancer of an HA control plane
+	if lbs, _, err := p.client.LoadBalancers.List(ctx, &godo.ListOptions{PerPage: 200}); err == nil {
+		for _, lb := range lbs {
+			if lb.Name == computeAPILoadBalancerName(name) {
+				if _, err := p.client.LoadBalancers.Delete(ctx, lb.ID); err != nil {
+					log.Printf("Warning: failed to delete load balancer %s: %v", lb.Name, err)
+				}
+			}
+		}
+	}
+
 	// Firewall
 	if fws, _, err := p.client.Firewalls.List(ctx, &godo.ListOptions{PerPage: 200}); err == nil {
 		for _, fw := range fws {
@@ -589,7 +722,8 @@ func (p *Provider) isComputeCluster(ctx context.Context, clusterID string) bool
 }
 
 // upgradeComputeCluster performs an in-place kubeadm upgrade of a compute
-// cluster: control plane first, then every worker.
+// cluster: control-plane droplets one at a time (in name order), then every
+// worker.
 func (p *Provider) upgradeComputeCluster(ctx context.Context, clusterID, version string) error {
 	name := computeClusterName(clusterID)
 	log.Printf("Upgrading self-managed cluster %q to %s via kubeadm", name, version)
@@ -598,8 +732,8 @@ func (p *Provider) upgradeComputeCluster(ctx context.Context, clusterID, version
 	if err != nil {
 		return err
 	}
-	var masterIP string
-	var workerIPs []string
+	sort.Slice(droplets, func(i, j int) bool { return droplets[i].Name < droplets[j].Name })
+	var masterIPs, workerIPs []string
 	for _, d := range droplets {
 		ip, _ := d.PublicIPv4()
 		if ip == "" {
@@ -612,19 +746,19 @@ func (p *Provider) upgradeComputeCluster(ctx context.Context, clusterID, version
 			}
 		}
 		if isMaster {
-			masterIP = ip
+			masterIPs = append(masterIPs, ip)
 		} else {
 			workerIPs = append(workerIPs, ip)
 		}
 	}
-	if masterIP == "" {
+	if len(masterIPs) == 0 {
 		return fmt.Errorf("no reachable control-plane droplet for cluster %s", name)
 	}
 	signer, err := provider.LoadClusterSSHKey(name)
 	if err != nil {
 		return err
 	}
-	if err := provider.KubeadmUpgradeCluster(ctx, signer, computeSSHUser, masterIP, workerIPs, version); err != nil {
+	if err := provider.KubeadmUpgradeCluster(ctx, signer, computeSSHUser, masterIPs, workerIPs, version); err != nil {
 		return fmt.Errorf("kubeadm upgrade of cluster %s failed: %w", name, err)
 	}
 	log.Printf("Successfully upgraded cluster %q to %s", name, version)
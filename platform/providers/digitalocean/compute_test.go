@@ -46,3 +46,9 @@ func TestLastLines(t *testing.T) {
 		t.Errorf("lastLines short = %q", got)
 	}
 }
+
+func TestComputeAPILoadBalancerName(t *testing.T) {
+	if got := computeAPILoadBalancerName("demo"); got != "adhar-demo-api" {
+		t.Errorf("computeAPILoadBalancerName = %q, want adhar-demo-api", got)
+	}
+}
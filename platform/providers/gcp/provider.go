@@ -2465,7 +2465,7 @@ func (p *Provider) UpgradeCluster(ctx context.Context, clusterID string, version
 			workerIPs = append(workerIPs, w.PublicIP)
 		}
 	}
-	if err := provider.KubeadmUpgradeCluster(ctx, signer, gcpSSHUser, infrastructure.MasterNodes[0].PublicIP, workerIPs, version); err != nil {
+	if err := provider.KubeadmUpgradeCluster(ctx, signer, gcpSSHUser, []string{infrastructure.MasterNodes[0].PublicIP}, workerIPs, version); err != nil {
 		return fmt.Errorf("kubeadm upgrade of cluster %s failed: %w", clusterName, err)
 	}
 	log.Printf("Successfully upgraded cluster %s to %s", clusterName, version)
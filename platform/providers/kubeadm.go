@@ -7,11 +7,16 @@ package provider
 // flow), and no CNI preinstalled. Nodes therefore report NotReady until the
 // platform bootstrap installs Cilium; a cluster counts as created once the
 // API server answers.
+//
+// Control planes are either a single node reached on its public IP, or 3/5
+// nodes with stacked etcd behind a shared endpoint — the provider's load
+// balancer, or a kube-vip VIP where no load balancer exists.
 
 import (
 	"context"
 	"crypto/ed25519"
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
 	"net"
@@ -20,6 +25,8 @@ import (
 	"strings"
 	"time"
 
+	"adhar-io/adhar/platform/types"
+
 	"golang.org/x/crypto/ssh"
 )
 
@@ -36,8 +43,69 @@ const (
 	// KubeadmPodCIDR matches the pod network the platform's Cilium install
 	// expects (same value the Kind flow uses).
 	KubeadmPodCIDR = "10.244.0.0/16"
+
+	// KubeVIPImage announces the control-plane VIP on clusters without a
+	// load balancer in front of the API server.
+	KubeVIPImage = "ghcr.io/kube-vip/kube-vip:v0.8.9"
+
+	// kubeVIPManifestPath is the kube-vip static pod on control-plane nodes.
+	kubeVIPManifestPath = "/etc/kubernetes/manifests/kube-vip.yaml"
 )
 
+// ControlPlaneReplicas returns the number of control-plane nodes to
+// bootstrap for a cluster spec: 1 by default, 3 when only HighAvailability is
+// requested. Stacked etcd needs an odd member count, so only 1, 3 and 5 are
+// accepted.
+func ControlPlaneReplicas(cp types.ControlPlaneSpec) (int, error) {
+	replicas := cp.Replicas
+	if replicas <= 1 && cp.HighAvailability {
+		replicas = 3
+	}
+	switch replicas {
+	case 0, 1:
+		return 1, nil
+	case 3, 5:
+		return replicas, nil
+	}
+	return 0, fmt.Errorf("unsupported control-plane size %d: use 1, or 3 or 5 for an HA control plane (stacked etcd needs an odd member count)", replicas)
+}
+
+// KubeadmControlPlane describes how the API server is exposed. The zero value
+// is a single control-plane node reached on its public IP.
+type KubeadmControlPlane struct {
+	// Endpoint is the stable API server address (IP or DNS name, no port)
+	// shared by all control-plane nodes: the provider's load balancer or a
+	// kube-vip VIP. Setting it enables control-plane joins.
+	Endpoint string
+
+	// VIP runs kube-vip on every control-plane node to announce Endpoint via
+	// ARP; Endpoint must then be a free IP on the nodes' L2 network.
+	VIP bool
+
+	// VIPInterface is the interface kube-vip announces on; detected from the
+	// default route when empty.
+	VIPInterface string
+
+	// ExtraSANs are added to the API server certificate, typically the
+	// addresses of every control-plane node so each stays reachable directly.
+	ExtraSANs []string
+}
+
+// HA reports whether the control plane sits behind a shared endpoint.
+func (cp KubeadmControlPlane) HA() bool {
+	return cp.Endpoint != ""
+}
+
+// KubeadmJoinCommands are the join commands issued by the first control-plane
+// node.
+type KubeadmJoinCommands struct {
+	Worker string
+
+	// ControlPlane joins an additional control-plane node (--control-plane
+	// with the uploaded certificate key); empty unless the control plane is HA.
+	ControlPlane string
+}
+
 // K8sMinorFromVersion derives the pkgs.k8s.io minor stream ("1.34") from a
 // requested version ("", "1.34", "1.34.2", "v1.34.2").
 func K8sMinorFromVersion(requested string) string {
@@ -244,35 +312,191 @@ func WaitForNodePrep(ctx context.Context, signer ssh.Signer, user, ip string, de
 	}
 }
 
-// KubeadmInitMaster runs kubeadm init on the control-plane node (idempotent —
-// skipped when the node is already initialized) and returns the worker join
-// command. kube-proxy is skipped: the platform bootstrap installs Cilium with
-// kubeProxyReplacement.
+// KubeadmInitMaster runs kubeadm init on a single control-plane node and
+// returns the worker join command. See KubeadmInitControlPlane.
 func KubeadmInitMaster(signer ssh.Signer, user, publicIP, privateIP string) (string, error) {
+	join, err := KubeadmInitControlPlane(signer, user, publicIP, privateIP, KubeadmControlPlane{})
+	if err != nil {
+		return "", err
+	}
+	return join.Worker, nil
+}
+
+// KubeadmInitControlPlane runs kubeadm init on the first control-plane node
+// (idempotent — skipped when the node is already initialized) and returns the
+// join commands. kube-proxy is skipped: the platform bootstrap installs Cilium
+// with kubeProxyReplacement.
+//
+// With a shared endpoint the node advertises its private IP (etcd peers and
+// API servers talk over the private network) and the control-plane
+// certificates are re-uploaded under a fresh certificate key on every call,
+// so the returned control-plane join command is valid for the next two hours
+// even when init itself ran earlier.
+func KubeadmInitControlPlane(signer ssh.Signer, user, publicIP, privateIP string, cp KubeadmControlPlane) (*KubeadmJoinCommands, error) {
+	endpoint := publicIP
+	if cp.HA() {
+		endpoint = cp.Endpoint
+		if cp.VIP {
+			// The admin kubeconfig only gains its RBAC binding during init,
+			// so the first kube-vip runs with super-admin.conf (Kubernetes
+			// 1.29+) until init has finished.
+			if err := installKubeVIP(signer, user, publicIP, cp, "/etc/kubernetes/super-admin.conf"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	initCmd := fmt.Sprintf(
 		"test -f /etc/kubernetes/admin.conf || kubeadm init "+
 			"--pod-network-cidr=%s "+
 			"--skip-phases=addon/kube-proxy "+
 			"--control-plane-endpoint=%s "+
-			"--apiserver-cert-extra-sans=%s,%s",
-		KubeadmPodCIDR, publicIP, publicIP, privateIP)
+			"--apiserver-cert-extra-sans=%s",
+		KubeadmPodCIDR, endpoint, strings.Join(apiServerSANs(cp, publicIP, privateIP), ","))
+	if cp.HA() {
+		initCmd += " --apiserver-advertise-address=" + privateIP
+	}
 	if out, err := SSHRun(signer, user, publicIP, initCmd, 15*time.Minute); err != nil {
-		return "", fmt.Errorf("kubeadm init failed: %w (output: %s)", err, LastLines(out, 15))
+		return nil, fmt.Errorf("kubeadm init failed: %w (output: %s)", err, LastLines(out, 15))
+	}
+	if cp.HA() && cp.VIP {
+		if out, err := SSHRun(signer, user, publicIP, "sed -i 's|/etc/kubernetes/super-admin.conf|/etc/kubernetes/admin.conf|' "+kubeVIPManifestPath, 2*time.Minute); err != nil {
+			return nil, fmt.Errorf("failed to switch kube-vip to the admin kubeconfig: %w (output: %s)", err, LastLines(out, 5))
+		}
+	}
+	if err := preferNodeIPs(signer, user, publicIP); err != nil {
+		return nil, err
 	}
 
-	// Cloud node hostnames are generally not DNS-resolvable by the API
-	// server; prefer node IPs so `kubectl logs/exec` work. Static-pod edit is
-	// idempotent (delete + single re-insert).
-	addrFix := `grep -q kubelet-preferred-address-types /etc/kubernetes/manifests/kube-apiserver.yaml || sed -i 's|    - kube-apiserver|    - kube-apiserver\n    - --kubelet-preferred-address-types=InternalIP,ExternalIP,Hostname|' /etc/kubernetes/manifests/kube-apiserver.yaml`
-	if out, err := SSHRun(signer, user, publicIP, addrFix, 2*time.Minute); err != nil {
-		return "", fmt.Errorf("failed to set apiserver kubelet address preference: %w (output: %s)", err, LastLines(out, 5))
+	workerJoin, err := SSHRun(signer, user, publicIP, "kubeadm token create --print-join-command", 2*time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubeadm join token: %w", err)
+	}
+	join := &KubeadmJoinCommands{Worker: strings.TrimSpace(workerJoin)}
+	if !cp.HA() {
+		return join, nil
 	}
 
-	joinCmd, err := SSHRun(signer, user, publicIP, "kubeadm token create --print-join-command", 2*time.Minute)
+	uploadCmd := `KEY=$(kubeadm certs certificate-key) && ` +
+		`kubeadm init phase upload-certs --upload-certs --certificate-key "$KEY" >/dev/null && ` +
+		`kubeadm token create --print-join-command --certificate-key "$KEY"`
+	out, err := SSHRun(signer, user, publicIP, uploadCmd, 2*time.Minute)
 	if err != nil {
-		return "", fmt.Errorf("failed to create kubeadm join token: %w", err)
+		return nil, fmt.Errorf("failed to upload control-plane certificates: %w", err)
 	}
-	return strings.TrimSpace(joinCmd), nil
+	join.ControlPlane = LastLines(out, 1)
+	if !strings.Contains(join.ControlPlane, "--control-plane") {
+		return nil, fmt.Errorf("unexpected control-plane join command: %s", join.ControlPlane)
+	}
+	return join, nil
+}
+
+// KubeadmJoinControlPlane joins an additional control-plane node with stacked
+// etcd (idempotent). The node advertises its private IP; with a VIP, kube-vip
+// is installed first so the node takes part in the leader election.
+func KubeadmJoinControlPlane(signer ssh.Signer, user, publicIP, privateIP string, join *KubeadmJoinCommands, cp KubeadmControlPlane) error {
+	if join == nil || join.ControlPlane == "" {
+		return fmt.Errorf("no control-plane join command: additional control-plane nodes need a shared API endpoint")
+	}
+	if cp.VIP {
+		if err := installKubeVIP(signer, user, publicIP, cp, "/etc/kubernetes/admin.conf"); err != nil {
+			return err
+		}
+	}
+	joinCmd := fmt.Sprintf("test -f /etc/kubernetes/kubelet.conf || %s --apiserver-advertise-address=%s", join.ControlPlane, privateIP)
+	if out, err := SSHRun(signer, user, publicIP, joinCmd, 15*time.Minute); err != nil {
+		return fmt.Errorf("kubeadm control-plane join failed on %s: %w (output: %s)", publicIP, err, LastLines(out, 15))
+	}
+	return preferNodeIPs(signer, user, publicIP)
+}
+
+// apiServerSANs returns the extra API server certificate SANs: the node's own
+// addresses, the shared endpoint and any configured extras, deduplicated.
+func apiServerSANs(cp KubeadmControlPlane, publicIP, privateIP string) []string {
+	var sans []string
+	seen := map[string]bool{}
+	for _, san := range append([]string{cp.Endpoint, publicIP, privateIP}, cp.ExtraSANs...) {
+		if san != "" && !seen[san] {
+			seen[san] = true
+			sans = append(sans, san)
+		}
+	}
+	return sans
+}
+
+// preferNodeIPs makes the API server on a control-plane node reach kubelets
+// by IP: cloud node hostnames are generally not DNS-resolvable by the API
+// server, and `kubectl logs/exec` need them. The static-pod edit is
+// idempotent (single insert guarded by grep).
+func preferNodeIPs(signer ssh.Signer, user, ip string) error {
+	addrFix := `grep -q kubelet-preferred-address-types /etc/kubernetes/manifests/kube-apiserver.yaml || sed -i 's|    - kube-apiserver|    - kube-apiserver\n    - --kubelet-preferred-address-types=InternalIP,ExternalIP,Hostname|' /etc/kubernetes/manifests/kube-apiserver.yaml`
+	if out, err := SSHRun(signer, user, ip, addrFix, 2*time.Minute); err != nil {
+		return fmt.Errorf("failed to set apiserver kubelet address preference: %w (output: %s)", err, LastLines(out, 5))
+	}
+	return nil
+}
+
+// installKubeVIP writes the kube-vip static pod on a control-plane node
+// (idempotent), detecting the announcing interface from the default route
+// unless one is configured.
+func installKubeVIP(signer ssh.Signer, user, ip string, cp KubeadmControlPlane, kubeconfig string) error {
+	iface := cp.VIPInterface
+	if iface == "" {
+		out, err := SSHRun(signer, user, ip, "ip route show default | awk '{print $5; exit}'", 30*time.Second)
+		if err != nil {
+			return fmt.Errorf("failed to detect the kube-vip interface on %s: %w", ip, err)
+		}
+		if iface = strings.TrimSpace(out); iface == "" {
+			return fmt.Errorf("no default route on %s to detect the kube-vip interface; set it explicitly", ip)
+		}
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte(kubeVIPManifest(cp.Endpoint, iface, kubeconfig)))
+	cmd := fmt.Sprintf("test -f %[1]s || { mkdir -p $(dirname %[1]s) && echo %[2]s | base64 -d >%[1]s; }", kubeVIPManifestPath, encoded)
+	if out, err := SSHRun(signer, user, ip, cmd, 2*time.Minute); err != nil {
+		return fmt.Errorf("failed to install kube-vip on %s: %w (output: %s)", ip, err, LastLines(out, 5))
+	}
+	return nil
+}
+
+// kubeVIPManifest renders the kube-vip static pod announcing vip over ARP on
+// iface, with leader election among the control-plane nodes.
+func kubeVIPManifest(vip, iface, kubeconfig string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Pod
+metadata:
+  name: kube-vip
+  namespace: kube-system
+spec:
+  containers:
+  - name: kube-vip
+    image: %[1]s
+    imagePullPolicy: IfNotPresent
+    args: ["manager"]
+    env:
+    - {name: vip_arp, value: "true"}
+    - {name: port, value: "6443"}
+    - {name: vip_interface, value: %[3]q}
+    - {name: vip_cidr, value: "32"}
+    - {name: cp_enable, value: "true"}
+    - {name: cp_namespace, value: kube-system}
+    - {name: vip_leaderelection, value: "true"}
+    - {name: vip_leasename, value: plndr-cp-lock}
+    - {name: vip_leaseduration, value: "5"}
+    - {name: vip_renewdeadline, value: "3"}
+    - {name: vip_retryperiod, value: "1"}
+    - {name: address, value: %[2]q}
+    securityContext:
+      capabilities:
+        add: ["NET_ADMIN", "NET_RAW"]
+    volumeMounts:
+    - {name: kubeconfig, mountPath: /etc/kubernetes/admin.conf}
+  hostAliases:
+  - {ip: 127.0.0.1, hostnames: ["kubernetes"]}
+  hostNetwork: true
+  volumes:
+  - name: kubeconfig
+    hostPath: {path: %[4]s}
+`, KubeVIPImage, vip, iface, kubeconfig)
 }
 
 // KubeadmJoinWorker joins a worker node to the cluster (idempotent).
@@ -302,40 +526,51 @@ func LastLines(s string, n int) string {
 	return strings.Join(lines, "\n")
 }
 
-// KubeadmUpgradeCluster performs an in-place minor/patch upgrade of a
-// single-control-plane compute cluster: control plane first via
-// `kubeadm upgrade apply`, then kubelet/kubectl on every node. Workers are
-// upgraded via `kubeadm upgrade node` after the control plane. The package
-// stream is switched to the target minor so apt can see the target version.
-func KubeadmUpgradeCluster(ctx context.Context, signer ssh.Signer, user, masterIP string, workerIPs []string, targetVersion string) error {
+// KubeadmUpgradeCluster performs an in-place minor/patch upgrade of a compute
+// cluster. Control-plane nodes roll one at a time — `kubeadm upgrade apply`
+// on the first, `kubeadm upgrade node` on the rest — and each must serve
+// again before the next goes down, so an HA control plane keeps etcd quorum
+// and a reachable API throughout. Workers follow with `kubeadm upgrade node`.
+// Every node then gets the matching kubelet/kubectl; the package stream is
+// switched to the target minor so apt can see the target version.
+func KubeadmUpgradeCluster(ctx context.Context, signer ssh.Signer, user string, controlPlaneIPs, workerIPs []string, targetVersion string) error {
+	if len(controlPlaneIPs) == 0 {
+		return fmt.Errorf("no control-plane nodes to upgrade")
+	}
 	minor := K8sMinorFromVersion(targetVersion)
 	repoSwitch := fmt.Sprintf(
 		"curl -fsSL https://pkgs.k8s.io/core:/stable:/v%[1]s/deb/Release.key | gpg --dearmor --yes -o /etc/apt/keyrings/kubernetes-apt-keyring.gpg && "+
 			"echo 'deb [signed-by=/etc/apt/keyrings/kubernetes-apt-keyring.gpg] https://pkgs.k8s.io/core:/stable:/v%[1]s/deb/ /' >/etc/apt/sources.list.d/kubernetes.list && "+
 			"apt-get update", minor)
-
-	// Control plane: upgrade kubeadm, apply, then kubelet.
-	steps := []string{
-		repoSwitch,
-		"apt-mark unhold kubeadm && apt-get install -y kubeadm && apt-mark hold kubeadm",
-		fmt.Sprintf("kubeadm upgrade apply -y v%s", strings.TrimPrefix(targetVersion, "v")),
-		"apt-mark unhold kubelet kubectl && apt-get install -y kubelet kubectl && apt-mark hold kubelet kubectl && systemctl restart kubelet",
+	nodeSteps := func(upgradeCmd string) []string {
+		return []string{
+			repoSwitch,
+			"apt-mark unhold kubeadm && apt-get install -y kubeadm && apt-mark hold kubeadm",
+			upgradeCmd,
+			"apt-mark unhold kubelet kubectl && apt-get install -y kubelet kubectl && apt-mark hold kubelet kubectl && systemctl restart kubelet",
+		}
 	}
-	for _, cmd := range steps {
-		if out, err := SSHRun(signer, user, masterIP, cmd, 20*time.Minute); err != nil {
-			return fmt.Errorf("control-plane upgrade step failed: %w (output: %s)", err, LastLines(out, 15))
+
+	// Control plane: upgrade kubeadm, apply (first node) or node config
+	// (others), then kubelet; wait for the local API server before moving on.
+	for i, ip := range controlPlaneIPs {
+		upgradeCmd := "kubeadm upgrade node"
+		if i == 0 {
+			upgradeCmd = fmt.Sprintf("kubeadm upgrade apply -y v%s", strings.TrimPrefix(targetVersion, "v"))
+		}
+		for _, cmd := range nodeSteps(upgradeCmd) {
+			if out, err := SSHRun(signer, user, ip, cmd, 20*time.Minute); err != nil {
+				return fmt.Errorf("control-plane %s upgrade step failed: %w (output: %s)", ip, err, LastLines(out, 15))
+			}
+		}
+		if err := waitForAPIServer(ctx, signer, user, ip, 5*time.Minute); err != nil {
+			return err
 		}
 	}
 
 	// Workers: upgrade kubeadm, node config, then kubelet.
 	for _, ip := range workerIPs {
-		wSteps := []string{
-			repoSwitch,
-			"apt-mark unhold kubeadm && apt-get install -y kubeadm && apt-mark hold kubeadm",
-			"kubeadm upgrade node",
-			"apt-mark unhold kubelet kubectl && apt-get install -y kubelet kubectl && apt-mark hold kubelet kubectl && systemctl restart kubelet",
-		}
-		for _, cmd := range wSteps {
+		for _, cmd := range nodeSteps("kubeadm upgrade node") {
 			if out, err := SSHRun(signer, user, ip, cmd, 20*time.Minute); err != nil {
 				return fmt.Errorf("worker %s upgrade step failed: %w (output: %s)", ip, err, LastLines(out, 15))
 			}
@@ -343,3 +578,21 @@ func KubeadmUpgradeCluster(ctx context.Context, signer ssh.Signer, user, masterI
 	}
 	return nil
 }
+
+// waitForAPIServer waits until the API server on a control-plane node answers
+// its readiness endpoint locally.
+func waitForAPIServer(ctx context.Context, signer ssh.Signer, user, ip string, deadline time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, deadline)
+	defer cancel()
+	for {
+		out, err := SSHRun(signer, user, ip, "curl -ksf https://127.0.0.1:6443/readyz", 30*time.Second)
+		if err == nil && strings.TrimSpace(out) == "ok" {
+			return nil
+		}
+		select {
+		case <-waitCtx.Done():
+			return fmt.Errorf("API server on %s not ready %s after the upgrade (last error: %v)", ip, deadline, err)
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"adhar-io/adhar/platform/types"
+)
+
+func TestControlPlaneReplicas(t *testing.T) {
+	cases := []struct {
+		spec    types.ControlPlaneSpec
+		want    int
+		wantErr bool
+	}{
+		{spec: types.ControlPlaneSpec{}, want: 1},
+		{spec: types.ControlPlaneSpec{Replicas: 1}, want: 1},
+		{spec: types.ControlPlaneSpec{HighAvailability: true}, want: 3},
+		{spec: types.ControlPlaneSpec{Replicas: 1, HighAvailability: true}, want: 3},
+		{spec: types.ControlPlaneSpec{Replicas: 3}, want: 3},
+		{spec: types.ControlPlaneSpec{Replicas: 5, HighAvailability: true}, want: 5},
+		{spec: types.ControlPlaneSpec{Replicas: 2}, wantErr: true},
+		{spec: types.ControlPlaneSpec{Replicas: 4}, wantErr: true},
+		{spec: types.ControlPlaneSpec{Replicas: 7}, wantErr: true},
+	}
+	for _, c := range cases {
+		got, err := ControlPlaneReplicas(c.spec)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("ControlPlaneReplicas(%+v) = %d, want error", c.spec, got)
+			}
+			continue
+		}
+		if err != nil || got != c.want {
+			t.Errorf("ControlPlaneReplicas(%+v) = %d, %v, want %d", c.spec, got, err, c.want)
+		}
+	}
+}
+
+func TestAPIServerSANs(t *testing.T) {
+	single := KubeadmControlPlane{}
+	if single.HA() {
+		t.Error("control plane without an endpoint must not be HA")
+	}
+	if got, want := apiServerSANs(single, "203.0.113.10", "10.0.0.10"), []string{"203.0.113.10", "10.0.0.10"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("apiServerSANs(single) = %v, want %v", got, want)
+	}
+
+	ha := KubeadmControlPlane{
+		Endpoint:  "api.example.com",
+		ExtraSANs: []string{"203.0.113.10", "10.0.0.10", "203.0.113.11", "", "10.0.0.11"},
+	}
+	if !ha.HA() {
+		t.Error("control plane with an endpoint must be HA")
+	}
+	want := []string{"api.example.com", "203.0.113.10", "10.0.0.10", "203.0.113.11", "10.0.0.11"}
+	if got := apiServerSANs(ha, "203.0.113.10", "10.0.0.10"); !reflect.DeepEqual(got, want) {
+		t.Errorf("apiServerSANs(ha) = %v, want %v", got, want)
+	}
+}
+
+func TestKubeVIPManifest(t *testing.T) {
+	var pod corev1.Pod
+	if err := yaml.Unmarshal([]byte(kubeVIPManifest("10.0.0.100", "eth1", "/etc/kubernetes/admin.conf")), &pod); err != nil {
+		t.Fatalf("kube-vip manifest is not valid YAML: %v", err)
+	}
+	if pod.Namespace != "kube-system" || !pod.Spec.HostNetwork {
+		t.Errorf("kube-vip must run host-networked in kube-system, got namespace %q hostNetwork %v", pod.Namespace, pod.Spec.HostNetwork)
+	}
+	if len(pod.Spec.Containers) != 1 || pod.Spec.Containers[0].Image != KubeVIPImage {
+		t.Fatalf("unexpected containers: %+v", pod.Spec.Containers)
+	}
+	env := map[string]string{}
+	for _, e := range pod.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	for name, want := range map[string]string{"address": "10.0.0.100", "vip_interface": "eth1", "port": "6443", "cp_enable": "true"} {
+		if env[name] != want {
+			t.Errorf("env %s = %q, want %q", name, env[name], want)
+		}
+	}
+	if len(pod.Spec.Volumes) != 1 || pod.Spec.Volumes[0].HostPath == nil || pod.Spec.Volumes[0].HostPath.Path != "/etc/kubernetes/admin.conf" {
+		t.Errorf("kubeconfig volume = %+v", pod.Spec.Volumes)
+	}
+}
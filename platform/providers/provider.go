@@ -380,12 +380,13 @@ func buildClusterSpec(envConfig *config.ResolvedEnvironmentConfig) (*types.Clust
 	// Set defaults based on environment type
 	isProduction := envConfig.ResolvedType == config.EnvironmentTypeProduction
 
-	// Configure control plane. Single control-plane node for now: the
-	// compute-mode (kubeadm) providers bootstrap one control plane and reject
-	// >1 (HA control planes need a load balancer + stacked etcd — the roadmap's
-	// Phase 1 "next step"). Managed providers run their own managed control
-	// plane, so 1 is the right request here regardless. Platform-level HA
-	// (ArgoCD/Gitea replicas, CNPG) is driven separately by enableHAMode.
+	// Configure control plane. A single control-plane node by default; the
+	// controlPlaneReplicas key below asks for 3 or 5, which the kubeadm
+	// providers (custom, DigitalOcean, Civo, AWS) bootstrap as an HA control
+	// plane with stacked etcd behind a load balancer or kube-vip VIP. Managed
+	// providers run their own managed control plane, so 1 is the right request
+	// there regardless. Platform-level HA (ArgoCD/Gitea replicas, CNPG) is
+	// driven separately by enableHAMode.
 	spec.ControlPlane = types.ControlPlaneSpec{
 		Replicas: 1,
 	}
@@ -410,7 +411,7 @@ func buildClusterSpec(envConfig *config.ResolvedEnvironmentConfig) (*types.Clust
 	}
 
 	// Configure domain management
-	spec.Domain = buildDomainConfig(envConfig)
+	spec.Domain = BuildDomainConfig(envConfig)
 
 	// Apply cluster-specific configuration
 	for _, kv := range envConfig.ResolvedClusterConfig {
@@ -436,8 +437,8 @@ func buildClusterSpec(envConfig *config.ResolvedEnvironmentConfig) (*types.Clust
 	return spec, nil
 }
 
-// buildDomainConfig creates domain configuration based on environment and provider
-func buildDomainConfig(envConfig *config.ResolvedEnvironmentConfig) *types.DomainConfig {
+// BuildDomainConfig creates domain configuration based on environment and provider
+func BuildDomainConfig(envConfig *config.ResolvedEnvironmentConfig) *types.DomainConfig {
 	// Get domain configuration from global settings or use defaults
 	var baseDomain string
 	var email string
@@ -459,13 +460,29 @@ func buildDomainConfig(envConfig *config.ResolvedEnvironmentConfig) *types.Domai
 		email = "admin@adhar.localtest.me"
 	}
 
-	return &types.DomainConfig{
+	domainConfig := &types.DomainConfig{
 		BaseDomain: baseDomain,
 		TLS: types.TLSConfig{
 			Enabled: true,
 			Email:   email,
 		},
 	}
+
+	if dns := envConfig.DNS; dns != nil {
+		domainConfig.DNS = types.DNSConfig{
+			Provider:          dns.Provider,
+			Zone:              dns.Zone,
+			CredentialsSecret: dns.CredentialsSecret,
+			Config:            dns.Config,
+			SplitHorizon: types.SplitHorizonConfig{
+				Enabled:      dns.SplitHorizon.Enabled,
+				InternalZone: dns.SplitHorizon.InternalZone,
+				Target:       dns.SplitHorizon.Target,
+			},
+		}
+	}
+
+	return domainConfig
 }
 
 // buildCredentials creates provider credentials from environment configuration
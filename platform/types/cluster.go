@@ -234,8 +234,19 @@ type TLSConfig struct {
 
 // DNSConfig defines DNS provider configuration
 type DNSConfig struct {
-	Provider string            `json:"provider,omitempty"`
-	Config   map[string]string `json:"config,omitempty"`
+	Provider          string             `json:"provider,omitempty"`          // route53, clouddns, azuredns, digitalocean, cloudflare, rfc2136
+	Zone              string             `json:"zone,omitempty"`              // public zone managed by the provider, defaults to the base domain
+	CredentialsSecret string             `json:"credentialsSecret,omitempty"` // Secret holding the provider credentials
+	Config            map[string]string  `json:"config,omitempty"`            // provider-specific settings
+	SplitHorizon      SplitHorizonConfig `json:"splitHorizon,omitempty"`
+}
+
+// SplitHorizonConfig serves the domain from an internal CoreDNS zone inside the
+// cluster while the DNS provider publishes the public records.
+type SplitHorizonConfig struct {
+	Enabled      bool   `json:"enabled"`
+	InternalZone string `json:"internalZone,omitempty"` // zone only resolvable in the cluster
+	Target       string `json:"target,omitempty"`       // in-cluster service the zone resolves to
 }
 
 // IngressConfig defines ingress controller configuration
@@ -0,0 +1,304 @@
+// Package gitopsrepo checks out, edits and pushes GitOps repositories hosted
+// on the platform Gitea. The gitops and pipeline commands share it.
+package gitopsrepo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"adhar-io/adhar/platform/k8s"
+	"adhar-io/adhar/platform/utils"
+
+	"code.gitea.io/sdk/gitea"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	billyutil "github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServiceURL is the platform Gitea as seen from pods in the cluster.
+const ServiceURL = "http://gitea-http." + utils.GiteaNamespace + ".svc.cluster.local:3000"
+
+// AdminAuth reads the Gitea admin credentials from the cluster.
+func AdminAuth(ctx context.Context) (*githttp.BasicAuth, error) {
+	cs, err := k8s.GetClientset()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := cs.CoreV1().Secrets(utils.GiteaNamespace).Get(ctx, utils.GiteaAdminSecret, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Gitea credentials %s/%s: %w", utils.GiteaNamespace, utils.GiteaAdminSecret, err)
+	}
+	return &githttp.BasicAuth{Username: string(secret.Data["username"]), Password: string(secret.Data["password"])}, nil
+}
+
+// ExternalURL rewrites an in-cluster Gitea URL (as ArgoCD sees it) to the
+// externally reachable one. URLs that do not point at a cluster service are
+// returned unchanged.
+func ExternalURL(ctx context.Context, repoURL string) (string, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid repository URL %q: %w", repoURL, err)
+	}
+	host := u.Hostname()
+	if strings.Contains(host, ".") && !strings.Contains(host, ".svc") {
+		return repoURL, nil
+	}
+	base, err := utils.GiteaBaseUrl(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the external Gitea URL: %w", err)
+	}
+	return strings.TrimRight(base, "/") + u.Path, nil
+}
+
+// IsGiteaURL reports whether repoURL points at the platform Gitea service.
+func IsGiteaURL(repoURL string) bool {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(u.Hostname(), "gitea") && strings.Contains(u.Hostname(), ".svc")
+}
+
+// OwnerRepo splits a repository URL path into owner and repository name.
+func OwnerRepo(repoURL string) (owner, name string, err error) {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid repository URL %q: %w", repoURL, err)
+	}
+	parts := strings.Split(strings.Trim(strings.TrimSuffix(u.Path, ".git"), "/"), "/")
+	if len(parts) < 2 {
+		return "", "", fmt.Errorf("%s does not look like a Gitea repository URL (want .../owner/repo)", repoURL)
+	}
+	return parts[len(parts)-2], parts[len(parts)-1], nil
+}
+
+// Client returns an API client for the platform Gitea that authenticates
+// with auth.
+func Client(ctx context.Context, auth *githttp.BasicAuth) (*gitea.Client, error) {
+	base, err := utils.GiteaBaseUrl(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the external Gitea URL: %w", err)
+	}
+	return gitea.NewClient(base, gitea.SetHTTPClient(utils.GetHttpClient()),
+		gitea.SetBasicAuth(auth.Username, auth.Password), gitea.SetContext(ctx))
+}
+
+// Repo is an in-memory checkout of a GitOps repository hosted on the
+// platform Gitea, used to commit changes on behalf of the CLI.
+type Repo struct {
+	URL    string // URL as seen by ArgoCD
+	Branch string
+	repo   *git.Repository
+	fs     billy.Filesystem
+	auth   *githttp.BasicAuth
+}
+
+// Open clones branch of repoURL (the default branch when branch is empty or
+// HEAD) with full history.
+func Open(ctx context.Context, auth *githttp.BasicAuth, repoURL, branch string) (*Repo, error) {
+	external, err := ExternalURL(ctx, repoURL)
+	if err != nil {
+		return nil, err
+	}
+	opts := &git.CloneOptions{
+		URL:             external,
+		Auth:            auth,
+		SingleBranch:    true,
+		InsecureSkipTLS: true,
+	}
+	if branch != "" && branch != "HEAD" {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(branch)
+	}
+	fs := memfs.New()
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), fs, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone %s: %w", external, err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD of %s: %w", external, err)
+	}
+	if !head.Name().IsBranch() {
+		return nil, fmt.Errorf("%s: revision %q is not a branch; only branches can be committed to", repoURL, branch)
+	}
+	return &Repo{URL: repoURL, Branch: head.Name().Short(), repo: repo, fs: fs, auth: auth}, nil
+}
+
+// RestorePath replaces dir in the worktree with its contents at revision and
+// stages the result. It reports whether anything changed.
+func (g *Repo) RestorePath(revision, dir string) (bool, error) {
+	commit, err := g.repo.CommitObject(plumbing.NewHash(revision))
+	if err != nil {
+		return false, fmt.Errorf("revision %s not found on branch %s: %w", revision, g.Branch, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return false, err
+	}
+	dir = strings.Trim(path.Clean("/"+dir), "/")
+	if dir != "" {
+		if tree, err = tree.Tree(dir); err != nil {
+			return false, fmt.Errorf("path %q does not exist at %s: %w", dir, shortSHA(revision), err)
+		}
+	}
+
+	if dir == "" {
+		entries, err := g.fs.ReadDir("/")
+		if err != nil {
+			return false, err
+		}
+		for _, e := range entries {
+			if e.Name() != ".git" {
+				if err := billyutil.RemoveAll(g.fs, e.Name()); err != nil {
+					return false, err
+				}
+			}
+		}
+	} else if err := billyutil.RemoveAll(g.fs, dir); err != nil {
+		return false, err
+	}
+
+	err = tree.Files().ForEach(func(f *object.File) error {
+		return g.checkoutFile(path.Join(dir, f.Name), f)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check out %s at %s: %w", dir, shortSHA(revision), err)
+	}
+	return g.stageAll()
+}
+
+// checkoutFile writes f to name in the worktree with its git mode: symlinks
+// stay symlinks and executables keep their exec bit.
+func (g *Repo) checkoutFile(name string, f *object.File) error {
+	if f.Mode == filemode.Symlink {
+		target, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		return g.fs.Symlink(target, name)
+	}
+	perm := os.FileMode(0o644)
+	if f.Mode == filemode.Executable {
+		perm = 0o755
+	}
+	r, err := f.Reader()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	out, err := g.fs.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, r)
+	return err
+}
+
+func (g *Repo) stageAll() (bool, error) {
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return false, err
+	}
+	if err := wt.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+		return false, fmt.Errorf("failed to stage changes: %w", err)
+	}
+	st, err := wt.Status()
+	if err != nil {
+		return false, err
+	}
+	return !st.IsClean(), nil
+}
+
+// Head returns the commit SHA the local branch points at.
+func (g *Repo) Head() (string, error) {
+	ref, err := g.repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return ref.Hash().String(), nil
+}
+
+// ReadFile returns the content of name in the worktree.
+func (g *Repo) ReadFile(name string) ([]byte, error) {
+	f, err := g.fs.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s not found on %s: %w", name, g.Branch, err)
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// WriteFile replaces name in the worktree and stages it. It reports whether
+// anything changed.
+func (g *Repo) WriteFile(name string, data []byte) (bool, error) {
+	if err := billyutil.WriteFile(g.fs, name, data, 0o644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return g.stageAll()
+}
+
+// Commit records the staged changes as author and returns the commit SHA.
+func (g *Repo) Commit(message, author string) (string, error) {
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	sig := &object.Signature{Name: author, Email: "adhar-cli@adhar.io", When: time.Now()}
+	hash, err := wt.Commit(message, &git.CommitOptions{Author: sig, Committer: sig})
+	if err != nil {
+		return "", fmt.Errorf("failed to commit: %w", err)
+	}
+	return hash.String(), nil
+}
+
+// Push pushes the local branch to the remote branch named target (the same
+// branch when target is empty). Pushes to another branch are forced, so a
+// re-run replaces its own earlier proposal branch.
+func (g *Repo) Push(ctx context.Context, target string) error {
+	if target == "" {
+		target = g.Branch
+	}
+	spec := gitconfig.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", g.Branch, target))
+	if target != g.Branch {
+		spec = "+" + spec
+	}
+	if err := g.repo.PushContext(ctx, &git.PushOptions{Auth: g.auth, InsecureSkipTLS: true, RefSpecs: []gitconfig.RefSpec{spec}}); err != nil {
+		return fmt.Errorf("failed to push %s to %s: %w", target, g.URL, err)
+	}
+	return nil
+}
+
+// CommitAndPush commits the staged changes as author and pushes the branch.
+// It returns the new commit SHA.
+func (g *Repo) CommitAndPush(ctx context.Context, message, author string) (string, error) {
+	sha, err := g.Commit(message, author)
+	if err != nil {
+		return "", err
+	}
+	if err := g.Push(ctx, ""); err != nil {
+		return "", err
+	}
+	return sha, nil
+}
+
+func shortSHA(rev string) string {
+	if len(rev) == 40 {
+		return rev[:7]
+	}
+	return rev
+}
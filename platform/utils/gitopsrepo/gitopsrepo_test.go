@@ -0,0 +1,73 @@
+package gitopsrepo
+
+import (
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	billyutil "github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+func TestRestorePathKeepsGitModes(t *testing.T) {
+	fs := memfs.New()
+	repo, err := git.Init(memory.NewStorage(), fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &Repo{Branch: "main", repo: repo, fs: fs}
+
+	if err := billyutil.WriteFile(fs, "deploy/run.sh", []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := billyutil.WriteFile(fs, "deploy/app.yaml", []byte("kind: ConfigMap\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Symlink("app.yaml", "deploy/current.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.stageAll(); err != nil {
+		t.Fatal(err)
+	}
+	good, err := g.Commit("good", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := billyutil.RemoveAll(fs, "deploy"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.WriteFile("deploy/app.yaml", []byte("kind: Secret\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Commit("bad", "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := g.RestorePath(good, "deploy")
+	if err != nil || !changed {
+		t.Fatalf("RestorePath = %v, %v; want a change", changed, err)
+	}
+	restored, err := g.Commit("restore", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	treeOf := func(rev string) string {
+		c, err := repo.CommitObject(plumbing.NewHash(rev))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c.TreeHash.String()
+	}
+	if treeOf(restored) != treeOf(good) {
+		t.Error("restored tree differs from the original: file modes or symlinks were not kept")
+	}
+	if target, err := fs.Readlink("deploy/current.yaml"); err != nil || target != "app.yaml" {
+		t.Errorf("deploy/current.yaml: Readlink = %q, %v; want a symlink to app.yaml", target, err)
+	}
+	if st, err := fs.Stat("deploy/run.sh"); err != nil || st.Mode()&0o100 == 0 {
+		t.Errorf("deploy/run.sh lost its exec bit: %v, %v", st, err)
+	}
+}
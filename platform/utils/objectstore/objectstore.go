@@ -0,0 +1,90 @@
+// Package objectstore reads objects from S3-compatible buckets (AWS S3,
+// minio, Ceph RGW…). Requests are signed with SigV4 from the aws-sdk core
+// module, so the CLI does not need the full S3 client.
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// ErrNotFound is returned by Store.Get for a missing key.
+var ErrNotFound = errors.New("object not found")
+
+// emptyPayloadSHA256 is the SHA-256 of an empty request body.
+const emptyPayloadSHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// Store is an S3-compatible bucket.
+type Store struct {
+	Endpoint  string // scheme://host[:port], no trailing slash
+	Bucket    string
+	Region    string
+	PathStyle bool
+	Creds     aws.Credentials // requests are anonymous without an access key
+	Client    *http.Client    // http.DefaultClient when nil
+}
+
+// ObjectURL builds the request URL for key in path- or virtual-hosted style.
+func (s *Store) ObjectURL(key string) (string, error) {
+	u, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid object store endpoint %q: %w", s.Endpoint, err)
+	}
+	segments := strings.Split(key, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	escaped := strings.Join(segments, "/")
+	if s.PathStyle {
+		u.Path = "/" + s.Bucket + "/" + key
+		u.RawPath = "/" + url.PathEscape(s.Bucket) + "/" + escaped
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+		u.Path = "/" + key
+		u.RawPath = "/" + escaped
+	}
+	return u.String(), nil
+}
+
+// Get fetches an object and returns its body and ETag (quotes stripped).
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, string, error) {
+	target, err := s.ObjectURL(key)
+	if err != nil {
+		return nil, "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req.Header.Set("x-amz-content-sha256", emptyPayloadSHA256)
+	if s.Creds.AccessKeyID != "" {
+		if err := v4.NewSigner().SignHTTP(ctx, s.Creds, req, emptyPayloadSHA256, "s3", s.Region, time.Now()); err != nil {
+			return nil, "", fmt.Errorf("failed to sign request for %s: %w", key, err)
+		}
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch s3://%s/%s: %w", s.Bucket, key, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, "", fmt.Errorf("s3://%s/%s: %w", s.Bucket, key, ErrNotFound)
+		}
+		return nil, "", fmt.Errorf("failed to fetch s3://%s/%s: %s", s.Bucket, key, resp.Status)
+	}
+	return resp.Body, strings.Trim(resp.Header.Get("ETag"), `"`), nil
+}
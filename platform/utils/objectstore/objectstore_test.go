@@ -0,0 +1,60 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestObjectURL(t *testing.T) {
+	path := &Store{Endpoint: "http://minio.velero.svc:9000", Bucket: "velero", PathStyle: true}
+	if u, _ := path.ObjectURL("adhar/backups/a b/a.tar.gz"); u != "http://minio.velero.svc:9000/velero/adhar/backups/a%20b/a.tar.gz" {
+		t.Errorf("path-style URL = %q", u)
+	}
+	vhost := &Store{Endpoint: "https://s3.eu-west-1.amazonaws.com", Bucket: "backups"}
+	if u, _ := vhost.ObjectURL("backups/a/a.tar.gz"); u != "https://backups.s3.eu-west-1.amazonaws.com/backups/a/a.tar.gz" {
+		t.Errorf("virtual-hosted URL = %q", u)
+	}
+}
+
+func TestGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minio/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/bucket/key" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("ETag", `"abc"`)
+		_, _ = w.Write([]byte("payload"))
+	}))
+	defer srv.Close()
+
+	store := &Store{
+		Endpoint: srv.URL, Bucket: "bucket", Region: "us-east-1", PathStyle: true,
+		Creds: aws.Credentials{AccessKeyID: "minio", SecretAccessKey: "minio123"},
+	}
+	body, etag, err := store.Get(context.Background(), "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	if data, _ := io.ReadAll(body); string(data) != "payload" || etag != "abc" {
+		t.Errorf("Get = %q, etag %q", data, etag)
+	}
+	if _, _, err := store.Get(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing key: got %v, want ErrNotFound", err)
+	}
+	anon := &Store{Endpoint: srv.URL, Bucket: "bucket", PathStyle: true}
+	if _, _, err := anon.Get(context.Background(), "key"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("unsigned request: got %v, want a 403 error", err)
+	}
+}
@@ -0,0 +1,65 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"adhar-io/adhar/api/v1alpha1"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// DataPlaneDefaulter applies DataPlane defaults on admission
+type DataPlaneDefaulter struct {
+	decoder admission.Decoder
+}
+
+// Handle patches a DataPlane admission request with its defaults.
+func (d *DataPlaneDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	dp := &v1alpha1.DataPlane{}
+	if err := d.decoder.Decode(req, dp); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	dp.Default()
+	return patch(req, dp)
+}
+
+// AdharPlatformDefaulter applies AdharPlatform defaults on admission
+type AdharPlatformDefaulter struct {
+	decoder admission.Decoder
+}
+
+// Handle patches an AdharPlatform admission request with its defaults.
+func (d *AdharPlatformDefaulter) Handle(ctx context.Context, req admission.Request) admission.Response {
+	platform := &v1alpha1.AdharPlatform{}
+	if err := d.decoder.Decode(req, platform); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	platform.Default()
+	return patch(req, platform)
+}
+
+// patch returns the JSON patch turning the request object into obj.
+func patch(req admission.Request, obj interface{}) admission.Response {
+	marshaled, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// InjectDecoder injects the admission decoder into the defaulter.
+func (d *DataPlaneDefaulter) InjectDecoder(dec admission.Decoder) error {
+	d.decoder = dec
+	return nil
+}
+
+// InjectDecoder injects the admission decoder into the defaulter.
+func (d *AdharPlatformDefaulter) InjectDecoder(dec admission.Decoder) error {
+	d.decoder = dec
+	return nil
+}
+
+var _ admission.Handler = &DataPlaneDefaulter{}
+var _ admission.Handler = &AdharPlatformDefaulter{}
@@ -0,0 +1,79 @@
+package webhooks
+
+import (
+	"context"
+	"testing"
+
+	"adhar-io/adhar/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+)
+
+func TestDataPlaneDefaulter(t *testing.T) {
+	d := &DataPlaneDefaulter{}
+	require.NoError(t, d.InjectDecoder(newDecoder(t)))
+
+	resp := d.Handle(context.Background(), request(t, admissionv1.Create, dataPlane(v1alpha1.InfraModeVCluster, nil), nil))
+	require.True(t, resp.Allowed, resp.Result)
+	patches := map[string]interface{}{}
+	for _, p := range resp.Patches {
+		patches[p.Path] = p.Value
+	}
+	assert.Equal(t, "standard", patches["/spec/profile"])
+	assert.Equal(t, v1alpha1.DefaultObservabilityHub, patches["/spec/observability/hub"])
+
+	// Explicit values are left alone.
+	dp := dataPlane(v1alpha1.InfraModeVCluster, func(dp *v1alpha1.DataPlane) {
+		dp.Spec.Profile = v1alpha1.ProfileEdge
+		dp.Spec.Observability.Hub = "hub-eu"
+	})
+	resp = d.Handle(context.Background(), request(t, admissionv1.Create, dp, nil))
+	for _, p := range resp.Patches {
+		assert.NotContains(t, p.Path, "/spec/", "unexpected patch %+v", p)
+	}
+}
+
+func TestAdharPlatformDefaulter(t *testing.T) {
+	d := &AdharPlatformDefaulter{}
+	require.NoError(t, d.InjectDecoder(newDecoder(t)))
+
+	p := &v1alpha1.AdharPlatform{}
+	p.APIVersion, p.Kind, p.Name = "platform.adhar.io/v1alpha1", "AdharPlatform", "adhar"
+	resp := d.Handle(context.Background(), request(t, admissionv1.Create, p, nil))
+	require.True(t, resp.Allowed, resp.Result)
+	patches := map[string]interface{}{}
+	for _, patch := range resp.Patches {
+		patches[patch.Path] = patch.Value
+	}
+	assert.Equal(t, "kind", patches["/spec/provider"])
+}
+
+func TestImmutableFields(t *testing.T) {
+	dv := &DataPlaneValidator{}
+	require.NoError(t, dv.InjectDecoder(newDecoder(t)))
+	old := dataPlane(v1alpha1.InfraModeComposite, nil)
+	moved := dataPlane(v1alpha1.InfraModeComposite, func(dp *v1alpha1.DataPlane) { dp.Spec.Infrastructure.Region = "us-east-1" })
+	resp := dv.Handle(context.Background(), request(t, admissionv1.Update, moved, old))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "region is immutable")
+	scaled := dataPlane(v1alpha1.InfraModeComposite, func(dp *v1alpha1.DataPlane) { dp.Spec.Infrastructure.NodePools[0].Count = 5 })
+	resp = dv.Handle(context.Background(), request(t, admissionv1.Update, scaled, old))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+
+	pv := &AdharPlatformValidator{}
+	require.NoError(t, pv.InjectDecoder(newDecoder(t)))
+	platform := func(provider v1alpha1.EnvironmentProvider) *v1alpha1.AdharPlatform {
+		p := &v1alpha1.AdharPlatform{}
+		p.APIVersion, p.Kind, p.Name = "platform.adhar.io/v1alpha1", "AdharPlatform", "adhar"
+		p.Spec.Provider = provider
+		return p
+	}
+	// Defaulting an object stored with an empty provider is not a change.
+	resp = pv.Handle(context.Background(), request(t, admissionv1.Update, platform(v1alpha1.ProviderKind), platform("")))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+	resp = pv.Handle(context.Background(), request(t, admissionv1.Update, platform(v1alpha1.ProviderAWS), platform("")))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "provider is immutable")
+}
@@ -0,0 +1,183 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"adhar-io/adhar/api/v1alpha1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// DataPlaneValidator validates DataPlane resources
+type DataPlaneValidator struct {
+	decoder admission.Decoder
+}
+
+// Handle validates a DataPlane admission request.
+func (v *DataPlaneValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	dp := &v1alpha1.DataPlane{}
+	if err := v.decoder.Decode(req, dp); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	infra := dp.Spec.Infrastructure
+	switch infra.Mode {
+	case v1alpha1.InfraModeComposite:
+		// Composite planes are provisioned through a CompositeCluster XR,
+		// which needs a cloud provider, a region and node pools
+		if !contains(cloudProviders, string(infra.Provider)) {
+			return admission.Denied(fmt.Sprintf("infrastructure.provider must be one of %s for mode composite", strings.Join(cloudProviders, ", ")))
+		}
+		if infra.Region == "" {
+			return admission.Denied("infrastructure.region is required for mode composite")
+		}
+		if len(infra.NodePools) == 0 {
+			return admission.Denied("at least one node pool is required for mode composite")
+		}
+		seen := map[string]bool{}
+		for i, pool := range infra.NodePools {
+			if pool.Name == "" || pool.Size == "" {
+				return admission.Denied(fmt.Sprintf("node pool %d must specify name and size", i))
+			}
+			if seen[pool.Name] {
+				return admission.Denied(fmt.Sprintf("duplicate node pool name %q", pool.Name))
+			}
+			seen[pool.Name] = true
+			if pool.Count < 1 {
+				return admission.Denied(fmt.Sprintf("node pool %q count must be >= 1", pool.Name))
+			}
+		}
+	case v1alpha1.InfraModeAdopt:
+		if infra.KubeconfigSecretRef == nil || infra.KubeconfigSecretRef.Name == "" {
+			return admission.Denied("infrastructure.kubeconfigSecretRef.name is required for mode adopt")
+		}
+	case v1alpha1.InfraModeVCluster:
+	default:
+		return admission.Denied(fmt.Sprintf("invalid infrastructure.mode: %q", infra.Mode))
+	}
+
+	for key, value := range dp.Spec.Placement.Labels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return admission.Denied(fmt.Sprintf("invalid placement label key %q: %s", key, strings.Join(errs, "; ")))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return admission.Denied(fmt.Sprintf("invalid value for placement label %q: %s", key, strings.Join(errs, "; ")))
+		}
+	}
+
+	// The controller never migrates a plane between modes
+	if req.Operation == admissionv1.Update && len(req.OldObject.Raw) > 0 {
+		old := &v1alpha1.DataPlane{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if old.Spec.Infrastructure.Mode != infra.Mode {
+			return admission.Denied(fmt.Sprintf("infrastructure.mode is immutable (was %s)", old.Spec.Infrastructure.Mode))
+		}
+		// A provisioned CompositeCluster cannot move between clouds or regions
+		if infra.Mode == v1alpha1.InfraModeComposite {
+			if old.Spec.Infrastructure.Provider != infra.Provider {
+				return admission.Denied(fmt.Sprintf("infrastructure.provider is immutable (was %s)", old.Spec.Infrastructure.Provider))
+			}
+			if old.Spec.Infrastructure.Region != infra.Region {
+				return admission.Denied(fmt.Sprintf("infrastructure.region is immutable (was %s)", old.Spec.Infrastructure.Region))
+			}
+		}
+	}
+
+	return admission.Allowed("dataplane validation passed")
+}
+
+// AdharPlatformValidator validates AdharPlatform resources
+type AdharPlatformValidator struct {
+	decoder admission.Decoder
+}
+
+// Handle validates an AdharPlatform admission request.
+func (v *AdharPlatformValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	platform := &v1alpha1.AdharPlatform{}
+	if err := v.decoder.Decode(req, platform); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if p := string(platform.Spec.Provider); p != "" {
+		validProviders := append([]string{string(v1alpha1.ProviderKind), string(v1alpha1.ProviderCustom)}, cloudProviders...)
+		if !contains(validProviders, p) {
+			return admission.Denied(fmt.Sprintf("invalid provider: %s. Must be one of: %s", p, strings.Join(validProviders, ", ")))
+		}
+	}
+
+	build := platform.Spec.BuildCustomization
+	if build.Protocol != "" && build.Protocol != "http" && build.Protocol != "https" {
+		return admission.Denied(fmt.Sprintf("invalid protocol: %s. Must be http or https", build.Protocol))
+	}
+	if build.Port != "" {
+		port, err := strconv.Atoi(build.Port)
+		if err != nil || port < 1 || port > 65535 {
+			return admission.Denied(fmt.Sprintf("invalid port: %s", build.Port))
+		}
+	}
+	for field, host := range map[string]string{"host": build.Host, "ingressHost": build.IngressHost} {
+		if host == "" {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+			return admission.Denied(fmt.Sprintf("invalid %s %q: %s", field, host, strings.Join(errs, "; ")))
+		}
+	}
+
+	// Build customization and provider are baked into the cluster when it
+	// is created
+	if req.Operation == admissionv1.Update && len(req.OldObject.Raw) > 0 {
+		old := &v1alpha1.AdharPlatform{}
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		// The self-signed certificate is rotated by `adhar certs rotate-ca`
+		oldBuild, newBuild := old.Spec.BuildCustomization, build
+		oldBuild.SelfSignedCert, newBuild.SelfSignedCert = "", ""
+		if !reflect.DeepEqual(oldBuild, newBuild) {
+			return admission.Denied("buildCustomization cannot change once the platform is created")
+		}
+		// Objects stored before the defaulting webhook carry an empty provider
+		old.Default()
+		current := platform.DeepCopy()
+		current.Default()
+		if old.Spec.Provider != current.Spec.Provider {
+			return admission.Denied(fmt.Sprintf("provider is immutable (was %s)", old.Spec.Provider))
+		}
+	}
+
+	return admission.Allowed("platform validation passed")
+}
+
+// InjectDecoder injects the admission decoder into the validator.
+func (v *DataPlaneValidator) InjectDecoder(d admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// InjectDecoder injects the admission decoder into the validator.
+func (v *AdharPlatformValidator) InjectDecoder(d admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
+// cloudProviders are the providers a composite data plane can be built on.
+var cloudProviders = []string{
+	string(v1alpha1.ProviderAWS),
+	string(v1alpha1.ProviderAzure),
+	string(v1alpha1.ProviderGKE),
+	string(v1alpha1.ProviderDO),
+	string(v1alpha1.ProviderCivo),
+}
+
+var _ admission.Handler = &DataPlaneValidator{}
+var _ admission.Handler = &AdharPlatformValidator{}
@@ -0,0 +1,328 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"adhar-io/adhar/api/v1alpha1"
+	"adhar-io/adhar/platform/domain"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	// ConfigurationName is the ValidatingWebhookConfiguration the controller
+	// manager installs for its validators.
+	ConfigurationName = "adhar-platform-validation"
+	// DefaultingConfigurationName is the MutatingWebhookConfiguration the
+	// controller manager installs for its defaulters.
+	DefaultingConfigurationName = "adhar-platform-defaulting"
+	// ServiceName is the Service in front of the manager's webhook server.
+	ServiceName = "adhar-webhook"
+	// SecretName holds the webhook server's serving certificate.
+	SecretName = "adhar-webhook-tls"
+
+	// CertModeSelfSigned stores a self-signed serving certificate in SecretName.
+	CertModeSelfSigned = "self-signed"
+	// CertModeCertManager has cert-manager issue the serving certificate.
+	CertModeCertManager = "cert-manager"
+)
+
+// decodingHandler is an admission handler that is handed its decoder at setup.
+type decodingHandler interface {
+	admission.Handler
+	InjectDecoder(admission.Decoder) error
+}
+
+// route binds a platform.adhar.io resource to the handler serving it.
+type route struct {
+	resource string
+	handler  decodingHandler
+}
+
+// routes lists the validated resources. The Composite* kinds are the
+// namespaced Crossplane XRs; dataplanes and adharplatforms are adhar's own CRDs.
+func routes() []route {
+	return []route{
+		{resource: "compositeclusters", handler: &ClusterValidator{}},
+		{resource: "compositedatabases", handler: &DatabaseValidator{}},
+		{resource: "compositenetworks", handler: &NetworkValidator{}},
+		{resource: "compositeapplications", handler: &ApplicationValidator{}},
+		{resource: "compositeenvironments", handler: &EnvironmentValidator{}},
+		{resource: "dataplanes", handler: &DataPlaneValidator{}},
+		{resource: "adharplatforms", handler: &AdharPlatformValidator{}},
+	}
+}
+
+// defaultingRoutes lists the resources defaulted on admission.
+func defaultingRoutes() []route {
+	return []route{
+		{resource: "dataplanes", handler: &DataPlaneDefaulter{}},
+		{resource: "adharplatforms", handler: &AdharPlatformDefaulter{}},
+	}
+}
+
+// Path is the URL path the webhook server serves resource's validator on.
+func Path(resource string) string {
+	return "/validate-platform-adhar-io-v1alpha1-" + resource
+}
+
+// DefaultingPath is the URL path the webhook server serves resource's
+// defaulter on.
+func DefaultingPath(resource string) string {
+	return "/mutate-platform-adhar-io-v1alpha1-" + resource
+}
+
+// SetupWebhooks registers all validators and defaulters with the manager's
+// webhook server.
+func SetupWebhooks(mgr manager.Manager) error {
+	decoder := admission.NewDecoder(mgr.GetScheme())
+	server := mgr.GetWebhookServer()
+	register := func(r route, path string) error {
+		if err := r.handler.InjectDecoder(decoder); err != nil {
+			return fmt.Errorf("injecting decoder for %s: %w", r.resource, err)
+		}
+		server.Register(path, &webhook.Admission{Handler: r.handler})
+		return nil
+	}
+	for _, r := range routes() {
+		if err := register(r, Path(r.resource)); err != nil {
+			return err
+		}
+	}
+	for _, r := range defaultingRoutes() {
+		if err := register(r, DefaultingPath(r.resource)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidatingWebhookConfiguration returns the configuration routing CREATE and
+// UPDATE of every validated resource to service in namespace. Webhooks fail
+// open so a manager restart cannot block platform resources.
+func ValidatingWebhookConfiguration(namespace, service string, caBundle []byte) *admissionregistrationv1.ValidatingWebhookConfiguration {
+	failurePolicy := admissionregistrationv1.Ignore
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	matchPolicy := admissionregistrationv1.Equivalent
+	timeout := int32(10)
+	port := int32(443)
+
+	cfg := &admissionregistrationv1.ValidatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admissionregistration.k8s.io/v1", Kind: "ValidatingWebhookConfiguration"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   ConfigurationName,
+			Labels: map[string]string{"app.kubernetes.io/managed-by": "adhar"},
+		},
+	}
+	for _, r := range routes() {
+		path := Path(r.resource)
+		cfg.Webhooks = append(cfg.Webhooks, admissionregistrationv1.ValidatingWebhook{
+			Name: strings.TrimSuffix(r.resource, "s") + "." + v1alpha1.GroupVersion.Group,
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Namespace: namespace,
+					Name:      service,
+					Path:      &path,
+					Port:      &port,
+				},
+				CABundle: caBundle,
+			},
+			Rules: []admissionregistrationv1.RuleWithOperations{{
+				Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   []string{v1alpha1.GroupVersion.Group},
+					APIVersions: []string{v1alpha1.GroupVersion.Version},
+					Resources:   []string{r.resource},
+				},
+			}},
+			FailurePolicy:           &failurePolicy,
+			MatchPolicy:             &matchPolicy,
+			SideEffects:             &sideEffects,
+			TimeoutSeconds:          &timeout,
+			AdmissionReviewVersions: []string{"v1"},
+		})
+	}
+	return cfg
+}
+
+// MutatingWebhookConfiguration returns the configuration routing CREATE and
+// UPDATE of every defaulted resource to service in namespace. Like the
+// validators, the defaulters fail open; the controllers apply the same
+// defaults in memory.
+func MutatingWebhookConfiguration(namespace, service string, caBundle []byte) *admissionregistrationv1.MutatingWebhookConfiguration {
+	failurePolicy := admissionregistrationv1.Ignore
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	matchPolicy := admissionregistrationv1.Equivalent
+	reinvocation := admissionregistrationv1.NeverReinvocationPolicy
+	timeout := int32(10)
+	port := int32(443)
+
+	cfg := &admissionregistrationv1.MutatingWebhookConfiguration{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admissionregistration.k8s.io/v1", Kind: "MutatingWebhookConfiguration"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   DefaultingConfigurationName,
+			Labels: map[string]string{"app.kubernetes.io/managed-by": "adhar"},
+		},
+	}
+	for _, r := range defaultingRoutes() {
+		path := DefaultingPath(r.resource)
+		cfg.Webhooks = append(cfg.Webhooks, admissionregistrationv1.MutatingWebhook{
+			Name: strings.TrimSuffix(r.resource, "s") + ".defaults." + v1alpha1.GroupVersion.Group,
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Namespace: namespace,
+					Name:      service,
+					Path:      &path,
+					Port:      &port,
+				},
+				CABundle: caBundle,
+			},
+			Rules: []admissionregistrationv1.RuleWithOperations{{
+				Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create, admissionregistrationv1.Update},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   []string{v1alpha1.GroupVersion.Group},
+					APIVersions: []string{v1alpha1.GroupVersion.Version},
+					Resources:   []string{r.resource},
+				},
+			}},
+			FailurePolicy:           &failurePolicy,
+			MatchPolicy:             &matchPolicy,
+			SideEffects:             &sideEffects,
+			ReinvocationPolicy:      &reinvocation,
+			TimeoutSeconds:          &timeout,
+			AdmissionReviewVersions: []string{"v1"},
+		})
+	}
+	return cfg
+}
+
+// InstallConfigurations creates or updates the validating and mutating
+// webhook configurations for service in namespace.
+func InstallConfigurations(ctx context.Context, kubeClient client.Client, namespace, service string, caBundle []byte) error {
+	vwc := ValidatingWebhookConfiguration(namespace, service, caBundle)
+	existing := &admissionregistrationv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: vwc.Name}}
+	_, err := controllerutil.CreateOrUpdate(ctx, kubeClient, existing, func() error {
+		existing.Labels = vwc.Labels
+		existing.Webhooks = vwc.Webhooks
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("installing ValidatingWebhookConfiguration %s: %w", vwc.Name, err)
+	}
+
+	mwc := MutatingWebhookConfiguration(namespace, service, caBundle)
+	existingMutating := &admissionregistrationv1.MutatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{Name: mwc.Name}}
+	_, err = controllerutil.CreateOrUpdate(ctx, kubeClient, existingMutating, func() error {
+		existingMutating.Labels = mwc.Labels
+		existingMutating.Webhooks = mwc.Webhooks
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("installing MutatingWebhookConfiguration %s: %w", mwc.Name, err)
+	}
+	return nil
+}
+
+// EnsureServingCertificate makes sure a serving certificate for service
+// exists, writes it to certDir as tls.crt/tls.key for the webhook server and
+// returns the CA bundle the API server should trust.
+func EnsureServingCertificate(ctx context.Context, kubeClient client.Client, mode, namespace, service, certDir string) ([]byte, error) {
+	sans := []string{
+		service,
+		service + "." + namespace,
+		service + "." + namespace + ".svc",
+		service + "." + namespace + ".svc.cluster.local",
+	}
+
+	var cert, key, caBundle []byte
+	switch mode {
+	case CertModeSelfSigned:
+		var err error
+		cert, key, err = domain.GetOrCreateSelfSignedCertificate(ctx, kubeClient, SecretName, namespace, sans)
+		if err != nil {
+			return nil, fmt.Errorf("webhook serving certificate: %w", err)
+		}
+		caBundle = cert
+	case CertModeCertManager:
+		secret, err := requestCertificate(ctx, kubeClient, namespace, sans)
+		if err != nil {
+			return nil, err
+		}
+		cert, key, caBundle = secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey], secret.Data["ca.crt"]
+	default:
+		return nil, fmt.Errorf("unknown webhook certificate mode %q (want %s or %s)", mode, CertModeSelfSigned, CertModeCertManager)
+	}
+
+	if err := os.MkdirAll(certDir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating webhook cert dir: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(certDir, corev1.TLSCertKey), cert, 0o600); err != nil {
+		return nil, fmt.Errorf("writing webhook certificate: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(certDir, corev1.TLSPrivateKeyKey), key, 0o600); err != nil {
+		return nil, fmt.Errorf("writing webhook key: %w", err)
+	}
+	return caBundle, nil
+}
+
+// requestCertificate has cert-manager issue the serving certificate from a
+// self-signed Issuer and waits for the secret, including its ca.crt.
+func requestCertificate(ctx context.Context, kubeClient client.Client, namespace string, sans []string) (*corev1.Secret, error) {
+	issuer := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Issuer",
+		"metadata":   map[string]interface{}{"name": ServiceName + "-selfsigned", "namespace": namespace},
+		"spec":       map[string]interface{}{"selfSigned": map[string]interface{}{}},
+	}}
+	dnsNames := make([]interface{}, 0, len(sans))
+	for _, san := range sans {
+		dnsNames = append(dnsNames, san)
+	}
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"metadata":   map[string]interface{}{"name": ServiceName, "namespace": namespace},
+		"spec": map[string]interface{}{
+			"secretName": SecretName,
+			"dnsNames":   dnsNames,
+			"issuerRef":  map[string]interface{}{"kind": "Issuer", "name": ServiceName + "-selfsigned"},
+		},
+	}}
+	for _, obj := range []*unstructured.Unstructured{issuer, certificate} {
+		if err := kubeClient.Patch(ctx, obj, client.Apply, client.FieldOwner(v1alpha1.FieldManager), client.ForceOwnership); err != nil {
+			return nil, fmt.Errorf("applying cert-manager %s %s (is cert-manager installed?): %w", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	secret := &corev1.Secret{}
+	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, 2*time.Minute, true, func(ctx context.Context) (bool, error) {
+		err := kubeClient.Get(ctx, types.NamespacedName{Name: SecretName, Namespace: namespace}, secret)
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return len(secret.Data[corev1.TLSCertKey]) > 0 && len(secret.Data["ca.crt"]) > 0, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("waiting for cert-manager to issue %s/%s: %w", namespace, SecretName, err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,191 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"adhar-io/adhar/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func TestEnsureServingCertificateSelfSigned(t *testing.T) {
+	s := k8sruntime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(s))
+	c := fake.NewClientBuilder().WithScheme(s).Build()
+	dir := t.TempDir()
+
+	ca, err := EnsureServingCertificate(context.Background(), c, CertModeSelfSigned, "adhar-system", ServiceName, dir)
+	require.NoError(t, err)
+
+	pair, err := tls.LoadX509KeyPair(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"))
+	require.NoError(t, err)
+	require.NotNil(t, pair.Leaf)
+	assert.Contains(t, pair.Leaf.DNSNames, "adhar-webhook.adhar-system.svc")
+
+	// A restart reuses the stored certificate, so the CA bundle is stable.
+	again, err := EnsureServingCertificate(context.Background(), c, CertModeSelfSigned, "adhar-system", ServiceName, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, ca, again)
+
+	_, err = EnsureServingCertificate(context.Background(), c, "acme", "adhar-system", ServiceName, dir)
+	assert.Error(t, err)
+}
+
+// xrCRD stands in for the CRD Crossplane derives from an XRD.
+func xrCRD(kind, plural string) *apiextensionsv1.CustomResourceDefinition {
+	preserve := true
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: plural + "." + v1alpha1.GroupVersion.Group},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: v1alpha1.GroupVersion.Group,
+			Scope: apiextensionsv1.NamespaceScoped,
+			Names: apiextensionsv1.CustomResourceDefinitionNames{
+				Kind:     kind,
+				ListKind: kind + "List",
+				Plural:   plural,
+				Singular: strings.ToLower(kind),
+			},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+				Name:    v1alpha1.GroupVersion.Version,
+				Served:  true,
+				Storage: true,
+				Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+					Type:                   "object",
+					XPreserveUnknownFields: &preserve,
+				}},
+			}},
+		},
+	}
+}
+
+func TestWebhookServer(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS not set; run via `make test`")
+	}
+
+	s := k8sruntime.NewScheme()
+	sb := k8sruntime.NewSchemeBuilder(
+		corev1.AddToScheme,
+		v1alpha1.AddToScheme,
+	)
+	require.NoError(t, sb.AddToScheme(s))
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths: []string{filepath.Join("..", "controllers", "resources")},
+		CRDs: []*apiextensionsv1.CustomResourceDefinition{
+			xrCRD("CompositeDatabase", "compositedatabases"),
+			xrCRD("CompositeEnvironment", "compositeenvironments"),
+			xrCRD("CompositeApplication", "compositeapplications"),
+		},
+		ErrorIfCRDPathMissing: true,
+		Scheme:                s,
+		WebhookInstallOptions: envtest.WebhookInstallOptions{
+			ValidatingWebhooks: []*admissionregistrationv1.ValidatingWebhookConfiguration{
+				ValidatingWebhookConfiguration("adhar-system", ServiceName, nil),
+			},
+			MutatingWebhooks: []*admissionregistrationv1.MutatingWebhookConfiguration{
+				MutatingWebhookConfiguration("adhar-system", ServiceName, nil),
+			},
+		},
+	}
+
+	cfg, err := testEnv.Start()
+	require.NoError(t, err)
+	defer testEnv.Stop()
+
+	opts := testEnv.WebhookInstallOptions
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+		Scheme:  s,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Host:    opts.LocalServingHost,
+			Port:    opts.LocalServingPort,
+			CertDir: opts.LocalServingCertDir,
+		}),
+	})
+	require.NoError(t, err)
+	require.NoError(t, SetupWebhooks(mgr))
+
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	stoppedCh := make(chan error)
+	go func() {
+		stoppedCh <- mgr.Start(ctx)
+	}()
+	defer func() {
+		ctxCancel()
+		if err := <-stoppedCh; err != nil {
+			t.Errorf("Starting controller manager: %v", err)
+		}
+	}()
+
+	c, err := client.New(cfg, client.Options{Scheme: s})
+	require.NoError(t, err)
+
+	// The webhooks fail open, so wait until the server answers before
+	// expecting denials.
+	invalid := dataPlane(v1alpha1.InfraModeAdopt, func(dp *v1alpha1.DataPlane) {
+		dp.Name, dp.GenerateName = "", "adopted-"
+	})
+	require.Eventually(t, func() bool {
+		err := c.Create(ctx, invalid.DeepCopy())
+		return err != nil && strings.Contains(err.Error(), "kubeconfigSecretRef")
+	}, 30*time.Second, 500*time.Millisecond)
+
+	valid := dataPlane(v1alpha1.InfraModeComposite, nil)
+	require.NoError(t, c.Create(ctx, valid))
+	assert.Equal(t, v1alpha1.DefaultObservabilityHub, valid.Spec.Observability.Hub)
+	valid.Spec.Infrastructure.Mode = v1alpha1.InfraModeVCluster
+	err = c.Update(ctx, valid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "immutable")
+
+	platform := &v1alpha1.AdharPlatform{ObjectMeta: metav1.ObjectMeta{Name: "adhar", Namespace: "default"}}
+	platform.Spec.BuildCustomization.Protocol = "ftp"
+	err = c.Create(ctx, platform)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "protocol")
+
+	platform.Spec.BuildCustomization.Protocol = "https"
+	require.NoError(t, c.Create(ctx, platform))
+	assert.Equal(t, v1alpha1.ProviderKind, platform.Spec.Provider)
+	platform.Spec.BuildCustomization.Port = "8443"
+	err = c.Update(ctx, platform)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "buildCustomization")
+
+	for _, tc := range []struct {
+		kind    string
+		params  map[string]interface{}
+		message string
+	}{
+		{"CompositeDatabase", map[string]interface{}{"engine": "oracle", "engineVersion": "19"}, "invalid engine"},
+		{"CompositeEnvironment", map[string]interface{}{"name": "team-a", "tier": "prod", "enableNetworkPolicy": false}, "enableNetworkPolicy"},
+		{"CompositeApplication", map[string]interface{}{"project": "default", "source": map[string]interface{}{"repoURL": "https://git.example.com/app.git"}, "destination": map[string]interface{}{"server": "https://kubernetes.default.svc"}}, "path or chart"},
+	} {
+		obj := &unstructured.Unstructured{Object: xr(tc.kind, tc.params)}
+		err := c.Create(ctx, obj)
+		require.Error(t, err, tc.kind)
+		assert.Contains(t, err.Error(), tc.message)
+	}
+
+	env := &unstructured.Unstructured{Object: xr("CompositeEnvironment", map[string]interface{}{"name": "team-a", "tier": "dev", "podQuota": 20})}
+	assert.NoError(t, c.Create(ctx, env))
+}
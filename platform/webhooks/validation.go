@@ -1,6 +1,8 @@
 // Package webhooks implements Kubernetes admission webhook validators for the
-// platform's Crossplane composite resources (clusters, databases, networks, and
-// applications), enforcing required fields and provider-specific constraints.
+// platform's Crossplane composite resources (clusters, databases, networks,
+// environments and applications) and its own DataPlane and AdharPlatform
+// resources, enforcing required fields and provider-specific constraints. The
+// controller manager serves them; see SetupWebhooks.
 package webhooks
 
 import (
@@ -10,8 +12,9 @@ import (
 	"regexp"
 	"strings"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
@@ -88,13 +91,13 @@ func (v *ClusterValidator) Handle(ctx context.Context, req admission.Request) ad
 		}
 
 		// Validate count
-		count, ok := poolMap["count"].(float64)
+		count, ok := number(poolMap["count"])
 		if !ok || count < 1 {
 			return admission.Denied(fmt.Sprintf("node pool '%s' must have a count >= 1", name))
 		}
 
 		// Validate min/max count if specified
-		if minCount, ok := poolMap["minCount"].(float64); ok {
+		if minCount, ok := number(poolMap["minCount"]); ok {
 			if minCount < 1 {
 				return admission.Denied(fmt.Sprintf("node pool '%s' minCount must be >= 1", name))
 			}
@@ -103,7 +106,7 @@ func (v *ClusterValidator) Handle(ctx context.Context, req admission.Request) ad
 			}
 		}
 
-		if maxCount, ok := poolMap["maxCount"].(float64); ok {
+		if maxCount, ok := number(poolMap["maxCount"]); ok {
 			if maxCount < count {
 				return admission.Denied(fmt.Sprintf("node pool '%s' maxCount cannot be less than count", name))
 			}
@@ -179,7 +182,7 @@ func (v *DatabaseValidator) Handle(ctx context.Context, req admission.Request) a
 	}
 
 	// Validate backup retention days
-	if retentionDays, ok := params["backupRetentionDays"].(float64); ok {
+	if retentionDays, ok := number(params["backupRetentionDays"]); ok {
 		if retentionDays < 0 || retentionDays > 35 {
 			return admission.Denied("backupRetentionDays must be between 0 and 35")
 		}
@@ -350,23 +353,97 @@ func (v *ApplicationValidator) Handle(ctx context.Context, req admission.Request
 		return admission.Denied("destination is required")
 	}
 
-	// Must have either server or name
-	hasServer := false
-	hasName := false
+	// Must name a cluster: by server URL, ArgoCD cluster name, or the
+	// clusterRef/clusterSelector the XRD offers
+	hasTarget := false
+	for _, key := range []string{"server", "name"} {
+		if v, ok := destination[key].(string); ok && v != "" {
+			hasTarget = true
+		}
+	}
+	for _, key := range []string{"clusterRef", "clusterSelector"} {
+		if v, ok := destination[key].(map[string]interface{}); ok && len(v) > 0 {
+			hasTarget = true
+		}
+	}
 
-	if server, ok := destination["server"].(string); ok && server != "" {
-		hasServer = true
+	if !hasTarget {
+		return admission.Denied("destination must specify server, name, clusterRef or clusterSelector")
 	}
 
-	if name, ok := destination["name"].(string); ok && name != "" {
-		hasName = true
+	return admission.Allowed("application validation passed")
+}
+
+// EnvironmentValidator validates environment composite resources
+type EnvironmentValidator struct {
+	decoder admission.Decoder
+}
+
+// Handle validates an environment composite resource admission request.
+func (v *EnvironmentValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	env := &unstructured.Unstructured{}
+
+	err := v.decoder.Decode(req, env)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	if !hasServer && !hasName {
-		return admission.Denied("destination must have either server or name specified")
+	spec, ok := env.Object["spec"].(map[string]interface{})
+	if !ok {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("invalid spec"))
 	}
 
-	return admission.Allowed("application validation passed")
+	params, ok := spec["parameters"].(map[string]interface{})
+	if !ok {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf("invalid parameters"))
+	}
+
+	// The environment name becomes its namespace
+	name, ok := params["name"].(string)
+	if !ok || name == "" {
+		return admission.Denied("name is required")
+	}
+	if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+		return admission.Denied(fmt.Sprintf("invalid name %q: %s", name, strings.Join(errs, "; ")))
+	}
+
+	tier, _ := params["tier"].(string)
+	if tier != "" {
+		validTiers := []string{"dev", "test", "staging", "prod"}
+		if !contains(validTiers, tier) {
+			return admission.Denied(fmt.Sprintf("invalid tier: %s. Must be one of: %s", tier, strings.Join(validTiers, ", ")))
+		}
+	}
+
+	// Validate quotas
+	for _, key := range []string{"cpuQuota", "memoryQuota"} {
+		if quota, ok := params[key].(string); ok {
+			if _, err := resource.ParseQuantity(quota); err != nil {
+				return admission.Denied(fmt.Sprintf("%s %q is not a valid quantity", key, quota))
+			}
+		}
+	}
+	if podQuota, ok := number(params["podQuota"]); ok && podQuota < 1 {
+		return admission.Denied("podQuota must be >= 1")
+	}
+
+	// Production environments keep their default-deny network policy
+	if enabled, ok := params["enableNetworkPolicy"].(bool); ok && !enabled && tier == "prod" {
+		return admission.Denied("enableNetworkPolicy cannot be disabled for prod environments")
+	}
+
+	if labels, ok := params["labels"].(map[string]interface{}); ok {
+		for key, value := range labels {
+			if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+				return admission.Denied(fmt.Sprintf("invalid label key %q: %s", key, strings.Join(errs, "; ")))
+			}
+			if s, _ := value.(string); len(validation.IsValidLabelValue(s)) > 0 {
+				return admission.Denied(fmt.Sprintf("invalid value for label %q", key))
+			}
+		}
+	}
+
+	return admission.Allowed("environment validation passed")
 }
 
 // Helper functions
@@ -380,6 +457,20 @@ func contains(slice []string, item string) bool {
 	return false
 }
 
+// number reads a JSON number from an unstructured object, which decodes
+// integers as int64 and everything else as float64.
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
 func isValidCIDR(cidr string) bool {
 	cidrPattern := regexp.MustCompile(`^(\d{1,3}\.){3}\d{1,3}/\d{1,2}$`)
 	return cidrPattern.MatchString(cidr)
@@ -414,14 +505,14 @@ func (v *ApplicationValidator) InjectDecoder(d admission.Decoder) error {
 	return nil
 }
 
+// InjectDecoder injects the admission decoder into the validator.
+func (v *EnvironmentValidator) InjectDecoder(d admission.Decoder) error {
+	v.decoder = d
+	return nil
+}
+
 var _ admission.Handler = &ClusterValidator{}
 var _ admission.Handler = &DatabaseValidator{}
 var _ admission.Handler = &NetworkValidator{}
 var _ admission.Handler = &ApplicationValidator{}
-
-// SetupWebhooks registers all validators with the webhook server
-func SetupWebhooks(mgr runtime.Object) error {
-	// This would be implemented in the actual webhook server setup
-	// For now, this is a placeholder to show the structure
-	return nil
-}
+var _ admission.Handler = &EnvironmentValidator{}
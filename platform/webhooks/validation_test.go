@@ -0,0 +1,211 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"adhar-io/adhar/api/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newDecoder(t *testing.T) admission.Decoder {
+	s := runtime.NewScheme()
+	require.NoError(t, v1alpha1.AddToScheme(s))
+	return admission.NewDecoder(s)
+}
+
+func request(t *testing.T, op admissionv1.Operation, obj, old interface{}) admission.Request {
+	raw, err := json.Marshal(obj)
+	require.NoError(t, err)
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: op}}
+	req.Object.Raw = raw
+	if old != nil {
+		req.OldObject.Raw, err = json.Marshal(old)
+		require.NoError(t, err)
+	}
+	return req
+}
+
+func xr(kind string, params map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": "platform.adhar.io/v1alpha1",
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": "test", "namespace": "default"},
+		"spec":       map[string]interface{}{"parameters": params},
+	}
+}
+
+func TestEnvironmentValidator(t *testing.T) {
+	v := &EnvironmentValidator{}
+	require.NoError(t, v.InjectDecoder(newDecoder(t)))
+
+	cases := []struct {
+		name    string
+		params  map[string]interface{}
+		allowed bool
+	}{
+		{"valid", map[string]interface{}{"name": "team-a", "tier": "prod", "cpuQuota": "8", "memoryQuota": "16Gi", "podQuota": 50}, true},
+		{"missing name", map[string]interface{}{"tier": "dev"}, false},
+		{"name not a label", map[string]interface{}{"name": "Team_A"}, false},
+		{"unknown tier", map[string]interface{}{"name": "a", "tier": "qa"}, false},
+		{"bad quota", map[string]interface{}{"name": "a", "memoryQuota": "lots"}, false},
+		{"zero pods", map[string]interface{}{"name": "a", "podQuota": 0}, false},
+		{"prod without network policy", map[string]interface{}{"name": "a", "tier": "prod", "enableNetworkPolicy": false}, false},
+		{"dev without network policy", map[string]interface{}{"name": "a", "tier": "dev", "enableNetworkPolicy": false}, true},
+		{"bad label", map[string]interface{}{"name": "a", "labels": map[string]interface{}{"team": "a b"}}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := v.Handle(context.Background(), request(t, admissionv1.Create, xr("CompositeEnvironment", tc.params), nil))
+			assert.Equal(t, tc.allowed, resp.Allowed, resp.Result.Message)
+		})
+	}
+}
+
+func TestIntegerParameters(t *testing.T) {
+	// Integers in admission requests decode as int64, not float64.
+	v := &DatabaseValidator{}
+	require.NoError(t, v.InjectDecoder(newDecoder(t)))
+	params := map[string]interface{}{"engine": "postgresql", "engineVersion": "16", "backupRetentionDays": 90}
+	resp := v.Handle(context.Background(), request(t, admissionv1.Create, xr("CompositeDatabase", params), nil))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "backupRetentionDays")
+}
+
+func TestApplicationDestination(t *testing.T) {
+	v := &ApplicationValidator{}
+	require.NoError(t, v.InjectDecoder(newDecoder(t)))
+	params := func(dest map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"project":     "default",
+			"source":      map[string]interface{}{"repoURL": "https://git.example.com/app.git", "path": "deploy"},
+			"destination": dest,
+		}
+	}
+	for _, dest := range []map[string]interface{}{
+		{"server": "https://kubernetes.default.svc"},
+		{"clusterRef": map[string]interface{}{"name": "edge-1"}},
+		{"clusterSelector": map[string]interface{}{"matchLabels": map[string]interface{}{"tier": "edge"}}},
+	} {
+		resp := v.Handle(context.Background(), request(t, admissionv1.Create, xr("CompositeApplication", params(dest)), nil))
+		assert.True(t, resp.Allowed, "%v: %s", dest, resp.Result.Message)
+	}
+	resp := v.Handle(context.Background(), request(t, admissionv1.Create, xr("CompositeApplication", params(map[string]interface{}{"namespace": "web"})), nil))
+	assert.False(t, resp.Allowed)
+}
+
+func dataPlane(mode v1alpha1.DataPlaneInfraMode, mutate func(*v1alpha1.DataPlane)) *v1alpha1.DataPlane {
+	dp := &v1alpha1.DataPlane{}
+	dp.APIVersion, dp.Kind, dp.Name = "platform.adhar.io/v1alpha1", "DataPlane", "edge-1"
+	dp.Spec.Infrastructure = v1alpha1.DataPlaneInfrastructure{
+		Mode:      mode,
+		Provider:  v1alpha1.ProviderAWS,
+		Region:    "eu-west-1",
+		NodePools: []v1alpha1.NodePoolSpec{{Name: "default", Size: "m6i.large", Count: 3}},
+	}
+	if mutate != nil {
+		mutate(dp)
+	}
+	return dp
+}
+
+func TestDataPlaneValidator(t *testing.T) {
+	v := &DataPlaneValidator{}
+	require.NoError(t, v.InjectDecoder(newDecoder(t)))
+
+	cases := []struct {
+		name    string
+		dp      *v1alpha1.DataPlane
+		allowed bool
+	}{
+		{"composite", dataPlane(v1alpha1.InfraModeComposite, nil), true},
+		{"composite on kind", dataPlane(v1alpha1.InfraModeComposite, func(dp *v1alpha1.DataPlane) { dp.Spec.Infrastructure.Provider = v1alpha1.ProviderKind }), false},
+		{"composite without region", dataPlane(v1alpha1.InfraModeComposite, func(dp *v1alpha1.DataPlane) { dp.Spec.Infrastructure.Region = "" }), false},
+		{"empty node pool", dataPlane(v1alpha1.InfraModeComposite, func(dp *v1alpha1.DataPlane) { dp.Spec.Infrastructure.NodePools[0].Count = 0 }), false},
+		{"duplicate node pools", dataPlane(v1alpha1.InfraModeComposite, func(dp *v1alpha1.DataPlane) {
+			dp.Spec.Infrastructure.NodePools = append(dp.Spec.Infrastructure.NodePools, dp.Spec.Infrastructure.NodePools[0])
+		}), false},
+		{"adopt without kubeconfig", dataPlane(v1alpha1.InfraModeAdopt, nil), false},
+		{"adopt", dataPlane(v1alpha1.InfraModeAdopt, func(dp *v1alpha1.DataPlane) {
+			dp.Spec.Infrastructure.KubeconfigSecretRef = &v1alpha1.NamedRef{Name: "edge-1-kubeconfig"}
+		}), true},
+		{"vcluster", dataPlane(v1alpha1.InfraModeVCluster, nil), true},
+		{"bad placement label", dataPlane(v1alpha1.InfraModeVCluster, func(dp *v1alpha1.DataPlane) {
+			dp.Spec.Placement.Labels = map[string]string{"region/": "eu"}
+		}), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := v.Handle(context.Background(), request(t, admissionv1.Create, tc.dp, nil))
+			assert.Equal(t, tc.allowed, resp.Allowed, resp.Result.Message)
+		})
+	}
+
+	old := dataPlane(v1alpha1.InfraModeVCluster, nil)
+	resp := v.Handle(context.Background(), request(t, admissionv1.Update, dataPlane(v1alpha1.InfraModeComposite, nil), old))
+	assert.False(t, resp.Allowed)
+	assert.Contains(t, resp.Result.Message, "immutable")
+}
+
+func TestAdharPlatformValidator(t *testing.T) {
+	v := &AdharPlatformValidator{}
+	require.NoError(t, v.InjectDecoder(newDecoder(t)))
+	platform := func(build v1alpha1.BuildCustomizationSpec) *v1alpha1.AdharPlatform {
+		p := &v1alpha1.AdharPlatform{}
+		p.APIVersion, p.Kind, p.Name = "platform.adhar.io/v1alpha1", "AdharPlatform", "adhar"
+		p.Spec.Provider = v1alpha1.ProviderKind
+		p.Spec.BuildCustomization = build
+		return p
+	}
+	valid := v1alpha1.BuildCustomizationSpec{Protocol: "https", Host: "adhar.localtest.me", Port: "8443"}
+
+	resp := v.Handle(context.Background(), request(t, admissionv1.Create, platform(valid), nil))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+
+	for _, build := range []v1alpha1.BuildCustomizationSpec{
+		{Protocol: "ftp"},
+		{Port: "70000"},
+		{Port: "https"},
+		{Host: "Not A Host"},
+	} {
+		resp := v.Handle(context.Background(), request(t, admissionv1.Create, platform(build), nil))
+		assert.False(t, resp.Allowed, "%+v", build)
+	}
+
+	bad := platform(valid)
+	bad.Spec.Provider = "openstack"
+	resp = v.Handle(context.Background(), request(t, admissionv1.Create, bad, nil))
+	assert.False(t, resp.Allowed)
+
+	changed := valid
+	changed.Port = "443"
+	resp = v.Handle(context.Background(), request(t, admissionv1.Update, platform(changed), platform(valid)))
+	assert.False(t, resp.Allowed)
+	resp = v.Handle(context.Background(), request(t, admissionv1.Update, platform(valid), platform(valid)))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+
+	// The self-signed certificate is rotated in place
+	rotated := valid
+	rotated.SelfSignedCert = "-----BEGIN CERTIFICATE-----"
+	resp = v.Handle(context.Background(), request(t, admissionv1.Update, platform(rotated), platform(valid)))
+	assert.True(t, resp.Allowed, resp.Result.Message)
+}
+
+func TestValidatingWebhookConfiguration(t *testing.T) {
+	cfg := ValidatingWebhookConfiguration("adhar-system", ServiceName, []byte("ca"))
+	require.Len(t, cfg.Webhooks, len(routes()))
+	for _, w := range cfg.Webhooks {
+		require.NotNil(t, w.ClientConfig.Service)
+		assert.Equal(t, "adhar-system", w.ClientConfig.Service.Namespace)
+		assert.Equal(t, Path(w.Rules[0].Resources[0]), *w.ClientConfig.Service.Path)
+		assert.Equal(t, []byte("ca"), w.ClientConfig.CABundle)
+		assert.Equal(t, "Ignore", string(*w.FailurePolicy))
+	}
+	assert.Equal(t, "dataplane.platform.adhar.io", cfg.Webhooks[5].Name)
+}